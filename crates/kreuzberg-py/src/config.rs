@@ -1620,7 +1620,11 @@ impl TesseractConfig {
                 tessedit_char_blacklist: tessedit_char_blacklist.unwrap_or_default(),
                 tessedit_use_primary_params_model: tessedit_use_primary_params_model.unwrap_or(true),
                 textord_space_size_is_variable: textord_space_size_is_variable.unwrap_or(true),
+                preserve_interword_spaces: false,
                 thresholding_method: thresholding_method.unwrap_or(false),
+                tessdata_path: None,
+                cache_key_extra: None,
+                include_hocr: false,
             },
         }
     }
@@ -57,6 +57,8 @@ impl ImageExtractor {
         let mut result = ocr_result;
         result.content = ocr_extraction_result.content;
         result.pages = ocr_extraction_result.page_contents;
+        // The OCR backend reads the first frame of an image.
+        result.metadata.additional.insert("ocr_pages".to_string(), serde_json::json!([1]));
 
         Ok(result)
     }
@@ -5,6 +5,7 @@ use crate::core::config::ExtractionConfig;
 use crate::plugins::{DocumentExtractor, Plugin};
 use crate::types::{ExtractionResult, Metadata, PageContent};
 use async_trait::async_trait;
+use std::collections::HashMap;
 #[cfg(feature = "tokio-runtime")]
 use std::path::Path;
 
@@ -280,9 +281,16 @@ impl PdfExtractor {
 
     /// Extract text from PDF using OCR.
     ///
-    /// Renders all pages to images and processes them with OCR.
+    /// Renders all pages to images and processes them with OCR. Also returns the metadata of
+    /// the OCR: the numbers of the pages OCR'd under `ocr_pages`, the clockwise rotation, in
+    /// degrees, that auto-rotate applied to each page under `page_rotations`, and the hOCR of
+    /// every page under `page_hocr` when the backend returned it.
     #[cfg(feature = "ocr")]
-    async fn extract_with_ocr(&self, content: &[u8], config: &ExtractionConfig) -> Result<String> {
+    async fn extract_with_ocr(
+        &self,
+        content: &[u8],
+        config: &ExtractionConfig,
+    ) -> Result<(String, HashMap<String, serde_json::Value>)> {
         use crate::plugins::registry::get_ocr_backend_registry;
         use image::ImageEncoder;
         use image::codecs::png::PngEncoder;
@@ -318,8 +326,11 @@ impl PdfExtractor {
         };
 
         let mut page_texts = Vec::with_capacity(images.len());
+        let mut ocr_pages = Vec::with_capacity(images.len());
+        let mut page_rotations = serde_json::Map::new();
+        let mut page_hocr = Vec::with_capacity(images.len());
 
-        for image in images {
+        for (page_index, image) in images.into_iter().enumerate() {
             let rgb_image = image.to_rgb8();
             let (width, height) = rgb_image.dimensions();
 
@@ -336,10 +347,37 @@ impl PdfExtractor {
 
             let ocr_result = backend.process_image(&image_data, ocr_config).await?;
 
+            if let Some(degrees) = ocr_result
+                .metadata
+                .additional
+                .get("auto_rotation")
+                .and_then(|value| value.as_str())
+                .and_then(|value| value.parse::<i64>().ok())
+            {
+                page_rotations.insert((page_index + 1).to_string(), degrees.into());
+            }
+            page_hocr.push(
+                ocr_result
+                    .metadata
+                    .additional
+                    .get("hocr")
+                    .cloned()
+                    .unwrap_or(serde_json::Value::Null),
+            );
             page_texts.push(ocr_result.content);
+            ocr_pages.push(serde_json::Value::from(page_index + 1));
+        }
+
+        let mut metadata = HashMap::new();
+        metadata.insert("ocr_pages".to_string(), serde_json::Value::Array(ocr_pages));
+        if !page_rotations.is_empty() {
+            metadata.insert("page_rotations".to_string(), serde_json::Value::Object(page_rotations));
+        }
+        if page_hocr.iter().any(|hocr| !hocr.is_null()) {
+            metadata.insert("page_hocr".to_string(), serde_json::Value::Array(page_hocr));
         }
 
-        Ok(page_texts.join("\n\n"))
+        Ok((page_texts.join("\n\n"), metadata))
     }
 }
 
@@ -480,11 +518,11 @@ impl DocumentExtractor for PdfExtractor {
         };
 
         #[cfg(feature = "ocr")]
-        let text = if config.force_ocr {
+        let (text, additional) = if config.force_ocr {
             if config.ocr.is_some() {
                 self.extract_with_ocr(content, config).await?
             } else {
-                native_text
+                (native_text, HashMap::new())
             }
         } else if config.ocr.is_some() {
             let decision = evaluate_native_text_for_ocr(&native_text, None);
@@ -506,14 +544,14 @@ impl DocumentExtractor for PdfExtractor {
             if decision.fallback {
                 self.extract_with_ocr(content, config).await?
             } else {
-                native_text
+                (native_text, HashMap::new())
             }
         } else {
-            native_text
+            (native_text, HashMap::new())
         };
 
         #[cfg(not(feature = "ocr"))]
-        let text = native_text;
+        let (text, additional) = (native_text, HashMap::new());
 
         #[cfg(feature = "pdf")]
         if let Some(ref page_cfg) = config.pages
@@ -586,6 +624,7 @@ impl DocumentExtractor for PdfExtractor {
                 pages: pdf_metadata.page_structure.clone(),
                 #[cfg(feature = "pdf")]
                 format: Some(crate::types::FormatMetadata::Pdf(pdf_metadata.pdf_specific)),
+                additional,
                 ..Default::default()
             },
             pages: final_pages,
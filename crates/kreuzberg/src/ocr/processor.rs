@@ -25,6 +25,30 @@ fn strip_control_characters(text: &str) -> String {
     }
 }
 
+/// Orientation confidence below which Tesseract's orientation detection is not trusted.
+const AUTO_ROTATE_MIN_CONFIDENCE: f32 = 2.0;
+
+/// Returns the clockwise rotation, in degrees, that turns `image` upright, as found by
+/// Tesseract's orientation and script detection. Returns 0 when the image is upright, when
+/// detection is not confident, or when `osd.traineddata` is not installed.
+fn detect_rotation(tessdata_path: &str, image: &image::RgbImage) -> i32 {
+    let api = TesseractAPI::new();
+    if api.init(tessdata_path, "osd").is_err() || api.set_page_seg_mode(TessPageSegMode::PSM_OSD_ONLY).is_err() {
+        return 0;
+    }
+    let (width, height) = image.dimensions();
+    if api.set_image(image.as_raw(), width as i32, height as i32, 3, (width * 3) as i32).is_err() {
+        return 0;
+    }
+    match api.detect_os() {
+        // Tesseract reports the counter-clockwise rotation that turns the image upright.
+        Ok((orientation, confidence, _, _)) if confidence >= AUTO_ROTATE_MIN_CONFIDENCE => {
+            (360 - orientation.rem_euclid(360)) % 360
+        }
+        _ => 0,
+    }
+}
+
 fn log_ci_debug<F>(enabled: bool, stage: &str, details: F)
 where
     F: FnOnce() -> String,
@@ -151,7 +175,11 @@ impl OcrProcessor {
         config.tessedit_char_whitelist.hash(&mut hasher);
         config.tessedit_use_primary_params_model.hash(&mut hasher);
         config.textord_space_size_is_variable.hash(&mut hasher);
+        config.preserve_interword_spaces.hash(&mut hasher);
         config.thresholding_method.hash(&mut hasher);
+        config.cache_key_extra.hash(&mut hasher);
+        config.include_hocr.hash(&mut hasher);
+        config.preprocessing.as_ref().is_some_and(|p| p.auto_rotate).hash(&mut hasher);
 
         format!("{:016x}", hasher.finish())
     }
@@ -197,8 +225,10 @@ impl OcrProcessor {
             r#"C:\Program Files\Tesseract-OCR\tessdata"#,
             r#"C:\ProgramData\Tesseract-OCR\tessdata"#,
         ];
-        let tessdata_path = tessdata_env
+        let tessdata_path = config
+            .tessdata_path
             .clone()
+            .or_else(|| tessdata_env.clone())
             .or_else(|| {
                 fallback_paths
                     .iter()
@@ -266,6 +296,22 @@ impl OcrProcessor {
             }
         }
 
+        let auto_rotation = if config.preprocessing.as_ref().is_some_and(|p| p.auto_rotate) {
+            detect_rotation(&tessdata_path, &rgb_image)
+        } else {
+            0
+        };
+        let rgb_image = match auto_rotation {
+            90 => image::imageops::rotate90(&rgb_image),
+            180 => image::imageops::rotate180(&rgb_image),
+            270 => image::imageops::rotate270(&rgb_image),
+            _ => rgb_image,
+        };
+        let (width, height) = rgb_image.dimensions();
+        let bytes_per_line = width * bytes_per_pixel;
+
+        log_ci_debug(ci_debug_enabled, "auto_rotate", || format!("degrees={}", auto_rotation));
+
         let init_result = api.init(&tessdata_path, &config.language);
         log_ci_debug(ci_debug_enabled, "init", || match &init_result {
             Ok(_) => format!("language={} datapath='{}'", config.language, tessdata_path),
@@ -354,6 +400,12 @@ impl OcrProcessor {
         )
         .map_err(|e| OcrError::InvalidConfiguration(format!("Failed to set textord_space_size_is_variable: {}", e)))?;
 
+        api.set_variable(
+            "preserve_interword_spaces",
+            if config.preserve_interword_spaces { "1" } else { "0" },
+        )
+        .map_err(|e| OcrError::InvalidConfiguration(format!("Failed to set preserve_interword_spaces: {}", e)))?;
+
         api.set_variable("thresholding_method", &config.thresholding_method.to_string())
             .map_err(|e| OcrError::InvalidConfiguration(format!("Failed to set thresholding_method: {}", e)))?;
 
@@ -438,6 +490,18 @@ impl OcrProcessor {
             "tables_detected".to_string(),
             serde_json::Value::String("0".to_string()),
         );
+        if config.include_hocr && config.output_format != "hocr" {
+            let hocr = api
+                .get_hocr_text(0)
+                .map_err(|e| OcrError::ProcessingFailed(format!("Failed to extract hOCR: {}", e)))?;
+            metadata.insert("hocr".to_string(), serde_json::Value::String(hocr));
+        }
+        if auto_rotation != 0 {
+            metadata.insert(
+                "auto_rotation".to_string(),
+                serde_json::Value::String(auto_rotation.to_string()),
+            );
+        }
         if config.output_format == "markdown" {
             metadata.insert(
                 "source_format".to_string(),
@@ -742,6 +806,22 @@ mod tests {
         assert_ne!(hash1, hash2);
     }
 
+    #[test]
+    fn test_hash_config_different_cache_key_extra() {
+        let temp_dir = tempdir().unwrap();
+        let processor = OcrProcessor::new(Some(temp_dir.path().to_path_buf())).unwrap();
+
+        let config1 = create_test_config();
+
+        let mut config2 = create_test_config();
+        config2.cache_key_extra = Some("pipeline-v2".to_string());
+
+        let hash1 = processor.hash_config(&config1);
+        let hash2 = processor.hash_config(&config2);
+
+        assert_ne!(hash1, hash2);
+    }
+
     #[test]
     fn test_hash_config_different_output_format() {
         let temp_dir = tempdir().unwrap();
@@ -78,7 +78,11 @@ impl TesseractBackend {
             tessedit_char_blacklist: public_config.tessedit_char_blacklist.clone(),
             tessedit_use_primary_params_model: public_config.tessedit_use_primary_params_model,
             textord_space_size_is_variable: public_config.textord_space_size_is_variable,
+            preserve_interword_spaces: public_config.preserve_interword_spaces,
             thresholding_method: public_config.thresholding_method,
+            tessdata_path: public_config.tessdata_path.clone(),
+            cache_key_extra: public_config.cache_key_extra.clone(),
+            include_hocr: public_config.include_hocr,
         }
     }
 
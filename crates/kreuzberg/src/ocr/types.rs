@@ -73,7 +73,11 @@ pub struct TesseractConfig {
     pub tessedit_char_blacklist: String,
     pub tessedit_use_primary_params_model: bool,
     pub textord_space_size_is_variable: bool,
+    pub preserve_interword_spaces: bool,
     pub thresholding_method: bool,
+    pub tessdata_path: Option<String>,
+    pub cache_key_extra: Option<String>,
+    pub include_hocr: bool,
 }
 
 impl Default for TesseractConfig {
@@ -99,7 +103,11 @@ impl Default for TesseractConfig {
             tessedit_char_blacklist: String::new(),
             tessedit_use_primary_params_model: true,
             textord_space_size_is_variable: true,
+            preserve_interword_spaces: false,
             thresholding_method: false,
+            tessdata_path: None,
+            cache_key_extra: None,
+            include_hocr: false,
         }
     }
 }
@@ -144,7 +152,11 @@ impl From<&crate::types::TesseractConfig> for TesseractConfig {
             tessedit_char_blacklist: config.tessedit_char_blacklist.clone(),
             tessedit_use_primary_params_model: config.tessedit_use_primary_params_model,
             textord_space_size_is_variable: config.textord_space_size_is_variable,
+            preserve_interword_spaces: config.preserve_interword_spaces,
             thresholding_method: config.thresholding_method,
+            tessdata_path: config.tessdata_path.clone(),
+            cache_key_extra: config.cache_key_extra.clone(),
+            include_hocr: config.include_hocr,
         }
     }
 }
@@ -363,7 +375,11 @@ mod tests {
             tessedit_char_blacklist: "!@#$".to_string(),
             tessedit_use_primary_params_model: false,
             textord_space_size_is_variable: false,
+            preserve_interword_spaces: true,
             thresholding_method: true,
+            tessdata_path: Some("/opt/tessdata".to_string()),
+            cache_key_extra: Some("pipeline-v2".to_string()),
+            include_hocr: true,
         };
 
         let internal_config: TesseractConfig = (&public_config).into();
@@ -388,6 +404,10 @@ mod tests {
         assert_eq!(internal_config.tessedit_char_blacklist, "!@#$");
         assert!(!internal_config.tessedit_use_primary_params_model);
         assert!(!internal_config.textord_space_size_is_variable);
+        assert!(internal_config.preserve_interword_spaces);
         assert!(internal_config.thresholding_method);
+        assert_eq!(internal_config.tessdata_path.as_deref(), Some("/opt/tessdata"));
+        assert_eq!(internal_config.cache_key_extra.as_deref(), Some("pipeline-v2"));
+        assert!(internal_config.include_hocr);
     }
 }
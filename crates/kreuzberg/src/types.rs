@@ -1322,8 +1322,26 @@ pub struct TesseractConfig {
     /// Variable-width space detection
     pub textord_space_size_is_variable: bool,
 
+    /// Keep runs of spaces between words instead of collapsing them to one
+    pub preserve_interword_spaces: bool,
+
     /// Use adaptive thresholding method
     pub thresholding_method: bool,
+
+    /// Directory holding the traineddata files.
+    ///
+    /// Takes precedence over `TESSDATA_PREFIX` and the default search paths.
+    #[serde(skip_serializing_if = "Option::is_none")]
+    pub tessdata_path: Option<String>,
+
+    /// Extra value mixed into the key of cached OCR results.
+    ///
+    /// Changing it makes results cached under the previous value unreachable.
+    #[serde(skip_serializing_if = "Option::is_none")]
+    pub cache_key_extra: Option<String>,
+
+    /// Return the hOCR of each OCR'd page along with its text, under the `hocr` metadata key
+    pub include_hocr: bool,
 }
 
 impl Default for TesseractConfig {
@@ -1349,7 +1367,11 @@ impl Default for TesseractConfig {
             tessedit_char_blacklist: String::new(),
             tessedit_use_primary_params_model: true,
             textord_space_size_is_variable: true,
+            preserve_interword_spaces: false,
             thresholding_method: false,
+            tessdata_path: None,
+            cache_key_extra: None,
+            include_hocr: false,
         }
     }
 }
@@ -0,0 +1,159 @@
+package kreuzberg
+
+import (
+	"bytes"
+	"encoding/xml"
+	"errors"
+	"io"
+	"strings"
+)
+
+// Annotation types reported in Annotation.Type.
+const (
+	AnnotationTypeComment   = "comment"
+	AnnotationTypeHighlight = "highlight"
+	AnnotationTypeNote      = "note"
+)
+
+// Annotation is a reviewer comment or markup attached to a document, such as a PDF
+// annotation or a DOCX comment (w:comment).
+type Annotation struct {
+	Type       string      `json:"type"`
+	Text       string      `json:"text"`
+	Author     string      `json:"author,omitempty"`
+	PageNumber int         `json:"page_number,omitempty"`
+	BBox       *[4]float64 `json:"bbox,omitempty"`
+}
+
+// applyAnnotations populates result.Annotations when annotation extraction is enabled.
+// PDF annotations are read from the /Annots of each page of the source document; DOCX
+// comments are read from word/comments.xml. DOCX comments have no page geometry, so their
+// PageNumber and BBox are left unset.
+func applyAnnotations(result *ExtractionResult, config *ExtractionConfig, src *documentSource) error {
+	if config.ExtractAnnotations == nil || !*config.ExtractAnnotations {
+		return nil
+	}
+
+	switch {
+	case isPDFMimeType(result.MimeType):
+		doc, err := src.pdfDocument()
+		if err != nil {
+			return err
+		}
+		result.Annotations = append(result.Annotations, doc.annotations()...)
+	case result.MimeType == mimeTypeDOCX:
+		data, err := src.bytes()
+		if err != nil {
+			return err
+		}
+		comments, err := parseDocxComments(data)
+		if err != nil {
+			return newParsingErrorWithContext("failed to read DOCX comments", err, ErrorCodeParsing, nil)
+		}
+		result.Annotations = append(result.Annotations, comments...)
+	}
+
+	return nil
+}
+
+// annotations returns the markup annotations of the document's pages in page order. Text
+// annotations (sticky notes) are notes and Highlight annotations highlights; other markup,
+// such as FreeText or Underline, is reported as a comment when it carries text. Links,
+// form widgets and the pop-up windows of other annotations are not annotations in this
+// sense and are skipped.
+func (d *pdfDocument) annotations() []Annotation {
+	var annotations []Annotation
+	for i, page := range d.pages() {
+		for _, value := range d.array(page.dict["Annots"]) {
+			annot := d.dict(value)
+			if annot == nil {
+				continue
+			}
+			contents, _ := d.resolve(annot["Contents"]).(string)
+			author, _ := d.resolve(annot["T"]).(string)
+			annotation := Annotation{Text: strings.TrimSpace(contents), Author: author, PageNumber: i + 1}
+			switch annot.name("Subtype") {
+			case "Text":
+				annotation.Type = AnnotationTypeNote
+			case "Highlight":
+				annotation.Type = AnnotationTypeHighlight
+			case "Link", "Widget", "Popup", "":
+				continue
+			default:
+				if annotation.Text == "" {
+					continue
+				}
+				annotation.Type = AnnotationTypeComment
+			}
+			if rect, ok := d.rect(annot["Rect"]); ok {
+				annotation.BBox = &rect
+			}
+			annotations = append(annotations, annotation)
+		}
+	}
+	return annotations
+}
+
+// parseDocxComments extracts the comments stored in word/comments.xml of a DOCX package.
+func parseDocxComments(data []byte) ([]Annotation, error) {
+	raw, ok, err := readZipEntry(data, "word/comments.xml")
+	if err != nil || !ok {
+		return nil, err
+	}
+
+	decoder := xml.NewDecoder(bytes.NewReader(raw))
+	var annotations []Annotation
+	var current *Annotation
+	var text strings.Builder
+	inText := false
+
+	for {
+		token, err := decoder.Token()
+		if errors.Is(err, io.EOF) {
+			break
+		}
+		if err != nil {
+			return nil, err
+		}
+
+		switch el := token.(type) {
+		case xml.StartElement:
+			switch el.Name.Local {
+			case "comment":
+				current = &Annotation{Type: AnnotationTypeComment, Author: xmlAttr(el, "author")}
+				text.Reset()
+			case "t":
+				inText = current != nil
+			case "tab":
+				if current != nil {
+					text.WriteByte('\t')
+				}
+			case "br":
+				if current != nil {
+					text.WriteByte('\n')
+				}
+			}
+		case xml.EndElement:
+			switch el.Name.Local {
+			case "comment":
+				if current != nil {
+					current.Text = strings.TrimSpace(text.String())
+					annotations = append(annotations, *current)
+					current = nil
+				}
+			case "t":
+				inText = false
+			case "p":
+				if current != nil {
+					text.WriteByte('\n')
+				}
+			}
+		case xml.CharData:
+			if inText {
+				text.Write(el)
+			}
+		}
+	}
+
+	return annotations, nil
+}
@@ -0,0 +1,99 @@
+package kreuzberg
+
+import (
+	"archive/zip"
+	"bytes"
+	"reflect"
+	"testing"
+)
+
+func buildDocxWithComments(t *testing.T, comments string) []byte {
+	t.Helper()
+	var buf bytes.Buffer
+	zw := zip.NewWriter(&buf)
+	w, err := zw.Create("word/comments.xml")
+	if err != nil {
+		t.Fatalf("create comments entry: %v", err)
+	}
+	if _, err := w.Write([]byte(comments)); err != nil {
+		t.Fatalf("write comments entry: %v", err)
+	}
+	if err := zw.Close(); err != nil {
+		t.Fatalf("close zip: %v", err)
+	}
+	return buf.Bytes()
+}
+
+// TestAnnotationsFromDocxComments verifies that DOCX w:comment elements become annotations.
+func TestAnnotationsFromDocxComments(t *testing.T) {
+	data := buildDocxWithComments(t, `<?xml version="1.0" encoding="UTF-8"?>
+<w:comments xmlns:w="http://schemas.openxmlformats.org/wordprocessingml/2006/main">
+  <w:comment w:id="0" w:author="Alice">
+    <w:p><w:r><w:t>Please </w:t></w:r><w:r><w:t>rephrase.</w:t></w:r></w:p>
+    <w:p><w:r><w:t>Second line</w:t></w:r></w:p>
+  </w:comment>
+  <w:comment w:id="1" w:author="Bob"><w:p><w:r><w:t>OK</w:t></w:r></w:p></w:comment>
+</w:comments>`)
+
+	result := &ExtractionResult{MimeType: mimeTypeDOCX}
+	config := NewExtractionConfig(WithExtractAnnotations(true))
+	if err := applyAnnotations(result, config, &documentSource{data: data}); err != nil {
+		t.Fatalf("applyAnnotations failed: %v", err)
+	}
+
+	if len(result.Annotations) != 2 {
+		t.Fatalf("expected 2 annotations, got %d", len(result.Annotations))
+	}
+	first := result.Annotations[0]
+	if first.Type != AnnotationTypeComment || first.Author != "Alice" {
+		t.Errorf("unexpected first annotation: %+v", first)
+	}
+	if first.Text != "Please rephrase.\nSecond line" {
+		t.Errorf("unexpected comment text %q", first.Text)
+	}
+	if result.Annotations[1].Author != "Bob" || result.Annotations[1].Text != "OK" {
+		t.Errorf("unexpected second annotation: %+v", result.Annotations[1])
+	}
+}
+
+// TestAnnotationsFromPDF verifies that the markup annotations of a PDF's pages are read
+// from the document, and that links and pop-ups are skipped.
+func TestAnnotationsFromPDF(t *testing.T) {
+	result := &ExtractionResult{MimeType: "application/pdf"}
+	src := &documentSource{path: getTestFilePath("pdf/annotations.pdf")}
+	if err := applyAnnotations(result, NewExtractionConfig(WithExtractAnnotations(true)), src); err != nil {
+		t.Fatalf("applyAnnotations failed: %v", err)
+	}
+
+	want := []Annotation{
+		{Type: AnnotationTypeNote, Text: "Check this figure against Q2.", Author: "Alice", PageNumber: 1, BBox: &[4]float64{400, 700, 420, 720}},
+		{Type: AnnotationTypeHighlight, Text: "Key result", Author: "Bob", PageNumber: 1, BBox: &[4]float64{72, 690, 300, 706}},
+		{Type: AnnotationTypeComment, Text: "Note for édition", Author: "Carol", PageNumber: 2, BBox: &[4]float64{300, 500, 500, 540}},
+	}
+	if !reflect.DeepEqual(result.Annotations, want) {
+		t.Fatalf("Annotations = %+v, want %+v", result.Annotations, want)
+	}
+}
+
+// TestAnnotationsFromPDFExtraction verifies annotations end to end on the fixture.
+func TestAnnotationsFromPDFExtraction(t *testing.T) {
+	result, err := ExtractFileSync(getTestFilePath("pdf/annotations.pdf"), NewExtractionConfig(WithExtractAnnotations(true)))
+	if err != nil {
+		t.Skipf("native library unavailable: %v", err)
+	}
+	if len(result.Annotations) != 3 || result.Annotations[0].Author != "Alice" {
+		t.Fatalf("unexpected annotations %+v", result.Annotations)
+	}
+}
+
+// TestAnnotationsDisabledByDefault verifies that annotations stay empty unless requested.
+func TestAnnotationsDisabledByDefault(t *testing.T) {
+	data := buildDocxWithComments(t, `<w:comments xmlns:w="w"><w:comment w:author="A"><w:p><w:r><w:t>x</w:t></w:r></w:p></w:comment></w:comments>`)
+	result := &ExtractionResult{MimeType: mimeTypeDOCX}
+	if err := applyAnnotations(result, NewExtractionConfig(), &documentSource{data: data}); err != nil {
+		t.Fatalf("applyAnnotations failed: %v", err)
+	}
+	if result.Annotations != nil {
+		t.Errorf("expected no annotations, got %+v", result.Annotations)
+	}
+}
@@ -0,0 +1,466 @@
+package kreuzberg
+
+import (
+	"bytes"
+	"image"
+	_ "image/gif"  // register GIF decoding for barcode detection
+	_ "image/jpeg" // register JPEG decoding for barcode detection
+	_ "image/png"  // register PNG decoding for barcode detection
+	"math"
+	"slices"
+	"strings"
+)
+
+// Barcode symbologies reported in Barcode.Type.
+const (
+	BarcodeTypeQR      = "qr"
+	BarcodeTypeCode128 = "code128"
+	BarcodeTypeEAN13   = "ean13"
+)
+
+// barcodeScanLines is the number of rows and of columns sampled per image.
+const barcodeScanLines = 24
+
+// barcodeMinContrast is the minimum luminance range of a scan line worth decoding.
+const barcodeMinContrast = 48
+
+// applyBarcodes fills result.Barcodes when barcode detection is enabled. EAN-13 and Code 128
+// symbols are decoded from image documents and from the images extracted from other
+// documents; QR codes are reported only when the native library detects them.
+func applyBarcodes(result *ExtractionResult, config *ExtractionConfig, src *documentSource) error {
+	if config.DetectBarcodes == nil || !*config.DetectBarcodes {
+		return nil
+	}
+
+	if isBarcodeImageMimeType(result.MimeType) {
+		data, err := src.bytes()
+		if err != nil {
+			return err
+		}
+		// The image is the page, so pixel coordinates locate the barcode on it.
+		result.Barcodes = appendBarcodes(result.Barcodes, scanBarcodes(data), 1, true)
+	}
+	for _, img := range result.Images {
+		if img.IsMask || len(img.Data) == 0 {
+			continue
+		}
+		switch strings.ToLower(img.Format) {
+		case "png", "jpg", "jpeg", "gif":
+		default:
+			continue
+		}
+		page := 1
+		if img.PageNumber != nil {
+			page = *img.PageNumber
+		}
+		result.Barcodes = appendBarcodes(result.Barcodes, scanBarcodes(img.Data), page, false)
+	}
+	return nil
+}
+
+func isBarcodeImageMimeType(mimeType string) bool {
+	switch normalizeMimeType(mimeType) {
+	case "image/png", "image/jpeg", "image/gif":
+		return true
+	default:
+		return false
+	}
+}
+
+// appendBarcodes adds found to barcodes on page, skipping symbols already reported for the
+// page. Bounding boxes are kept only when they are in page coordinates.
+func appendBarcodes(barcodes []Barcode, found []Barcode, page int, keepBBox bool) []Barcode {
+	for _, barcode := range found {
+		duplicate := slices.ContainsFunc(barcodes, func(b Barcode) bool {
+			return b.PageNumber == page && b.Type == barcode.Type && b.Value == barcode.Value
+		})
+		if duplicate {
+			continue
+		}
+		barcode.PageNumber = page
+		if !keepBBox {
+			barcode.BBox = nil
+		}
+		barcodes = append(barcodes, barcode)
+	}
+	return barcodes
+}
+
+// scanBarcodes decodes the 1D barcodes crossed by evenly spaced rows and columns of an
+// image. BBox is in pixels and spans the scan lines that decoded each symbol.
+func scanBarcodes(data []byte) []Barcode {
+	img, _, err := image.Decode(bytes.NewReader(data))
+	if err != nil {
+		return nil
+	}
+	bounds := img.Bounds()
+	width, height := bounds.Dx(), bounds.Dy()
+	if width < 20 || height < 1 {
+		return nil
+	}
+
+	var found []Barcode
+	record := func(kind, value string, bbox [4]float64) {
+		for i := range found {
+			if found[i].Type == kind && found[i].Value == value {
+				box := found[i].BBox
+				box[0], box[1] = min(box[0], bbox[0]), min(box[1], bbox[1])
+				box[2], box[3] = max(box[2], bbox[2]), max(box[3], bbox[3])
+				return
+			}
+		}
+		found = append(found, Barcode{Type: kind, Value: value, BBox: &bbox})
+	}
+
+	line := make([]uint8, max(width, height))
+	for i := range barcodeScanLines {
+		y := bounds.Min.Y + (2*i+1)*height/(2*barcodeScanLines)
+		for x := range width {
+			line[x] = luminance(img, bounds.Min.X+x, y)
+		}
+		for _, hit := range decodeScanLine(line[:width]) {
+			record(hit.kind, hit.value, [4]float64{float64(hit.start), float64(y - bounds.Min.Y), float64(hit.end), float64(y - bounds.Min.Y + 1)})
+		}
+
+		if height < 20 {
+			continue
+		}
+		x := bounds.Min.X + (2*i+1)*width/(2*barcodeScanLines)
+		for y := range height {
+			line[y] = luminance(img, x, bounds.Min.Y+y)
+		}
+		for _, hit := range decodeScanLine(line[:height]) {
+			record(hit.kind, hit.value, [4]float64{float64(x - bounds.Min.X), float64(hit.start), float64(x - bounds.Min.X + 1), float64(hit.end)})
+		}
+	}
+	return found
+}
+
+func luminance(img image.Image, x, y int) uint8 {
+	r, g, b, a := img.At(x, y).RGBA()
+	if a == 0 {
+		// Transparent pixels read as white paper.
+		return 255
+	}
+	return uint8((299*r + 587*g + 114*b) / 1000 >> 8)
+}
+
+// barcodeHit is a symbol decoded from a scan line, spanning pixels [start, end).
+type barcodeHit struct {
+	kind, value string
+	start, end  int
+}
+
+// decodeScanLine binarizes a line of luminance values and decodes the symbols it crosses in
+// either direction.
+func decodeScanLine(line []uint8) []barcodeHit {
+	lo, hi := slices.Min(line), slices.Max(line)
+	if int(hi)-int(lo) < barcodeMinContrast {
+		return nil
+	}
+	threshold := (int(lo) + int(hi)) / 2
+
+	// runs alternate between light and dark, starting with light.
+	var runs []int
+	dark := false
+	count := 0
+	for _, v := range line {
+		if (int(v) < threshold) != dark {
+			runs = append(runs, count)
+			dark, count = !dark, 0
+		}
+		count++
+	}
+	runs = append(runs, count)
+	if dark {
+		// End on a light run so the reversed line also starts light.
+		runs = append(runs, 0)
+	}
+
+	var hits []barcodeHit
+	for _, reversed := range []bool{false, true} {
+		seq := runs
+		if reversed {
+			seq = slices.Clone(runs)
+			slices.Reverse(seq)
+		}
+		offsets := make([]int, len(seq)+1)
+		for i, run := range seq {
+			offsets[i+1] = offsets[i] + run
+		}
+		// Odd indices are dark runs; a symbol starts with a bar after a light quiet zone.
+		for i := 1; i < len(seq); i += 2 {
+			if seq[i] == 0 {
+				continue
+			}
+			for _, decode := range []func([]int, int) (string, string, int, bool){decodeEAN13, decodeCode128} {
+				kind, value, n, ok := decode(seq, i)
+				if !ok {
+					continue
+				}
+				start, end := offsets[i], offsets[i+n]
+				if reversed {
+					start, end = len(line)-end, len(line)-start
+				}
+				hits = append(hits, barcodeHit{kind: kind, value: value, start: start, end: end})
+				i += n - 1
+				break
+			}
+		}
+	}
+	return hits
+}
+
+// matchPattern returns the index of the pattern in patterns closest to runs, each run
+// measured in modules of total/modules pixels, or -1 when none is close enough.
+func matchPattern(runs []int, patterns [][]int, modules int) int {
+	total := 0
+	for _, run := range runs {
+		total += run
+	}
+	if total == 0 {
+		return -1
+	}
+	unit := float64(total) / float64(modules)
+	best, bestErr := -1, math.MaxFloat64
+	for p, pattern := range patterns {
+		err := 0.0
+		for i, run := range runs {
+			err += math.Abs(float64(run)/unit - float64(pattern[i]))
+		}
+		if err < bestErr {
+			best, bestErr = p, err
+		}
+	}
+	if bestErr > 0.5*float64(len(runs)) {
+		return -1
+	}
+	return best
+}
+
+// hasQuietZone reports whether the light run before index i is at least modules wide.
+func hasQuietZone(runs []int, i int, unit float64, modules float64) bool {
+	return i > 0 && float64(runs[i-1]) >= modules*unit
+}
+
+// eanDigitPatterns are the L-code run widths (space, bar, space, bar) of digits 0-9. R-codes
+// have the same widths with bars and spaces swapped, and G-codes are the L-codes reversed.
+var eanDigitPatterns = [][]int{
+	{3, 2, 1, 1}, {2, 2, 2, 1}, {2, 1, 2, 2}, {1, 4, 1, 1}, {1, 1, 3, 2},
+	{1, 2, 3, 1}, {1, 1, 1, 4}, {1, 3, 1, 2}, {1, 2, 1, 3}, {3, 1, 1, 2},
+}
+
+// eanPatterns lists the L-codes followed by the G-codes.
+var eanPatterns = func() [][]int {
+	patterns := slices.Clone(eanDigitPatterns)
+	for _, p := range eanDigitPatterns {
+		g := slices.Clone(p)
+		slices.Reverse(g)
+		patterns = append(patterns, g)
+	}
+	return patterns
+}()
+
+// eanFirstDigitParity maps the L/G parity of the six left digits (bit set for G, first
+// digit in the highest bit) to the implied leading digit.
+var eanFirstDigitParity = map[int]int{
+	0b000000: 0, 0b001011: 1, 0b001101: 2, 0b001110: 3, 0b010011: 4,
+	0b011001: 5, 0b011100: 6, 0b010101: 7, 0b010110: 8, 0b011010: 9,
+}
+
+// decodeEAN13 decodes an EAN-13 symbol whose start guard begins at runs[i]. It returns the
+// number of runs consumed.
+func decodeEAN13(runs []int, i int) (string, string, int, bool) {
+	const symbolRuns = 59
+	if i+symbolRuns > len(runs) {
+		return "", "", 0, false
+	}
+	symbol := runs[i : i+symbolRuns]
+	total := 0
+	for _, run := range symbol {
+		total += run
+	}
+	unit := float64(total) / 95
+	if !hasQuietZone(runs, i, unit, 5) || !isGuard(symbol[0:3], unit) || !isGuard(symbol[27:32], unit) || !isGuard(symbol[56:59], unit) {
+		return "", "", 0, false
+	}
+
+	digits := make([]byte, 13)
+	parity := 0
+	for d := range 6 {
+		p := matchPattern(symbol[3+4*d:7+4*d], eanPatterns, 7)
+		if p < 0 {
+			return "", "", 0, false
+		}
+		digits[1+d] = byte('0' + p%10)
+		parity <<= 1
+		if p >= 10 {
+			parity |= 1
+		}
+	}
+	first, ok := eanFirstDigitParity[parity]
+	if !ok {
+		return "", "", 0, false
+	}
+	digits[0] = byte('0' + first)
+	for d := range 6 {
+		p := matchPattern(symbol[32+4*d:36+4*d], eanDigitPatterns, 7)
+		if p < 0 {
+			return "", "", 0, false
+		}
+		digits[7+d] = byte('0' + p)
+	}
+
+	sum := 0
+	for d, c := range digits[:12] {
+		weight := 1
+		if d%2 == 1 {
+			weight = 3
+		}
+		sum += weight * int(c-'0')
+	}
+	if (10-sum%10)%10 != int(digits[12]-'0') {
+		return "", "", 0, false
+	}
+	return BarcodeTypeEAN13, string(digits), symbolRuns, true
+}
+
+// isGuard reports whether every run of a guard pattern is about one module wide.
+func isGuard(runs []int, unit float64) bool {
+	for _, run := range runs {
+		if math.Abs(float64(run)/unit-1) > 0.6 {
+			return false
+		}
+	}
+	return true
+}
+
+// code128Patterns are the run widths (bar, space, ...) of the Code 128 symbol values 0-105.
+var code128Patterns = [][]int{
+	{2, 1, 2, 2, 2, 2}, {2, 2, 2, 1, 2, 2}, {2, 2, 2, 2, 2, 1}, {1, 2, 1, 2, 2, 3}, {1, 2, 1, 3, 2, 2},
+	{1, 3, 1, 2, 2, 2}, {1, 2, 2, 2, 1, 3}, {1, 2, 2, 3, 1, 2}, {1, 3, 2, 2, 1, 2}, {2, 2, 1, 2, 1, 3},
+	{2, 2, 1, 3, 1, 2}, {2, 3, 1, 2, 1, 2}, {1, 1, 2, 2, 3, 2}, {1, 2, 2, 1, 3, 2}, {1, 2, 2, 2, 3, 1},
+	{1, 1, 3, 2, 2, 2}, {1, 2, 3, 1, 2, 2}, {1, 2, 3, 2, 2, 1}, {2, 2, 3, 2, 1, 1}, {2, 2, 1, 1, 3, 2},
+	{2, 2, 1, 2, 3, 1}, {2, 1, 3, 2, 1, 2}, {2, 2, 3, 1, 1, 2}, {3, 1, 2, 1, 3, 1}, {3, 1, 1, 2, 2, 2},
+	{3, 2, 1, 1, 2, 2}, {3, 2, 1, 2, 2, 1}, {3, 1, 2, 2, 1, 2}, {3, 2, 2, 1, 1, 2}, {3, 2, 2, 2, 1, 1},
+	{2, 1, 2, 1, 2, 3}, {2, 1, 2, 3, 2, 1}, {2, 3, 2, 1, 2, 1}, {1, 1, 1, 3, 2, 3}, {1, 3, 1, 1, 2, 3},
+	{1, 3, 1, 3, 2, 1}, {1, 1, 2, 3, 1, 3}, {1, 3, 2, 1, 1, 3}, {1, 3, 2, 3, 1, 1}, {2, 1, 1, 3, 1, 3},
+	{2, 3, 1, 1, 1, 3}, {2, 3, 1, 3, 1, 1}, {1, 1, 2, 1, 3, 3}, {1, 1, 2, 3, 3, 1}, {1, 3, 2, 1, 3, 1},
+	{1, 1, 3, 1, 2, 3}, {1, 1, 3, 3, 2, 1}, {1, 3, 3, 1, 2, 1}, {3, 1, 3, 1, 2, 1}, {2, 1, 1, 3, 3, 1},
+	{2, 3, 1, 1, 3, 1}, {2, 1, 3, 1, 1, 3}, {2, 1, 3, 3, 1, 1}, {2, 1, 3, 1, 3, 1}, {3, 1, 1, 1, 2, 3},
+	{3, 1, 1, 3, 2, 1}, {3, 3, 1, 1, 2, 1}, {3, 1, 2, 1, 1, 3}, {3, 1, 2, 3, 1, 1}, {3, 3, 2, 1, 1, 1},
+	{3, 1, 4, 1, 1, 1}, {2, 2, 1, 4, 1, 1}, {4, 3, 1, 1, 1, 1}, {1, 1, 1, 2, 2, 4}, {1, 1, 1, 4, 2, 2},
+	{1, 2, 1, 1, 2, 4}, {1, 2, 1, 4, 2, 1}, {1, 4, 1, 1, 2, 2}, {1, 4, 1, 2, 2, 1}, {1, 1, 2, 2, 1, 4},
+	{1, 1, 2, 4, 1, 2}, {1, 2, 2, 1, 1, 4}, {1, 2, 2, 4, 1, 1}, {1, 4, 2, 1, 1, 2}, {1, 4, 2, 2, 1, 1},
+	{2, 4, 1, 2, 1, 1}, {2, 2, 1, 1, 1, 4}, {4, 1, 3, 1, 1, 1}, {2, 4, 1, 1, 1, 2}, {1, 3, 4, 1, 1, 1},
+	{1, 1, 1, 2, 4, 2}, {1, 2, 1, 1, 4, 2}, {1, 2, 1, 2, 4, 1}, {1, 1, 4, 2, 1, 2}, {1, 2, 4, 1, 1, 2},
+	{1, 2, 4, 2, 1, 1}, {4, 1, 1, 2, 1, 2}, {4, 2, 1, 1, 1, 2}, {4, 2, 1, 2, 1, 1}, {2, 1, 2, 1, 4, 1},
+	{2, 1, 4, 1, 2, 1}, {4, 1, 2, 1, 2, 1}, {1, 1, 1, 1, 4, 3}, {1, 1, 1, 3, 4, 1}, {1, 3, 1, 1, 4, 1},
+	{1, 1, 4, 1, 1, 3}, {1, 1, 4, 3, 1, 1}, {4, 1, 1, 1, 1, 3}, {4, 1, 1, 3, 1, 1}, {1, 1, 3, 1, 4, 1},
+	{1, 1, 4, 1, 3, 1}, {3, 1, 1, 1, 4, 1}, {4, 1, 1, 1, 3, 1}, {2, 1, 1, 4, 1, 2}, {2, 1, 1, 2, 1, 4},
+	{2, 1, 1, 2, 3, 2},
+}
+
+var code128Stop = []int{2, 3, 3, 1, 1, 1, 2}
+
+// Code 128 special symbol values.
+const (
+	code128Shift  = 98
+	code128CodeC  = 99
+	code128CodeB  = 100
+	code128CodeA  = 101
+	code128StartA = 103
+	code128StartB = 104
+	code128StartC = 105
+)
+
+// decodeCode128 decodes a Code 128 symbol whose start character begins at runs[i]. It
+// returns the number of runs consumed.
+func decodeCode128(runs []int, i int) (string, string, int, bool) {
+	if i+6 > len(runs) {
+		return "", "", 0, false
+	}
+	start := matchPattern(runs[i:i+6], code128Patterns, 11)
+	if start < code128StartA {
+		return "", "", 0, false
+	}
+	width := 0
+	for _, run := range runs[i : i+6] {
+		width += run
+	}
+	unit := float64(width) / 11
+	if !hasQuietZone(runs, i, unit, 5) {
+		return "", "", 0, false
+	}
+
+	values := []int{start}
+	pos := i + 6
+	for {
+		if pos+7 <= len(runs) && isCode128Stop(runs[pos:pos+7], unit) {
+			pos += 7
+			break
+		}
+		if pos+6 > len(runs) {
+			return "", "", 0, false
+		}
+		value := matchPattern(runs[pos:pos+6], code128Patterns, 11)
+		if value < 0 || value >= code128StartA {
+			return "", "", 0, false
+		}
+		values = append(values, value)
+		pos += 6
+	}
+	if len(values) < 3 {
+		return "", "", 0, false
+	}
+
+	checksum := values[0]
+	for k, value := range values[1 : len(values)-1] {
+		checksum += (k + 1) * value
+	}
+	if checksum%103 != values[len(values)-1] {
+		return "", "", 0, false
+	}
+
+	var out strings.Builder
+	set := start
+	shift := false
+	for _, value := range values[1 : len(values)-1] {
+		current := set
+		if shift {
+			// A shift switches between sets A and B for one character.
+			current = code128StartA + code128StartB - set
+			shift = false
+		}
+		switch {
+		case current == code128StartC && value < 100:
+			out.WriteByte(byte('0' + value/10))
+			out.WriteByte(byte('0' + value%10))
+		case value == code128CodeC && current != code128StartC:
+			set = code128StartC
+		case value == code128CodeB && current != code128StartB:
+			set = code128StartB
+		case value == code128CodeA && current != code128StartA:
+			set = code128StartA
+		case value == code128Shift && current != code128StartC:
+			shift = true
+		case value >= 96:
+			// FNC1-FNC4 carry no text.
+		case current == code128StartA && value >= 64:
+			out.WriteByte(byte(value - 64))
+		default:
+			out.WriteByte(byte(value + 32))
+		}
+	}
+	return BarcodeTypeCode128, out.String(), pos - i, true
+}
+
+// isCode128Stop reports whether runs are the stop pattern drawn at the symbol's module width.
+func isCode128Stop(runs []int, unit float64) bool {
+	for k, run := range runs {
+		if math.Abs(float64(run)/unit-float64(code128Stop[k])) > 0.6 {
+			return false
+		}
+	}
+	return true
+}
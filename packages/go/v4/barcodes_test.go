@@ -0,0 +1,160 @@
+package kreuzberg
+
+import (
+	"bytes"
+	"image"
+	"image/color"
+	"image/png"
+	"slices"
+	"testing"
+)
+
+// testEAN13Runs returns the run widths, starting with a bar, of the EAN-13 symbol for digits.
+func testEAN13Runs(digits string) []int {
+	parity := 0
+	for code, first := range eanFirstDigitParity {
+		if first == int(digits[0]-'0') {
+			parity = code
+		}
+	}
+	runs := []int{1, 1, 1}
+	for d := range 6 {
+		pattern := eanDigitPatterns[digits[1+d]-'0']
+		if parity&(1<<(5-d)) != 0 {
+			pattern = eanPatterns[10+int(digits[1+d]-'0')]
+		}
+		runs = append(runs, pattern...)
+	}
+	runs = append(runs, 1, 1, 1, 1, 1)
+	for d := range 6 {
+		runs = append(runs, eanDigitPatterns[digits[7+d]-'0']...)
+	}
+	return append(runs, 1, 1, 1)
+}
+
+// testCode128Runs returns the run widths, starting with a bar, of text encoded in code set B.
+func testCode128Runs(text string) []int {
+	values := []int{code128StartB}
+	for _, c := range text {
+		values = append(values, int(c)-32)
+	}
+	checksum := values[0]
+	for k, value := range values[1:] {
+		checksum += (k + 1) * value
+	}
+	values = append(values, checksum%103)
+
+	var runs []int
+	for _, value := range values {
+		runs = append(runs, code128Patterns[value]...)
+	}
+	return append(runs, code128Stop...)
+}
+
+// testBarcodePNG draws runs as vertical bars of module pixels with a quiet zone of ten
+// modules on both sides, and returns the PNG with the x range covered by the symbol.
+func testBarcodePNG(t *testing.T, runs []int, module int, reversed bool) ([]byte, int, int) {
+	t.Helper()
+	var modules []bool
+	for k, run := range runs {
+		for range run {
+			modules = append(modules, k%2 == 0)
+		}
+	}
+	if reversed {
+		slices.Reverse(modules)
+	}
+	quiet := 10 * module
+	width := 2*quiet + len(modules)*module
+	img := image.NewGray(image.Rect(0, 0, width, 60))
+	for y := range 60 {
+		for x := range width {
+			img.SetGray(x, y, color.Gray{Y: 255})
+			m := (x - quiet) / module
+			if x >= quiet && m < len(modules) && modules[m] && y >= 10 && y < 50 {
+				img.SetGray(x, y, color.Gray{Y: 20})
+			}
+		}
+	}
+	var buf bytes.Buffer
+	if err := png.Encode(&buf, img); err != nil {
+		t.Fatalf("encode png: %v", err)
+	}
+	return buf.Bytes(), quiet, quiet + len(modules)*module
+}
+
+func TestBarcodes(t *testing.T) {
+	cases := []struct {
+		name, kind, value string
+		runs              []int
+		reversed          bool
+	}{
+		{"ean13", BarcodeTypeEAN13, "4006381333931", testEAN13Runs("4006381333931"), false},
+		{"code128", BarcodeTypeCode128, "Kreuzberg-42", testCode128Runs("Kreuzberg-42"), false},
+		{"upside down", BarcodeTypeCode128, "Kreuzberg-42", testCode128Runs("Kreuzberg-42"), true},
+	}
+	config := NewExtractionConfig(WithDetectBarcodes(true))
+	for _, tc := range cases {
+		t.Run(tc.name, func(t *testing.T) {
+			data, left, right := testBarcodePNG(t, tc.runs, 3, tc.reversed)
+			result := &ExtractionResult{MimeType: "image/png"}
+			if err := applyBarcodes(result, config, &documentSource{data: data}); err != nil {
+				t.Fatalf("applyBarcodes: %v", err)
+			}
+			if len(result.Barcodes) != 1 {
+				t.Fatalf("expected one barcode, got %+v", result.Barcodes)
+			}
+			got := result.Barcodes[0]
+			if got.Type != tc.kind || got.Value != tc.value || got.PageNumber != 1 {
+				t.Fatalf("unexpected barcode %+v", got)
+			}
+			if got.BBox == nil {
+				t.Fatal("expected a bounding box")
+			}
+			if box := *got.BBox; box[0] != float64(left) || box[2] != float64(right) || box[1] < 10 || box[3] > 50 {
+				t.Fatalf("bbox = %v, want x from %d to %d within rows 10-50", box, left, right)
+			}
+		})
+	}
+}
+
+func TestBarcodesInExtractedImages(t *testing.T) {
+	data, _, _ := testBarcodePNG(t, testEAN13Runs("4006381333931"), 2, false)
+	page := 2
+	result := &ExtractionResult{
+		MimeType: "application/pdf",
+		Images: []ExtractedImage{
+			{Data: data, Format: "png", PageNumber: &page},
+			{Data: data, Format: "PNG", PageNumber: &page},
+		},
+	}
+	if err := applyBarcodes(result, NewExtractionConfig(WithDetectBarcodes(true)), &documentSource{}); err != nil {
+		t.Fatalf("applyBarcodes: %v", err)
+	}
+	want := []Barcode{{Type: BarcodeTypeEAN13, Value: "4006381333931", PageNumber: 2}}
+	if !slices.Equal(result.Barcodes, want) {
+		t.Fatalf("Barcodes = %+v, want %+v", result.Barcodes, want)
+	}
+}
+
+func TestBarcodesDisabled(t *testing.T) {
+	data, _, _ := testBarcodePNG(t, testCode128Runs("Kreuzberg-42"), 3, false)
+	for _, config := range []*ExtractionConfig{{}, NewExtractionConfig(WithDetectBarcodes(false))} {
+		result := &ExtractionResult{MimeType: "image/png"}
+		if err := applyBarcodes(result, config, &documentSource{data: data}); err != nil {
+			t.Fatalf("applyBarcodes: %v", err)
+		}
+		if result.Barcodes != nil {
+			t.Fatalf("expected no barcodes, got %+v", result.Barcodes)
+		}
+	}
+
+	blank, _, _ := testBarcodePNG(t, nil, 3, false)
+	result := &ExtractionResult{MimeType: "image/png"}
+	if err := applyBarcodes(result, NewExtractionConfig(WithDetectBarcodes(true)), &documentSource{data: blank}); err != nil {
+		t.Fatalf("applyBarcodes: %v", err)
+	}
+	if len(result.Barcodes) != 0 {
+		t.Fatalf("expected no barcodes in a blank image, got %+v", result.Barcodes)
+	}
+}
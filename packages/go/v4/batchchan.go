@@ -0,0 +1,65 @@
+package kreuzberg
+
+import (
+	"context"
+	"runtime"
+	"sync"
+)
+
+// BatchExtractFilesFromChan extracts the files whose paths arrive on paths as they arrive,
+// for long-running pipelines such as a directory watcher, and sends one BatchResult per path
+// on the returned channel in the order extractions finish. Index is the position of the path
+// in the order it was received. Up to runtime.GOMAXPROCS(0) files are extracted at once; a
+// failing file is reported in its BatchResult and does not stop the others.
+//
+// The returned channel is closed once paths is closed and every received path has been
+// reported, or once ctx ends and the extractions in progress have returned. After ctx ends,
+// paths are no longer read and results not yet received are discarded, so callers may stop
+// reading then without leaking goroutines. Until then the caller must keep receiving.
+func BatchExtractFilesFromChan(ctx context.Context, paths <-chan string, config *ExtractionConfig) <-chan BatchResult {
+	results := make(chan BatchResult)
+	jobs := make(chan BatchResult)
+	var wg sync.WaitGroup
+	for range runtime.GOMAXPROCS(0) {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for job := range jobs {
+				if ctx.Err() != nil {
+					continue
+				}
+				job.Result, job.Err = extractFile(ctx, job.Path, config)
+				select {
+				case results <- job:
+				case <-ctx.Done():
+				}
+			}
+		}()
+	}
+
+	go func() {
+		defer func() {
+			close(jobs)
+			wg.Wait()
+			close(results)
+		}()
+		for index := 0; ; index++ {
+			var path string
+			select {
+			case <-ctx.Done():
+				return
+			case p, ok := <-paths:
+				if !ok {
+					return
+				}
+				path = p
+			}
+			select {
+			case jobs <- BatchResult{Index: index, Path: path}:
+			case <-ctx.Done():
+				return
+			}
+		}
+	}()
+	return results
+}
@@ -0,0 +1,125 @@
+package kreuzberg
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"path/filepath"
+	"runtime"
+	"testing"
+	"time"
+)
+
+// registerChanTestExtractor routes .txt files to a Go extractor echoing their content, so
+// that the tests do not depend on the native library.
+func registerChanTestExtractor(t *testing.T) *ExtractionConfig {
+	t.Helper()
+	if err := RegisterExtractor(testExtractorMime, func(data []byte, _ *ExtractionConfig) (*ExtractionResult, error) {
+		return &ExtractionResult{Content: string(data)}, nil
+	}); err != nil {
+		t.Fatalf("RegisterExtractor failed: %v", err)
+	}
+	t.Cleanup(func() { _ = UnregisterExtractor(testExtractorMime) })
+	return NewExtractionConfig(WithMimeOverrides(map[string]string{".txt": testExtractorMime}))
+}
+
+// waitForGoroutines fails the test when the goroutine count does not return to initial.
+func waitForGoroutines(t *testing.T, initial int) {
+	t.Helper()
+	leaked := 0
+	for range 50 {
+		runtime.GC()
+		leaked = runtime.NumGoroutine() - initial
+		if leaked <= 0 {
+			return
+		}
+		time.Sleep(10 * time.Millisecond)
+	}
+	t.Errorf("goroutine leak detected: %d goroutines left running", leaked)
+}
+
+func TestBatchExtractFilesFromChan(t *testing.T) {
+	config := registerChanTestExtractor(t)
+	dir := t.TempDir()
+	initial := runtime.NumGoroutine()
+
+	paths := make(chan string)
+	results := BatchExtractFilesFromChan(context.Background(), paths, config)
+	const n = 20
+	go func() {
+		for i := range n {
+			path := filepath.Join(dir, fmt.Sprintf("doc%d.txt", i))
+			if i != 7 {
+				if err := os.WriteFile(path, []byte(fmt.Sprintf("document %d", i)), 0o600); err != nil {
+					panic(err)
+				}
+			}
+			paths <- path
+		}
+		close(paths)
+	}()
+
+	seen := make(map[int]bool)
+	for res := range results {
+		if seen[res.Index] {
+			t.Fatalf("index %d reported twice", res.Index)
+		}
+		seen[res.Index] = true
+		if want := filepath.Join(dir, fmt.Sprintf("doc%d.txt", res.Index)); res.Path != want {
+			t.Errorf("result %d has path %q, want %q", res.Index, res.Path, want)
+		}
+		if res.Index == 7 {
+			if res.Err == nil {
+				t.Error("missing file reported no error")
+			}
+			continue
+		}
+		if res.Err != nil || res.Result.Content != fmt.Sprintf("document %d", res.Index) {
+			t.Errorf("result %d: %+v", res.Index, res)
+		}
+	}
+	if len(seen) != n {
+		t.Fatalf("got %d results, want %d", len(seen), n)
+	}
+	waitForGoroutines(t, initial)
+}
+
+func TestBatchExtractFilesFromChanCancel(t *testing.T) {
+	config := registerChanTestExtractor(t)
+	path := filepath.Join(t.TempDir(), "doc.txt")
+	if err := os.WriteFile(path, []byte("document"), 0o600); err != nil {
+		t.Fatal(err)
+	}
+	initial := runtime.NumGoroutine()
+
+	ctx, cancel := context.WithCancel(context.Background())
+	paths := make(chan string, 4)
+	results := BatchExtractFilesFromChan(ctx, paths, config)
+	paths <- path
+	if res := <-results; res.Err != nil {
+		t.Fatalf("extraction failed: %v", res.Err)
+	}
+	// paths is never closed: cancelling ctx alone must shut the pipeline down, even with
+	// paths still queued.
+	paths <- path
+	paths <- path
+	cancel()
+
+	select {
+	case <-waitClosed(results):
+	case <-time.After(5 * time.Second):
+		t.Fatal("results not closed after ctx was cancelled")
+	}
+	waitForGoroutines(t, initial)
+}
+
+// waitClosed drains results in the background and returns a channel closed with it.
+func waitClosed(results <-chan BatchResult) <-chan struct{} {
+	done := make(chan struct{})
+	go func() {
+		for range results {
+		}
+		close(done)
+	}()
+	return done
+}
@@ -0,0 +1,329 @@
+package kreuzberg
+
+import (
+	"strings"
+	"unicode"
+	"unicode/utf8"
+)
+
+// bidiClass is the simplified bidirectional character type reorderBidiLine works with.
+type bidiClass uint8
+
+const (
+	bidiNeutral bidiClass = iota
+	bidiLTR
+	bidiRTL
+	bidiNumber
+)
+
+// rtlScripts are the scripts written right to left, plus the Hebrew and Arabic
+// presentation forms PDF fonts often map glyphs to.
+var rtlScripts = []*unicode.RangeTable{
+	unicode.Hebrew, unicode.Arabic, unicode.Syriac, unicode.Thaana, unicode.Nko,
+	{R16: []unicode.Range16{{Lo: 0xfb1d, Hi: 0xfdff, Stride: 1}, {Lo: 0xfe70, Hi: 0xfeff, Stride: 1}}},
+}
+
+// applyBidi records the dominant direction of Content in Metadata.DominantTextDirection
+// and, when ExtractionConfig.Bidi is set, puts the text of result in the selected order.
+// Without it the text is left in the order the extraction produced. BidiLogical treats
+// PDF text as visual order, the order it is drawn on the page, and BidiVisual treats text
+// from the other formats as logical order. Lines are reordered one at a time, so offsets of
+// lines, pages and parts into Content stay valid, and chunk ranges and text positions are
+// moved with the text they cover.
+func applyBidi(result *ExtractionResult, config *ExtractionConfig) {
+	if direction := dominantTextDirection(result.Content); direction != "" {
+		result.Metadata.DominantTextDirection = &direction
+	}
+	if config.Bidi == nil {
+		return
+	}
+	if (*config.Bidi == BidiLogical) != isPDFMimeType(result.MimeType) {
+		return
+	}
+
+	content := result.Content
+	reordered, offsets := reorderBidi(content)
+	if reordered != content {
+		span := func(start, end int) (int, int) {
+			if start >= end || end > len(content) {
+				return offsets[min(start, len(content))], offsets[min(end, len(content))]
+			}
+			newStart, newEnd := len(reordered), 0
+			for i := start; i < end; i++ {
+				newStart, newEnd = min(newStart, offsets[i]), max(newEnd, offsets[i]+1)
+			}
+			return newStart, newEnd
+		}
+		for i := range result.Chunks {
+			chunk := &result.Chunks[i]
+			start, end := int(chunk.Metadata.ByteStart), int(chunk.Metadata.ByteEnd)
+			verbatim := start <= end && end <= len(content) && content[start:end] == chunk.Content
+			start, end = span(start, end)
+			chunk.Metadata.ByteStart, chunk.Metadata.ByteEnd = uint64(start), uint64(end)
+			if verbatim {
+				chunk.Content = reordered[start:end]
+			} else {
+				chunk.Content, _ = reorderBidi(chunk.Content)
+			}
+		}
+		if result.textPositionsContent == content {
+			for i := range result.textPositions {
+				pos := &result.textPositions[i]
+				pos.start, pos.end = span(pos.start, pos.end)
+			}
+			result.textPositionsContent = reordered
+		}
+		result.Content = reordered
+	}
+	for i := range result.Pages {
+		result.Pages[i].Content, _ = reorderBidi(result.Pages[i].Content)
+	}
+
+	// Page tables may share their cells with result.Tables; each row is reordered once.
+	seen := make(map[*string]bool)
+	reorderTable := func(table *Table) {
+		changed := false
+		for _, row := range table.Cells {
+			if len(row) == 0 || seen[&row[0]] {
+				continue
+			}
+			seen[&row[0]] = true
+			for col, cell := range row {
+				if reordered, _ := reorderBidi(cell); reordered != cell {
+					row[col] = reordered
+					changed = true
+				}
+			}
+		}
+		if changed {
+			table.Markdown = tableMarkdown(table.Cells)
+		}
+	}
+	for i := range result.Tables {
+		reorderTable(&result.Tables[i])
+	}
+	for i := range result.Pages {
+		for j := range result.Pages[i].Tables {
+			reorderTable(&result.Pages[i].Tables[j])
+		}
+	}
+}
+
+// dominantTextDirection returns TextDirectionRTL when text has more right-to-left letters
+// than left-to-right ones, TextDirectionLTR when it has more left-to-right letters, and ""
+// when it has no letters.
+func dominantTextDirection(text string) string {
+	ltr, rtl := 0, 0
+	for _, r := range text {
+		switch classifyBidi(r) {
+		case bidiLTR:
+			ltr++
+		case bidiRTL:
+			rtl++
+		}
+	}
+	switch {
+	case rtl > ltr:
+		return TextDirectionRTL
+	case ltr > 0:
+		return TextDirectionLTR
+	default:
+		return ""
+	}
+}
+
+// reorderBidi converts each line of s between logical and visual order; the conversion is
+// its own inverse. offsets[i] is the position in the result of byte i of s, and offsets has
+// len(s)+1 entries.
+func reorderBidi(s string) (string, []int) {
+	offsets := make([]int, len(s)+1)
+	for i := range offsets {
+		offsets[i] = i
+	}
+	if strings.IndexFunc(s, func(r rune) bool { return classifyBidi(r) == bidiRTL }) < 0 {
+		return s, offsets
+	}
+	out := []byte(s)
+	for start := 0; start < len(s); {
+		end := len(s)
+		if i := strings.IndexByte(s[start:], '\n'); i >= 0 {
+			end = start + i
+		}
+		lineEnd := end
+		if lineEnd > start && s[lineEnd-1] == '\r' {
+			lineEnd--
+		}
+		reorderBidiLine(s[start:lineEnd], out[start:lineEnd], offsets[start:lineEnd], start)
+		start = end + 1
+	}
+	return string(out), offsets
+}
+
+// reorderBidiLine writes line, reordered, to out and the position of each of its bytes to
+// offsets, relative to base. It is a simplified form of the Unicode bidirectional
+// algorithm: the line's direction is that of the majority of its letters, right-to-left
+// letters get embedding level 1, left-to-right letters and numbers get the next even
+// level, numbers within right-to-left text count as right-to-left, neutrals between text of
+// one direction take that direction and other neutrals that of the line, and runs of each level are then reversed from the highest level down.
+// Every rule looks at both neighbours alike, so reordering the result restores line.
+func reorderBidiLine(line string, out []byte, offsets []int, base int) {
+	if !utf8.ValidString(line) {
+		return
+	}
+	runes := []rune(line)
+	classes := make([]bidiClass, len(runes))
+	ltr, rtl := 0, 0
+	for i, r := range runes {
+		switch {
+		case unicode.Is(unicode.Mn, r) && i > 0:
+			classes[i] = classes[i-1]
+		default:
+			classes[i] = classifyBidi(r)
+		}
+		switch classes[i] {
+		case bidiLTR:
+			ltr++
+		case bidiRTL:
+			rtl++
+		}
+	}
+	if rtl == 0 {
+		return
+	}
+	// A single separator between digits belongs to the number, as in "1,234.5".
+	for i := 1; i+1 < len(runes); i++ {
+		if classes[i-1] == bidiNumber && classes[i+1] == bidiNumber && strings.ContainsRune(",.:/+-", runes[i]) {
+			classes[i] = bidiNumber
+		}
+	}
+
+	lineLevel := 0
+	if rtl > ltr {
+		lineLevel = 1
+	}
+	strongLevel := map[bidiClass]int{bidiRTL: 1, bidiLTR: lineLevel + lineLevel%2}
+	levels := make([]int, len(runes))
+	for i, class := range classes {
+		if class != bidiNeutral && class != bidiNumber {
+			levels[i] = strongLevel[class]
+		}
+	}
+	// letterAt returns the class of the nearest letter from i in steps of step, or the
+	// line's direction at the end of the line.
+	letterAt := func(i, step int) bidiClass {
+		for ; i >= 0 && i < len(runes); i += step {
+			if classes[i] == bidiLTR || classes[i] == bidiRTL {
+				return classes[i]
+			}
+		}
+		if lineLevel == 1 {
+			return bidiRTL
+		}
+		return bidiLTR
+	}
+	// A number is part of the right-to-left text around it unless it is surrounded by
+	// left-to-right letters; in a left-to-right line it must be surrounded by right-to-left
+	// letters.
+	numberRTL := make([]bool, len(runes))
+	for i := 0; i < len(runes); {
+		j := i + 1
+		for j < len(runes) && classes[j] == classes[i] {
+			j++
+		}
+		if classes[i] == bidiNumber {
+			before, after := letterAt(i-1, -1), letterAt(j, 1)
+			rtl := before == bidiRTL && after == bidiRTL
+			if lineLevel == 1 {
+				rtl = before == bidiRTL || after == bidiRTL
+			}
+			for k := i; k < j; k++ {
+				numberRTL[k] = rtl
+				levels[k] = strongLevel[bidiLTR]
+				if rtl {
+					levels[k] = 2
+				}
+			}
+		}
+		i = j
+	}
+	// strongAt returns the direction of the nearest letter or number from i in steps of
+	// step, or bidiNeutral at the end of the line.
+	strongAt := func(i, step int) bidiClass {
+		for ; i >= 0 && i < len(runes); i += step {
+			switch classes[i] {
+			case bidiNumber:
+				if numberRTL[i] {
+					return bidiRTL
+				}
+				return bidiLTR
+			case bidiLTR, bidiRTL:
+				return classes[i]
+			}
+		}
+		return bidiNeutral
+	}
+	for i := 0; i < len(runes); {
+		j := i + 1
+		for j < len(runes) && classes[j] == classes[i] {
+			j++
+		}
+		if classes[i] == bidiNeutral {
+			level := lineLevel
+			if before, after := strongAt(i-1, -1), strongAt(j, 1); before == after && before != bidiNeutral {
+				level = strongLevel[before]
+			}
+			for k := i; k < j; k++ {
+				levels[k] = level
+			}
+		}
+		i = j
+	}
+
+	order := make([]int, len(runes))
+	for i := range order {
+		order[i] = i
+	}
+	for level := 2; level >= 1; level-- {
+		for i := 0; i < len(order); {
+			if levels[order[i]] < level {
+				i++
+				continue
+			}
+			j := i
+			for j < len(order) && levels[order[j]] >= level {
+				j++
+			}
+			for a, b := i, j-1; a < b; a, b = a+1, b-1 {
+				order[a], order[b] = order[b], order[a]
+			}
+			i = j
+		}
+	}
+
+	runeStarts := make([]int, len(runes)+1)
+	for i, r := range runes {
+		runeStarts[i+1] = runeStarts[i] + utf8.RuneLen(r)
+	}
+	written := 0
+	for _, i := range order {
+		n := utf8.EncodeRune(out[written:], runes[i])
+		for k := range n {
+			offsets[runeStarts[i]+k] = base + written + k
+		}
+		written += n
+	}
+}
+
+// classifyBidi returns the simplified bidirectional type of r.
+func classifyBidi(r rune) bidiClass {
+	switch {
+	case unicode.IsDigit(r):
+		return bidiNumber
+	case unicode.In(r, rtlScripts...) && (unicode.IsLetter(r) || unicode.IsMark(r)):
+		return bidiRTL
+	case unicode.IsLetter(r):
+		return bidiLTR
+	default:
+		return bidiNeutral
+	}
+}
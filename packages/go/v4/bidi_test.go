@@ -0,0 +1,104 @@
+package kreuzberg
+
+import (
+	"errors"
+	"strings"
+	"testing"
+)
+
+// reverseRunes returns s with its runes in reverse order.
+func reverseRunes(s string) string {
+	runes := []rune(s)
+	for i, j := 0, len(runes)-1; i < j; i, j = i+1, j-1 {
+		runes[i], runes[j] = runes[j], runes[i]
+	}
+	return string(runes)
+}
+
+func TestApplyBidiArabicPDF(t *testing.T) {
+	const hello, world = "مرحبا", "بالعالم"
+	logical := hello + " " + world + " 2024\nPage 1"
+	// A PDF draws the line from left to right: the year first, then the words last to first,
+	// each with its letters reversed.
+	visual := "2024 " + reverseRunes(world) + " " + reverseRunes(hello) + "\nPage 1"
+	result := &ExtractionResult{
+		MimeType: "application/pdf",
+		Content:  visual,
+		Chunks:   []Chunk{{Content: visual[:strings.IndexByte(visual, ' ')], Metadata: ChunkMetadata{ByteStart: 0, ByteEnd: 4}}},
+		Pages:    []PageContent{{PageNumber: 1, Content: visual}},
+		Tables:   []Table{{Cells: [][]string{{reverseRunes(hello)}}}},
+	}
+	applyBidi(result, NewExtractionConfig())
+	if result.Content != visual || result.Pages[0].Content != visual {
+		t.Fatalf("PDF text reordered without WithBidi: %q", result.Content)
+	}
+	applyBidi(result, NewExtractionConfig(WithBidi(BidiLogical)))
+
+	if result.Content != logical {
+		t.Fatalf("content = %q, want %q", result.Content, logical)
+	}
+	if words := strings.Fields(result.Content); words[0] != hello || words[1] != world {
+		t.Fatalf("unexpected word order %q", words)
+	}
+	chunk := result.Chunks[0]
+	if chunk.Content != "2024" || result.Content[chunk.Metadata.ByteStart:chunk.Metadata.ByteEnd] != "2024" {
+		t.Fatalf("chunk not moved with its text: %+v", chunk)
+	}
+	if result.Pages[0].Content != logical || result.Tables[0].Cells[0][0] != hello || !strings.Contains(result.Tables[0].Markdown, hello) {
+		t.Fatalf("pages or tables not reordered: %+v %+v", result.Pages, result.Tables)
+	}
+	if dir := result.Metadata.DominantTextDirection; dir == nil || *dir != TextDirectionRTL {
+		t.Fatalf("expected rtl dominant direction, got %v", dir)
+	}
+
+	applyBidi(result, NewExtractionConfig(WithBidi(BidiVisual)))
+	if result.Content != logical {
+		t.Fatalf("visual mode changed PDF text: %q", result.Content)
+	}
+}
+
+func TestApplyBidiVisualMode(t *testing.T) {
+	content := "Price: 150 שקל\nplain line"
+	result := &ExtractionResult{MimeType: "text/plain", Content: content}
+	applyBidi(result, NewExtractionConfig(WithBidi(BidiLogical)))
+	if result.Content != content {
+		t.Fatalf("logical text changed in logical mode: %q", result.Content)
+	}
+	if dir := result.Metadata.DominantTextDirection; dir == nil || *dir != TextDirectionLTR {
+		t.Fatalf("expected ltr dominant direction, got %v", dir)
+	}
+
+	applyBidi(result, NewExtractionConfig(WithBidi(BidiVisual)))
+	if want := "Price: 150 " + reverseRunes("שקל") + "\nplain line"; result.Content != want {
+		t.Fatalf("content = %q, want %q", result.Content, want)
+	}
+}
+
+func TestReorderBidiRoundTrip(t *testing.T) {
+	for _, s := range []string{
+		"שלום עולם",
+		"סה\"כ 1,234.50 ₪ (כולל מע\"מ)",
+		"Invoice חשבונית 42 - total",
+		"مرحبا بالعالم: ABC 123، شكرا\r\nsecond line",
+		"plain ASCII only",
+		"",
+	} {
+		reordered, offsets := reorderBidi(s)
+		if len(reordered) != len(s) || len(offsets) != len(s)+1 {
+			t.Fatalf("reordering %q changed its length", s)
+		}
+		if back, _ := reorderBidi(reordered); back != s {
+			t.Errorf("round trip of %q gave %q via %q", s, back, reordered)
+		}
+	}
+}
+
+func TestBidiValidation(t *testing.T) {
+	var valErr *ValidationError
+	if err := validateConfig(NewExtractionConfig(WithBidi("reversed"))); !errors.As(err, &valErr) {
+		t.Fatalf("expected ValidationError, got %v", err)
+	}
+	if err := validateConfig(NewExtractionConfig(WithBidi(BidiVisual))); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+}
@@ -66,6 +66,8 @@ import (
 	"fmt"
 	"os"
 	"path/filepath"
+	"slices"
+	"strings"
 	"sync"
 	"unsafe"
 )
@@ -83,16 +85,76 @@ type BytesWithMime struct {
 
 // ExtractFileSync extracts content and metadata from the file at the provided path.
 func ExtractFileSync(path string, config *ExtractionConfig) (*ExtractionResult, error) {
+	return extractFile(context.Background(), path, config)
+}
+
+func extractFile(ctx context.Context, path string, config *ExtractionConfig) (*ExtractionResult, error) {
+	config = configOrDefault(config)
+	// Reject a bad config before touching the filesystem, which may be slow to open.
+	if err := validateConfig(config); err != nil {
+		return nil, err
+	}
+	if path != "" {
+		if err := checkInputSizeFile(path, config); err != nil {
+			return nil, err
+		}
+		if data, mimeType, ok, err := readMimeOverrideFile(path, config); err != nil {
+			return nil, err
+		} else if ok {
+			return extractBytes(ctx, data, mimeType, config)
+		}
+		if data, mimeType, ok, err := readEncodedTextFile(path, config); err != nil {
+			return nil, err
+		} else if ok {
+			return extractBytes(ctx, data, mimeType, config)
+		}
+		if data, mimeType, ok := readOversizedImageFile(path, config); ok {
+			return extractBytes(ctx, data, mimeType, config)
+		}
+		data, mimeType, ok, err := openEncryptedOfficeFile(path, config)
+		if err != nil {
+			return nil, err
+		}
+		if ok {
+			return extractBytes(ctx, data, mimeType, config)
+		}
+		if data, mimeType, ok := readSampledFile(path, config); ok {
+			return extractBytes(ctx, data, mimeType, config)
+		}
+		if data, ok := readRawTextPDFFile(path, config); ok {
+			return extractBytes(ctx, data, "application/pdf", config)
+		}
+		if data, ok := readOCRPagesPDFFile(path, config); ok {
+			return extractBytes(ctx, data, "application/pdf", config)
+		}
+		if err := checkMaxPagesFile(path, config); err != nil {
+			return nil, err
+		}
+	}
+	src := &documentSource{path: path}
+	result, err := extractFileNative(ctx, path, config)
+	if err != nil {
+		partial, ok := extractPartialResult(ctx, src, config, err)
+		if !ok {
+			return nil, err
+		}
+		result = partial
+	}
+	if err := postProcessResult(ctx, result, config, src); err != nil {
+		return nil, err
+	}
+	return result, nil
+}
+
+func extractFileNative(ctx context.Context, path string, config *ExtractionConfig) (*ExtractionResult, error) {
 	// Validate path is not empty
 	if path == "" {
 		return nil, newValidationErrorWithContext("path is required", nil, ErrorCodeValidation, nil)
 	}
 
-	// Validate chunking parameters if provided in config
-	if config != nil && config.Chunking != nil {
-		if err := validateChunkingConfig(config.Chunking); err != nil {
-			return nil, err
-		}
+	config, downloaded, err := ensureOCRLanguages(ctx, config)
+	if err != nil {
+		return nil, err
 	}
 
 	cPath := C.CString(path)
@@ -110,6 +172,12 @@ func ExtractFileSync(path string, config *ExtractionConfig) (*ExtractionResult,
 	ffiMutex.Lock()
 	defer ffiMutex.Unlock()
 
+	// The caller may have given up while this call was queued behind the mutex
+	if err := ctx.Err(); err != nil {
+		return nil, err
+	}
+
+	tracker := startResourceTracking(config)
 	var cRes *C.CExtractionResult
 	if cfgPtr != nil {
 		cRes = C.kreuzberg_extract_file_sync_with_config(cPath, cfgPtr)
@@ -118,24 +186,115 @@ func ExtractFileSync(path string, config *ExtractionConfig) (*ExtractionResult,
 	}
 
 	if cRes == nil {
-		return nil, lastError()
+		return nil, logNativeError(ctx, config, lastError())
 	}
 	defer C.kreuzberg_free_result(cRes)
 
-	return convertCResult(cRes)
+	usage := tracker.finish()
+
+	result, err := convertCResult(cRes)
+	if err != nil {
+		return nil, err
+	}
+	result.Resources = usage
+	addLanguageDownloadWarnings([]*ExtractionResult{result}, downloaded)
+	return result, nil
 }
 
 // ExtractBytesSync extracts content and metadata from a byte array with the given MIME type.
 func ExtractBytesSync(data []byte, mimeType string, config *ExtractionConfig) (*ExtractionResult, error) {
+	return extractBytes(context.Background(), data, mimeType, config)
+}
+
+func extractBytes(ctx context.Context, data []byte, mimeType string, config *ExtractionConfig) (*ExtractionResult, error) {
+	config = configOrDefault(config)
+	if err := validateConfig(config); err != nil {
+		return nil, err
+	}
+	result, src, err := extractBytesUnprocessed(ctx, data, mimeType, config)
+	if err != nil {
+		return nil, err
+	}
+	if err := postProcessResult(ctx, result, config, src); err != nil {
+		return nil, err
+	}
+	return result, nil
+}
+
+// extractBytesUnprocessed is extractBytes without validating config and without the
+// post-processing stages, which run on the returned source. Batch extraction uses it for
+// files with a MIME override.
+func extractBytesUnprocessed(ctx context.Context, data []byte, mimeType string, config *ExtractionConfig) (*ExtractionResult, *documentSource, error) {
+	if err := checkInputSize(data, config); err != nil {
+		return nil, nil, err
+	}
+	data, encoding, err := transcodeText(data, mimeType, config)
+	if err != nil {
+		return nil, nil, err
+	}
+	if plain, plainMime, ok, err := openEncryptedOffice(data, officeFormatName(mimeType, ""), config); err != nil {
+		return nil, nil, err
+	} else if ok {
+		data, mimeType = plain, plainMime
+	}
+	data, sampled, err := sampleDocument(data, config)
+	if err != nil {
+		return nil, nil, err
+	}
+	if sampled == nil {
+		if err := checkMaxPages(data, config); err != nil {
+			return nil, nil, err
+		}
+	}
+	data, mimeType, downsampled := downsampleForOCR(data, mimeType, config)
+
+	src := &documentSource{data: data}
+	result, err := extractBytesDispatch(ctx, data, mimeType, config)
+	if err != nil {
+		partial, ok := extractPartialResult(ctx, src, config, err)
+		if !ok {
+			return nil, nil, err
+		}
+		result = partial
+	}
+	if sampled != nil {
+		result.SampledPages = sampled
+	}
+	if downsampled != "" {
+		result.addWarning(WarningCodeImageDownsampled, 0, downsampled)
+	}
+	if encoding != "" {
+		result.Metadata.SourceEncoding = &encoding
+	}
+	return result, src, nil
+}
+
+// extractBytesDispatch sends data to the raw PDF text reader when RawText applies, to the
+// page-by-page PDF OCR when a page timeout applies, to a registered Go extractor for
+// mimeType, or to the native library.
+func extractBytesDispatch(ctx context.Context, data []byte, mimeType string, config *ExtractionConfig) (*ExtractionResult, error) {
+	if isRawTextPDF(data, mimeType, config) {
+		if result, ok, err := extractRawTextPDF(ctx, data, config); ok || err != nil {
+			return result, err
+		}
+	}
+	if ocrsPDFPages(data, mimeType, config) {
+		return extractPDFPages(ctx, data, config)
+	}
+	if fn := lookupExtractor(mimeType); fn != nil {
+		return runExtractor(fn, data, mimeType, config)
+	}
+	return extractBytesNative(ctx, data, mimeType, config)
+}
+
+func extractBytesNative(ctx context.Context, data []byte, mimeType string, config *ExtractionConfig) (*ExtractionResult, error) {
 	if mimeType == "" {
 		return nil, newValidationErrorWithContext("mimeType is required", nil, ErrorCodeValidation, nil)
 	}
 
-	// Validate chunking parameters if provided in config
-	if config != nil && config.Chunking != nil {
-		if err := validateChunkingConfig(config.Chunking); err != nil {
-			return nil, err
-		}
+	config, downloaded, err := ensureOCRLanguages(ctx, config)
+	if err != nil {
+		return nil, err
 	}
 
 	buf := C.CBytes(data)
@@ -156,6 +315,13 @@ func ExtractBytesSync(data []byte, mimeType string, config *ExtractionConfig) (*
 	ffiMutex.Lock()
 	defer ffiMutex.Unlock()
 
+	// The caller may have given up while this call was queued behind the mutex
+	if err := ctx.Err(); err != nil {
+		return nil, err
+	}
+	notifyNativeStart(ctx)
+
+	tracker := startResourceTracking(config)
 	var cRes *C.CExtractionResult
 	if cfgPtr != nil {
 		cRes = C.kreuzberg_extract_bytes_sync_with_config((*C.uint8_t)(buf), C.uintptr_t(len(data)), cMime, cfgPtr)
@@ -164,24 +330,197 @@ func ExtractBytesSync(data []byte, mimeType string, config *ExtractionConfig) (*
 	}
 
 	if cRes == nil {
-		return nil, lastError()
+		return nil, logNativeError(ctx, config, lastError())
 	}
 	defer C.kreuzberg_free_result(cRes)
 
-	return convertCResult(cRes)
+	usage := tracker.finish()
+
+	result, err := convertCResult(cRes)
+	if err != nil {
+		return nil, err
+	}
+	result.Resources = usage
+	addLanguageDownloadWarnings([]*ExtractionResult{result}, downloaded)
+	return result, nil
 }
 
 // BatchExtractFilesSync extracts multiple files sequentially but leverages the optimized batch pipeline.
 func BatchExtractFilesSync(paths []string, config *ExtractionConfig) ([]*ExtractionResult, error) {
+	return batchExtractFiles(context.Background(), paths, config)
+}
+
+func batchExtractFiles(ctx context.Context, paths []string, config *ExtractionConfig) ([]*ExtractionResult, error) {
+	config = configOrDefault(config)
+	if err := validateConfig(config); err != nil {
+		return nil, err
+	}
+	for _, path := range paths {
+		if err := checkInputSizeFile(path, config); err != nil {
+			return nil, err
+		}
+	}
+	// Documents that may be sampled are checked once their pages are selected.
+	if _, _, ok := samplePages(config); !ok {
+		for _, path := range paths {
+			if err := checkMaxPagesFile(path, config); err != nil {
+				return nil, err
+			}
+		}
+	}
+	results, sources, err := batchExtractFilesDispatch(ctx, paths, config)
+	if err != nil {
+		return nil, err
+	}
+	for i := 0; i < len(results) && i < len(sources); i++ {
+		if err := postProcessResult(ctx, results[i], config, sources[i]); err != nil {
+			return nil, err
+		}
+	}
+	return results, nil
+}
+
+// batchExtractFilesDispatch extracts the files the binding reads itself like extractFile
+// does (files with a MIME override, legacy-encoded text, oversized images, sampled PDFs and
+// TIFFs, PDFs read as raw text and encrypted Office files) and sends the remaining paths to the native batch API,
+// preserving the order of paths. It returns the source of each result for post-processing.
+func batchExtractFilesDispatch(ctx context.Context, paths []string, config *ExtractionConfig) ([]*ExtractionResult, []*documentSource, error) {
+	sources := make([]*documentSource, len(paths))
+	results := make([]*ExtractionResult, len(paths))
+	nativePaths := make([]string, 0, len(paths))
+	nativeIndices := make([]int, 0, len(paths))
+	for i, path := range paths {
+		if path == "" {
+			return nil, nil, newValidationErrorWithContext(fmt.Sprintf("path at index %d is empty", i), nil, ErrorCodeValidation, nil)
+		}
+		sources[i] = &documentSource{path: path}
+		if data, mimeType, ok, err := readMimeOverrideFile(path, config); err != nil {
+			return nil, nil, err
+		} else if ok {
+			// Extracted as extractFile does, so that the file is decoded, decrypted, sampled
+			// and downsampled the same way in a batch.
+			result, src, err := extractBytesUnprocessed(ctx, data, mimeType, config)
+			if err != nil {
+				return nil, nil, err
+			}
+			sources[i] = src
+			results[i] = result
+			continue
+		}
+		if data, mimeType, ok, err := readEncodedTextFile(path, config); err != nil {
+			return nil, nil, err
+		} else if ok {
+			data, encoding, err := transcodeText(data, mimeType, config)
+			if err != nil {
+				return nil, nil, err
+			}
+			result, err := extractBytesDispatch(ctx, data, mimeType, config)
+			if err != nil {
+				return nil, nil, err
+			}
+			if encoding != "" {
+				result.Metadata.SourceEncoding = &encoding
+			}
+			sources[i] = &documentSource{data: data}
+			results[i] = result
+			continue
+		}
+		if data, mimeType, ok := readOversizedImageFile(path, config); ok {
+			data, mimeType, downsampled := downsampleForOCR(data, mimeType, config)
+			result, err := extractBytesNative(ctx, data, mimeType, config)
+			if err != nil {
+				return nil, nil, err
+			}
+			if downsampled != "" {
+				result.addWarning(WarningCodeImageDownsampled, 0, downsampled)
+			}
+			sources[i] = &documentSource{data: data}
+			results[i] = result
+			continue
+		}
+		if data, mimeType, ok := readSampledFile(path, config); ok {
+			data, sampled, err := sampleDocument(data, config)
+			if err != nil {
+				return nil, nil, err
+			}
+			if sampled == nil {
+				if err := checkMaxPages(data, config); err != nil {
+					return nil, nil, err
+				}
+			}
+			result, err := extractBytesDispatch(ctx, data, mimeType, config)
+			if err != nil {
+				return nil, nil, err
+			}
+			if sampled != nil {
+				result.SampledPages = sampled
+			}
+			sources[i] = &documentSource{data: data}
+			results[i] = result
+			continue
+		}
+		if data, ok := readRawTextPDFFile(path, config); ok {
+			result, ok, err := extractRawTextPDF(ctx, data, config)
+			if err != nil {
+				return nil, nil, err
+			}
+			if ok {
+				sources[i] = &documentSource{data: data}
+				results[i] = result
+				continue
+			}
+		}
+		if data, ok := readOCRPagesPDFFile(path, config); ok {
+			result, err := extractPDFPages(ctx, data, config)
+			if err != nil {
+				return nil, nil, err
+			}
+			sources[i] = &documentSource{data: data}
+			results[i] = result
+			continue
+		}
+		data, mimeType, ok, err := openEncryptedOfficeFile(path, config)
+		if err != nil {
+			return nil, nil, err
+		}
+		if !ok {
+			nativePaths = append(nativePaths, path)
+			nativeIndices = append(nativeIndices, i)
+			continue
+		}
+		sources[i] = &documentSource{data: data}
+		result, err := extractBytesNative(ctx, data, mimeType, config)
+		if err != nil {
+			return nil, nil, err
+		}
+		results[i] = result
+	}
+	if len(nativePaths) == len(paths) {
+		nativeResults, err := batchExtractFilesNative(ctx, paths, config)
+		return nativeResults, sources, err
+	}
+	if len(nativePaths) == 0 {
+		return results, sources, nil
+	}
+
+	nativeResults, err := batchExtractFilesNative(ctx, nativePaths, config)
+	if err != nil {
+		return nil, nil, err
+	}
+	for i := 0; i < len(nativeResults) && i < len(nativeIndices); i++ {
+		results[nativeIndices[i]] = nativeResults[i]
+	}
+	return results, sources, nil
+}
+
+func batchExtractFilesNative(ctx context.Context, paths []string, config *ExtractionConfig) ([]*ExtractionResult, error) {
 	if len(paths) == 0 {
 		return []*ExtractionResult{}, nil
 	}
 
-	// Validate chunking parameters if provided in config
-	if config != nil && config.Chunking != nil {
-		if err := validateChunkingConfig(config.Chunking); err != nil {
-			return nil, err
-		}
+	config, downloaded, err := ensureOCRLanguages(ctx, config)
+	if err != nil {
+		return nil, err
 	}
 
 	cStrings := make([]*C.char, len(paths))
@@ -209,26 +548,145 @@ func BatchExtractFilesSync(paths []string, config *ExtractionConfig) ([]*Extract
 	ffiMutex.Lock()
 	defer ffiMutex.Unlock()
 
+	// The caller may have given up while this call was queued behind the mutex
+	if err := ctx.Err(); err != nil {
+		return nil, err
+	}
+
 	batch := C.kreuzberg_batch_extract_files_sync((**C.char)(unsafe.Pointer(&cStrings[0])), C.uintptr_t(len(paths)), cfgPtr)
 	if batch == nil {
-		return nil, lastError()
+		return nil, logNativeError(ctx, config, lastError())
 	}
 	defer C.kreuzberg_free_batch_result(batch)
 
-	return convertCBatchResult(batch)
+	results, err := convertCBatchResult(batch)
+	if err != nil {
+		return nil, err
+	}
+	addLanguageDownloadWarnings(results, downloaded)
+	return results, nil
 }
 
 // BatchExtractBytesSync processes multiple in-memory documents in one pass.
 func BatchExtractBytesSync(items []BytesWithMime, config *ExtractionConfig) ([]*ExtractionResult, error) {
-	if len(items) == 0 {
-		return []*ExtractionResult{}, nil
+	return batchExtractBytes(context.Background(), items, config)
+}
+
+func batchExtractBytes(ctx context.Context, items []BytesWithMime, config *ExtractionConfig) ([]*ExtractionResult, error) {
+	config = configOrDefault(config)
+	if err := validateConfig(config); err != nil {
+		return nil, err
+	}
+	for _, item := range items {
+		if err := checkInputSize(item.Data, config); err != nil {
+			return nil, err
+		}
+	}
+	items, encodings, err := transcodeBatchItems(items, config)
+	if err != nil {
+		return nil, err
+	}
+	items, err = decryptBatchItems(items, config)
+	if err != nil {
+		return nil, err
+	}
+	items, sampled, err := sampleBatchItems(items, config)
+	if err != nil {
+		return nil, err
+	}
+	items, downsampled := downsampleBatchItems(items, config)
+	for i, item := range items {
+		if i < len(sampled) && sampled[i] != nil {
+			continue
+		}
+		if err := checkMaxPages(item.Data, config); err != nil {
+			return nil, err
+		}
 	}
+	results, err := batchExtractBytesDispatch(ctx, items, config)
+	if err != nil {
+		return nil, err
+	}
+	for i := 0; i < len(results) && i < len(items); i++ {
+		if i < len(sampled) && sampled[i] != nil {
+			results[i].SampledPages = sampled[i]
+		}
+		if i < len(downsampled) && downsampled[i] != "" {
+			results[i].addWarning(WarningCodeImageDownsampled, 0, downsampled[i])
+		}
+		if i < len(encodings) && encodings[i] != "" {
+			results[i].Metadata.SourceEncoding = &encodings[i]
+		}
+		if err := postProcessResult(ctx, results[i], config, &documentSource{data: items[i].Data}); err != nil {
+			return nil, err
+		}
+	}
+	return results, nil
+}
 
-	// Validate chunking parameters if provided in config
-	if config != nil && config.Chunking != nil {
-		if err := validateChunkingConfig(config.Chunking); err != nil {
+// batchExtractBytesDispatch sends PDFs readable as raw text to the raw text reader, items
+// with a registered Go extractor to that extractor and the remaining items to the native
+// batch API, preserving the order of items.
+func batchExtractBytesDispatch(ctx context.Context, items []BytesWithMime, config *ExtractionConfig) ([]*ExtractionResult, error) {
+	results := make([]*ExtractionResult, len(items))
+	nativeItems := make([]BytesWithMime, 0, len(items))
+	nativeIndices := make([]int, 0, len(items))
+	for i, item := range items {
+		if isRawTextPDF(item.Data, item.MimeType, config) {
+			result, ok, err := extractRawTextPDF(ctx, item.Data, config)
+			if err != nil {
+				return nil, err
+			}
+			if ok {
+				results[i] = result
+				continue
+			}
+		}
+		if ocrsPDFPages(item.Data, item.MimeType, config) {
+			result, err := extractPDFPages(ctx, item.Data, config)
+			if err != nil {
+				return nil, err
+			}
+			results[i] = result
+			continue
+		}
+		fn := lookupExtractor(item.MimeType)
+		if fn == nil {
+			nativeItems = append(nativeItems, item)
+			nativeIndices = append(nativeIndices, i)
+			continue
+		}
+		result, err := runExtractor(fn, item.Data, item.MimeType, config)
+		if err != nil {
 			return nil, err
 		}
+		results[i] = result
+	}
+	if len(nativeItems) == len(items) {
+		return batchExtractBytesNative(ctx, items, config)
+	}
+	if len(nativeItems) == 0 {
+		return results, nil
+	}
+
+	nativeResults, err := batchExtractBytesNative(ctx, nativeItems, config)
+	if err != nil {
+		return nil, err
+	}
+	for i := 0; i < len(nativeResults) && i < len(nativeIndices); i++ {
+		results[nativeIndices[i]] = nativeResults[i]
+	}
+	return results, nil
+}
+
+func batchExtractBytesNative(ctx context.Context, items []BytesWithMime, config *ExtractionConfig) ([]*ExtractionResult, error) {
+	if len(items) == 0 {
+		return []*ExtractionResult{}, nil
+	}
+
+	config, downloaded, err := ensureOCRLanguages(ctx, config)
+	if err != nil {
+		return nil, err
 	}
 
 	cItems := make([]C.CBytesWithMime, len(items))
@@ -274,53 +732,92 @@ func BatchExtractBytesSync(items []BytesWithMime, config *ExtractionConfig) ([]*
 	ffiMutex.Lock()
 	defer ffiMutex.Unlock()
 
+	// The caller may have given up while this call was queued behind the mutex
+	if err := ctx.Err(); err != nil {
+		return nil, err
+	}
+
 	batch := C.kreuzberg_batch_extract_bytes_sync((*C.CBytesWithMime)(unsafe.Pointer(&cItems[0])), C.uintptr_t(len(items)), cfgPtr)
 	if batch == nil {
-		return nil, lastError()
+		return nil, logNativeError(ctx, config, lastError())
 	}
 	defer C.kreuzberg_free_batch_result(batch)
 
-	return convertCBatchResult(batch)
+	results, err := convertCBatchResult(batch)
+	if err != nil {
+		return nil, err
+	}
+	addLanguageDownloadWarnings(results, downloaded)
+	return results, nil
 }
 
 // ExtractFileWithContext extracts content and metadata from a file at the given path,
-// respecting the provided context for cancellation. Note that extraction operations
-// cannot be interrupted mid-way; this cancellation check occurs before starting extraction.
+// respecting the provided context for cancellation and deadlines.
+//
+// The native extraction cannot be interrupted once it is running, so it is executed on a
+// separate goroutine and abandoned when ctx is done: the call returns ctx.Err() promptly
+// while the native work finishes in the background and its result is released. Calls that
+// are still queued behind another extraction when ctx ends never reach the native library.
 func ExtractFileWithContext(ctx context.Context, path string, config *ExtractionConfig) (*ExtractionResult, error) {
-	if err := ctx.Err(); err != nil {
-		return nil, err
-	}
-	return ExtractFileSync(path, config)
+	return runWithContext(ctx, func() (*ExtractionResult, error) {
+		return extractFile(ctx, path, config)
+	})
 }
 
 // ExtractBytesWithContext extracts content and metadata from a byte array,
-// respecting the provided context for cancellation. Note that extraction operations
-// cannot be interrupted mid-way; this cancellation check occurs before starting extraction.
+// respecting the provided context for cancellation and deadlines.
+// See ExtractFileWithContext for the cancellation semantics.
 func ExtractBytesWithContext(ctx context.Context, data []byte, mimeType string, config *ExtractionConfig) (*ExtractionResult, error) {
-	if err := ctx.Err(); err != nil {
-		return nil, err
-	}
-	return ExtractBytesSync(data, mimeType, config)
+	return runWithContext(ctx, func() (*ExtractionResult, error) {
+		return extractBytes(ctx, data, mimeType, config)
+	})
 }
 
 // BatchExtractFilesWithContext extracts multiple files respecting the provided context
-// for cancellation. Note that extraction operations cannot be interrupted mid-way;
-// this cancellation check occurs before starting the batch operation.
+// for cancellation and deadlines. See ExtractFileWithContext for the cancellation semantics.
 func BatchExtractFilesWithContext(ctx context.Context, paths []string, config *ExtractionConfig) ([]*ExtractionResult, error) {
-	if err := ctx.Err(); err != nil {
-		return nil, err
-	}
-	return BatchExtractFilesSync(paths, config)
+	return runWithContext(ctx, func() ([]*ExtractionResult, error) {
+		return batchExtractFiles(ctx, paths, config)
+	})
 }
 
 // BatchExtractBytesWithContext processes multiple in-memory documents respecting the
-// provided context for cancellation. Note that extraction operations cannot be
-// interrupted mid-way; this cancellation check occurs before starting the batch operation.
+// provided context for cancellation and deadlines. See ExtractFileWithContext for the
+// cancellation semantics.
 func BatchExtractBytesWithContext(ctx context.Context, items []BytesWithMime, config *ExtractionConfig) ([]*ExtractionResult, error) {
+	return runWithContext(ctx, func() ([]*ExtractionResult, error) {
+		return batchExtractBytes(ctx, items, config)
+	})
+}
+
+// runWithContext runs fn on its own goroutine and waits for either its completion or ctx.
+// The result channel is buffered so an abandoned goroutine can always deliver its outcome
+// and exit once the native call returns, instead of leaking.
+func runWithContext[T any](ctx context.Context, fn func() (T, error)) (T, error) {
+	var zero T
 	if err := ctx.Err(); err != nil {
-		return nil, err
+		return zero, err
+	}
+	if ctx.Done() == nil {
+		return fn()
+	}
+
+	type outcome struct {
+		value T
+		err   error
+	}
+	done := make(chan outcome, 1)
+	go func() {
+		value, err := fn()
+		done <- outcome{value: value, err: err}
+	}()
+
+	select {
+	case out := <-done:
+		return out.value, out.err
+	case <-ctx.Done():
+		return zero, ctx.Err()
 	}
-	return BatchExtractBytesSync(items, config)
 }
 
 // LibraryVersion returns the underlying Rust crate version string.
@@ -449,9 +946,13 @@ func newConfigJSON(config *ExtractionConfig) (*C.char, func(), error) {
 	if config == nil {
 		return nil, nil, nil
 	}
-	data, err := json.Marshal(config)
-	if err != nil {
-		return nil, nil, newSerializationErrorWithContext("failed to encode config", err, ErrorCodeValidation, nil)
+	data := config.encoded
+	if data == nil {
+		var err error
+		data, err = encodeConfig(config)
+		if err != nil {
+			return nil, nil, newSerializationErrorWithContext("failed to encode config", err, ErrorCodeValidation, nil)
+		}
 	}
 	if len(data) == 0 {
 		return nil, nil, nil
@@ -704,6 +1205,138 @@ func GetEmbeddingPreset(name string) (*EmbeddingPreset, error) {
 	return &preset, nil
 }
 
+// validateConfig checks the parts of config that the binding validates before calling into
+// the native library.
+func validateConfig(config *ExtractionConfig) error {
+	if config == nil {
+		return nil
+	}
+	if config.Chunking != nil {
+		if err := validateChunkingConfig(config.Chunking); err != nil {
+			return err
+		}
+	}
+	if config.MaxInputBytes != nil && *config.MaxInputBytes < 1 {
+		return newValidationErrorWithContext(fmt.Sprintf("invalid max input bytes: %d (must be at least 1)", *config.MaxInputBytes), nil, ErrorCodeValidation, nil)
+	}
+	if config.MaxRecursionDepth != nil && *config.MaxRecursionDepth < 0 {
+		return newValidationErrorWithContext(fmt.Sprintf("invalid max recursion depth: %d (must not be negative)", *config.MaxRecursionDepth), nil, ErrorCodeValidation, nil)
+	}
+	if config.NormalizeTableNumbers != nil {
+		if _, ok := numberFormatFor(*config.NormalizeTableNumbers); !ok {
+			return newValidationErrorWithContext(fmt.Sprintf("unsupported number locale: %q", *config.NormalizeTableNumbers), nil, ErrorCodeValidation, nil)
+		}
+	}
+	if config.MaxExtractedBytes != nil && *config.MaxExtractedBytes < 1 {
+		return newValidationErrorWithContext(fmt.Sprintf("invalid max extracted bytes: %d (must be at least 1)", *config.MaxExtractedBytes), nil, ErrorCodeValidation, nil)
+	}
+	if config.ParallelPages != nil && *config.ParallelPages < 1 {
+		return newValidationErrorWithContext(fmt.Sprintf("invalid parallel pages: %d (must be at least 1)", *config.ParallelPages), nil, ErrorCodeValidation, nil)
+	}
+	if err := validateSourceEncoding(config); err != nil {
+		return err
+	}
+	if config.Pages != nil && config.Pages.MaxPages != nil && *config.Pages.MaxPages < 1 {
+		return newValidationErrorWithContext(fmt.Sprintf("invalid max pages: %d (must be at least 1)", *config.Pages.MaxPages), nil, ErrorCodeValidation, nil)
+	}
+	if config.Pages != nil && config.Pages.SamplePages != nil && *config.Pages.SamplePages < 1 {
+		return newValidationErrorWithContext(fmt.Sprintf("invalid sample pages: %d (must be at least 1)", *config.Pages.SamplePages), nil, ErrorCodeValidation, nil)
+	}
+	if config.Pages != nil && config.Pages.SampleStrategy != nil {
+		switch *config.Pages.SampleStrategy {
+		case SampleFirst, SampleUniform, SampleRandom:
+		default:
+			return newValidationErrorWithContext(
+				fmt.Sprintf("invalid sample strategy: %q (must be %q, %q or %q)", *config.Pages.SampleStrategy, SampleFirst, SampleUniform, SampleRandom),
+				nil, ErrorCodeValidation, nil)
+		}
+	}
+	if config.Docx != nil && config.Docx.TrackedChangesMode != "" {
+		switch config.Docx.TrackedChangesMode {
+		case TrackedChangesAccepted, TrackedChangesRejected, TrackedChangesOriginal:
+		default:
+			return newValidationErrorWithContext(
+				fmt.Sprintf("invalid tracked changes mode: %q (must be %q, %q or %q)", config.Docx.TrackedChangesMode, TrackedChangesAccepted, TrackedChangesRejected, TrackedChangesOriginal),
+				nil, ErrorCodeValidation, nil)
+		}
+	}
+	if config.HeaderFooterThreshold != nil && (*config.HeaderFooterThreshold <= 0 || *config.HeaderFooterThreshold > 1) {
+		return newValidationErrorWithContext(
+			fmt.Sprintf("invalid header/footer threshold: %g (must be > 0 and <= 1)", *config.HeaderFooterThreshold),
+			nil, ErrorCodeValidation, nil)
+	}
+	if config.ReadingOrder != nil {
+		switch *config.ReadingOrder {
+		case ReadingOrderRaw, ReadingOrderColumns:
+		default:
+			return newValidationErrorWithContext(
+				fmt.Sprintf("invalid reading order: %q (must be %q or %q)", *config.ReadingOrder, ReadingOrderRaw, ReadingOrderColumns),
+				nil, ErrorCodeValidation, nil)
+		}
+	}
+	if config.TableBackend != nil && !slices.Contains(AvailableTableBackends(), *config.TableBackend) {
+		return newValidationErrorWithContext(
+			fmt.Sprintf("invalid table backend: %q (must be one of %q)", *config.TableBackend, AvailableTableBackends()),
+			nil, ErrorCodeValidation, nil)
+	}
+	for ext, mimeType := range config.MimeOverrides {
+		if normalizeExtension(ext) == "" || !strings.Contains(mimeType, "/") {
+			return newValidationErrorWithContext(
+				fmt.Sprintf("invalid mime override: %q -> %q (need an extension and a type/subtype mime type)", ext, mimeType),
+				nil, ErrorCodeValidation, nil)
+		}
+	}
+	if config.Bidi != nil && *config.Bidi != BidiLogical && *config.Bidi != BidiVisual {
+		return newValidationErrorWithContext(
+			fmt.Sprintf("invalid bidi mode: %q (must be %q or %q)", *config.Bidi, BidiLogical, BidiVisual),
+			nil, ErrorCodeValidation, nil)
+	}
+	if config.RenderPages != nil {
+		if config.RenderPages.DPI <= 0 {
+			return newValidationErrorWithContext(
+				fmt.Sprintf("invalid render DPI: %d (must be > 0)", config.RenderPages.DPI),
+				nil, ErrorCodeValidation, nil)
+		}
+		switch config.RenderPages.Format {
+		case RenderFormatPNG, RenderFormatJPEG:
+		default:
+			return newValidationErrorWithContext(
+				fmt.Sprintf("invalid render format: %q (must be %q or %q)", config.RenderPages.Format, RenderFormatPNG, RenderFormatJPEG),
+				nil, ErrorCodeValidation, nil)
+		}
+	}
+	if config.MaxTables != nil && *config.MaxTables < 1 {
+		return newValidationErrorWithContext(
+			fmt.Sprintf("invalid max tables: %d (must be >= 1)", *config.MaxTables),
+			nil, ErrorCodeValidation, nil)
+	}
+	if config.TableMinArea != nil && *config.TableMinArea < 0 {
+		return newValidationErrorWithContext(
+			fmt.Sprintf("invalid table min area: %d (must be >= 0)", *config.TableMinArea),
+			nil, ErrorCodeValidation, nil)
+	}
+	if config.MinTableRows != nil && *config.MinTableRows < 0 {
+		return newValidationErrorWithContext(
+			fmt.Sprintf("invalid min table rows: %d (must be >= 0)", *config.MinTableRows),
+			nil, ErrorCodeValidation, nil)
+	}
+	if config.MinTableCols != nil && *config.MinTableCols < 0 {
+		return newValidationErrorWithContext(
+			fmt.Sprintf("invalid min table cols: %d (must be >= 0)", *config.MinTableCols),
+			nil, ErrorCodeValidation, nil)
+	}
+	if config.WhitespaceNormalization != nil {
+		switch *config.WhitespaceNormalization {
+		case WhitespaceNone, WhitespaceTrim, WhitespaceAggressive:
+		default:
+			return newValidationErrorWithContext(
+				fmt.Sprintf("invalid whitespace normalization: %q (must be %q, %q or %q)", *config.WhitespaceNormalization, WhitespaceNone, WhitespaceTrim, WhitespaceAggressive),
+				nil, ErrorCodeValidation, nil)
+		}
+	}
+	return nil
+}
+
 // validateChunkingConfig validates chunking configuration parameters.
 // It checks that ChunkSize and ChunkOverlap are positive when set, and that overlap < chunk size.
 // These validations are performed before FFI calls.
@@ -741,6 +1374,16 @@ func validateChunkingConfig(cfg *ChunkingConfig) error {
 		}
 	}
 
+	if cfg.OverlapStrategy != nil {
+		switch *cfg.OverlapStrategy {
+		case OverlapStrategyChars, OverlapStrategySentences, OverlapStrategyTokens:
+		default:
+			return newValidationErrorWithContext(
+				fmt.Sprintf("invalid overlap strategy: %q (must be %q, %q or %q)", *cfg.OverlapStrategy, OverlapStrategyChars, OverlapStrategySentences, OverlapStrategyTokens),
+				nil, ErrorCodeValidation, nil)
+		}
+	}
+
 	// Also validate MaxChars and MaxOverlap if provided (for backward compatibility)
 	if cfg.MaxChars != nil {
 		if *cfg.MaxChars <= 0 {
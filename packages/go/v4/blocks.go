@@ -0,0 +1,250 @@
+package kreuzberg
+
+import (
+	"cmp"
+	"math"
+	"slices"
+	"strings"
+)
+
+// Block types reported in Block.Type.
+const (
+	BlockTypeParagraph = "paragraph"
+	BlockTypeHeading   = "heading"
+	BlockTypeTable     = "table"
+	BlockTypeImage     = "image"
+)
+
+// Block is one element of a document in reading order: a paragraph or heading of Content, a
+// table or an image. Table and image blocks reference their entries in
+// ExtractionResult.Tables and ExtractionResult.Images by index.
+type Block struct {
+	Type       string `json:"type"`
+	Content    string `json:"content"`
+	PageNumber int    `json:"page_number"`
+	// Level is the heading depth of heading blocks, 1 for top-level headings.
+	Level      int  `json:"level,omitempty"`
+	TableIndex *int `json:"table_index,omitempty"`
+	ImageIndex *int `json:"image_index,omitempty"`
+}
+
+// contentBlock is a block of Content spanning bytes [start, end). title is the text of a
+// heading without its Markdown markers.
+type contentBlock struct {
+	kind       string
+	start, end int
+	level      int
+	title      string
+}
+
+// applyBlocks fills result.Blocks when structured output is enabled. Content is split into
+// paragraphs at blank lines, headings are recognized as in Sections, and Markdown tables
+// become table blocks. A table whose text already appears in Content replaces that
+// paragraph; other tables, and images, follow the content of their page.
+func applyBlocks(result *ExtractionResult, config *ExtractionConfig) {
+	if config.StructuredOutput == nil || !*config.StructuredOutput {
+		return
+	}
+	result.Blocks = documentBlocks(result)
+}
+
+// documentBlocks returns the blocks of result in reading order, as described at
+// applyBlocks.
+func documentBlocks(result *ExtractionResult) []Block {
+	content := result.Content
+	pageOf := contentPageFunc(result)
+	used := make([]bool, len(result.Tables))
+
+	var blocks []Block
+	for _, cb := range splitContentBlocks(content) {
+		text := strings.TrimSpace(content[cb.start:cb.end])
+		if cb.kind == BlockTypeHeading {
+			text = cb.title
+		}
+		block := Block{Type: cb.kind, Content: text, PageNumber: pageOf(cb.start), Level: cb.level}
+		if cb.kind == BlockTypeParagraph || cb.kind == BlockTypeTable {
+			if t := matchBlockTable(result.Tables, used, text, block.PageNumber, cb.kind == BlockTypeTable); t >= 0 {
+				used[t] = true
+				block.Type, block.TableIndex = BlockTypeTable, &t
+			}
+		}
+		blocks = append(blocks, block)
+	}
+
+	var extras []Block
+	for t, table := range result.Tables {
+		if !used[t] {
+			extras = append(extras, Block{Type: BlockTypeTable, Content: table.Markdown, PageNumber: table.PageNumber, TableIndex: &t})
+		}
+	}
+	for i, img := range result.Images {
+		if img.IsMask {
+			continue
+		}
+		page := 0
+		if img.PageNumber != nil {
+			page = *img.PageNumber
+		}
+		text := img.OCRText
+		if text == "" && img.Description != nil {
+			text = *img.Description
+		}
+		extras = append(extras, Block{Type: BlockTypeImage, Content: text, PageNumber: page, ImageIndex: &i})
+	}
+	// Blocks without a known page go to the end of the document.
+	last := func(b Block) int {
+		if b.PageNumber <= 0 {
+			return math.MaxInt
+		}
+		return b.PageNumber
+	}
+	slices.SortStableFunc(extras, func(a, b Block) int { return cmp.Compare(last(a), last(b)) })
+
+	merged := make([]Block, 0, len(blocks)+len(extras))
+	for _, block := range blocks {
+		for len(extras) > 0 && last(extras[0]) < block.PageNumber {
+			merged = append(merged, extras[0])
+			extras = extras[1:]
+		}
+		merged = append(merged, block)
+	}
+	return append(merged, extras...)
+}
+
+// contentPageFunc returns a function mapping a Content offset to its page number, using the
+// page boundaries when they are known and page 1 otherwise.
+func contentPageFunc(result *ExtractionResult) func(offset int) int {
+	var boundaries []PageBoundary
+	if result.Metadata.PageStructure != nil {
+		boundaries = result.Metadata.PageStructure.Boundaries
+	}
+	return func(offset int) int {
+		page := 1
+		for _, boundary := range boundaries {
+			if uint64(offset) < boundary.ByteStart {
+				break
+			}
+			page = int(boundary.PageNumber)
+		}
+		return page
+	}
+}
+
+// splitContentBlocks splits content into paragraphs, headings and Markdown tables. Fenced
+// code blocks are kept whole.
+func splitContentBlocks(content string) []contentBlock {
+	var blocks []contentBlock
+	var current *contentBlock
+	flush := func() {
+		if current != nil {
+			blocks = append(blocks, *current)
+			current = nil
+		}
+	}
+	lines, fence := 0, ""
+	for offset := 0; offset < len(content); {
+		end := strings.IndexByte(content[offset:], '\n')
+		if end < 0 {
+			end = len(content)
+		} else {
+			end += offset
+		}
+		line := strings.TrimRight(content[offset:end], "\r")
+		trimmed := strings.TrimSpace(line)
+		start := offset
+		offset = end + 1
+
+		switch {
+		case fence != "":
+			current.end = end
+			if strings.HasPrefix(trimmed, fence) {
+				fence = ""
+				flush()
+			}
+			continue
+		case strings.HasPrefix(trimmed, "```") || strings.HasPrefix(trimmed, "~~~"):
+			flush()
+			fence = trimmed[:3]
+			current = &contentBlock{kind: BlockTypeParagraph, start: start, end: end}
+			continue
+		case trimmed == "":
+			flush()
+			continue
+		}
+
+		if title, level, ok := atxHeading(line); ok {
+			flush()
+			blocks = append(blocks, contentBlock{kind: BlockTypeHeading, start: start, end: end, level: level, title: title})
+			continue
+		}
+		if level := setextLevel(trimmed); level > 0 && current != nil && current.kind == BlockTypeParagraph && lines == 1 {
+			current.kind, current.level = BlockTypeHeading, level
+			current.title = strings.TrimSpace(content[current.start:current.end])
+			flush()
+			continue
+		}
+		kind := BlockTypeParagraph
+		if strings.HasPrefix(trimmed, "|") {
+			kind = BlockTypeTable
+		}
+		if current != nil && current.kind != kind {
+			flush()
+		}
+		if current == nil {
+			current = &contentBlock{kind: kind, start: start}
+			lines = 0
+		}
+		current.end = end
+		lines++
+	}
+	flush()
+	return blocks
+}
+
+// matchBlockTable returns the index of the first unused table whose cell text is the text
+// of a content block, or -1. Plain paragraphs only match tables on the same page with at
+// least two cells, so short paragraphs are not mistaken for tables.
+func matchBlockTable(tables []Table, used []bool, text string, page int, markdown bool) int {
+	words := tableWords(text)
+	if len(words) == 0 {
+		return -1
+	}
+	for t, table := range tables {
+		if used[t] {
+			continue
+		}
+		if !markdown {
+			cells := 0
+			for _, row := range table.Cells {
+				cells += len(row)
+			}
+			if cells < 2 || (table.PageNumber > 0 && table.PageNumber != page) {
+				continue
+			}
+		}
+		var cellWords []string
+		for _, row := range table.Cells {
+			for _, cell := range row {
+				cellWords = append(cellWords, tableWords(cell)...)
+			}
+		}
+		if slices.Equal(words, cellWords) {
+			return t
+		}
+	}
+	return -1
+}
+
+// tableWords splits text into words, ignoring Markdown table pipes and delimiter rows.
+func tableWords(text string) []string {
+	var words []string
+	for _, word := range strings.FieldsFunc(text, func(r rune) bool {
+		return r == '|' || r == ' ' || r == '\t' || r == '\n' || r == '\r'
+	}) {
+		if strings.Trim(word, "-:") == "" {
+			continue
+		}
+		words = append(words, strings.ReplaceAll(word, `\`, ""))
+	}
+	return words
+}
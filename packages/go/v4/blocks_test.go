@@ -0,0 +1,67 @@
+package kreuzberg
+
+import (
+	"reflect"
+	"testing"
+)
+
+func TestBlocks(t *testing.T) {
+	content := "# Report\n\nIntro paragraph\nspanning two lines.\n\n" +
+		"| Name | Qty |\n| --- | --- |\n| Apples | 3 |\n\nBetween the tables.\n\n" +
+		"Summary\n=======\n\n```\ncode\n\nmore code\n```\n" +
+		"Second page text.\n\nRegion Total\nNorth 10\n"
+	secondPage := uint64(len(content) - len("Second page text.\n\nRegion Total\nNorth 10\n"))
+	pageTwo := 2
+	result := &ExtractionResult{
+		Content: content,
+		Tables: []Table{
+			{Cells: [][]string{{"Name", "Qty"}, {"Apples", "3"}}, Markdown: "| Name | Qty |", PageNumber: 1},
+			{Cells: [][]string{{"Region", "Total"}, {"North", "10"}}, Markdown: "| Region | Total |", PageNumber: 2},
+			{Cells: [][]string{{"Only", "in"}, {"tables", "!"}}, Markdown: "| Only | in |", PageNumber: 1},
+		},
+		Images: []ExtractedImage{{ImageIndex: 0, PageNumber: &pageTwo, OCRText: "chart"}, {IsMask: true}},
+		Metadata: Metadata{PageStructure: &PageStructure{Boundaries: []PageBoundary{
+			{ByteStart: 0, ByteEnd: secondPage, PageNumber: 1},
+			{ByteStart: secondPage, ByteEnd: uint64(len(content)), PageNumber: 2},
+		}}},
+	}
+	applyBlocks(result, NewExtractionConfig(WithStructuredOutput(true)))
+
+	index := func(i int) *int { return &i }
+	want := []Block{
+		{Type: BlockTypeHeading, Content: "Report", PageNumber: 1, Level: 1},
+		{Type: BlockTypeParagraph, Content: "Intro paragraph\nspanning two lines.", PageNumber: 1},
+		{Type: BlockTypeTable, Content: "| Name | Qty |\n| --- | --- |\n| Apples | 3 |", PageNumber: 1, TableIndex: index(0)},
+		{Type: BlockTypeParagraph, Content: "Between the tables.", PageNumber: 1},
+		{Type: BlockTypeHeading, Content: "Summary", PageNumber: 1, Level: 1},
+		{Type: BlockTypeParagraph, Content: "```\ncode\n\nmore code\n```", PageNumber: 1},
+		{Type: BlockTypeTable, Content: "| Only | in |", PageNumber: 1, TableIndex: index(2)},
+		{Type: BlockTypeParagraph, Content: "Second page text.", PageNumber: 2},
+		{Type: BlockTypeTable, Content: "Region Total\nNorth 10", PageNumber: 2, TableIndex: index(1)},
+		{Type: BlockTypeImage, Content: "chart", PageNumber: 2, ImageIndex: index(0)},
+	}
+	if !reflect.DeepEqual(result.Blocks, want) {
+		for i := range max(len(result.Blocks), len(want)) {
+			var got, exp any
+			if i < len(result.Blocks) {
+				got = result.Blocks[i]
+			}
+			if i < len(want) {
+				exp = want[i]
+			}
+			t.Logf("%d: got %+v, want %+v", i, got, exp)
+		}
+		t.Fatal("unexpected blocks")
+	}
+	if result.Content != content || len(result.Tables) != 3 {
+		t.Fatal("structured output must leave Content and Tables in place")
+	}
+}
+
+func TestBlocksDisabled(t *testing.T) {
+	result := &ExtractionResult{Content: "# Title\n\nText."}
+	applyBlocks(result, &ExtractionConfig{})
+	if result.Blocks != nil {
+		t.Fatalf("expected no blocks, got %+v", result.Blocks)
+	}
+}
@@ -0,0 +1,25 @@
+package kreuzberg
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+)
+
+// CacheKeyFor returns the cache identity of extracting data with config: a hex-encoded
+// SHA-256 over the document bytes and the serialized configuration, including
+// CacheKeyExtra. Callers caching extraction results can key them on it, so bumping
+// CacheKeyExtra gives new entries. Callback fields such as ContentFilter are not part of
+// the key.
+func CacheKeyFor(data []byte, config *ExtractionConfig) string {
+	configJSON, err := json.Marshal(config)
+	if err != nil {
+		configJSON = nil
+	}
+
+	contentHash := sha256.Sum256(data)
+	hasher := sha256.New()
+	hasher.Write(contentHash[:])
+	hasher.Write(configJSON)
+	return hex.EncodeToString(hasher.Sum(nil))
+}
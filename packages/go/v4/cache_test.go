@@ -0,0 +1,83 @@
+package kreuzberg
+
+import (
+	"bytes"
+	"encoding/json"
+	"image"
+	"image/png"
+	"path/filepath"
+	"testing"
+)
+
+func TestCacheKeyFor(t *testing.T) {
+	data := []byte("document")
+	base := CacheKeyFor(data, NewExtractionConfig(WithUseCache(true)))
+
+	if again := CacheKeyFor(data, NewExtractionConfig(WithUseCache(true))); again != base {
+		t.Errorf("cache key should be deterministic: %s != %s", again, base)
+	}
+	if len(base) != 64 {
+		t.Errorf("expected hex SHA-256 key, got %q", base)
+	}
+
+	// CacheKeyExtra is part of the key whether or not OCR is configured.
+	v1 := CacheKeyFor(data, NewExtractionConfig(WithUseCache(true), WithCacheKeyExtra("pipeline-v1")))
+	v2 := CacheKeyFor(data, NewExtractionConfig(WithUseCache(true), WithCacheKeyExtra("pipeline-v2")))
+	if v1 == base || v1 == v2 {
+		t.Errorf("CacheKeyExtra should change the key: base=%s v1=%s v2=%s", base, v1, v2)
+	}
+
+	if other := CacheKeyFor([]byte("other"), NewExtractionConfig(WithUseCache(true))); other == base {
+		t.Error("different content should produce a different key")
+	}
+	if withFilter := CacheKeyFor(data, NewExtractionConfig(WithUseCache(true), WithContentFilter(func(s string) string { return s }))); withFilter != base {
+		t.Error("callbacks should not affect the cache key")
+	}
+}
+
+func TestCacheKeyExtraInOCRConfig(t *testing.T) {
+	config := NewExtractionConfig(WithCacheKeyExtra("pipeline-v2"), WithOCR(WithOCRLanguage("deu")))
+	data, err := encodeConfig(config)
+	if err != nil {
+		t.Fatal(err)
+	}
+	var decoded map[string]any
+	if err := json.Unmarshal(data, &decoded); err != nil {
+		t.Fatal(err)
+	}
+	tesseract, _ := decoded["ocr"].(map[string]any)["tesseract_config"].(map[string]any)
+	if tesseract["cache_key_extra"] != "pipeline-v2" || tesseract["language"] != "deu" {
+		t.Fatalf("expected the cache key extra and language in the Tesseract config: %s", data)
+	}
+	if config.OCR.Tesseract != nil || config.CacheKeyExtra == nil {
+		t.Fatal("encodeConfig modified the config")
+	}
+}
+
+// TestCacheKeyExtraSeparatesCacheEntries verifies that OCRing the same image under two
+// extras stores two entries in the native OCR cache, which lives under the working
+// directory.
+func TestCacheKeyExtraSeparatesCacheEntries(t *testing.T) {
+	frame := renderWord("HELLO")
+	img := image.NewGray(image.Rect(0, 0, frame.width, frame.height))
+	copy(img.Pix, frame.pixels)
+	var data bytes.Buffer
+	if err := png.Encode(&data, img); err != nil {
+		t.Fatal(err)
+	}
+
+	t.Chdir(t.TempDir())
+	for _, extra := range []string{"pipeline-v1", "pipeline-v2", "pipeline-v1"} {
+		config := NewExtractionConfig(WithOCR(WithOCRBackend("tesseract"), WithOCRLanguage("eng")), WithCacheKeyExtra(extra))
+		if _, err := ExtractBytesSync(data.Bytes(), "image/png", config); err != nil {
+			t.Skipf("OCR unavailable: %v", err)
+		}
+	}
+	entries, err := filepath.Glob(filepath.Join(".kreuzberg", "ocr", "*.msgpack"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(entries) != 2 {
+		t.Fatalf("expected one OCR cache entry per extra, got %v", entries)
+	}
+}
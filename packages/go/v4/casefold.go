@@ -0,0 +1,56 @@
+package kreuzberg
+
+import (
+	"strings"
+	"unicode"
+)
+
+// ContentLower returns Content in lower case. Extraction never changes the case of Content,
+// so the original stays available; the lowercase copy is computed on first use and cached
+// until Content changes. When the detected language (see GetDetectedLanguage) is Turkish or
+// Azerbaijani, the dotted and dotless i are lowered by that language's rules: "I" becomes
+// "ı" and "İ" becomes "i". ContentLower is not safe for concurrent use.
+func (r *ExtractionResult) ContentLower() string {
+	if r == nil {
+		return ""
+	}
+	if r.contentLowerOf != r.Content {
+		if r.turkicCase() {
+			r.contentLower = strings.ToLowerSpecial(unicode.TurkishCase, r.Content)
+		} else {
+			r.contentLower = strings.ToLower(r.Content)
+		}
+		r.contentLowerOf = r.Content
+	}
+	return r.contentLower
+}
+
+// Fold returns Content with Unicode simple case folding applied, for building search keys
+// that match regardless of case: characters with several lowercase forms, such as final
+// sigma "ς" and long s "ſ", fold to the same character as their uppercase letter. Folds
+// that change the length of the text, such as "ß" to "ss", are not applied. Turkish and
+// Azerbaijani text is folded with that language's i rules, as in ContentLower.
+func (r *ExtractionResult) Fold() string {
+	if r == nil {
+		return ""
+	}
+	if r.turkicCase() {
+		return strings.Map(func(c rune) rune {
+			return unicode.TurkishCase.ToLower(unicode.TurkishCase.ToUpper(c))
+		}, r.Content)
+	}
+	return strings.Map(func(c rune) rune {
+		return unicode.ToLower(unicode.ToUpper(c))
+	}, r.Content)
+}
+
+// turkicCase reports whether the detected language uses the Turkish dotted and dotless i.
+func (r *ExtractionResult) turkicCase() bool {
+	language, _ := r.GetDetectedLanguage()
+	switch strings.ToLower(language) {
+	case "tr", "tur", "turkish", "az", "aze", "azerbaijani":
+		return true
+	default:
+		return false
+	}
+}
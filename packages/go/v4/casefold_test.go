@@ -0,0 +1,39 @@
+package kreuzberg
+
+import "testing"
+
+func TestContentLower(t *testing.T) {
+	result := &ExtractionResult{Content: "Hello WORLD"}
+	if got := result.ContentLower(); got != "hello world" {
+		t.Fatalf("ContentLower() = %q", got)
+	}
+	result.Content = "Changed"
+	if got := result.ContentLower(); got != "changed" {
+		t.Fatalf("ContentLower() after change = %q", got)
+	}
+	if result.Content != "Changed" {
+		t.Fatalf("Content was modified: %q", result.Content)
+	}
+
+	turkish := &ExtractionResult{Content: "İSTANBUL IRMAK", DetectedLanguages: []string{"tur"}}
+	if got := turkish.ContentLower(); got != "istanbul ırmak" {
+		t.Fatalf("Turkish ContentLower() = %q", got)
+	}
+}
+
+func TestFold(t *testing.T) {
+	result := &ExtractionResult{Content: "ΣΟΦΟΣ σοφος ſtraße"}
+	if got, want := result.Fold(), "σοφοσ σοφοσ straße"; got != want {
+		t.Fatalf("Fold() = %q, want %q", got, want)
+	}
+	upper := &ExtractionResult{Content: "ΣΟΦΟΣ"}
+	lower := &ExtractionResult{Content: "σοφος"}
+	if upper.Fold() != lower.Fold() {
+		t.Fatalf("expected %q and %q to fold alike", upper.Fold(), lower.Fold())
+	}
+
+	turkish := &ExtractionResult{Content: "Iİıi", Metadata: Metadata{Language: StringPtr("tr")}}
+	if got, want := turkish.Fold(), "ıiıi"; got != want {
+		t.Fatalf("Turkish Fold() = %q, want %q", got, want)
+	}
+}
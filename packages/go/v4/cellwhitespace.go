@@ -0,0 +1,23 @@
+package kreuzberg
+
+import "strings"
+
+// applyCellWhitespace makes the whitespace around table cells the same whichever extractor
+// produced the table. By default the leading and trailing whitespace of every cell is
+// trimmed. With PreserveCellWhitespace the cells are kept as extracted and Markdown is
+// rendered again from them, keeping their spacing.
+func applyCellWhitespace(result *ExtractionResult, config *ExtractionConfig) {
+	preserve := config.PreserveCellWhitespace != nil && *config.PreserveCellWhitespace
+	for t := range result.Tables {
+		table := &result.Tables[t]
+		if preserve {
+			table.Markdown = renderTableMarkdown(table.Cells, true)
+			continue
+		}
+		for _, row := range table.Cells {
+			for col, cell := range row {
+				row[col] = strings.TrimSpace(cell)
+			}
+		}
+	}
+}
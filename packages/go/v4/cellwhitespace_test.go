@@ -0,0 +1,44 @@
+package kreuzberg
+
+import (
+	"reflect"
+	"testing"
+)
+
+func TestCellWhitespace(t *testing.T) {
+	text := "code,  amount\nA1,    42\n  B2 ,7  \n"
+	extract := func(config *ExtractionConfig) Table {
+		t.Helper()
+		result := &ExtractionResult{MimeType: "text/csv"}
+		if err := applyCSVTable(result, config, &documentSource{data: []byte(text)}); err != nil {
+			t.Fatalf("applyCSVTable failed: %v", err)
+		}
+		applyCellWhitespace(result, config)
+		return result.Tables[0]
+	}
+
+	trimmed := extract(NewExtractionConfig())
+	if want := [][]string{{"code", "amount"}, {"A1", "42"}, {"B2", "7"}}; !reflect.DeepEqual(trimmed.Cells, want) {
+		t.Fatalf("default cells = %q, want %q", trimmed.Cells, want)
+	}
+
+	preserved := extract(NewExtractionConfig(WithPreserveTableWhitespace(true)))
+	if want := [][]string{{"code", "  amount"}, {"A1", "    42"}, {"  B2 ", "7  "}}; !reflect.DeepEqual(preserved.Cells, want) {
+		t.Fatalf("preserved cells = %q, want %q", preserved.Cells, want)
+	}
+	if want := "| code |   amount |\n| --- | --- |\n| A1 |     42 |\n|   B2  | 7   |"; preserved.Markdown != want {
+		t.Fatalf("preserved markdown = %q, want %q", preserved.Markdown, want)
+	}
+
+	explicit := extract(NewExtractionConfig(WithPreserveTableWhitespace(false)))
+	if !reflect.DeepEqual(explicit.Cells, trimmed.Cells) || explicit.Markdown != trimmed.Markdown {
+		t.Fatal("WithPreserveTableWhitespace(false) differs from the default")
+	}
+}
+
+func TestRenderTableMarkdownPreservesSpacing(t *testing.T) {
+	got := renderTableMarkdown([][]string{{"a  b"}, {"x\ny"}}, true)
+	if want := "| a  b |\n| --- |\n| x y |"; got != want {
+		t.Fatalf("renderTableMarkdown = %q, want %q", got, want)
+	}
+}
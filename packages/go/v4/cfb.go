@@ -0,0 +1,215 @@
+package kreuzberg
+
+import (
+	"bytes"
+	"encoding/binary"
+	"errors"
+	"unicode/utf16"
+)
+
+// cfbSignature starts every Compound File Binary (OLE2) container. Legacy Office formats
+// and encrypted OOXML packages are stored in this container.
+var cfbSignature = []byte{0xD0, 0xCF, 0x11, 0xE0, 0xA1, 0xB1, 0x1A, 0xE1}
+
+// Special sector numbers from the CFB specification.
+const (
+	cfbEndOfChain     = 0xFFFFFFFE
+	cfbFreeSector     = 0xFFFFFFFF
+	cfbMaxSectors     = 1 << 24
+	cfbHeaderSize     = 512
+	cfbDirEntrySize   = 128
+	cfbMiniSectorSize = 64
+)
+
+var errInvalidCFB = errors.New("invalid compound file")
+
+func isCFB(data []byte) bool {
+	return bytes.HasPrefix(data, cfbSignature)
+}
+
+// cfbFile is a read-only view of a Compound File Binary container.
+type cfbFile struct {
+	data        []byte
+	sectorSize  int
+	fat         []uint32
+	miniFAT     []uint32
+	miniStream  []byte
+	miniCutoff  uint64
+	entries     []cfbEntry
+	entryByName map[string]int
+}
+
+type cfbEntry struct {
+	name   string
+	kind   byte
+	start  uint32
+	size   uint64
+	isRoot bool
+}
+
+func parseCFB(data []byte) (*cfbFile, error) {
+	if len(data) < cfbHeaderSize || !isCFB(data) {
+		return nil, errInvalidCFB
+	}
+	le := binary.LittleEndian
+	shift := le.Uint16(data[0x1E:])
+	if shift != 9 && shift != 12 {
+		return nil, errInvalidCFB
+	}
+	f := &cfbFile{
+		data:        data,
+		sectorSize:  1 << shift,
+		miniCutoff:  uint64(le.Uint32(data[0x38:])),
+		entryByName: make(map[string]int),
+	}
+
+	// The header holds the first 109 FAT sector numbers; the rest live in the DIFAT chain.
+	var fatSectors []uint32
+	for i := range 109 {
+		if sector := le.Uint32(data[0x4C+4*i:]); sector < cfbEndOfChain {
+			fatSectors = append(fatSectors, sector)
+		}
+	}
+	// A DIFAT sector may appear only once, and the chain cannot be longer than the file
+	// has sectors; a looping chain would otherwise add FAT sectors without end.
+	perDIFAT := f.sectorSize/4 - 1
+	visited := make(map[uint32]bool)
+	for sector := le.Uint32(data[0x44:]); sector < cfbEndOfChain; {
+		block, ok := f.sector(sector)
+		if !ok || visited[sector] || len(visited) >= len(data)/f.sectorSize {
+			return nil, errInvalidCFB
+		}
+		visited[sector] = true
+		for i := range perDIFAT {
+			if s := le.Uint32(block[4*i:]); s < cfbEndOfChain {
+				fatSectors = append(fatSectors, s)
+			}
+		}
+		sector = le.Uint32(block[4*perDIFAT:])
+	}
+	for _, sector := range fatSectors {
+		block, ok := f.sector(sector)
+		if !ok {
+			return nil, errInvalidCFB
+		}
+		for i := 0; i < len(block); i += 4 {
+			f.fat = append(f.fat, le.Uint32(block[i:]))
+		}
+	}
+
+	dir, err := cfbChain(le.Uint32(data[0x30:]), f.fat, f.sectorSize, f.sector)
+	if err != nil {
+		return nil, err
+	}
+	for off := 0; off+cfbDirEntrySize <= len(dir); off += cfbDirEntrySize {
+		raw := dir[off : off+cfbDirEntrySize]
+		kind := raw[66]
+		if kind == 0 {
+			continue
+		}
+		nameLen := int(le.Uint16(raw[64:]))
+		if nameLen > 64 {
+			nameLen = 64
+		}
+		units := make([]uint16, 0, nameLen/2)
+		for i := 0; i+1 < nameLen; i += 2 {
+			if u := le.Uint16(raw[i:]); u != 0 {
+				units = append(units, u)
+			}
+		}
+		size := le.Uint64(raw[120:])
+		if f.sectorSize == 512 {
+			size &= 0xFFFFFFFF
+		}
+		entry := cfbEntry{
+			name:   string(utf16.Decode(units)),
+			kind:   kind,
+			start:  le.Uint32(raw[116:]),
+			size:   size,
+			isRoot: kind == 5,
+		}
+		if _, dup := f.entryByName[entry.name]; !dup {
+			f.entryByName[entry.name] = len(f.entries)
+		}
+		f.entries = append(f.entries, entry)
+	}
+	if len(f.entries) == 0 || !f.entries[0].isRoot {
+		return nil, errInvalidCFB
+	}
+
+	if miniFAT, err := cfbChain(le.Uint32(data[0x3C:]), f.fat, f.sectorSize, f.sector); err == nil {
+		for i := 0; i+4 <= len(miniFAT); i += 4 {
+			f.miniFAT = append(f.miniFAT, le.Uint32(miniFAT[i:]))
+		}
+	}
+	root := f.entries[0]
+	if stream, err := cfbChain(root.start, f.fat, f.sectorSize, f.sector); err == nil {
+		f.miniStream = stream
+	}
+	return f, nil
+}
+
+func (f *cfbFile) sector(n uint32) ([]byte, bool) {
+	start := (int(n) + 1) * f.sectorSize
+	if n >= cfbMaxSectors || start+f.sectorSize > len(f.data) {
+		return nil, false
+	}
+	return f.data[start : start+f.sectorSize], true
+}
+
+func (f *cfbFile) miniSector(n uint32) ([]byte, bool) {
+	start := int(n) * cfbMiniSectorSize
+	if n >= cfbMaxSectors || start+cfbMiniSectorSize > len(f.miniStream) {
+		return nil, false
+	}
+	return f.miniStream[start : start+cfbMiniSectorSize], true
+}
+
+// cfbChain concatenates the sectors of the chain starting at start, following table.
+func cfbChain(start uint32, table []uint32, size int, read func(uint32) ([]byte, bool)) ([]byte, error) {
+	var out []byte
+	for sector, n := start, 0; sector != cfbEndOfChain; n++ {
+		if sector == cfbFreeSector || int(sector) >= len(table) || n > len(table) {
+			if n == 0 && sector == cfbFreeSector {
+				return nil, nil
+			}
+			return nil, errInvalidCFB
+		}
+		block, ok := read(sector)
+		if !ok || len(block) != size {
+			return nil, errInvalidCFB
+		}
+		out = append(out, block...)
+		sector = table[sector]
+	}
+	return out, nil
+}
+
+// hasStream reports whether the container holds a stream with the given name.
+func (f *cfbFile) hasStream(name string) bool {
+	idx, ok := f.entryByName[name]
+	return ok && f.entries[idx].kind == 2
+}
+
+// stream returns the contents of the named stream.
+func (f *cfbFile) stream(name string) ([]byte, error) {
+	idx, ok := f.entryByName[name]
+	if !ok || f.entries[idx].kind != 2 {
+		return nil, errInvalidCFB
+	}
+	entry := f.entries[idx]
+	var data []byte
+	var err error
+	if entry.size < f.miniCutoff {
+		data, err = cfbChain(entry.start, f.miniFAT, cfbMiniSectorSize, f.miniSector)
+	} else {
+		data, err = cfbChain(entry.start, f.fat, f.sectorSize, f.sector)
+	}
+	if err != nil {
+		return nil, err
+	}
+	if uint64(len(data)) < entry.size {
+		return nil, errInvalidCFB
+	}
+	return data[:entry.size], nil
+}
@@ -0,0 +1,107 @@
+package kreuzberg
+
+import (
+	"slices"
+	"strings"
+	"unicode/utf8"
+)
+
+// CoalesceChunks returns the chunks of the result with every chunk of fewer than minChars
+// characters merged into its neighbor, for documents whose short paragraphs were chunked
+// into fragments too small to retrieve well. A small chunk is merged into the chunk before
+// it, or the first chunk into the one after it, as long as the merged chunk is no longer
+// than the longest chunk of the result, which stands in for the chunker's maximum size.
+//
+// A merged chunk spans from the start of its first chunk to the end of its last one: its
+// content is that range of Content, so overlapping text is not repeated, or the chunks
+// joined by a blank line when their offsets do not fit Content. Its pages range from the
+// first to the last page of its chunks, its TokenCount is their sum when all of them have
+// one, and its SectionPath is the path its chunks share. Embedding and ID are cleared,
+// since they no longer match the content. ChunkIndex and TotalChunks are renumbered.
+// r.Chunks is not modified; a minChars of 0 or less returns a copy of it.
+func (r *ExtractionResult) CoalesceChunks(minChars int) []Chunk {
+	if r == nil || len(r.Chunks) == 0 {
+		return nil
+	}
+	chunks := slices.Clone(r.Chunks)
+	if minChars <= 0 {
+		return chunks
+	}
+	limit := 0
+	for _, chunk := range chunks {
+		limit = max(limit, utf8.RuneCountInString(chunk.Content))
+	}
+
+	out := chunks[:0:0]
+	for _, chunk := range chunks {
+		if n := len(out); n > 0 {
+			last := out[n-1]
+			small := utf8.RuneCountInString(last.Content) < minChars || utf8.RuneCountInString(chunk.Content) < minChars
+			if merged := r.mergeChunks(last, chunk); small && utf8.RuneCountInString(merged.Content) <= limit {
+				out[n-1] = merged
+				continue
+			}
+		}
+		out = append(out, chunk)
+	}
+	for i := range out {
+		out[i].Metadata.ChunkIndex = i
+		out[i].Metadata.TotalChunks = len(out)
+	}
+	return out
+}
+
+// mergeChunks returns the chunk spanning a and b, which follows a in the result.
+func (r *ExtractionResult) mergeChunks(a, b Chunk) Chunk {
+	merged := Chunk{Metadata: a.Metadata}
+	start, end := a.Metadata.ByteStart, b.Metadata.ByteEnd
+	if r.chunkInContent(a) && r.chunkInContent(b) && b.Metadata.ByteStart >= start && end >= a.Metadata.ByteEnd {
+		merged.Content = r.Content[start:end]
+	} else {
+		merged.Content = strings.TrimRight(a.Content, "\n") + "\n\n" + strings.TrimLeft(b.Content, "\n")
+	}
+	merged.Metadata.ByteEnd = max(end, a.Metadata.ByteEnd)
+
+	merged.Metadata.FirstPage = minPage(a.Metadata.FirstPage, b.Metadata.FirstPage)
+	if a.Metadata.LastPage != nil && b.Metadata.LastPage != nil {
+		last := max(*a.Metadata.LastPage, *b.Metadata.LastPage)
+		merged.Metadata.LastPage = &last
+	} else if a.Metadata.LastPage == nil {
+		merged.Metadata.LastPage = b.Metadata.LastPage
+	}
+	if a.Metadata.TokenCount != nil && b.Metadata.TokenCount != nil {
+		tokens := *a.Metadata.TokenCount + *b.Metadata.TokenCount
+		merged.Metadata.TokenCount = &tokens
+	} else {
+		merged.Metadata.TokenCount = nil
+	}
+	shared := 0
+	for shared < len(a.Metadata.SectionPath) && shared < len(b.Metadata.SectionPath) &&
+		a.Metadata.SectionPath[shared] == b.Metadata.SectionPath[shared] {
+		shared++
+	}
+	merged.Metadata.SectionPath = slices.Clip(a.Metadata.SectionPath[:shared])
+	if shared == 0 {
+		merged.Metadata.SectionPath = nil
+	}
+	merged.Metadata.ID = ""
+	return merged
+}
+
+// chunkInContent reports whether the offsets of chunk locate its content in r.Content.
+func (r *ExtractionResult) chunkInContent(chunk Chunk) bool {
+	start, end := chunk.Metadata.ByteStart, chunk.Metadata.ByteEnd
+	return start < end && end <= uint64(len(r.Content)) && r.Content[start:end] == chunk.Content
+}
+
+// minPage returns the lower of two optional page numbers, or the one that is set.
+func minPage(a, b *uint64) *uint64 {
+	switch {
+	case a == nil:
+		return b
+	case b == nil || *a <= *b:
+		return a
+	default:
+		return b
+	}
+}
@@ -0,0 +1,87 @@
+package kreuzberg
+
+import (
+	"strings"
+	"testing"
+)
+
+// testChunk returns the chunk of content from start to end, on pages first to last.
+func testChunk(content string, start, end int, first, last uint64, tokens int) Chunk {
+	return Chunk{
+		Content:   content[start:end],
+		Embedding: []float32{1},
+		Metadata: ChunkMetadata{
+			ByteStart:  uint64(start),
+			ByteEnd:    uint64(end),
+			FirstPage:  &first,
+			LastPage:   &last,
+			TokenCount: &tokens,
+		},
+	}
+}
+
+func TestCoalesceChunks(t *testing.T) {
+	content := "Intro.\n\nA longer paragraph about the topic at hand.\n\nShort.\n\nThe longest paragraph, which sets how long a merged chunk may grow."
+	spans := [][2]int{}
+	for start := 0; start < len(content); {
+		end := strings.Index(content[start:], "\n\n")
+		if end < 0 {
+			end = len(content) - start
+		}
+		spans = append(spans, [2]int{start, start + end})
+		start += end + 2
+	}
+	result := &ExtractionResult{Content: content}
+	for i, span := range spans {
+		result.Chunks = append(result.Chunks, testChunk(content, span[0], span[1], uint64(i+1), uint64(i+1), 2))
+	}
+	original := append([]Chunk(nil), result.Chunks...)
+
+	chunks := result.CoalesceChunks(10)
+	want := []string{
+		"Intro.\n\nA longer paragraph about the topic at hand.\n\nShort.",
+		"The longest paragraph, which sets how long a merged chunk may grow.",
+	}
+	if len(chunks) != len(want) {
+		t.Fatalf("got %d chunks: %+v", len(chunks), chunks)
+	}
+	for i, chunk := range chunks {
+		meta := chunk.Metadata
+		if chunk.Content != want[i] || content[meta.ByteStart:meta.ByteEnd] != want[i] {
+			t.Errorf("chunk %d = %q, want %q", i, chunk.Content, want[i])
+		}
+		if meta.ChunkIndex != i || meta.TotalChunks != 2 {
+			t.Errorf("chunk %d metadata not updated: %+v", i, chunk)
+		}
+	}
+	if m := chunks[0].Metadata; *m.FirstPage != 1 || *m.LastPage != 3 || *m.TokenCount != 6 || chunks[0].Embedding != nil {
+		t.Errorf("merged pages %d-%d, tokens %d, want 1-3 and 6", *m.FirstPage, *m.LastPage, *m.TokenCount)
+	}
+	if chunks[1].Embedding == nil {
+		t.Error("embedding of an unmerged chunk was cleared")
+	}
+
+	for i := range original {
+		if result.Chunks[i].Content != original[i].Content || result.Chunks[i].Metadata.ByteEnd != original[i].Metadata.ByteEnd {
+			t.Fatal("CoalesceChunks modified the result's chunks")
+		}
+	}
+	if got := result.CoalesceChunks(0); len(got) != len(original) {
+		t.Fatalf("minChars 0 merged chunks: %d", len(got))
+	}
+}
+
+func TestCoalesceChunksWithoutOffsets(t *testing.T) {
+	result := &ExtractionResult{Chunks: []Chunk{
+		{Content: "Hello world, this is long."},
+		{Content: "Hi."},
+		{Content: "Another chunk, the longest of the result."},
+	}}
+	chunks := result.CoalesceChunks(5)
+	if len(chunks) != 2 || chunks[0].Content != "Hello world, this is long.\n\nHi." {
+		t.Fatalf("unexpected chunks %+v", chunks)
+	}
+	if (&ExtractionResult{}).CoalesceChunks(5) != nil {
+		t.Fatal("expected no chunks")
+	}
+}
@@ -0,0 +1,76 @@
+package kreuzberg
+
+import (
+	"bufio"
+	"encoding/json"
+	"io"
+)
+
+// chunkRow is one line of the WriteChunksNDJSON output. Its fields and their order form the
+// documented schema and must not change.
+type chunkRow struct {
+	Source      int       `json:"source"`
+	ChunkIndex  int       `json:"chunk_index"`
+	Content     string    `json:"content"`
+	PageNumbers []int     `json:"page_numbers"`
+	Embedding   []float32 `json:"embedding"`
+}
+
+// WriteChunksNDJSON writes the chunks of results to w as newline-delimited JSON, one object
+// per chunk, for bulk loading into columnar stores and data lakes. Every line has the same
+// five fields, in this order:
+//
+//	source        integer         index of the chunk's result in results
+//	chunk_index   integer         position of the chunk within its result
+//	content       string          chunk text
+//	page_numbers  array of ints   pages the chunk spans, first to last; [] when unknown
+//	embedding     array or null   chunk embedding, null when embeddings were not generated
+//
+// Chunks are written in order of results and then of chunks. Nil results, such as failed
+// batch entries, and results without chunks write nothing. The schema is stable; new
+// information will only be added as new fields.
+func WriteChunksNDJSON(results []*ExtractionResult, w io.Writer) error {
+	buf := bufio.NewWriter(w)
+	enc := json.NewEncoder(buf)
+	enc.SetEscapeHTML(false)
+	for source, result := range results {
+		if result == nil {
+			continue
+		}
+		for _, chunk := range result.Chunks {
+			row := chunkRow{
+				Source:      source,
+				ChunkIndex:  chunk.Metadata.ChunkIndex,
+				Content:     chunk.Content,
+				PageNumbers: chunkPageNumbers(chunk.Metadata),
+				Embedding:   chunk.Embedding,
+			}
+			if err := enc.Encode(row); err != nil {
+				return newIOErrorWithContext("failed to write chunks", err, ErrorCodeIo, nil)
+			}
+		}
+	}
+	if err := buf.Flush(); err != nil {
+		return newIOErrorWithContext("failed to write chunks", err, ErrorCodeIo, nil)
+	}
+	return nil
+}
+
+// chunkPageNumbers lists the pages from FirstPage to LastPage. A chunk with only one of the
+// two known, or with LastPage before FirstPage, is on FirstPage or the known page.
+func chunkPageNumbers(meta ChunkMetadata) []int {
+	first, last := meta.FirstPage, meta.LastPage
+	switch {
+	case first == nil && last == nil:
+		return []int{}
+	case first == nil:
+		first = last
+	case last == nil || *last < *first:
+		last = first
+	}
+	pages := make([]int, 0, *last-*first+1)
+	for page := *first; page <= *last; page++ {
+		pages = append(pages, int(page))
+	}
+	return pages
+}
@@ -0,0 +1,50 @@
+package kreuzberg
+
+import (
+	"bytes"
+	"errors"
+	"strings"
+	"testing"
+)
+
+func TestWriteChunksNDJSON(t *testing.T) {
+	one, two, three := uint64(1), uint64(2), uint64(3)
+	results := []*ExtractionResult{
+		{Chunks: []Chunk{
+			{Content: "Intro <b>", Metadata: ChunkMetadata{ChunkIndex: 0, FirstPage: &one, LastPage: &one}},
+			{Content: "Body", Embedding: []float32{0.5, -1}, Metadata: ChunkMetadata{ChunkIndex: 1, FirstPage: &two, LastPage: &three}},
+		}},
+		nil,
+		{Content: "no chunks"},
+		{Chunks: []Chunk{{Content: "Loose", Metadata: ChunkMetadata{ChunkIndex: 0, LastPage: &two}}, {Content: "Unpaged"}}},
+	}
+	var buf bytes.Buffer
+	if err := WriteChunksNDJSON(results, &buf); err != nil {
+		t.Fatalf("WriteChunksNDJSON: %v", err)
+	}
+	want := `{"source":0,"chunk_index":0,"content":"Intro <b>","page_numbers":[1],"embedding":null}
+{"source":0,"chunk_index":1,"content":"Body","page_numbers":[2,3],"embedding":[0.5,-1]}
+{"source":3,"chunk_index":0,"content":"Loose","page_numbers":[2],"embedding":null}
+{"source":3,"chunk_index":0,"content":"Unpaged","page_numbers":[],"embedding":null}
+`
+	if buf.String() != want {
+		t.Fatalf("output:\n%s\nwant:\n%s", buf.String(), want)
+	}
+
+	buf.Reset()
+	if err := WriteChunksNDJSON(nil, &buf); err != nil || buf.Len() != 0 {
+		t.Fatalf("empty input wrote %q, %v", buf.String(), err)
+	}
+}
+
+type failingWriter struct{}
+
+func (failingWriter) Write([]byte) (int, error) { return 0, errors.New("disk full") }
+
+func TestWriteChunksNDJSONWriteError(t *testing.T) {
+	results := []*ExtractionResult{{Chunks: []Chunk{{Content: strings.Repeat("x", 10)}}}}
+	var ioErr *IOError
+	if err := WriteChunksNDJSON(results, failingWriter{}); !errors.As(err, &ioErr) {
+		t.Fatalf("expected IOError, got %v", err)
+	}
+}
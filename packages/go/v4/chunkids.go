@@ -0,0 +1,32 @@
+package kreuzberg
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+)
+
+// StableChunkID returns the ID that WithStableChunkIDs gives a chunk with content content of
+// the document named source: the lowercase hex-encoded SHA-256 of the UTF-8 bytes of source,
+// a zero byte, and the UTF-8 bytes of content. For example, in a shell:
+//
+//	printf '%s\0%s' "$source" "$content" | sha256sum
+func StableChunkID(source, content string) string {
+	hasher := sha256.New()
+	hasher.Write([]byte(source))
+	hasher.Write([]byte{0})
+	hasher.Write([]byte(content))
+	return hex.EncodeToString(hasher.Sum(nil))
+}
+
+// applyStableChunkIDs sets ChunkMetadata.ID on every chunk when ChunkingConfig.StableIDSource
+// is set. It runs after every stage that changes chunk content, so the ID matches the
+// content the caller gets.
+func applyStableChunkIDs(result *ExtractionResult, config *ExtractionConfig) {
+	if config.Chunking == nil || config.Chunking.StableIDSource == nil {
+		return
+	}
+	source := *config.Chunking.StableIDSource
+	for i := range result.Chunks {
+		result.Chunks[i].Metadata.ID = StableChunkID(source, result.Chunks[i].Content)
+	}
+}
@@ -0,0 +1,37 @@
+package kreuzberg
+
+import "testing"
+
+func TestApplyStableChunkIDs(t *testing.T) {
+	newResult := func() *ExtractionResult {
+		return &ExtractionResult{Chunks: []Chunk{
+			{Content: "first chunk", Metadata: ChunkMetadata{ChunkIndex: 0}},
+			{Content: "second chunk", Metadata: ChunkMetadata{ChunkIndex: 1}},
+		}}
+	}
+
+	result := newResult()
+	applyStableChunkIDs(result, NewExtractionConfig(WithChunking(WithChunkSectionTitles(true))))
+	if result.Chunks[0].Metadata.ID != "" {
+		t.Fatalf("chunk ID set without WithStableChunkIDs: %q", result.Chunks[0].Metadata.ID)
+	}
+
+	config := NewExtractionConfig(WithChunking(WithStableChunkIDs("docs/report.pdf")))
+	applyStableChunkIDs(result, config)
+	// printf 'docs/report.pdf\0first chunk' | sha256sum
+	const want = "6e7c638a589fef89c49ad450f1f2754dcc25a16b85909febb69143ee491123c8"
+	first := result.Chunks[0].Metadata.ID
+	if first != want || first != StableChunkID("docs/report.pdf", "first chunk") {
+		t.Fatalf("chunk ID = %q, want %q", first, want)
+	}
+
+	again := newResult()
+	again.Chunks[0], again.Chunks[1] = again.Chunks[1], again.Chunks[0]
+	applyStableChunkIDs(again, config)
+	if again.Chunks[1].Metadata.ID != first || again.Chunks[0].Metadata.ID != result.Chunks[1].Metadata.ID {
+		t.Fatal("chunk IDs must not depend on chunk position")
+	}
+	if first == result.Chunks[1].Metadata.ID || first == StableChunkID("docs/other.pdf", "first chunk") {
+		t.Fatal("chunk IDs must differ by content and source")
+	}
+}
@@ -0,0 +1,95 @@
+package kreuzberg
+
+import (
+	"unicode"
+	"unicode/utf8"
+)
+
+// applyChunkOverlapStrategy moves the start of each chunk that overlaps the chunk before it
+// to the boundary ChunkingConfig.OverlapStrategy asks for. Chunks whose offsets do not
+// locate their content in Content are left as they are.
+func applyChunkOverlapStrategy(result *ExtractionResult, config *ExtractionConfig) {
+	if config.Chunking == nil || config.Chunking.OverlapStrategy == nil {
+		return
+	}
+	strategy := *config.Chunking.OverlapStrategy
+	if strategy != OverlapStrategySentences && strategy != OverlapStrategyTokens {
+		return
+	}
+	content := result.Content
+	lang, _ := result.GetDetectedLanguage()
+	for i := 1; i < len(result.Chunks); i++ {
+		chunk := &result.Chunks[i]
+		start, end := int(chunk.Metadata.ByteStart), int(chunk.Metadata.ByteEnd)
+		overlapEnd := min(int(result.Chunks[i-1].Metadata.ByteEnd), end)
+		if start >= overlapEnd || !result.chunkInContent(*chunk) {
+			continue
+		}
+		at := -1
+		if strategy == OverlapStrategySentences {
+			at = nextSentenceStart(content, start, overlapEnd, lang)
+		}
+		if at < 0 {
+			at = overlapWordStart(content, start, overlapEnd)
+		}
+		if at == start || at >= end {
+			continue
+		}
+		chunk.Content = content[at:end]
+		chunk.Metadata.ByteStart = uint64(at)
+	}
+}
+
+// overlapWordStart returns the offset at which a chunk starting at start, overlapping the
+// chunk before it up to limit, starts without cutting a word: start itself when it is at the
+// start of a word, otherwise the start of the next word when that is within the overlap,
+// and the start of the word containing start when it is not.
+func overlapWordStart(text string, start, limit int) int {
+	if start == 0 || !isWordRuneAt(text, start) || !isWordRuneBefore(text, start) {
+		return skipSpace(text, start, limit)
+	}
+	i := start
+	for i < limit && isWordRuneAt(text, i) {
+		_, size := utf8.DecodeRuneInString(text[i:])
+		i += size
+	}
+	if next := skipSpace(text, i, limit); next < limit || (next == limit && !isWordRuneBefore(text, limit)) {
+		return next
+	}
+	for start > 0 && isWordRuneBefore(text, start) {
+		_, size := utf8.DecodeLastRuneInString(text[:start])
+		start -= size
+	}
+	return start
+}
+
+// skipSpace returns the offset of the first character at or after from that is not
+// whitespace, stopping at limit.
+func skipSpace(text string, from, limit int) int {
+	for from < limit {
+		r, size := utf8.DecodeRuneInString(text[from:])
+		if !unicode.IsSpace(r) {
+			break
+		}
+		from += size
+	}
+	return from
+}
+
+// isWordRuneAt reports whether the character at offset i of text belongs to a word.
+func isWordRuneAt(text string, i int) bool {
+	if i >= len(text) {
+		return false
+	}
+	r, _ := utf8.DecodeRuneInString(text[i:])
+	return !unicode.IsSpace(r)
+}
+
+// isWordRuneBefore reports whether the character before offset i of text belongs to a word.
+func isWordRuneBefore(text string, i int) bool {
+	if i <= 0 {
+		return false
+	}
+	r, _ := utf8.DecodeLastRuneInString(text[:i])
+	return !unicode.IsSpace(r)
+}
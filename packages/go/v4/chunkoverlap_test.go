@@ -0,0 +1,87 @@
+package kreuzberg
+
+import (
+	"strings"
+	"testing"
+	"unicode"
+)
+
+// testCharChunks splits content into chunks of size bytes overlapping by overlap bytes,
+// cutting anywhere like a character-based chunker.
+func testCharChunks(content string, size, overlap int) []Chunk {
+	var chunks []Chunk
+	for start := 0; start < len(content); start += size - overlap {
+		end := min(start+size, len(content))
+		chunks = append(chunks, Chunk{
+			Content:  content[start:end],
+			Metadata: ChunkMetadata{ByteStart: uint64(start), ByteEnd: uint64(end)},
+		})
+		if end == len(content) {
+			break
+		}
+	}
+	return chunks
+}
+
+func TestChunkOverlapStrategyNeverStartsMidWord(t *testing.T) {
+	content := strings.Repeat("The quick brown fox jumps over the lazy dog. Pack my box with five dozen liquor jugs! ", 6)
+	for _, strategy := range []string{OverlapStrategySentences, OverlapStrategyTokens} {
+		for size := 30; size <= 90; size += 7 {
+			for overlap := 1; overlap < size/2; overlap += 3 {
+				result := &ExtractionResult{Content: content, Chunks: testCharChunks(content, size, overlap)}
+				original := append([]Chunk(nil), result.Chunks...)
+				applyChunkOverlapStrategy(result, NewExtractionConfig(WithChunking(WithChunkOverlapStrategy(strategy))))
+				for i, chunk := range result.Chunks[1:] {
+					start, end := int(chunk.Metadata.ByteStart), int(chunk.Metadata.ByteEnd)
+					if content[start:end] != chunk.Content {
+						t.Fatalf("%s size %d overlap %d: chunk %d content does not match its offsets", strategy, size, overlap, i+1)
+					}
+					if unicode.IsSpace(rune(content[start])) || (start > 0 && !unicode.IsSpace(rune(content[start-1]))) {
+						t.Fatalf("%s size %d overlap %d: chunk %d starts mid-word: %q", strategy, size, overlap, i+1, chunk.Content)
+					}
+					if start > int(result.Chunks[i].Metadata.ByteEnd) {
+						t.Fatalf("%s size %d overlap %d: chunk %d leaves a gap after the previous chunk", strategy, size, overlap, i+1)
+					}
+					if start < int(original[i+1].Metadata.ByteStart) && strings.ContainsAny(content[start:int(original[i+1].Metadata.ByteStart)], " ") {
+						t.Fatalf("%s size %d overlap %d: chunk %d overlap grew by more than a word", strategy, size, overlap, i+1)
+					}
+				}
+			}
+		}
+	}
+}
+
+func TestChunkOverlapStrategySentences(t *testing.T) {
+	content := "First sentence is here. Second one follows it closely."
+	chunks := []Chunk{
+		{Content: content[:30], Metadata: ChunkMetadata{ByteStart: 0, ByteEnd: 30}},
+		{Content: content[16:], Metadata: ChunkMetadata{ByteStart: 16, ByteEnd: uint64(len(content))}},
+	}
+	config := NewExtractionConfig(WithChunking(WithChunkOverlapStrategy(OverlapStrategySentences)))
+	result := &ExtractionResult{Content: content, Chunks: append([]Chunk(nil), chunks...)}
+	applyChunkOverlapStrategy(result, config)
+	if got := result.Chunks[1].Content; got != "Second one follows it closely." {
+		t.Errorf("sentence overlap = %q", got)
+	}
+
+	tokens := &ExtractionResult{Content: content, Chunks: append([]Chunk(nil), chunks...)}
+	applyChunkOverlapStrategy(tokens, NewExtractionConfig(WithChunking(WithChunkOverlapStrategy(OverlapStrategyTokens))))
+	if got := tokens.Chunks[1].Content; got != "here. Second one follows it closely." {
+		t.Errorf("token overlap = %q", got)
+	}
+
+	chars := &ExtractionResult{Content: content, Chunks: append([]Chunk(nil), chunks...)}
+	applyChunkOverlapStrategy(chars, NewExtractionConfig(WithChunking(WithChunkOverlapStrategy(OverlapStrategyChars))))
+	if chars.Chunks[1].Content != content[16:] {
+		t.Errorf("chars strategy changed the overlap: %q", chars.Chunks[1].Content)
+	}
+}
+
+func TestChunkOverlapStrategyValidation(t *testing.T) {
+	if err := validateChunkingConfig(NewChunkingConfig(WithChunkOverlapStrategy("paragraphs"))); err == nil {
+		t.Fatal("expected an unknown overlap strategy to be rejected")
+	}
+	if err := validateChunkingConfig(NewChunkingConfig(WithChunkOverlapStrategy(OverlapStrategySentences))); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+}
@@ -0,0 +1,35 @@
+package kreuzberg
+
+import "context"
+
+// ChunkText splits text into chunks with the same chunker extraction uses, so text from
+// other sources is chunked consistently with extracted documents. config is validated like
+// ExtractionConfig.Chunking and chunking is enabled regardless of config.Enabled. Byte
+// offsets in ChunkMetadata refer to text as read by the plain text extractor, which is text
+// itself unless it has a byte order mark or invalid UTF-8. Registered Go extractors for
+// text/plain are not used.
+func ChunkText(text string, config *ChunkingConfig) ([]Chunk, error) {
+	if config == nil {
+		return nil, newValidationErrorWithContext("chunking config is required", nil, ErrorCodeValidation, nil)
+	}
+	if err := validateChunkingConfig(config); err != nil {
+		return nil, err
+	}
+	if text == "" {
+		return []Chunk{}, nil
+	}
+
+	chunking := *config
+	chunking.Enabled = BoolPtr(true)
+	extractConfig := &ExtractionConfig{Chunking: &chunking}
+	result, err := extractBytesNative(context.Background(), []byte(text), "text/plain", extractConfig)
+	if err != nil {
+		return nil, err
+	}
+	applyChunkOverlapStrategy(result, extractConfig)
+	applyChunkSectionTitles(result, extractConfig)
+	if result.Chunks == nil {
+		return []Chunk{}, nil
+	}
+	return result.Chunks, nil
+}
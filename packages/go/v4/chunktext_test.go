@@ -0,0 +1,44 @@
+package kreuzberg
+
+import (
+	"errors"
+	"strings"
+	"testing"
+)
+
+func TestChunkTextValidation(t *testing.T) {
+	var validationErr *ValidationError
+	if _, err := ChunkText("text", nil); !errors.As(err, &validationErr) {
+		t.Fatalf("expected a validation error for a nil config, got %v", err)
+	}
+	config := &ChunkingConfig{ChunkSize: IntPtr(10), ChunkOverlap: IntPtr(10)}
+	if _, err := ChunkText("text", config); !errors.As(err, &validationErr) {
+		t.Fatalf("expected a validation error for overlap >= size, got %v", err)
+	}
+	chunks, err := ChunkText("", &ChunkingConfig{MaxChars: IntPtr(10)})
+	if err != nil || len(chunks) != 0 {
+		t.Fatalf("expected no chunks for empty text, got %v, %v", chunks, err)
+	}
+}
+
+func TestChunkText(t *testing.T) {
+	if _, err := ExtractBytesSync([]byte("probe"), "text/plain", nil); err != nil {
+		t.Skipf("native library unavailable: %v", err)
+	}
+	text := strings.Repeat("The quick brown fox jumps over the lazy dog. ", 40)
+	chunks, err := ChunkText(text, &ChunkingConfig{MaxChars: IntPtr(200), MaxOverlap: IntPtr(20)})
+	if err != nil {
+		t.Fatalf("ChunkText failed: %v", err)
+	}
+	if len(chunks) < 2 {
+		t.Fatalf("expected several chunks, got %d", len(chunks))
+	}
+	for i, chunk := range chunks {
+		if chunk.Metadata.ChunkIndex != i || chunk.Metadata.TotalChunks != len(chunks) {
+			t.Fatalf("chunk %d has metadata %+v", i, chunk.Metadata)
+		}
+		if len(chunk.Content) > 200 {
+			t.Fatalf("chunk %d exceeds max chars: %d", i, len(chunk.Content))
+		}
+	}
+}
@@ -3,6 +3,8 @@ package kreuzberg_test
 import (
 	"context"
 	"errors"
+	"os"
+	"path/filepath"
 	"runtime"
 	"sync"
 	"sync/atomic"
@@ -735,6 +737,74 @@ func TestContextTimeoutDetection(t *testing.T) {
 	}
 }
 
+// TestContextDeadlineInterruptsLongExtraction verifies that a deadline expiring while a
+// long OCR extraction is running returns promptly instead of waiting for the native call.
+func TestContextDeadlineInterruptsLongExtraction(t *testing.T) {
+	if testing.Short() {
+		t.Skip("skipping long-running OCR extraction in short mode")
+	}
+
+	scanned := filepath.Join("..", "..", "..", "test_documents", "pdfs", "scanned.pdf")
+	if _, err := os.Stat(scanned); err != nil {
+		t.Skipf("scanned fixture not available: %v", err)
+	}
+
+	// WithUseCache does not reach the Tesseract OCR cache, which would otherwise let the
+	// timed run reuse the pages OCR'd by the first one and finish before the deadline.
+	config := kreuzberg.NewExtractionConfig(
+		kreuzberg.WithUseCache(false),
+		kreuzberg.WithForceOCR(true),
+		kreuzberg.WithOCR(kreuzberg.WithOCRBackend("tesseract"), kreuzberg.WithTesseract(kreuzberg.WithTesseractUseCache(false))),
+	)
+
+	start := time.Now()
+	if _, err := kreuzberg.ExtractFileSync(scanned, config); err != nil {
+		t.Skipf("OCR extraction unavailable: %v", err)
+	}
+	fullDuration := time.Since(start)
+	if fullDuration < time.Second {
+		t.Skipf("full extraction took %v; need a multi-second document to observe interruption", fullDuration)
+	}
+
+	initialGoroutines := runtime.NumGoroutine()
+
+	ctx, cancel := context.WithTimeout(context.Background(), fullDuration/10)
+	defer cancel()
+
+	start = time.Now()
+	result, err := kreuzberg.ExtractFileWithContext(ctx, scanned, config)
+	elapsed := time.Since(start)
+
+	if !errors.Is(err, context.DeadlineExceeded) {
+		t.Fatalf("expected context.DeadlineExceeded, got result=%v err=%v", result, err)
+	}
+	if result != nil {
+		t.Errorf("expected nil result after deadline, got %v", result)
+	}
+	if elapsed > fullDuration/2 {
+		t.Errorf("deadline not honored promptly: returned after %v, full extraction takes %v", elapsed, fullDuration)
+	}
+
+	// Any follow-up call queues behind the abandoned extraction, so once it returns the
+	// background goroutine has delivered its result and must exit.
+	if _, err := kreuzberg.ExtractBytesSync([]byte("after deadline"), "text/plain", nil); err != nil {
+		t.Fatalf("follow-up extraction failed: %v", err)
+	}
+
+	leaked := 0
+	for i := 0; i < 50; i++ {
+		runtime.GC()
+		leaked = runtime.NumGoroutine() - initialGoroutines
+		if leaked <= 1 {
+			break
+		}
+		time.Sleep(10 * time.Millisecond)
+	}
+	if leaked > 1 {
+		t.Errorf("abandoned extraction leaked %d goroutines", leaked)
+	}
+}
+
 // TestConcurrentConfigUsage verifies that configuration objects can be safely
 // used across multiple concurrent extraction operations.
 func TestConcurrentConfigUsage(t *testing.T) {
@@ -0,0 +1,95 @@
+package kreuzberg
+
+import (
+	"regexp"
+	"strconv"
+	"strings"
+)
+
+// hocrWordConfidence matches the confidence Tesseract records on each ocrx_word of hOCR.
+var hocrWordConfidence = regexp.MustCompile(`class=['"]ocrx_word['"][^>]*?x_wconf (\d+)`)
+
+// ConfidenceReport summarizes how reliable an extraction is, for routing documents to
+// review. Scores range from 0 (unreliable) to 1 (reliable); a score is nil when its
+// processing did not run or reported nothing to aggregate.
+type ConfidenceReport struct {
+	// Overall is the mean of the scores that are set.
+	Overall float64 `json:"overall"`
+	// OCR is the mean word confidence Tesseract reported. It is set when hOCR output is
+	// available; see WithOCRHOCR.
+	OCR *float64 `json:"ocr,omitempty"`
+	// TableFillRatio is a heuristic, not a confidence reported by table detection, which
+	// reports none: the share of non-empty cells in the tables detected in PDFs and images.
+	// Badly aligned words tend to leave cells empty, but sparse tables score low too. Tables
+	// read from the structure of other formats are not counted.
+	TableFillRatio *float64 `json:"table_fill_ratio,omitempty"`
+}
+
+// applyConfidence fills result.Confidence from the per-element confidences of the result,
+// or leaves it nil when no modality has any.
+func applyConfidence(result *ExtractionResult) {
+	report := ConfidenceReport{
+		OCR:            hocrConfidence(result.HOCR),
+		TableFillRatio: tableFillRatio(result),
+	}
+	var sum float64
+	count := 0
+	for _, score := range []*float64{report.OCR, report.TableFillRatio} {
+		if score != nil {
+			sum += *score
+			count++
+		}
+	}
+	if count == 0 {
+		return
+	}
+	report.Overall = sum / float64(count)
+	result.Confidence = &report
+}
+
+// hocrConfidence returns the mean confidence of the words of hocr, scaled to 0..1, or nil
+// when it has no word confidences.
+func hocrConfidence(hocr string) *float64 {
+	if hocr == "" {
+		return nil
+	}
+	var sum float64
+	count := 0
+	for _, m := range hocrWordConfidence.FindAllStringSubmatch(hocr, -1) {
+		confidence, err := strconv.ParseFloat(m[1], 64)
+		if err != nil {
+			continue
+		}
+		sum += min(confidence, 100) / 100
+		count++
+	}
+	if count == 0 {
+		return nil
+	}
+	mean := sum / float64(count)
+	return &mean
+}
+
+// tableFillRatio returns the share of non-empty cells over the tables of a PDF or image
+// result, or nil when there are no such cells.
+func tableFillRatio(result *ExtractionResult) *float64 {
+	if !isPDFMimeType(result.MimeType) && !strings.HasPrefix(normalizeMimeType(result.MimeType), "image/") {
+		return nil
+	}
+	filled, total := 0, 0
+	for _, table := range result.Tables {
+		for _, row := range table.Cells {
+			for _, cell := range row {
+				total++
+				if strings.TrimSpace(cell) != "" {
+					filled++
+				}
+			}
+		}
+	}
+	if total == 0 {
+		return nil
+	}
+	share := float64(filled) / float64(total)
+	return &share
+}
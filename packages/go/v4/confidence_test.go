@@ -0,0 +1,41 @@
+package kreuzberg
+
+import (
+	"math"
+	"testing"
+)
+
+func TestApplyConfidence(t *testing.T) {
+	result := &ExtractionResult{
+		MimeType: "image/png",
+		HOCR: `<div class='ocr_page' id='page_1' title='bbox 0 0 100 50'>
+<span class='ocrx_word' id='word_1_1' title='bbox 1 1 20 10; x_wconf 90'>Hello</span>
+<span class='ocrx_word' id='word_1_2' title='bbox 25 1 50 10; x_wconf 70'>world</span>
+</div>`,
+		Tables: []Table{{Cells: [][]string{{"a", "b"}, {"c", " "}}}},
+	}
+	applyConfidence(result)
+
+	report := result.Confidence
+	if report == nil || report.OCR == nil || report.TableFillRatio == nil {
+		t.Fatalf("expected OCR and table scores, got %+v", report)
+	}
+	if math.Abs(*report.OCR-0.8) > 1e-9 {
+		t.Errorf("OCR = %v, want 0.8", *report.OCR)
+	}
+	if *report.TableFillRatio != 0.75 {
+		t.Errorf("TableFillRatio = %v, want 0.75", *report.TableFillRatio)
+	}
+	if math.Abs(report.Overall-0.775) > 1e-9 {
+		t.Errorf("Overall = %v, want 0.775", report.Overall)
+	}
+}
+
+func TestApplyConfidenceWithoutScores(t *testing.T) {
+	// DOCX tables are read from the document structure, not detected.
+	result := &ExtractionResult{MimeType: mimeTypeDOCX, Tables: []Table{{Cells: [][]string{{"a", ""}}}}}
+	applyConfidence(result)
+	if result.Confidence != nil {
+		t.Fatalf("expected no confidence report, got %+v", result.Confidence)
+	}
+}
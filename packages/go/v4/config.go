@@ -1,8 +1,11 @@
 package kreuzberg
 
 import (
+	"bytes"
 	"encoding/json"
 	"fmt"
+	"reflect"
+	"strings"
 	"unsafe"
 )
 
@@ -12,6 +15,117 @@ import (
 */
 import "C"
 
+// encodeConfig serializes config for the native library. DocumentPasswords are passed on
+// as PDF passwords after any set in PdfOptions, since the native PDF extractor reads them
+// from there. Fields tagged native:"-" are left out; see nativeConfigJSON.
+func encodeConfig(config *ExtractionConfig) ([]byte, error) {
+	if config.SeparateHeadersFooters != nil && *config.SeparateHeadersFooters && config.Pages == nil {
+		// The native PDF extractor reports page boundaries only when page tracking is
+		// configured, and headers and footers are found per page.
+		cfg := *config
+		cfg.Pages = &PageConfig{}
+		config = &cfg
+	}
+	if len(config.DocumentPasswords) > 0 {
+		cfg := *config
+		pdf := PdfConfig{}
+		if cfg.PdfOptions != nil {
+			pdf = *cfg.PdfOptions
+		}
+		pdf.Passwords = append(append([]string(nil), pdf.Passwords...), cfg.DocumentPasswords...)
+		cfg.PdfOptions = &pdf
+		config = &cfg
+	}
+	if config.CacheKeyExtra != nil && config.OCR != nil {
+		// The native library caches OCR results, keyed by the Tesseract configuration.
+		extra := *config.CacheKeyExtra
+		cfg := *config
+		cfg.OCR = withTesseract(cfg.OCR, func(tesseract *TesseractConfig) { tesseract.CacheKeyExtra = extra })
+		config = &cfg
+	}
+	if textPositionsEnabled(config) && config.OCR != nil && strings.EqualFold(ocrBackendName(config.OCR), "tesseract") &&
+		(config.OCR.Tesseract == nil || !config.OCR.Tesseract.IncludeHOCR) {
+		// Recognized text is located from the hOCR Tesseract returns along with it.
+		cfg := *config
+		cfg.OCR = withTesseract(cfg.OCR, func(tesseract *TesseractConfig) { tesseract.IncludeHOCR = true })
+		config = &cfg
+	}
+	return nativeConfigJSON(config)
+}
+
+// nativeConfigJSON returns the JSON of config without the fields tagged native:"-", which
+// the Go binding applies itself and the native library has no use for.
+func nativeConfigJSON(config *ExtractionConfig) ([]byte, error) {
+	data, err := json.Marshal(config)
+	if err != nil {
+		return nil, err
+	}
+	var fields map[string]any
+	decoder := json.NewDecoder(bytes.NewReader(data))
+	decoder.UseNumber()
+	if err := decoder.Decode(&fields); err != nil {
+		return nil, err
+	}
+	dropBindingFields(fields, reflect.TypeFor[ExtractionConfig]())
+	return json.Marshal(fields)
+}
+
+// dropBindingFields deletes from value, the decoded JSON of a value of type t, the fields
+// tagged native:"-", at any depth.
+func dropBindingFields(value any, t reflect.Type) {
+	for t.Kind() == reflect.Pointer || t.Kind() == reflect.Slice {
+		t = t.Elem()
+	}
+	switch v := value.(type) {
+	case map[string]any:
+		if t.Kind() != reflect.Struct {
+			return
+		}
+		for i := range t.NumField() {
+			field := t.Field(i)
+			name, _, _ := strings.Cut(field.Tag.Get("json"), ",")
+			if name == "" || name == "-" {
+				continue
+			}
+			if field.Tag.Get("native") == "-" {
+				delete(v, name)
+			} else if child, ok := v[name]; ok {
+				dropBindingFields(child, field.Type)
+			}
+		}
+	case []any:
+		for _, item := range v {
+			dropBindingFields(item, t)
+		}
+	}
+}
+
+// addBindingFields copies the fields tagged native:"-" of full, the Go JSON of a value of
+// type t, into native, the native library's JSON of the same value, at any depth.
+func addBindingFields(native, full any, t reflect.Type) {
+	for t.Kind() == reflect.Pointer {
+		t = t.Elem()
+	}
+	nativeFields, ok := native.(map[string]any)
+	fullFields, fullOK := full.(map[string]any)
+	if !ok || !fullOK || t.Kind() != reflect.Struct {
+		return
+	}
+	for i := range t.NumField() {
+		field := t.Field(i)
+		name, _, _ := strings.Cut(field.Tag.Get("json"), ",")
+		value, ok := fullFields[name]
+		if name == "" || name == "-" || !ok {
+			continue
+		}
+		if field.Tag.Get("native") == "-" {
+			nativeFields[name] = value
+		} else if child, ok := nativeFields[name]; ok {
+			addBindingFields(child, value, field.Type)
+		}
+	}
+}
+
 // ConfigFromJSON parses an ExtractionConfig from a JSON string via FFI.
 // This is the primary method for converting JSON to a config structure.
 func ConfigFromJSON(jsonStr string) (*ExtractionConfig, error) {
@@ -35,6 +149,23 @@ func ConfigFromJSON(jsonStr string) (*ExtractionConfig, error) {
 	return cfg, nil
 }
 
+// ConfigFromJSONStrict is ConfigFromJSON, but fails on fields ExtractionConfig does not
+// have, naming the first one, so that misspelled options surface instead of being ignored.
+func ConfigFromJSONStrict(jsonStr string) (*ExtractionConfig, error) {
+	if jsonStr == "" {
+		return nil, newValidationErrorWithContext("JSON string cannot be empty", nil, ErrorCodeValidation, nil)
+	}
+
+	cfg := &ExtractionConfig{}
+	if err := decodeJSONStrict(jsonStr, cfg); err != nil {
+		return nil, newSerializationErrorWithContext(fmt.Sprintf("failed to decode config JSON: %v", err), err, ErrorCodeValidation, nil)
+	}
+	if _, err := ConfigFromJSON(jsonStr); err != nil {
+		return nil, err
+	}
+	return cfg, nil
+}
+
 // IsValidJSON validates a JSON config string without fully parsing it.
 // Returns true if the JSON is valid, false otherwise.
 func IsValidJSON(jsonStr string) bool {
@@ -49,13 +180,20 @@ func IsValidJSON(jsonStr string) bool {
 	return result == 1
 }
 
-// ConfigToJSON serializes an ExtractionConfig to a JSON string via FFI.
+// ConfigToJSON serializes an ExtractionConfig to a JSON string via FFI. The native library
+// validates the config and fills in its defaults; the fields it does not have, which the Go
+// binding applies itself, are added back, so ConfigFromJSON of the result gives the same
+// config.
 func ConfigToJSON(config *ExtractionConfig) (string, error) {
 	if config == nil {
 		return "", newValidationErrorWithContext("config cannot be nil", nil, ErrorCodeValidation, nil)
 	}
 
-	data, err := json.Marshal(config)
+	data, err := nativeConfigJSON(config)
+	if err != nil {
+		return "", newSerializationErrorWithContext("failed to encode config", err, ErrorCodeValidation, nil)
+	}
+	full, err := json.Marshal(config)
 	if err != nil {
 		return "", newSerializationErrorWithContext("failed to encode config", err, ErrorCodeValidation, nil)
 	}
@@ -76,7 +214,23 @@ func ConfigToJSON(config *ExtractionConfig) (string, error) {
 	}
 	defer C.kreuzberg_free_string(cSerialized)
 
-	return C.GoString(cSerialized), nil
+	var nativeFields, fullFields map[string]any
+	decoder := json.NewDecoder(strings.NewReader(C.GoString(cSerialized)))
+	decoder.UseNumber()
+	if err := decoder.Decode(&nativeFields); err != nil {
+		return "", newSerializationErrorWithContext("failed to decode native config JSON", err, ErrorCodeValidation, nil)
+	}
+	decoder = json.NewDecoder(bytes.NewReader(full))
+	decoder.UseNumber()
+	if err := decoder.Decode(&fullFields); err != nil {
+		return "", newSerializationErrorWithContext("failed to decode config JSON", err, ErrorCodeValidation, nil)
+	}
+	addBindingFields(nativeFields, fullFields, reflect.TypeFor[ExtractionConfig]())
+	out, err := json.Marshal(nativeFields)
+	if err != nil {
+		return "", newSerializationErrorWithContext("failed to encode config", err, ErrorCodeValidation, nil)
+	}
+	return string(out), nil
 }
 
 // ConfigGetField retrieves a specific field value from a config.
@@ -90,7 +244,7 @@ func ConfigGetField(config *ExtractionConfig, fieldName string) (interface{}, er
 		return nil, newValidationErrorWithContext("field name cannot be empty", nil, ErrorCodeValidation, nil)
 	}
 
-	data, err := json.Marshal(config)
+	data, err := nativeConfigJSON(config)
 	if err != nil {
 		return nil, newSerializationErrorWithContext("failed to encode config", err, ErrorCodeValidation, nil)
 	}
@@ -174,6 +328,147 @@ func ConfigMerge(base, override *ExtractionConfig) error {
 	if override.MaxConcurrentExtractions != nil {
 		base.MaxConcurrentExtractions = override.MaxConcurrentExtractions
 	}
+	if override.ExtractAnnotations != nil {
+		base.ExtractAnnotations = override.ExtractAnnotations
+	}
+	if override.Spreadsheet != nil {
+		base.Spreadsheet = override.Spreadsheet
+	}
+	if override.CacheKeyExtra != nil {
+		base.CacheKeyExtra = override.CacheKeyExtra
+	}
+	if override.FormFieldsInContent != nil {
+		base.FormFieldsInContent = override.FormFieldsInContent
+	}
+	if override.PartSeparator != nil {
+		base.PartSeparator = override.PartSeparator
+	}
+	if override.DocumentPasswords != nil {
+		base.DocumentPasswords = override.DocumentPasswords
+	}
+	if override.ReadingOrder != nil {
+		base.ReadingOrder = override.ReadingOrder
+	}
+	if override.RawText != nil {
+		base.RawText = override.RawText
+	}
+	if override.DetectBarcodes != nil {
+		base.DetectBarcodes = override.DetectBarcodes
+	}
+	if override.StructuredOutput != nil {
+		base.StructuredOutput = override.StructuredOutput
+	}
+	if override.WhitespaceNormalization != nil {
+		base.WhitespaceNormalization = override.WhitespaceNormalization
+	}
+	if override.RetainSource != nil {
+		base.RetainSource = override.RetainSource
+	}
+	if override.AllowPartialResults != nil {
+		base.AllowPartialResults = override.AllowPartialResults
+	}
+	if override.ExtractRevisions != nil {
+		base.ExtractRevisions = override.ExtractRevisions
+	}
+	if override.Docx != nil {
+		base.Docx = override.Docx
+	}
+	if override.ResourceTracking != nil {
+		base.ResourceTracking = override.ResourceTracking
+	}
+	if override.MimeOverrides != nil {
+		base.MimeOverrides = override.MimeOverrides
+	}
+	if override.ExtractSignatures != nil {
+		base.ExtractSignatures = override.ExtractSignatures
+	}
+	if override.SignatureRoots != nil {
+		base.SignatureRoots = override.SignatureRoots
+	}
+	if override.ExtractTextColors != nil {
+		base.ExtractTextColors = override.ExtractTextColors
+	}
+	if override.MaxInputBytes != nil {
+		base.MaxInputBytes = override.MaxInputBytes
+	}
+	if override.DetectEncoding != nil {
+		base.DetectEncoding = override.DetectEncoding
+	}
+	if override.SourceEncoding != nil {
+		base.SourceEncoding = override.SourceEncoding
+	}
+	if override.PreserveCellWhitespace != nil {
+		base.PreserveCellWhitespace = override.PreserveCellWhitespace
+	}
+	if override.TableMerges != nil {
+		base.TableMerges = override.TableMerges
+	}
+	if override.LayerSelection != nil {
+		base.LayerSelection = override.LayerSelection
+	}
+	if override.MaxRecursionDepth != nil {
+		base.MaxRecursionDepth = override.MaxRecursionDepth
+	}
+	if override.MaxExtractedBytes != nil {
+		base.MaxExtractedBytes = override.MaxExtractedBytes
+	}
+	if override.PageThumbprints != nil {
+		base.PageThumbprints = override.PageThumbprints
+	}
+	if override.EmptyContentIsError != nil {
+		base.EmptyContentIsError = override.EmptyContentIsError
+	}
+	if override.NormalizeTableNumbers != nil {
+		base.NormalizeTableNumbers = override.NormalizeTableNumbers
+	}
+	if override.ParallelPages != nil {
+		base.ParallelPages = override.ParallelPages
+	}
+	if override.Bidi != nil {
+		base.Bidi = override.Bidi
+	}
+	if override.RenderPages != nil {
+		base.RenderPages = override.RenderPages
+	}
+	if override.OffsetMap != nil {
+		base.OffsetMap = override.OffsetMap
+	}
+	if override.TextPositions != nil {
+		base.TextPositions = override.TextPositions
+	}
+	if override.MaxTables != nil {
+		base.MaxTables = override.MaxTables
+	}
+	if override.TableMinArea != nil {
+		base.TableMinArea = override.TableMinArea
+	}
+	if override.MinTableRows != nil {
+		base.MinTableRows = override.MinTableRows
+	}
+	if override.MinTableCols != nil {
+		base.MinTableCols = override.MinTableCols
+	}
+	if override.TableBackend != nil {
+		base.TableBackend = override.TableBackend
+	}
+	if override.SeparateHeadersFooters != nil {
+		base.SeparateHeadersFooters = override.SeparateHeadersFooters
+	}
+	if override.HeaderFooterThreshold != nil {
+		base.HeaderFooterThreshold = override.HeaderFooterThreshold
+	}
+	if override.ContentFilter != nil {
+		base.ContentFilter = override.ContentFilter
+	}
+	if override.CellFilter != nil {
+		base.CellFilter = override.CellFilter
+	}
+	if override.Logger != nil {
+		base.Logger = override.Logger
+	}
+	if override.ContextFields != nil {
+		base.ContextFields = override.ContextFields
+	}
 
 	return nil
 }
@@ -272,6 +272,16 @@ func TestTesseractConfig_JSON_Marshaling(t *testing.T) {
 	}
 }
 
+func TestTesseractConfig_PreserveInterwordSpacing(t *testing.T) {
+	ocr := kreuzberg.NewOCRConfig(kreuzberg.WithOCRLanguage("eng"), kreuzberg.WithOCRPreserveSpacing(true))
+	if ocr.Tesseract == nil || ocr.Tesseract.PreserveInterwordSpacing == nil || !*ocr.Tesseract.PreserveInterwordSpacing {
+		t.Fatal("expected PreserveInterwordSpacing to be true")
+	}
+	if ocr.Language == nil || *ocr.Language != "eng" {
+		t.Error("expected Language to be kept")
+	}
+}
+
 func TestTesseractConfig_TableDetection(t *testing.T) {
 	config := kreuzberg.NewTesseractConfig(
 		kreuzberg.WithTesseractEnableTableDetection(true),
@@ -0,0 +1,74 @@
+package kreuzberg
+
+import (
+	"encoding/json"
+	"testing"
+	"time"
+)
+
+// TestEncodeConfigOmitsBindingFields verifies that the options the Go binding applies
+// itself are left out of the native config, at the top level and in nested configs, while
+// the native ones are kept.
+func TestEncodeConfigOmitsBindingFields(t *testing.T) {
+	config := NewExtractionConfig(
+		WithUseCache(false),
+		WithParallelPages(4),
+		WithTableMerges(true),
+		WithOCR(WithOCRBackend("tesseract"), WithOCRPageTimeout(time.Second)),
+		WithChunking(WithMaxChars(500), WithChunkSectionTitles(true)),
+		WithPages(WithExtractPages(true), WithSamplePages(3, "even"), WithPageGeometry(true)),
+	)
+	data, err := encodeConfig(config)
+	if err != nil {
+		t.Fatalf("encodeConfig failed: %v", err)
+	}
+	var decoded struct {
+		UseCache      *bool `json:"use_cache"`
+		ParallelPages any   `json:"parallel_pages"`
+		TableMerges   any   `json:"table_merges"`
+		OCR           map[string]any
+		Chunking      map[string]any
+		Pages         map[string]any
+	}
+	if err := json.Unmarshal(data, &decoded); err != nil {
+		t.Fatal(err)
+	}
+	if decoded.UseCache == nil || *decoded.UseCache || decoded.ParallelPages != nil || decoded.TableMerges != nil {
+		t.Fatalf("unexpected top-level fields in %s", data)
+	}
+	if decoded.OCR["backend"] != "tesseract" || decoded.OCR["page_timeout_millis"] != nil {
+		t.Fatalf("unexpected OCR config in %s", data)
+	}
+	if decoded.Chunking["max_chars"] == nil || decoded.Chunking["section_titles"] != nil {
+		t.Fatalf("unexpected chunking config in %s", data)
+	}
+	if decoded.Pages["extract_pages"] != true || decoded.Pages["sample_pages"] != nil || decoded.Pages["page_geometry"] != nil {
+		t.Fatalf("unexpected page config in %s", data)
+	}
+	if config.ParallelPages == nil || config.OCR.PageTimeoutMillis == nil || config.Pages.SamplePages == nil {
+		t.Fatal("encodeConfig changed the caller's config")
+	}
+}
+
+// TestConfigToJSONKeepsBindingFields verifies that ConfigToJSON round-trips the options
+// the native library does not have.
+func TestConfigToJSONKeepsBindingFields(t *testing.T) {
+	config := NewExtractionConfig(
+		WithParallelPages(4),
+		WithOCR(WithOCRBackend("tesseract"), WithOCRPageTimeout(time.Second)),
+		WithPages(WithExtractPages(true), WithPageGeometry(true)),
+	)
+	jsonStr, err := ConfigToJSON(config)
+	if err != nil {
+		t.Skipf("native config serialization unavailable: %v", err)
+	}
+	decoded, err := ConfigFromJSON(jsonStr)
+	if err != nil {
+		t.Fatalf("ConfigFromJSON failed: %v", err)
+	}
+	if decoded.ParallelPages == nil || *decoded.ParallelPages != 4 ||
+		decoded.OCR == nil || decoded.OCR.PageTimeoutMillis == nil || *decoded.OCR.PageTimeoutMillis != 1000 ||
+		decoded.Pages == nil || decoded.Pages.PageGeometry == nil || !*decoded.Pages.PageGeometry {
+		t.Fatalf("binding fields lost in %s", jsonStr)
+	}
+}
@@ -1,5 +1,11 @@
 package kreuzberg
 
+import (
+	"crypto/x509"
+	"log/slog"
+	"time"
+)
+
 // This file implements the functional options pattern for all Kreuzberg configuration types.
 // Instead of using pointer helper functions (BoolPtr, StringPtr, etc.), use the option
 // constructors defined below with NewXxxConfig functions.
@@ -127,6 +133,571 @@ func WithMaxConcurrentExtractions(max int) ExtractionOption {
 	}
 }
 
+// WithExtractAnnotations enables extraction of PDF annotations and DOCX comments into
+// ExtractionResult.Annotations.
+func WithExtractAnnotations(enabled bool) ExtractionOption {
+	return func(c *ExtractionConfig) {
+		c.ExtractAnnotations = &enabled
+	}
+}
+
+// WithCacheKeyExtra mixes extra into the cache identity of every extraction made with the
+// config: the key CacheKeyFor returns and the key of the OCR results the native library
+// caches. Changing it, for example on a pipeline upgrade, makes earlier entries unreachable
+// without clearing the whole cache.
+func WithCacheKeyExtra(extra string) ExtractionOption {
+	return func(c *ExtractionConfig) {
+		c.CacheKeyExtra = &extra
+	}
+}
+
+// WithSheets restricts spreadsheet extraction to the named sheets. Names that do not exist
+// in the workbook cause extraction to fail with a ValidationError listing the available sheets.
+func WithSheets(names ...string) ExtractionOption {
+	return func(c *ExtractionConfig) {
+		if c.Spreadsheet == nil {
+			c.Spreadsheet = &SpreadsheetOptions{}
+		}
+		c.Spreadsheet.SheetNames = names
+	}
+}
+
+// WithSheetIndices restricts spreadsheet extraction to the sheets at the given zero-based indices.
+func WithSheetIndices(indices ...int) ExtractionOption {
+	return func(c *ExtractionConfig) {
+		if c.Spreadsheet == nil {
+			c.Spreadsheet = &SpreadsheetOptions{}
+		}
+		c.Spreadsheet.SheetIndices = indices
+	}
+}
+
+// WithExtractRevisions fills ExtractionResult.Revisions with the tracked changes of DOCX
+// documents: insertions, deletions and formatting changes, in document order, with their
+// author and date. Changes without text, such as inserted paragraph marks, are not reported.
+func WithExtractRevisions(enabled bool) ExtractionOption {
+	return func(c *ExtractionConfig) {
+		c.ExtractRevisions = &enabled
+	}
+}
+
+// WithExtractSignatures fills ExtractionResult.Signatures with the signed signature fields
+// of PDFs. A signature is Valid when the digest of its signed byte range matches, its
+// signature of every signer verifies with the signing certificate embedded in it, and that
+// certificate chains through the other embedded certificates to a trusted root at the
+// current time; see WithSignatureRoots. The signing time a signature claims is not trusted
+// and timestamp tokens are not read, so a signature made with a since-expired certificate
+// is not valid. Signatures in the adbe.pkcs7.detached, ETSI.CAdES.detached and
+// adbe.pkcs7.sha1 formats are checked; others are reported as not valid. The reason a
+// signature is not valid is given in a WarningCodeSignatureInvalid warning. Revocation is
+// not checked; see Signature.RevocationChecked.
+func WithExtractSignatures(enabled bool) ExtractionOption {
+	return func(c *ExtractionConfig) {
+		c.ExtractSignatures = &enabled
+	}
+}
+
+// WithSignatureRoots sets the root certificates that WithExtractSignatures trusts, for
+// example an organization's own CA or the Adobe Approved Trust List. By default the system
+// roots are trusted.
+func WithSignatureRoots(roots *x509.CertPool) ExtractionOption {
+	return func(c *ExtractionConfig) {
+		c.SignatureRoots = roots
+	}
+}
+
+// WithExtractTextColors fills ExtractionResult.ColoredSpans with the PDF text drawn in a
+// color other than black and the text under a highlight, either a Highlight annotation or
+// a rectangle filled in a color behind the text. Adjacent strings of the same color on a
+// line form one span. Text is read from the content streams, so pages whose fonts have no
+// Unicode mapping are skipped.
+func WithExtractTextColors(enabled bool) ExtractionOption {
+	return func(c *ExtractionConfig) {
+		c.ExtractTextColors = &enabled
+	}
+}
+
+// WithMaxInputBytes refuses inputs larger than n bytes, as a guard against oversized
+// uploads to a service. Bytes are checked before any processing and files before they are
+// read, so an oversized input fails with a ValidationError whose cause is an
+// *InputSizeError giving the actual and allowed sizes. Unlike WithMaxPages it applies to
+// every format.
+func WithMaxInputBytes(n int) ExtractionOption {
+	return func(c *ExtractionConfig) {
+		c.MaxInputBytes = &n
+	}
+}
+
+// WithMaxRecursionDepth limits how deep archives nested in a ZIP archive are opened, as a
+// guard against zip bombs in untrusted uploads. The text of a ZIP member that is itself a
+// ZIP archive is extracted and added to Content as the part of that member, and so on for
+// the archives it contains, down to n levels below the archive being extracted; 0 extracts
+// the outer archive only. Archives below the limit are skipped with a
+// WarningCodeRecursionLimit warning. The default is DefaultMaxRecursionDepth. Archives
+// attached to emails are not opened.
+func WithMaxRecursionDepth(n int) ExtractionOption {
+	return func(c *ExtractionConfig) {
+		c.MaxRecursionDepth = &n
+	}
+}
+
+// WithMaxExtractedBytes caps the total uncompressed size of the nested archives opened under
+// WithMaxRecursionDepth while extracting one document at n bytes. The archive that would
+// exceed the cap, and every nested archive after it, is skipped with a
+// WarningCodeExtractedBytesLimit warning. The default is DefaultMaxExtractedBytes.
+func WithMaxExtractedBytes(n int) ExtractionOption {
+	return func(c *ExtractionConfig) {
+		c.MaxExtractedBytes = &n
+	}
+}
+
+// WithDetectEncoding converts plain text and CSV documents in legacy encodings to UTF-8
+// before extraction. The encoding is taken from a byte order mark when there is one; data
+// that is valid UTF-8 is read as UTF-8, and other data as UTF-16 when its byte pattern
+// shows it, or else in whichever of Windows-1252, Shift_JIS, GBK and the encodings added
+// with RegisterEncoding decodes it into the most plausible text. The binding decodes
+// UTF-8, UTF-16, Windows-1252, ISO-8859-1, Shift_JIS and GBK itself. The encoding used is
+// reported in Metadata.SourceEncoding. Files are converted when their extension is .txt,
+// .text, .log or .csv, in batches too.
+func WithDetectEncoding(enabled bool) ExtractionOption {
+	return func(c *ExtractionConfig) {
+		c.DetectEncoding = &enabled
+	}
+}
+
+// WithSourceEncoding reads plain text and CSV documents in the encoding enc instead of
+// detecting it, for sources whose encoding is known; see WithDetectEncoding. enc is one of
+// the Encoding constants, an alias such as "latin1", "cp1252" or "sjis", or an encoding
+// added with RegisterEncoding. A byte order mark still selects between the UTF encodings.
+func WithSourceEncoding(enc string) ExtractionOption {
+	return func(c *ExtractionConfig) {
+		c.SourceEncoding = &enc
+	}
+}
+
+// WithTrackedChangesMode selects how the tracked changes of DOCX documents appear in the
+// content. TrackedChangesAccepted (the default) shows the document with every change
+// accepted: inserted text is kept and deleted text left out. TrackedChangesRejected shows it
+// with every change rejected, as it was before the tracked edits. TrackedChangesOriginal
+// keeps all text stored in the document, both inserted and deleted. Formatting changes do
+// not affect the content. Other modes fail validation.
+//
+// Paragraphs are rewritten in the content after extraction: a changed paragraph whose
+// accepted text is not found verbatim in the content, for example because Markdown
+// formatting was added, or that has no accepted text, is left as extracted. Chunks and
+// page boundaries computed by the native extractor still describe the accepted text.
+func WithTrackedChangesMode(mode string) ExtractionOption {
+	return func(c *ExtractionConfig) {
+		if c.Docx == nil {
+			c.Docx = &DocxOptions{}
+		}
+		c.Docx.TrackedChangesMode = mode
+	}
+}
+
+// WithSeparateHeadersFooters moves running headers and footers out of the content of
+// paginated documents into ExtractionResult.Headers and ExtractionResult.Footers. A line is
+// a header (footer) when it is among the first (last) two lines of enough pages; numbers
+// are ignored when comparing lines, so "Page 3 of 10" repeats on every page. Detection needs
+// at least three pages with known boundaries, which PDFs and multi-page TIFFs have. Page
+// boundaries and chunk offsets are moved to match, and chunk text is updated when it was
+// taken verbatim from the content. See WithHeaderFooterThreshold.
+func WithSeparateHeadersFooters(enabled bool) ExtractionOption {
+	return func(c *ExtractionConfig) {
+		c.SeparateHeadersFooters = &enabled
+	}
+}
+
+// WithHeaderFooterThreshold sets the fraction of pages, greater than 0 and at most 1, on
+// which a line must repeat to be a header or footer. The default is 0.5; a line must always
+// repeat on at least two pages.
+func WithHeaderFooterThreshold(threshold float64) ExtractionOption {
+	return func(c *ExtractionConfig) {
+		c.HeaderFooterThreshold = &threshold
+	}
+}
+
+// WithCSVDelimiter sets the field delimiter of CSV and TSV documents instead of detecting
+// it from the content.
+func WithCSVDelimiter(delimiter rune) ExtractionOption {
+	return func(c *ExtractionConfig) {
+		if c.Spreadsheet == nil {
+			c.Spreadsheet = &SpreadsheetOptions{}
+		}
+		c.Spreadsheet.CSVDelimiter = string(delimiter)
+	}
+}
+
+// WithFormFieldsInContent appends the filled form fields of a PDF to Content as
+// "Label: value" lines in reading order, rendering checkboxes as "[x]" or "[ ]", so that
+// form data is indexed together with the document text.
+func WithFormFieldsInContent(enabled bool) ExtractionOption {
+	return func(c *ExtractionConfig) {
+		c.FormFieldsInContent = &enabled
+	}
+}
+
+// WithPartSeparator sets the separator placed between the parts of a multi-part document,
+// such as the members of an archive, when they are concatenated into Content. The default
+// is DefaultPartSeparator. ExtractionResult.PartBoundaries records where each part starts.
+func WithPartSeparator(separator string) ExtractionOption {
+	return func(c *ExtractionConfig) {
+		c.PartSeparator = &separator
+	}
+}
+
+// WithDocumentPasswords sets the passwords tried, in order, when opening an encrypted
+// document. They apply to PDF documents (after PdfConfig.Passwords) and to encrypted DOCX,
+// XLSX and PPTX packages. A document that none of them opens fails with an
+// EncryptedDocumentError.
+func WithDocumentPasswords(passwords ...string) ExtractionOption {
+	return func(c *ExtractionConfig) {
+		c.DocumentPasswords = passwords
+	}
+}
+
+// WithReadingOrder selects how text from multi-column PDFs is ordered: ReadingOrderRaw keeps
+// the native order (the default) and ReadingOrderColumns reads the text layer one column at
+// a time. Scanned pages and text the Go reader cannot decode keep the native order.
+func WithReadingOrder(order string) ExtractionOption {
+	return func(c *ExtractionConfig) {
+		c.ReadingOrder = &order
+	}
+}
+
+// WithTableBackend selects the algorithm that detects tables in PDFs and OCR output. Names
+// other than those returned by AvailableTableBackends fail validation. When no backend is
+// set, TableBackendHeuristic is used, as before the option existed.
+//
+// TableBackendHeuristic rebuilds tables from the positions of words on the page, grouping
+// them into columns and rows. It is fast and needs no models, but it relies on the text
+// being aligned: tables without clear column gaps, with cells spanning several lines, or
+// with merged cells come out split or misaligned, and text laid out in columns can be
+// reported as a table. It ignores ruling lines.
+func WithTableBackend(name string) ExtractionOption {
+	return func(c *ExtractionConfig) {
+		c.TableBackend = &name
+	}
+}
+
+// WithRenderPages fills ExtractionResult.PageImages with an image of each page at dpi
+// pixels per inch, encoded as format (RenderFormatPNG or RenderFormatJPEG), for previews
+// and for passing whole pages to vision models. Unlike ExtractionResult.Images, which holds
+// the images embedded in the document, these show the whole page. dpi must be positive.
+// With page sampling only the sampled pages are rendered, and documents over MaxPages fail
+// before anything is rendered.
+//
+// The binding has no PDF rasterizer: PNG, JPEG and GIF documents are rendered, and PDF
+// pages are rendered from the scanned image that fills them, drawn to the page size and
+// rotation. Other pages, including PDF pages with vector text or graphics, are skipped
+// with a WarningCodePageNotRendered warning.
+func WithRenderPages(dpi int, format string) ExtractionOption {
+	return func(c *ExtractionConfig) {
+		c.RenderPages = &RenderPagesConfig{DPI: dpi, Format: format}
+	}
+}
+
+// WithEmptyContentIsError makes an extraction whose content is empty or only whitespace
+// fail with an *EmptyResultError instead of returning a successful empty result, so
+// pipelines can detect documents nothing was extracted from, such as scans without OCR,
+// with errors.As rather than by checking Content. The error holds the result for its
+// metadata and warnings. Batch extractions fail as a whole on the first empty document;
+// BatchExtractFilesFromChan reports the error of each document separately. The default, false,
+// returns empty results.
+func WithEmptyContentIsError(enabled bool) ExtractionOption {
+	return func(c *ExtractionConfig) {
+		c.EmptyContentIsError = &enabled
+	}
+}
+
+// WithParallelPages OCRs up to n pages of a scanned PDF or multi-page TIFF at once in one
+// native batch, holding only those n pages in memory. The default, 1, OCRs one at a time.
+func WithParallelPages(n int) ExtractionOption {
+	return func(c *ExtractionConfig) {
+		c.ParallelPages = &n
+	}
+}
+
+// WithPageThumbprints fills ExtractionResult.PageHashes with a perceptual hash of each page
+// image, for finding near-duplicate scans: pages that look the same have hashes a few bits
+// apart even when their OCR text differs, while any change makes content hashes differ
+// completely. Compare hashes with PageHash.Distance. The page images are the ones
+// WithRenderPages produces, made once when both are enabled, so the same pages are covered
+// and pages that cannot be rendered get a WarningCodePageNotRendered warning and no hash.
+// PageHashes stays empty when disabled.
+func WithPageThumbprints(enabled bool) ExtractionOption {
+	return func(c *ExtractionConfig) {
+		c.PageThumbprints = &enabled
+	}
+}
+
+// WithMimeOverrides maps file extensions, with or without the leading dot, to the MIME type
+// files with them are extracted as, for vendor-specific extensions of known formats such as
+// {"xyzdoc": "application/vnd.openxmlformats-officedocument.wordprocessingml.document"}.
+// They apply to extraction from paths and fs.FS files and take precedence over
+// SetMimeOverride and the built-in extension table. The content is still checked: a file
+// whose detected type rules out its override, such as a PDF named as DOCX, fails with a
+// ValidationError.
+func WithMimeOverrides(overrides map[string]string) ExtractionOption {
+	return func(c *ExtractionConfig) {
+		c.MimeOverrides = overrides
+	}
+}
+
+// WithBidi selects the order of right-to-left and bidirectional text, such as Arabic or
+// Hebrew, in Content, pages, chunks and table cells: BidiLogical gives the order in which
+// the text is read and typed, which is what storage and search need; BidiVisual gives the
+// order in which it is displayed from left to right. BidiLogical reorders PDF text on the
+// assumption that it comes out in visual order, the order it is drawn on the page, so use
+// it for PDFs whose right-to-left text reads backwards; BidiVisual reorders the text of the
+// other formats. Without WithBidi text is left in the order it was extracted in. Lines
+// without right-to-left letters are never changed. The dominant direction of the text is
+// reported in Metadata.DominantTextDirection whatever the mode.
+func WithBidi(mode string) ExtractionOption {
+	return func(c *ExtractionConfig) {
+		c.Bidi = &mode
+	}
+}
+
+// WithOffsetMap fills ExtractionResult.OffsetMap, which traces every span of Content to its
+// page and, where known, its position on the page, for tools that precompute highlight
+// indexes. For PDFs read by the raw text reader (see WithRawText) there is one entry per
+// string shown by the page's content stream, typically a word or a line, with its bounding
+// box; these are the positions LocateText uses. Words recognized by Tesseract get an entry
+// each too, as with WithTextPositions. Other paginated documents get one entry
+// per page, without a box. The map holds an entry per string of the document, so it is
+// off by default. Entries are not produced once Content is changed by a content filter.
+func WithOffsetMap(enabled bool) ExtractionOption {
+	return func(c *ExtractionConfig) {
+		c.OffsetMap = &enabled
+	}
+}
+
+// WithTextPositions records where the words recognized by Tesseract are on their page, for
+// ExtractionResult.LocateText and WithOffsetMap. Tesseract returns hOCR along with the text
+// for this, which costs time on every OCR'd page and gives OCR results a cache key of their
+// own, so it is off by default; WithOffsetMap turns it on as well. PDFs read by the raw
+// text reader always have positions.
+func WithTextPositions(enabled bool) ExtractionOption {
+	return func(c *ExtractionConfig) {
+		c.TextPositions = &enabled
+	}
+}
+
+// WithMaxTables keeps only the first n tables of each document, in document order, for
+// example the line items of an invoice; see also ExtractionResult.FirstTable. Tables
+// dropped by WithTableMinArea or WithMinTableSize do not count towards n. n must be at least 1. The native
+// library still detects every table, so this saves the work done on tables afterwards,
+// such as cell filters, rather than detection itself.
+func WithMaxTables(n int) ExtractionOption {
+	return func(c *ExtractionConfig) {
+		c.MaxTables = &n
+	}
+}
+
+// WithTableMinArea drops tables with fewer than cells cells (rows times columns), such as
+// the small tables used for layout, before WithMaxTables is applied. cells must not be
+// negative; 0 keeps every table.
+func WithTableMinArea(cells int) ExtractionOption {
+	return func(c *ExtractionConfig) {
+		c.TableMinArea = &cells
+	}
+}
+
+// WithMinTableSize drops tables with fewer than rows rows or fewer than cols columns, such
+// as the borderless one-row or two-column tables PDFs use to lay out text, so that
+// ExtractionResult.Tables holds only data tables. The text of dropped tables stays in
+// Content. The sizes are checked before WithMaxTables is applied, so small tables never
+// take up its quota. Neither may be negative; 0 disables that check.
+func WithMinTableSize(rows, cols int) ExtractionOption {
+	return func(c *ExtractionConfig) {
+		c.MinTableRows = &rows
+		c.MinTableCols = &cols
+	}
+}
+
+// WithNormalizeTableNumbers rewrites the numeric cells of tables in a canonical form that
+// strconv.ParseFloat reads, for ETL pipelines that load table cells as numbers. locale is a
+// language code with an optional region, such as "en-US", "de" or "de-CH", and selects the
+// decimal and group separators cells are read with: "$1,234.56" becomes "1234.56" in
+// "en-US" and "1.234,56 €" becomes "1234.56" in "de". Currency symbols and codes are
+// dropped, parentheses around a number, as in accounting negatives like "(500)", and the
+// minus sign U+2212 become a leading "-", and a percent sign is kept after the number.
+// Digits are otherwise kept as written, so codes with leading zeros stay intact.
+//
+// Table.RawCell returns the text of a rewritten cell as extracted. Markdown is rendered
+// again from the rewritten cells and ColumnTypes inferred again. Cells that look like
+// numbers but do not fit the locale, such as "1.234,5" in "en-US" or "1.2.3", are left as
+// they are and reported with WarningCodeNumberNotNormalized warnings. An unsupported
+// locale makes the extraction fail with a ValidationError.
+func WithNormalizeTableNumbers(locale string) ExtractionOption {
+	return func(c *ExtractionConfig) {
+		c.NormalizeTableNumbers = &locale
+	}
+}
+
+// WithRawText makes PDF extraction return the text as stored in the content streams, in
+// the order it is drawn, skipping the native layout analysis. This is much faster for
+// born-digital PDFs whose drawing order already matches the reading order, but text from
+// multi-column pages, tables and rotated content may come out interleaved, and no tables,
+// images or annotations are detected. Documents the raw reader cannot decode (encrypted
+// files, fonts without a Unicode mapping, scanned pages) use the regular extraction.
+func WithRawText(enabled bool) ExtractionOption {
+	return func(c *ExtractionConfig) {
+		c.RawText = &enabled
+	}
+}
+
+// WithDetectBarcodes fills ExtractionResult.Barcodes with the barcodes found in the
+// document. EAN-13 and Code 128 symbols are decoded from image documents and from extracted
+// images, so for PDFs and Office documents image extraction must be enabled as well. QR codes
+// are reported when the native library detects them.
+func WithDetectBarcodes(enabled bool) ExtractionOption {
+	return func(c *ExtractionConfig) {
+		c.DetectBarcodes = &enabled
+	}
+}
+
+// WithStructuredOutput fills ExtractionResult.Blocks with the paragraphs, headings, tables
+// and images of the document in reading order, so tables can be placed where they appear in
+// the text. Content, Tables and Images are populated as usual. For PPTX documents it also
+// fills ExtractionResult.Slides with the title, layout name and text of each slide, and for
+// PDFs ExtractionResult.Layers with the layers of the document.
+func WithStructuredOutput(enabled bool) ExtractionOption {
+	return func(c *ExtractionConfig) {
+		c.StructuredOutput = &enabled
+	}
+}
+
+// WithLayerSelection extracts only the text of the named layers (optional content groups) of
+// PDFs, for example to isolate the dimensions of an engineering drawing from its geometry,
+// and fills ExtractionResult.Layers with every layer of the document. Names are matched
+// ignoring case, and a selected layer is read whether or not it is visible by default. Text
+// that belongs to no layer is always extracted. The text is read by the raw text reader of
+// WithRawText, without the native layout analysis. A name the document has no layer for
+// adds a WarningCodeLayerNotFound warning and selects nothing; a document whose text the raw
+// reader cannot decode is extracted with every layer and a WarningCodeLayerSelectionIgnored
+// warning. For PDFs without layers and other formats the selection has no effect.
+func WithLayerSelection(names ...string) ExtractionOption {
+	return func(c *ExtractionConfig) {
+		c.LayerSelection = names
+	}
+}
+
+// WithWhitespaceNormalization cleans up whitespace in Content after extraction, so repeated
+// extractions compare equal and stored text is tidy. WhitespaceNone (the default) leaves
+// Content unchanged. WhitespaceTrim converts CRLF and CR line endings to LF, strips trailing
+// spaces and tabs and collapses three or more blank lines to two. WhitespaceAggressive also
+// collapses runs of spaces and tabs to one space, strips indentation and keeps single blank
+// lines only. Page boundaries, part boundaries and chunk offsets are adjusted to the
+// normalized content. Other values fail validation.
+func WithWhitespaceNormalization(mode string) ExtractionOption {
+	return func(c *ExtractionConfig) {
+		c.WhitespaceNormalization = &mode
+	}
+}
+
+// WithRetainSource stores the extracted document in ExtractionResult.SourceBytes, with its
+// MIME type in SourceMime, so ExtractionResult.ReExtract can process it again with other
+// settings. This keeps the whole document in memory (and in serialized results) for as long
+// as the result lives, so enable it only where re-extraction is needed. For
+// password-protected Office documents the decrypted document is retained.
+func WithRetainSource(enabled bool) ExtractionOption {
+	return func(c *ExtractionConfig) {
+		c.RetainSource = &enabled
+	}
+}
+
+// WithAllowPartialResults returns what could be extracted instead of an error when part of a
+// document fails. Such results have Success set to false and an ExtractionWarning for each
+// page that was left out. When the native extraction of a PDF fails, the text of the pages
+// that can still be read from the PDF content streams is returned, without the tables and
+// images the native pipeline would have found; pages of multi-page TIFFs that fail OCR are
+// skipped. Invalid input and configuration still fail with a ValidationError.
+func WithAllowPartialResults(enabled bool) ExtractionOption {
+	return func(c *ExtractionConfig) {
+		c.AllowPartialResults = &enabled
+	}
+}
+
+// WithResourceTracking sets ExtractionResult.Resources to the peak memory and CPU time of
+// the native extraction, for sizing workers and spotting pathological documents. It covers
+// single-document extractions on Linux, where the peak resident set size is read from /proc
+// and CPU time from getrusage, and leaves Resources nil elsewhere and for batches. Both are
+// measured for the whole process, so work done concurrently by other goroutines is
+// included. Tracking costs a few system calls per document and is off by default.
+func WithResourceTracking(enabled bool) ExtractionOption {
+	return func(c *ExtractionConfig) {
+		c.ResourceTracking = &enabled
+	}
+}
+
+// WithContentFilter registers fn to transform the final ExtractionResult.Content before it
+// is returned. The filter runs once per document (once per item in batch extractions) and
+// must not panic. When fn changes the content it is also run on the text of every chunk
+// and page, and their byte offsets are found again in the filtered content; offsets that
+// cannot be found are cleared. It runs on the goroutine doing the extraction, which for
+// the WithContext functions is not the caller's: after the context is cancelled it may
+// still run, on a result that is then discarded.
+func WithContentFilter(fn func(content string) string) ExtractionOption {
+	return func(c *ExtractionConfig) {
+		c.ContentFilter = fn
+	}
+}
+
+// WithPreserveTableWhitespace keeps the leading and trailing whitespace of table cells, for
+// fixed-width data where spacing is significant. By default every cell of Table.Cells is
+// trimmed, whichever format the table came from. When enabled, cells are kept as the
+// extractor produced them and Table.Markdown is rendered from them with their spacing
+// intact; line breaks within a cell become spaces. Formats whose extractor trims cells
+// itself have no whitespace to keep.
+func WithPreserveTableWhitespace(enabled bool) ExtractionOption {
+	return func(c *ExtractionConfig) {
+		c.PreserveCellWhitespace = &enabled
+	}
+}
+
+// WithTableMerges fills Table.MergedCells, and so the spans of Table.CellGrid, for DOCX
+// tables from the w:gridSpan and w:vMerge properties of their cells. The native extractor
+// reports tables without span information, so this reads word/document.xml of the document
+// a second time; without it every cell of CellGrid spans one row and one column.
+func WithTableMerges(enabled bool) ExtractionOption {
+	return func(c *ExtractionConfig) {
+		c.TableMerges = &enabled
+	}
+}
+
+// WithCellFilter registers fn to transform every cell of every extracted table. row and col
+// are zero-based indices into Table.Cells. Table.Markdown is left as produced by the
+// extractor. The filter runs synchronously and must not panic.
+func WithCellFilter(fn func(row, col int, cell string) string) ExtractionOption {
+	return func(c *ExtractionConfig) {
+		c.CellFilter = fn
+	}
+}
+
+// WithContextFields copies fields into ExtractionResult.UserData of every result extracted
+// with this config, batch results included, so caller context such as a tenant or job ID
+// travels with the result through ResultToJSON and ResultFromJSON. Each result gets its
+// own copy of the map; the values themselves are not copied. See ExtractFileWithData for
+// fields that differ per call.
+func WithContextFields(fields map[string]any) ExtractionOption {
+	return func(c *ExtractionConfig) {
+		c.ContextFields = fields
+	}
+}
+
+// WithLogger sends the binding's log records for extractions with this config to logger
+// instead of the logger set with SetDefaultLogger. Each extracted document is logged at
+// debug level with its MIME type, format, size and OCR backend; each ExtractionWarning at
+// the level given by WarningLevel; and each native extraction error at the level given by
+// ErrorLevel, with its error code. The native library's own stderr output is not affected.
+func WithLogger(logger *slog.Logger) ExtractionOption {
+	return func(c *ExtractionConfig) {
+		c.Logger = logger
+	}
+}
+
 // ============================================================================
 // OCRConfig Options
 // ============================================================================
@@ -154,6 +725,103 @@ func WithOCRLanguage(lang string) OCROption {
 	}
 }
 
+// WithOCRPageTimeout drops the OCR text of a PDF page, TIFF frame or image that takes longer
+// than d: the page is skipped with an ExtractionWarning and the remaining pages are still
+// extracted. Scanned PDFs are then OCR'd one page at a time.
+//
+// It does not bound the latency of the extraction. The native OCR call cannot be
+// interrupted, so a slow page keeps running after it is skipped and the next page waits for
+// it to finish; use a context deadline to stop waiting for the extraction as a whole.
+func WithOCRPageTimeout(d time.Duration) OCROption {
+	return func(c *OCRConfig) {
+		millis := int(d.Milliseconds())
+		c.PageTimeoutMillis = &millis
+	}
+}
+
+// WithOCRAutoDownloadLanguages downloads the traineddata of configured Tesseract languages
+// that are not installed before OCR runs, instead of failing with a MissingDependencyError.
+// Files are fetched from the pinned tessdata_fast 4.1.0 release, checked against the digests
+// set with WithOCRLanguageChecksums, into the directory set with WithOCRLanguageDataDir, else
+// TESSDATA_PREFIX, else the first writable Tesseract data directory on the system, else a
+// kreuzberg directory under os.UserCacheDir. Tesseract then loads language data from that
+// directory. Each download is reported as an ExtractionWarning and to WithOCRDownloadProgress.
+func WithOCRAutoDownloadLanguages(enabled bool) OCROption {
+	return func(c *OCRConfig) {
+		c.AutoDownloadLanguages = &enabled
+	}
+}
+
+// WithOCRLanguageDataDir sets the directory that automatically downloaded OCR language
+// data is written to and read from.
+func WithOCRLanguageDataDir(dir string) OCROption {
+	return func(c *OCRConfig) {
+		c.LanguageDataDir = &dir
+	}
+}
+
+// WithOCRLanguageChecksums sets the hex SHA-256 digests, keyed by language code, that
+// automatically downloaded traineddata files must match. Languages without one are not downloaded.
+func WithOCRLanguageChecksums(checksums map[string]string) OCROption {
+	return func(c *OCRConfig) {
+		c.LanguageChecksums = checksums
+	}
+}
+
+// WithOCRDownloadProgress registers fn to be called as OCR language data downloads
+// progress. It is called from the extracting goroutine.
+func WithOCRDownloadProgress(fn func(OCRLanguageDownload)) OCROption {
+	return func(c *OCRConfig) {
+		c.DownloadProgress = fn
+	}
+}
+
+// WithOCRMaxPixels downsamples images with more than n pixels to n pixels before they are
+// OCR'd, keeping their aspect ratio, so that gigapixel scans cannot exhaust memory during
+// OCR preprocessing whatever their DPI. Each downsampled image is reported as an
+// ExtractionWarning with code WarningCodeImageDownsampled giving the scale applied; positions
+// in OCR output, such as hOCR boxes, are in the pixels of the downsampled image. It applies
+// to PNG, JPEG and GIF image documents and to extracted images OCR'd with image OCR; TIFF
+// images and the pages the native library renders from PDFs are not downsampled. The limit
+// is stored in the Tesseract preprocessing settings, which are created when missing.
+// Values below 1 disable the limit.
+func WithOCRMaxPixels(n int) OCROption {
+	return func(c *OCRConfig) {
+		if c.Tesseract == nil {
+			c.Tesseract = &TesseractConfig{}
+		}
+		if c.Tesseract.Preprocessing == nil {
+			c.Tesseract.Preprocessing = &ImagePreprocessingConfig{}
+		}
+		c.Tesseract.Preprocessing.MaxPixels = &n
+	}
+}
+
+// WithOCRHOCR fills ExtractionResult.HOCR with the hOCR markup of OCR'd image documents,
+// keeping the word boxes and confidences Tesseract reports. The output is an hOCR 1.2 XHTML
+// document with one ocr_page element per page; multi-page TIFFs are numbered page_1,
+// page_2, and so on. Producing it runs the Tesseract backend a second time, so enable the
+// cache when the same images are extracted repeatedly. HOCR stays empty for documents that
+// were not OCR'd as images, including PDFs, and with OCR backends other than Tesseract.
+// The word confidences also set ExtractionResult.Confidence.OCR.
+func WithOCRHOCR(enabled bool) OCROption {
+	return func(c *OCRConfig) {
+		c.HOCR = &enabled
+	}
+}
+
+// WithOCRPreserveSpacing sets Tesseract's preserve_interword_spaces, keeping runs of spaces
+// between words so the columns of forms and monospaced text survive OCR. For monospaced
+// text also turn off WithTesseractTextordSpaceSizeIsVariable, which is on by default.
+func WithOCRPreserveSpacing(enabled bool) OCROption {
+	return func(c *OCRConfig) {
+		if c.Tesseract == nil {
+			c.Tesseract = &TesseractConfig{}
+		}
+		c.Tesseract.PreserveInterwordSpacing = &enabled
+	}
+}
+
 // WithTesseract sets the Tesseract configuration with functional options.
 func WithTesseract(opts ...TesseractOption) OCROption {
 	return func(c *OCRConfig) {
@@ -314,6 +982,14 @@ func WithTesseractTextordSpaceSizeIsVariable(enabled bool) TesseractOption {
 	}
 }
 
+// WithTesseractPreserveInterwordSpacing sets the preserve_interword_spaces parameter; see
+// WithOCRPreserveSpacing.
+func WithTesseractPreserveInterwordSpacing(enabled bool) TesseractOption {
+	return func(c *TesseractConfig) {
+		c.PreserveInterwordSpacing = &enabled
+	}
+}
+
 // WithTesseractThresholdingMethod sets the thresholding method.
 func WithTesseractThresholdingMethod(enabled bool) TesseractOption {
 	return func(c *TesseractConfig) {
@@ -341,7 +1017,8 @@ func WithTargetDPI(dpi int) ImagePreprocessingOption {
 	}
 }
 
-// WithAutoRotate enables automatic rotation.
+// WithAutoRotate turns pages scanned sideways or upside down upright before OCR, which needs
+// osd.traineddata. The rotations applied are reported in ExtractionResult.PageRotations.
 func WithAutoRotate(enabled bool) ImagePreprocessingOption {
 	return func(c *ImagePreprocessingConfig) {
 		c.AutoRotate = &enabled
@@ -445,6 +1122,42 @@ func WithChunkingEnabled(enabled bool) ChunkingOption {
 	}
 }
 
+// WithChunkSectionTitles sets whether chunks are tagged with the heading hierarchy they
+// belong to. See ChunkMetadata.SectionPath.
+func WithChunkSectionTitles(enabled bool) ChunkingOption {
+	return func(c *ChunkingConfig) {
+		c.SectionTitles = &enabled
+	}
+}
+
+// WithStableChunkIDs sets ChunkMetadata.ID of every chunk to an identifier derived from
+// source, which names the document (for example its path or URL), and the chunk content, so
+// that re-extracting an unchanged document gives the same IDs and upserts into a vector
+// store do not create duplicates. The ID does not depend on the chunk's position. See
+// StableChunkID for the hashing scheme.
+func WithStableChunkIDs(source string) ChunkingOption {
+	return func(c *ChunkingConfig) {
+		c.StableIDSource = &source
+	}
+}
+
+// WithChunkOverlapStrategy sets where the overlap of a chunk with the one before it starts.
+// OverlapStrategyChars (the default) keeps the overlap the chunker produced, which starts
+// exactly ChunkOverlap characters back and may cut a word. OverlapStrategyTokens starts it
+// at the next word, and OverlapStrategySentences at the next sentence as SplitSentences
+// finds it for the detected language of the document, falling back to the next word when
+// no sentence starts inside the overlap.
+//
+// ChunkSize and ChunkOverlap stay measured in characters whichever strategy is chosen: the
+// start of the overlap only moves forward, so overlaps get shorter and never exceed
+// ChunkOverlap. Only when the whole overlap lies inside one word does it move back to the
+// start of that word, so that no chunk starts mid-word.
+func WithChunkOverlapStrategy(strategy string) ChunkingOption {
+	return func(c *ChunkingConfig) {
+		c.OverlapStrategy = &strategy
+	}
+}
+
 // ============================================================================
 // ImageExtractionConfig Options
 // ============================================================================
@@ -500,6 +1213,55 @@ func WithMaxDPI(dpi int) ImageExtractionOption {
 	}
 }
 
+// WithMinImageDimension sets the minimum width and height an image needs to be OCR'd.
+func WithMinImageDimension(min int) ImageExtractionOption {
+	return func(c *ImageExtractionConfig) {
+		c.MinImageDimension = &min
+	}
+}
+
+// WithImageOCR enables OCR of individual extracted images using the configured OCR backend.
+// This is distinct from page-level OCR; the text is stored in ExtractedImage.OCRText.
+func WithImageOCR(enabled bool) ImageExtractionOption {
+	return func(c *ImageExtractionConfig) {
+		c.OCRImages = &enabled
+	}
+}
+
+// WithImageColorAnalysis sets ExtractedImage.IsGrayscale and ExtractedImage.DominantColors
+// for extracted images, for example to separate scanned pages from photos. Up to five
+// dominant colors are reported, each covering at least 2% of the image. PNG, JPEG and GIF
+// images are decoded and sampled on a grid of at most 128 by 128 pixels; other images only
+// get IsGrayscale, when their color space is gray. Masks are skipped.
+func WithImageColorAnalysis(enabled bool) ImageExtractionOption {
+	return func(c *ImageExtractionConfig) {
+		c.ColorAnalysis = &enabled
+	}
+}
+
+// WithExtractColorProfiles sets ExtractedImage.ICCProfile and ExtractedImage.ColorProfileName
+// for extracted images that embed an ICC profile, so that print workflows can reproduce their
+// colors. Profiles are read from PNG iCCP chunks, JPEG APP2 segments and the first frame of
+// TIFF images; the name is the profile's description. Other images and masks are left as is.
+func WithExtractColorProfiles(enabled bool) ImageExtractionOption {
+	return func(c *ImageExtractionConfig) {
+		c.ExtractColorProfiles = &enabled
+	}
+}
+
+// WithImageCaptioner calls fn for each extracted image and stores the string it returns in
+// ExtractedImage.Caption, for example to describe figures with a vision model. Masks,
+// images without data and images below MinImageDimension are skipped. fn runs after image
+// OCR and color analysis, so it can use OCRText and DominantColors, and it is called for one
+// image at a time. An error from fn leaves that image without a caption and is reported as
+// a WarningCodeImageCaptionFailed warning instead of failing the extraction. Images must be
+// extracted for fn to be called; see WithExtractImages.
+func WithImageCaptioner(fn func(img *ExtractedImage) (string, error)) ImageExtractionOption {
+	return func(c *ImageExtractionConfig) {
+		c.Captioner = fn
+	}
+}
+
 // ============================================================================
 // FontConfig Options
 // ============================================================================
@@ -1237,3 +1999,49 @@ func WithMarkerFormat(format string) PageOption {
 		c.MarkerFormat = &format
 	}
 }
+
+// WithMaxPages refuses documents with more than n pages, as a guard against untrusted
+// uploads that declare huge page counts. The count is read from PDF page trees and TIFF
+// frames before extraction starts, and an oversized document fails with a ValidationError
+// whose cause is a *PageLimitError. Other formats are not checked. Reading the count of a
+// file loads the file, so pair it with WithMaxInputBytes to bound that too.
+func WithMaxPages(n int) PageOption {
+	return func(c *PageConfig) {
+		c.MaxPages = &n
+	}
+}
+
+// WithSamplePages extracts only n pages of PDFs and TIFFs, for classifying long documents
+// without paying for a full extraction. strategy is SampleFirst for the first n pages,
+// SampleUniform for n pages spread evenly from the first page to the last, or SampleRandom
+// for n random pages; use WithSampleSeed to make random samples reproducible.
+//
+// ExtractionResult.SampledPages lists the original numbers of the sampled pages, and page
+// numbers throughout the result refer to the original document. Documents with n pages or
+// fewer, encrypted PDFs and other formats are extracted in full and leave SampledPages nil.
+// MaxPages is not applied to sampled documents. A PDF whose structure cannot be read fails
+// with a ParsingError rather than being extracted in full.
+func WithSamplePages(n int, strategy string) PageOption {
+	return func(c *PageConfig) {
+		c.SamplePages = &n
+		c.SampleStrategy = &strategy
+	}
+}
+
+// WithPageGeometry fills Metadata.PageStructure.PageSizes of PDFs from the document's page
+// tree: each page's CropBox, or MediaBox when it has none, and its /Rotate. This reads and
+// parses the whole file in Go, so it is off by default; without it PageSizes holds the
+// dimensions the native library reports, with Rotation left at 0.
+func WithPageGeometry(enabled bool) PageOption {
+	return func(c *PageConfig) {
+		c.PageGeometry = &enabled
+	}
+}
+
+// WithSampleSeed seeds the SampleRandom strategy of WithSamplePages, so the same document is
+// always sampled the same way.
+func WithSampleSeed(seed int64) PageOption {
+	return func(c *PageConfig) {
+		c.SampleSeed = &seed
+	}
+}
@@ -2,6 +2,7 @@ package kreuzberg_test
 
 import (
 	"encoding/json"
+	"strings"
 	"testing"
 
 	kreuzberg "github.com/kreuzberg-dev/kreuzberg/packages/go/v4"
@@ -691,6 +692,60 @@ func TestResultFromJSON(t *testing.T) {
 	}
 }
 
+func TestResultFromJSONStrict(t *testing.T) {
+	jsonStr := `{
+		"content": "test content",
+		"mime_type": "text/plain",
+		"metadata": {"custom_key": 1},
+		"tables": [{"cells": [["a"]], "markdown": "| a |", "page_number": 1}],
+		"success": true
+	}`
+	result, err := kreuzberg.ResultFromJSONStrict(jsonStr)
+	if err != nil {
+		t.Fatalf("ResultFromJSONStrict() error = %v", err)
+	}
+	if result.Content != "test content" || len(result.Tables) != 1 {
+		t.Errorf("unexpected result %+v", result)
+	}
+	if _, ok := result.Metadata.Additional["custom_key"]; !ok {
+		t.Error("expected unknown metadata keys to be kept in Additional")
+	}
+
+	for name, input := range map[string]string{
+		"top level": `{"content": "x", "new_field": true}`,
+		"nested":    `{"tables": [{"cells": [], "span_info": []}]}`,
+	} {
+		_, err := kreuzberg.ResultFromJSONStrict(input)
+		if err == nil {
+			t.Errorf("%s: expected an error for an unknown field", name)
+			continue
+		}
+		if !strings.Contains(err.Error(), "new_field") && !strings.Contains(err.Error(), "span_info") {
+			t.Errorf("%s: error %q does not name the unknown field", name, err)
+		}
+	}
+	if _, err := kreuzberg.ResultFromJSONStrict(`{"content": "x"} {}`); err == nil {
+		t.Error("expected an error for trailing data")
+	}
+}
+
+func TestConfigFromJSONStrict(t *testing.T) {
+	_, err := kreuzberg.ConfigFromJSONStrict(`{"use_cache": true, "chunkng": {"max_chars": 10}}`)
+	if err == nil || !strings.Contains(err.Error(), "chunkng") {
+		t.Fatalf("expected an error naming the misspelled field, got %v", err)
+	}
+	if _, err := kreuzberg.ConfigFromJSON(`{"use_cache": true}`); err != nil {
+		t.Skipf("native config parsing unavailable: %v", err)
+	}
+	cfg, err := kreuzberg.ConfigFromJSONStrict(`{"use_cache": true, "chunking": {"max_chars": 10}}`)
+	if err != nil {
+		t.Fatalf("ConfigFromJSONStrict() error = %v", err)
+	}
+	if cfg.UseCache == nil || !*cfg.UseCache || cfg.Chunking == nil {
+		t.Errorf("unexpected config %+v", cfg)
+	}
+}
+
 func TestHierarchyConfigFromJSON(t *testing.T) {
 	tests := []struct {
 		name    string
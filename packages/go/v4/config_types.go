@@ -1,5 +1,10 @@
 package kreuzberg
 
+import (
+	"crypto/x509"
+	"log/slog"
+)
+
 // This file contains pure Go type definitions for Kreuzberg configuration.
 // These types are intentionally separated from CGO code so they remain available
 // when CGO is disabled (e.g., during linting with CGO_ENABLED=0).
@@ -67,9 +72,11 @@ type HTMLConversionOption func(*HTMLConversionOptions)
 // PageOption is a functional option for configuring PageConfig.
 type PageOption func(*PageConfig)
 
-// ExtractionConfig mirrors the Rust ExtractionConfig structure and is serialized to JSON
-// before crossing the FFI boundary. Use pointer fields to omit values and rely on Kreuzberg
-// defaults whenever possible.
+// ExtractionConfig configures an extraction. The fields of the Rust ExtractionConfig are
+// serialized to JSON before crossing the FFI boundary; fields tagged native:"-", here and
+// in the nested configs, are options the Go binding applies itself and are left out of that
+// JSON, though ConfigToJSON and ConfigFromJSON keep them. Use pointer fields to omit values
+// and rely on Kreuzberg defaults whenever possible.
 type ExtractionConfig struct {
 	UseCache                 *bool                    `json:"use_cache,omitempty"`
 	EnableQualityProcessing  *bool                    `json:"enable_quality_processing,omitempty"`
@@ -85,13 +92,79 @@ type ExtractionConfig struct {
 	HTMLOptions              *HTMLConversionOptions   `json:"html_options,omitempty"`
 	Pages                    *PageConfig              `json:"pages,omitempty"`
 	MaxConcurrentExtractions *int                     `json:"max_concurrent_extractions,omitempty"`
+	ExtractAnnotations       *bool                    `json:"extract_annotations,omitempty" native:"-"`
+	Spreadsheet              *SpreadsheetOptions      `json:"spreadsheet,omitempty" native:"-"`
+	CacheKeyExtra            *string                  `json:"cache_key_extra,omitempty" native:"-"`
+	FormFieldsInContent      *bool                    `json:"form_fields_in_content,omitempty" native:"-"`
+	PartSeparator            *string                  `json:"part_separator,omitempty" native:"-"`
+	DocumentPasswords        []string                 `json:"document_passwords,omitempty" native:"-"`
+	ReadingOrder             *string                  `json:"reading_order,omitempty" native:"-"`
+	RawText                  *bool                    `json:"raw_text,omitempty" native:"-"`
+	DetectBarcodes           *bool                    `json:"detect_barcodes,omitempty" native:"-"`
+	StructuredOutput         *bool                    `json:"structured_output,omitempty" native:"-"`
+	WhitespaceNormalization  *string                  `json:"whitespace_normalization,omitempty" native:"-"`
+	RetainSource             *bool                    `json:"retain_source,omitempty" native:"-"`
+	AllowPartialResults      *bool                    `json:"allow_partial_results,omitempty" native:"-"`
+	ResourceTracking         *bool                    `json:"resource_tracking,omitempty" native:"-"`
+	ExtractRevisions         *bool                    `json:"extract_revisions,omitempty" native:"-"`
+	Docx                     *DocxOptions             `json:"docx,omitempty" native:"-"`
+	SeparateHeadersFooters   *bool                    `json:"separate_headers_footers,omitempty" native:"-"`
+	HeaderFooterThreshold    *float64                 `json:"header_footer_threshold,omitempty" native:"-"`
+	TableBackend             *string                  `json:"table_backend,omitempty" native:"-"`
+	MaxTables                *int                     `json:"max_tables,omitempty" native:"-"`
+	TableMinArea             *int                     `json:"table_min_area,omitempty" native:"-"`
+	MinTableRows             *int                     `json:"min_table_rows,omitempty" native:"-"`
+	MinTableCols             *int                     `json:"min_table_cols,omitempty" native:"-"`
+	OffsetMap                *bool                    `json:"offset_map,omitempty" native:"-"`
+	TextPositions            *bool                    `json:"text_positions,omitempty" native:"-"`
+	RenderPages              *RenderPagesConfig       `json:"render_pages,omitempty" native:"-"`
+	Bidi                     *string                  `json:"bidi,omitempty" native:"-"`
+	MimeOverrides            map[string]string        `json:"mime_overrides,omitempty" native:"-"`
+	ExtractSignatures        *bool                    `json:"extract_signatures,omitempty" native:"-"`
+	ExtractTextColors        *bool                    `json:"extract_text_colors,omitempty" native:"-"`
+	MaxInputBytes            *int                     `json:"max_input_bytes,omitempty" native:"-"`
+	DetectEncoding           *bool                    `json:"detect_encoding,omitempty" native:"-"`
+	SourceEncoding           *string                  `json:"source_encoding,omitempty" native:"-"`
+	PreserveCellWhitespace   *bool                    `json:"preserve_cell_whitespace,omitempty" native:"-"`
+	TableMerges              *bool                    `json:"table_merges,omitempty" native:"-"`
+	LayerSelection           []string                 `json:"layer_selection,omitempty" native:"-"`
+	MaxRecursionDepth        *int                     `json:"max_recursion_depth,omitempty" native:"-"`
+	MaxExtractedBytes        *int                     `json:"max_extracted_bytes,omitempty" native:"-"`
+	PageThumbprints          *bool                    `json:"page_thumbprints,omitempty" native:"-"`
+	EmptyContentIsError      *bool                    `json:"empty_content_is_error,omitempty" native:"-"`
+	NormalizeTableNumbers    *string                  `json:"normalize_table_numbers,omitempty" native:"-"`
+	ParallelPages            *int                     `json:"parallel_pages,omitempty" native:"-"`
+
+	// ContentFilter and CellFilter are applied by the Go binding after the native
+	// extraction returns and are never sent across the FFI boundary.
+	ContentFilter func(content string) string            `json:"-"`
+	CellFilter    func(row, col int, cell string) string `json:"-"`
+	// Logger receives the binding's log records; see WithLogger.
+	Logger *slog.Logger `json:"-"`
+	// ContextFields seed ExtractionResult.UserData; see WithContextFields.
+	ContextFields map[string]any `json:"-"`
+	// SignatureRoots are the trusted roots for signature validation; see
+	// WithSignatureRoots.
+	SignatureRoots *x509.CertPool `json:"-"`
+
+	// encoded caches the JSON form of a config owned by an Extractor.
+	encoded []byte
 }
 
 // OCRConfig selects and configures OCR backends.
 type OCRConfig struct {
-	Backend   string           `json:"backend,omitempty"`
-	Language  *string          `json:"language,omitempty"`
-	Tesseract *TesseractConfig `json:"tesseract_config,omitempty"`
+	Backend           string           `json:"backend,omitempty"`
+	Language          *string          `json:"language,omitempty"`
+	Tesseract         *TesseractConfig `json:"tesseract_config,omitempty"`
+	PageTimeoutMillis *int             `json:"page_timeout_millis,omitempty" native:"-"`
+	// AutoDownloadLanguages, LanguageDataDir, LanguageChecksums and DownloadProgress are
+	// handled by the Go binding before OCR runs; see WithOCRAutoDownloadLanguages.
+	AutoDownloadLanguages *bool                     `json:"auto_download_languages,omitempty" native:"-"`
+	LanguageDataDir       *string                   `json:"language_data_dir,omitempty" native:"-"`
+	LanguageChecksums     map[string]string         `json:"language_checksums,omitempty" native:"-"`
+	DownloadProgress      func(OCRLanguageDownload) `json:"-"`
+	// HOCR fills ExtractionResult.HOCR; see WithOCRHOCR.
+	HOCR *bool `json:"hocr,omitempty" native:"-"`
 }
 
 // TesseractConfig exposes fine-grained controls for the Tesseract backend.
@@ -116,7 +189,17 @@ type TesseractConfig struct {
 	TesseditCharBlacklist          string                    `json:"tessedit_char_blacklist,omitempty"`
 	TesseditUsePrimaryParamsModel  *bool                     `json:"tessedit_use_primary_params_model,omitempty"`
 	TextordSpaceSizeIsVariable     *bool                     `json:"textord_space_size_is_variable,omitempty"`
+	PreserveInterwordSpacing       *bool                     `json:"preserve_interword_spaces,omitempty"`
 	ThresholdingMethod             *bool                     `json:"thresholding_method,omitempty"`
+	// TessdataPath is the directory traineddata files are loaded from, ahead of
+	// TESSDATA_PREFIX and the default search paths.
+	TessdataPath string `json:"tessdata_path,omitempty"`
+	// CacheKeyExtra is mixed into the key of cached OCR results; the Go binding sets it from
+	// ExtractionConfig.CacheKeyExtra.
+	CacheKeyExtra string `json:"cache_key_extra,omitempty"`
+	// IncludeHOCR returns the hOCR of OCR'd pages along with their text; the Go binding sets
+	// it to locate recognized text when WithTextPositions or WithOffsetMap is enabled.
+	IncludeHOCR bool `json:"include_hocr,omitempty"`
 }
 
 // ImagePreprocessingConfig tunes DPI normalization and related steps for OCR.
@@ -128,6 +211,8 @@ type ImagePreprocessingConfig struct {
 	ContrastEnhance  *bool  `json:"contrast_enhance,omitempty"`
 	BinarizationMode string `json:"binarization_method,omitempty"`
 	InvertColors     *bool  `json:"invert_colors,omitempty"`
+	// MaxPixels caps the pixel count of images before OCR; see WithOCRMaxPixels.
+	MaxPixels *int `json:"max_pixels,omitempty" native:"-"`
 }
 
 // ChunkingConfig configures text chunking for downstream RAG/Retrieval workloads.
@@ -139,6 +224,14 @@ type ChunkingConfig struct {
 	Preset       *string          `json:"preset,omitempty"`
 	Embedding    *EmbeddingConfig `json:"embedding,omitempty"`
 	Enabled      *bool            `json:"enabled,omitempty"`
+	// SectionTitles fills ChunkMetadata.SectionPath with the headings enclosing each chunk.
+	SectionTitles *bool `json:"section_titles,omitempty" native:"-"`
+	// StableIDSource turns on ChunkMetadata.ID, hashed with this source; see
+	// WithStableChunkIDs.
+	StableIDSource *string `json:"stable_id_source,omitempty" native:"-"`
+	// OverlapStrategy aligns the overlap of consecutive chunks; see
+	// WithChunkOverlapStrategy.
+	OverlapStrategy *string `json:"overlap_strategy,omitempty" native:"-"`
 }
 
 // ImageExtractionConfig controls inline image extraction from PDFs/Office docs.
@@ -149,6 +242,14 @@ type ImageExtractionConfig struct {
 	AutoAdjustDPI     *bool `json:"auto_adjust_dpi,omitempty"`
 	MinDPI            *int  `json:"min_dpi,omitempty"`
 	MaxDPI            *int  `json:"max_dpi,omitempty"`
+	MinImageDimension *int  `json:"min_image_dimension,omitempty" native:"-"`
+	OCRImages         *bool `json:"ocr_images,omitempty" native:"-"`
+	ColorAnalysis     *bool `json:"color_analysis,omitempty" native:"-"`
+	// ExtractColorProfiles keeps embedded ICC profiles; see WithExtractColorProfiles.
+	ExtractColorProfiles *bool `json:"extract_color_profiles,omitempty" native:"-"`
+	// Captioner sets ExtractedImage.Caption; see WithImageCaptioner. It runs in the Go
+	// binding and is never sent across the FFI boundary.
+	Captioner func(img *ExtractedImage) (string, error) `json:"-"`
 }
 
 // FontConfig exposes font provider configuration for PDF extraction.
@@ -289,4 +390,81 @@ type PageConfig struct {
 	ExtractPages      *bool   `json:"extract_pages,omitempty"`
 	InsertPageMarkers *bool   `json:"insert_page_markers,omitempty"`
 	MarkerFormat      *string `json:"marker_format,omitempty"`
+	MaxPages          *int    `json:"max_pages,omitempty" native:"-"`
+	// SamplePages, SampleStrategy and SampleSeed select the pages extracted from PDFs and
+	// TIFFs; see WithSamplePages.
+	SamplePages    *int    `json:"sample_pages,omitempty" native:"-"`
+	SampleStrategy *string `json:"sample_strategy,omitempty" native:"-"`
+	SampleSeed     *int64  `json:"sample_seed,omitempty" native:"-"`
+	// PageGeometry reads PDF page sizes and rotations from the page tree; see
+	// WithPageGeometry.
+	PageGeometry *bool `json:"page_geometry,omitempty" native:"-"`
+}
+
+// SpreadsheetOptions configures tabular formats. SheetNames and SheetIndices (zero-based, in
+// workbook order) restrict workbook extraction to the selected sheets and are combined; when
+// both are empty every sheet is returned. CSVDelimiter, when set, overrides delimiter
+// detection for CSV and TSV documents.
+type SpreadsheetOptions struct {
+	SheetNames   []string `json:"sheet_names,omitempty"`
+	SheetIndices []int    `json:"sheet_indices,omitempty"`
+	CSVDelimiter string   `json:"csv_delimiter,omitempty"`
 }
+
+// DocxOptions configures DOCX documents. TrackedChangesMode selects how tracked changes
+// appear in the content; see WithTrackedChangesMode.
+type DocxOptions struct {
+	TrackedChangesMode string `json:"tracked_changes_mode,omitempty"`
+}
+
+// Tracked changes modes accepted by DocxOptions.TrackedChangesMode.
+const (
+	TrackedChangesAccepted = "accepted"
+	TrackedChangesRejected = "rejected"
+	TrackedChangesOriginal = "original"
+)
+
+// Reading orders accepted by ExtractionConfig.ReadingOrder.
+const (
+	ReadingOrderRaw     = "raw"
+	ReadingOrderColumns = "columns"
+)
+
+// Text orders accepted by ExtractionConfig.Bidi.
+const (
+	BidiLogical = "logical"
+	BidiVisual  = "visual"
+)
+
+// Text directions reported in Metadata.DominantTextDirection.
+const (
+	TextDirectionLTR = "ltr"
+	TextDirectionRTL = "rtl"
+)
+
+// Table detection backends accepted by ExtractionConfig.TableBackend; see
+// AvailableTableBackends.
+const (
+	TableBackendHeuristic = "heuristic"
+)
+
+// Page sampling strategies accepted by PageConfig.SampleStrategy.
+const (
+	SampleFirst   = "first"
+	SampleUniform = "uniform"
+	SampleRandom  = "random"
+)
+
+// Overlap strategies accepted by ChunkingConfig.OverlapStrategy.
+const (
+	OverlapStrategyChars     = "chars"
+	OverlapStrategySentences = "sentences"
+	OverlapStrategyTokens    = "tokens"
+)
+
+// Whitespace normalization modes accepted by ExtractionConfig.WhitespaceNormalization.
+const (
+	WhitespaceNone       = "none"
+	WhitespaceTrim       = "trim"
+	WhitespaceAggressive = "aggressive"
+)
@@ -0,0 +1,146 @@
+package kreuzberg
+
+import (
+	"bytes"
+	"image"
+	"io"
+	"math"
+	"os"
+	"strings"
+)
+
+// Relative costs of the work EstimateCost counts, in units of a page with a text layer.
+const (
+	costTextPage = 1.0
+	costOCRPage  = 10.0
+	costMiB      = 1.0
+)
+
+// CostEstimate is the expected work of extracting a document; see EstimateCost.
+type CostEstimate struct {
+	// PageCount is the number of pages of PDFs and TIFFs, 1 for other images and 0 for
+	// documents whose pages cannot be counted without extracting them.
+	PageCount int
+	// IsScanned reports whether every page needs OCR.
+	IsScanned bool
+	// EstimatedOCRPages is the number of pages without text that show an image, which
+	// are OCR'd when OCR is enabled.
+	EstimatedOCRPages int
+	// ComplexityScore orders documents by expected extraction time; it has no unit and is
+	// only meaningful compared with the scores of other documents.
+	ComplexityScore float64
+}
+
+// EstimateCost inspects the document at path without extracting it and estimates the work
+// extraction will take, so schedulers can send documents that need OCR to dedicated
+// workers. It reads the page tree of PDFs and the text operators of each page, the frame
+// count of TIFFs and the header of other images; other formats are only identified. It
+// runs neither the extractors nor OCR.
+//
+// A PDF page needs OCR when its content shows an image but no text. Text that cannot be
+// decoded, such as text in a font without a Unicode mapping, still counts as text, and the
+// pages of encrypted PDFs are assumed to have text. Every TIFF frame and every other image
+// needs OCR.
+//
+// ComplexityScore counts 1 for each page with text and 10 for each page that needs OCR,
+// roughly the ratio of their extraction times. Documents whose pages cannot be counted
+// score 1 per MiB of file size instead. Every document scores at least 1.
+func EstimateCost(path string) (*CostEstimate, error) {
+	if path == "" {
+		return nil, newValidationErrorWithContext("path is required", nil, ErrorCodeValidation, nil)
+	}
+	file, err := os.Open(path)
+	if err != nil {
+		return nil, newIOErrorWithContext("failed to open document", err, ErrorCodeIo, nil)
+	}
+	defer file.Close()
+	info, err := file.Stat()
+	if err != nil {
+		return nil, newIOErrorWithContext("failed to read document", err, ErrorCodeIo, nil)
+	}
+	header := make([]byte, 8)
+	n, _ := io.ReadFull(file, header)
+	header = header[:n]
+
+	estimate := &CostEstimate{}
+	switch {
+	case isPagedDocumentHeader(header):
+		if _, err := file.Seek(0, io.SeekStart); err != nil {
+			return nil, newIOErrorWithContext("failed to read document", err, ErrorCodeIo, nil)
+		}
+		data, err := io.ReadAll(file)
+		if err != nil {
+			return nil, newIOErrorWithContext("failed to read document", err, ErrorCodeIo, nil)
+		}
+		if bytes.HasPrefix(data, []byte("%PDF-")) {
+			doc, err := parsePDFDocument(data)
+			if err != nil {
+				return nil, newParsingErrorWithContext("failed to read PDF structure", err, ErrorCodeParsing, nil)
+			}
+			estimate.PageCount, estimate.EstimatedOCRPages = pdfCostPages(doc)
+		} else {
+			estimate.PageCount, _ = documentPageCount(data)
+			estimate.EstimatedOCRPages = estimate.PageCount
+		}
+	default:
+		if _, err := file.Seek(0, io.SeekStart); err != nil {
+			return nil, newIOErrorWithContext("failed to read document", err, ErrorCodeIo, nil)
+		}
+		isImage := false
+		if _, _, err := image.DecodeConfig(file); err == nil {
+			isImage = true
+		} else {
+			mimeType, err := DetectMimeTypeFromPath(path)
+			if err != nil {
+				return nil, err
+			}
+			isImage = strings.HasPrefix(normalizeMimeType(mimeType), "image/")
+		}
+		if isImage {
+			estimate.PageCount, estimate.EstimatedOCRPages = 1, 1
+		}
+	}
+
+	estimate.IsScanned = estimate.PageCount > 0 && estimate.EstimatedOCRPages == estimate.PageCount
+	if estimate.PageCount > 0 {
+		estimate.ComplexityScore = float64(estimate.PageCount-estimate.EstimatedOCRPages)*costTextPage +
+			float64(estimate.EstimatedOCRPages)*costOCRPage
+	} else {
+		estimate.ComplexityScore = float64(info.Size()) / (1 << 20) * costMiB
+	}
+	estimate.ComplexityScore = math.Max(estimate.ComplexityScore, 1)
+	return estimate, nil
+}
+
+// pdfCostPages returns the page count of a PDF and the number of its pages that need OCR.
+func pdfCostPages(doc *pdfDocument) (int, int) {
+	pages := doc.pages()
+	count := len(pages)
+	if declared, ok := doc.resolve(doc.dict(doc.catalog()["Pages"])["Count"]).(float64); ok && declared > 0 {
+		count = int(declared)
+	}
+	if doc.encrypted {
+		return count, 0
+	}
+	ocrPages := 0
+	fonts := make(map[any]*pdfFontDecoder)
+	for _, page := range pages {
+		if !pdfPageHasImage(doc, page) {
+			continue
+		}
+		if text, ok := doc.pageText(page, fonts); ok && strings.TrimSpace(text.text) == "" {
+			ocrPages++
+		}
+	}
+	return count, min(ocrPages, count)
+}
+
+// pdfPageHasImage reports whether the resources of a page hold an image XObject.
+func pdfPageHasImage(doc *pdfDocument, page pdfPage) bool {
+	for _, value := range doc.dict(page.resources["XObject"]) {
+		if stream, ok := doc.stream(value); ok && stream.dict.name("Subtype") == "Image" {
+			return true
+		}
+	}
+	return false
+}
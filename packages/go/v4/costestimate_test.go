@@ -0,0 +1,83 @@
+package kreuzberg
+
+import (
+	"bytes"
+	"errors"
+	"image/jpeg"
+	"image/png"
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+func writeCostFile(t *testing.T, name string, data []byte) string {
+	t.Helper()
+	path := filepath.Join(t.TempDir(), name)
+	if err := os.WriteFile(path, data, 0o600); err != nil {
+		t.Fatal(err)
+	}
+	return path
+}
+
+func TestEstimateCost(t *testing.T) {
+	var scan bytes.Buffer
+	if err := jpeg.Encode(&scan, testRenderImage(40, 60), nil); err != nil {
+		t.Fatal(err)
+	}
+	var picture bytes.Buffer
+	if err := png.Encode(&picture, testRenderImage(40, 60)); err != nil {
+		t.Fatal(err)
+	}
+	mixed := buildTestPDF(
+		`<< /Type /Catalog /Pages 2 0 R >>`,
+		`<< /Type /Pages /Kids [3 0 R 4 0 R 5 0 R] /Count 3 /Resources << /Font << /F1 6 0 R >> /XObject << /Im1 7 0 R >> >> >>`,
+		`<< /Type /Page /Parent 2 0 R /Contents 8 0 R >>`,
+		`<< /Type /Page /Parent 2 0 R /Contents 9 0 R >>`,
+		`<< /Type /Page /Parent 2 0 R /Contents 10 0 R >>`,
+		`<< /Type /Font /Subtype /Type1 /BaseFont /Helvetica >>`,
+		`<< /Type /XObject /Subtype /Image /Width 1 /Height 1 /BitsPerComponent 8 /ColorSpace /DeviceGray /Length 1 >>
+stream
+`+"\x00"+`
+endstream`,
+		testPDFStream("BT /F1 12 Tf 72 700 Td (Typed page) Tj ET", false),
+		testPDFStream("q 612 0 0 792 0 0 cm /Im1 Do Q", false),
+		testPDFStream("q 612 0 0 792 0 0 cm /Im1 Do Q BT /F1 12 Tf 72 700 Td (Caption) Tj ET", false),
+	)
+
+	cases := []struct {
+		name      string
+		data      []byte
+		pages     int
+		ocrPages  int
+		scanned   bool
+		wantScore float64
+	}{
+		{"scan.pdf", testScannedPDF(t, scan.Bytes()), 1, 1, true, 10},
+		{"mixed.pdf", mixed, 3, 1, false, 12},
+		{"text.pdf", testSamplePDF(4), 4, 0, false, 4},
+		{"photo.png", picture.Bytes(), 1, 1, true, 10},
+	}
+	for _, tc := range cases {
+		t.Run(tc.name, func(t *testing.T) {
+			estimate, err := EstimateCost(writeCostFile(t, tc.name, tc.data))
+			if err != nil {
+				t.Fatalf("EstimateCost failed: %v", err)
+			}
+			want := CostEstimate{PageCount: tc.pages, IsScanned: tc.scanned, EstimatedOCRPages: tc.ocrPages, ComplexityScore: tc.wantScore}
+			if *estimate != want {
+				t.Fatalf("got %+v, want %+v", *estimate, want)
+			}
+		})
+	}
+}
+
+func TestEstimateCostErrors(t *testing.T) {
+	var valErr *ValidationError
+	if _, err := EstimateCost(""); !errors.As(err, &valErr) {
+		t.Fatalf("expected a ValidationError for an empty path, got %v", err)
+	}
+	var ioErr *IOError
+	if _, err := EstimateCost(filepath.Join(t.TempDir(), "missing.pdf")); !errors.As(err, &ioErr) {
+		t.Fatalf("expected an IOError for a missing file, got %v", err)
+	}
+}
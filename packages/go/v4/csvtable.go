@@ -0,0 +1,343 @@
+package kreuzberg
+
+import (
+	"strconv"
+	"strings"
+	"time"
+	"unicode/utf8"
+)
+
+// ColumnType is the value type inferred for a table column.
+type ColumnType string
+
+const (
+	ColumnTypeString  ColumnType = "string"
+	ColumnTypeInteger ColumnType = "integer"
+	ColumnTypeNumber  ColumnType = "number"
+	ColumnTypeBoolean ColumnType = "boolean"
+	ColumnTypeDate    ColumnType = "date"
+)
+
+// csvSampleRows bounds the rows inspected when detecting the dialect.
+const csvSampleRows = 50
+
+var csvDelimiters = []rune{',', ';', '\t'}
+
+// csvDialect describes how a delimited text file is written.
+type csvDialect struct {
+	delimiter rune
+	quote     rune
+}
+
+func isDelimitedMimeType(mimeType string) bool {
+	switch normalizeMimeType(mimeType) {
+	case "text/csv", "application/csv", "text/tab-separated-values":
+		return true
+	default:
+		return false
+	}
+}
+
+// applyCSVTable parses CSV and TSV documents into a Table appended to result.Tables,
+// detecting the delimiter, quote character and header row. Content is left as extracted.
+// SpreadsheetOptions.CSVDelimiter overrides delimiter detection.
+func applyCSVTable(result *ExtractionResult, config *ExtractionConfig, src *documentSource) error {
+	if !isDelimitedMimeType(result.MimeType) || len(result.Tables) > 0 {
+		return nil
+	}
+	data, err := src.bytes()
+	if err != nil {
+		return err
+	}
+	text := strings.TrimPrefix(string(data), "\ufeff")
+	if strings.TrimSpace(text) == "" {
+		return nil
+	}
+
+	var dialect csvDialect
+	if config.Spreadsheet != nil && config.Spreadsheet.CSVDelimiter != "" {
+		delimiter, _ := utf8.DecodeRuneInString(config.Spreadsheet.CSVDelimiter)
+		dialect = csvDialect{delimiter: delimiter, quote: detectCSVQuote(text, delimiter)}
+	} else {
+		dialect = detectCSVDialect(text, normalizeMimeType(result.MimeType) == "text/tab-separated-values")
+	}
+
+	rows := parseDelimited(text, dialect, -1)
+	if len(rows) == 0 {
+		return nil
+	}
+	hasHeader := detectCSVHeader(rows)
+	dataRows := rows
+	if hasHeader {
+		dataRows = rows[1:]
+	}
+	result.Tables = append(result.Tables, Table{
+		Cells:       rows,
+		Markdown:    tableMarkdown(rows),
+		PageNumber:  1,
+		HasHeader:   hasHeader,
+		ColumnTypes: inferColumnTypes(dataRows),
+	})
+	return nil
+}
+
+// detectCSVDialect picks the delimiter that splits the sample into the most consistent
+// number of columns (more than one), preferring tabs for TSV documents on ties.
+func detectCSVDialect(text string, tsv bool) csvDialect {
+	best := csvDialect{delimiter: ',', quote: '"'}
+	if tsv {
+		best.delimiter = '\t'
+	}
+	bestScore := 0
+	for _, delimiter := range csvDelimiters {
+		quote := detectCSVQuote(text, delimiter)
+		rows := parseDelimited(text, csvDialect{delimiter: delimiter, quote: quote}, csvSampleRows)
+		counts := make(map[int]int)
+		for _, row := range rows {
+			counts[len(row)]++
+		}
+		mode, consistent := 0, 0
+		for width, n := range counts {
+			if n > consistent || (n == consistent && width > mode) {
+				mode, consistent = width, n
+			}
+		}
+		if mode < 2 {
+			continue
+		}
+		score := consistent*1000 + mode
+		if score > bestScore || (score == bestScore && tsv && delimiter == '\t') {
+			best, bestScore = csvDialect{delimiter: delimiter, quote: quote}, score
+		}
+	}
+	return best
+}
+
+// detectCSVQuote returns the quote character wrapping most fields, defaulting to '"'.
+func detectCSVQuote(text string, delimiter rune) rune {
+	counts := map[rune]int{}
+	lines := strings.SplitN(text, "\n", csvSampleRows+1)
+	for _, line := range lines[:min(len(lines), csvSampleRows)] {
+		for _, field := range strings.Split(strings.TrimRight(line, "\r"), string(delimiter)) {
+			field = strings.TrimSpace(field)
+			for _, quote := range []rune{'"', '\''} {
+				if len(field) >= 2 && rune(field[0]) == quote && rune(field[len(field)-1]) == quote {
+					counts[quote]++
+				}
+			}
+		}
+	}
+	if counts['\''] > counts['"'] {
+		return '\''
+	}
+	return '"'
+}
+
+// parseDelimited splits text into rows of fields. Quoted fields may contain delimiters,
+// newlines and doubled quotes. A negative limit parses every row.
+func parseDelimited(text string, dialect csvDialect, limit int) [][]string {
+	var rows [][]string
+	var row []string
+	var field strings.Builder
+	inQuotes, quoted := false, false
+	endRow := func() {
+		row = append(row, field.String())
+		field.Reset()
+		quoted = false
+		if len(row) > 1 || row[0] != "" {
+			rows = append(rows, row)
+		}
+		row = nil
+	}
+
+	for i, width := 0, 0; i < len(text); i += width {
+		if limit >= 0 && len(rows) >= limit {
+			return rows
+		}
+		var r rune
+		r, width = utf8.DecodeRuneInString(text[i:])
+		switch {
+		case inQuotes:
+			if r != dialect.quote {
+				field.WriteRune(r)
+			} else if strings.HasPrefix(text[i+width:], string(dialect.quote)) {
+				field.WriteRune(r)
+				width += utf8.RuneLen(r)
+			} else {
+				inQuotes = false
+			}
+		case r == dialect.quote && field.Len() == 0 && !quoted:
+			inQuotes, quoted = true, true
+		case r == dialect.delimiter:
+			row = append(row, field.String())
+			field.Reset()
+			quoted = false
+		case r == '\r' && strings.HasPrefix(text[i+width:], "\n"):
+		case r == '\n':
+			endRow()
+		default:
+			field.WriteRune(r)
+		}
+	}
+	if field.Len() > 0 || len(row) > 0 || quoted {
+		endRow()
+	}
+	return rows
+}
+
+// detectCSVHeader votes per column on whether the first row differs from the data rows in
+// type or, for text columns of uniform width, in length.
+func detectCSVHeader(rows [][]string) bool {
+	if len(rows) < 2 {
+		return false
+	}
+	header, sample := rows[0], rows[1:min(len(rows), csvSampleRows)]
+	votes := 0
+	for col, name := range header {
+		var values []string
+		for _, row := range sample {
+			if col < len(row) && strings.TrimSpace(row[col]) != "" {
+				values = append(values, row[col])
+			}
+		}
+		if len(values) == 0 {
+			continue
+		}
+		columnType := inferColumnType(values)
+		if columnType != ColumnTypeString {
+			if inferColumnType([]string{name}) != columnType {
+				votes++
+			} else {
+				votes--
+			}
+			continue
+		}
+		width := utf8.RuneCountInString(values[0])
+		uniform := true
+		for _, value := range values[1:] {
+			if utf8.RuneCountInString(value) != width {
+				uniform = false
+				break
+			}
+		}
+		if uniform {
+			if utf8.RuneCountInString(name) != width {
+				votes++
+			} else {
+				votes--
+			}
+		}
+	}
+	return votes > 0
+}
+
+// inferColumnTypes returns the type shared by the non-empty cells of each column.
+func inferColumnTypes(rows [][]string) []ColumnType {
+	width := 0
+	for _, row := range rows {
+		width = max(width, len(row))
+	}
+	types := make([]ColumnType, width)
+	for col := range types {
+		var values []string
+		for _, row := range rows {
+			if col < len(row) && strings.TrimSpace(row[col]) != "" {
+				values = append(values, row[col])
+			}
+		}
+		types[col] = inferColumnType(values)
+	}
+	return types
+}
+
+func inferColumnType(values []string) ColumnType {
+	if len(values) == 0 {
+		return ColumnTypeString
+	}
+	for _, candidate := range []ColumnType{ColumnTypeInteger, ColumnTypeNumber, ColumnTypeBoolean, ColumnTypeDate} {
+		matches := true
+		for _, value := range values {
+			if !cellMatchesType(strings.TrimSpace(value), candidate) {
+				matches = false
+				break
+			}
+		}
+		if matches {
+			return candidate
+		}
+	}
+	return ColumnTypeString
+}
+
+func cellMatchesType(value string, columnType ColumnType) bool {
+	switch columnType {
+	case ColumnTypeInteger:
+		_, err := strconv.ParseInt(value, 10, 64)
+		return err == nil
+	case ColumnTypeNumber:
+		_, err := strconv.ParseFloat(value, 64)
+		return err == nil
+	case ColumnTypeBoolean:
+		switch strings.ToLower(value) {
+		case "true", "false":
+			return true
+		}
+		return false
+	case ColumnTypeDate:
+		_, ok := parseCellDate(value)
+		return ok
+	default:
+		return true
+	}
+}
+
+// parseCellDate parses a date cell of a ColumnTypeDate column.
+func parseCellDate(value string) (time.Time, bool) {
+	for _, layout := range []string{time.DateOnly, time.RFC3339, "2006-01-02 15:04:05"} {
+		if t, err := time.Parse(layout, value); err == nil {
+			return t, true
+		}
+	}
+	return time.Time{}, false
+}
+
+// tableMarkdown renders cells as a Markdown table using the first row as the header row.
+func tableMarkdown(cells [][]string) string {
+	return renderTableMarkdown(cells, false)
+}
+
+// renderTableMarkdown renders cells as a Markdown table. Runs of whitespace in a cell are
+// collapsed to one space unless preserveWhitespace is set, which only turns line breaks
+// into spaces so that each row stays on one line.
+func renderTableMarkdown(cells [][]string, preserveWhitespace bool) string {
+	if len(cells) == 0 {
+		return ""
+	}
+	width := 0
+	for _, row := range cells {
+		width = max(width, len(row))
+	}
+	var b strings.Builder
+	writeRow := func(row []string) {
+		b.WriteString("|")
+		for col := range width {
+			cell := ""
+			if col < len(row) {
+				if preserveWhitespace {
+					cell = strings.NewReplacer("\r\n", " ", "\n", " ", "\r", " ").Replace(row[col])
+				} else {
+					cell = strings.Join(strings.Fields(row[col]), " ")
+				}
+				cell = strings.ReplaceAll(cell, "|", "\\|")
+			}
+			b.WriteString(" " + cell + " |")
+		}
+		b.WriteString("\n")
+	}
+	writeRow(cells[0])
+	b.WriteString("|" + strings.Repeat(" --- |", width) + "\n")
+	for _, row := range cells[1:] {
+		writeRow(row)
+	}
+	return strings.TrimSuffix(b.String(), "\n")
+}
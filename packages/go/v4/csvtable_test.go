@@ -0,0 +1,109 @@
+package kreuzberg
+
+import (
+	"reflect"
+	"testing"
+)
+
+func TestCSVTableFromFixture(t *testing.T) {
+	result := &ExtractionResult{MimeType: "text/csv"}
+	src := &documentSource{path: getTestFilePath("pandoc/data_table.csv")}
+	if err := applyCSVTable(result, NewExtractionConfig(), src); err != nil {
+		t.Fatalf("applyCSVTable failed: %v", err)
+	}
+	if len(result.Tables) != 1 {
+		t.Fatalf("expected 1 table, got %d", len(result.Tables))
+	}
+	table := result.Tables[0]
+	if !table.HasHeader {
+		t.Error("expected header to be detected")
+	}
+	if got := table.Cells[0]; !reflect.DeepEqual(got, []string{"Name", "Department", "Salary", "Start Date", "Active"}) {
+		t.Errorf("unexpected header row %q", got)
+	}
+	want := []ColumnType{ColumnTypeString, ColumnTypeString, ColumnTypeInteger, ColumnTypeDate, ColumnTypeBoolean}
+	if !reflect.DeepEqual(table.ColumnTypes, want) {
+		t.Errorf("ColumnTypes = %v, want %v", table.ColumnTypes, want)
+	}
+	if table.PageNumber != 1 || table.Markdown == "" {
+		t.Errorf("unexpected table: %+v", table)
+	}
+}
+
+func TestCSVDialectDetection(t *testing.T) {
+	tests := []struct {
+		name      string
+		text      string
+		mimeType  string
+		rows      [][]string
+		hasHeader bool
+	}{
+		{
+			name:      "semicolon with quoted delimiter",
+			text:      "city;population\n\"Berlin; DE\";3645000\nParis;2161000\n",
+			mimeType:  "text/csv",
+			rows:      [][]string{{"city", "population"}, {"Berlin; DE", "3645000"}, {"Paris", "2161000"}},
+			hasHeader: true,
+		},
+		{
+			name:     "tab separated without header",
+			text:     "1\t2\t3\r\n4\t5\t6\r\n",
+			mimeType: "text/tab-separated-values",
+			rows:     [][]string{{"1", "2", "3"}, {"4", "5", "6"}},
+		},
+		{
+			name:      "single quotes with embedded newline and escaped quote",
+			text:      "'id','note'\n'1','it''s\nfine'\n'2','ok'\n",
+			mimeType:  "text/csv",
+			rows:      [][]string{{"id", "note"}, {"1", "it's\nfine"}, {"2", "ok"}},
+			hasHeader: true,
+		},
+	}
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			result := &ExtractionResult{MimeType: tc.mimeType}
+			if err := applyCSVTable(result, NewExtractionConfig(), &documentSource{data: []byte(tc.text)}); err != nil {
+				t.Fatalf("applyCSVTable failed: %v", err)
+			}
+			if len(result.Tables) != 1 {
+				t.Fatalf("expected 1 table, got %d", len(result.Tables))
+			}
+			if got := result.Tables[0].Cells; !reflect.DeepEqual(got, tc.rows) {
+				t.Errorf("cells = %q, want %q", got, tc.rows)
+			}
+			if got := result.Tables[0].HasHeader; got != tc.hasHeader {
+				t.Errorf("HasHeader = %v, want %v", got, tc.hasHeader)
+			}
+		})
+	}
+}
+
+func TestCSVDelimiterOverride(t *testing.T) {
+	text := "a|b,c\n1|2,3\n"
+	result := &ExtractionResult{MimeType: "text/csv"}
+	if err := applyCSVTable(result, NewExtractionConfig(WithCSVDelimiter('|')), &documentSource{data: []byte(text)}); err != nil {
+		t.Fatalf("applyCSVTable failed: %v", err)
+	}
+	want := [][]string{{"a", "b,c"}, {"1", "2,3"}}
+	if got := result.Tables[0].Cells; !reflect.DeepEqual(got, want) {
+		t.Fatalf("cells = %q, want %q", got, want)
+	}
+}
+
+func TestCSVTableIgnoresOtherFormats(t *testing.T) {
+	result := &ExtractionResult{MimeType: "text/plain"}
+	if err := applyCSVTable(result, NewExtractionConfig(), &documentSource{data: []byte("a,b\n1,2\n")}); err != nil {
+		t.Fatal(err)
+	}
+	if len(result.Tables) != 0 {
+		t.Fatalf("expected no tables, got %d", len(result.Tables))
+	}
+}
+
+func TestTableMarkdown(t *testing.T) {
+	got := tableMarkdown([][]string{{"a", "b|c"}, {"1"}})
+	want := "| a | b\\|c |\n| --- | --- |\n| 1 |  |"
+	if got != want {
+		t.Fatalf("tableMarkdown = %q, want %q", got, want)
+	}
+}
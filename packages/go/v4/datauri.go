@@ -0,0 +1,76 @@
+package kreuzberg
+
+import (
+	"context"
+	"encoding/base64"
+	"fmt"
+	"net/url"
+	"strings"
+)
+
+// ExtractDataURI extracts content and metadata from a data URI such as
+// "data:application/pdf;base64,JVBERi0...", using the MIME type the URI declares. Both
+// base64 payloads and percent-encoded ones ("data:text/plain,hello%20world") are accepted;
+// a URI without a MIME type is text/plain, as RFC 2397 specifies. Malformed URIs and MIME
+// types that neither the native library nor a registered Go extractor handles return a
+// ValidationError.
+func ExtractDataURI(uri string, config *ExtractionConfig) (*ExtractionResult, error) {
+	data, mimeType, err := parseDataURI(uri)
+	if err != nil {
+		return nil, err
+	}
+	if lookupExtractor(mimeType) == nil {
+		if _, err := ValidateMimeType(mimeType); err != nil {
+			return nil, newValidationErrorWithContext(fmt.Sprintf("unsupported data URI mime type %q", mimeType), err, ErrorCodeValidation, nil)
+		}
+	}
+	return extractBytes(context.Background(), data, mimeType, config)
+}
+
+// parseDataURI returns the payload and the MIME type, without parameters, of a data URI.
+func parseDataURI(uri string) ([]byte, string, error) {
+	invalid := func(reason string, cause error) ([]byte, string, error) {
+		return nil, "", newValidationErrorWithContext("invalid data URI: "+reason, cause, ErrorCodeValidation, nil)
+	}
+	if len(uri) < len("data:") || !strings.EqualFold(uri[:len("data:")], "data:") {
+		return invalid(`it must start with "data:"`, nil)
+	}
+	header, payload, ok := strings.Cut(uri[len("data:"):], ",")
+	if !ok {
+		return invalid("missing ',' before the data", nil)
+	}
+
+	params := strings.Split(header, ";")
+	encoded := false
+	if last := len(params) - 1; last > 0 && strings.EqualFold(strings.TrimSpace(params[last]), "base64") {
+		encoded = true
+		params = params[:last]
+	}
+	mimeType := normalizeMimeType(params[0])
+	if mimeType == "" {
+		mimeType = "text/plain"
+	} else if !strings.Contains(mimeType, "/") {
+		return invalid(fmt.Sprintf("malformed mime type %q", params[0]), nil)
+	}
+
+	if !encoded {
+		data, err := url.PathUnescape(payload)
+		if err != nil {
+			return invalid("malformed percent-encoding", err)
+		}
+		return []byte(data), mimeType, nil
+	}
+	payload, err := url.PathUnescape(payload)
+	if err != nil {
+		return invalid("malformed percent-encoding", err)
+	}
+	payload = strings.Join(strings.Fields(payload), "")
+	data, err := base64.StdEncoding.DecodeString(payload)
+	if err != nil {
+		data, err = base64.RawStdEncoding.DecodeString(strings.TrimRight(payload, "="))
+	}
+	if err != nil {
+		return invalid("malformed base64 data", err)
+	}
+	return data, mimeType, nil
+}
@@ -0,0 +1,64 @@
+package kreuzberg
+
+import (
+	"errors"
+	"testing"
+)
+
+func TestParseDataURI(t *testing.T) {
+	tests := []struct {
+		uri      string
+		data     string
+		mimeType string
+	}{
+		{"data:text/plain;base64,aGVsbG8gd29ybGQ=", "hello world", "text/plain"},
+		{"data:Application/PDF;name=a.pdf;base64,JVBERi0x", "%PDF-1", "application/pdf"},
+		{"data:text/plain;base64,aGVsbG8gd29ybGQ", "hello world", "text/plain"},
+		{"data:text/html;charset=utf-8,%3Cp%3Ea+b%3C%2Fp%3E", "<p>a+b</p>", "text/html"},
+		{"DATA:,plain%20text", "plain text", "text/plain"},
+	}
+	for _, tt := range tests {
+		data, mimeType, err := parseDataURI(tt.uri)
+		if err != nil {
+			t.Errorf("parseDataURI(%q) failed: %v", tt.uri, err)
+			continue
+		}
+		if string(data) != tt.data || mimeType != tt.mimeType {
+			t.Errorf("parseDataURI(%q) = %q, %q; want %q, %q", tt.uri, data, mimeType, tt.data, tt.mimeType)
+		}
+	}
+}
+
+func TestExtractDataURIMalformed(t *testing.T) {
+	for _, uri := range []string{
+		"",
+		"text/plain;base64,aGVsbG8=",
+		"data:text/plain;base64",
+		"data:text/plain;base64,not base64!",
+		"data:text/plain,%zz",
+		"data:pdf;base64,JVBERi0x",
+	} {
+		var valErr *ValidationError
+		if _, err := ExtractDataURI(uri, nil); !errors.As(err, &valErr) {
+			t.Errorf("ExtractDataURI(%q): expected ValidationError, got %v", uri, err)
+		}
+	}
+}
+
+func TestExtractDataURI(t *testing.T) {
+	if _, err := ExtractBytesSync([]byte("probe"), "text/plain", nil); err != nil {
+		t.Skipf("native extraction unavailable: %v", err)
+	}
+	result, err := ExtractDataURI("data:text/plain;base64,aGVsbG8gd29ybGQ=", nil)
+	if err != nil {
+		t.Fatalf("ExtractDataURI failed: %v", err)
+	}
+	if result.MimeType != "text/plain" || result.Content != "hello world" {
+		t.Fatalf("unexpected result %q (%s)", result.Content, result.MimeType)
+	}
+
+	var valErr *ValidationError
+	if _, err := ExtractDataURI("data:application/x-unknown-format;base64,aGVsbG8=", nil); !errors.As(err, &valErr) {
+		t.Fatalf("expected ValidationError for an unsupported mime type, got %v", err)
+	}
+}
@@ -0,0 +1,73 @@
+package kreuzberg
+
+import (
+	"encoding/json"
+	"sync/atomic"
+)
+
+// defaultConfig is the config used by extractions called with a nil config.
+var defaultConfig atomic.Pointer[ExtractionConfig]
+
+// SetDefaultConfig registers config as the package-level default, used by every extraction
+// function called with a nil config, including Extractors created with a nil config. An
+// explicit non-nil config always wins and is used as it is, without merging in the default;
+// use ConfigMerge on a copy from DefaultConfig to extend the default instead. Without a
+// default, nil selects the library defaults. A nil config removes the default.
+//
+// A deep copy of config is stored, so later changes to config do not affect the default.
+// Callback fields such as ContentFilter and Logger are shared with the copy. The default
+// is not validated here; an invalid default fails every extraction that uses it.
+// SetDefaultConfig is safe to call concurrently with extractions, which use the default
+// registered when they start.
+func SetDefaultConfig(config *ExtractionConfig) {
+	if config == nil {
+		defaultConfig.Store(nil)
+		return
+	}
+	cfg := cloneConfig(config)
+	if encoded, err := encodeConfig(cfg); err == nil {
+		cfg.encoded = encoded
+	}
+	defaultConfig.Store(cfg)
+}
+
+// DefaultConfig returns a copy of the config registered with SetDefaultConfig, or nil when
+// there is none.
+func DefaultConfig() *ExtractionConfig {
+	cfg := defaultConfig.Load()
+	if cfg == nil {
+		return nil
+	}
+	return cloneConfig(cfg)
+}
+
+// configOrDefault returns config, or the registered default when config is nil.
+func configOrDefault(config *ExtractionConfig) *ExtractionConfig {
+	if config != nil {
+		return config
+	}
+	return defaultConfig.Load()
+}
+
+// cloneConfig returns a deep copy of config. Fields that are not serialized are copied
+// as they are.
+func cloneConfig(config *ExtractionConfig) *ExtractionConfig {
+	clone := &ExtractionConfig{}
+	if data, err := json.Marshal(config); err == nil && json.Unmarshal(data, clone) == nil {
+		clone.ContentFilter = config.ContentFilter
+		clone.CellFilter = config.CellFilter
+		clone.Logger = config.Logger
+		clone.SignatureRoots = config.SignatureRoots
+		clone.ContextFields = config.ContextFields
+		if clone.OCR != nil && config.OCR != nil {
+			clone.OCR.DownloadProgress = config.OCR.DownloadProgress
+		}
+		if clone.Images != nil && config.Images != nil {
+			clone.Images.Captioner = config.Images.Captioner
+		}
+		return clone
+	}
+	cfg := *config
+	cfg.encoded = nil
+	return &cfg
+}
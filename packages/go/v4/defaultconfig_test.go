@@ -0,0 +1,53 @@
+package kreuzberg
+
+import (
+	"strings"
+	"testing"
+)
+
+func TestSetDefaultConfig(t *testing.T) {
+	registerTestExtractor(t)
+	t.Cleanup(func() { SetDefaultConfig(nil) })
+
+	config := NewExtractionConfig(
+		WithContentFilter(func(s string) string { return s + "!" }),
+		WithOCR(WithOCRBackend("tesseract")),
+	)
+	SetDefaultConfig(config)
+	config.OCR.Backend = "easyocr"
+	config.ContentFilter = nil
+
+	result, err := ExtractBytesSync([]byte("acme"), testExtractorMime, nil)
+	if err != nil || result.Content != "ACME!" {
+		t.Fatalf("expected the default config to apply to a nil config, got %+v, %v", result, err)
+	}
+	result, err = ExtractBytesSync([]byte("acme"), testExtractorMime, NewExtractionConfig())
+	if err != nil || result.Content != "ACME" {
+		t.Fatalf("expected an explicit config to win, got %+v, %v", result, err)
+	}
+	extractor, err := NewExtractor(nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if result, err := extractor.ExtractBytesWithContext(t.Context(), []byte("acme"), testExtractorMime); err != nil || result.Content != "ACME!" {
+		t.Fatalf("expected an Extractor without config to use the default, got %+v, %v", result, err)
+	}
+
+	current := DefaultConfig()
+	if current == nil || current.OCR == nil || current.OCR.Backend != "tesseract" || current.ContentFilter == nil {
+		t.Fatalf("DefaultConfig = %+v", current)
+	}
+	current.OCR.Backend = "paddleocr"
+	if DefaultConfig().OCR.Backend != "tesseract" {
+		t.Fatal("expected DefaultConfig to return a copy")
+	}
+
+	SetDefaultConfig(nil)
+	if DefaultConfig() != nil {
+		t.Fatal("expected SetDefaultConfig(nil) to remove the default")
+	}
+	result, err = ExtractBytesSync([]byte("acme"), testExtractorMime, nil)
+	if err != nil || strings.HasSuffix(result.Content, "!") {
+		t.Fatalf("expected library defaults after removing the default, got %+v, %v", result, err)
+	}
+}
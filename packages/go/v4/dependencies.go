@@ -0,0 +1,104 @@
+package kreuzberg
+
+import (
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+)
+
+// RequiredDependencies returns the external components extractions with config need at run
+// time, named as in MissingDependencyError.Dependency: the OCR backend (for example
+// "tesseract") and, for Tesseract, the language data of each configured language (for
+// example "tesseract-eng"). Language data is not listed when OCRConfig.AutoDownloadLanguages
+// is enabled, since it is fetched when needed. A nil config stands for the default config
+// (see SetDefaultConfig).
+func RequiredDependencies(config *ExtractionConfig) ([]string, error) {
+	config = configOrDefault(config)
+	if config == nil || config.OCR == nil {
+		return nil, nil
+	}
+	ocr := config.OCR
+	backend := ocr.Backend
+	if backend == "" {
+		backend = "tesseract"
+	}
+	dependencies := []string{backend}
+	if !strings.EqualFold(backend, "tesseract") || (ocr.AutoDownloadLanguages != nil && *ocr.AutoDownloadLanguages) {
+		return dependencies, nil
+	}
+	languages, err := tesseractLanguages(ocr)
+	if err != nil {
+		return nil, err
+	}
+	for _, language := range languages {
+		dependencies = append(dependencies, "tesseract-"+language)
+	}
+	return dependencies, nil
+}
+
+// PreflightDependencies checks that everything RequiredDependencies lists for config is
+// installed, so that a service can refuse to start instead of failing requests later. It
+// returns nil when nothing is missing, and otherwise one *MissingDependencyError per missing
+// dependency joined with errors.Join; errors.As finds the first. OCR backends must be
+// registered with the native library, and Tesseract language data must be in
+// OCRConfig.LanguageDataDir, TESSDATA_PREFIX or a directory Tesseract searches by default.
+func PreflightDependencies(config *ExtractionConfig) error {
+	dependencies, err := RequiredDependencies(config)
+	if err != nil || len(dependencies) == 0 {
+		return err
+	}
+	backends, err := ListOCRBackends()
+	if err != nil {
+		return err
+	}
+	config = configOrDefault(config)
+
+	var missing []error
+	for _, dependency := range dependencies {
+		if language, ok := strings.CutPrefix(dependency, "tesseract-"); ok {
+			if !tessdataInstalled(config.OCR, language) {
+				missing = append(missing, newMissingDependencyErrorWithContext(dependency,
+					fmt.Sprintf("Tesseract language data for %q is not installed", language), nil, ErrorCodeMissingDependency, nil))
+			}
+			continue
+		}
+		if !containsFold(backends, dependency) {
+			missing = append(missing, newMissingDependencyErrorWithContext(dependency,
+				fmt.Sprintf("OCR backend %q is not registered", dependency), nil, ErrorCodeMissingDependency, nil))
+		}
+	}
+	return errors.Join(missing...)
+}
+
+// tessdataInstalled reports whether the traineddata file of language is in one of the
+// directories Tesseract loads language data from.
+func tessdataInstalled(ocr *OCRConfig, language string) bool {
+	var dirs []string
+	if ocr.Tesseract != nil && ocr.Tesseract.TessdataPath != "" {
+		dirs = append(dirs, ocr.Tesseract.TessdataPath)
+	}
+	if ocr.LanguageDataDir != nil && *ocr.LanguageDataDir != "" {
+		dirs = append(dirs, *ocr.LanguageDataDir)
+	}
+	if prefix := os.Getenv("TESSDATA_PREFIX"); prefix != "" {
+		dirs = append(dirs, prefix, filepath.Join(prefix, "tessdata"))
+	}
+	dirs = append(dirs, tessdataSearchPaths...)
+	for _, dir := range dirs {
+		if info, err := os.Stat(filepath.Join(dir, language+".traineddata")); err == nil && !info.IsDir() {
+			return true
+		}
+	}
+	return false
+}
+
+func containsFold(values []string, value string) bool {
+	for _, v := range values {
+		if strings.EqualFold(v, value) {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,68 @@
+package kreuzberg
+
+import (
+	"errors"
+	"os"
+	"path/filepath"
+	"reflect"
+	"strings"
+	"testing"
+)
+
+func TestRequiredDependencies(t *testing.T) {
+	dependencies, err := RequiredDependencies(NewExtractionConfig())
+	if err != nil || len(dependencies) != 0 {
+		t.Fatalf("expected no dependencies without OCR, got %v, %v", dependencies, err)
+	}
+
+	dependencies, err = RequiredDependencies(NewExtractionConfig(WithOCR(WithOCRLanguage("eng+deu"))))
+	if err != nil {
+		t.Fatalf("RequiredDependencies: %v", err)
+	}
+	if want := []string{"tesseract", "tesseract-eng", "tesseract-deu"}; !reflect.DeepEqual(dependencies, want) {
+		t.Fatalf("dependencies = %v, want %v", dependencies, want)
+	}
+
+	dependencies, err = RequiredDependencies(NewExtractionConfig(WithOCR(WithOCRLanguage("eng"), WithOCRAutoDownloadLanguages(true))))
+	if err != nil || !reflect.DeepEqual(dependencies, []string{"tesseract"}) {
+		t.Fatalf("expected only the backend with auto-download, got %v, %v", dependencies, err)
+	}
+}
+
+func TestPreflightDependencies(t *testing.T) {
+	if err := PreflightDependencies(NewExtractionConfig()); err != nil {
+		t.Fatalf("expected no error without OCR, got %v", err)
+	}
+
+	// Simulate a system without Tesseract language data.
+	t.Setenv("TESSDATA_PREFIX", "")
+	previous := tessdataSearchPaths
+	tessdataSearchPaths = nil
+	t.Cleanup(func() { tessdataSearchPaths = previous })
+	dir := t.TempDir()
+
+	config := NewExtractionConfig(WithOCR(WithOCRLanguage("eng+deu"), WithOCRLanguageDataDir(dir)))
+	err := PreflightDependencies(config)
+	var missing *MissingDependencyError
+	if !errors.As(err, &missing) {
+		t.Fatalf("expected a MissingDependencyError, got %v", err)
+	}
+	for _, language := range []string{"eng", "deu"} {
+		if !strings.Contains(err.Error(), `"`+language+`"`) {
+			t.Errorf("error %q does not mention %s", err, language)
+		}
+	}
+
+	if err := os.WriteFile(filepath.Join(dir, "eng.traineddata"), []byte("installed"), 0o644); err != nil {
+		t.Fatal(err)
+	}
+	err = PreflightDependencies(config)
+	if err == nil || strings.Contains(err.Error(), `"eng"`) || !strings.Contains(err.Error(), `"deu"`) {
+		t.Fatalf("expected only deu to be missing, got %v", err)
+	}
+
+	err = PreflightDependencies(NewExtractionConfig(WithOCR(WithOCRBackend("no-such-backend"))))
+	if !errors.As(err, &missing) || missing.Dependency != "no-such-backend" {
+		t.Fatalf("expected the unregistered backend to be missing, got %v", err)
+	}
+}
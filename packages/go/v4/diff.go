@@ -0,0 +1,191 @@
+package kreuzberg
+
+import "strings"
+
+// ContentDiff is a hunk of consecutive line changes between two extraction results.
+// LineNumber is the 1-based line in the first result where the hunk starts; for pure
+// insertions it is the line before which the Added lines appear.
+type ContentDiff struct {
+	Added      []string
+	Removed    []string
+	LineNumber int
+}
+
+// DiffContent produces a line-level diff of the Content of two extraction results using a
+// longest-common-subsequence alignment. It returns nil when the contents are identical.
+// A nil result is treated as empty content.
+//
+// The diff takes time quadratic in the number of differing lines (the common prefix and
+// suffix are skipped first) but only memory linear in it, which is fine for regression
+// checks over typical documents.
+func DiffContent(a, b *ExtractionResult) []ContentDiff {
+	linesA := resultLines(a)
+	linesB := resultLines(b)
+	ops := diffLines(linesA, linesB)
+
+	var diffs []ContentDiff
+	var current *ContentDiff
+	lineA := 1
+	for _, op := range ops {
+		switch op.kind {
+		case diffEqual:
+			if current != nil {
+				diffs = append(diffs, *current)
+				current = nil
+			}
+			lineA++
+		case diffRemove:
+			if current == nil {
+				current = &ContentDiff{LineNumber: lineA}
+			}
+			current.Removed = append(current.Removed, op.line)
+			lineA++
+		case diffAdd:
+			if current == nil {
+				current = &ContentDiff{LineNumber: lineA}
+			}
+			current.Added = append(current.Added, op.line)
+		}
+	}
+	if current != nil {
+		diffs = append(diffs, *current)
+	}
+	return diffs
+}
+
+// ContentSimilarity returns a 0–1 ratio describing how similar the Content of two results
+// is, computed as 2*common/(linesA+linesB) over lines. Two empty contents are identical (1).
+func ContentSimilarity(a, b *ExtractionResult) float64 {
+	linesA := resultLines(a)
+	linesB := resultLines(b)
+	total := len(linesA) + len(linesB)
+	if total == 0 {
+		return 1
+	}
+
+	common := 0
+	for _, op := range diffLines(linesA, linesB) {
+		if op.kind == diffEqual {
+			common++
+		}
+	}
+	return float64(2*common) / float64(total)
+}
+
+type diffKind int
+
+const (
+	diffEqual diffKind = iota
+	diffRemove
+	diffAdd
+)
+
+type diffOp struct {
+	kind diffKind
+	line string
+}
+
+func resultLines(r *ExtractionResult) []string {
+	if r == nil || r.Content == "" {
+		return nil
+	}
+	content := strings.ReplaceAll(r.Content, "\r\n", "\n")
+	return strings.Split(strings.TrimSuffix(content, "\n"), "\n")
+}
+
+// diffLines aligns a and b after trimming the common prefix and suffix, returning the edit
+// script in order. The lines in between are aligned with Hirschberg's algorithm, which finds
+// a longest common subsequence in time proportional to the product of their counts but in
+// space proportional to their sum.
+func diffLines(a, b []string) []diffOp {
+	prefix := 0
+	for prefix < len(a) && prefix < len(b) && a[prefix] == b[prefix] {
+		prefix++
+	}
+	suffix := 0
+	for suffix < len(a)-prefix && suffix < len(b)-prefix && a[len(a)-1-suffix] == b[len(b)-1-suffix] {
+		suffix++
+	}
+
+	ops := make([]diffOp, 0, len(a)+len(b))
+	for _, line := range a[:prefix] {
+		ops = append(ops, diffOp{kind: diffEqual, line: line})
+	}
+	ops = alignLines(ops, a[prefix:len(a)-suffix], b[prefix:len(b)-suffix])
+	for _, line := range a[len(a)-suffix:] {
+		ops = append(ops, diffOp{kind: diffEqual, line: line})
+	}
+	return ops
+}
+
+// alignLines appends to ops an edit script turning a into b that keeps a longest common
+// subsequence of them. It splits a in half and b where the LCS lengths of the two halves
+// add up to the most, then aligns each pair of halves.
+func alignLines(ops []diffOp, a, b []string) []diffOp {
+	switch {
+	case len(a) == 0:
+		for _, line := range b {
+			ops = append(ops, diffOp{kind: diffAdd, line: line})
+		}
+		return ops
+	case len(b) == 0:
+		for _, line := range a {
+			ops = append(ops, diffOp{kind: diffRemove, line: line})
+		}
+		return ops
+	case len(a) == 1:
+		for j, line := range b {
+			if line == a[0] {
+				ops = alignLines(ops, nil, b[:j])
+				ops = append(ops, diffOp{kind: diffEqual, line: line})
+				return alignLines(ops, nil, b[j+1:])
+			}
+		}
+		ops = append(ops, diffOp{kind: diffRemove, line: a[0]})
+		return alignLines(ops, nil, b)
+	}
+
+	mid := len(a) / 2
+	head := lcsPrefixLengths(a[:mid], b)
+	tail := lcsSuffixLengths(a[mid:], b)
+	split := 0
+	for j := range head {
+		if head[j]+tail[j] > head[split]+tail[split] {
+			split = j
+		}
+	}
+	ops = alignLines(ops, a[:mid], b[:split])
+	return alignLines(ops, a[mid:], b[split:])
+}
+
+// lcsPrefixLengths returns, for every j, the LCS length of a and b[:j].
+func lcsPrefixLengths(a, b []string) []int {
+	prev, cur := make([]int, len(b)+1), make([]int, len(b)+1)
+	for i := range a {
+		for j := range b {
+			if a[i] == b[j] {
+				cur[j+1] = prev[j] + 1
+			} else {
+				cur[j+1] = max(prev[j+1], cur[j])
+			}
+		}
+		prev, cur = cur, prev
+	}
+	return prev
+}
+
+// lcsSuffixLengths returns, for every j, the LCS length of a and b[j:].
+func lcsSuffixLengths(a, b []string) []int {
+	prev, cur := make([]int, len(b)+1), make([]int, len(b)+1)
+	for i := len(a) - 1; i >= 0; i-- {
+		for j := len(b) - 1; j >= 0; j-- {
+			if a[i] == b[j] {
+				cur[j] = prev[j+1] + 1
+			} else {
+				cur[j] = max(prev[j], cur[j+1])
+			}
+		}
+		prev, cur = cur, prev
+	}
+	return prev
+}
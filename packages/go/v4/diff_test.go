@@ -0,0 +1,77 @@
+package kreuzberg
+
+import (
+	"fmt"
+	"math"
+	"reflect"
+	"strings"
+	"testing"
+)
+
+// TestDiffContentIdentical verifies that identical contents produce no diff.
+func TestDiffContentIdentical(t *testing.T) {
+	a := &ExtractionResult{Content: "alpha\nbeta\ngamma\n"}
+	b := &ExtractionResult{Content: "alpha\r\nbeta\r\ngamma"}
+
+	if diffs := DiffContent(a, b); diffs != nil {
+		t.Errorf("expected no diff, got %+v", diffs)
+	}
+	if sim := ContentSimilarity(a, b); sim != 1 {
+		t.Errorf("expected similarity 1, got %v", sim)
+	}
+}
+
+// TestDiffContentHunks verifies grouping of changed, inserted and appended lines.
+func TestDiffContentHunks(t *testing.T) {
+	a := &ExtractionResult{Content: "one\ntwo\nthree\nfour"}
+	b := &ExtractionResult{Content: "one\n2\nthree\ninserted\nfour\nfive"}
+
+	expected := []ContentDiff{
+		{Removed: []string{"two"}, Added: []string{"2"}, LineNumber: 2},
+		{Added: []string{"inserted"}, LineNumber: 4},
+		{Added: []string{"five"}, LineNumber: 5},
+	}
+	if diffs := DiffContent(a, b); !reflect.DeepEqual(diffs, expected) {
+		t.Errorf("unexpected diff:\n got  %+v\n want %+v", diffs, expected)
+	}
+}
+
+// TestDiffContentNilResults verifies nil results are treated as empty content.
+func TestDiffContentNilResults(t *testing.T) {
+	b := &ExtractionResult{Content: "only line"}
+
+	expected := []ContentDiff{{Added: []string{"only line"}, LineNumber: 1}}
+	if diffs := DiffContent(nil, b); !reflect.DeepEqual(diffs, expected) {
+		t.Errorf("unexpected diff: %+v", diffs)
+	}
+	if sim := ContentSimilarity(nil, nil); sim != 1 {
+		t.Errorf("expected two empty results to be identical, got %v", sim)
+	}
+	if sim := ContentSimilarity(nil, b); sim != 0 {
+		t.Errorf("expected similarity 0 against empty content, got %v", sim)
+	}
+}
+
+// TestContentSimilarityRatio verifies the 2*common/total ratio.
+func TestContentSimilarityRatio(t *testing.T) {
+	a := &ExtractionResult{Content: "a\nb\nc\nd"}
+	b := &ExtractionResult{Content: "a\nx\nc\nd"}
+
+	if sim := ContentSimilarity(a, b); math.Abs(sim-0.75) > 1e-9 {
+		t.Errorf("expected similarity 0.75, got %v", sim)
+	}
+}
+
+// TestDiffContentLargeDocuments verifies that two large contents differing on every line
+// are diffed without a table of all line pairs.
+func TestDiffContentLargeDocuments(t *testing.T) {
+	var a, b strings.Builder
+	for i := 0; i < 5000; i++ {
+		fmt.Fprintf(&a, "a%d\n", i)
+		fmt.Fprintf(&b, "b%d\n", i)
+	}
+	diffs := DiffContent(&ExtractionResult{Content: a.String()}, &ExtractionResult{Content: b.String()})
+	if len(diffs) != 1 || len(diffs[0].Removed) != 5000 || len(diffs[0].Added) != 5000 {
+		t.Fatalf("expected one hunk replacing every line, got %d hunks", len(diffs))
+	}
+}
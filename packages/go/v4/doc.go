@@ -106,6 +106,24 @@
 //		fmt.Printf("[%d] %s => %d bytes\n", i, res.MimeType, len(res.Content))
 //	}
 //
+// For long-running services, an Extractor encodes its configuration once and reports
+// per-document results, so one bad file does not fail the whole batch:
+//
+//	extractor, err := kreuzberg.NewExtractor(config, kreuzberg.WithWorkers(4))
+//	if err != nil {
+//		log.Fatal(err)
+//	}
+//	for _, res := range extractor.BatchFiles(ctx, paths) {
+//		if res.Err != nil {
+//			log.Printf("%s: %v", paths[res.Index], res.Err)
+//			continue
+//		}
+//		fmt.Println(len(res.Result.Content))
+//	}
+//
+// Jobs that should fail fast instead create the Extractor with WithStopOnFirstError(true)
+// and check FirstBatchError(results), which names the index and path of the failed document.
+//
 // # Concurrency and Goroutines
 //
 // All extraction functions are synchronous and block until completion.
@@ -128,9 +146,17 @@
 //	}
 //	wg.Wait()
 //
-// Note: Extraction operations cannot be canceled once started. If you need
-// timeouts, implement them at the application level (e.g., using channels
-// with time.After or dedicated timeout goroutines).
+// For timeouts, use the context-aware variants. The native extraction cannot be
+// interrupted once started, so ExtractFileWithContext and friends return ctx.Err()
+// as soon as the context ends and let the abandoned native call finish in the
+// background:
+//
+//	ctx, cancel := context.WithTimeout(context.Background(), 30*time.Second)
+//	defer cancel()
+//	result, err := kreuzberg.ExtractFileWithContext(ctx, "scanned.pdf", cfg)
+//	if errors.Is(err, context.DeadlineExceeded) {
+//		log.Println("extraction timed out")
+//	}
 //
 // # Error Handling
 //
@@ -154,6 +180,27 @@
 //		}
 //	}
 //
+// Password-protected documents fail with an EncryptedDocumentError rather than a
+// ParsingError. Supply passwords with WithDocumentPasswords; they are tried for PDF and
+// for encrypted DOCX, XLSX and PPTX files:
+//
+//	var encErr *kreuzberg.EncryptedDocumentError
+//	if errors.As(err, &encErr) {
+//		if encErr.PasswordRejected() {
+//			log.Printf("wrong password for %s document", encErr.Format())
+//		} else {
+//			log.Printf("%s document needs a password", encErr.Format())
+//		}
+//	}
+//
+// With WithEmptyContentIsError, extractions that produce no text fail with an
+// EmptyResultError instead of returning an empty result:
+//
+//	var emptyErr *kreuzberg.EmptyResultError
+//	if errors.As(err, &emptyErr) {
+//		log.Printf("nothing extracted, %d warnings", len(emptyErr.Result().Warnings))
+//	}
+//
 // # Metadata Types
 //
 // Each document format supports format-specific metadata. Use the FormatType() method
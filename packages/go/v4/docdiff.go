@@ -0,0 +1,257 @@
+package kreuzberg
+
+import "strings"
+
+// Change types reported in DocumentChange.Type.
+const (
+	DocumentChangeAdded   = "added"
+	DocumentChangeRemoved = "removed"
+	DocumentChangeChanged = "changed"
+)
+
+// documentChangeSimilarity is the share of words a removed and an added block must have in
+// common to be reported as one changed block.
+const documentChangeSimilarity = 0.5
+
+// DocumentDiff is the structural difference between two documents.
+type DocumentDiff struct {
+	// Changes are the changed blocks in document order.
+	Changes []DocumentChange `json:"changes,omitempty"`
+}
+
+// DocumentChange is a paragraph, heading or table that was added, removed or changed
+// between two documents.
+type DocumentChange struct {
+	Type string `json:"type"`
+	// Kind is the kind of block: BlockTypeParagraph, BlockTypeHeading or BlockTypeTable.
+	Kind string `json:"kind"`
+	// Section is the title of the heading the block is under, in the second document for
+	// added and changed blocks and in the first for removed ones; empty before the first
+	// heading.
+	Section string `json:"section,omitempty"`
+	// Old and New are the text of the block in the first and second document; tables are
+	// given as Markdown. Only New is set for added blocks and only Old for removed ones.
+	Old string `json:"old,omitempty"`
+	New string `json:"new,omitempty"`
+	// OldPage and NewPage are the pages of the block in each document, 0 where it is absent.
+	OldPage int `json:"old_page,omitempty"`
+	NewPage int `json:"new_page,omitempty"`
+}
+
+// DiffDocuments extracts the documents at paths a and b with config and returns how b
+// differs from a; see DiffResults.
+func DiffDocuments(a, b string, config *ExtractionConfig) (*DocumentDiff, error) {
+	resultA, err := ExtractFileSync(a, config)
+	if err != nil {
+		return nil, err
+	}
+	resultB, err := ExtractFileSync(b, config)
+	if err != nil {
+		return nil, err
+	}
+	return DiffResults(resultA, resultB), nil
+}
+
+// DiffResults compares the structure of two extraction results. Unlike DiffContent, which
+// compares lines, it splits Content into paragraphs, headings and Markdown tables as
+// structured output does and aligns them section by section: the sections returned by
+// Sections are matched by title first, and the blocks of each pair of sections are then
+// matched by their text with whitespace collapsed, so that text reflowed onto other lines
+// counts as unchanged. Sections without a match are compared together with the unmatched
+// sections next to them. A removed and an added block of the same kind that share at least
+// half their words are reported as one changed block. A nil result is treated as empty.
+func DiffResults(a, b *ExtractionResult) *DocumentDiff {
+	sectionsA, sectionsB := diffSections(a), diffSections(b)
+	keysA, keysB := make([]string, len(sectionsA)), make([]string, len(sectionsB))
+	for i, section := range sectionsA {
+		keysA[i] = section.key
+	}
+	for i, section := range sectionsB {
+		keysB[i] = section.key
+	}
+
+	diff := &DocumentDiff{}
+	var pendingA, pendingB []diffBlock
+	flush := func() {
+		diff.Changes = append(diff.Changes, diffBlocks(pendingA, pendingB)...)
+		pendingA, pendingB = nil, nil
+	}
+	i, j := 0, 0
+	for _, op := range diffLines(keysA, keysB) {
+		switch op.kind {
+		case diffEqual:
+			flush()
+			pendingA, pendingB = sectionsA[i].blocks, sectionsB[j].blocks
+			flush()
+			i++
+			j++
+		case diffRemove:
+			pendingA = append(pendingA, sectionsA[i].blocks...)
+			i++
+		case diffAdd:
+			pendingB = append(pendingB, sectionsB[j].blocks...)
+			j++
+		}
+	}
+	flush()
+	return diff
+}
+
+// diffSection is a section of a result: the content before the first heading, or a
+// heading and the blocks up to the next one.
+type diffSection struct {
+	key    string
+	blocks []diffBlock
+}
+
+// diffBlock is a block of content. words is its text normalized for comparison and key
+// adds its kind.
+type diffBlock struct {
+	kind    string
+	text    string
+	words   string
+	key     string
+	section string
+	page    int
+}
+
+// diffSections splits the content of r into its sections.
+func diffSections(r *ExtractionResult) []diffSection {
+	sections := []diffSection{{key: "\x00"}}
+	if r == nil {
+		return sections
+	}
+	content := r.Content
+	pageOf := contentPageFunc(r)
+	headings := r.Sections()
+	title := ""
+	for _, cb := range splitContentBlocks(content) {
+		for len(headings) > 0 && headings[0].Offset <= cb.start {
+			title = headings[0].Title
+			sections = append(sections, diffSection{key: strings.ToLower(strings.Join(strings.Fields(title), " "))})
+			headings = headings[1:]
+		}
+		block := diffBlock{kind: cb.kind, text: strings.TrimSpace(content[cb.start:cb.end]), section: title, page: pageOf(cb.start)}
+		switch cb.kind {
+		case BlockTypeHeading:
+			block.text = cb.title
+			block.words = strings.Join(strings.Fields(cb.title), " ")
+		case BlockTypeTable:
+			var rows []string
+			for _, line := range strings.Split(block.text, "\n") {
+				if words := tableWords(line); len(words) > 0 {
+					rows = append(rows, strings.Join(words, " "))
+				}
+			}
+			block.words = strings.Join(rows, "\n")
+		default:
+			block.words = strings.Join(strings.Fields(block.text), " ")
+		}
+		block.key = cb.kind + "\x00" + block.words
+		last := &sections[len(sections)-1]
+		last.blocks = append(last.blocks, block)
+	}
+	return sections
+}
+
+// diffBlocks returns the changes between two runs of blocks.
+func diffBlocks(a, b []diffBlock) []DocumentChange {
+	if len(a) == 0 && len(b) == 0 {
+		return nil
+	}
+	keysA, keysB := make([]string, len(a)), make([]string, len(b))
+	for i, block := range a {
+		keysA[i] = block.key
+	}
+	for i, block := range b {
+		keysB[i] = block.key
+	}
+
+	var changes []DocumentChange
+	var removed, added []diffBlock
+	flush := func() {
+		changes = append(changes, pairDiffBlocks(removed, added)...)
+		removed, added = nil, nil
+	}
+	i, j := 0, 0
+	for _, op := range diffLines(keysA, keysB) {
+		switch op.kind {
+		case diffEqual:
+			flush()
+			i++
+			j++
+		case diffRemove:
+			removed = append(removed, a[i])
+			i++
+		case diffAdd:
+			added = append(added, b[j])
+			j++
+		}
+	}
+	flush()
+	return changes
+}
+
+// pairDiffBlocks reports the removed and added blocks of a hunk, pairing a removed block
+// with the first later added block of the same kind that is similar enough as a change.
+func pairDiffBlocks(removed, added []diffBlock) []DocumentChange {
+	var changes []DocumentChange
+	paired := make([]bool, len(added))
+	next := 0
+	for _, old := range removed {
+		match := -1
+		for k := next; k < len(added); k++ {
+			if added[k].kind == old.kind && wordSimilarity(old.words, added[k].words) >= documentChangeSimilarity {
+				match = k
+				break
+			}
+		}
+		if match < 0 {
+			changes = append(changes, DocumentChange{
+				Type: DocumentChangeRemoved, Kind: old.kind, Section: old.section, Old: old.text, OldPage: old.page,
+			})
+			continue
+		}
+		for k := next; k < match; k++ {
+			paired[k] = true
+			changes = append(changes, addedDocumentChange(added[k]))
+		}
+		paired[match] = true
+		next = match + 1
+		current := added[match]
+		changes = append(changes, DocumentChange{
+			Type: DocumentChangeChanged, Kind: old.kind, Section: current.section,
+			Old: old.text, New: current.text, OldPage: old.page, NewPage: current.page,
+		})
+	}
+	for k, block := range added {
+		if !paired[k] {
+			changes = append(changes, addedDocumentChange(block))
+		}
+	}
+	return changes
+}
+
+func addedDocumentChange(block diffBlock) DocumentChange {
+	return DocumentChange{Type: DocumentChangeAdded, Kind: block.kind, Section: block.section, New: block.text, NewPage: block.page}
+}
+
+// wordSimilarity returns the Dice coefficient of the words of a and b.
+func wordSimilarity(a, b string) float64 {
+	wordsA, wordsB := strings.Fields(a), strings.Fields(b)
+	if len(wordsA)+len(wordsB) == 0 {
+		return 1
+	}
+	counts := make(map[string]int, len(wordsA))
+	for _, word := range wordsA {
+		counts[word]++
+	}
+	common := 0
+	for _, word := range wordsB {
+		if counts[word] > 0 {
+			counts[word]--
+			common++
+		}
+	}
+	return float64(2*common) / float64(len(wordsA)+len(wordsB))
+}
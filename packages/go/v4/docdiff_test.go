@@ -0,0 +1,71 @@
+package kreuzberg
+
+import (
+	"path/filepath"
+	"reflect"
+	"testing"
+)
+
+func TestDiffResults(t *testing.T) {
+	pages := func(starts ...uint64) Metadata {
+		structure := &PageStructure{UnitType: PageUnitTypePage}
+		for i, start := range starts {
+			structure.Boundaries = append(structure.Boundaries, PageBoundary{ByteStart: start, PageNumber: uint64(i + 1)})
+		}
+		return Metadata{PageStructure: structure}
+	}
+	a := &ExtractionResult{
+		Content: "# Terms\n\nThe tenant pays the rent\non the first day of each month.\n\n" +
+			"The deposit is two months of rent.\n\n# Fees\n\n| Item | Fee |\n|---|---|\n| Late | 50 |\n\n" +
+			"# Old section\n\nThis section was dropped.",
+		Metadata: pages(0, 72),
+	}
+	b := &ExtractionResult{
+		Content: "# Terms\n\nThe tenant pays the rent on the first day of each month.\n\n" +
+			"The deposit is three months of rent.\n\nPets are not allowed.\n\n# Fees\n\n" +
+			"| Item | Fee |\n| --- | --- |\n| Late | 75 |",
+		Metadata: pages(0, 110),
+	}
+
+	want := []DocumentChange{
+		{
+			Type: DocumentChangeChanged, Kind: BlockTypeParagraph, Section: "Terms",
+			Old: "The deposit is two months of rent.", New: "The deposit is three months of rent.", OldPage: 1, NewPage: 1,
+		},
+		{Type: DocumentChangeAdded, Kind: BlockTypeParagraph, Section: "Terms", New: "Pets are not allowed.", NewPage: 1},
+		{
+			Type: DocumentChangeChanged, Kind: BlockTypeTable, Section: "Fees",
+			Old: "| Item | Fee |\n|---|---|\n| Late | 50 |", New: "| Item | Fee |\n| --- | --- |\n| Late | 75 |", OldPage: 2, NewPage: 2,
+		},
+		{Type: DocumentChangeRemoved, Kind: BlockTypeHeading, Section: "Old section", Old: "Old section", OldPage: 2},
+		{Type: DocumentChangeRemoved, Kind: BlockTypeParagraph, Section: "Old section", Old: "This section was dropped.", OldPage: 2},
+	}
+	if diff := DiffResults(a, b); !reflect.DeepEqual(diff.Changes, want) {
+		t.Errorf("unexpected changes:\n got  %+v\n want %+v", diff.Changes, want)
+	}
+	if diff := DiffResults(a, a); diff.Changes != nil {
+		t.Errorf("expected no changes, got %+v", diff.Changes)
+	}
+}
+
+func TestDiffResultsRenamedSection(t *testing.T) {
+	a := &ExtractionResult{Content: "# Payment\n\nRent is due monthly."}
+	b := &ExtractionResult{Content: "# Payment terms\n\nRent is due monthly."}
+	want := []DocumentChange{{
+		Type: DocumentChangeChanged, Kind: BlockTypeHeading, Section: "Payment terms",
+		Old: "Payment", New: "Payment terms", OldPage: 1, NewPage: 1,
+	}}
+	if diff := DiffResults(a, b); !reflect.DeepEqual(diff.Changes, want) {
+		t.Errorf("unexpected changes: %+v", diff.Changes)
+	}
+	if diff := DiffResults(nil, &ExtractionResult{Content: "New."}); len(diff.Changes) != 1 || diff.Changes[0].Type != DocumentChangeAdded {
+		t.Errorf("unexpected changes against a nil result: %+v", diff.Changes)
+	}
+}
+
+func TestDiffDocumentsMissingFile(t *testing.T) {
+	missing := filepath.Join(t.TempDir(), "missing.pdf")
+	if _, err := DiffDocuments(missing, missing, nil); err == nil {
+		t.Fatal("expected an error for a missing file")
+	}
+}
@@ -0,0 +1,27 @@
+package kreuzberg
+
+import (
+	"fmt"
+	"strings"
+	"unicode"
+)
+
+// checkEmptyContent returns an *EmptyResultError for a result without text when
+// EmptyContentIsError is set. Whitespace and invisible format characters, such as byte
+// order marks and zero-width spaces, do not count as text.
+func checkEmptyContent(result *ExtractionResult, config *ExtractionConfig) error {
+	if config.EmptyContentIsError == nil || !*config.EmptyContentIsError {
+		return nil
+	}
+	usable := strings.TrimFunc(result.Content, func(r rune) bool {
+		return unicode.IsSpace(r) || unicode.Is(unicode.Cf, r)
+	})
+	if usable != "" {
+		return nil
+	}
+	message := "extraction produced no text"
+	if result.MimeType != "" {
+		message = fmt.Sprintf("extraction of the %s document produced no text", result.MimeType)
+	}
+	return newEmptyResultErrorWithContext(result, message, nil, ErrorCodeParsing, nil)
+}
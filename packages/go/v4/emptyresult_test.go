@@ -0,0 +1,37 @@
+package kreuzberg
+
+import (
+	"errors"
+	"testing"
+)
+
+func TestEmptyContentIsError(t *testing.T) {
+	if err := RegisterExtractor(testExtractorMime, func(data []byte, _ *ExtractionConfig) (*ExtractionResult, error) {
+		return &ExtractionResult{Content: string(data), Success: true}, nil
+	}); err != nil {
+		t.Fatalf("RegisterExtractor failed: %v", err)
+	}
+	t.Cleanup(func() { _ = UnregisterExtractor(testExtractorMime) })
+
+	result, err := ExtractBytesSync([]byte(" \n\t"), testExtractorMime, nil)
+	if err != nil || !result.Success || result.Content != " \n\t" {
+		t.Fatalf("expected an empty successful result by default, got %v, %v", result, err)
+	}
+
+	config := NewExtractionConfig(WithEmptyContentIsError(true))
+	for _, content := range []string{"\n", "  \n\n ", "\ufeff\u200b "} {
+		result, err := ExtractBytesSync([]byte(content), testExtractorMime, config)
+		var emptyErr *EmptyResultError
+		if !errors.As(err, &emptyErr) {
+			t.Fatalf("content %q: expected EmptyResultError, got %v, %v", content, result, err)
+		}
+		if emptyErr.Kind() != ErrorKindEmptyResult || emptyErr.Result() == nil || emptyErr.Result().MimeType != testExtractorMime {
+			t.Fatalf("content %q: unexpected error %v", content, emptyErr)
+		}
+	}
+
+	result, err = ExtractBytesSync([]byte(" text "), testExtractorMime, config)
+	if err != nil || result.Content != " text " {
+		t.Fatalf("expected text to be returned, got %v, %v", result, err)
+	}
+}
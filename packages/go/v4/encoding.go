@@ -0,0 +1,419 @@
+package kreuzberg
+
+import (
+	"bytes"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+	"sync"
+	"unicode"
+	"unicode/utf16"
+	"unicode/utf8"
+
+	"golang.org/x/text/encoding/japanese"
+	"golang.org/x/text/encoding/simplifiedchinese"
+)
+
+// Encodings the binding decodes itself, as reported in Metadata.SourceEncoding.
+const (
+	EncodingUTF8        = "utf-8"
+	EncodingUTF16LE     = "utf-16le"
+	EncodingUTF16BE     = "utf-16be"
+	EncodingWindows1252 = "windows-1252"
+	EncodingLatin1      = "iso-8859-1"
+	EncodingShiftJIS    = "shift_jis"
+	EncodingGBK         = "gbk"
+)
+
+// DecoderFunc converts text in a character encoding to UTF-8. Decoders for encodings the
+// binding does not decode itself, for example the NewDecoder().Bytes methods of the
+// golang.org/x/text/encoding packages, are added with RegisterEncoding.
+type DecoderFunc func(data []byte) ([]byte, error)
+
+// encodingSampleSize bounds the bytes decoded with each candidate encoding when detecting
+// the encoding of a document.
+const encodingSampleSize = 64 << 10
+
+var (
+	encodingsMu sync.RWMutex
+	encodings   = map[string]registeredEncoding{}
+)
+
+type registeredEncoding struct {
+	name   string
+	decode DecoderFunc
+}
+
+// builtinEncodings are the encodings decoded without a registered decoder, by normalized
+// name and alias.
+var builtinEncodings = map[string]string{
+	"utf8":        EncodingUTF8,
+	"utf16le":     EncodingUTF16LE,
+	"utf16be":     EncodingUTF16BE,
+	"windows1252": EncodingWindows1252,
+	"cp1252":      EncodingWindows1252,
+	"iso88591":    EncodingLatin1,
+	"latin1":      EncodingLatin1,
+	"shiftjis":    EncodingShiftJIS,
+	"sjis":        EncodingShiftJIS,
+	"cp932":       EncodingShiftJIS,
+	"gbk":         EncodingGBK,
+	"cp936":       EncodingGBK,
+}
+
+// multiByteDecoders decode the built-in multi-byte encodings, which detection weighs
+// against each other like registered encodings.
+var multiByteDecoders = []registeredEncoding{
+	{name: EncodingShiftJIS, decode: japanese.ShiftJIS.NewDecoder().Bytes},
+	{name: EncodingGBK, decode: simplifiedchinese.GBK.NewDecoder().Bytes},
+}
+
+// normalizeEncodingName folds the case and separators of an encoding name, so that
+// "Shift_JIS" and "shift-jis" are the same encoding.
+func normalizeEncodingName(name string) string {
+	return strings.Map(func(r rune) rune {
+		if r == '-' || r == '_' || r == ' ' {
+			return -1
+		}
+		return unicode.ToLower(r)
+	}, strings.TrimSpace(name))
+}
+
+// RegisterEncoding adds a decoder for the encoding name, which WithSourceEncoding can then
+// select and WithDetectEncoding considers when detecting the encoding of a document. Names
+// are matched ignoring case, hyphens and underscores. Registering an encoding the binding
+// decodes itself, or one that is already registered, returns a PluginError.
+func RegisterEncoding(name string, decode DecoderFunc) error {
+	key := normalizeEncodingName(name)
+	if key == "" {
+		return newValidationErrorWithContext("encoding name cannot be empty", nil, ErrorCodeValidation, nil)
+	}
+	if decode == nil {
+		return newValidationErrorWithContext("encoding decoder cannot be nil", nil, ErrorCodeValidation, nil)
+	}
+	if _, ok := builtinEncodings[key]; ok {
+		return newPluginErrorWithContext(name, fmt.Sprintf("encoding %s is built in", name), nil, ErrorCodePlugin, nil)
+	}
+	encodingsMu.Lock()
+	defer encodingsMu.Unlock()
+	if _, exists := encodings[key]; exists {
+		return newPluginErrorWithContext(name, fmt.Sprintf("a decoder for encoding %s is already registered", name), nil, ErrorCodePlugin, nil)
+	}
+	encodings[key] = registeredEncoding{name: strings.TrimSpace(name), decode: decode}
+	return nil
+}
+
+// UnregisterEncoding removes the decoder registered for the encoding name.
+func UnregisterEncoding(name string) error {
+	key := normalizeEncodingName(name)
+	encodingsMu.Lock()
+	defer encodingsMu.Unlock()
+	if _, exists := encodings[key]; !exists {
+		return newPluginErrorWithContext(name, fmt.Sprintf("no decoder registered for encoding %s", name), nil, ErrorCodePlugin, nil)
+	}
+	delete(encodings, key)
+	return nil
+}
+
+// knownEncoding reports whether name is a built-in or registered encoding.
+func knownEncoding(name string) bool {
+	key := normalizeEncodingName(name)
+	if _, ok := builtinEncodings[key]; ok {
+		return true
+	}
+	encodingsMu.RLock()
+	defer encodingsMu.RUnlock()
+	_, ok := encodings[key]
+	return ok
+}
+
+func validateSourceEncoding(config *ExtractionConfig) error {
+	if config.SourceEncoding != nil && !knownEncoding(*config.SourceEncoding) {
+		return newValidationErrorWithContext(fmt.Sprintf("invalid source encoding: %q (not a built-in or registered encoding)", *config.SourceEncoding), nil, ErrorCodeValidation, nil)
+	}
+	return nil
+}
+
+// isEncodedTextMimeType reports whether documents of mimeType are transcoded by
+// WithDetectEncoding and WithSourceEncoding.
+func isEncodedTextMimeType(mimeType string) bool {
+	switch normalizeMimeType(mimeType) {
+	case "text/plain", "text/csv":
+		return true
+	}
+	return false
+}
+
+func encodingEnabled(config *ExtractionConfig) bool {
+	return config != nil && ((config.DetectEncoding != nil && *config.DetectEncoding) || config.SourceEncoding != nil)
+}
+
+// transcodeText converts plain text and CSV data to UTF-8 when encoding detection is
+// enabled or a source encoding is set, returning the encoding the data was read in. Other
+// data is returned unchanged with an empty encoding.
+func transcodeText(data []byte, mimeType string, config *ExtractionConfig) ([]byte, string, error) {
+	if !encodingEnabled(config) || !isEncodedTextMimeType(mimeType) {
+		return data, "", nil
+	}
+	if err := validateSourceEncoding(config); err != nil {
+		return nil, "", err
+	}
+	name := ""
+	if config.SourceEncoding != nil {
+		name = *config.SourceEncoding
+		// A byte order mark still tells the UTF encodings apart.
+		if bom, ok := bomEncoding(data); ok && strings.HasPrefix(normalizeEncodingName(name), "utf") {
+			name = bom
+		}
+	} else {
+		name = detectEncoding(data)
+	}
+	decoded, name, err := decodeText(data, name)
+	if err != nil {
+		return nil, "", newParsingErrorWithContext(fmt.Sprintf("failed to decode text as %s", name), err, ErrorCodeParsing, nil)
+	}
+	return decoded, name, nil
+}
+
+// transcodeBatchItems converts the plain text and CSV items of a batch to UTF-8 like
+// transcodeText, returning the encoding of each item.
+func transcodeBatchItems(items []BytesWithMime, config *ExtractionConfig) ([]BytesWithMime, []string, error) {
+	if !encodingEnabled(config) {
+		return items, nil, nil
+	}
+	var out []BytesWithMime
+	var names []string
+	for i, item := range items {
+		data, name, err := transcodeText(item.Data, item.MimeType, config)
+		if err != nil {
+			return nil, nil, err
+		}
+		if name == "" {
+			continue
+		}
+		if out == nil {
+			out = append([]BytesWithMime(nil), items...)
+			names = make([]string, len(items))
+		}
+		out[i] = BytesWithMime{Data: data, MimeType: item.MimeType}
+		names[i] = name
+	}
+	if out == nil {
+		return items, nil, nil
+	}
+	return out, names, nil
+}
+
+// readEncodedTextFile reads the file at path when its extension marks it as plain text or
+// CSV and its encoding is to be converted, returning its MIME type.
+func readEncodedTextFile(path string, config *ExtractionConfig) ([]byte, string, bool, error) {
+	if !encodingEnabled(config) {
+		return nil, "", false, nil
+	}
+	var mimeType string
+	switch strings.ToLower(filepath.Ext(path)) {
+	case ".txt", ".text", ".log":
+		mimeType = "text/plain"
+	case ".csv":
+		mimeType = "text/csv"
+	default:
+		return nil, "", false, nil
+	}
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, "", false, newIOErrorWithContext(fmt.Sprintf("failed to read %s", path), err, ErrorCodeIo, nil)
+	}
+	return data, mimeType, true, nil
+}
+
+// bomEncoding returns the UTF encoding named by a byte order mark at the start of data.
+func bomEncoding(data []byte) (string, bool) {
+	switch {
+	case bytes.HasPrefix(data, []byte{0xEF, 0xBB, 0xBF}):
+		return EncodingUTF8, true
+	case bytes.HasPrefix(data, []byte{0xFF, 0xFE}):
+		return EncodingUTF16LE, true
+	case bytes.HasPrefix(data, []byte{0xFE, 0xFF}):
+		return EncodingUTF16BE, true
+	}
+	return "", false
+}
+
+// detectEncoding guesses the encoding of data: from a byte order mark, as UTF-16 when every
+// other byte is mostly zero, as UTF-8 when data is valid UTF-8, and otherwise as the
+// Shift_JIS, GBK, registered encoding or Windows-1252, whichever decodes the start of data
+// into the most plausible text.
+func detectEncoding(data []byte) string {
+	if name, ok := bomEncoding(data); ok {
+		return name
+	}
+	sample := data[:min(len(data), encodingSampleSize)]
+	// UTF-16 text in ASCII is also valid UTF-8, full of NUL characters.
+	if name, ok := detectUTF16(sample); ok {
+		return name
+	}
+	if utf8.Valid(sample) || (len(sample) < len(data) && utf8.Valid(sample[:max(0, len(sample)-utf8.UTFMax)])) {
+		return EncodingUTF8
+	}
+
+	best, bestScore := EncodingWindows1252, textPlausibility(decodeWindows1252(sample))
+	encodingsMu.RLock()
+	candidates := append(make([]registeredEncoding, 0, len(multiByteDecoders)+len(encodings)), multiByteDecoders...)
+	for _, encoding := range encodings {
+		candidates = append(candidates, encoding)
+	}
+	encodingsMu.RUnlock()
+	for _, encoding := range candidates {
+		decoded, err := encoding.decode(sample)
+		if err != nil {
+			continue
+		}
+		if score := textPlausibility(string(decoded)); score > bestScore || (score == bestScore && encoding.name < best) {
+			best, bestScore = encoding.name, score
+		}
+	}
+	return best
+}
+
+// detectUTF16 recognizes UTF-16 text without a byte order mark by the zero high bytes of
+// its ASCII characters.
+func detectUTF16(sample []byte) (string, bool) {
+	if len(sample) < 4 {
+		return "", false
+	}
+	var zeros [2]int
+	for i, b := range sample {
+		if b == 0 {
+			zeros[i%2]++
+		}
+	}
+	half := len(sample) / 2
+	switch {
+	case zeros[1] > half*3/10 && zeros[0] <= half/20:
+		return EncodingUTF16LE, true
+	case zeros[0] > half*3/10 && zeros[1] <= half/20:
+		return EncodingUTF16BE, true
+	}
+	return "", false
+}
+
+// decodeText converts data in the encoding name to UTF-8, dropping a byte order mark, and
+// returns the name of the encoding as reported in Metadata.SourceEncoding.
+func decodeText(data []byte, name string) ([]byte, string, error) {
+	key := normalizeEncodingName(name)
+	switch builtinEncodings[key] {
+	case EncodingUTF8:
+		data = bytes.TrimPrefix(data, []byte{0xEF, 0xBB, 0xBF})
+		return bytes.ToValidUTF8(data, []byte("�")), EncodingUTF8, nil
+	case EncodingUTF16LE:
+		return decodeUTF16(bytes.TrimPrefix(data, []byte{0xFF, 0xFE}), false), EncodingUTF16LE, nil
+	case EncodingUTF16BE:
+		return decodeUTF16(bytes.TrimPrefix(data, []byte{0xFE, 0xFF}), true), EncodingUTF16BE, nil
+	case EncodingWindows1252:
+		return []byte(decodeWindows1252(data)), EncodingWindows1252, nil
+	case EncodingLatin1:
+		runes := make([]rune, len(data))
+		for i, b := range data {
+			runes[i] = rune(b)
+		}
+		return []byte(string(runes)), EncodingLatin1, nil
+	}
+	for _, encoding := range multiByteDecoders {
+		if encoding.name == builtinEncodings[key] {
+			decoded, err := encoding.decode(data)
+			return decoded, encoding.name, err
+		}
+	}
+	encodingsMu.RLock()
+	encoding, ok := encodings[key]
+	encodingsMu.RUnlock()
+	if !ok {
+		return nil, name, fmt.Errorf("unknown encoding %q", name)
+	}
+	decoded, err := encoding.decode(data)
+	return decoded, encoding.name, err
+}
+
+func decodeUTF16(data []byte, bigEndian bool) []byte {
+	units := make([]uint16, len(data)/2)
+	for i := range units {
+		if bigEndian {
+			units[i] = uint16(data[2*i])<<8 | uint16(data[2*i+1])
+		} else {
+			units[i] = uint16(data[2*i+1])<<8 | uint16(data[2*i])
+		}
+	}
+	return []byte(string(utf16.Decode(units)))
+}
+
+// windows1252High maps the bytes 0x80 to 0x9F of Windows-1252; the five bytes the code
+// page leaves undefined map to the C1 control with their value, as in ISO-8859-1.
+var windows1252High = [32]rune{
+	0x20AC, 0x81, 0x201A, 0x0192, 0x201E, 0x2026, 0x2020, 0x2021,
+	0x02C6, 0x2030, 0x0160, 0x2039, 0x0152, 0x8D, 0x017D, 0x8F,
+	0x90, 0x2018, 0x2019, 0x201C, 0x201D, 0x2022, 0x2013, 0x2014,
+	0x02DC, 0x2122, 0x0161, 0x203A, 0x0153, 0x9D, 0x017E, 0x0178,
+}
+
+func decodeWindows1252(data []byte) string {
+	var b strings.Builder
+	b.Grow(len(data))
+	for _, c := range data {
+		if c >= 0x80 && c < 0xA0 {
+			b.WriteRune(windows1252High[c-0x80])
+		} else {
+			b.WriteRune(rune(c))
+		}
+	}
+	return b.String()
+}
+
+// textPlausibility scores how much decoded text looks like natural language, from the
+// characters it is made of: letters and digits of any script count for it, with kana and
+// Hangul, which rarely come out of a wrong decoding, counting double; replacement and
+// control characters count heavily against it, and so do Han ideographs next to a Latin
+// letter, which is what a multi-byte lead byte swallowing that letter turns into.
+func textPlausibility(text string) float64 {
+	score, count := 0.0, 0
+	prev := rune(0)
+	for _, r := range text {
+		count++
+		switch {
+		case r == utf8.RuneError:
+			score -= 10
+		case r == '\n' || r == '\r' || r == '\t':
+			score++
+		case unicode.IsControl(r):
+			score -= 5
+		case r < 0x80:
+			if isASCIILetter(r) && unicode.Is(unicode.Han, prev) {
+				score--
+			} else {
+				score++
+			}
+		case unicode.In(r, unicode.Hiragana, unicode.Katakana, unicode.Hangul):
+			if r >= 0xFF61 && r <= 0xFF9F {
+				// Half-width katakana are what Latin text decoded as Shift_JIS turns into.
+				score -= 1
+			} else {
+				score += 2
+			}
+		case unicode.Is(unicode.Han, r) && isASCIILetter(prev):
+			score--
+		case unicode.IsLetter(r) || unicode.IsDigit(r):
+			score += 0.5
+		default:
+			score -= 0.5
+		}
+		prev = r
+	}
+	if count == 0 {
+		return 0
+	}
+	return score / float64(count)
+}
+
+func isASCIILetter(r rune) bool {
+	return r >= 'a' && r <= 'z' || r >= 'A' && r <= 'Z'
+}
@@ -0,0 +1,151 @@
+package kreuzberg
+
+import (
+	"errors"
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+func TestDetectEncodingTranscodesLegacyText(t *testing.T) {
+	config := NewExtractionConfig(WithDetectEncoding(true))
+	tests := []struct {
+		name     string
+		data     []byte
+		want     string
+		encoding string
+	}{
+		{"utf-8", []byte("naïve café"), "naïve café", EncodingUTF8},
+		{"utf-8 bom", []byte("\xEF\xBB\xBFhello"), "hello", EncodingUTF8},
+		{"windows-1252", []byte("\x93Caf\xe9\x94 costs \x8010"), "“Café” costs €10", EncodingWindows1252},
+		{"utf-16le bom", []byte("\xFF\xFEh\x00\xe9\x00"), "hé", EncodingUTF16LE},
+		{"utf-16be bom", []byte("\xFE\xFF\x00h\x00\xe9"), "hé", EncodingUTF16BE},
+		{"utf-16le", []byte("a\x00,\x00b\x00\n\x001\x00,\x002\x00"), "a,b\n1,2", EncodingUTF16LE},
+		{"shift_jis", []byte("\x93\xfa\x96{\x8c\xea\x82\xcc\x83e\x83L\x83X\x83g\x82\xc5\x82\xb7\x81B"), "日本語のテキストです。", EncodingShiftJIS},
+		{"gbk", []byte("\xd5\xe2\xca\xc7\xd6\xd0\xce\xc4\xce\xc4\xb1\xbe\xa3\xac\xb1\xe0\xc2\xeb\xce\xaaGBK\xa1\xa3"), "这是中文文本，编码为GBK。", EncodingGBK},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, encoding, err := transcodeText(tt.data, "text/csv", config)
+			if err != nil {
+				t.Fatalf("transcodeText failed: %v", err)
+			}
+			if string(got) != tt.want || encoding != tt.encoding {
+				t.Fatalf("got %q as %q, want %q as %q", got, encoding, tt.want, tt.encoding)
+			}
+		})
+	}
+
+	data := []byte("caf\xe9")
+	if got, encoding, _ := transcodeText(data, "application/pdf", config); string(got) != string(data) || encoding != "" {
+		t.Fatalf("non-text data was transcoded: %q, %q", got, encoding)
+	}
+	if got, encoding, _ := transcodeText(data, "text/plain", nil); string(got) != string(data) || encoding != "" {
+		t.Fatalf("data was transcoded without the option: %q, %q", got, encoding)
+	}
+}
+
+func TestSourceEncodingOverridesDetection(t *testing.T) {
+	data := []byte("\x80 \xe9")
+	got, encoding, err := transcodeText(data, "text/plain", NewExtractionConfig(WithSourceEncoding("Latin-1")))
+	if err != nil {
+		t.Fatalf("transcodeText failed: %v", err)
+	}
+	if string(got) != "\u0080 é" || encoding != EncodingLatin1 {
+		t.Fatalf("got %q as %q", got, encoding)
+	}
+
+	var valErr *ValidationError
+	config := NewExtractionConfig(WithSourceEncoding("ebcdic"))
+	if _, _, err := transcodeText(data, "text/plain", config); !errors.As(err, &valErr) {
+		t.Fatalf("expected ValidationError for an unknown encoding, got %v", err)
+	}
+	if err := validateConfig(config); !errors.As(err, &valErr) {
+		t.Fatalf("expected validateConfig to reject an unknown encoding, got %v", err)
+	}
+}
+
+// decodeTestKana is a stand-in for a multi-byte decoder: each byte pair 0x82 0x9F+n is the
+// hiragana U+3041+n, as in Shift_JIS, and other bytes are ASCII or invalid.
+func decodeTestKana(data []byte) ([]byte, error) {
+	var runes []rune
+	for i := 0; i < len(data); i++ {
+		switch {
+		case data[i] < 0x80:
+			runes = append(runes, rune(data[i]))
+		case data[i] == 0x82 && i+1 < len(data) && data[i+1] >= 0x9F && data[i+1] <= 0xF1:
+			runes = append(runes, rune(0x3041+int(data[i+1])-0x9F))
+			i++
+		default:
+			runes = append(runes, '�')
+		}
+	}
+	return []byte(string(runes)), nil
+}
+
+func TestRegisterEncodingDetection(t *testing.T) {
+	if err := RegisterEncoding("X_Test_Kana", decodeTestKana); err != nil {
+		t.Fatalf("RegisterEncoding failed: %v", err)
+	}
+	t.Cleanup(func() { _ = UnregisterEncoding("x-test-kana") })
+
+	var pluginErr *PluginError
+	if err := RegisterEncoding("x-test-kana", decodeTestKana); !errors.As(err, &pluginErr) {
+		t.Fatalf("expected PluginError for a duplicate encoding, got %v", err)
+	}
+	if err := RegisterEncoding("CP1252", decodeTestKana); !errors.As(err, &pluginErr) {
+		t.Fatalf("expected PluginError for a built-in encoding, got %v", err)
+	}
+
+	// こんにちは
+	data := []byte("\x82\xb1\x82\xf1\x82\xc9\x82\xbf\x82\xcd")
+	got, encoding, err := transcodeText(data, "text/plain", NewExtractionConfig(WithDetectEncoding(true)))
+	if err != nil {
+		t.Fatalf("transcodeText failed: %v", err)
+	}
+	if string(got) != "こんにちは" || encoding != "X_Test_Kana" {
+		t.Fatalf("got %q as %q", got, encoding)
+	}
+
+	// Latin text is not mistaken for the registered encoding.
+	if encoding := detectEncoding([]byte("r\xe9sum\xe9 na\xefve")); encoding != EncodingWindows1252 {
+		t.Fatalf("Latin text detected as %q", encoding)
+	}
+}
+
+func TestDetectEncodingExtraction(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "notes.txt")
+	if err := os.WriteFile(path, []byte("Gr\xfc\xdfe"), 0o600); err != nil {
+		t.Fatal(err)
+	}
+	result, err := ExtractFileSync(path, NewExtractionConfig(WithDetectEncoding(true)))
+	if err != nil {
+		t.Fatalf("ExtractFileSync failed: %v", err)
+	}
+	if result.Content != "Grüße" || result.Metadata.SourceEncoding == nil || *result.Metadata.SourceEncoding != EncodingWindows1252 {
+		t.Fatalf("got %q, encoding %v", result.Content, result.Metadata.SourceEncoding)
+	}
+
+	// こんにちは in Shift_JIS
+	sjisPath := filepath.Join(t.TempDir(), "greeting.txt")
+	if err := os.WriteFile(sjisPath, []byte("\x82\xb1\x82\xf1\x82\xc9\x82\xbf\x82\xcd"), 0o600); err != nil {
+		t.Fatal(err)
+	}
+	results, err := BatchExtractFilesSync([]string{path, sjisPath}, NewExtractionConfig(WithDetectEncoding(true)))
+	if err != nil {
+		t.Fatalf("BatchExtractFilesSync failed: %v", err)
+	}
+	if results[0].Content != "Grüße" || results[1].Content != "こんにちは" ||
+		results[1].Metadata.SourceEncoding == nil || *results[1].Metadata.SourceEncoding != EncodingShiftJIS {
+		t.Fatalf("batch files not transcoded: %q, %q", results[0].Content, results[1].Content)
+	}
+
+	results, err = BatchExtractBytesSync([]BytesWithMime{{Data: []byte("\xa35"), MimeType: "text/plain"}},
+		NewExtractionConfig(WithSourceEncoding(EncodingLatin1)))
+	if err != nil {
+		t.Fatalf("BatchExtractBytesSync failed: %v", err)
+	}
+	if results[0].Content != "£5" || *results[0].Metadata.SourceEncoding != EncodingLatin1 {
+		t.Fatalf("got %q, encoding %v", results[0].Content, results[0].Metadata.SourceEncoding)
+	}
+}
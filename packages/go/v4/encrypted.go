@@ -0,0 +1,113 @@
+package kreuzberg
+
+import (
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"strings"
+)
+
+// openEncryptedOffice decrypts data when it is a password-protected DOCX, XLSX or PPTX
+// package, trying each of config.DocumentPasswords. It returns the decrypted package and
+// its MIME type, or false when data is not an encrypted Office document. format names the
+// expected format in errors when the package cannot be decrypted.
+func openEncryptedOffice(data []byte, format string, config *ExtractionConfig) ([]byte, string, bool, error) {
+	if !isCFB(data) {
+		return nil, "", false, nil
+	}
+	container, err := parseCFB(data)
+	if err != nil || !isEncryptedOffice(container) {
+		// Legacy Office documents share the container format; leave them to the native library.
+		return nil, "", false, nil
+	}
+
+	var passwords []string
+	if config != nil {
+		passwords = config.DocumentPasswords
+	}
+	pkg, err := decryptOfficePackage(container, passwords)
+	switch {
+	case errors.Is(err, errOfficePasswordRejected):
+		if len(passwords) == 0 {
+			return nil, "", true, newEncryptedDocumentErrorWithContext(format, false, fmt.Sprintf("%s document is password-protected", format), nil, ErrorCodeParsing, nil)
+		}
+		return nil, "", true, newEncryptedDocumentErrorWithContext(format, true, fmt.Sprintf("none of the supplied passwords opens the %s document", format), err, ErrorCodeParsing, nil)
+	case errors.Is(err, errOfficeUnsupported):
+		return nil, "", true, newUnsupportedFormatErrorWithContext(format, fmt.Sprintf("unsupported encryption in %s document", format), err, ErrorCodeUnsupportedFormat, nil)
+	case err != nil:
+		return nil, "", true, newParsingErrorWithContext(fmt.Sprintf("corrupt encrypted %s document", format), err, ErrorCodeParsing, nil)
+	}
+
+	mimeType, ok := officePackageMimeType(pkg)
+	if !ok {
+		return nil, "", true, newParsingErrorWithContext(fmt.Sprintf("decrypted %s document is not an Office Open XML package", format), nil, ErrorCodeParsing, nil)
+	}
+	return pkg, mimeType, true, nil
+}
+
+// openEncryptedOfficeFile is openEncryptedOffice for a file on disk. Only files that start
+// with the compound file signature are read in full.
+func openEncryptedOfficeFile(path string, config *ExtractionConfig) ([]byte, string, bool, error) {
+	// #nosec G304 -- path is the document the caller asked us to extract
+	file, err := os.Open(path)
+	if err != nil {
+		// Reported by the native extraction.
+		return nil, "", false, nil
+	}
+	header := make([]byte, len(cfbSignature))
+	_, err = io.ReadFull(file, header)
+	_ = file.Close()
+	if err != nil || !isCFB(header) {
+		return nil, "", false, nil
+	}
+
+	// #nosec G304 -- path is the document the caller asked us to extract
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, "", false, newIOErrorWithContext("failed to read source document", err, ErrorCodeIo, nil)
+	}
+	return openEncryptedOffice(data, officeFormatName("", path), config)
+}
+
+// officeFormatName returns the short format name ("docx", "xlsx", "pptx") implied by a
+// MIME type or file extension, or "office" when neither identifies one.
+func officeFormatName(mimeType, path string) string {
+	switch normalizeMimeType(mimeType) {
+	case mimeTypeDOCX:
+		return "docx"
+	case mimeTypeXLSX:
+		return "xlsx"
+	case mimeTypePPTX:
+		return "pptx"
+	}
+	switch ext := strings.ToLower(strings.TrimPrefix(filepath.Ext(path), ".")); ext {
+	case "docx", "docm", "xlsx", "xlsm", "pptx", "pptm":
+		return ext
+	}
+	return "office"
+}
+
+// decryptBatchItems replaces encrypted Office documents in items with their decrypted
+// packages. The input slice is not modified.
+func decryptBatchItems(items []BytesWithMime, config *ExtractionConfig) ([]BytesWithMime, error) {
+	var out []BytesWithMime
+	for i, item := range items {
+		data, mimeType, ok, err := openEncryptedOffice(item.Data, officeFormatName(item.MimeType, ""), config)
+		if err != nil {
+			return nil, err
+		}
+		if !ok {
+			continue
+		}
+		if out == nil {
+			out = append([]BytesWithMime(nil), items...)
+		}
+		out[i] = BytesWithMime{Data: data, MimeType: mimeType}
+	}
+	if out == nil {
+		return items, nil
+	}
+	return out, nil
+}
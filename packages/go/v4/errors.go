@@ -31,6 +31,8 @@ const (
 	ErrorKindPlugin            ErrorKind = "plugin"
 	ErrorKindUnsupportedFormat ErrorKind = "unsupported_format"
 	ErrorKindRuntime           ErrorKind = "runtime"
+	ErrorKindEncryptedDocument ErrorKind = "encrypted_document"
+	ErrorKindEmptyResult       ErrorKind = "empty_result"
 )
 
 // ErrorCode represents FFI error codes from kreuzberg-ffi.
@@ -158,6 +160,38 @@ type UnsupportedFormatError struct {
 	Format string
 }
 
+// EncryptedDocumentError reports a password-protected document that could not be opened,
+// either because no password was supplied or because none of the supplied passwords is
+// correct. It is distinct from ParsingError, which signals a corrupt document.
+type EncryptedDocumentError struct {
+	baseError
+	format           string
+	passwordRejected bool
+}
+
+// Format returns the format of the encrypted document, such as "pdf" or "docx".
+func (e *EncryptedDocumentError) Format() string {
+	return e.format
+}
+
+// PasswordRejected reports whether passwords were supplied but none of them was correct.
+func (e *EncryptedDocumentError) PasswordRejected() bool {
+	return e.passwordRejected
+}
+
+// EmptyResultError reports an extraction that produced no text while
+// ExtractionConfig.EmptyContentIsError is set.
+type EmptyResultError struct {
+	baseError
+	result *ExtractionResult
+}
+
+// Result returns the empty result, whose metadata and warnings may tell why nothing was
+// extracted.
+func (e *EmptyResultError) Result() *ExtractionResult {
+	return e.result
+}
+
 type IOError struct {
 	baseError
 }
@@ -227,6 +261,21 @@ func newUnsupportedFormatErrorWithContext(format string, message string, cause e
 	}
 }
 
+func newEncryptedDocumentErrorWithContext(format string, passwordRejected bool, message string, cause error, code ErrorCode, panicCtx *PanicContext) *EncryptedDocumentError {
+	return &EncryptedDocumentError{
+		baseError:        makeBaseError(ErrorKindEncryptedDocument, messageWithFallback(message, fmt.Sprintf("Encrypted document: %s", format)), cause, code, panicCtx),
+		format:           format,
+		passwordRejected: passwordRejected,
+	}
+}
+
+func newEmptyResultErrorWithContext(result *ExtractionResult, message string, cause error, code ErrorCode, panicCtx *PanicContext) *EmptyResultError {
+	return &EmptyResultError{
+		baseError: makeBaseError(ErrorKindEmptyResult, messageWithFallback(message, "extraction produced no text"), cause, code, panicCtx),
+		result:    result,
+	}
+}
+
 func newIOErrorWithContext(message string, cause error, code ErrorCode, panicCtx *PanicContext) *IOError {
 	return &IOError{baseError: makeBaseError(ErrorKindIO, message, cause, code, panicCtx)}
 }
@@ -270,6 +319,14 @@ func classifyNativeError(message string, code ErrorCode, panicCtx *PanicContext)
 		trimmed = "unknown error"
 	}
 
+	// PDF password failures arrive as parsing errors; surface them as encrypted documents.
+	switch {
+	case strings.Contains(trimmed, "PDF is password-protected"):
+		return newEncryptedDocumentErrorWithContext("pdf", false, trimmed, nil, code, panicCtx)
+	case strings.Contains(trimmed, "Invalid password provided"):
+		return newEncryptedDocumentErrorWithContext("pdf", true, trimmed, nil, code, panicCtx)
+	}
+
 	switch code {
 	case ErrorCodeValidation:
 		return newValidationErrorWithContext(trimmed, nil, code, panicCtx)
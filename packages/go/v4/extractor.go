@@ -0,0 +1,226 @@
+package kreuzberg
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"sync"
+)
+
+// Extractor extracts single documents, with or without a context. Code that depends on
+// Extractor instead of calling the package functions can be tested with a fake, such as the
+// one in package kreuzbergtest, without documents on disk or the native library. The
+// extractor returned by NewExtractor implements it.
+type Extractor interface {
+	ExtractFile(path string) (*ExtractionResult, error)
+	ExtractBytes(data []byte, mimeType string) (*ExtractionResult, error)
+	ExtractFileWithContext(ctx context.Context, path string) (*ExtractionResult, error)
+	ExtractBytesWithContext(ctx context.Context, data []byte, mimeType string) (*ExtractionResult, error)
+}
+
+var _ Extractor = (*ConfiguredExtractor)(nil)
+
+// ConfiguredExtractor is a long-lived extraction handle with a fixed configuration. The
+// configuration is validated and encoded once in NewExtractor instead of on every call,
+// which matters for large batches.
+//
+// A ConfiguredExtractor is safe for concurrent use by multiple goroutines. The binding runs one
+// native extraction at a time in the whole process, whatever the number of extractors or
+// workers, so batch throughput is bounded by a single native extraction. The worker pool of
+// BatchFiles and BatchBytes only overlaps file reading, result decoding and Go-side
+// post-processing with the native extraction of another document.
+type ConfiguredExtractor struct {
+	config      *ExtractionConfig
+	workers     int
+	stopOnError bool
+}
+
+// ExtractorOption configures a ConfiguredExtractor.
+type ExtractorOption func(*ConfiguredExtractor)
+
+// defaultExtractorWorkers is enough workers to prepare or post-process one document while
+// another is in the native library. Native calls are serialized by ffiMutex, so the second
+// worker only overlaps that Go-side work, never a second native extraction.
+const defaultExtractorWorkers = 2
+
+// WithWorkers bounds the number of documents a ConfiguredExtractor processes concurrently
+// in batch calls. Values below 1 are ignored. The default is 2: native extractions do not
+// run in parallel (see ConfiguredExtractor), so the second worker only overlaps reading and
+// post-processing in Go with the native extraction of another document. More workers only
+// help when that Go-side work, such as reading files from slow storage, takes about as long
+// as the native extraction; extra workers otherwise wait for the native library while
+// holding their document in memory.
+func WithWorkers(n int) ExtractorOption {
+	return func(e *ConfiguredExtractor) {
+		if n > 0 {
+			e.workers = n
+		}
+	}
+}
+
+// WithStopOnFirstError makes BatchFiles and BatchBytes stop starting documents once one
+// fails, for jobs that should fail fast. Documents already being extracted finish, and
+// documents that were not started report ErrBatchStopped. With more than one worker the
+// failure that stopped the batch need not be the first in input order, which is the one
+// FirstBatchError returns. By default every document is extracted regardless of failures
+// and each reports its own outcome.
+func WithStopOnFirstError(enabled bool) ExtractorOption {
+	return func(e *ConfiguredExtractor) {
+		e.stopOnError = enabled
+	}
+}
+
+// ErrBatchStopped is the error of documents skipped because an earlier document of the
+// batch failed under WithStopOnFirstError.
+var ErrBatchStopped = errors.New("kreuzberg: batch stopped after a document failed")
+
+// BatchResult is the outcome of one document in a ConfiguredExtractor batch. Index is the position
+// of the document in the input slice and Path its path for BatchFiles; exactly one of
+// Result and Err is set.
+type BatchResult struct {
+	Index  int
+	Path   string
+	Result *ExtractionResult
+	Err    error
+}
+
+// BatchError identifies the document of a batch that failed.
+type BatchError struct {
+	Index int
+	// Path is empty for BatchBytes.
+	Path string
+	Err  error
+}
+
+func (e *BatchError) Error() string {
+	if e.Path != "" {
+		return fmt.Sprintf("document %d (%s): %v", e.Index, e.Path, e.Err)
+	}
+	return fmt.Sprintf("document %d: %v", e.Index, e.Err)
+}
+
+func (e *BatchError) Unwrap() error {
+	return e.Err
+}
+
+// FirstBatchError returns the failure of the first document of results, in input order,
+// that failed, as a *BatchError, or nil when no document failed. A batch runs to the end
+// unless WithStopOnFirstError is set, so later documents may have failed too. Documents
+// skipped with ErrBatchStopped are not counted as failures.
+func FirstBatchError(results []BatchResult) error {
+	for _, res := range results {
+		if res.Err != nil && !errors.Is(res.Err, ErrBatchStopped) {
+			return &BatchError{Index: res.Index, Path: res.Path, Err: res.Err}
+		}
+	}
+	return nil
+}
+
+// NewExtractor returns a ConfiguredExtractor using a copy of config. A nil config selects
+// the default registered with SetDefaultConfig when each document is extracted, or the
+// library defaults. Later changes to config do not affect the extractor.
+func NewExtractor(config *ExtractionConfig, opts ...ExtractorOption) (*ConfiguredExtractor, error) {
+	e := &ConfiguredExtractor{workers: defaultExtractorWorkers}
+	for _, opt := range opts {
+		opt(e)
+	}
+	if config == nil {
+		return e, nil
+	}
+
+	if err := validateConfig(config); err != nil {
+		return nil, err
+	}
+	cfg := *config
+	cfg.encoded = nil
+	encoded, err := encodeConfig(&cfg)
+	if err != nil {
+		return nil, newSerializationErrorWithContext("failed to encode config", err, ErrorCodeValidation, nil)
+	}
+	cfg.encoded = encoded
+	e.config = &cfg
+	return e, nil
+}
+
+// ExtractFile extracts the file at path.
+func (e *ConfiguredExtractor) ExtractFile(path string) (*ExtractionResult, error) {
+	return extractFile(context.Background(), path, e.config)
+}
+
+// ExtractBytes extracts an in-memory document of the given MIME type.
+func (e *ConfiguredExtractor) ExtractBytes(data []byte, mimeType string) (*ExtractionResult, error) {
+	return extractBytes(context.Background(), data, mimeType, e.config)
+}
+
+// ExtractFileWithContext extracts the file at path. See the package function
+// ExtractFileWithContext for the cancellation semantics.
+func (e *ConfiguredExtractor) ExtractFileWithContext(ctx context.Context, path string) (*ExtractionResult, error) {
+	return runWithContext(ctx, func() (*ExtractionResult, error) {
+		return extractFile(ctx, path, e.config)
+	})
+}
+
+// ExtractBytesWithContext extracts an in-memory document of the given MIME type. See the
+// package function ExtractFileWithContext for the cancellation semantics.
+func (e *ConfiguredExtractor) ExtractBytesWithContext(ctx context.Context, data []byte, mimeType string) (*ExtractionResult, error) {
+	return runWithContext(ctx, func() (*ExtractionResult, error) {
+		return extractBytes(ctx, data, mimeType, e.config)
+	})
+}
+
+// BatchFiles extracts every path on a bounded worker pool and returns one BatchResult per
+// path, in input order. A failing document does not stop the batch unless the extractor was
+// created with WithStopOnFirstError; documents not started before ctx ends report ctx.Err().
+func (e *ConfiguredExtractor) BatchFiles(ctx context.Context, paths []string) []BatchResult {
+	return e.batch(ctx, len(paths), paths, func(i int) (*ExtractionResult, error) {
+		return extractFile(ctx, paths[i], e.config)
+	})
+}
+
+// BatchBytes extracts every in-memory document like BatchFiles.
+func (e *ConfiguredExtractor) BatchBytes(ctx context.Context, items []BytesWithMime) []BatchResult {
+	return e.batch(ctx, len(items), nil, func(i int) (*ExtractionResult, error) {
+		return extractBytes(ctx, items[i].Data, items[i].MimeType, e.config)
+	})
+}
+
+func (e *ConfiguredExtractor) batch(ctx context.Context, n int, paths []string, extract func(i int) (*ExtractionResult, error)) []BatchResult {
+	results := make([]BatchResult, n)
+	// stop is closed by the first failure when stopOnError is set.
+	stop := make(chan struct{})
+	var stopOnce sync.Once
+	indices := make(chan int)
+	var wg sync.WaitGroup
+	for range min(e.workers, n) {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for i := range indices {
+				results[i].Index = i
+				if paths != nil {
+					results[i].Path = paths[i]
+				}
+				if err := ctx.Err(); err != nil {
+					results[i].Err = err
+					continue
+				}
+				select {
+				case <-stop:
+					results[i].Err = ErrBatchStopped
+					continue
+				default:
+				}
+				results[i].Result, results[i].Err = extract(i)
+				if results[i].Err != nil && e.stopOnError {
+					stopOnce.Do(func() { close(stop) })
+				}
+			}
+		}()
+	}
+	for i := range n {
+		indices <- i
+	}
+	close(indices)
+	wg.Wait()
+	return results
+}
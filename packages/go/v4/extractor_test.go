@@ -0,0 +1,236 @@
+package kreuzberg
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"testing"
+)
+
+func TestExtractorBatchBytesPreservesOrder(t *testing.T) {
+	registerTestExtractor(t)
+
+	extractor, err := NewExtractor(NewExtractionConfig(WithUseCache(false)), WithWorkers(3))
+	if err != nil {
+		t.Fatalf("NewExtractor failed: %v", err)
+	}
+
+	items := make([]BytesWithMime, 10)
+	for i := range items {
+		items[i] = BytesWithMime{Data: []byte(fmt.Sprintf("doc%d", i)), MimeType: testExtractorMime}
+	}
+	items[4].Data = nil
+
+	results := extractor.BatchBytes(context.Background(), items)
+	if len(results) != len(items) {
+		t.Fatalf("expected %d results, got %d", len(items), len(results))
+	}
+	for i, res := range results {
+		if res.Index != i {
+			t.Errorf("result %d has index %d", i, res.Index)
+		}
+		if i == 4 {
+			var validationErr *ValidationError
+			if !errors.As(res.Err, &validationErr) {
+				t.Errorf("expected ValidationError for empty item, got %v", res.Err)
+			}
+			continue
+		}
+		if res.Err != nil || res.Result == nil || res.Result.Content != fmt.Sprintf("DOC%d", i) {
+			t.Errorf("unexpected result %d: %+v", i, res)
+		}
+	}
+}
+
+func TestExtractorBatchCanceled(t *testing.T) {
+	registerTestExtractor(t)
+
+	extractor, err := NewExtractor(nil)
+	if err != nil {
+		t.Fatalf("NewExtractor failed: %v", err)
+	}
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+
+	results := extractor.BatchBytes(ctx, []BytesWithMime{{Data: []byte("a"), MimeType: testExtractorMime}})
+	if len(results) != 1 || !errors.Is(results[0].Err, context.Canceled) {
+		t.Fatalf("expected canceled result, got %+v", results)
+	}
+}
+
+func TestExtractorStopOnFirstError(t *testing.T) {
+	registerTestExtractor(t)
+
+	items := make([]BytesWithMime, 6)
+	for i := range items {
+		items[i] = BytesWithMime{Data: []byte(fmt.Sprintf("doc%d", i)), MimeType: testExtractorMime}
+	}
+	items[2].Data = nil
+
+	for _, stop := range []bool{false, true} {
+		extractor, err := NewExtractor(nil, WithWorkers(1), WithStopOnFirstError(stop))
+		if err != nil {
+			t.Fatalf("NewExtractor failed: %v", err)
+		}
+		results := extractor.BatchBytes(context.Background(), items)
+
+		var batchErr *BatchError
+		var validationErr *ValidationError
+		if err := FirstBatchError(results); !errors.As(err, &batchErr) || batchErr.Index != 2 || !errors.As(err, &validationErr) {
+			t.Fatalf("stop=%v: FirstBatchError = %v, want a ValidationError for document 2", stop, err)
+		}
+		for i, res := range results[3:] {
+			switch {
+			case stop && !errors.Is(res.Err, ErrBatchStopped):
+				t.Errorf("document %d ran after the batch was stopped: %+v", i+3, res)
+			case !stop && (res.Err != nil || res.Result == nil):
+				t.Errorf("document %d should have been extracted: %+v", i+3, res)
+			}
+		}
+		if results[1].Err != nil || results[1].Result.Content != "DOC1" {
+			t.Errorf("stop=%v: documents before the failure must be extracted: %+v", stop, results[1])
+		}
+	}
+
+	if err := FirstBatchError([]BatchResult{{Index: 0, Result: &ExtractionResult{}}}); err != nil {
+		t.Fatalf("FirstBatchError of a successful batch = %v", err)
+	}
+	err := &BatchError{Index: 3, Path: "scans/a.pdf", Err: errors.New("corrupt")}
+	if err.Error() != "document 3 (scans/a.pdf): corrupt" {
+		t.Fatalf("Error() = %q", err.Error())
+	}
+}
+
+func TestExtractorBatchFilesPaths(t *testing.T) {
+	extractor, err := NewExtractor(nil, WithStopOnFirstError(true), WithWorkers(1))
+	if err != nil {
+		t.Fatalf("NewExtractor failed: %v", err)
+	}
+	results := extractor.BatchFiles(context.Background(), []string{"", "b.pdf"})
+	if results[1].Path != "b.pdf" || !errors.Is(results[1].Err, ErrBatchStopped) {
+		t.Fatalf("unexpected results %+v", results)
+	}
+}
+
+func TestExtractorInterface(t *testing.T) {
+	registerTestExtractor(t)
+
+	configured, err := NewExtractor(NewExtractionConfig(WithUseCache(false)))
+	if err != nil {
+		t.Fatalf("NewExtractor failed: %v", err)
+	}
+	var extractor Extractor = configured
+	if result, err := extractor.ExtractBytes([]byte("doc"), testExtractorMime); err != nil || result.Content != "DOC" {
+		t.Fatalf("ExtractBytes = %+v, %v", result, err)
+	}
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+	if _, err := extractor.ExtractBytesWithContext(ctx, []byte("doc"), testExtractorMime); !errors.Is(err, context.Canceled) {
+		t.Fatalf("expected context.Canceled, got %v", err)
+	}
+}
+
+func TestExtractorCopiesConfig(t *testing.T) {
+	config := NewExtractionConfig(WithUseCache(false))
+	extractor, err := NewExtractor(config)
+	if err != nil {
+		t.Fatalf("NewExtractor failed: %v", err)
+	}
+	config.UseCache = BoolPtr(true)
+
+	if extractor.config == config || *extractor.config.UseCache {
+		t.Error("extractor should keep its own copy of the config")
+	}
+	if extractor.config.encoded == nil {
+		t.Error("extractor config should be pre-encoded")
+	}
+	if config.encoded != nil {
+		t.Error("caller's config should not be modified")
+	}
+}
+
+func TestNewExtractorRejectsInvalidChunking(t *testing.T) {
+	_, err := NewExtractor(NewExtractionConfig(WithChunking(WithMaxChars(10), WithMaxOverlap(20))))
+	if err == nil {
+		t.Fatal("expected invalid chunking config to be rejected")
+	}
+}
+
+func TestReadingOrderValidation(t *testing.T) {
+	for _, order := range []string{ReadingOrderRaw, ReadingOrderColumns} {
+		if _, err := NewExtractor(NewExtractionConfig(WithReadingOrder(order))); err != nil {
+			t.Errorf("reading order %q rejected: %v", order, err)
+		}
+	}
+
+	_, err := NewExtractor(NewExtractionConfig(WithReadingOrder("diagonal")))
+	var valErr *ValidationError
+	if !errors.As(err, &valErr) {
+		t.Fatalf("expected ValidationError for unknown reading order, got %v", err)
+	}
+	if _, err := ExtractBytesSync([]byte("x"), "text/plain", NewExtractionConfig(WithReadingOrder("diagonal"))); !errors.As(err, &valErr) {
+		t.Fatalf("expected ExtractBytesSync to reject unknown reading order, got %v", err)
+	}
+}
+
+func TestTableBackendValidation(t *testing.T) {
+	for _, backend := range AvailableTableBackends() {
+		if _, err := NewExtractor(NewExtractionConfig(WithTableBackend(backend))); err != nil {
+			t.Errorf("table backend %q rejected: %v", backend, err)
+		}
+	}
+
+	_, err := NewExtractor(NewExtractionConfig(WithTableBackend("neural")))
+	var valErr *ValidationError
+	if !errors.As(err, &valErr) {
+		t.Fatalf("expected ValidationError for unknown table backend, got %v", err)
+	}
+}
+
+func benchmarkItems(b *testing.B) []BytesWithMime {
+	b.Helper()
+	items := make([]BytesWithMime, 32)
+	for i := range items {
+		items[i] = BytesWithMime{Data: []byte(fmt.Sprintf("document %d\n", i)), MimeType: "text/plain"}
+	}
+	if _, err := ExtractBytesSync(items[0].Data, items[0].MimeType, nil); err != nil {
+		b.Skipf("native extraction unavailable: %v", err)
+	}
+	return items
+}
+
+func benchmarkConfig() *ExtractionConfig {
+	return NewExtractionConfig(
+		WithUseCache(false),
+		WithChunking(WithMaxChars(512), WithMaxOverlap(64)),
+		WithLanguageDetection(WithLanguageDetectionEnabled(false)),
+	)
+}
+
+// BenchmarkExtractorBatchBytes measures a shared Extractor, which encodes its config once.
+func BenchmarkExtractorBatchBytes(b *testing.B) {
+	items := benchmarkItems(b)
+	extractor, err := NewExtractor(benchmarkConfig())
+	if err != nil {
+		b.Fatalf("NewExtractor failed: %v", err)
+	}
+	b.ReportAllocs()
+	b.ResetTimer()
+	for range b.N {
+		extractor.BatchBytes(context.Background(), items)
+	}
+}
+
+// BenchmarkExtractBytesSyncRepeated measures the package-level API, which encodes the
+// config on every call.
+func BenchmarkExtractBytesSyncRepeated(b *testing.B) {
+	items := benchmarkItems(b)
+	config := benchmarkConfig()
+	b.ReportAllocs()
+	b.ResetTimer()
+	for range b.N {
+		for _, item := range items {
+			_, _ = ExtractBytesSync(item.Data, item.MimeType, config)
+		}
+	}
+}
@@ -0,0 +1,103 @@
+package kreuzberg
+
+import (
+	"fmt"
+	"strings"
+	"sync"
+)
+
+// ExtractorFunc extracts a document of a MIME type the native library does not handle.
+// It receives the raw document bytes and the caller's configuration (which may be nil).
+type ExtractorFunc func(data []byte, config *ExtractionConfig) (*ExtractionResult, error)
+
+var (
+	goExtractorsMu sync.RWMutex
+	goExtractors   = map[string]ExtractorFunc{}
+)
+
+func normalizeMimeType(mimeType string) string {
+	return strings.ToLower(strings.TrimSpace(mimeType))
+}
+
+// RegisterExtractor registers a Go extractor for mimeType. ExtractBytesSync,
+// BatchExtractBytesSync and their context-aware variants dispatch documents of that type to
+// fn instead of the native library; the Go-side post-processing configured on the
+// ExtractionConfig still runs on the returned result.
+//
+// Registering a MIME type that is already registered, or that the native library handles,
+// returns a PluginError.
+func RegisterExtractor(mimeType string, fn ExtractorFunc) error {
+	normalized := normalizeMimeType(mimeType)
+	if normalized == "" {
+		return newValidationErrorWithContext("extractor mime type cannot be empty", nil, ErrorCodeValidation, nil)
+	}
+	if fn == nil {
+		return newValidationErrorWithContext("extractor function cannot be nil", nil, ErrorCodeValidation, nil)
+	}
+	if _, err := ValidateMimeType(normalized); err == nil {
+		return newPluginErrorWithContext(normalized, fmt.Sprintf("mime type %s is already handled by the native library", normalized), nil, ErrorCodePlugin, nil)
+	}
+
+	goExtractorsMu.Lock()
+	defer goExtractorsMu.Unlock()
+	if _, exists := goExtractors[normalized]; exists {
+		return newPluginErrorWithContext(normalized, fmt.Sprintf("an extractor for mime type %s is already registered", normalized), nil, ErrorCodePlugin, nil)
+	}
+	goExtractors[normalized] = fn
+	return nil
+}
+
+// UnregisterExtractor removes the Go extractor registered for mimeType.
+func UnregisterExtractor(mimeType string) error {
+	normalized := normalizeMimeType(mimeType)
+	if normalized == "" {
+		return newValidationErrorWithContext("extractor mime type cannot be empty", nil, ErrorCodeValidation, nil)
+	}
+
+	goExtractorsMu.Lock()
+	defer goExtractorsMu.Unlock()
+	if _, exists := goExtractors[normalized]; !exists {
+		return newPluginErrorWithContext(normalized, fmt.Sprintf("no extractor registered for mime type %s", normalized), nil, ErrorCodePlugin, nil)
+	}
+	delete(goExtractors, normalized)
+	return nil
+}
+
+// ListExtractors returns the MIME types that have a registered Go extractor.
+func ListExtractors() []string {
+	goExtractorsMu.RLock()
+	defer goExtractorsMu.RUnlock()
+	mimeTypes := make([]string, 0, len(goExtractors))
+	for mimeType := range goExtractors {
+		mimeTypes = append(mimeTypes, mimeType)
+	}
+	return mimeTypes
+}
+
+func lookupExtractor(mimeType string) ExtractorFunc {
+	goExtractorsMu.RLock()
+	defer goExtractorsMu.RUnlock()
+	return goExtractors[normalizeMimeType(mimeType)]
+}
+
+// runExtractor invokes a registered Go extractor, converting a nil result into a PluginError
+// so callers always receive either a result or an error.
+func runExtractor(fn ExtractorFunc, data []byte, mimeType string, config *ExtractionConfig) (*ExtractionResult, error) {
+	if len(data) == 0 {
+		return nil, newValidationErrorWithContext("data cannot be empty", nil, ErrorCodeValidation, nil)
+	}
+	result, err := fn(data, config)
+	if err != nil {
+		return nil, err
+	}
+	if result == nil {
+		return nil, newPluginErrorWithContext(normalizeMimeType(mimeType), "extractor returned no result", nil, ErrorCodePlugin, nil)
+	}
+	if result.MimeType == "" {
+		result.MimeType = mimeType
+	}
+	if result.Pipeline == nil {
+		result.Pipeline = &PipelineInfo{Extractor: ExtractorGo}
+	}
+	return result, nil
+}
@@ -0,0 +1,77 @@
+package kreuzberg
+
+import (
+	"errors"
+	"strings"
+	"testing"
+)
+
+const testExtractorMime = "application/x-kreuzberg-test"
+
+func registerTestExtractor(t *testing.T) {
+	t.Helper()
+	err := RegisterExtractor(testExtractorMime, func(data []byte, config *ExtractionConfig) (*ExtractionResult, error) {
+		return &ExtractionResult{Content: strings.ToUpper(string(data)), Success: true}, nil
+	})
+	if err != nil {
+		t.Fatalf("register extractor: %v", err)
+	}
+	t.Cleanup(func() { _ = UnregisterExtractor(testExtractorMime) })
+}
+
+func TestRegisterExtractorDispatch(t *testing.T) {
+	registerTestExtractor(t)
+
+	config := NewExtractionConfig(WithContentFilter(func(s string) string { return s + "!" }))
+	result, err := ExtractBytesSync([]byte("acme"), testExtractorMime, config)
+	if err != nil {
+		t.Fatalf("extract with registered extractor: %v", err)
+	}
+	if result.Content != "ACME!" {
+		t.Errorf("expected Go extractor output with filters applied, got %q", result.Content)
+	}
+	if result.MimeType != testExtractorMime {
+		t.Errorf("expected mime type to default to %q, got %q", testExtractorMime, result.MimeType)
+	}
+
+	results, err := BatchExtractBytesSync([]BytesWithMime{
+		{Data: []byte("one"), MimeType: testExtractorMime},
+		{Data: []byte("two"), MimeType: testExtractorMime},
+	}, nil)
+	if err != nil {
+		t.Fatalf("batch extract with registered extractor: %v", err)
+	}
+	if len(results) != 2 || results[0].Content != "ONE" || results[1].Content != "TWO" {
+		t.Errorf("unexpected batch results: %+v", results)
+	}
+}
+
+func TestRegisterExtractorDuplicate(t *testing.T) {
+	registerTestExtractor(t)
+
+	err := RegisterExtractor(testExtractorMime, func([]byte, *ExtractionConfig) (*ExtractionResult, error) { return nil, nil })
+	var pluginErr *PluginError
+	if !errors.As(err, &pluginErr) {
+		t.Fatalf("expected PluginError for duplicate registration, got %v", err)
+	}
+}
+
+func TestRegisterExtractorNativeType(t *testing.T) {
+	if _, err := ValidateMimeType("application/pdf"); err != nil {
+		t.Skipf("native mime validation unavailable: %v", err)
+	}
+	err := RegisterExtractor("application/pdf", func([]byte, *ExtractionConfig) (*ExtractionResult, error) { return nil, nil })
+	var pluginErr *PluginError
+	if !errors.As(err, &pluginErr) {
+		t.Fatalf("expected PluginError for native mime type, got %v", err)
+	}
+}
+
+func TestRegisterExtractorGuards(t *testing.T) {
+	if err := RegisterExtractor("", func([]byte, *ExtractionConfig) (*ExtractionResult, error) { return nil, nil }); err == nil {
+		t.Fatalf("expected validation error for empty mime type")
+	}
+	if err := RegisterExtractor(testExtractorMime, nil); err == nil {
+		t.Fatalf("expected validation error for nil extractor")
+	}
+}
@@ -0,0 +1,98 @@
+package kreuzberg
+
+import "strings"
+
+// applyFilters runs the caller-supplied CellFilter and ContentFilter. The content filter
+// runs last so it sees the final content. When it changes the content, it is also run on
+// the text of every chunk and page, whose offsets are then found again in the filtered
+// content; PartBoundaries and the OffsetMap are dropped, since their offsets no longer
+// apply.
+func applyFilters(result *ExtractionResult, config *ExtractionConfig) {
+	if config.CellFilter != nil {
+		for t := range result.Tables {
+			for row, cells := range result.Tables[t].Cells {
+				for col, cell := range cells {
+					cells[col] = config.CellFilter(row, col, cell)
+				}
+			}
+		}
+	}
+	if config.ContentFilter != nil {
+		filtered := config.ContentFilter(result.Content)
+		if filtered != result.Content {
+			result.PartBoundaries = nil
+			result.OffsetMap = nil
+			filterChunks(result, filtered, config.ContentFilter)
+			filterPages(result, filtered, config.ContentFilter)
+		}
+		result.Content = filtered
+	}
+}
+
+// filterChunks runs filter on the text of every chunk and sets the chunk's ByteStart and
+// ByteEnd to where that text is in content. Chunks are searched for in order, each from
+// the start of the one before, since chunks may overlap. When a chunk is not found, for
+// example because the filter changes text differently out of context, the offsets of
+// every chunk are cleared to zero rather than left pointing at the unfiltered content.
+func filterChunks(result *ExtractionResult, content string, filter func(string) string) {
+	texts := make([]string, len(result.Chunks))
+	for i := range result.Chunks {
+		texts[i] = filter(result.Chunks[i].Content)
+		result.Chunks[i].Content = texts[i]
+	}
+	ranges, ok := locateInOrder(content, texts)
+	for i := range result.Chunks {
+		result.Chunks[i].Metadata.ByteStart, result.Chunks[i].Metadata.ByteEnd = 0, 0
+		if ok {
+			result.Chunks[i].Metadata.ByteStart, result.Chunks[i].Metadata.ByteEnd = uint64(ranges[i][0]), uint64(ranges[i][1])
+		}
+	}
+}
+
+// filterPages runs filter on the text of every page and finds the page boundaries again
+// in content from the filtered pages. Boundaries that cannot be found, including all of
+// them when pages were not extracted, are dropped.
+func filterPages(result *ExtractionResult, content string, filter func(string) string) {
+	texts := make(map[uint64]string, len(result.Pages))
+	for i := range result.Pages {
+		result.Pages[i].Content = filter(result.Pages[i].Content)
+		texts[result.Pages[i].PageNumber] = result.Pages[i].Content
+	}
+	structure := result.Metadata.PageStructure
+	if structure == nil || len(structure.Boundaries) == 0 {
+		return
+	}
+	pageTexts := make([]string, len(structure.Boundaries))
+	for i, boundary := range structure.Boundaries {
+		text, ok := texts[boundary.PageNumber]
+		if !ok {
+			structure.Boundaries = nil
+			return
+		}
+		pageTexts[i] = text
+	}
+	ranges, ok := locateInOrder(content, pageTexts)
+	if !ok {
+		structure.Boundaries = nil
+		return
+	}
+	for i := range structure.Boundaries {
+		structure.Boundaries[i].ByteStart, structure.Boundaries[i].ByteEnd = uint64(ranges[i][0]), uint64(ranges[i][1])
+	}
+}
+
+// locateInOrder returns the byte range of each of texts in content, searching for each
+// from the start of the one before. ok is false when a text is not found.
+func locateInOrder(content string, texts []string) (ranges [][2]int, ok bool) {
+	ranges = make([][2]int, len(texts))
+	from := 0
+	for i, text := range texts {
+		at := strings.Index(content[from:], text)
+		if at < 0 {
+			return nil, false
+		}
+		ranges[i] = [2]int{from + at, from + at + len(text)}
+		from += at
+	}
+	return ranges, true
+}
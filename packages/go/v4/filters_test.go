@@ -0,0 +1,99 @@
+package kreuzberg
+
+import (
+	"encoding/json"
+	"strings"
+	"testing"
+)
+
+// TestContentAndCellFilters verifies that filters are applied to content and every table cell.
+func TestContentAndCellFilters(t *testing.T) {
+	result := &ExtractionResult{
+		Content: "Header\nbody",
+		Tables:  []Table{{Cells: [][]string{{"A", "B"}, {"c", "d"}}}},
+	}
+	var seen []string
+	config := NewExtractionConfig(
+		WithContentFilter(strings.ToLower),
+		WithCellFilter(func(row, col int, cell string) string {
+			seen = append(seen, cell)
+			if row == 0 {
+				return strings.ToLower(cell)
+			}
+			return cell
+		}),
+	)
+
+	if err := postProcessResult(t.Context(), result, config, nil); err != nil {
+		t.Fatalf("postProcessResult failed: %v", err)
+	}
+
+	if result.Content != "header\nbody" {
+		t.Errorf("content filter not applied, got %q", result.Content)
+	}
+	want := [][]string{{"a", "b"}, {"c", "d"}}
+	for i := range want {
+		for j := range want[i] {
+			if result.Tables[0].Cells[i][j] != want[i][j] {
+				t.Errorf("cell (%d,%d) = %q, want %q", i, j, result.Tables[0].Cells[i][j], want[i][j])
+			}
+		}
+	}
+	if len(seen) != 4 {
+		t.Errorf("cell filter should be called once per cell, got %d calls", len(seen))
+	}
+}
+
+// TestContentFilterChunksAndPages verifies that the content filter is applied to chunks
+// and pages and that their offsets are found again in the filtered content.
+func TestContentFilterChunksAndPages(t *testing.T) {
+	redact := func(s string) string { return strings.ReplaceAll(s, "secret", "[x]") }
+	result := &ExtractionResult{
+		Content: "one secret\n\ntwo secret",
+		Chunks: []Chunk{
+			{Content: "one secret", Metadata: ChunkMetadata{ByteStart: 0, ByteEnd: 10}},
+			{Content: "two secret", Metadata: ChunkMetadata{ByteStart: 12, ByteEnd: 22}},
+		},
+		Pages: []PageContent{{PageNumber: 1, Content: "one secret"}, {PageNumber: 2, Content: "two secret"}},
+		Metadata: Metadata{PageStructure: &PageStructure{Boundaries: []PageBoundary{
+			{ByteStart: 0, ByteEnd: 10, PageNumber: 1}, {ByteStart: 12, ByteEnd: 22, PageNumber: 2},
+		}}},
+	}
+	applyFilters(result, NewExtractionConfig(WithContentFilter(redact)))
+
+	if result.Content != "one [x]\n\ntwo [x]" {
+		t.Fatalf("content = %q", result.Content)
+	}
+	for i, chunk := range result.Chunks {
+		if chunk.Content != result.Pages[i].Content || result.Content[chunk.Metadata.ByteStart:chunk.Metadata.ByteEnd] != chunk.Content {
+			t.Errorf("chunk %d = %+v does not match the filtered content", i, chunk)
+		}
+		boundary := result.Metadata.PageStructure.Boundaries[i]
+		if result.Content[boundary.ByteStart:boundary.ByteEnd] != result.Pages[i].Content {
+			t.Errorf("page boundary %d = %+v does not match the filtered content", i, boundary)
+		}
+	}
+
+	// Text the filter changes differently out of context cannot be found again.
+	result.Chunks[1].Content = "two secret"
+	applyFilters(result, NewExtractionConfig(WithContentFilter(func(s string) string {
+		return strings.ToUpper(s[:1]) + s[1:]
+	})))
+	for i, chunk := range result.Chunks {
+		if chunk.Metadata.ByteStart != 0 || chunk.Metadata.ByteEnd != 0 {
+			t.Errorf("chunk %d keeps offsets %+v", i, chunk.Metadata)
+		}
+	}
+}
+
+// TestFiltersNotSerialized verifies that filters never reach the native config JSON.
+func TestFiltersNotSerialized(t *testing.T) {
+	config := NewExtractionConfig(WithContentFilter(strings.TrimSpace))
+	data, err := json.Marshal(config)
+	if err != nil {
+		t.Fatalf("marshal config failed: %v", err)
+	}
+	if strings.Contains(string(data), "filter") {
+		t.Errorf("filters leaked into config JSON: %s", data)
+	}
+}
@@ -0,0 +1,212 @@
+package kreuzberg
+
+import (
+	"context"
+	"sort"
+	"strings"
+)
+
+// pdfFormField is a terminal AcroForm field with the position of its first widget.
+type pdfFormField struct {
+	label      string
+	value      string
+	checkbox   bool
+	checked    bool
+	pageNumber int
+	top        float64
+	left       float64
+}
+
+// Field flag bits from the PDF specification (table 226).
+const (
+	pdfFieldFlagRadio      = 1 << 15
+	pdfFieldFlagPushButton = 1 << 16
+)
+
+// applyFormFieldsInContent appends the filled AcroForm fields of a PDF to result.Content,
+// one "Label: value" line per field, ordered by page and then top-to-bottom, left-to-right
+// position. Checkboxes render as "[x]" or "[ ]". Encrypted documents are left untouched.
+// Chunks and detected languages are computed again from the new content.
+func applyFormFieldsInContent(ctx context.Context, result *ExtractionResult, config *ExtractionConfig, src *documentSource) error {
+	if config.FormFieldsInContent == nil || !*config.FormFieldsInContent || !isPDFMimeType(result.MimeType) {
+		return nil
+	}
+	doc, err := src.pdfDocument()
+	if err != nil {
+		return err
+	}
+	fields := readPDFFormFields(doc)
+	if len(fields) == 0 {
+		return nil
+	}
+
+	lines := make([]string, 0, len(fields))
+	for _, field := range fields {
+		value := field.value
+		if field.checkbox {
+			value = "[ ]"
+			if field.checked {
+				value = "[x]"
+			}
+		}
+		lines = append(lines, field.label+": "+value)
+	}
+
+	content := strings.TrimRight(result.Content, "\n")
+	if content != "" {
+		content += "\n\n"
+	}
+	result.Content = content + strings.Join(lines, "\n")
+	return applyContentFeatures(ctx, result, config, extractBytesNative)
+}
+
+// readPDFFormFields returns the terminal fields of the document's AcroForm in reading order.
+func readPDFFormFields(doc *pdfDocument) []pdfFormField {
+	if doc.encrypted {
+		return nil
+	}
+	catalog := doc.catalog()
+	if catalog == nil {
+		return nil
+	}
+	acroForm := doc.dict(catalog["AcroForm"])
+	if acroForm == nil {
+		return nil
+	}
+	pages := doc.pageNumbers()
+
+	var fields []pdfFormField
+	visited := make(map[int]bool)
+	var walk func(node any, inherited pdfDict)
+	walk = func(node any, inherited pdfDict) {
+		if ref, ok := node.(pdfRef); ok {
+			if visited[ref.num] {
+				return
+			}
+			visited[ref.num] = true
+		}
+		dict := doc.dict(node)
+		if dict == nil {
+			return
+		}
+
+		attrs := pdfDict{}
+		for _, key := range []string{"FT", "Ff", "V", "T", "TU"} {
+			if value, ok := dict[key]; ok {
+				attrs[key] = value
+			} else if value, ok := inherited[key]; ok && key != "T" && key != "TU" {
+				attrs[key] = value
+			}
+		}
+
+		// Kids carrying their own /T are child fields; kids without one are widgets.
+		var widgets []pdfDict
+		hasChildFields := false
+		for _, kid := range doc.array(dict["Kids"]) {
+			kidDict := doc.dict(kid)
+			if kidDict == nil {
+				continue
+			}
+			if _, named := kidDict["T"]; named {
+				hasChildFields = true
+				walk(kid, attrs)
+				continue
+			}
+			widgets = append(widgets, kidDict)
+		}
+		if hasChildFields && len(widgets) == 0 {
+			return
+		}
+		if len(widgets) == 0 {
+			widgets = []pdfDict{dict}
+		}
+
+		if field, ok := newPDFFormField(doc, attrs, widgets, pages); ok {
+			fields = append(fields, field)
+		}
+	}
+	for _, field := range doc.array(acroForm["Fields"]) {
+		walk(field, nil)
+	}
+
+	sort.SliceStable(fields, func(i, j int) bool {
+		a, b := fields[i], fields[j]
+		if a.pageNumber != b.pageNumber {
+			return a.pageNumber < b.pageNumber
+		}
+		if a.top != b.top {
+			return a.top > b.top
+		}
+		return a.left < b.left
+	})
+	return fields
+}
+
+func newPDFFormField(doc *pdfDocument, attrs pdfDict, widgets []pdfDict, pages map[int]int) (pdfFormField, bool) {
+	fieldType := attrs.name("FT")
+	flags, _ := doc.resolve(attrs["Ff"]).(float64)
+	if fieldType == "" || fieldType == "Sig" || (fieldType == "Btn" && int(flags)&pdfFieldFlagPushButton != 0) {
+		return pdfFormField{}, false
+	}
+
+	label, _ := doc.resolve(attrs["TU"]).(string)
+	if strings.TrimSpace(label) == "" {
+		label, _ = doc.resolve(attrs["T"]).(string)
+	}
+	label = strings.TrimSpace(label)
+	if label == "" {
+		return pdfFormField{}, false
+	}
+
+	field := pdfFormField{label: label}
+	widget := widgets[0]
+	if page, ok := widget["P"].(pdfRef); ok {
+		field.pageNumber = pages[page.num]
+	}
+	if rect := doc.array(widget["Rect"]); len(rect) == 4 {
+		left, _ := doc.resolve(rect[0]).(float64)
+		top, _ := doc.resolve(rect[3]).(float64)
+		field.left, field.top = left, top
+	}
+
+	value := doc.resolve(attrs["V"])
+	switch fieldType {
+	case "Btn":
+		field.checkbox = true
+		if int(flags)&pdfFieldFlagRadio != 0 {
+			// Radio groups share one value naming the selected option.
+			if name, ok := value.(pdfName); ok && name != "Off" {
+				field.checkbox = false
+				field.value = string(name)
+			}
+			return field, true
+		}
+		if name, ok := value.(pdfName); ok {
+			field.checked = name != "Off"
+		} else if state, ok := widget["AS"].(pdfName); ok {
+			field.checked = state != "Off"
+		}
+	default:
+		field.value = pdfFieldValueString(doc, value)
+	}
+	return field, true
+}
+
+func pdfFieldValueString(doc *pdfDocument, value any) string {
+	switch v := value.(type) {
+	case string:
+		return strings.TrimSpace(v)
+	case pdfName:
+		return string(v)
+	case pdfArray:
+		parts := make([]string, 0, len(v))
+		for _, item := range v {
+			if s := pdfFieldValueString(doc, doc.resolve(item)); s != "" {
+				parts = append(parts, s)
+			}
+		}
+		return strings.Join(parts, ", ")
+	default:
+		return ""
+	}
+}
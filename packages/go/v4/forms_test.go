@@ -0,0 +1,104 @@
+package kreuzberg
+
+import (
+	"bytes"
+	"compress/zlib"
+	"context"
+	"fmt"
+	"strings"
+	"testing"
+)
+
+// buildTestPDF assembles a minimal PDF from numbered object bodies (object 1 is the catalog).
+func buildTestPDF(objects ...string) []byte {
+	var buf bytes.Buffer
+	buf.WriteString("%PDF-1.7\n")
+	for i, body := range objects {
+		fmt.Fprintf(&buf, "%d 0 obj\n%s\nendobj\n", i+1, body)
+	}
+	buf.WriteString("trailer\n<< /Root 1 0 R >>\n%%EOF\n")
+	return buf.Bytes()
+}
+
+const testFormPages = `<< /Type /Pages /Kids [3 0 R 4 0 R] /Count 2 >>`
+
+func testFormPDF() []byte {
+	return buildTestPDF(
+		`<< /Type /Catalog /Pages 2 0 R /AcroForm << /Fields [5 0 R 6 0 R 7 0 R 8 0 R] >> >>`,
+		testFormPages,
+		`<< /Type /Page /Parent 2 0 R >>`,
+		`<< /Type /Page /Parent 2 0 R >>`,
+		`<< /FT /Btn /T (agree) /TU (I agree) /V /Yes /P 4 0 R /Rect [50 500 60 510] >>`,
+		`<< /FT /Tx /T (city) /V (Berlin) /P 3 0 R /Rect [50 600 200 620] >>`,
+		`<< /FT /Tx /T (name) /TU <FEFF004E0061006D0065> /V (John Doe) /P 3 0 R /Rect [50 700 200 720] >>`,
+		`<< /FT /Btn /T (newsletter) /V /Off /P 4 0 R /Rect [50 400 60 410] >>`,
+	)
+}
+
+func TestFormFieldsInContent(t *testing.T) {
+	result := &ExtractionResult{Content: "Application form\n", MimeType: "application/pdf"}
+	config := NewExtractionConfig(WithFormFieldsInContent(true))
+
+	if err := applyFormFieldsInContent(context.Background(), result, config, &documentSource{data: testFormPDF()}); err != nil {
+		t.Fatalf("applyFormFieldsInContent failed: %v", err)
+	}
+
+	want := "Application form\n\nName: John Doe\ncity: Berlin\nI agree: [x]\nnewsletter: [ ]"
+	if result.Content != want {
+		t.Errorf("unexpected content:\n%q\nwant:\n%q", result.Content, want)
+	}
+}
+
+// TestFormFieldsInContentChunked verifies that the inlined fields are chunked with the rest
+// of the content.
+func TestFormFieldsInContentChunked(t *testing.T) {
+	if _, err := ExtractBytesSync([]byte("probe"), "text/plain", nil); err != nil {
+		t.Skipf("native library unavailable: %v", err)
+	}
+	result := &ExtractionResult{Content: "Application form\n", MimeType: "application/pdf", Chunks: []Chunk{{Content: "Application form"}}}
+	config := NewExtractionConfig(WithFormFieldsInContent(true), WithChunking(WithMaxChars(1000)))
+	if err := applyFormFieldsInContent(context.Background(), result, config, &documentSource{data: testFormPDF()}); err != nil {
+		t.Fatalf("applyFormFieldsInContent failed: %v", err)
+	}
+	if len(result.Chunks) != 1 || !strings.Contains(result.Chunks[0].Content, "Name: John Doe") {
+		t.Fatalf("expected the form fields in the chunks, got %+v", result.Chunks)
+	}
+}
+
+func TestFormFieldsInObjectStream(t *testing.T) {
+	objects := []string{
+		`<< /FT /Tx /T (company) /V (Acme) /Rect [0 10 10 20] >>`,
+		`<< /FT /Tx /T (country) /V (DE) /Rect [0 5 10 15] >>`,
+	}
+	var header, body strings.Builder
+	for i, object := range objects {
+		fmt.Fprintf(&header, "%d %d ", i+3, body.Len())
+		body.WriteString(object + "\n")
+	}
+	var packed bytes.Buffer
+	zw := zlib.NewWriter(&packed)
+	_, _ = zw.Write([]byte(header.String() + body.String()))
+	_ = zw.Close()
+
+	var buf bytes.Buffer
+	buf.WriteString("%PDF-1.7\n")
+	buf.WriteString("1 0 obj\n<< /Type /Catalog /AcroForm << /Fields [3 0 R 4 0 R] >> >>\nendobj\n")
+	fmt.Fprintf(&buf, "2 0 obj\n<< /Type /ObjStm /N 2 /First %d /Filter /FlateDecode /Length %d >>\nstream\n", header.Len(), packed.Len())
+	buf.Write(packed.Bytes())
+	buf.WriteString("\nendstream\nendobj\ntrailer\n<< /Root 1 0 R >>\n%%EOF\n")
+
+	fields := readPDFFormFields(mustParsePDF(t, buf.Bytes()))
+	if len(fields) != 2 || fields[0].label != "company" || fields[0].value != "Acme" || fields[1].value != "DE" {
+		t.Fatalf("unexpected fields: %+v", fields)
+	}
+}
+
+func TestFormFieldsInContentDisabled(t *testing.T) {
+	result := &ExtractionResult{Content: "text", MimeType: "application/pdf"}
+	if err := applyFormFieldsInContent(context.Background(), result, NewExtractionConfig(), &documentSource{data: testFormPDF()}); err != nil {
+		t.Fatalf("applyFormFieldsInContent failed: %v", err)
+	}
+	if result.Content != "text" {
+		t.Errorf("content should be unchanged, got %q", result.Content)
+	}
+}
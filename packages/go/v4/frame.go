@@ -0,0 +1,182 @@
+package kreuzberg
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+)
+
+// Frame is a table as typed records, for analysis without parsing cells by hand; see
+// Table.AsFrame.
+type Frame struct {
+	// Columns names the columns in table order.
+	Columns []string
+	// Records holds one map per data row from column name to value: an int64 for
+	// ColumnTypeInteger columns, a float64 for ColumnTypeNumber, a bool for
+	// ColumnTypeBoolean, a time.Time for ColumnTypeDate and the cell text for
+	// ColumnTypeString. Empty cells are nil.
+	Records []map[string]any
+	// Mismatches lists the cells that kept a column from having the type most of its cells
+	// share; see Err.
+	Mismatches []TypeMismatch
+
+	types map[string]ColumnType
+}
+
+// TypeMismatch is a cell whose value does not have the type of most of its column, which
+// made the column a ColumnTypeString column.
+type TypeMismatch struct {
+	Column string
+	// Record is the zero-based index of the cell's record in Frame.Records.
+	Record int
+	Value  string
+	// Expected is the type the other cells of the column share.
+	Expected ColumnType
+}
+
+// TypeMismatchError reports the cells of a Frame whose type differs from the rest of their
+// column.
+type TypeMismatchError struct {
+	Mismatches []TypeMismatch
+}
+
+func (e *TypeMismatchError) Error() string {
+	parts := make([]string, 0, len(e.Mismatches))
+	for _, m := range e.Mismatches {
+		parts = append(parts, fmt.Sprintf("column %q record %d: %q is not %s", m.Column, m.Record, m.Value, m.Expected))
+	}
+	return "mixed column types: " + strings.Join(parts, "; ")
+}
+
+// Err returns a *TypeMismatchError listing Mismatches, or nil when every column has a
+// single type.
+func (f *Frame) Err() error {
+	if f == nil || len(f.Mismatches) == 0 {
+		return nil
+	}
+	return &TypeMismatchError{Mismatches: f.Mismatches}
+}
+
+// TypeOf returns the type of the named column as the string of its ColumnType, such as
+// "integer" or "date", or "" when the frame has no such column.
+func (f *Frame) TypeOf(col string) string {
+	if f == nil {
+		return ""
+	}
+	return string(f.types[col])
+}
+
+// AsFrame returns the table as a Frame. The first row names the columns unless the table is
+// known to have no header row, as CSV tables whose header detection found none; columns
+// without a name are called "column_N" for their one-based position, and repeated names
+// get a "_N" suffix. Each column gets the most specific type that fits all its non-empty
+// cells, trying integer, number, boolean and date before string, as in Table.ColumnTypes.
+// A column that is a string column only because a minority of its cells does not fit the
+// type of the rest has those cells reported in Mismatches. Nested tables are kept as their
+// Markdown text.
+func (t *Table) AsFrame() *Frame {
+	if t == nil {
+		return nil
+	}
+	rows := t.Cells
+	var header []string
+	if len(rows) > 0 && (t.HasHeader || t.ColumnTypes == nil) {
+		header, rows = rows[0], rows[1:]
+	}
+	width := len(header)
+	for _, row := range rows {
+		width = max(width, len(row))
+	}
+
+	f := &Frame{Columns: make([]string, width), Records: make([]map[string]any, len(rows)), types: make(map[string]ColumnType, width)}
+	used := make(map[string]bool, width)
+	for col := range f.Columns {
+		name := ""
+		if col < len(header) {
+			name = strings.TrimSpace(header[col])
+		}
+		if name == "" {
+			name = "column_" + strconv.Itoa(col+1)
+		}
+		for base, n := name, 2; used[name]; n++ {
+			name = base + "_" + strconv.Itoa(n)
+		}
+		used[name] = true
+		f.Columns[col] = name
+	}
+	for i := range f.Records {
+		f.Records[i] = make(map[string]any, width)
+	}
+
+	for col, name := range f.Columns {
+		var values []string
+		var records []int
+		for i, row := range rows {
+			if col < len(row) && strings.TrimSpace(row[col]) != "" {
+				values = append(values, strings.TrimSpace(row[col]))
+				records = append(records, i)
+			}
+		}
+		columnType := inferColumnType(values)
+		f.types[name] = columnType
+		if columnType == ColumnTypeString {
+			f.Mismatches = append(f.Mismatches, columnMismatches(name, values, records)...)
+		}
+		for i := range rows {
+			f.Records[i][name] = nil
+		}
+		for j, value := range values {
+			if columnType == ColumnTypeString {
+				value = rows[records[j]][col]
+			}
+			f.Records[records[j]][name] = frameValue(value, columnType)
+		}
+	}
+	return f
+}
+
+// columnMismatches returns the values of a string column that do not fit the type most of
+// them fit, when a strict majority of at least two values fits one.
+func columnMismatches(column string, values []string, records []int) []TypeMismatch {
+	best, bestCount := ColumnTypeString, 0
+	for _, candidate := range []ColumnType{ColumnTypeInteger, ColumnTypeNumber, ColumnTypeBoolean, ColumnTypeDate} {
+		count := 0
+		for _, value := range values {
+			if cellMatchesType(value, candidate) {
+				count++
+			}
+		}
+		if count > bestCount {
+			best, bestCount = candidate, count
+		}
+	}
+	if bestCount < 2 || bestCount*2 <= len(values) {
+		return nil
+	}
+	var mismatches []TypeMismatch
+	for j, value := range values {
+		if !cellMatchesType(value, best) {
+			mismatches = append(mismatches, TypeMismatch{Column: column, Record: records[j], Value: value, Expected: best})
+		}
+	}
+	return mismatches
+}
+
+// frameValue converts the trimmed text of a non-empty cell to the Go value of columnType.
+func frameValue(value string, columnType ColumnType) any {
+	switch columnType {
+	case ColumnTypeInteger:
+		n, _ := strconv.ParseInt(value, 10, 64)
+		return n
+	case ColumnTypeNumber:
+		x, _ := strconv.ParseFloat(value, 64)
+		return x
+	case ColumnTypeBoolean:
+		return strings.EqualFold(value, "true")
+	case ColumnTypeDate:
+		t, _ := parseCellDate(value)
+		return t
+	default:
+		return value
+	}
+}
@@ -0,0 +1,61 @@
+package kreuzberg
+
+import (
+	"errors"
+	"reflect"
+	"testing"
+	"time"
+)
+
+func TestTableAsFrame(t *testing.T) {
+	table := &Table{Cells: [][]string{
+		{"id", "price", "active", "since", "note", "qty", ""},
+		{"1", "2.5", "true", "2024-01-02", "first", "3", "x"},
+		{"2", "", "FALSE", "2024-02-03", "", "4", "y"},
+		{"3", "7", "false", "2024-03-04", "42", "n/a", ""},
+	}}
+	f := table.AsFrame()
+	if want := []string{"id", "price", "active", "since", "note", "qty", "column_7"}; !reflect.DeepEqual(f.Columns, want) {
+		t.Fatalf("columns = %q, want %q", f.Columns, want)
+	}
+	types := map[string]string{"id": "integer", "price": "number", "active": "boolean", "since": "date", "note": "string", "qty": "string", "missing": ""}
+	for col, want := range types {
+		if got := f.TypeOf(col); got != want {
+			t.Errorf("TypeOf(%q) = %q, want %q", col, got, want)
+		}
+	}
+	first := f.Records[0]
+	if first["id"] != int64(1) || first["price"] != 2.5 || first["active"] != true ||
+		first["since"] != time.Date(2024, 1, 2, 0, 0, 0, 0, time.UTC) || first["note"] != "first" {
+		t.Errorf("unexpected first record %v", first)
+	}
+	if f.Records[1]["price"] != nil || f.Records[1]["active"] != false || f.Records[2]["column_7"] != nil {
+		t.Errorf("unexpected records %v", f.Records)
+	}
+
+	want := []TypeMismatch{{Column: "qty", Record: 2, Value: "n/a", Expected: ColumnTypeInteger}}
+	if !reflect.DeepEqual(f.Mismatches, want) {
+		t.Fatalf("mismatches = %+v, want %+v", f.Mismatches, want)
+	}
+	var mismatchErr *TypeMismatchError
+	if err := f.Err(); !errors.As(err, &mismatchErr) || len(mismatchErr.Mismatches) != 1 {
+		t.Fatalf("expected a TypeMismatchError, got %v", err)
+	}
+}
+
+func TestTableAsFrameHeaderless(t *testing.T) {
+	table := &Table{
+		Cells:       [][]string{{"a", "1"}, {"a", "2"}},
+		ColumnTypes: []ColumnType{ColumnTypeString, ColumnTypeInteger},
+	}
+	f := table.AsFrame()
+	if !reflect.DeepEqual(f.Columns, []string{"column_1", "column_2"}) {
+		t.Fatalf("columns = %q", f.Columns)
+	}
+	if len(f.Records) != 2 || f.Records[1][f.Columns[1]] != int64(2) || f.Err() != nil {
+		t.Fatalf("unexpected frame %+v", f)
+	}
+	if (*Table)(nil).AsFrame() != nil {
+		t.Fatal("nil table gave a frame")
+	}
+}
@@ -0,0 +1,116 @@
+package kreuzberg
+
+import (
+	"context"
+	"fmt"
+	"io/fs"
+	"path"
+	"strings"
+)
+
+// extensionMimeTypes mirrors the extension table the native library uses to detect the MIME
+// type of a path, so names in an fs.FS are typed the same way as files on disk.
+var extensionMimeTypes = map[string]string{
+	"txt":        "text/plain",
+	"md":         "text/markdown",
+	"markdown":   "text/markdown",
+	"commonmark": "text/x-commonmark",
+	"rst":        "text/x-rst",
+	"org":        "text/x-org",
+	"pdf":        "application/pdf",
+	"html":       "text/html",
+	"htm":        "text/html",
+	"docx":       mimeTypeDOCX,
+	"doc":        "application/msword",
+	"odt":        "application/vnd.oasis.opendocument.text",
+	"xlsx":       mimeTypeXLSX,
+	"xls":        "application/vnd.ms-excel",
+	"xlsm":       "application/vnd.ms-excel.sheet.macroEnabled.12",
+	"xlsb":       "application/vnd.ms-excel.sheet.binary.macroEnabled.12",
+	"xlam":       "application/vnd.ms-excel.addin.macroEnabled.12",
+	"xla":        "application/vnd.ms-excel.template.macroEnabled.12",
+	"ods":        "application/vnd.oasis.opendocument.spreadsheet",
+	"pptx":       mimeTypePPTX,
+	"ppt":        "application/vnd.ms-powerpoint",
+	"bmp":        "image/bmp",
+	"gif":        "image/gif",
+	"jpg":        "image/jpeg",
+	"jpeg":       "image/jpeg",
+	"png":        "image/png",
+	"tiff":       "image/tiff",
+	"tif":        "image/tiff",
+	"webp":       "image/webp",
+	"jp2":        "image/jp2",
+	"jpx":        "image/jpx",
+	"jpm":        "image/jpm",
+	"mj2":        "image/mj2",
+	"pnm":        "image/x-portable-anymap",
+	"pbm":        "image/x-portable-bitmap",
+	"pgm":        "image/x-portable-graymap",
+	"ppm":        "image/x-portable-pixmap",
+	"csv":        "text/csv",
+	"tsv":        "text/tab-separated-values",
+	"json":       "application/json",
+	"yaml":       "application/x-yaml",
+	"yml":        "application/x-yaml",
+	"toml":       "application/toml",
+	"xml":        "application/xml",
+	"svg":        "image/svg+xml",
+	"eml":        "message/rfc822",
+	"msg":        "application/vnd.ms-outlook",
+	"zip":        "application/zip",
+	"tar":        "application/x-tar",
+	"gz":         "application/gzip",
+	"tgz":        "application/x-tar",
+	"7z":         "application/x-7z-compressed",
+	"epub":       "application/epub+zip",
+	"rtf":        "application/rtf",
+	"bib":        "application/x-bibtex",
+	"ipynb":      "application/x-ipynb+json",
+	"tex":        "application/x-latex",
+	"latex":      "application/x-latex",
+	"typst":      "application/x-typst",
+}
+
+// ExtractFileFS extracts content and metadata from the file called name in fsys, such as an
+// embed.FS or the fs.FS of a zip archive, without copying it to disk. name follows the
+// fs.FS rules: slash-separated and relative to the root of fsys.
+//
+// The MIME type comes from the extension of name, as for ExtractFileSync, including MIME
+// overrides (see WithMimeOverrides and SetMimeOverride), and is detected
+// from the content when the extension is missing or unknown. The result is the same as
+// passing the file's bytes to ExtractBytesSync.
+func ExtractFileFS(fsys fs.FS, name string, config *ExtractionConfig) (*ExtractionResult, error) {
+	if fsys == nil {
+		return nil, newValidationErrorWithContext("fsys is required", nil, ErrorCodeValidation, nil)
+	}
+	if !fs.ValidPath(name) || name == "." {
+		return nil, newValidationErrorWithContext(fmt.Sprintf("invalid file name %q", name), nil, ErrorCodeValidation, nil)
+	}
+	data, err := fs.ReadFile(fsys, name)
+	if err != nil {
+		return nil, newIOErrorWithContext(fmt.Sprintf("failed to read %s", name), err, ErrorCodeIo, nil)
+	}
+	config = configOrDefault(config)
+	if mimeType, ok := mimeOverride(name, config); ok {
+		if err := checkMimeOverride(name, data, mimeType); err != nil {
+			return nil, err
+		}
+		return extractBytes(context.Background(), data, mimeType, config)
+	}
+	mimeType, err := mimeTypeForName(name, data)
+	if err != nil {
+		return nil, err
+	}
+	return extractBytes(context.Background(), data, mimeType, config)
+}
+
+// mimeTypeForName returns the MIME type implied by the extension of name, falling back to
+// detection from data.
+func mimeTypeForName(name string, data []byte) (string, error) {
+	ext := strings.ToLower(strings.TrimPrefix(path.Ext(name), "."))
+	if mimeType, ok := extensionMimeTypes[ext]; ok {
+		return mimeType, nil
+	}
+	return DetectMimeType(data)
+}
@@ -0,0 +1,71 @@
+package kreuzberg
+
+import (
+	"errors"
+	"strings"
+	"testing"
+	"testing/fstest"
+)
+
+func TestMimeTypeForName(t *testing.T) {
+	for name, want := range map[string]string{
+		"report.pdf":         "application/pdf",
+		"dir/Letter.DOCX":    mimeTypeDOCX,
+		"archive/notes.md":   "text/markdown",
+		"scans/page.tif":     "image/tiff",
+		"data/table.csv":     "text/csv",
+		"nested/a.b/c.jpeg":  "image/jpeg",
+		"presentation.pptx":  mimeTypePPTX,
+		"workbook.v2.xlsx":   mimeTypeXLSX,
+		"inbox/message.eml":  "message/rfc822",
+		"bundle/readme.txt":  "text/plain",
+		"legacy/report.doc":  "application/msword",
+		"images/diagram.svg": "image/svg+xml",
+	} {
+		got, err := mimeTypeForName(name, nil)
+		if err != nil || got != want {
+			t.Errorf("mimeTypeForName(%q) = %q, %v; want %q", name, got, err, want)
+		}
+	}
+}
+
+func TestExtractFileFSErrors(t *testing.T) {
+	fsys := fstest.MapFS{"docs/readme.txt": {Data: []byte("hello")}}
+
+	var ioErr *IOError
+	if _, err := ExtractFileFS(fsys, "docs/missing.txt", nil); !errors.As(err, &ioErr) {
+		t.Fatalf("expected IOError for a missing file, got %v", err)
+	}
+	for _, name := range []string{"", ".", "/docs/readme.txt", "docs/../readme.txt"} {
+		var validation *ValidationError
+		if _, err := ExtractFileFS(fsys, name, nil); !errors.As(err, &validation) {
+			t.Fatalf("expected ValidationError for %q, got %v", name, err)
+		}
+	}
+	var validation *ValidationError
+	if _, err := ExtractFileFS(nil, "docs/readme.txt", nil); !errors.As(err, &validation) {
+		t.Fatalf("expected ValidationError for a nil fs.FS, got %v", err)
+	}
+}
+
+func TestExtractFileFS(t *testing.T) {
+	if _, err := ExtractBytesSync([]byte("probe"), "text/plain", nil); err != nil {
+		t.Skipf("native extraction unavailable: %v", err)
+	}
+	fsys := fstest.MapFS{
+		"docs/readme.txt": {Data: []byte("Hello from an fs.FS")},
+		"docs/README":     {Data: []byte("No extension here")},
+	}
+	for name, want := range map[string]string{
+		"docs/readme.txt": "Hello from an fs.FS",
+		"docs/README":     "No extension here",
+	} {
+		result, err := ExtractFileFS(fsys, name, nil)
+		if err != nil {
+			t.Fatalf("ExtractFileFS(%q): %v", name, err)
+		}
+		if strings.TrimSpace(result.Content) != want || result.MimeType != "text/plain" {
+			t.Fatalf("ExtractFileFS(%q) = %q (%s), want %q", name, result.Content, result.MimeType, want)
+		}
+	}
+}
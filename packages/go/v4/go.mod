@@ -4,3 +4,5 @@ go 1.25
 
 // Local development; remove this section for published releases
 // replace github.com/kreuzberg-dev/kreuzberg => ../../
+
+require golang.org/x/text v0.31.0
@@ -0,0 +1,211 @@
+package kreuzberg
+
+import (
+	"math"
+	"sort"
+	"strings"
+	"unicode"
+)
+
+const (
+	defaultHeaderFooterThreshold = 0.5
+	// headerFooterLines is the number of lines at the top and at the bottom of a page that
+	// can be part of a header or footer.
+	headerFooterLines = 2
+	// minHeaderFooterPages is the page count below which repetition says too little.
+	minHeaderFooterPages = 3
+)
+
+// contentLine is a non-blank line of Content; end is the offset of its line break or of the
+// end of its page.
+type contentLine struct {
+	start, end int
+}
+
+// applyHeadersFooters moves running headers and footers out of result.Content into
+// result.Headers and result.Footers when ExtractionConfig.SeparateHeadersFooters is set.
+// Pages are taken from the page boundaries. A line within headerFooterLines of the top
+// (bottom) of its page is a header (footer) when lines with the same headerFooterKey are in
+// that position on at least the threshold fraction of pages. Headers and footers are
+// removed from the edge of each page inwards, stopping at the first line that repeats too
+// rarely, together with the blank lines separating them from the page body.
+func applyHeadersFooters(result *ExtractionResult, config *ExtractionConfig) {
+	if config.SeparateHeadersFooters == nil || !*config.SeparateHeadersFooters {
+		return
+	}
+	ps := result.Metadata.PageStructure
+	if ps == nil || len(ps.Boundaries) < minHeaderFooterPages {
+		return
+	}
+	threshold := defaultHeaderFooterThreshold
+	if config.HeaderFooterThreshold != nil {
+		threshold = *config.HeaderFooterThreshold
+	}
+	content := result.Content
+
+	pages := make([][]contentLine, len(ps.Boundaries))
+	for i, boundary := range ps.Boundaries {
+		start := int(min(boundary.ByteStart, uint64(len(content))))
+		end := int(min(boundary.ByteEnd, uint64(len(content))))
+		pages[i] = pageLines(content, start, end)
+	}
+	minPages := max(2, int(math.Ceil(threshold*float64(len(pages)))))
+	headerCounts, footerCounts := make(map[string]int), make(map[string]int)
+	for _, lines := range pages {
+		countEdgeLines(content, lines[:min(len(lines), headerFooterLines)], headerCounts)
+		countEdgeLines(content, lines[max(0, len(lines)-headerFooterLines):], footerCounts)
+	}
+
+	var removed [][2]int
+	var headers, footers []string
+	seenHeaders, seenFooters := make(map[string]bool), make(map[string]bool)
+	add := func(list *[]string, seen map[string]bool, text string) {
+		if !seen[text] {
+			seen[text] = true
+			*list = append(*list, text)
+		}
+	}
+	for i, lines := range pages {
+		pageEnd := int(min(ps.Boundaries[i].ByteEnd, uint64(len(content))))
+		top := 0
+		for top < len(lines) && top < headerFooterLines && headerCounts[headerFooterKey(lineText(content, lines[top]))] >= minPages {
+			end := pageEnd
+			if top+1 < len(lines) {
+				end = lines[top+1].start
+			}
+			removed = append(removed, [2]int{lines[top].start, end})
+			add(&headers, seenHeaders, lineText(content, lines[top]))
+			top++
+		}
+		var pageFooters []string
+		for j := len(lines) - 1; j >= top && j >= len(lines)-headerFooterLines && footerCounts[headerFooterKey(lineText(content, lines[j]))] >= minPages; j-- {
+			start := lines[j].start
+			if j > 0 {
+				start = lines[j-1].end
+			}
+			removed = append(removed, [2]int{start, lines[j].end})
+			pageFooters = append([]string{lineText(content, lines[j])}, pageFooters...)
+		}
+		for _, text := range pageFooters {
+			add(&footers, seenFooters, text)
+		}
+	}
+	if len(removed) == 0 {
+		return
+	}
+
+	sort.Slice(removed, func(i, j int) bool { return removed[i][0] < removed[j][0] })
+	var out strings.Builder
+	out.Grow(len(content))
+	offsets := make([]int, len(content)+1)
+	next := 0
+	for _, r := range removed {
+		start := max(r[0], next)
+		for ; next < start; next++ {
+			offsets[next] = out.Len()
+			out.WriteByte(content[next])
+		}
+		for ; next < r[1]; next++ {
+			offsets[next] = out.Len()
+		}
+	}
+	for ; next < len(content); next++ {
+		offsets[next] = out.Len()
+		out.WriteByte(content[next])
+	}
+	offsets[len(content)] = out.Len()
+	separated := out.String()
+
+	// Chunk and page text taken verbatim from the content is cut from the new content.
+	verbatim := make([]bool, len(result.Chunks))
+	for i, chunk := range result.Chunks {
+		start, end := chunk.Metadata.ByteStart, chunk.Metadata.ByteEnd
+		verbatim[i] = start <= end && end <= uint64(len(content)) && content[start:end] == chunk.Content
+	}
+	pageText := make(map[uint64]bool, len(result.Pages))
+	for _, page := range result.Pages {
+		for _, boundary := range ps.Boundaries {
+			if boundary.PageNumber == page.PageNumber && boundary.ByteEnd <= uint64(len(content)) &&
+				strings.TrimSpace(content[boundary.ByteStart:boundary.ByteEnd]) == strings.TrimSpace(page.Content) {
+				pageText[page.PageNumber] = true
+			}
+		}
+	}
+
+	remapContent(result, separated, offsets)
+	for i := range result.Chunks {
+		if verbatim[i] {
+			meta := result.Chunks[i].Metadata
+			result.Chunks[i].Content = separated[meta.ByteStart:meta.ByteEnd]
+		}
+	}
+	for i := range result.Pages {
+		if !pageText[result.Pages[i].PageNumber] {
+			continue
+		}
+		for _, boundary := range ps.Boundaries {
+			if boundary.PageNumber == result.Pages[i].PageNumber {
+				result.Pages[i].Content = strings.TrimSpace(separated[boundary.ByteStart:boundary.ByteEnd])
+			}
+		}
+	}
+	result.Headers, result.Footers = headers, footers
+}
+
+// pageLines returns the non-blank lines of content[start:end].
+func pageLines(content string, start, end int) []contentLine {
+	var lines []contentLine
+	for offset := start; offset < end; {
+		lineEnd := end
+		if i := strings.IndexByte(content[offset:end], '\n'); i >= 0 {
+			lineEnd = offset + i
+		}
+		if strings.TrimSpace(content[offset:lineEnd]) != "" {
+			lines = append(lines, contentLine{start: offset, end: lineEnd})
+		}
+		offset = lineEnd + 1
+	}
+	return lines
+}
+
+// countEdgeLines counts the page in counts once for every distinct key among lines.
+func countEdgeLines(content string, lines []contentLine, counts map[string]int) {
+	seen := make(map[string]bool, len(lines))
+	for _, line := range lines {
+		key := headerFooterKey(lineText(content, line))
+		if !seen[key] {
+			seen[key] = true
+			counts[key]++
+		}
+	}
+}
+
+func lineText(content string, line contentLine) string {
+	return strings.TrimSpace(content[line.start:line.end])
+}
+
+// headerFooterKey is the text compared between pages: text in lower case with each run of
+// digits replaced by "#" and each run of whitespace by one space, so page numbers and
+// dates do not prevent a match.
+func headerFooterKey(text string) string {
+	var key strings.Builder
+	digit, space := false, false
+	for _, r := range strings.ToLower(text) {
+		switch {
+		case unicode.IsDigit(r):
+			if !digit {
+				key.WriteByte('#')
+			}
+			digit, space = true, false
+		case unicode.IsSpace(r):
+			if !space {
+				key.WriteByte(' ')
+			}
+			digit, space = false, true
+		default:
+			key.WriteRune(r)
+			digit, space = false, false
+		}
+	}
+	return key.String()
+}
@@ -0,0 +1,91 @@
+package kreuzberg
+
+import (
+	"reflect"
+	"strings"
+	"testing"
+)
+
+// pagedResult builds a result whose content is pages joined by blank lines, with page
+// boundaries and one verbatim chunk per page.
+func pagedResult(pages ...string) *ExtractionResult {
+	result := &ExtractionResult{Metadata: Metadata{PageStructure: &PageStructure{}}}
+	var content strings.Builder
+	for i, page := range pages {
+		if i > 0 {
+			content.WriteString("\n\n")
+		}
+		start := content.Len()
+		content.WriteString(page)
+		boundary := PageBoundary{ByteStart: uint64(start), ByteEnd: uint64(content.Len()), PageNumber: uint64(i + 1)}
+		result.Metadata.PageStructure.Boundaries = append(result.Metadata.PageStructure.Boundaries, boundary)
+		result.Chunks = append(result.Chunks, Chunk{Content: page, Metadata: ChunkMetadata{ByteStart: boundary.ByteStart, ByteEnd: boundary.ByteEnd}})
+		result.Pages = append(result.Pages, PageContent{PageNumber: uint64(i + 1), Content: page})
+	}
+	result.Content = content.String()
+	return result
+}
+
+func TestApplyHeadersFooters(t *testing.T) {
+	result := pagedResult(
+		"Annual Report 2024\n\nIntroduction text.\n\nPage 1 of 3",
+		"Annual Report 2024\nResults are good.\nPage 2 of 3",
+		"Annual Report 2024\n\nOutlook text.\n\nPage 3 of 3",
+	)
+	config := NewExtractionConfig(WithSeparateHeadersFooters(true))
+	applyHeadersFooters(result, config)
+
+	if want := "Introduction text.\n\nResults are good.\n\nOutlook text."; result.Content != want {
+		t.Fatalf("Content = %q, want %q", result.Content, want)
+	}
+	if want := []string{"Annual Report 2024"}; !reflect.DeepEqual(result.Headers, want) {
+		t.Fatalf("Headers = %q, want %q", result.Headers, want)
+	}
+	if want := []string{"Page 1 of 3", "Page 2 of 3", "Page 3 of 3"}; !reflect.DeepEqual(result.Footers, want) {
+		t.Fatalf("Footers = %q, want %q", result.Footers, want)
+	}
+	for i, want := range []string{"Introduction text.", "Results are good.", "Outlook text."} {
+		boundary := result.Metadata.PageStructure.Boundaries[i]
+		if got := result.Content[boundary.ByteStart:boundary.ByteEnd]; strings.TrimSpace(got) != want {
+			t.Errorf("page %d boundary covers %q, want %q", i+1, got, want)
+		}
+		if got := strings.TrimSpace(result.Chunks[i].Content); got != want {
+			t.Errorf("chunk %d = %q, want %q", i, got, want)
+		}
+		if got := result.Pages[i].Content; got != want {
+			t.Errorf("page %d content = %q, want %q", i+1, got, want)
+		}
+	}
+}
+
+func TestApplyHeadersFootersThreshold(t *testing.T) {
+	pages := []string{
+		"Chapter One\nFirst page.",
+		"Chapter One\nSecond page.",
+		"Appendix\nThird page.",
+		"Appendix\nFourth page.",
+	}
+	result := pagedResult(pages...)
+	applyHeadersFooters(result, NewExtractionConfig(WithSeparateHeadersFooters(true), WithHeaderFooterThreshold(0.75)))
+	if result.Headers != nil || result.Footers != nil || !strings.HasPrefix(result.Content, "Chapter One") {
+		t.Fatalf("expected nothing removed at threshold 0.75, got headers %q, footers %q", result.Headers, result.Footers)
+	}
+
+	result = pagedResult(pages...)
+	applyHeadersFooters(result, NewExtractionConfig(WithSeparateHeadersFooters(true)))
+	if want := []string{"Chapter One", "Appendix"}; !reflect.DeepEqual(result.Headers, want) {
+		t.Fatalf("Headers = %q, want %q", result.Headers, want)
+	}
+
+	result = pagedResult(pages...)
+	applyHeadersFooters(result, &ExtractionConfig{})
+	if result.Headers != nil || !strings.HasPrefix(result.Content, "Chapter One") {
+		t.Fatal("expected headers to stay in the content when disabled")
+	}
+}
+
+func TestHeaderFooterThresholdValidation(t *testing.T) {
+	if err := validateConfig(NewExtractionConfig(WithHeaderFooterThreshold(1.5))); err == nil {
+		t.Fatal("expected a validation error for a threshold above 1")
+	}
+}
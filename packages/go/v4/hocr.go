@@ -0,0 +1,171 @@
+package kreuzberg
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"regexp"
+	"slices"
+	"strconv"
+	"strings"
+)
+
+// hocrHeader and hocrFooter wrap the ocr_page elements returned by Tesseract into an hOCR
+// 1.2 document, matching the header written by Tesseract's own hOCR renderer.
+const (
+	hocrHeader = `<?xml version="1.0" encoding="UTF-8"?>
+<!DOCTYPE html PUBLIC "-//W3C//DTD XHTML 1.0 Transitional//EN"
+    "http://www.w3.org/TR/xhtml1/DTD/xhtml1-transitional.dtd">
+<html xmlns="http://www.w3.org/1999/xhtml" xml:lang="en" lang="en">
+ <head>
+  <title></title>
+  <meta http-equiv="Content-Type" content="text/html;charset=utf-8"/>
+  <meta name='ocr-system' content='tesseract'/>
+  <meta name='ocr-capabilities' content='ocr_page ocr_carea ocr_par ocr_line ocrx_word ocrp_wconf'/>
+ </head>
+ <body>
+`
+	hocrFooter = ` </body>
+</html>
+`
+)
+
+var (
+	// hocrPageID matches the page part of the element ids Tesseract writes for page 1,
+	// such as page_1, block_1_2 and word_1_17.
+	hocrPageID = regexp.MustCompile(`(id=['"][a-z]+_)1([_'"])`)
+	hocrPageNo = regexp.MustCompile(`ppageno \d+`)
+)
+
+// applyHOCR fills result.HOCR when OCRConfig.HOCR is enabled and an image document was
+// OCR'd. The Tesseract backend is run again with hOCR output, once per frame for
+// multi-page TIFFs, and the pages are combined into one document. Pages that fail or time
+// out are left out; HOCR stays empty when no page produced hOCR.
+func applyHOCR(ctx context.Context, result *ExtractionResult, config *ExtractionConfig, src *documentSource) error {
+	ocr := config.OCR
+	if ocr == nil || ocr.HOCR == nil || !*ocr.HOCR || !strings.HasPrefix(result.MimeType, "image/") {
+		return nil
+	}
+	if ocr.Backend != "" && !strings.EqualFold(ocr.Backend, "tesseract") {
+		return nil
+	}
+	data, err := src.bytes()
+	if err != nil {
+		return err
+	}
+
+	frames := [][]byte{data}
+	if isTIFFMimeType(result.MimeType) {
+		if order, offsets := tiffIFDOffsets(data); len(offsets) > 1 {
+			frames = frames[:0]
+			for _, offset := range offsets {
+				// Frames that cannot be split are left out but keep their page number.
+				frame, _ := tiffFrame(data, order, offset)
+				frames = append(frames, frame)
+			}
+		}
+	}
+
+	hocrConfig := hocrExtractionConfig(config)
+	timeout := ocrPageTimeout(hocrConfig)
+
+	var pages strings.Builder
+	for i, frame := range frames {
+		if frame == nil {
+			continue
+		}
+		page, err := extractPageWithTimeout(ctx, frame, result.MimeType, hocrConfig, timeout)
+		if err != nil {
+			if ctxErr := ctx.Err(); ctxErr != nil {
+				return ctxErr
+			}
+			continue
+		}
+		pages.WriteString(hocrPage(page.Content, i+1))
+	}
+	if pages.Len() > 0 {
+		result.HOCR = hocrHeader + pages.String() + hocrFooter
+	}
+	return nil
+}
+
+// hocrExtractionConfig returns a config that OCRs an image with the OCR settings of
+// config, which must be set, and returns Tesseract's hOCR as the content.
+func hocrExtractionConfig(config *ExtractionConfig) *ExtractionConfig {
+	ocr := config.OCR
+	hocrOCR := *ocr
+	hocrOCR.Tesseract = &TesseractConfig{}
+	if ocr.Tesseract != nil {
+		*hocrOCR.Tesseract = *ocr.Tesseract
+	} else if ocr.Language != nil {
+		hocrOCR.Tesseract.Language = *ocr.Language
+	}
+	hocrOCR.Tesseract.OutputFormat = "hocr"
+	// Native post-processing would treat the markup as text, so it is turned off.
+	disabled := false
+	return &ExtractionConfig{
+		UseCache:                config.UseCache,
+		OCR:                     &hocrOCR,
+		EnableQualityProcessing: &disabled,
+		Postprocessor:           &PostProcessorConfig{Enabled: &disabled},
+	}
+}
+
+// hocrPage returns the ocr_page elements of the hOCR Tesseract produced for one page,
+// renumbered as page pageNumber of the document.
+func hocrPage(hocr string, pageNumber int) string {
+	if start := strings.Index(hocr, "<body"); start >= 0 {
+		hocr = hocr[start:]
+		hocr = hocr[strings.IndexByte(hocr, '>')+1:]
+		if end := strings.LastIndex(hocr, "</body>"); end >= 0 {
+			hocr = hocr[:end]
+		}
+	}
+	hocr = strings.TrimLeft(strings.TrimRight(hocr, " \t\r\n"), "\r\n")
+	if hocr == "" {
+		return ""
+	}
+	if pageNumber > 1 {
+		hocr = hocrPageID.ReplaceAllString(hocr, "${1}"+strconv.Itoa(pageNumber)+"${2}")
+		hocr = hocrPageNo.ReplaceAllString(hocr, fmt.Sprintf("ppageno %d", pageNumber-1))
+	}
+	return hocr + "\n"
+}
+
+// ocrPageHOCR returns the hOCR the native OCR returned along with the text of each page of
+// a result: "page_hocr" for the pages of a PDF, and "hocr" for an image, which is page 1.
+// Pages without hOCR are "".
+func ocrPageHOCR(metadata Metadata) []string {
+	if raw, ok := metadata.Additional["page_hocr"]; ok {
+		var pages []*string
+		if json.Unmarshal(raw, &pages) == nil {
+			hocr := make([]string, len(pages))
+			for i, page := range pages {
+				if page != nil {
+					hocr[i] = *page
+				}
+			}
+			return hocr
+		}
+	}
+	var hocr string
+	if raw, ok := metadata.Additional["hocr"]; ok && json.Unmarshal(raw, &hocr) == nil {
+		return []string{hocr}
+	}
+	return nil
+}
+
+// setPageHOCR lists the hOCR of the pages of result under "page_hocr" in its metadata, as
+// the native library does for the PDF pages it OCRs.
+func setPageHOCR(result *ExtractionResult, pages []string) {
+	delete(result.Metadata.Additional, "hocr")
+	if !slices.ContainsFunc(pages, func(page string) bool { return page != "" }) {
+		delete(result.Metadata.Additional, "page_hocr")
+		return
+	}
+	data, _ := json.Marshal(pages)
+	if result.Metadata.Additional == nil {
+		result.Metadata.Additional = make(map[string]json.RawMessage)
+	}
+	result.Metadata.Additional["page_hocr"] = data
+}
@@ -0,0 +1,55 @@
+package kreuzberg
+
+import (
+	"context"
+	"strings"
+	"testing"
+)
+
+func TestHOCRPage(t *testing.T) {
+	page := "  <div class='ocr_page' id='page_1' title='image \"\"; bbox 0 0 100 50; ppageno 0'>\n" +
+		"   <span class='ocrx_word' id='word_1_12' title='bbox 1 2 30 20; x_wconf 96'>Hello</span>\n" +
+		"  </div>\n"
+
+	if got := hocrPage(page, 1); got != strings.Trim(page, "\n")+"\n" {
+		t.Fatalf("page 1 changed: %q", got)
+	}
+	got := hocrPage(page, 3)
+	for _, want := range []string{"id='page_3'", "id='word_3_12'", "ppageno 2", "x_wconf 96"} {
+		if !strings.Contains(got, want) {
+			t.Fatalf("renumbered page missing %q: %q", want, got)
+		}
+	}
+
+	document := hocrHeader + page + hocrFooter
+	if got := hocrPage(document, 1); got != strings.Trim(page, "\n")+"\n" {
+		t.Fatalf("body of a full document = %q", got)
+	}
+	if got := hocrPage("\n", 1); got != "" {
+		t.Fatalf("empty page = %q", got)
+	}
+}
+
+func TestHOCRNotRequested(t *testing.T) {
+	data, _, _ := testBarcodePNG(t, nil, 1, false)
+	cases := map[string]struct {
+		config *ExtractionConfig
+		mime   string
+	}{
+		"no ocr":       {&ExtractionConfig{}, "image/png"},
+		"disabled":     {NewExtractionConfig(WithOCR(WithOCRHOCR(false))), "image/png"},
+		"not an image": {NewExtractionConfig(WithOCR(WithOCRHOCR(true))), "application/pdf"},
+		"other backend": {
+			NewExtractionConfig(WithOCR(WithOCRHOCR(true), WithOCRBackend("paddleocr"))), "image/png",
+		},
+	}
+	for name, tc := range cases {
+		result := &ExtractionResult{MimeType: tc.mime}
+		if err := applyHOCR(context.Background(), result, tc.config, &documentSource{data: data}); err != nil {
+			t.Fatalf("%s: applyHOCR: %v", name, err)
+		}
+		if result.HOCR != "" {
+			t.Fatalf("%s: expected no hOCR, got %q", name, result.HOCR)
+		}
+	}
+}
@@ -0,0 +1,279 @@
+package kreuzberg
+
+import (
+	"bufio"
+	"encoding/base64"
+	"fmt"
+	"html"
+	"io"
+	"regexp"
+	"strings"
+)
+
+// htmlStyle is the style sheet of documents written by WriteHTML.
+const htmlStyle = `body { font-family: sans-serif; line-height: 1.5; max-width: 50em; margin: 2em auto; padding: 0 1em; }
+table { border-collapse: collapse; margin: 1em 0; }
+th, td { border: 1px solid #999; padding: 0.25em 0.5em; vertical-align: top; }
+img { max-width: 100%; }
+pre { background: #f4f4f4; padding: 0.5em; overflow-x: auto; }`
+
+var (
+	markdownListItem = regexp.MustCompile(`^\s*(?:([-*+])|(\d+)[.)])\s+`)
+	markdownCodeSpan = regexp.MustCompile("`([^`]+)`")
+	markdownImage    = regexp.MustCompile(`!\[([^\]]*)\]\(([^)\s]+)\)`)
+	markdownLink     = regexp.MustCompile(`\[([^\]]+)\]\(([^)\s]+)\)`)
+	markdownStrong   = regexp.MustCompile(`\*\*([^*]+)\*\*`)
+	markdownEmphasis = regexp.MustCompile(`\*([^*\s][^*]*)\*`)
+)
+
+// ToHTML renders the result as a self-contained HTML document; see WriteHTML.
+func (r *ExtractionResult) ToHTML() (string, error) {
+	var b strings.Builder
+	if err := r.WriteHTML(&b); err != nil {
+		return "", err
+	}
+	return b.String(), nil
+}
+
+// WriteHTML writes the result to w as a complete HTML document that needs no other files,
+// for archiving and sharing extractions. Content is rendered from Markdown: headings,
+// paragraphs, lists, block quotes and fenced code blocks, with emphasis, code spans and
+// links in the text. Tables are rendered as HTML tables from Tables, with merged cells
+// spanning rows and columns and the first row as the header, and images are embedded as
+// base64 data URIs; both are placed in reading order as in Blocks, which are computed like
+// structured output does when the result has none. Images in formats without a MIME type,
+// such as raw PDF image streams, are represented by their description or OCR text. Links
+// other than http, https, mailto and relative ones are rendered as plain text.
+func (r *ExtractionResult) WriteHTML(w io.Writer) error {
+	if r == nil {
+		r = &ExtractionResult{}
+	}
+	blocks := r.Blocks
+	if blocks == nil {
+		blocks = documentBlocks(r)
+	}
+
+	title, _ := r.indexTitleAuthor()
+	for _, block := range blocks {
+		if title != "" {
+			break
+		}
+		if block.Type == BlockTypeHeading {
+			title = block.Content
+		}
+	}
+	if title == "" {
+		title = "Document"
+	}
+
+	b := bufio.NewWriter(w)
+	b.WriteString("<!DOCTYPE html>\n<html")
+	if r.Metadata.Language != nil && *r.Metadata.Language != "" {
+		fmt.Fprintf(b, ` lang="%s"`, html.EscapeString(*r.Metadata.Language))
+	}
+	b.WriteString(">\n<head>\n<meta charset=\"utf-8\">\n<title>")
+	b.WriteString(html.EscapeString(title))
+	b.WriteString("</title>\n<style>\n" + htmlStyle + "\n</style>\n</head>\n<body>\n")
+	for _, block := range blocks {
+		switch block.Type {
+		case BlockTypeHeading:
+			level := min(max(block.Level, 1), 6)
+			fmt.Fprintf(b, "<h%d>%s</h%d>\n", level, markdownInlineHTML(block.Content), level)
+		case BlockTypeTable:
+			if block.TableIndex != nil && *block.TableIndex < len(r.Tables) {
+				writeHTMLTable(b, &r.Tables[*block.TableIndex])
+			} else {
+				writeHTMLTable(b, markdownTable(block.Content))
+			}
+		case BlockTypeImage:
+			if block.ImageIndex != nil && *block.ImageIndex < len(r.Images) {
+				writeHTMLImage(b, r.Images[*block.ImageIndex], block.Content)
+			}
+		default:
+			writeHTMLParagraph(b, block.Content)
+		}
+	}
+	b.WriteString("</body>\n</html>\n")
+	return b.Flush()
+}
+
+// writeHTMLParagraph writes a paragraph block of Markdown: a fenced code block, a list, a
+// block quote or a plain paragraph.
+func writeHTMLParagraph(b *bufio.Writer, text string) {
+	lines := strings.Split(text, "\n")
+	first := strings.TrimSpace(lines[0])
+	switch {
+	case strings.HasPrefix(first, "```") || strings.HasPrefix(first, "~~~"):
+		code := lines[1:]
+		if n := len(code); n > 0 && strings.HasPrefix(strings.TrimSpace(code[n-1]), first[:3]) {
+			code = code[:n-1]
+		}
+		b.WriteString("<pre><code>" + html.EscapeString(strings.Join(code, "\n")) + "</code></pre>\n")
+		return
+	case markdownListItem.MatchString(lines[0]):
+		ordered := markdownListItem.FindStringSubmatch(lines[0])[2] != ""
+		tag := "ul"
+		if ordered {
+			tag = "ol"
+		}
+		var items []string
+		for _, line := range lines {
+			if marker := markdownListItem.FindString(line); marker != "" {
+				items = append(items, line[len(marker):])
+			} else if len(items) > 0 {
+				items[len(items)-1] += "\n" + strings.TrimSpace(line)
+			}
+		}
+		b.WriteString("<" + tag + ">\n")
+		for _, item := range items {
+			b.WriteString("<li>" + markdownInlineHTML(item) + "</li>\n")
+		}
+		b.WriteString("</" + tag + ">\n")
+		return
+	case strings.HasPrefix(first, ">"):
+		for i, line := range lines {
+			lines[i] = strings.TrimPrefix(strings.TrimPrefix(strings.TrimSpace(line), ">"), " ")
+		}
+		b.WriteString("<blockquote><p>" + markdownInlineHTML(strings.Join(lines, "\n")) + "</p></blockquote>\n")
+		return
+	}
+	b.WriteString("<p>" + markdownInlineHTML(text) + "</p>\n")
+}
+
+// markdownInlineHTML escapes text for HTML and renders its code spans, images, links,
+// strong emphasis and emphasis. Line breaks become <br>.
+func markdownInlineHTML(text string) string {
+	var out strings.Builder
+	last := 0
+	for _, span := range markdownCodeSpan.FindAllStringSubmatchIndex(text, -1) {
+		out.WriteString(markdownTextHTML(text[last:span[0]]))
+		out.WriteString("<code>" + html.EscapeString(text[span[2]:span[3]]) + "</code>")
+		last = span[1]
+	}
+	out.WriteString(markdownTextHTML(text[last:]))
+	return out.String()
+}
+
+// markdownTextHTML renders the inline Markdown of text without code spans.
+func markdownTextHTML(text string) string {
+	s := html.EscapeString(text)
+	s = markdownImage.ReplaceAllStringFunc(s, func(match string) string {
+		parts := markdownImage.FindStringSubmatch(match)
+		if !safeHTMLURL(html.UnescapeString(parts[2])) {
+			return parts[1]
+		}
+		return `<img src="` + parts[2] + `" alt="` + parts[1] + `">`
+	})
+	s = markdownLink.ReplaceAllStringFunc(s, func(match string) string {
+		parts := markdownLink.FindStringSubmatch(match)
+		if !safeHTMLURL(html.UnescapeString(parts[2])) {
+			return parts[1]
+		}
+		return `<a href="` + parts[2] + `">` + parts[1] + `</a>`
+	})
+	s = markdownStrong.ReplaceAllString(s, "<strong>$1</strong>")
+	s = markdownEmphasis.ReplaceAllString(s, "<em>$1</em>")
+	return strings.ReplaceAll(s, "\n", "<br>\n")
+}
+
+// safeHTMLURL reports whether a link target may be written to an href or src attribute:
+// http, https and mailto URLs, and URLs without a scheme.
+func safeHTMLURL(target string) bool {
+	scheme, _, found := strings.Cut(target, ":")
+	if !found || strings.ContainsAny(scheme, "/?#") {
+		return true
+	}
+	switch strings.ToLower(scheme) {
+	case "http", "https", "mailto":
+		return true
+	}
+	return false
+}
+
+// markdownTable parses the Markdown pipe table of a table block that has no entry in
+// Tables.
+func markdownTable(text string) *Table {
+	var cells [][]string
+	for _, line := range strings.Split(text, "\n") {
+		if isPipeRow(line) && !isPipeDelimiterRow(line) {
+			cells = append(cells, splitPipeRow(line))
+		}
+	}
+	return &Table{Cells: cells}
+}
+
+// writeHTMLTable writes table as an HTML table whose first row is the header. Tables nested
+// in cells are written as tables inside them.
+func writeHTMLTable(b *bufio.Writer, table *Table) {
+	grid := table.CellGrid()
+	if len(grid) == 0 {
+		return
+	}
+	b.WriteString("<table>\n")
+	for r, row := range grid {
+		switch r {
+		case 0:
+			b.WriteString("<thead>\n")
+		case 1:
+			b.WriteString("<tbody>\n")
+		}
+		b.WriteString("<tr>")
+		tag := "td"
+		if r == 0 {
+			tag = "th"
+		}
+		for _, cell := range row {
+			if cell.IsContinuation {
+				continue
+			}
+			b.WriteString("<" + tag)
+			if cell.RowSpan > 1 {
+				fmt.Fprintf(b, ` rowspan="%d"`, cell.RowSpan)
+			}
+			if cell.ColSpan > 1 {
+				fmt.Fprintf(b, ` colspan="%d"`, cell.ColSpan)
+			}
+			b.WriteString(">")
+			if cell.Nested != nil {
+				b.WriteString("\n")
+				writeHTMLTable(b, cell.Nested)
+			} else {
+				b.WriteString(strings.ReplaceAll(html.EscapeString(cell.Text), "\n", "<br>"))
+			}
+			b.WriteString("</" + tag + ">")
+		}
+		b.WriteString("</tr>\n")
+		if r == 0 {
+			b.WriteString("</thead>\n")
+		}
+	}
+	if len(grid) > 1 {
+		b.WriteString("</tbody>\n")
+	}
+	b.WriteString("</table>\n")
+}
+
+// writeHTMLImage writes img as a figure embedding its data, captioned with text, the
+// caption, description or OCR text of the image.
+func writeHTMLImage(b *bufio.Writer, img ExtractedImage, text string) {
+	if img.Caption != "" {
+		text = img.Caption
+	}
+	alt := html.EscapeString(text)
+	mimeType, ok := imageOCRMimeTypes[strings.ToLower(img.Format)]
+	if !ok || len(img.Data) == 0 {
+		if alt != "" {
+			b.WriteString("<figure><figcaption>" + alt + "</figcaption></figure>\n")
+		}
+		return
+	}
+	b.WriteString(`<figure><img src="data:` + mimeType + ";base64,")
+	encoder := base64.NewEncoder(base64.StdEncoding, b)
+	_, _ = encoder.Write(img.Data)
+	_ = encoder.Close()
+	b.WriteString(`" alt="` + alt + `">`)
+	if alt != "" {
+		b.WriteString("<figcaption>" + alt + "</figcaption>")
+	}
+	b.WriteString("</figure>\n")
+}
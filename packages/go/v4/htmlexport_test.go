@@ -0,0 +1,72 @@
+package kreuzberg
+
+import (
+	"bytes"
+	"strings"
+	"testing"
+)
+
+func TestToHTML(t *testing.T) {
+	page := 1
+	lang := "en"
+	result := &ExtractionResult{
+		Content: "# Report <draft>\n\nSee **results** at [site](https://example.com) and [bad](javascript:alert(1)).\n\n" +
+			"- first\n- `x < y`\n\n| Name | Qty |\n| --- | --- |\n| a & b | 1 |",
+		Tables: []Table{{Cells: [][]string{{"Name", "Qty"}, {"a & b", "1"}}, Markdown: "| Name | Qty |\n| --- | --- |\n| a & b | 1 |"}},
+		Images: []ExtractedImage{{Data: []byte("png!"), Format: "PNG", PageNumber: &page, Caption: "Figure 1"}},
+	}
+	result.Metadata.Language = &lang
+
+	got, err := result.ToHTML()
+	if err != nil {
+		t.Fatalf("ToHTML failed: %v", err)
+	}
+	for _, want := range []string{
+		"<!DOCTYPE html>",
+		`<html lang="en">`,
+		"<title>Report &lt;draft&gt;</title>",
+		"<h1>Report &lt;draft&gt;</h1>",
+		`<strong>results</strong>`,
+		`<a href="https://example.com">site</a>`,
+		"<ul>\n<li>first</li>\n<li><code>x &lt; y</code></li>\n</ul>",
+		"<thead>\n<tr><th>Name</th><th>Qty</th></tr>\n</thead>",
+		"<tr><td>a &amp; b</td><td>1</td></tr>",
+		`<img src="data:image/png;base64,cG5nIQ==" alt="Figure 1"><figcaption>Figure 1</figcaption>`,
+		"</html>\n",
+	} {
+		if !strings.Contains(got, want) {
+			t.Errorf("HTML lacks %q:\n%s", want, got)
+		}
+	}
+	if strings.Contains(got, "javascript:") {
+		t.Errorf("unsafe link rendered:\n%s", got)
+	}
+	if strings.Count(got, "<table>") != 1 {
+		t.Errorf("expected the table once:\n%s", got)
+	}
+
+	var buf bytes.Buffer
+	if err := result.WriteHTML(&buf); err != nil || buf.String() != got {
+		t.Fatalf("WriteHTML differs from ToHTML: %v", err)
+	}
+}
+
+func TestToHTMLMergedCells(t *testing.T) {
+	result := &ExtractionResult{
+		Content: "Intro",
+		Tables: []Table{{
+			Cells:       [][]string{{"Region", "Sales", ""}, {"North", "1", "2"}},
+			MergedCells: []CellRange{{Row: 0, Col: 1, RowSpan: 1, ColSpan: 2}},
+		}},
+	}
+	got, err := result.ToHTML()
+	if err != nil {
+		t.Fatalf("ToHTML failed: %v", err)
+	}
+	if !strings.Contains(got, `<th colspan="2">Sales</th></tr>`) {
+		t.Errorf("merged header not spanned:\n%s", got)
+	}
+	if !strings.Contains(got, "<title>Document</title>") || !strings.Contains(got, "<p>Intro</p>") {
+		t.Errorf("unexpected document:\n%s", got)
+	}
+}
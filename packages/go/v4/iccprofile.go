@@ -0,0 +1,205 @@
+package kreuzberg
+
+import (
+	"bytes"
+	"compress/zlib"
+	"encoding/binary"
+	"io"
+	"strings"
+	"unicode/utf16"
+)
+
+const (
+	// iccMaxProfileSize bounds the decompressed size of a PNG ICC profile.
+	iccMaxProfileSize = 16 << 20
+	// tiffTagICCProfile is the TIFF tag holding an embedded ICC profile.
+	tiffTagICCProfile = 34675
+)
+
+// jpegICCMarker starts the APP2 segments that carry an ICC profile in JPEG files.
+var jpegICCMarker = []byte("ICC_PROFILE\x00")
+
+// applyColorProfiles fills ExtractedImage.ICCProfile and ExtractedImage.ColorProfileName
+// when ImageExtractionConfig.ExtractColorProfiles is set. The profile is read from the
+// iCCP chunk of PNG images, the ICC_PROFILE segments of JPEG images and the ICC profile tag
+// of the first frame of TIFF images. The name is the profile's description tag, or for PNG
+// the name of the iCCP chunk when the profile has no readable description.
+func applyColorProfiles(result *ExtractionResult, config *ExtractionConfig) {
+	if config.Images == nil || config.Images.ExtractColorProfiles == nil || !*config.Images.ExtractColorProfiles {
+		return
+	}
+	for i := range result.Images {
+		img := &result.Images[i]
+		if img.IsMask || len(img.Data) == 0 {
+			continue
+		}
+		var profile []byte
+		var name string
+		switch strings.ToLower(img.Format) {
+		case "png":
+			profile, name = pngICCProfile(img.Data)
+		case "jpg", "jpeg":
+			profile = jpegICCProfile(img.Data)
+		case "tif", "tiff":
+			profile = tiffICCProfile(img.Data)
+		}
+		if len(profile) == 0 {
+			continue
+		}
+		if description := iccDescription(profile); description != "" {
+			name = description
+		}
+		img.ICCProfile = profile
+		img.ColorProfileName = name
+	}
+}
+
+// pngICCProfile returns the decompressed profile and the name of the iCCP chunk of a PNG.
+func pngICCProfile(data []byte) ([]byte, string) {
+	const signature = "\x89PNG\r\n\x1a\n"
+	if !bytes.HasPrefix(data, []byte(signature)) {
+		return nil, ""
+	}
+	for offset := len(signature); offset+8 <= len(data); {
+		length := int(binary.BigEndian.Uint32(data[offset:]))
+		kind := string(data[offset+4 : offset+8])
+		start := offset + 8
+		if length < 0 || start+length > len(data) {
+			return nil, ""
+		}
+		switch kind {
+		case "iCCP":
+			chunk := data[start : start+length]
+			nul := bytes.IndexByte(chunk, 0)
+			if nul < 0 || nul+2 > len(chunk) || chunk[nul+1] != 0 {
+				return nil, ""
+			}
+			reader, err := zlib.NewReader(bytes.NewReader(chunk[nul+2:]))
+			if err != nil {
+				return nil, ""
+			}
+			defer func() { _ = reader.Close() }()
+			profile, err := io.ReadAll(io.LimitReader(reader, iccMaxProfileSize))
+			if err != nil {
+				return nil, ""
+			}
+			return profile, string(chunk[:nul])
+		case "IDAT", "IEND":
+			// iCCP must come before the image data.
+			return nil, ""
+		}
+		offset = start + length + 4
+	}
+	return nil, ""
+}
+
+// jpegICCProfile returns the ICC profile of a JPEG, joining its APP2 ICC_PROFILE segments
+// in sequence order.
+func jpegICCProfile(data []byte) []byte {
+	if len(data) < 4 || data[0] != 0xff || data[1] != 0xd8 {
+		return nil
+	}
+	parts := make(map[int][]byte)
+	count := 0
+	for offset := 2; offset+4 <= len(data) && data[offset] == 0xff; {
+		marker := data[offset+1]
+		if marker == 0xda || marker == 0xd9 {
+			break
+		}
+		length := int(binary.BigEndian.Uint16(data[offset+2:]))
+		end := offset + 2 + length
+		if length < 2 || end > len(data) {
+			break
+		}
+		segment := data[offset+4 : end]
+		if marker == 0xe2 && bytes.HasPrefix(segment, jpegICCMarker) && len(segment) >= len(jpegICCMarker)+2 {
+			sequence := int(segment[len(jpegICCMarker)])
+			count = int(segment[len(jpegICCMarker)+1])
+			parts[sequence] = segment[len(jpegICCMarker)+2:]
+		}
+		offset = end
+	}
+	if count == 0 || len(parts) != count {
+		return nil
+	}
+	var profile []byte
+	for sequence := 1; sequence <= count; sequence++ {
+		part, ok := parts[sequence]
+		if !ok {
+			return nil
+		}
+		profile = append(profile, part...)
+	}
+	return profile
+}
+
+// tiffICCProfile returns the ICC profile tag of the first frame of a TIFF.
+func tiffICCProfile(data []byte) []byte {
+	order, offsets := tiffIFDOffsets(data)
+	if len(offsets) == 0 {
+		return nil
+	}
+	offset := uint64(offsets[0])
+	entries := uint64(order.Uint16(data[offset : offset+2]))
+	for i := range entries {
+		entry := data[offset+2+i*12 : offset+2+(i+1)*12]
+		if order.Uint16(entry[0:2]) != tiffTagICCProfile {
+			continue
+		}
+		count := uint64(order.Uint32(entry[4:8]))
+		if count <= 4 {
+			return append([]byte(nil), entry[8:8+count]...)
+		}
+		start := uint64(order.Uint32(entry[8:12]))
+		if start+count > uint64(len(data)) {
+			return nil
+		}
+		return append([]byte(nil), data[start:start+count]...)
+	}
+	return nil
+}
+
+// iccDescription returns the profile description of an ICC profile, read from its "desc"
+// tag in the ICC v2 textDescriptionType or ICC v4 multiLocalizedUnicodeType form, or "".
+func iccDescription(profile []byte) string {
+	if len(profile) < 132 {
+		return ""
+	}
+	tags := int(binary.BigEndian.Uint32(profile[128:132]))
+	for i := 0; i < tags && 132+(i+1)*12 <= len(profile); i++ {
+		entry := profile[132+i*12:]
+		if string(entry[:4]) != "desc" {
+			continue
+		}
+		start := int(binary.BigEndian.Uint32(entry[4:8]))
+		size := int(binary.BigEndian.Uint32(entry[8:12]))
+		if start < 0 || size < 12 || start+size > len(profile) {
+			return ""
+		}
+		tag := profile[start : start+size]
+		switch string(tag[:4]) {
+		case "desc":
+			n := int(binary.BigEndian.Uint32(tag[8:12]))
+			if n <= 0 || 12+n > len(tag) {
+				return ""
+			}
+			return strings.TrimSpace(strings.TrimRight(string(tag[12:12+n]), "\x00"))
+		case "mluc":
+			if len(tag) < 28 {
+				return ""
+			}
+			length := int(binary.BigEndian.Uint32(tag[20:24]))
+			textStart := int(binary.BigEndian.Uint32(tag[24:28]))
+			if length < 2 || textStart < 0 || textStart+length > len(tag) {
+				return ""
+			}
+			units := make([]uint16, length/2)
+			for j := range units {
+				units[j] = binary.BigEndian.Uint16(tag[textStart+2*j:])
+			}
+			return strings.TrimSpace(strings.TrimRight(string(utf16.Decode(units)), "\x00"))
+		}
+		return ""
+	}
+	return ""
+}
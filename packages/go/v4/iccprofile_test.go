@@ -0,0 +1,143 @@
+package kreuzberg
+
+import (
+	"bytes"
+	"compress/zlib"
+	"encoding/binary"
+	"hash/crc32"
+	"testing"
+	"unicode/utf16"
+)
+
+// testICCProfile returns a minimal ICC profile whose only tag is a "desc" tag, in the v2
+// textDescriptionType form, or the v4 multiLocalizedUnicodeType form when v4 is set.
+func testICCProfile(description string, v4 bool) []byte {
+	var tag bytes.Buffer
+	if v4 {
+		units := utf16.Encode([]rune(description))
+		tag.WriteString("mluc")
+		_ = binary.Write(&tag, binary.BigEndian, []uint32{0, 1, 12})
+		tag.WriteString("enUS")
+		_ = binary.Write(&tag, binary.BigEndian, []uint32{uint32(2 * len(units)), 28})
+		_ = binary.Write(&tag, binary.BigEndian, units)
+	} else {
+		tag.WriteString("desc")
+		_ = binary.Write(&tag, binary.BigEndian, []uint32{0, uint32(len(description) + 1)})
+		tag.WriteString(description + "\x00")
+	}
+
+	profile := make([]byte, 144, 144+tag.Len())
+	binary.BigEndian.PutUint32(profile[128:], 1)
+	copy(profile[132:], "desc")
+	binary.BigEndian.PutUint32(profile[136:], 144)
+	binary.BigEndian.PutUint32(profile[140:], uint32(tag.Len()))
+	profile = append(profile, tag.Bytes()...)
+	binary.BigEndian.PutUint32(profile[0:], uint32(len(profile)))
+	return profile
+}
+
+// testICCPNG returns a PNG with an iCCP chunk called name that holds profile.
+func testICCPNG(t *testing.T, name string, profile []byte) []byte {
+	t.Helper()
+	data := testColorPNG(t)
+	var compressed bytes.Buffer
+	w := zlib.NewWriter(&compressed)
+	if _, err := w.Write(profile); err != nil {
+		t.Fatal(err)
+	}
+	if err := w.Close(); err != nil {
+		t.Fatal(err)
+	}
+	body := append([]byte("iCCP"+name+"\x00\x00"), compressed.Bytes()...)
+	chunk := binary.BigEndian.AppendUint32(nil, uint32(len(body)-4))
+	chunk = append(chunk, body...)
+	chunk = binary.BigEndian.AppendUint32(chunk, crc32.ChecksumIEEE(body))
+
+	// The iCCP chunk goes right after the 8-byte signature and the 25-byte IHDR chunk.
+	const ihdrEnd = 8 + 25
+	return append(append(append([]byte(nil), data[:ihdrEnd]...), chunk...), data[ihdrEnd:]...)
+}
+
+// testICCJPEG returns a minimal JPEG whose ICC profile is split over APP2 segments of at
+// most segmentSize bytes, written in reverse order.
+func testICCJPEG(profile []byte, segmentSize int) []byte {
+	var parts [][]byte
+	for len(profile) > segmentSize {
+		parts = append(parts, profile[:segmentSize])
+		profile = profile[segmentSize:]
+	}
+	parts = append(parts, profile)
+
+	data := []byte{0xff, 0xd8}
+	for i := len(parts) - 1; i >= 0; i-- {
+		segment := append(append([]byte(nil), jpegICCMarker...), byte(i+1), byte(len(parts)))
+		segment = append(segment, parts[i]...)
+		data = append(data, 0xff, 0xe2)
+		data = binary.BigEndian.AppendUint16(data, uint16(len(segment)+2))
+		data = append(data, segment...)
+	}
+	return append(data, 0xff, 0xd9)
+}
+
+// testICCTIFF returns a one-entry little-endian TIFF whose first IFD carries profile.
+func testICCTIFF(profile []byte) []byte {
+	data := []byte("II*\x00")
+	data = binary.LittleEndian.AppendUint32(data, 8)
+	data = binary.LittleEndian.AppendUint16(data, 1)
+	data = binary.LittleEndian.AppendUint16(data, tiffTagICCProfile)
+	data = binary.LittleEndian.AppendUint16(data, 7)
+	data = binary.LittleEndian.AppendUint32(data, uint32(len(profile)))
+	data = binary.LittleEndian.AppendUint32(data, 8+2+12+4)
+	data = binary.LittleEndian.AppendUint32(data, 0)
+	return append(data, profile...)
+}
+
+func TestApplyColorProfiles(t *testing.T) {
+	srgb := testICCProfile("sRGB IEC61966-2.1", false)
+	fogra := testICCProfile("Coated FOGRA39", true)
+	unnamed := make([]byte, 132)
+	result := &ExtractionResult{Images: []ExtractedImage{
+		{Data: testICCPNG(t, "sRGB", srgb), Format: "png"},
+		{Data: testICCJPEG(fogra, 50), Format: "JPEG"},
+		{Data: testICCTIFF(srgb), Format: "tiff"},
+		{Data: testICCPNG(t, "Display P3", unnamed), Format: "png"},
+		{Data: testColorPNG(t), Format: "png"},
+		{Data: testICCPNG(t, "sRGB", srgb), Format: "png", IsMask: true},
+	}}
+
+	applyColorProfiles(result, &ExtractionConfig{Images: &ImageExtractionConfig{}})
+	for i, img := range result.Images {
+		if img.ICCProfile != nil || img.ColorProfileName != "" {
+			t.Fatalf("image %d got a profile without WithExtractColorProfiles: %q", i, img.ColorProfileName)
+		}
+	}
+
+	applyColorProfiles(result, NewExtractionConfig(WithImages(WithExtractColorProfiles(true))))
+	for i, want := range []struct {
+		profile []byte
+		name    string
+	}{
+		{srgb, "sRGB IEC61966-2.1"},
+		{fogra, "Coated FOGRA39"},
+		{srgb, "sRGB IEC61966-2.1"},
+		{unnamed, "Display P3"},
+		{nil, ""},
+		{nil, ""},
+	} {
+		img := result.Images[i]
+		if !bytes.Equal(img.ICCProfile, want.profile) || img.ColorProfileName != want.name {
+			t.Errorf("image %d: profile of %d bytes named %q, want %d bytes named %q",
+				i, len(img.ICCProfile), img.ColorProfileName, len(want.profile), want.name)
+		}
+	}
+}
+
+func TestJPEGICCProfileIncomplete(t *testing.T) {
+	data := testICCJPEG(testICCProfile("sRGB", false), 50)
+	// Drop the first APP2 segment written, the last part of the profile.
+	length := int(binary.BigEndian.Uint16(data[4:6]))
+	truncated := append([]byte{0xff, 0xd8}, data[4+length:]...)
+	if profile := jpegICCProfile(truncated); profile != nil {
+		t.Fatalf("expected no profile from incomplete segments, got %d bytes", len(profile))
+	}
+}
@@ -0,0 +1,136 @@
+package kreuzberg
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"strings"
+)
+
+// imageOCRMimeTypes maps extracted image formats to the MIME types accepted by the native
+// image extractor. Formats outside this set (e.g. raw PDF image streams) are not OCR'd.
+var imageOCRMimeTypes = map[string]string{
+	"png":  "image/png",
+	"jpg":  "image/jpeg",
+	"jpeg": "image/jpeg",
+	"tif":  "image/tiff",
+	"tiff": "image/tiff",
+	"bmp":  "image/bmp",
+	"gif":  "image/gif",
+	"webp": "image/webp",
+	"jp2":  "image/jp2",
+}
+
+// applyImageOCR fills ExtractedImage.OCRText when image OCR is enabled. Images that already
+// carry a native OCR result reuse its content; the others are OCR'd through the configured
+// OCR backend, all in one native batch call. Masks, images below MinImageDimension and
+// images that cannot be decoded keep an empty OCRText, as do images in which no text was
+// found.
+func applyImageOCR(ctx context.Context, result *ExtractionResult, config *ExtractionConfig) error {
+	return ocrImages(ctx, result, config, extractBytesNative, batchExtractBytesNative)
+}
+
+// ocrImages is applyImageOCR OCRing single images with extract and several at once with
+// extractBatch.
+func ocrImages(ctx context.Context, result *ExtractionResult, config *ExtractionConfig,
+	extract func(context.Context, []byte, string, *ExtractionConfig) (*ExtractionResult, error),
+	extractBatch func(context.Context, []BytesWithMime, *ExtractionConfig) ([]*ExtractionResult, error)) error {
+	images := config.Images
+	if images == nil || images.OCRImages == nil || !*images.OCRImages || len(result.Images) == 0 {
+		return nil
+	}
+
+	ocrConfig := &ExtractionConfig{
+		UseCache: config.UseCache,
+		OCR:      config.OCR,
+	}
+	if ocrConfig.OCR == nil {
+		ocrConfig.OCR = &OCRConfig{}
+	}
+	timeout := ocrPageTimeout(ocrConfig)
+
+	pageNumber := func(img *ExtractedImage) int {
+		if img.PageNumber != nil {
+			return *img.PageNumber
+		}
+		return 0
+	}
+	var pending []int
+	var items []BytesWithMime
+	for i := range result.Images {
+		img := &result.Images[i]
+		img.OCRText = ""
+
+		if img.IsMask || len(img.Data) == 0 || !imageMeetsMinDimension(img, images.MinImageDimension) {
+			continue
+		}
+		if img.OCRResult != nil {
+			img.OCRText = strings.TrimSpace(img.OCRResult.Content)
+			continue
+		}
+
+		mimeType, ok := imageOCRMimeTypes[strings.ToLower(img.Format)]
+		if !ok {
+			continue
+		}
+		data, mimeType, downsampled := downsampleForOCR(img.Data, mimeType, ocrConfig)
+		if downsampled != "" {
+			result.addWarning(WarningCodeImageDownsampled, pageNumber(img), fmt.Sprintf("image %d: %s", img.ImageIndex, downsampled))
+		}
+		pending = append(pending, i)
+		items = append(items, BytesWithMime{Data: data, MimeType: mimeType})
+	}
+	if len(pending) == 0 {
+		return nil
+	}
+	result.OCRUsed = true
+
+	// A batch cannot give up on one of its images, so images with a time budget are OCR'd
+	// one at a time, as are all of them when the batch call itself fails.
+	if len(pending) > 1 && timeout <= 0 {
+		results, err := extractBatch(ctx, items, ocrConfig)
+		if err == nil && len(results) == len(pending) {
+			for j, i := range pending {
+				if ocr := results[j]; ocr != nil && ocr.Metadata.Error == nil {
+					result.Images[i].OCRText = strings.TrimSpace(ocr.Content)
+				}
+			}
+			return nil
+		}
+		if ctxErr := ctx.Err(); ctxErr != nil {
+			return ctxErr
+		}
+	}
+
+	for j, i := range pending {
+		img := &result.Images[i]
+		ocr, err := extractWithTimeout(ctx, items[j].Data, items[j].MimeType, ocrConfig, timeout, extract)
+		if err != nil {
+			if ctxErr := ctx.Err(); ctxErr != nil {
+				return ctxErr
+			}
+			if errors.Is(err, errOCRPageTimeout) {
+				result.addWarning(WarningCodeOCRPageTimeout, pageNumber(img), fmt.Sprintf("OCR of image %d exceeded %s and was skipped", img.ImageIndex, timeout))
+			}
+			continue
+		}
+		img.OCRText = strings.TrimSpace(ocr.Content)
+	}
+	return nil
+}
+
+// imageMeetsMinDimension reports whether both known dimensions of img reach minDimension.
+// Unknown dimensions never disqualify an image.
+func imageMeetsMinDimension(img *ExtractedImage, minDimension *int) bool {
+	if minDimension == nil || *minDimension <= 0 {
+		return true
+	}
+	limit := uint32(*minDimension)
+	if img.Width != nil && *img.Width < limit {
+		return false
+	}
+	if img.Height != nil && *img.Height < limit {
+		return false
+	}
+	return true
+}
@@ -0,0 +1,162 @@
+package kreuzberg
+
+import (
+	"context"
+	"errors"
+	"strings"
+	"testing"
+)
+
+// TestImageOCRPopulatesOCRText extracts images from a PDF with image OCR enabled and
+// verifies that only images passing the dimension filter are OCR'd.
+func TestImageOCRPopulatesOCRText(t *testing.T) {
+	config := NewExtractionConfig(
+		WithImages(
+			WithExtractImages(true),
+			WithImageOCR(true),
+			WithMinImageDimension(64),
+		),
+		WithOCR(WithOCRBackend("tesseract")),
+	)
+
+	result, err := ExtractFileSync(getTestFilePath("pdf/with_images.pdf"), config)
+	if err != nil {
+		t.Skipf("image extraction with OCR unavailable: %v", err)
+	}
+	if len(result.Images) == 0 {
+		t.Skip("no images extracted from PDF")
+	}
+
+	for i, img := range result.Images {
+		small := (img.Width != nil && *img.Width < 64) || (img.Height != nil && *img.Height < 64)
+		if small && img.OCRText != "" {
+			t.Errorf("image %d below minimum dimension should not be OCR'd, got %q", i, img.OCRText)
+		}
+	}
+}
+
+// TestImageOCRDisabledLeavesImagesUntouched verifies that image OCR is opt-in.
+func TestImageOCRDisabledLeavesImagesUntouched(t *testing.T) {
+	result := &ExtractionResult{
+		Images: []ExtractedImage{{Data: []byte{1, 2, 3}, Format: "png", OCRText: "kept"}},
+	}
+	config := NewExtractionConfig(WithImages(WithExtractImages(true)))
+
+	if err := applyImageOCR(context.Background(), result, config); err != nil {
+		t.Fatalf("applyImageOCR failed: %v", err)
+	}
+	if result.Images[0].OCRText != "kept" {
+		t.Errorf("expected OCRText to be untouched, got %q", result.Images[0].OCRText)
+	}
+}
+
+// TestImageOCRReusesNativeOCRResult verifies that images the native pipeline already OCR'd
+// are not processed again, and that masks and tiny images are skipped.
+func TestImageOCRReusesNativeOCRResult(t *testing.T) {
+	result := &ExtractionResult{
+		Images: []ExtractedImage{
+			{Data: []byte{1}, Format: "png", OCRResult: &ExtractionResult{Content: "  slide text \n"}},
+			{Data: []byte{1}, Format: "png", IsMask: true, OCRText: "stale"},
+			{Data: []byte{1}, Format: "png", Width: Uint32Ptr(16), Height: Uint32Ptr(400), OCRText: "stale"},
+		},
+	}
+	config := NewExtractionConfig(WithImages(WithImageOCR(true), WithMinImageDimension(32)))
+
+	if err := applyImageOCR(context.Background(), result, config); err != nil {
+		t.Fatalf("applyImageOCR failed: %v", err)
+	}
+	if got := result.Images[0].OCRText; got != "slide text" {
+		t.Errorf("expected native OCR text to be reused, got %q", got)
+	}
+	if got := result.Images[1].OCRText; got != "" {
+		t.Errorf("expected mask OCRText to be empty, got %q", got)
+	}
+	if got := result.Images[2].OCRText; got != "" {
+		t.Errorf("expected undersized image OCRText to be empty, got %q", got)
+	}
+}
+
+// TestImageOCRBatchesImages verifies that the images to OCR go to the native library in one
+// batch call, and one at a time when the batch fails.
+func TestImageOCRBatchesImages(t *testing.T) {
+	newResult := func() *ExtractionResult {
+		return &ExtractionResult{Images: []ExtractedImage{
+			{Data: []byte("one"), Format: "png"},
+			{Data: []byte("skipped"), Format: "svg"},
+			{Data: []byte("two"), Format: "jpeg"},
+		}}
+	}
+	var singles, batches int
+	extract := func(_ context.Context, data []byte, _ string, _ *ExtractionConfig) (*ExtractionResult, error) {
+		singles++
+		return &ExtractionResult{Content: " " + strings.ToUpper(string(data)) + "\n"}, nil
+	}
+	extractBatch := func(ctx context.Context, items []BytesWithMime, config *ExtractionConfig) ([]*ExtractionResult, error) {
+		batches++
+		results := make([]*ExtractionResult, len(items))
+		for i, item := range items {
+			results[i] = &ExtractionResult{Content: strings.ToUpper(string(item.Data))}
+		}
+		return results, nil
+	}
+	config := NewExtractionConfig(WithImages(WithImageOCR(true)))
+
+	result := newResult()
+	if err := ocrImages(context.Background(), result, config, extract, extractBatch); err != nil {
+		t.Fatalf("ocrImages failed: %v", err)
+	}
+	if batches != 1 || singles != 0 || result.Images[0].OCRText != "ONE" || result.Images[1].OCRText != "" || result.Images[2].OCRText != "TWO" {
+		t.Fatalf("unexpected OCR: %d batches, %d single calls, images %+v", batches, singles, result.Images)
+	}
+
+	failing := func(context.Context, []BytesWithMime, *ExtractionConfig) ([]*ExtractionResult, error) {
+		return nil, errors.New("batch failed")
+	}
+	result = newResult()
+	if err := ocrImages(context.Background(), result, config, extract, failing); err != nil {
+		t.Fatalf("ocrImages failed: %v", err)
+	}
+	if singles != 2 || result.Images[0].OCRText != "ONE" || result.Images[2].OCRText != "TWO" {
+		t.Fatalf("expected a failed batch to be OCR'd image by image, got %d single calls, images %+v", singles, result.Images)
+	}
+}
+
+// TestImageOCRConfigNotSentToNative verifies that the image options applied by the Go
+// binding are left out of the native config.
+func TestImageOCRConfigNotSentToNative(t *testing.T) {
+	config := NewExtractionConfig(WithImages(WithExtractImages(true), WithImageOCR(true), WithMinImageDimension(32)))
+	data, err := encodeConfig(config)
+	if err != nil {
+		t.Fatalf("encodeConfig failed: %v", err)
+	}
+	if s := string(data); strings.Contains(s, "ocr_images") || strings.Contains(s, "min_image_dimension") || !strings.Contains(s, "extract_images") {
+		t.Fatalf("unexpected native config %s", s)
+	}
+	if config.Images.OCRImages == nil || config.Images.MinImageDimension == nil {
+		t.Fatal("encodeConfig changed the caller's config")
+	}
+}
+
+// TestImageMeetsMinDimension covers the dimension filter, including unknown dimensions.
+func TestImageMeetsMinDimension(t *testing.T) {
+	tests := []struct {
+		name   string
+		img    ExtractedImage
+		min    *int
+		expect bool
+	}{
+		{"no limit", ExtractedImage{Width: Uint32Ptr(1), Height: Uint32Ptr(1)}, nil, true},
+		{"unknown dimensions", ExtractedImage{}, IntPtr(100), true},
+		{"large enough", ExtractedImage{Width: Uint32Ptr(100), Height: Uint32Ptr(200)}, IntPtr(100), true},
+		{"too narrow", ExtractedImage{Width: Uint32Ptr(99), Height: Uint32Ptr(200)}, IntPtr(100), false},
+		{"too short", ExtractedImage{Width: Uint32Ptr(200), Height: Uint32Ptr(10)}, IntPtr(100), false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := imageMeetsMinDimension(&tt.img, tt.min); got != tt.expect {
+				t.Errorf("imageMeetsMinDimension() = %v, want %v", got, tt.expect)
+			}
+		})
+	}
+}
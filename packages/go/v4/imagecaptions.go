@@ -0,0 +1,35 @@
+package kreuzberg
+
+import (
+	"context"
+	"fmt"
+)
+
+// applyImageCaptions fills ExtractedImage.Caption with ImageExtractionConfig.Captioner.
+// Captioner errors become warnings; cancelling ctx stops between images.
+func applyImageCaptions(ctx context.Context, result *ExtractionResult, config *ExtractionConfig) error {
+	images := config.Images
+	if images == nil || images.Captioner == nil {
+		return nil
+	}
+	for i := range result.Images {
+		img := &result.Images[i]
+		if img.IsMask || len(img.Data) == 0 || !imageMeetsMinDimension(img, images.MinImageDimension) {
+			continue
+		}
+		if err := ctx.Err(); err != nil {
+			return err
+		}
+		caption, err := images.Captioner(img)
+		if err != nil {
+			pageNumber := 0
+			if img.PageNumber != nil {
+				pageNumber = *img.PageNumber
+			}
+			result.addWarning(WarningCodeImageCaptionFailed, pageNumber, fmt.Sprintf("captioning image %d failed: %v", img.ImageIndex, err))
+			continue
+		}
+		img.Caption = caption
+	}
+	return nil
+}
@@ -0,0 +1,47 @@
+package kreuzberg
+
+import (
+	"context"
+	"errors"
+	"testing"
+)
+
+func TestApplyImageCaptions(t *testing.T) {
+	page := 2
+	result := &ExtractionResult{Images: []ExtractedImage{
+		{Data: []byte("a"), Format: "png", ImageIndex: 0, Width: Uint32Ptr(200), Height: Uint32Ptr(100)},
+		{Data: []byte("b"), Format: "png", ImageIndex: 1, Width: Uint32Ptr(10), Height: Uint32Ptr(10)},
+		{Data: []byte("c"), Format: "png", ImageIndex: 2, IsMask: true},
+		{Data: []byte("d"), Format: "png", ImageIndex: 3, PageNumber: &page},
+	}}
+	var captioned []int
+	config := NewExtractionConfig(WithImages(
+		WithMinImageDimension(50),
+		WithImageCaptioner(func(img *ExtractedImage) (string, error) {
+			captioned = append(captioned, img.ImageIndex)
+			if img.ImageIndex == 3 {
+				return "", errors.New("model unavailable")
+			}
+			return "a chart", nil
+		}),
+	))
+	if err := applyImageCaptions(context.Background(), result, config); err != nil {
+		t.Fatalf("applyImageCaptions failed: %v", err)
+	}
+
+	if len(captioned) != 2 || captioned[0] != 0 || captioned[1] != 3 {
+		t.Fatalf("captioner called for images %v, want [0 3]", captioned)
+	}
+	if result.Images[0].Caption != "a chart" || result.Images[1].Caption != "" || result.Images[3].Caption != "" {
+		t.Fatalf("unexpected captions %+v", result.Images)
+	}
+	if len(result.Warnings) != 1 || result.Warnings[0].Code != WarningCodeImageCaptionFailed || result.Warnings[0].PageNumber != 2 {
+		t.Fatalf("unexpected warnings %+v", result.Warnings)
+	}
+
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+	if err := applyImageCaptions(ctx, result, config); !errors.Is(err, context.Canceled) {
+		t.Fatalf("expected context.Canceled, got %v", err)
+	}
+}
@@ -0,0 +1,113 @@
+package kreuzberg
+
+import (
+	"bytes"
+	"cmp"
+	"image"
+	"slices"
+	"strings"
+)
+
+const (
+	// imageColorSamples is the number of rows and of columns of pixels sampled per image.
+	imageColorSamples = 128
+	// imageDominantColors is the most colors reported per image.
+	imageDominantColors = 5
+	// imageMinColorShare is the share of the sampled pixels a color needs to be reported.
+	imageMinColorShare = 0.02
+	// imageGrayTolerance is the largest channel spread of a pixel still counted as gray,
+	// which absorbs the color noise of JPEG compression.
+	imageGrayTolerance = 24
+	// imageMaxColorShare is the share of colored pixels a grayscale image may contain.
+	imageMaxColorShare = 0.01
+)
+
+// applyImageColors fills ExtractedImage.IsGrayscale and ExtractedImage.DominantColors when
+// image color analysis is enabled. PNG, JPEG and GIF images are decoded and sampled on a
+// grid; images in other formats are only marked grayscale when their color space says so.
+func applyImageColors(result *ExtractionResult, config *ExtractionConfig) {
+	if config.Images == nil || config.Images.ColorAnalysis == nil || !*config.Images.ColorAnalysis {
+		return
+	}
+	for i := range result.Images {
+		img := &result.Images[i]
+		if img.IsMask || len(img.Data) == 0 {
+			continue
+		}
+		switch strings.ToLower(img.Format) {
+		case "png", "jpg", "jpeg", "gif":
+			decoded, _, err := image.Decode(bytes.NewReader(img.Data))
+			if err == nil {
+				gray, colors := analyzeImageColors(decoded)
+				img.IsGrayscale, img.DominantColors = &gray, colors
+				continue
+			}
+		}
+		if img.Colorspace != nil && strings.Contains(strings.ToLower(*img.Colorspace), "gray") {
+			gray := true
+			img.IsGrayscale = &gray
+		}
+	}
+}
+
+// analyzeImageColors reports whether img is grayscale and returns its dominant colors, most
+// common first. Colors are grouped into 16 levels per channel and each group is reported
+// as the average of its pixels. Transparent pixels are ignored.
+func analyzeImageColors(img image.Image) (bool, [][3]uint8) {
+	type bin struct {
+		count   int
+		r, g, b int
+		order   int
+	}
+	bounds := img.Bounds()
+	stepX := max(1, bounds.Dx()/imageColorSamples)
+	stepY := max(1, bounds.Dy()/imageColorSamples)
+
+	bins := make(map[int]*bin)
+	sampled, colored := 0, 0
+	for y := bounds.Min.Y; y < bounds.Max.Y; y += stepY {
+		for x := bounds.Min.X; x < bounds.Max.X; x += stepX {
+			r16, g16, b16, a16 := img.At(x, y).RGBA()
+			if a16 < 0x8000 {
+				continue
+			}
+			// Undo the alpha premultiplication of RGBA.
+			r, g, b := int(r16*0xff/a16), int(g16*0xff/a16), int(b16*0xff/a16)
+			sampled++
+			if max(r, g, b)-min(r, g, b) > imageGrayTolerance {
+				colored++
+			}
+			key := r>>4<<8 | g>>4<<4 | b>>4
+			entry := bins[key]
+			if entry == nil {
+				entry = &bin{order: len(bins)}
+				bins[key] = entry
+			}
+			entry.count++
+			entry.r += r
+			entry.g += g
+			entry.b += b
+		}
+	}
+	if sampled == 0 {
+		return false, nil
+	}
+
+	sorted := make([]*bin, 0, len(bins))
+	for _, entry := range bins {
+		sorted = append(sorted, entry)
+	}
+	slices.SortFunc(sorted, func(a, b *bin) int {
+		return cmp.Or(cmp.Compare(b.count, a.count), cmp.Compare(a.order, b.order))
+	})
+	var colors [][3]uint8
+	for _, entry := range sorted {
+		if len(colors) == imageDominantColors || (len(colors) > 0 && float64(entry.count) < imageMinColorShare*float64(sampled)) {
+			break
+		}
+		colors = append(colors, [3]uint8{
+			uint8(entry.r / entry.count), uint8(entry.g / entry.count), uint8(entry.b / entry.count),
+		})
+	}
+	return float64(colored) <= imageMaxColorShare*float64(sampled), colors
+}
@@ -0,0 +1,68 @@
+package kreuzberg
+
+import (
+	"bytes"
+	"image"
+	"image/color"
+	"image/png"
+	"slices"
+	"testing"
+)
+
+func testColorPNG(t *testing.T) []byte {
+	t.Helper()
+	img := image.NewRGBA(image.Rect(0, 0, 300, 100))
+	for y := range 100 {
+		for x := range 300 {
+			c := color.RGBA{R: 220, G: 20, B: 20, A: 255}
+			if x >= 200 {
+				c = color.RGBA{R: 20, G: 20, B: 220, A: 255}
+			}
+			img.Set(x, y, c)
+		}
+	}
+	var buf bytes.Buffer
+	if err := png.Encode(&buf, img); err != nil {
+		t.Fatal(err)
+	}
+	return buf.Bytes()
+}
+
+func TestApplyImageColors(t *testing.T) {
+	gray, _, _ := testBarcodePNG(t, []int{2, 1, 2, 1, 3}, 4, false)
+	grayspace := "DeviceGray"
+	result := &ExtractionResult{Images: []ExtractedImage{
+		{Data: gray, Format: "png"},
+		{Data: testColorPNG(t), Format: "PNG"},
+		{Data: []byte("raw"), Format: "jbig2", Colorspace: &grayspace},
+		{Data: []byte("raw"), Format: "jp2"},
+	}}
+
+	applyImageColors(result, &ExtractionConfig{})
+	for i, img := range result.Images {
+		if img.IsGrayscale != nil || img.DominantColors != nil {
+			t.Fatalf("image %d analyzed without color analysis: %+v", i, img)
+		}
+	}
+
+	applyImageColors(result, &ExtractionConfig{Images: &ImageExtractionConfig{ColorAnalysis: BoolPtr(true)}})
+	images := result.Images
+	if images[0].IsGrayscale == nil || !*images[0].IsGrayscale {
+		t.Fatalf("expected the barcode to be grayscale, got %+v", images[0].IsGrayscale)
+	}
+	if want := [][3]uint8{{255, 255, 255}, {20, 20, 20}}; !slices.Equal(images[0].DominantColors, want) {
+		t.Fatalf("barcode colors = %v, want %v", images[0].DominantColors, want)
+	}
+	if images[1].IsGrayscale == nil || *images[1].IsGrayscale {
+		t.Fatalf("expected the colored image not to be grayscale, got %+v", images[1].IsGrayscale)
+	}
+	if want := [][3]uint8{{220, 20, 20}, {20, 20, 220}}; !slices.Equal(images[1].DominantColors, want) {
+		t.Fatalf("colored image colors = %v, want %v", images[1].DominantColors, want)
+	}
+	if images[2].IsGrayscale == nil || !*images[2].IsGrayscale || images[2].DominantColors != nil {
+		t.Fatalf("expected the gray colorspace to mark the image grayscale, got %+v", images[2])
+	}
+	if images[3].IsGrayscale != nil || images[3].DominantColors != nil {
+		t.Fatalf("expected an undecodable image without colorspace to stay unset, got %+v", images[3])
+	}
+}
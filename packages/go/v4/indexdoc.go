@@ -0,0 +1,122 @@
+package kreuzberg
+
+import (
+	"encoding/json"
+	"strings"
+)
+
+// IndexDocument returns the fields of the result a minimal search index needs, ready to
+// send to Elasticsearch or Meilisearch. Every key is present, with a zero value when the
+// document does not provide it:
+//
+//   - "content" (string): Content.
+//   - "title" (string): the title from the format metadata of PDF, HTML and PPTX documents,
+//     or the subject of emails.
+//   - "author" (string): the authors from the format metadata, joined with ", ", or the
+//     sender of emails.
+//   - "language" (string): the primary language, as GetDetectedLanguage returns it.
+//   - "page_count" (int): the number of pages, slides or sheets, as GetPageCount returns it,
+//     or the page count of the PDF metadata.
+//   - "keywords" ([]string): the keywords of the document metadata followed by those found
+//     by keyword extraction (see WithKeywords), without duplicates.
+//   - "table_count" (int): the number of tables.
+//
+// A nil result returns nil.
+func (r *ExtractionResult) IndexDocument() map[string]any {
+	if r == nil {
+		return nil
+	}
+	title, author := r.indexTitleAuthor()
+	language, _ := r.GetDetectedLanguage()
+	return map[string]any{
+		"content":     r.Content,
+		"title":       title,
+		"author":      author,
+		"language":    language,
+		"page_count":  r.indexPageCount(),
+		"keywords":    r.indexKeywords(),
+		"table_count": len(r.Tables),
+	}
+}
+
+// indexPageCount returns the page count of the page structure, or of the PDF metadata when
+// the result has none.
+func (r *ExtractionResult) indexPageCount() int {
+	pageCount, _ := r.GetPageCount()
+	if pdf, ok := r.Metadata.PdfMetadata(); ok && pageCount == 0 && pdf.PageCount != nil {
+		pageCount = *pdf.PageCount
+	}
+	return pageCount
+}
+
+// indexTitleAuthor returns the title and author of the document from its format metadata.
+func (r *ExtractionResult) indexTitleAuthor() (string, string) {
+	deref := func(s *string) string {
+		if s == nil {
+			return ""
+		}
+		return strings.TrimSpace(*s)
+	}
+	m := r.Metadata
+	if pdf, ok := m.PdfMetadata(); ok {
+		return deref(pdf.Title), strings.Join(pdf.Authors, ", ")
+	}
+	if html, ok := m.HTMLMetadata(); ok {
+		return deref(html.Title), deref(html.Author)
+	}
+	if pptx, ok := m.PptxMetadata(); ok {
+		return deref(pptx.Title), deref(pptx.Author)
+	}
+	if email, ok := m.EmailMetadata(); ok {
+		author := deref(email.FromName)
+		if author == "" {
+			author = deref(email.FromEmail)
+		}
+		return deref(m.Subject), author
+	}
+	return "", ""
+}
+
+// indexKeywords returns the keywords of the document metadata and those of keyword
+// extraction, which the native library stores in the "keywords" metadata field as strings
+// or as objects with a "text" field.
+func (r *ExtractionResult) indexKeywords() []string {
+	keywords := []string{}
+	seen := make(map[string]bool)
+	add := func(keyword string) {
+		keyword = strings.TrimSpace(keyword)
+		if keyword != "" && !seen[strings.ToLower(keyword)] {
+			seen[strings.ToLower(keyword)] = true
+			keywords = append(keywords, keyword)
+		}
+	}
+	if pdf, ok := r.Metadata.PdfMetadata(); ok {
+		for _, keyword := range pdf.Keywords {
+			add(keyword)
+		}
+	}
+	if html, ok := r.Metadata.HTMLMetadata(); ok {
+		for _, keyword := range html.Keywords {
+			add(keyword)
+		}
+	}
+	if raw, ok := r.Metadata.Additional["keywords"]; ok {
+		var entries []json.RawMessage
+		if json.Unmarshal(raw, &entries) == nil {
+			for _, entry := range entries {
+				var text string
+				if json.Unmarshal(entry, &text) != nil {
+					var keyword struct {
+						Text string `json:"text"`
+					}
+					if json.Unmarshal(entry, &keyword) != nil {
+						continue
+					}
+					text = keyword.Text
+				}
+				add(text)
+			}
+		}
+	}
+	return keywords
+}
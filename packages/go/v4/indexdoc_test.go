@@ -0,0 +1,55 @@
+package kreuzberg
+
+import (
+	"encoding/json"
+	"slices"
+	"testing"
+)
+
+func TestIndexDocument(t *testing.T) {
+	title, pages := "Annual Report", 12
+	result := &ExtractionResult{
+		Content:           "Revenue grew.",
+		DetectedLanguages: []string{"en"},
+		Tables:            []Table{{}, {}},
+		Metadata: Metadata{
+			Format: FormatMetadata{Type: FormatPDF, Pdf: &PdfMetadata{
+				Title: &title, Authors: []string{"Ada", "Grace"}, Keywords: []string{"finance"}, PageCount: &pages,
+			}},
+			Additional: map[string]json.RawMessage{
+				"keywords": json.RawMessage(`[{"text":"revenue","score":0.9},{"text":"Finance","score":0.5}]`),
+			},
+		},
+	}
+	doc := result.IndexDocument()
+	want := map[string]any{
+		"content":     "Revenue grew.",
+		"title":       "Annual Report",
+		"author":      "Ada, Grace",
+		"language":    "en",
+		"page_count":  12,
+		"table_count": 2,
+	}
+	for key, value := range want {
+		if doc[key] != value {
+			t.Errorf("%s = %v, want %v", key, doc[key], value)
+		}
+	}
+	if keywords := doc["keywords"].([]string); !slices.Equal(keywords, []string{"finance", "revenue"}) {
+		t.Errorf("keywords = %q", keywords)
+	}
+}
+
+func TestIndexDocumentEmpty(t *testing.T) {
+	var result *ExtractionResult
+	if result.IndexDocument() != nil {
+		t.Fatal("expected nil for a nil result")
+	}
+	doc := (&ExtractionResult{Content: "plain"}).IndexDocument()
+	if len(doc) != 7 || doc["title"] != "" || doc["page_count"] != 0 || len(doc["keywords"].([]string)) != 0 {
+		t.Fatalf("unexpected document %v", doc)
+	}
+	if _, err := json.Marshal(doc); err != nil {
+		t.Fatalf("document does not marshal: %v", err)
+	}
+}
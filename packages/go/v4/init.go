@@ -0,0 +1,174 @@
+package kreuzberg
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"fmt"
+	"image"
+	"image/png"
+	"os"
+	"strconv"
+	"strings"
+	"sync"
+)
+
+// initMu serializes Init calls; initDone is set once an Init call has succeeded.
+var (
+	initMu   sync.Mutex
+	initDone bool
+)
+
+// InitOption configures Init.
+type InitOption func(*initOptions)
+
+type initOptions struct {
+	threads int
+	config  *ExtractionConfig
+}
+
+// WithInitThreads sets the number of threads of the native worker pool, which OCR and
+// token reduction use, by setting RAYON_NUM_THREADS. The pool is created on first use, so
+// this has an effect only when Init runs before any extraction. Values below 1 are ignored.
+func WithInitThreads(n int) InitOption {
+	return func(o *initOptions) {
+		if n > 0 {
+			o.threads = n
+		}
+	}
+}
+
+// WithInitConfig sets the config the warm-up extractions of Init use. When it configures
+// OCR, Init also OCRs a small image, which loads the OCR backend and its language data and
+// downloads missing languages when OCRConfig.AutoDownloadLanguages is enabled. The default
+// is the default config (see SetDefaultConfig).
+func WithInitConfig(config *ExtractionConfig) InitOption {
+	return func(o *initOptions) {
+		o.config = config
+	}
+}
+
+// Init initializes the native library eagerly, so that servers pay the one-time costs at
+// startup instead of on the first request: it extracts a small PDF, which loads PDFium and
+// sets up the extractor registries, and with an OCR config also a small image. Extraction
+// works without Init, initializing the same things lazily. WarmupOCR preloads the models of
+// specific OCR languages.
+//
+// Init is safe to call concurrently. Once a call has succeeded, later calls return nil
+// without doing anything and their options are ignored; after a failure the next call
+// tries again.
+func Init(opts ...InitOption) error {
+	initMu.Lock()
+	defer initMu.Unlock()
+	if initDone {
+		return nil
+	}
+
+	options := initOptions{}
+	for _, opt := range opts {
+		if opt != nil {
+			opt(&options)
+		}
+	}
+	if options.threads > 0 {
+		if err := os.Setenv("RAYON_NUM_THREADS", strconv.Itoa(options.threads)); err != nil {
+			return newRuntimeErrorWithContext("failed to set RAYON_NUM_THREADS", err, ErrorCodeInternal, nil)
+		}
+	}
+
+	config := configOrDefault(options.config)
+	if config == nil {
+		config = &ExtractionConfig{}
+	}
+	warmup := cloneConfig(config)
+	warmup.UseCache = BoolPtr(false)
+	if _, err := ExtractBytesSync(selfTestPDF, "application/pdf", withoutOCR(warmup)); err != nil {
+		return newRuntimeErrorWithContext("init PDF extraction failed", err, ErrorCodeInternal, nil)
+	}
+	if warmup.OCR != nil {
+		if _, err := ExtractBytesSync(initOCRImage(), "image/png", warmup); err != nil {
+			return newRuntimeErrorWithContext(fmt.Sprintf("init OCR with backend %q failed", ocrBackendName(warmup.OCR)), err, ErrorCodeInternal, nil)
+		}
+	}
+	initDone = true
+	return nil
+}
+
+// withoutOCR returns a copy of config without OCR, so that the text PDF is never OCR'd.
+func withoutOCR(config *ExtractionConfig) *ExtractionConfig {
+	copied := *config
+	copied.OCR = nil
+	copied.encoded = nil
+	return &copied
+}
+
+// initOCRImage returns a small white PNG for the OCR warm-up.
+func initOCRImage() []byte {
+	img := image.NewGray(image.Rect(0, 0, 64, 32))
+	for i := range img.Pix {
+		img.Pix[i] = 0xff
+	}
+	var buf bytes.Buffer
+	_ = png.Encode(&buf, img)
+	return buf.Bytes()
+}
+
+func ocrBackendName(ocr *OCRConfig) string {
+	if ocr == nil || ocr.Backend == "" {
+		return "tesseract"
+	}
+	return ocr.Backend
+}
+
+// WarmupOCR loads the Tesseract models of languages, such as "eng" or "deu", so that the
+// first OCR request does not pay for it; without languages, those of the default config's
+// OCR settings are loaded, or "eng". Language data is looked up and downloaded as for an
+// extraction with the default config's OCR settings, so missing languages are fetched when
+// OCRConfig.AutoDownloadLanguages is enabled there. Languages that are still unavailable
+// return one *MissingDependencyError each, joined with errors.Join, before anything is
+// loaded. ctx cancels downloads and the warm-up OCR.
+func WarmupOCR(ctx context.Context, languages ...string) error {
+	if err := ctx.Err(); err != nil {
+		return err
+	}
+	ocr := OCRConfig{}
+	if config := configOrDefault(nil); config != nil && config.OCR != nil {
+		ocr = *config.OCR
+	}
+	ocr.Backend = "tesseract"
+	if len(languages) > 0 {
+		spec := strings.Join(languages, "+")
+		ocr.Language = &spec
+		if ocr.Tesseract != nil {
+			tesseract := *ocr.Tesseract
+			tesseract.Language = spec
+			ocr.Tesseract = &tesseract
+		}
+	}
+	languages, err := tesseractLanguages(&ocr)
+	if err != nil {
+		return err
+	}
+	config, _, err := ensureOCRLanguages(ctx, &ExtractionConfig{OCR: &ocr, UseCache: BoolPtr(false)})
+	if err != nil {
+		return err
+	}
+
+	var missing []error
+	for _, language := range languages {
+		if !tessdataInstalled(config.OCR, language) {
+			missing = append(missing, newMissingDependencyErrorWithContext("tesseract-"+language,
+				fmt.Sprintf("Tesseract language data for %q is not installed", language), nil, ErrorCodeMissingDependency, nil))
+		}
+	}
+	if len(missing) > 0 {
+		return errors.Join(missing...)
+	}
+	if _, err := ExtractBytesWithContext(ctx, initOCRImage(), "image/png", config); err != nil {
+		if ctxErr := ctx.Err(); ctxErr != nil {
+			return ctxErr
+		}
+		return newRuntimeErrorWithContext(fmt.Sprintf("OCR warm-up for %q failed", strings.Join(languages, "+")), err, ErrorCodeInternal, nil)
+	}
+	return nil
+}
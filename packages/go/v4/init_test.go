@@ -0,0 +1,75 @@
+package kreuzberg
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"image/png"
+	"os"
+	"path/filepath"
+	"sync"
+	"testing"
+)
+
+func TestInitOCRImage(t *testing.T) {
+	img, err := png.Decode(bytes.NewReader(initOCRImage()))
+	if err != nil {
+		t.Fatalf("initOCRImage is not a valid PNG: %v", err)
+	}
+	if bounds := img.Bounds(); bounds.Dx() != 64 || bounds.Dy() != 32 {
+		t.Fatalf("unexpected image size %v", bounds)
+	}
+}
+
+func TestInit(t *testing.T) {
+	if _, err := ExtractBytesSync([]byte("probe"), "text/plain", nil); err != nil {
+		t.Skipf("native library unavailable: %v", err)
+	}
+	t.Setenv("RAYON_NUM_THREADS", os.Getenv("RAYON_NUM_THREADS"))
+
+	var wg sync.WaitGroup
+	errs := make([]error, 8)
+	for i := range errs {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			errs[i] = Init(WithInitThreads(2))
+		}()
+	}
+	wg.Wait()
+	for i, err := range errs {
+		if err != nil {
+			t.Fatalf("Init call %d failed: %v", i, err)
+		}
+	}
+
+	// Later calls do nothing, so options that would fail are ignored.
+	if err := Init(WithInitConfig(NewExtractionConfig(WithOCR(WithOCRBackend("no-such-backend"))))); err != nil {
+		t.Fatalf("expected Init to be idempotent, got %v", err)
+	}
+}
+
+func TestWarmupOCRMissingLanguage(t *testing.T) {
+	dir := t.TempDir()
+	t.Setenv("TESSDATA_PREFIX", dir)
+	if err := os.WriteFile(filepath.Join(dir, "eng.traineddata"), []byte("model"), 0o600); err != nil {
+		t.Fatal(err)
+	}
+
+	err := WarmupOCR(context.Background(), "eng", "zzq")
+	var missing *MissingDependencyError
+	if !errors.As(err, &missing) || missing.Dependency != "tesseract-zzq" {
+		t.Fatalf("expected MissingDependencyError for zzq, got %v", err)
+	}
+
+	var valErr *ValidationError
+	if err := WarmupOCR(context.Background(), "../eng"); !errors.As(err, &valErr) {
+		t.Fatalf("expected ValidationError for an invalid language, got %v", err)
+	}
+
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+	if err := WarmupOCR(ctx, "eng"); !errors.Is(err, context.Canceled) {
+		t.Fatalf("expected context.Canceled, got %v", err)
+	}
+}
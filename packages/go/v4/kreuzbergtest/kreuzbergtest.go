@@ -0,0 +1,129 @@
+// Package kreuzbergtest provides a fake kreuzberg.Extractor for unit tests of code
+// that extracts documents, so those tests need neither real documents nor the native
+// library at run time.
+//
+//	fake := kreuzbergtest.NewExtractor().
+//		OnFile("invoice.pdf", &kreuzberg.ExtractionResult{Content: "Total: 42", Success: true}, nil).
+//		OnBytes("text/plain", nil, errors.New("unsupported"))
+//	svc := NewService(fake) // svc depends on kreuzberg.Extractor
+package kreuzbergtest
+
+import (
+	"context"
+	"fmt"
+	"sync"
+
+	"github.com/kreuzberg-dev/kreuzberg/packages/go/v4"
+)
+
+// Call records one call made to an Extractor. Path is set for file extractions; MimeType
+// and Data for in-memory ones.
+type Call struct {
+	Path     string
+	MimeType string
+	Data     []byte
+}
+
+type response struct {
+	result *kreuzberg.ExtractionResult
+	err    error
+}
+
+// Extractor is a kreuzberg.Extractor returning canned responses. File extractions answer
+// with the response registered for the path, in-memory ones with the one registered for the
+// MIME type, and both fall back to the response set with Default. Without a matching
+// response the call fails. The same result pointer is returned on every matching call.
+//
+// An Extractor is safe for concurrent use.
+type Extractor struct {
+	mu       sync.Mutex
+	files    map[string]response
+	bytes    map[string]response
+	fallback *response
+	calls    []Call
+}
+
+var _ kreuzberg.Extractor = (*Extractor)(nil)
+
+// NewExtractor returns an Extractor with no responses registered.
+func NewExtractor() *Extractor {
+	return &Extractor{files: make(map[string]response), bytes: make(map[string]response)}
+}
+
+// OnFile makes the extraction of the file at path return result and err.
+func (e *Extractor) OnFile(path string, result *kreuzberg.ExtractionResult, err error) *Extractor {
+	e.mu.Lock()
+	defer e.mu.Unlock()
+	e.files[path] = response{result: result, err: err}
+	return e
+}
+
+// OnBytes makes the extraction of in-memory documents of mimeType return result and err.
+func (e *Extractor) OnBytes(mimeType string, result *kreuzberg.ExtractionResult, err error) *Extractor {
+	e.mu.Lock()
+	defer e.mu.Unlock()
+	e.bytes[mimeType] = response{result: result, err: err}
+	return e
+}
+
+// Default sets the response for calls that match no path or MIME type.
+func (e *Extractor) Default(result *kreuzberg.ExtractionResult, err error) *Extractor {
+	e.mu.Lock()
+	defer e.mu.Unlock()
+	e.fallback = &response{result: result, err: err}
+	return e
+}
+
+// ExtractFile returns the response registered for path.
+func (e *Extractor) ExtractFile(path string) (*kreuzberg.ExtractionResult, error) {
+	return e.ExtractFileWithContext(context.Background(), path)
+}
+
+// ExtractBytes returns the response registered for mimeType.
+func (e *Extractor) ExtractBytes(data []byte, mimeType string) (*kreuzberg.ExtractionResult, error) {
+	return e.ExtractBytesWithContext(context.Background(), data, mimeType)
+}
+
+// ExtractFileWithContext returns the response registered for path. It returns ctx.Err()
+// when ctx is already done.
+func (e *Extractor) ExtractFileWithContext(ctx context.Context, path string) (*kreuzberg.ExtractionResult, error) {
+	e.mu.Lock()
+	defer e.mu.Unlock()
+	e.calls = append(e.calls, Call{Path: path})
+	if err := ctx.Err(); err != nil {
+		return nil, err
+	}
+	if r, ok := e.files[path]; ok {
+		return r.result, r.err
+	}
+	return e.defaultResponse(fmt.Sprintf("file %q", path))
+}
+
+// ExtractBytesWithContext returns the response registered for mimeType. It returns
+// ctx.Err() when ctx is already done.
+func (e *Extractor) ExtractBytesWithContext(ctx context.Context, data []byte, mimeType string) (*kreuzberg.ExtractionResult, error) {
+	e.mu.Lock()
+	defer e.mu.Unlock()
+	e.calls = append(e.calls, Call{MimeType: mimeType, Data: data})
+	if err := ctx.Err(); err != nil {
+		return nil, err
+	}
+	if r, ok := e.bytes[mimeType]; ok {
+		return r.result, r.err
+	}
+	return e.defaultResponse(fmt.Sprintf("%s document", mimeType))
+}
+
+func (e *Extractor) defaultResponse(what string) (*kreuzberg.ExtractionResult, error) {
+	if e.fallback != nil {
+		return e.fallback.result, e.fallback.err
+	}
+	return nil, fmt.Errorf("kreuzbergtest: no response registered for %s", what)
+}
+
+// Calls returns the calls made so far, in order.
+func (e *Extractor) Calls() []Call {
+	e.mu.Lock()
+	defer e.mu.Unlock()
+	return append([]Call(nil), e.calls...)
+}
@@ -0,0 +1,67 @@
+package kreuzbergtest_test
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	"github.com/kreuzberg-dev/kreuzberg/packages/go/v4"
+	"github.com/kreuzberg-dev/kreuzberg/packages/go/v4/kreuzbergtest"
+)
+
+// wordCount stands in for service code that depends on the interface.
+func wordCount(ctx context.Context, x kreuzberg.Extractor, path string) (int, error) {
+	result, err := x.ExtractFileWithContext(ctx, path)
+	if err != nil {
+		return 0, err
+	}
+	words := 0
+	inWord := false
+	for _, c := range result.Content {
+		if c == ' ' || c == '\n' {
+			inWord = false
+		} else if !inWord {
+			inWord = true
+			words++
+		}
+	}
+	return words, nil
+}
+
+func TestExtractor(t *testing.T) {
+	broken := errors.New("broken")
+	fake := kreuzbergtest.NewExtractor().
+		OnFile("a.pdf", &kreuzberg.ExtractionResult{Content: "three small words", Success: true}, nil).
+		OnFile("b.pdf", nil, broken).
+		OnBytes("text/plain", &kreuzberg.ExtractionResult{Content: "bytes"}, nil)
+	ctx := context.Background()
+
+	if n, err := wordCount(ctx, fake, "a.pdf"); err != nil || n != 3 {
+		t.Fatalf("wordCount = %d, %v", n, err)
+	}
+	if _, err := wordCount(ctx, fake, "b.pdf"); !errors.Is(err, broken) {
+		t.Fatalf("expected the canned error, got %v", err)
+	}
+	if _, err := fake.ExtractFile("missing.pdf"); err == nil {
+		t.Fatal("expected an error for an unregistered path")
+	}
+	if result, err := fake.ExtractBytes([]byte("x"), "text/plain"); err != nil || result.Content != "bytes" {
+		t.Fatalf("ExtractBytes = %+v, %v", result, err)
+	}
+
+	fake.Default(&kreuzberg.ExtractionResult{Content: "default"}, nil)
+	if result, err := fake.ExtractBytesWithContext(ctx, nil, "image/png"); err != nil || result.Content != "default" {
+		t.Fatalf("default response = %+v, %v", result, err)
+	}
+
+	cancelled, cancel := context.WithCancel(ctx)
+	cancel()
+	if _, err := fake.ExtractFileWithContext(cancelled, "a.pdf"); !errors.Is(err, context.Canceled) {
+		t.Fatalf("expected context.Canceled, got %v", err)
+	}
+
+	calls := fake.Calls()
+	if len(calls) != 6 || calls[0].Path != "a.pdf" || calls[3].MimeType != "text/plain" || string(calls[3].Data) != "x" {
+		t.Fatalf("unexpected calls %+v", calls)
+	}
+}
@@ -0,0 +1,168 @@
+package kreuzberg
+
+import (
+	"fmt"
+	"strings"
+)
+
+// PDFLayer is an optional content group of a PDF: a layer viewers can show or hide, such as
+// the dimension or geometry layer of an engineering drawing.
+type PDFLayer struct {
+	Name string `json:"name"`
+	// Visible is whether the layer is shown when the document is opened, according to the
+	// default configuration of the document.
+	Visible bool `json:"visible"`
+}
+
+// pdfLayerFilter selects the optional content read by the raw text reader. selected holds
+// every layer of the document, true for the selected ones.
+type pdfLayerFilter struct {
+	selected map[pdfRef]bool
+}
+
+func layerSelectionEnabled(config *ExtractionConfig) bool {
+	return config != nil && len(config.LayerSelection) > 0
+}
+
+// applyLayers fills result.Layers for PDFs when layers are selected or structured output is
+// enabled, and warns about selected layers the document does not have and selections that
+// could not be applied. Encrypted documents are skipped.
+func applyLayers(result *ExtractionResult, config *ExtractionConfig, src *documentSource) error {
+	selecting := layerSelectionEnabled(config)
+	if !selecting && (config.StructuredOutput == nil || !*config.StructuredOutput) || !isPDFMimeType(result.MimeType) {
+		return nil
+	}
+	doc, err := src.pdfDocument()
+	if err != nil {
+		return err
+	}
+	if doc.encrypted {
+		return nil
+	}
+	layers, _ := doc.pdfLayers()
+	result.Layers = append(result.Layers, layers...)
+	if !selecting || len(layers) == 0 {
+		return nil
+	}
+	for _, name := range config.LayerSelection {
+		found := false
+		for _, layer := range layers {
+			found = found || layerNameMatches(layer.Name, name)
+		}
+		if !found {
+			result.addWarning(WarningCodeLayerNotFound, 0, fmt.Sprintf("document has no layer named %q", name))
+		}
+	}
+	if result.Pipeline == nil || result.Pipeline.Extractor != ExtractorRawText {
+		result.addWarning(WarningCodeLayerSelectionIgnored, 0,
+			"the text of the document could not be read layer by layer, so the content of every layer was extracted")
+	}
+	return nil
+}
+
+// pdfLayers returns the layers of the document in the order of its OCProperties and the
+// reference to each layer's group.
+func (d *pdfDocument) pdfLayers() ([]PDFLayer, []pdfRef) {
+	properties := d.dict(d.catalog()["OCProperties"])
+	if properties == nil {
+		return nil, nil
+	}
+	defaults := d.dict(properties["D"])
+	listed := func(key string) map[pdfRef]bool {
+		set := make(map[pdfRef]bool)
+		for _, value := range d.array(defaults[key]) {
+			if ref, ok := value.(pdfRef); ok {
+				set[ref] = true
+			}
+		}
+		return set
+	}
+	on, off := listed("ON"), listed("OFF")
+	baseOff := defaults.name("BaseState") == "OFF"
+
+	var layers []PDFLayer
+	var refs []pdfRef
+	for _, value := range d.array(properties["OCGs"]) {
+		group := d.dict(value)
+		if group == nil {
+			continue
+		}
+		ref, _ := value.(pdfRef)
+		name, _ := d.resolve(group["Name"]).(string)
+		visible := !off[ref]
+		if baseOff {
+			visible = on[ref]
+		}
+		layers = append(layers, PDFLayer{Name: name, Visible: visible})
+		refs = append(refs, ref)
+	}
+	return layers, refs
+}
+
+// layerFilter returns the filter selecting the layers named in names, or nil when the
+// document has no layers.
+func (d *pdfDocument) layerFilter(names []string) *pdfLayerFilter {
+	layers, refs := d.pdfLayers()
+	if len(layers) == 0 {
+		return nil
+	}
+	filter := &pdfLayerFilter{selected: make(map[pdfRef]bool, len(layers))}
+	for i, layer := range layers {
+		for _, name := range names {
+			filter.selected[refs[i]] = filter.selected[refs[i]] || layerNameMatches(layer.Name, name)
+		}
+	}
+	return filter
+}
+
+// layerNameMatches reports whether name selects the layer called layer. Names are compared
+// ignoring case and surrounding whitespace.
+func layerNameMatches(layer, name string) bool {
+	return strings.EqualFold(strings.TrimSpace(layer), strings.TrimSpace(name))
+}
+
+// visible reports whether content marked as belonging to group, an optional content group
+// or membership dictionary, is read. Content of groups that are not layers of the document
+// is read, and a nil filter reads everything.
+func (f *pdfLayerFilter) visible(d *pdfDocument, group any) bool {
+	if f == nil || group == nil {
+		return true
+	}
+	if ref, ok := group.(pdfRef); ok {
+		if selected, known := f.selected[ref]; known {
+			return selected
+		}
+	}
+	membership := d.dict(group)
+	if membership.name("Type") != "OCMD" {
+		return true
+	}
+	members := d.array(membership["OCGs"])
+	if members == nil && membership["OCGs"] != nil {
+		members = pdfArray{membership["OCGs"]}
+	}
+	anyOn, allOn, known := false, true, false
+	for _, member := range members {
+		ref, _ := member.(pdfRef)
+		selected, ok := f.selected[ref]
+		if !ok {
+			continue
+		}
+		known = true
+		anyOn = anyOn || selected
+		allOn = allOn && selected
+	}
+	if !known {
+		return true
+	}
+	switch membership.name("P") {
+	case "AllOn":
+		return allOn
+	case "AnyOff":
+		return !allOn
+	case "AllOff":
+		return !anyOn
+	default:
+		return anyOn
+	}
+}
@@ -0,0 +1,92 @@
+package kreuzberg
+
+import (
+	"reflect"
+	"testing"
+)
+
+func testLayersPDF() []byte {
+	return buildTestPDF(
+		`<< /Type /Catalog /Pages 2 0 R /OCProperties << /OCGs [5 0 R 6 0 R] /D << /OFF [6 0 R] >> >> >>`,
+		`<< /Type /Pages /Kids [3 0 R] /Count 1 >>`,
+		`<< /Type /Page /Parent 2 0 R /Contents 4 0 R /Resources << /Font << /F1 8 0 R >> /XObject << /X1 9 0 R >>
+/Properties << /L1 5 0 R /L2 6 0 R /M1 7 0 R >> >> >>`,
+		testPDFStream("BT /F1 12 Tf 72 700 Td (Title) Tj ET\n"+
+			"/OC /L1 BDC BT /F1 12 Tf 72 680 Td (Geometry) Tj ET EMC\n"+
+			"/OC /L2 BDC BT /F1 12 Tf 72 660 Td (Dimensions) Tj ET EMC\n"+
+			"/OC /M1 BDC BT /F1 12 Tf 72 640 Td (Both) Tj ET EMC\n"+
+			"/Span << /ActualText (x) >> BDC BT /F1 12 Tf 72 620 Td (Note) Tj ET EMC\n/X1 Do", false),
+		`<< /Type /OCG /Name (Geometry) >>`,
+		`<< /Type /OCG /Name (Dimensions) >>`,
+		`<< /Type /OCMD /OCGs [5 0 R 6 0 R] /P /AllOn >>`,
+		`<< /Type /Font /Subtype /Type1 /BaseFont /Helvetica >>`,
+		`<< /Type /XObject /Subtype /Form /BBox [0 0 612 792] /OC 6 0 R /Length 40 >>
+stream
+BT /F1 12 Tf 72 100 Td (Tolerance) Tj ET
+endstream`,
+	)
+}
+
+func TestPDFLayers(t *testing.T) {
+	doc := mustParsePDF(t, testLayersPDF())
+	layers, _ := doc.pdfLayers()
+	want := []PDFLayer{{Name: "Geometry", Visible: true}, {Name: "Dimensions", Visible: false}}
+	if !reflect.DeepEqual(layers, want) {
+		t.Fatalf("layers = %+v, want %+v", layers, want)
+	}
+
+	tests := []struct {
+		names []string
+		want  string
+	}{
+		{nil, "Title\nGeometry\nDimensions\nBoth\nNote\nTolerance"},
+		{[]string{"dimensions"}, "Title\nDimensions\nNote\nTolerance"},
+		{[]string{"Geometry", "Dimensions"}, "Title\nGeometry\nDimensions\nBoth\nNote\nTolerance"},
+		{[]string{"Annotations"}, "Title\nNote"},
+	}
+	for _, tt := range tests {
+		doc := mustParsePDF(t, testLayersPDF())
+		if tt.names != nil {
+			doc.layers = doc.layerFilter(tt.names)
+		}
+		pages, ok := doc.rawText()
+		if !ok || len(pages) != 1 || pages[0] != tt.want {
+			t.Errorf("layers %q: got %q, %v, want %q", tt.names, pages, ok, tt.want)
+		}
+	}
+
+	if mustParsePDF(t, testRawTextPDF(testType0Font)).layerFilter([]string{"Geometry"}) != nil {
+		t.Error("expected no filter for a document without layers")
+	}
+}
+
+func TestApplyLayers(t *testing.T) {
+	src := &documentSource{data: testLayersPDF()}
+	config := NewExtractionConfig(WithLayerSelection("Dimensions", "Hidden"))
+	result := &ExtractionResult{MimeType: "application/pdf", Pipeline: &PipelineInfo{Extractor: ExtractorRawText}}
+	if err := applyLayers(result, config, src); err != nil {
+		t.Fatalf("applyLayers failed: %v", err)
+	}
+	if len(result.Layers) != 2 {
+		t.Fatalf("layers = %+v", result.Layers)
+	}
+	if len(result.Warnings) != 1 || result.Warnings[0].Code != WarningCodeLayerNotFound {
+		t.Fatalf("warnings = %+v", result.Warnings)
+	}
+
+	native := &ExtractionResult{MimeType: "application/pdf"}
+	if err := applyLayers(native, config, src); err != nil {
+		t.Fatalf("applyLayers failed: %v", err)
+	}
+	if n := len(native.Warnings); n != 2 || native.Warnings[1].Code != WarningCodeLayerSelectionIgnored {
+		t.Fatalf("warnings = %+v", native.Warnings)
+	}
+
+	plain := &ExtractionResult{MimeType: "application/pdf"}
+	if err := applyLayers(plain, NewExtractionConfig(), src); err != nil || plain.Layers != nil {
+		t.Fatalf("layers filled without the option: %+v, %v", plain.Layers, err)
+	}
+	if !isRawTextPDF(testLayersPDF(), "application/pdf", config) {
+		t.Fatal("layer selection does not route PDFs to the raw text reader")
+	}
+}
@@ -0,0 +1,110 @@
+package kreuzberg
+
+import (
+	"slices"
+	"sort"
+	"strings"
+	"unicode/utf8"
+)
+
+// hocrSearchWindow bounds how far past the previous word a word of hOCR is looked for in the
+// content, so that a word missing from the content does not match text much further on.
+const hocrSearchWindow = 256
+
+// textPosition locates Content[start:end] on a page. box is [left, top, right, bottom] in
+// points from the top-left corner of the page.
+type textPosition struct {
+	start, end int
+	page       int
+	box        [4]float64
+}
+
+// LocateText maps a byte offset in Content to the page and bounding box of the character
+// there, for highlighting the source of extracted text in a document viewer. The box is
+// [left, top, right, bottom] from the top-left corner of the unrotated page, in points for
+// PDFs and in pixels for images.
+//
+// Positions are known for PDFs read by the raw text reader (see WithRawText) and, with
+// WithTextPositions, for text recognized by Tesseract, which locates each word. ok is false
+// for other results, for
+// offsets that fall on inserted separators such as line breaks between pages, and once
+// Content has been changed after extraction, for example by a content filter.
+func (r *ExtractionResult) LocateText(offset int) (page int, bbox [4]float64, ok bool) {
+	if r == nil || len(r.textPositions) == 0 || offset < 0 || offset >= len(r.Content) || r.Content != r.textPositionsContent {
+		return 0, bbox, false
+	}
+	i := sort.Search(len(r.textPositions), func(i int) bool { return r.textPositions[i].end > offset })
+	if i == len(r.textPositions) || r.textPositions[i].start > offset {
+		return 0, bbox, false
+	}
+	pos := r.textPositions[i]
+
+	// Characters share the width of their string evenly.
+	for offset > pos.start && !utf8.RuneStart(r.Content[offset]) {
+		offset--
+	}
+	count := utf8.RuneCountInString(r.Content[pos.start:pos.end])
+	index := utf8.RuneCountInString(r.Content[pos.start:offset])
+	width := (pos.box[2] - pos.box[0]) / float64(count)
+	bbox = pos.box
+	bbox[0] = pos.box[0] + width*float64(index)
+	bbox[2] = bbox[0] + width
+	return pos.page, bbox, true
+}
+
+// textPositionsEnabled reports whether the positions of OCR'd words are wanted, which
+// makes Tesseract return hOCR along with the text.
+func textPositionsEnabled(config *ExtractionConfig) bool {
+	if config == nil {
+		return false
+	}
+	return (config.TextPositions != nil && *config.TextPositions) || (config.OffsetMap != nil && *config.OffsetMap)
+}
+
+// applyOCRTextPositions records, for LocateText, where the words of OCR'd pages are in
+// result.Content, from the hOCR the native OCR returned along with the text when text
+// positions are enabled. Words that cannot be found in order in the content are left out.
+// The hOCR is removed from the metadata unless the caller asked Tesseract for it.
+func applyOCRTextPositions(result *ExtractionResult, config *ExtractionConfig) {
+	if !textPositionsEnabled(config) {
+		return
+	}
+	pages := ocrPageHOCR(result.Metadata)
+	if config.OCR == nil || config.OCR.Tesseract == nil || !config.OCR.Tesseract.IncludeHOCR {
+		delete(result.Metadata.Additional, "hocr")
+		delete(result.Metadata.Additional, "page_hocr")
+	}
+	if len(pages) == 0 || len(result.textPositions) > 0 {
+		return
+	}
+	var sizes []PageSize
+	if structure := result.Metadata.PageStructure; structure != nil && isPDFMimeType(normalizeMimeType(result.MimeType)) {
+		sizes = structure.PageSizes
+	}
+
+	offset := 0
+	for i, hocr := range pages {
+		words, width, height := parseHOCRWords(hocr)
+		// PDF pages are rendered for OCR at a fixed resolution, so one factor scales pixels
+		// to points in both directions.
+		scale := 1.0
+		if n := slices.IndexFunc(sizes, func(size PageSize) bool { return size.PageNumber == i+1 }); n >= 0 && width > 0 && height > 0 {
+			scale = max(sizes[n].Width, sizes[n].Height) / max(width, height)
+		}
+		for _, word := range words {
+			window := result.Content[offset:min(len(result.Content), offset+len(word.text)+hocrSearchWindow)]
+			start := strings.Index(window, word.text)
+			if start < 0 {
+				continue
+			}
+			start += offset
+			offset = start + len(word.text)
+			box := word.box
+			for j := range box {
+				box[j] *= scale
+			}
+			result.textPositions = append(result.textPositions, textPosition{start: start, end: offset, page: i + 1, box: box})
+		}
+	}
+	result.textPositionsContent = result.Content
+}
@@ -0,0 +1,121 @@
+package kreuzberg
+
+import (
+	"encoding/json"
+	"math"
+	"strings"
+	"testing"
+)
+
+func TestLocateText(t *testing.T) {
+	pages, ok := mustParsePDF(t, testRawTextPDF(testType0Font)).rawTextPages()
+	if !ok {
+		t.Fatal("expected the raw text reader to handle the document")
+	}
+	texts := make([]string, len(pages))
+	for i, page := range pages {
+		texts[i] = page.text
+	}
+	result := &ExtractionResult{Content: strings.Join(texts, rawTextPageSeparator)}
+	result.setRawTextPositions(pages)
+
+	near := func(got, want float64) bool { return math.Abs(got-want) < 0.01 }
+
+	// "Hello" is drawn at (72, 700) in 12pt text on a 792pt high page.
+	page, box, ok := result.LocateText(0)
+	if !ok || page != 1 || !near(box[0], 72) || !near(box[1], 792-700-9.6) || !near(box[3], 792-700+2.4) {
+		t.Fatalf("LocateText(0) = %d, %v, %v", page, box, ok)
+	}
+	_, next, _ := result.LocateText(1)
+	if !near(next[0], box[2]) || next[2] <= next[0] {
+		t.Fatalf("second character box %v does not follow the first %v", next, box)
+	}
+
+	// The Type0 line is shifted down by 100pt through the CTM.
+	omega := strings.Index(result.Content, "Ω")
+	page, box, ok = result.LocateText(omega + 1)
+	if !ok || page != 1 || !near(box[1], 792-600-9.6) {
+		t.Fatalf("LocateText(Ω) = %d, %v, %v", page, box, ok)
+	}
+
+	page, box, ok = result.LocateText(strings.Index(result.Content, "ab") + 1)
+	if !ok || page != 2 || !near(box[3], 792-700+2) {
+		t.Fatalf("LocateText(b) = %d, %v, %v", page, box, ok)
+	}
+
+	for _, offset := range []int{-1, strings.Index(result.Content, "\n"), len(result.Content)} {
+		if _, _, ok := result.LocateText(offset); ok {
+			t.Errorf("LocateText(%d) should not resolve", offset)
+		}
+	}
+
+	result.Content = strings.ToUpper(result.Content)
+	if _, _, ok := result.LocateText(0); ok {
+		t.Error("positions should not apply once Content changes")
+	}
+	plain := &ExtractionResult{Content: "plain text"}
+	if _, _, ok := plain.LocateText(0); ok {
+		t.Error("plain text has no positions")
+	}
+}
+
+func TestLocateOCRText(t *testing.T) {
+	hocr := `<div class='ocr_page' id='page_1' title='image "scan.png"; bbox 0 0 1700 2200; ppageno 0'>
+ <span class='ocr_line' id='line_1_1' title="bbox 100 200 600 250">
+  <span class='ocrx_word' id='word_1_1' title='bbox 100 200 300 250; x_wconf 95'>Hello</span>
+  <span class='ocrx_word' id='word_1_2' title='bbox 340 200 600 250; x_wconf 93'>W&amp;rld</span>
+ </span>
+</div>`
+	metadata := func(key, value string) Metadata {
+		data, _ := json.Marshal(value)
+		if key == "page_hocr" {
+			data, _ = json.Marshal([]*string{nil, &value})
+		}
+		return Metadata{Additional: map[string]json.RawMessage{key: data}}
+	}
+
+	config := NewExtractionConfig(WithOCR(), WithTextPositions(true))
+	result := &ExtractionResult{MimeType: "image/png", Content: "# Hello W&rld\n", Metadata: metadata("hocr", hocr)}
+	applyOCRTextPositions(result, config)
+	if _, ok := result.Metadata.Additional["hocr"]; ok {
+		t.Fatal("expected hocr to be removed from the metadata")
+	}
+	page, box, ok := result.LocateText(strings.Index(result.Content, "W&rld"))
+	if !ok || page != 1 || box != [4]float64{340, 200, 392, 250} {
+		t.Fatalf("LocateText(W) = %d, %v, %v", page, box, ok)
+	}
+	if _, _, ok := result.LocateText(0); ok {
+		t.Error("markup the OCR did not read should not resolve")
+	}
+
+	// The second page of a letter-sized PDF, rendered at 200 dpi for OCR.
+	result = &ExtractionResult{MimeType: "application/pdf", Content: "<!-- PAGE 1 -->\n\n<!-- PAGE 2 -->\nHello W&rld", Metadata: metadata("page_hocr", hocr)}
+	result.Metadata.PageStructure = &PageStructure{PageSizes: []PageSize{{PageNumber: 1, Width: 612, Height: 792}, {PageNumber: 2, Width: 612, Height: 792}}}
+	applyOCRTextPositions(result, config)
+	page, box, ok = result.LocateText(strings.Index(result.Content, "Hello"))
+	if !ok || page != 2 || box != [4]float64{36, 72, 50.4, 90} {
+		t.Fatalf("LocateText(Hello) = %d, %v, %v", page, box, ok)
+	}
+
+	for _, config := range []*ExtractionConfig{config, NewExtractionConfig(WithOCR(), WithOffsetMap(true))} {
+		data, err := encodeConfig(config)
+		if err != nil || !strings.Contains(string(data), `"include_hocr":true`) {
+			t.Fatalf("expected Tesseract to be asked for hOCR, got %s, %v", data, err)
+		}
+	}
+}
+
+// TestOCRTextPositionsOffByDefault verifies that OCR does not pay for hOCR unless text
+// positions are requested.
+func TestOCRTextPositionsOffByDefault(t *testing.T) {
+	config := NewExtractionConfig(WithOCR())
+	data, err := encodeConfig(config)
+	if err != nil || strings.Contains(string(data), "include_hocr") {
+		t.Fatalf("expected no hOCR request by default, got %s, %v", data, err)
+	}
+	result := &ExtractionResult{MimeType: "image/png", Content: "Hello"}
+	applyOCRTextPositions(result, config)
+	if _, _, ok := result.LocateText(0); ok {
+		t.Fatal("expected no OCR positions without WithTextPositions")
+	}
+}
@@ -0,0 +1,106 @@
+package kreuzberg
+
+import (
+	"context"
+	"log/slog"
+	"sync/atomic"
+)
+
+// defaultLogger receives extraction logs for configs without a logger of their own.
+var defaultLogger atomic.Pointer[slog.Logger]
+
+// SetDefaultLogger sets the logger used by extractions whose config has no logger set with
+// WithLogger. A nil logger, the initial state, turns default logging off.
+func SetDefaultLogger(logger *slog.Logger) {
+	defaultLogger.Store(logger)
+}
+
+// extractionLogger returns the logger for extractions with config, or nil when none is set.
+func extractionLogger(config *ExtractionConfig) *slog.Logger {
+	if config != nil && config.Logger != nil {
+		return config.Logger
+	}
+	return defaultLogger.Load()
+}
+
+// ErrorLevel maps the code of a native error to the level it is logged at. Internal, I/O,
+// plugin and missing dependency errors point at the deployment and are errors; parsing and
+// OCR errors point at one document and are warnings; validation and unsupported format
+// errors point at the caller's input and are logged at info.
+func ErrorLevel(code ErrorCode) slog.Level {
+	switch code {
+	case ErrorCodeValidation, ErrorCodeUnsupportedFormat:
+		return slog.LevelInfo
+	case ErrorCodeParsing, ErrorCodeOcr:
+		return slog.LevelWarn
+	default:
+		return slog.LevelError
+	}
+}
+
+// WarningLevel maps the code of an ExtractionWarning to the level it is logged at. Warnings
+// that leave the result complete are logged at info, the others as warnings.
+func WarningLevel(code string) slog.Level {
+	if code == WarningCodeOCRLanguageDownloaded {
+		return slog.LevelInfo
+	}
+	return slog.LevelWarn
+}
+
+// logNativeError logs an error returned by the native library and returns it.
+func logNativeError(ctx context.Context, config *ExtractionConfig, err error) error {
+	logger := extractionLogger(config)
+	if logger == nil || err == nil {
+		return err
+	}
+	level := slog.LevelError
+	attrs := []slog.Attr{slog.String("error", err.Error())}
+	if coded, ok := err.(interface{ Code() ErrorCode }); ok {
+		level = ErrorLevel(coded.Code())
+		attrs = append(attrs, slog.String("error_code", coded.Code().String()))
+	}
+	logger.LogAttrs(ctx, level, "kreuzberg: extraction failed", attrs...)
+	return err
+}
+
+// logResult logs the outcome of an extraction: a debug record describing the document and
+// one record per warning.
+func logResult(ctx context.Context, result *ExtractionResult, config *ExtractionConfig) {
+	logger := extractionLogger(config)
+	if logger == nil {
+		return
+	}
+	if logger.Enabled(ctx, slog.LevelDebug) {
+		attrs := []slog.Attr{
+			slog.String("mime_type", result.MimeType),
+			slog.String("format", string(result.Metadata.Format.Type)),
+			slog.Int("content_length", len(result.Content)),
+			slog.Int("tables", len(result.Tables)),
+		}
+		if pages, _ := result.GetPageCount(); pages > 0 {
+			attrs = append(attrs, slog.Int("pages", pages))
+		}
+		if result.Pipeline != nil {
+			attrs = append(attrs, slog.String("extractor", result.Pipeline.Extractor))
+		}
+		if config.OCR != nil {
+			backend := config.OCR.Backend
+			if backend == "" {
+				backend = "tesseract"
+			}
+			attrs = append(attrs, slog.String("ocr_backend", backend))
+		}
+		logger.LogAttrs(ctx, slog.LevelDebug, "kreuzberg: document extracted", attrs...)
+	}
+	for _, warning := range result.Warnings {
+		attrs := []slog.Attr{
+			slog.String("mime_type", result.MimeType),
+			slog.String("code", warning.Code),
+			slog.String("message", warning.Message),
+		}
+		if warning.PageNumber > 0 {
+			attrs = append(attrs, slog.Int("page", warning.PageNumber))
+		}
+		logger.LogAttrs(ctx, WarningLevel(warning.Code), "kreuzberg: extraction warning", attrs...)
+	}
+}
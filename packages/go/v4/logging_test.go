@@ -0,0 +1,103 @@
+package kreuzberg
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"log/slog"
+	"strings"
+	"testing"
+)
+
+func testLogRecords(t *testing.T, buf *bytes.Buffer) []map[string]any {
+	t.Helper()
+	var records []map[string]any
+	for _, line := range strings.Split(strings.TrimSpace(buf.String()), "\n") {
+		if line == "" {
+			continue
+		}
+		var record map[string]any
+		if err := json.Unmarshal([]byte(line), &record); err != nil {
+			t.Fatalf("decode log record %q: %v", line, err)
+		}
+		records = append(records, record)
+	}
+	return records
+}
+
+func TestWithLogger(t *testing.T) {
+	registerTestExtractor(t)
+	var buf bytes.Buffer
+	logger := slog.New(slog.NewJSONHandler(&buf, &slog.HandlerOptions{Level: slog.LevelDebug}))
+
+	if _, err := ExtractBytesSync([]byte("acme"), testExtractorMime, NewExtractionConfig(WithLogger(logger))); err != nil {
+		t.Fatalf("extract: %v", err)
+	}
+	records := testLogRecords(t, &buf)
+	if len(records) != 1 || records[0]["msg"] != "kreuzberg: document extracted" || records[0]["level"] != "DEBUG" || records[0]["content_length"] != float64(4) {
+		t.Fatalf("unexpected records %v", records)
+	}
+
+	buf.Reset()
+	if _, err := ExtractBytesSync([]byte("acme"), testExtractorMime, nil); err != nil {
+		t.Fatalf("extract: %v", err)
+	}
+	if buf.Len() != 0 {
+		t.Fatalf("expected no records without a logger, got %q", buf.String())
+	}
+
+	SetDefaultLogger(logger)
+	t.Cleanup(func() { SetDefaultLogger(nil) })
+	if _, err := ExtractBytesSync([]byte("acme"), testExtractorMime, nil); err != nil {
+		t.Fatalf("extract: %v", err)
+	}
+	if records := testLogRecords(t, &buf); len(records) != 1 {
+		t.Fatalf("expected the default logger to be used, got %v", records)
+	}
+}
+
+func TestLogResultWarnings(t *testing.T) {
+	var buf bytes.Buffer
+	config := NewExtractionConfig(WithLogger(slog.New(slog.NewJSONHandler(&buf, nil))))
+	result := &ExtractionResult{MimeType: "application/pdf"}
+	result.addWarning(WarningCodeOCRPageTimeout, 3, "OCR timed out")
+	result.addWarning(WarningCodeOCRLanguageDownloaded, 0, "downloaded deu")
+
+	logResult(context.Background(), result, config)
+	records := testLogRecords(t, &buf)
+	if len(records) != 2 {
+		t.Fatalf("expected one record per warning, got %v", records)
+	}
+	if r := records[0]; r["level"] != "WARN" || r["code"] != WarningCodeOCRPageTimeout || r["page"] != float64(3) || r["message"] != "OCR timed out" {
+		t.Fatalf("unexpected timeout record %v", r)
+	}
+	if r := records[1]; r["level"] != "INFO" || r["page"] != nil {
+		t.Fatalf("unexpected download record %v", r)
+	}
+}
+
+func TestErrorLevel(t *testing.T) {
+	for code, want := range map[ErrorCode]slog.Level{
+		ErrorCodeValidation:        slog.LevelInfo,
+		ErrorCodeUnsupportedFormat: slog.LevelInfo,
+		ErrorCodeParsing:           slog.LevelWarn,
+		ErrorCodeOcr:               slog.LevelWarn,
+		ErrorCodeIo:                slog.LevelError,
+		ErrorCodeInternal:          slog.LevelError,
+	} {
+		if got := ErrorLevel(code); got != want {
+			t.Errorf("ErrorLevel(%v) = %v, want %v", code, got, want)
+		}
+	}
+
+	var buf bytes.Buffer
+	config := NewExtractionConfig(WithLogger(slog.New(slog.NewJSONHandler(&buf, nil))))
+	err := newParsingErrorWithContext("bad xref", nil, ErrorCodeParsing, nil)
+	if got := logNativeError(context.Background(), config, err); got != err {
+		t.Fatalf("logNativeError returned %v", got)
+	}
+	records := testLogRecords(t, &buf)
+	if len(records) != 1 || records[0]["level"] != "WARN" || records[0]["error_code"] != ErrorCodeParsing.String() {
+		t.Fatalf("unexpected records %v", records)
+	}
+}
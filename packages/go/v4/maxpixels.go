@@ -0,0 +1,113 @@
+package kreuzberg
+
+import (
+	"bytes"
+	"fmt"
+	"image"
+	"image/jpeg"
+	"image/png"
+	"math"
+	"os"
+	"strings"
+)
+
+// ocrMaxPixels returns ImagePreprocessingConfig.MaxPixels of config's Tesseract settings,
+// or 0 when images are not OCR'd or have no pixel budget.
+func ocrMaxPixels(config *ExtractionConfig) int {
+	if config == nil || config.OCR == nil || config.OCR.Tesseract == nil || config.OCR.Tesseract.Preprocessing == nil {
+		return 0
+	}
+	if maxPixels := config.OCR.Tesseract.Preprocessing.MaxPixels; maxPixels != nil && *maxPixels > 0 {
+		return *maxPixels
+	}
+	return 0
+}
+
+// downsampleForOCR shrinks a PNG, JPEG or GIF image document with more pixels than the OCR
+// pixel budget of config, keeping its aspect ratio. It returns the image to extract, its
+// MIME type and a message describing the scaling, or data and mimeType unchanged and ""
+// when the image fits or is not in a format the binding can decode.
+func downsampleForOCR(data []byte, mimeType string, config *ExtractionConfig) ([]byte, string, string) {
+	maxPixels := ocrMaxPixels(config)
+	if maxPixels == 0 || !strings.HasPrefix(normalizeMimeType(mimeType), "image/") {
+		return data, mimeType, ""
+	}
+	cfg, format, err := image.DecodeConfig(bytes.NewReader(data))
+	if err != nil || cfg.Width*cfg.Height <= maxPixels {
+		return data, mimeType, ""
+	}
+	img, _, err := image.Decode(bytes.NewReader(data))
+	if err != nil {
+		return data, mimeType, ""
+	}
+
+	scale := math.Sqrt(float64(maxPixels) / float64(cfg.Width*cfg.Height))
+	width := max(int(float64(cfg.Width)*scale), 1)
+	height := max(int(float64(cfg.Height)*scale), 1)
+	scaled := scaleImage(img, width, height)
+	var buf bytes.Buffer
+	outMime := "image/png"
+	if format == "jpeg" {
+		outMime = "image/jpeg"
+		err = jpeg.Encode(&buf, scaled, &jpeg.Options{Quality: renderJPEGQuality})
+	} else {
+		err = png.Encode(&buf, scaled)
+	}
+	if err != nil {
+		return data, mimeType, ""
+	}
+	message := fmt.Sprintf("image of %dx%d pixels was downsampled to %dx%d (scale %.4f) to fit the OCR budget of %d pixels",
+		cfg.Width, cfg.Height, width, height, float64(width)/float64(cfg.Width), maxPixels)
+	return buf.Bytes(), outMime, message
+}
+
+// readOversizedImageFile reads the image at path when it exceeds the OCR pixel budget of
+// config, so that it can be downsampled before extraction. Only the image header is read
+// otherwise.
+func readOversizedImageFile(path string, config *ExtractionConfig) ([]byte, string, bool) {
+	maxPixels := ocrMaxPixels(config)
+	if maxPixels == 0 {
+		return nil, "", false
+	}
+	file, err := os.Open(path)
+	if err != nil {
+		return nil, "", false
+	}
+	cfg, format, err := image.DecodeConfig(file)
+	_ = file.Close()
+	if err != nil || cfg.Width*cfg.Height <= maxPixels {
+		return nil, "", false
+	}
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, "", false
+	}
+	return data, "image/" + format, true
+}
+
+// downsampleBatchItems applies downsampleForOCR to every item, returning the items to
+// extract and the message for each downsampled item; the messages are nil when no item was
+// downsampled.
+func downsampleBatchItems(items []BytesWithMime, config *ExtractionConfig) ([]BytesWithMime, []string) {
+	if ocrMaxPixels(config) == 0 {
+		return items, nil
+	}
+	var out []BytesWithMime
+	var messages []string
+	for i, item := range items {
+		data, mimeType, message := downsampleForOCR(item.Data, item.MimeType, config)
+		if message == "" {
+			continue
+		}
+		if out == nil {
+			out = append([]BytesWithMime(nil), items...)
+			messages = make([]string, len(items))
+		}
+		out[i] = BytesWithMime{Data: data, MimeType: mimeType}
+		messages[i] = message
+	}
+	if out == nil {
+		return items, nil
+	}
+	return out, messages
+}
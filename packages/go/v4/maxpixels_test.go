@@ -0,0 +1,83 @@
+package kreuzberg
+
+import (
+	"bytes"
+	"image"
+	"image/jpeg"
+	"image/png"
+	"os"
+	"path/filepath"
+	"slices"
+	"strings"
+	"testing"
+)
+
+func TestDownsampleForOCR(t *testing.T) {
+	var pngData, jpegData bytes.Buffer
+	if err := png.Encode(&pngData, testRenderImage(400, 100)); err != nil {
+		t.Fatal(err)
+	}
+	if err := jpeg.Encode(&jpegData, testRenderImage(400, 100), nil); err != nil {
+		t.Fatal(err)
+	}
+	config := NewExtractionConfig(WithOCR(WithOCRMaxPixels(10000)))
+
+	data, mimeType, message := downsampleForOCR(pngData.Bytes(), "image/png", config)
+	img, err := png.Decode(bytes.NewReader(data))
+	if err != nil || mimeType != "image/png" {
+		t.Fatalf("expected a PNG, got %s: %v", mimeType, err)
+	}
+	if size := img.Bounds().Size(); size != (image.Point{X: 200, Y: 50}) || !strings.Contains(message, "scale 0.5000") {
+		t.Fatalf("unexpected downsampling to %v: %q", size, message)
+	}
+
+	data, mimeType, _ = downsampleForOCR(jpegData.Bytes(), "image/jpeg", config)
+	if cfg, err := jpeg.DecodeConfig(bytes.NewReader(data)); err != nil || mimeType != "image/jpeg" || cfg.Width != 200 {
+		t.Fatalf("unexpected JPEG downsampling: %s %+v %v", mimeType, cfg, err)
+	}
+
+	for _, tt := range []struct {
+		name   string
+		config *ExtractionConfig
+	}{
+		{"within budget", NewExtractionConfig(WithOCR(WithOCRMaxPixels(40000)))},
+		{"no budget", NewExtractionConfig(WithOCR())},
+		{"no OCR", NewExtractionConfig()},
+	} {
+		if data, _, message := downsampleForOCR(pngData.Bytes(), "image/png", tt.config); message != "" || !bytes.Equal(data, pngData.Bytes()) {
+			t.Errorf("%s: image changed: %q", tt.name, message)
+		}
+	}
+}
+
+func TestDownsampleBatchItemsAndFiles(t *testing.T) {
+	var big bytes.Buffer
+	if err := png.Encode(&big, testRenderImage(200, 200)); err != nil {
+		t.Fatal(err)
+	}
+	config := NewExtractionConfig(WithOCR(WithOCRMaxPixels(100)))
+	items := []BytesWithMime{{Data: []byte("text"), MimeType: "text/plain"}, {Data: big.Bytes(), MimeType: "image/png"}}
+	out, messages := downsampleBatchItems(items, config)
+	if len(messages) != 2 || messages[0] != "" || messages[1] == "" || len(out[1].Data) >= big.Len() || !bytes.Equal(items[1].Data, big.Bytes()) {
+		t.Fatalf("unexpected batch downsampling: %q", messages)
+	}
+
+	path := filepath.Join(t.TempDir(), "scan.png")
+	if err := os.WriteFile(path, big.Bytes(), 0o600); err != nil {
+		t.Fatal(err)
+	}
+	if data, mimeType, ok := readOversizedImageFile(path, config); !ok || mimeType != "image/png" || !bytes.Equal(data, big.Bytes()) {
+		t.Fatalf("oversized image file not read: %s %v", mimeType, ok)
+	}
+	if _, _, ok := readOversizedImageFile(path, NewExtractionConfig(WithOCR(WithOCRMaxPixels(40000)))); ok {
+		t.Fatal("image within budget read")
+	}
+}
+
+func TestWithOCRMaxPixelsKeepsLanguage(t *testing.T) {
+	ocr := NewOCRConfig(WithOCRLanguage("deu"), WithOCRMaxPixels(1000))
+	languages, err := tesseractLanguages(ocr)
+	if err != nil || !slices.Equal(languages, []string{"deu"}) {
+		t.Fatalf("languages = %q, %v", languages, err)
+	}
+}
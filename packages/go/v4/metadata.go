@@ -3,13 +3,15 @@ package kreuzberg
 import "encoding/json"
 
 var metadataCoreKeys = map[string]struct{}{
-	"language":            {},
-	"date":                {},
-	"subject":             {},
-	"format_type":         {},
-	"image_preprocessing": {},
-	"json_schema":         {},
-	"error":               {},
+	"language":                {},
+	"date":                    {},
+	"subject":                 {},
+	"format_type":             {},
+	"image_preprocessing":     {},
+	"json_schema":             {},
+	"error":                   {},
+	"dominant_text_direction": {},
+	"source_encoding":         {},
 }
 
 var formatFieldSets = map[FormatType][]string{
@@ -55,6 +57,15 @@ func (m *Metadata) UnmarshalJSON(data []byte) error {
 	m.Language = decodeString("language")
 	m.Date = decodeString("date")
 	m.Subject = decodeString("subject")
+	m.DominantTextDirection = decodeString("dominant_text_direction")
+	m.SourceEncoding = decodeString("source_encoding")
+	m.CreatedAtRaw = decodeString("created_at")
+	if m.CreatedAtRaw == nil {
+		m.CreatedAtRaw = m.Date
+	}
+	m.ModifiedAtRaw = decodeString("modified_at")
+	m.CreatedAt = parseMetadataDate(m.CreatedAtRaw)
+	m.ModifiedAt = parseMetadataDate(m.ModifiedAtRaw)
 
 	if value, ok := raw["image_preprocessing"]; ok {
 		var meta ImagePreprocessingMetadata
@@ -118,6 +129,12 @@ func (m Metadata) MarshalJSON() ([]byte, error) {
 	if m.Subject != nil {
 		out["subject"] = *m.Subject
 	}
+	if m.DominantTextDirection != nil {
+		out["dominant_text_direction"] = *m.DominantTextDirection
+	}
+	if m.SourceEncoding != nil {
+		out["source_encoding"] = *m.SourceEncoding
+	}
 	if m.ImagePreprocessing != nil {
 		out["image_preprocessing"] = m.ImagePreprocessing
 	}
@@ -0,0 +1,76 @@
+package kreuzberg
+
+import (
+	"strings"
+	"time"
+)
+
+// metadataDateLayouts are the date formats other than PDF dates that extractors report, tried
+// in order.
+var metadataDateLayouts = []string{
+	time.RFC3339Nano,
+	"2006-01-02T15:04:05.999999999",
+	"2006-01-02T15:04:05Z0700",
+	"2006-01-02T15:04Z07:00",
+	"2006-01-02T15:04",
+	"2006-01-02 15:04:05Z07:00",
+	"2006-01-02 15:04:05 -0700",
+	"2006-01-02 15:04:05",
+	time.DateOnly,
+	"2006-01",
+	time.RFC1123Z,
+	time.RFC1123,
+	"Mon, 2 Jan 2006 15:04:05 -0700",
+	"Mon, 2 Jan 2006 15:04:05 MST",
+	"2 Jan 2006 15:04:05 -0700",
+	time.RFC850,
+	time.ANSIC,
+	time.UnixDate,
+}
+
+// parseMetadataDate parses a date reported in document metadata, returning nil when raw is
+// nil or in no known format. Dates without a time zone are taken as UTC.
+func parseMetadataDate(raw *string) *time.Time {
+	if raw == nil {
+		return nil
+	}
+	s := strings.TrimSpace(*raw)
+	if isPDFDate(s) {
+		if t, ok := parsePDFDate(s); ok {
+			return &t
+		}
+		return nil
+	}
+	// RFC 5322 dates may end with a comment naming the zone, as in "+0000 (UTC)".
+	if i := strings.LastIndex(s, " ("); i > 0 && strings.HasSuffix(s, ")") {
+		s = s[:i]
+	}
+	for _, layout := range metadataDateLayouts {
+		if t, err := time.Parse(layout, s); err == nil {
+			return &t
+		}
+	}
+	return nil
+}
+
+// isPDFDate reports whether s is a date in the PDF date syntax, "D:YYYYMMDDHHmmSSOHH'mm'",
+// where everything after the year is optional and the prefix is often left out.
+func isPDFDate(s string) bool {
+	s = strings.TrimPrefix(s, "D:")
+	digits := 0
+	for digits < len(s) && s[digits] >= '0' && s[digits] <= '9' {
+		digits++
+	}
+	if digits < 4 || digits > 14 || digits%2 != 0 {
+		return false
+	}
+	if digits == len(s) {
+		return true
+	}
+	switch s[digits] {
+	case 'Z', '+', '-':
+		// A "-" after the year alone starts an ISO date such as "2023-01-01".
+		return digits > 4 || s[digits] != '-'
+	}
+	return false
+}
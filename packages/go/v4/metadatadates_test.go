@@ -0,0 +1,82 @@
+package kreuzberg
+
+import (
+	"encoding/json"
+	"testing"
+	"time"
+)
+
+func TestParseMetadataDate(t *testing.T) {
+	tests := []struct {
+		raw  string
+		want time.Time
+	}{
+		{"D:20230102030405+05'30'", time.Date(2023, 1, 2, 3, 4, 5, 0, time.FixedZone("", 5*3600+30*60))},
+		{"D:20230102030405-08'00", time.Date(2023, 1, 2, 3, 4, 5, 0, time.FixedZone("", -8*3600))},
+		{"D:20230102030405Z", time.Date(2023, 1, 2, 3, 4, 5, 0, time.UTC)},
+		{"D:2023", time.Date(2023, 1, 1, 0, 0, 0, 0, time.UTC)},
+		{"20230102", time.Date(2023, 1, 2, 0, 0, 0, 0, time.UTC)},
+		{"2023-01-02T03:04:05Z", time.Date(2023, 1, 2, 3, 4, 5, 0, time.UTC)},
+		{"2023-01-02T03:04:05.5+02:00", time.Date(2023, 1, 2, 3, 4, 5, 5e8, time.FixedZone("", 2*3600))},
+		{"2023-01-02T03:04:05", time.Date(2023, 1, 2, 3, 4, 5, 0, time.UTC)},
+		{"2023-01-02 03:04:05", time.Date(2023, 1, 2, 3, 4, 5, 0, time.UTC)},
+		{"2023-01-02", time.Date(2023, 1, 2, 0, 0, 0, 0, time.UTC)},
+		{"Mon, 2 Jan 2023 03:04:05 +0000 (UTC)", time.Date(2023, 1, 2, 3, 4, 5, 0, time.UTC)},
+	}
+	for _, tt := range tests {
+		got := parseMetadataDate(&tt.raw)
+		if got == nil || !got.Equal(tt.want) {
+			t.Errorf("parseMetadataDate(%q) = %v, want %v", tt.raw, got, tt.want)
+			continue
+		}
+		_, offset := got.Zone()
+		if _, wantOffset := tt.want.Zone(); offset != wantOffset {
+			t.Errorf("parseMetadataDate(%q) has offset %d, want %d", tt.raw, offset, wantOffset)
+		}
+	}
+	for _, raw := range []string{"", "yesterday", "D:20", "2023/13/45"} {
+		if got := parseMetadataDate(&raw); got != nil {
+			t.Errorf("parseMetadataDate(%q) = %v, want nil", raw, got)
+		}
+	}
+	if parseMetadataDate(nil) != nil {
+		t.Error("parseMetadataDate(nil) is not nil")
+	}
+}
+
+func TestMetadataDates(t *testing.T) {
+	var m Metadata
+	raw := `{"format_type":"pdf","created_at":"D:20230102030405Z","modified_at":"last tuesday"}`
+	if err := json.Unmarshal([]byte(raw), &m); err != nil {
+		t.Fatalf("unmarshal failed: %v", err)
+	}
+	if m.CreatedAt == nil || !m.CreatedAt.Equal(time.Date(2023, 1, 2, 3, 4, 5, 0, time.UTC)) {
+		t.Fatalf("CreatedAt = %v", m.CreatedAt)
+	}
+	if m.ModifiedAt != nil || m.ModifiedAtRaw == nil || *m.ModifiedAtRaw != "last tuesday" {
+		t.Fatalf("unparsable ModifiedAt = %v, raw %v", m.ModifiedAt, m.ModifiedAtRaw)
+	}
+	if pdf, ok := m.PdfMetadata(); !ok || pdf.CreatedAt == nil || *pdf.CreatedAt != "D:20230102030405Z" {
+		t.Fatalf("PDF metadata lost the raw date: %+v", pdf)
+	}
+
+	var email Metadata
+	if err := json.Unmarshal([]byte(`{"date":"2024-05-06"}`), &email); err != nil {
+		t.Fatalf("unmarshal failed: %v", err)
+	}
+	if email.CreatedAt == nil || email.CreatedAt.Day() != 6 || *email.CreatedAtRaw != "2024-05-06" {
+		t.Fatalf("CreatedAt did not fall back to Date: %v", email.CreatedAt)
+	}
+
+	out, err := json.Marshal(m)
+	if err != nil {
+		t.Fatalf("marshal failed: %v", err)
+	}
+	var roundTrip map[string]any
+	if err := json.Unmarshal(out, &roundTrip); err != nil {
+		t.Fatal(err)
+	}
+	if roundTrip["created_at"] != "D:20230102030405Z" || roundTrip["modified_at"] != "last tuesday" {
+		t.Fatalf("round trip lost the raw dates: %s", out)
+	}
+}
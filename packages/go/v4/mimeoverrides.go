@@ -0,0 +1,114 @@
+package kreuzberg
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+	"sync"
+)
+
+// mimeOverrides holds the extension mappings set with SetMimeOverride.
+var (
+	mimeOverridesMu sync.RWMutex
+	mimeOverrides   = map[string]string{}
+)
+
+// SetMimeOverride makes files with extension ext, with or without the leading dot and in any
+// case, be extracted as mimeType, for vendor-specific extensions of known formats such as
+// ".xyzdoc" for DOCX. It applies to every extraction from a path, below the overrides of
+// ExtractionConfig.MimeOverrides; an empty mimeType removes the override. It is safe to call
+// concurrently with extractions.
+func SetMimeOverride(ext, mimeType string) error {
+	key := normalizeExtension(ext)
+	if key == "" {
+		return newValidationErrorWithContext("extension cannot be empty", nil, ErrorCodeValidation, nil)
+	}
+	mimeType = normalizeMimeType(mimeType)
+	mimeOverridesMu.Lock()
+	defer mimeOverridesMu.Unlock()
+	if mimeType == "" {
+		delete(mimeOverrides, key)
+		return nil
+	}
+	if !strings.Contains(mimeType, "/") {
+		return newValidationErrorWithContext(fmt.Sprintf("invalid mime type %q for extension %q", mimeType, ext), nil, ErrorCodeValidation, nil)
+	}
+	mimeOverrides[key] = mimeType
+	return nil
+}
+
+// mimeOverride returns the MIME type the overrides of config, then those set with
+// SetMimeOverride, give files named name.
+func mimeOverride(name string, config *ExtractionConfig) (string, bool) {
+	ext := normalizeExtension(filepath.Ext(name))
+	if ext == "" {
+		return "", false
+	}
+	if config != nil {
+		if mimeType, ok := config.MimeOverrides[ext]; ok {
+			return normalizeMimeType(mimeType), true
+		}
+		for key, mimeType := range config.MimeOverrides {
+			if normalizeExtension(key) == ext {
+				return normalizeMimeType(mimeType), true
+			}
+		}
+	}
+	mimeOverridesMu.RLock()
+	defer mimeOverridesMu.RUnlock()
+	mimeType, ok := mimeOverrides[ext]
+	return mimeType, ok
+}
+
+// readMimeOverrideFile reads the file at path when a MIME override applies to its name and
+// checks the override against the MIME type detected from the content.
+func readMimeOverrideFile(path string, config *ExtractionConfig) ([]byte, string, bool, error) {
+	mimeType, ok := mimeOverride(path, config)
+	if !ok {
+		return nil, "", false, nil
+	}
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, "", false, newIOErrorWithContext(fmt.Sprintf("failed to read %s", path), err, ErrorCodeIo, nil)
+	}
+	if err := checkMimeOverride(path, data, mimeType); err != nil {
+		return nil, "", false, err
+	}
+	return data, mimeType, true, nil
+}
+
+// checkMimeOverride returns a ValidationError when the MIME type detected from data rules
+// out mimeType, the override for the file called name.
+func checkMimeOverride(name string, data []byte, mimeType string) error {
+	if detected, err := DetectMimeType(data); err == nil && !mimeCompatible(mimeType, normalizeMimeType(detected)) {
+		return newValidationErrorWithContext(
+			fmt.Sprintf("content of %s is %s, which does not match its mime override %s", name, detected, mimeType),
+			nil, ErrorCodeValidation, nil)
+	}
+	return nil
+}
+
+// mimeCompatible reports whether content detected as detected can be of type mimeType.
+// Detection sees only the container of many formats, such as the zip archive of OOXML
+// documents, and cannot tell text formats apart.
+func mimeCompatible(mimeType, detected string) bool {
+	switch {
+	case detected == mimeType, detected == "", detected == "application/octet-stream", detected == "text/plain":
+		return true
+	case detected == "application/zip":
+		return strings.Contains(mimeType, "zip") || strings.Contains(mimeType, "openxmlformats") ||
+			strings.Contains(mimeType, "opendocument")
+	case detected == "application/x-ole-storage", detected == "application/x-cfb":
+		return strings.Contains(mimeType, "ms-") || strings.Contains(mimeType, "msword") || strings.Contains(mimeType, "outlook")
+	case strings.HasPrefix(detected, "text/"):
+		return strings.HasPrefix(mimeType, "text/") || strings.HasSuffix(mimeType, "json") || strings.HasSuffix(mimeType, "xml")
+	default:
+		return false
+	}
+}
+
+// normalizeExtension returns ext in lower case without its leading dot.
+func normalizeExtension(ext string) string {
+	return strings.ToLower(strings.TrimPrefix(strings.TrimSpace(ext), "."))
+}
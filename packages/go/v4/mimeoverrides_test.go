@@ -0,0 +1,143 @@
+package kreuzberg
+
+import (
+	"errors"
+	"os"
+	"path/filepath"
+	"sync"
+	"testing"
+)
+
+const docxMime = "application/vnd.openxmlformats-officedocument.wordprocessingml.document"
+
+func TestMimeOverridePrecedence(t *testing.T) {
+	if err := SetMimeOverride(".XYZDOC", docxMime); err != nil {
+		t.Fatalf("SetMimeOverride failed: %v", err)
+	}
+	t.Cleanup(func() { _ = SetMimeOverride("xyzdoc", "") })
+
+	if mimeType, ok := mimeOverride("/data/report.xyzdoc", nil); !ok || mimeType != docxMime {
+		t.Fatalf("global override not applied: %q, %v", mimeType, ok)
+	}
+	config := NewExtractionConfig(WithMimeOverrides(map[string]string{".XyzDoc": "text/plain"}))
+	if mimeType, ok := mimeOverride("report.xyzdoc", config); !ok || mimeType != "text/plain" {
+		t.Fatalf("config override did not take precedence: %q, %v", mimeType, ok)
+	}
+	if _, ok := mimeOverride("report.pdf", config); ok {
+		t.Fatal("unexpected override for a file without one")
+	}
+
+	if err := SetMimeOverride("xyzdoc", ""); err != nil {
+		t.Fatalf("removing the override failed: %v", err)
+	}
+	if _, ok := mimeOverride("report.xyzdoc", nil); ok {
+		t.Fatal("override not removed")
+	}
+}
+
+func TestSetMimeOverrideConcurrent(t *testing.T) {
+	t.Cleanup(func() { _ = SetMimeOverride("conc", "") })
+	var wg sync.WaitGroup
+	for range 8 {
+		wg.Add(2)
+		go func() {
+			defer wg.Done()
+			_ = SetMimeOverride("conc", "text/plain")
+		}()
+		go func() {
+			defer wg.Done()
+			mimeOverride("file.conc", nil)
+		}()
+	}
+	wg.Wait()
+
+	var valErr *ValidationError
+	if err := SetMimeOverride("", "text/plain"); !errors.As(err, &valErr) {
+		t.Fatalf("expected ValidationError for an empty extension, got %v", err)
+	}
+	if err := SetMimeOverride("conc", "plain"); !errors.As(err, &valErr) {
+		t.Fatalf("expected ValidationError for a malformed mime type, got %v", err)
+	}
+	if err := validateConfig(NewExtractionConfig(WithMimeOverrides(map[string]string{"": "text/plain"}))); !errors.As(err, &valErr) {
+		t.Fatalf("expected ValidationError for an empty config extension, got %v", err)
+	}
+}
+
+func TestMimeCompatible(t *testing.T) {
+	for _, tt := range []struct {
+		mimeType, detected string
+		want               bool
+	}{
+		{docxMime, "application/zip", true},
+		{docxMime, docxMime, true},
+		{docxMime, "application/pdf", false},
+		{"application/msword", "application/x-ole-storage", true},
+		{"text/markdown", "text/plain", true},
+		{"application/json", "text/html", true},
+		{"application/pdf", "image/png", false},
+	} {
+		if got := mimeCompatible(tt.mimeType, tt.detected); got != tt.want {
+			t.Errorf("mimeCompatible(%q, %q) = %v, want %v", tt.mimeType, tt.detected, got, tt.want)
+		}
+	}
+}
+
+func TestExtractFileMimeOverride(t *testing.T) {
+	if _, err := ExtractBytesSync([]byte("probe"), "text/plain", nil); err != nil {
+		t.Skipf("native library unavailable: %v", err)
+	}
+	dir := t.TempDir()
+	path := filepath.Join(dir, "notes.vendortxt")
+	if err := os.WriteFile(path, []byte("override content"), 0o600); err != nil {
+		t.Fatal(err)
+	}
+	config := NewExtractionConfig(WithMimeOverrides(map[string]string{"vendortxt": "text/plain"}))
+	result, err := ExtractFileSync(path, config)
+	if err != nil {
+		t.Fatalf("ExtractFileSync failed: %v", err)
+	}
+	if result.MimeType != "text/plain" || result.Content != "override content" {
+		t.Fatalf("unexpected result %q (%s)", result.Content, result.MimeType)
+	}
+
+	pdfPath := filepath.Join(dir, "scan.vendortxt")
+	if err := os.WriteFile(pdfPath, selfTestPDF, 0o600); err != nil {
+		t.Fatal(err)
+	}
+	config = NewExtractionConfig(WithMimeOverrides(map[string]string{"vendortxt": docxMime}))
+	var valErr *ValidationError
+	if _, err := ExtractFileSync(pdfPath, config); !errors.As(err, &valErr) {
+		t.Fatalf("expected ValidationError for a PDF overridden as DOCX, got %v", err)
+	}
+}
+
+// TestBatchMimeOverrideMatchesSingle verifies that a file with a MIME override is extracted
+// the same way in a batch as on its own, here decoded from its legacy encoding.
+func TestBatchMimeOverrideMatchesSingle(t *testing.T) {
+	if _, err := ExtractBytesSync([]byte("probe"), "text/plain", nil); err != nil {
+		t.Skipf("native library unavailable: %v", err)
+	}
+	path := filepath.Join(t.TempDir(), "notes.vendortxt")
+	if err := os.WriteFile(path, []byte("caf\xe9 cr\xe8me"), 0o600); err != nil {
+		t.Fatal(err)
+	}
+	config := NewExtractionConfig(
+		WithMimeOverrides(map[string]string{"vendortxt": "text/plain"}),
+		WithSourceEncoding("latin1"),
+	)
+	single, err := ExtractFileSync(path, config)
+	if err != nil {
+		t.Fatalf("ExtractFileSync failed: %v", err)
+	}
+	batch, err := BatchExtractFilesSync([]string{path}, config)
+	if err != nil {
+		t.Fatalf("BatchExtractFilesSync failed: %v", err)
+	}
+	if single.Content != "café crème" || len(batch) != 1 || batch[0].Content != single.Content {
+		t.Fatalf("batch extraction differs from single: %q, %+v", single.Content, batch)
+	}
+	if batch[0].Metadata.SourceEncoding == nil || single.Metadata.SourceEncoding == nil ||
+		*batch[0].Metadata.SourceEncoding != *single.Metadata.SourceEncoding {
+		t.Fatal("expected both extractions to report the source encoding")
+	}
+}
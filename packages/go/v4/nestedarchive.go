@@ -0,0 +1,149 @@
+package kreuzberg
+
+import (
+	"archive/zip"
+	"bytes"
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"path"
+	"strings"
+)
+
+const (
+	// DefaultMaxRecursionDepth is how many levels of nested archives are opened when no
+	// MaxRecursionDepth is configured.
+	DefaultMaxRecursionDepth = 2
+	// DefaultMaxExtractedBytes is the total uncompressed size of the nested archives opened
+	// for one document when no MaxExtractedBytes is configured.
+	DefaultMaxExtractedBytes = 256 << 20
+)
+
+var zipMagic = []byte("PK\x03\x04")
+
+var errExtractedBytesLimit = errors.New("the maximum of extracted bytes has been reached")
+
+// archiveNesting is the state shared by the extractions of an archive and the archives
+// nested in it: the depth of the archive being extracted and the bytes left to open.
+type archiveNesting struct {
+	depth     int
+	remaining int64
+}
+
+type archiveNestingKey struct{}
+
+// applyNestedArchives extracts the ZIP archives among the members of a ZIP archive result
+// and appends the text of each as a "=== path ===" member section, so applyPartSeparator
+// makes it a part. Each nested archive is extracted like the outer one, which opens the
+// archives nested in it in turn until MaxRecursionDepth is reached. Skipped archives are
+// reported with warnings, and so are archives that fail to extract. Nested archives are
+// extracted as application/zip, or with the registered Go extractor that read the outer
+// archive, if any.
+func applyNestedArchives(ctx context.Context, result *ExtractionResult, config *ExtractionConfig, src *documentSource) error {
+	if _, ok := result.Metadata.ArchiveMetadata(); !ok {
+		return nil
+	}
+	data, err := src.bytes()
+	if err != nil || !bytes.HasPrefix(data, zipMagic) {
+		return err
+	}
+	reader, err := zip.NewReader(bytes.NewReader(data), int64(len(data)))
+	if err != nil {
+		// The native library has already extracted what it could read.
+		return nil
+	}
+
+	nesting, _ := ctx.Value(archiveNestingKey{}).(*archiveNesting)
+	if nesting == nil {
+		nesting = &archiveNesting{remaining: DefaultMaxExtractedBytes}
+		if config.MaxExtractedBytes != nil {
+			nesting.remaining = int64(*config.MaxExtractedBytes)
+		}
+	}
+	maxDepth := DefaultMaxRecursionDepth
+	if config.MaxRecursionDepth != nil {
+		maxDepth = *config.MaxRecursionDepth
+	}
+
+	nestedMime := "application/zip"
+	if lookupExtractor(result.MimeType) != nil {
+		nestedMime = result.MimeType
+	}
+
+	var sections strings.Builder
+	for _, file := range reader.File {
+		if file.FileInfo().IsDir() || normalizeExtension(path.Ext(file.Name)) != "zip" {
+			continue
+		}
+		if nesting.depth >= maxDepth {
+			result.addWarning(WarningCodeRecursionLimit, 0,
+				fmt.Sprintf("nested archive %q was not opened: it is deeper than the maximum recursion depth of %d", file.Name, maxDepth))
+			continue
+		}
+		member, err := readNestedArchive(file, nesting.remaining)
+		if errors.Is(err, errExtractedBytesLimit) {
+			result.addWarning(WarningCodeExtractedBytesLimit, 0, fmt.Sprintf("nested archive %q was not opened: %v", file.Name, err))
+			nesting.remaining = 0
+			continue
+		}
+		if err != nil {
+			result.addWarning(WarningCodeNestedArchiveFailed, 0, fmt.Sprintf("nested archive %q could not be read: %v", file.Name, err))
+			continue
+		}
+		nesting.remaining -= int64(len(member))
+		if !bytes.HasPrefix(member, zipMagic) {
+			continue
+		}
+
+		nesting.depth++
+		nested, err := extractBytes(context.WithValue(ctx, archiveNestingKey{}, nesting), member, nestedMime, config)
+		nesting.depth--
+		if err != nil {
+			if ctx.Err() != nil {
+				return ctx.Err()
+			}
+			result.addWarning(WarningCodeNestedArchiveFailed, 0, fmt.Sprintf("nested archive %q could not be extracted: %v", file.Name, err))
+			continue
+		}
+		for _, warning := range nested.Warnings {
+			warning.Message = file.Name + ": " + warning.Message
+			result.Warnings = append(result.Warnings, warning)
+		}
+		fmt.Fprintf(&sections, "=== %s ===\n%s\n\n", file.Name, nested.Content)
+	}
+	if sections.Len() == 0 {
+		return nil
+	}
+
+	content := result.Content
+	if !strings.Contains(content, archiveContentsMarker) {
+		content = strings.TrimRight(content, "\n") + archiveContentsMarker
+	} else if !strings.HasSuffix(content, "\n\n") {
+		content = strings.TrimRight(content, "\n") + "\n\n"
+	}
+	result.Content = content + sections.String()
+	// The chunks and languages the native library found do not cover the nested archives.
+	return applyContentFeatures(ctx, result, config, extractBytesNative)
+}
+
+// readNestedArchive reads the nested archive file, failing without reading further once it
+// turns out larger than limit bytes, whatever size its header declares.
+func readNestedArchive(file *zip.File, limit int64) ([]byte, error) {
+	if limit <= 0 || file.UncompressedSize64 > uint64(limit) {
+		return nil, errExtractedBytesLimit
+	}
+	rc, err := file.Open()
+	if err != nil {
+		return nil, err
+	}
+	defer rc.Close()
+	data, err := io.ReadAll(io.LimitReader(rc, limit+1))
+	if err != nil {
+		return nil, err
+	}
+	if int64(len(data)) > limit {
+		return nil, errExtractedBytesLimit
+	}
+	return data, nil
+}
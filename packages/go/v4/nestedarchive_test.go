@@ -0,0 +1,149 @@
+package kreuzberg
+
+import (
+	"archive/zip"
+	"bytes"
+	"io"
+	"path"
+	"strings"
+	"testing"
+)
+
+// testZipMime is a ZIP-based archive type the native library does not handle.
+const testZipMime = "application/x-kreuzberg-test-zip"
+
+// registerZipTestExtractor registers a Go extractor for testZipMime archives that lists the
+// members and includes the text of .txt members the way the native archive extractor does.
+func registerZipTestExtractor(t *testing.T) {
+	t.Helper()
+	if err := RegisterExtractor(testZipMime, func(data []byte, _ *ExtractionConfig) (*ExtractionResult, error) {
+		reader, err := zip.NewReader(bytes.NewReader(data), int64(len(data)))
+		if err != nil {
+			return nil, err
+		}
+		archive := &ArchiveMetadata{Format: "ZIP", FileCount: len(reader.File)}
+		var members strings.Builder
+		for _, file := range reader.File {
+			archive.FileList = append(archive.FileList, file.Name)
+			if path.Ext(file.Name) != ".txt" {
+				continue
+			}
+			rc, err := file.Open()
+			if err != nil {
+				return nil, err
+			}
+			text, err := io.ReadAll(rc)
+			_ = rc.Close()
+			if err != nil {
+				return nil, err
+			}
+			members.WriteString("=== " + file.Name + " ===\n" + string(text) + "\n\n")
+		}
+		result := &ExtractionResult{Content: "ZIP Archive" + archiveContentsMarker + members.String()}
+		result.Metadata.Format = FormatMetadata{Type: FormatArchive, Archive: archive}
+		return result, nil
+	}); err != nil {
+		t.Fatalf("RegisterExtractor failed: %v", err)
+	}
+	t.Cleanup(func() { _ = UnregisterExtractor(testZipMime) })
+}
+
+func testZip(t *testing.T, files map[string][]byte) []byte {
+	t.Helper()
+	var buf bytes.Buffer
+	w := zip.NewWriter(&buf)
+	for name, data := range files {
+		f, err := w.Create(name)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if _, err := f.Write(data); err != nil {
+			t.Fatal(err)
+		}
+	}
+	if err := w.Close(); err != nil {
+		t.Fatal(err)
+	}
+	return buf.Bytes()
+}
+
+func TestNestedArchiveRecursionDepth(t *testing.T) {
+	registerZipTestExtractor(t)
+	level2 := testZip(t, map[string][]byte{"deep.txt": []byte("deepest text")})
+	level1 := testZip(t, map[string][]byte{"middle.txt": []byte("middle text"), "level2.zip": level2})
+	outer := testZip(t, map[string][]byte{"top.txt": []byte("top text"), "level1.zip": level1})
+
+	result, err := ExtractBytesSync(outer, testZipMime, NewExtractionConfig(WithMaxRecursionDepth(1)))
+	if err != nil {
+		t.Fatalf("ExtractBytesSync failed: %v", err)
+	}
+	for _, want := range []string{"top text", "=== level1.zip ===", "middle text"} {
+		if !strings.Contains(result.Content, want) {
+			t.Errorf("content lacks %q:\n%s", want, result.Content)
+		}
+	}
+	if strings.Contains(result.Content, "deepest text") {
+		t.Errorf("archive below the depth limit was opened:\n%s", result.Content)
+	}
+	if len(result.Warnings) != 1 || result.Warnings[0].Code != WarningCodeRecursionLimit ||
+		!strings.Contains(result.Warnings[0].Message, "level2.zip") {
+		t.Fatalf("expected one recursion limit warning for level2.zip, got %+v", result.Warnings)
+	}
+	if len(result.PartBoundaries) != 3 {
+		t.Errorf("expected summary, top.txt and level1.zip parts, got boundaries %v", result.PartBoundaries)
+	}
+
+	result, err = ExtractBytesSync(outer, testZipMime, nil)
+	if err != nil {
+		t.Fatalf("ExtractBytesSync failed: %v", err)
+	}
+	if !strings.Contains(result.Content, "deepest text") || len(result.Warnings) != 0 {
+		t.Errorf("default depth should open both levels, got warnings %+v:\n%s", result.Warnings, result.Content)
+	}
+
+	result, err = ExtractBytesSync(outer, testZipMime, NewExtractionConfig(WithMaxRecursionDepth(0)))
+	if err != nil {
+		t.Fatalf("ExtractBytesSync failed: %v", err)
+	}
+	if strings.Contains(result.Content, "middle text") || len(result.Warnings) != 1 {
+		t.Errorf("depth 0 opened a nested archive, warnings %+v:\n%s", result.Warnings, result.Content)
+	}
+}
+
+// TestNestedArchiveChunked verifies that the text of nested archives is chunked with the
+// rest of the content.
+func TestNestedArchiveChunked(t *testing.T) {
+	registerZipTestExtractor(t)
+	inner := testZip(t, map[string][]byte{"inner.txt": []byte("inner text")})
+	outer := testZip(t, map[string][]byte{"outer.txt": []byte("outer text"), "inner.zip": inner})
+
+	result, err := ExtractBytesSync(outer, testZipMime, NewExtractionConfig(WithChunking(WithMaxChars(1000))))
+	if err != nil {
+		t.Fatalf("ExtractBytesSync failed: %v", err)
+	}
+	var chunked strings.Builder
+	for _, chunk := range result.Chunks {
+		chunked.WriteString(chunk.Content)
+	}
+	if !strings.Contains(chunked.String(), "inner text") {
+		t.Fatalf("expected the nested archive's text in the chunks, got %+v", result.Chunks)
+	}
+}
+
+func TestNestedArchiveExtractedBytes(t *testing.T) {
+	registerZipTestExtractor(t)
+	inner := testZip(t, map[string][]byte{"inner.txt": bytes.Repeat([]byte("x"), 4096)})
+	outer := testZip(t, map[string][]byte{"a.zip": inner})
+
+	result, err := ExtractBytesSync(outer, testZipMime, NewExtractionConfig(WithMaxExtractedBytes(len(inner)-1)))
+	if err != nil {
+		t.Fatalf("ExtractBytesSync failed: %v", err)
+	}
+	if strings.Contains(result.Content, "a.zip ===") || len(result.Warnings) != 1 || result.Warnings[0].Code != WarningCodeExtractedBytesLimit {
+		t.Fatalf("expected the archive to be skipped with a warning, got %+v:\n%s", result.Warnings, result.Content)
+	}
+
+	if _, err := ExtractBytesSync(outer, testZipMime, NewExtractionConfig(WithMaxRecursionDepth(-1))); err == nil {
+		t.Fatal("expected a negative depth to fail validation")
+	}
+}
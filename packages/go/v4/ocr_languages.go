@@ -0,0 +1,267 @@
+package kreuzberg
+
+import (
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"fmt"
+	"io"
+	"net/http"
+	"os"
+	"path/filepath"
+	"regexp"
+	"strings"
+	"time"
+)
+
+// ocrLanguageRelease is the tessdata_fast release language data is downloaded from.
+const ocrLanguageRelease = "4.1.0"
+
+// ocrLanguageDownloadURL is the location of the Tesseract language data, formatted with the
+// language code.
+var ocrLanguageDownloadURL = "https://github.com/tesseract-ocr/tessdata_fast/raw/" + ocrLanguageRelease + "/%s.traineddata"
+
+// ocrLanguageClient fetches language data; the timeout bounds downloads started without a
+// deadline.
+var ocrLanguageClient = &http.Client{Timeout: 10 * time.Minute}
+
+// tessdataSearchPaths are the directories the native Tesseract backend searches when
+// TESSDATA_PREFIX is not set, in its order.
+var tessdataSearchPaths = []string{
+	"/opt/homebrew/share/tessdata",
+	"/opt/homebrew/opt/tesseract/share/tessdata",
+	"/usr/local/opt/tesseract/share/tessdata",
+	"/usr/share/tesseract-ocr/5/tessdata",
+	"/usr/share/tesseract-ocr/4/tessdata",
+	"/usr/share/tessdata",
+	"/usr/local/share/tessdata",
+	`C:\Program Files\Tesseract-OCR\tessdata`,
+	`C:\ProgramData\Tesseract-OCR\tessdata`,
+}
+
+var ocrLanguageCode = regexp.MustCompile(`^[A-Za-z0-9_]+$`)
+
+// OCRLanguageDownload reports the progress of a language data download started by
+// OCRConfig.AutoDownloadLanguages.
+type OCRLanguageDownload struct {
+	Language string
+	// Path is the traineddata file being written.
+	Path            string
+	BytesDownloaded int64
+	// TotalBytes is the size of the download, or -1 when the server does not report it.
+	TotalBytes int64
+	// Done is set on the last report for a language, once the file is in place.
+	Done bool
+}
+
+// ensureOCRLanguages downloads the traineddata of the configured Tesseract languages that
+// are missing when OCRConfig.AutoDownloadLanguages is enabled, and returns the languages it
+// downloaded. The returned config points the Tesseract backend at the data directory; it is
+// config itself when auto-download is off.
+func ensureOCRLanguages(ctx context.Context, config *ExtractionConfig) (*ExtractionConfig, []string, error) {
+	if config == nil || config.OCR == nil || config.OCR.AutoDownloadLanguages == nil || !*config.OCR.AutoDownloadLanguages {
+		return config, nil, nil
+	}
+	ocr := config.OCR
+	if ocr.Backend != "" && !strings.EqualFold(ocr.Backend, "tesseract") {
+		return config, nil, nil
+	}
+	languages, err := tesseractLanguages(ocr)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	dir, err := tessdataDir(ocr)
+	if err != nil {
+		return nil, nil, err
+	}
+	// Concurrent extractions may fetch the same language; each writes its own temporary
+	// file and the rename into place is atomic, so no lock is held across the download.
+	var downloaded []string
+	for _, language := range languages {
+		path := filepath.Join(dir, language+".traineddata")
+		if _, err := os.Stat(path); err == nil {
+			continue
+		}
+		if err := downloadOCRLanguage(ctx, language, path, ocr.LanguageChecksums[language], ocr.DownloadProgress); err != nil {
+			return nil, downloaded, err
+		}
+		downloaded = append(downloaded, language)
+	}
+	return withTessdataPath(config, dir), downloaded, nil
+}
+
+// withTessdataPath returns a copy of config whose Tesseract configuration loads language data
+// from dir.
+func withTessdataPath(config *ExtractionConfig, dir string) *ExtractionConfig {
+	cfg := *config
+	cfg.encoded = nil
+	cfg.OCR = withTesseract(cfg.OCR, func(tesseract *TesseractConfig) { tesseract.TessdataPath = dir })
+	return &cfg
+}
+
+// withTesseract returns a copy of ocr whose Tesseract configuration, created when missing,
+// is changed by set.
+func withTesseract(ocr *OCRConfig, set func(tesseract *TesseractConfig)) *OCRConfig {
+	copied := *ocr
+	tesseract := TesseractConfig{}
+	if ocr.Tesseract != nil {
+		tesseract = *ocr.Tesseract
+	}
+	// The native backend takes the Tesseract configuration as a whole once it is present,
+	// so it has to carry the OCR language.
+	if tesseract.Language == "" && ocr.Language != nil {
+		tesseract.Language = *ocr.Language
+	}
+	set(&tesseract)
+	copied.Tesseract = &tesseract
+	return &copied
+}
+
+// tesseractLanguages returns the "+"-separated languages the Tesseract backend will load,
+// which come from the Tesseract configuration when it sets any.
+func tesseractLanguages(ocr *OCRConfig) ([]string, error) {
+	spec := ""
+	if ocr.Tesseract != nil {
+		spec = ocr.Tesseract.Language
+	}
+	if spec == "" && ocr.Language != nil {
+		spec = *ocr.Language
+	}
+	if strings.TrimSpace(spec) == "" {
+		spec = "eng"
+	}
+	var languages []string
+	for _, language := range strings.Split(spec, "+") {
+		language = strings.TrimSpace(language)
+		if !ocrLanguageCode.MatchString(language) {
+			return nil, newValidationErrorWithContext(fmt.Sprintf("invalid OCR language %q", language), nil, ErrorCodeValidation, nil)
+		}
+		languages = append(languages, language)
+	}
+	return languages, nil
+}
+
+// tessdataDir returns the directory language data is downloaded to: OCRConfig.LanguageDataDir,
+// else TESSDATA_PREFIX, else the first existing writable directory the native backend
+// searches, else a kreuzberg directory in the user cache directory.
+func tessdataDir(ocr *OCRConfig) (string, error) {
+	dir := ""
+	switch {
+	case ocr.LanguageDataDir != nil && *ocr.LanguageDataDir != "":
+		dir = *ocr.LanguageDataDir
+	case os.Getenv("TESSDATA_PREFIX") != "":
+		dir = os.Getenv("TESSDATA_PREFIX")
+	default:
+		for _, path := range tessdataSearchPaths {
+			if info, err := os.Stat(path); err == nil && info.IsDir() && dirWritable(path) {
+				return path, nil
+			}
+		}
+		cache, err := os.UserCacheDir()
+		if err != nil {
+			return "", newRuntimeErrorWithContext("no directory for OCR language data; set OCRConfig.LanguageDataDir", err, ErrorCodeInternal, nil)
+		}
+		dir = filepath.Join(cache, "kreuzberg", "tessdata")
+	}
+	if err := os.MkdirAll(dir, 0o755); err != nil {
+		return "", newIOErrorWithContext(fmt.Sprintf("failed to create OCR language directory %s", dir), err, ErrorCodeIo, nil)
+	}
+	return dir, nil
+}
+
+// dirWritable reports whether files can be created in dir.
+func dirWritable(dir string) bool {
+	f, err := os.CreateTemp(dir, ".kreuzberg-write-check-*")
+	if err != nil {
+		return false
+	}
+	f.Close()
+	os.Remove(f.Name())
+	return true
+}
+
+// downloadOCRLanguage fetches the traineddata for language into path, reporting progress
+// to progress when it is set. The download must match checksum, the hex SHA-256 digest of
+// the file, and appears at path only once it is complete and verified.
+func downloadOCRLanguage(ctx context.Context, language, path, checksum string, progress func(OCRLanguageDownload)) error {
+	missing := func(cause error) error {
+		return newMissingDependencyErrorWithContext("tesseract-"+language,
+			fmt.Sprintf("failed to download OCR language data for %q", language), cause, ErrorCodeMissingDependency, nil)
+	}
+	if checksum == "" {
+		return missing(fmt.Errorf("no SHA-256 checksum for %q; set one with WithOCRLanguageChecksums", language))
+	}
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, fmt.Sprintf(ocrLanguageDownloadURL, language), nil)
+	if err != nil {
+		return missing(err)
+	}
+	resp, err := ocrLanguageClient.Do(req)
+	if err != nil {
+		return missing(err)
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode != http.StatusOK {
+		return missing(fmt.Errorf("unexpected HTTP status %s", resp.Status))
+	}
+
+	tmp, err := os.CreateTemp(filepath.Dir(path), "."+language+".*.download")
+	if err != nil {
+		return newIOErrorWithContext(fmt.Sprintf("failed to write OCR language data to %s", filepath.Dir(path)), err, ErrorCodeIo, nil)
+	}
+	defer os.Remove(tmp.Name())
+
+	report := OCRLanguageDownload{Language: language, Path: path, TotalBytes: resp.ContentLength}
+	var body io.Reader = resp.Body
+	if progress != nil {
+		body = &downloadProgressReader{r: resp.Body, report: &report, progress: progress}
+	}
+	digest := sha256.New()
+	_, err = io.Copy(io.MultiWriter(tmp, digest), body)
+	if closeErr := tmp.Close(); err == nil {
+		err = closeErr
+	}
+	if err != nil {
+		return missing(err)
+	}
+	if got := hex.EncodeToString(digest.Sum(nil)); !strings.EqualFold(got, checksum) {
+		return missing(fmt.Errorf("SHA-256 checksum mismatch: got %s, want %s", got, checksum))
+	}
+	if err := os.Rename(tmp.Name(), path); err != nil {
+		return newIOErrorWithContext(fmt.Sprintf("failed to write OCR language data to %s", path), err, ErrorCodeIo, nil)
+	}
+	if progress != nil {
+		report.Done = true
+		progress(report)
+	}
+	return nil
+}
+
+// downloadProgressReader reports the bytes read through it.
+type downloadProgressReader struct {
+	r        io.Reader
+	report   *OCRLanguageDownload
+	progress func(OCRLanguageDownload)
+}
+
+func (p *downloadProgressReader) Read(b []byte) (int, error) {
+	n, err := p.r.Read(b)
+	if n > 0 {
+		p.report.BytesDownloaded += int64(n)
+		p.progress(*p.report)
+	}
+	return n, err
+}
+
+// addLanguageDownloadWarnings records the languages downloaded for an extraction.
+func addLanguageDownloadWarnings(results []*ExtractionResult, languages []string) {
+	for _, result := range results {
+		if result == nil {
+			continue
+		}
+		for _, language := range languages {
+			result.addWarning(WarningCodeOCRLanguageDownloaded, 0, fmt.Sprintf("downloaded missing OCR language data for %q", language))
+		}
+	}
+}
@@ -0,0 +1,178 @@
+package kreuzberg
+
+import (
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"reflect"
+	"strings"
+	"testing"
+)
+
+// testLanguageChecksums are the digests of the files served by serveTestLanguages.
+func testLanguageChecksums(languages ...string) map[string]string {
+	checksums := map[string]string{}
+	for _, language := range languages {
+		sum := sha256.Sum256([]byte("traineddata for " + language))
+		checksums[language] = hex.EncodeToString(sum[:])
+	}
+	return checksums
+}
+
+// serveTestLanguages serves "<lang>.traineddata" for the given languages and 404 otherwise.
+func serveTestLanguages(t *testing.T, languages ...string) *[]string {
+	t.Helper()
+	var requested []string
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		language := strings.TrimSuffix(strings.TrimPrefix(r.URL.Path, "/"), ".traineddata")
+		requested = append(requested, language)
+		for _, known := range languages {
+			if known == language {
+				_, _ = w.Write([]byte("traineddata for " + language))
+				return
+			}
+		}
+		http.NotFound(w, r)
+	}))
+	t.Cleanup(server.Close)
+
+	previous := ocrLanguageDownloadURL
+	ocrLanguageDownloadURL = server.URL + "/%s.traineddata"
+	t.Cleanup(func() { ocrLanguageDownloadURL = previous })
+	return &requested
+}
+
+func TestEnsureOCRLanguages(t *testing.T) {
+	t.Setenv("TESSDATA_PREFIX", "")
+	requested := serveTestLanguages(t, "deu", "fra")
+	dir := t.TempDir()
+	if err := os.WriteFile(filepath.Join(dir, "eng.traineddata"), []byte("installed"), 0o644); err != nil {
+		t.Fatal(err)
+	}
+
+	var reports []OCRLanguageDownload
+	config := NewExtractionConfig(WithOCR(
+		WithOCRLanguage("eng+deu+fra"),
+		WithOCRAutoDownloadLanguages(true),
+		WithOCRLanguageDataDir(dir),
+		WithOCRLanguageChecksums(testLanguageChecksums("deu", "fra")),
+		WithOCRDownloadProgress(func(p OCRLanguageDownload) { reports = append(reports, p) }),
+	))
+	resolved, downloaded, err := ensureOCRLanguages(context.Background(), config)
+	if err != nil {
+		t.Fatalf("ensureOCRLanguages: %v", err)
+	}
+	if !reflect.DeepEqual(downloaded, []string{"deu", "fra"}) || !reflect.DeepEqual(*requested, []string{"deu", "fra"}) {
+		t.Fatalf("downloaded %v after requesting %v, want deu and fra", downloaded, *requested)
+	}
+	data, err := os.ReadFile(filepath.Join(dir, "deu.traineddata"))
+	if err != nil || string(data) != "traineddata for deu" {
+		t.Fatalf("deu.traineddata = %q, %v", data, err)
+	}
+	if os.Getenv("TESSDATA_PREFIX") != "" {
+		t.Fatal("TESSDATA_PREFIX was changed")
+	}
+	if tesseract := resolved.OCR.Tesseract; tesseract == nil || tesseract.TessdataPath != dir || tesseract.Language != "eng+deu+fra" {
+		t.Fatalf("resolved Tesseract config %+v, want data path %q and the OCR languages", tesseract, dir)
+	}
+	if config.OCR.Tesseract != nil {
+		t.Fatal("the caller's config was modified")
+	}
+
+	last := reports[len(reports)-1]
+	if !last.Done || last.Language != "fra" || last.BytesDownloaded != int64(len("traineddata for fra")) || last.Path != filepath.Join(dir, "fra.traineddata") {
+		t.Fatalf("unexpected final progress report %+v", last)
+	}
+
+	result := &ExtractionResult{}
+	addLanguageDownloadWarnings([]*ExtractionResult{result, nil}, downloaded)
+	if len(result.Warnings) != 2 || result.Warnings[0].Code != WarningCodeOCRLanguageDownloaded {
+		t.Fatalf("unexpected warnings %+v", result.Warnings)
+	}
+
+	// Installed languages are not downloaded again.
+	*requested = nil
+	_, downloaded, err = ensureOCRLanguages(context.Background(), config)
+	if err != nil || len(downloaded) != 0 || len(*requested) != 0 {
+		t.Fatalf("second call downloaded %v (requested %v), err %v", downloaded, *requested, err)
+	}
+}
+
+func TestEnsureOCRLanguagesFailures(t *testing.T) {
+	t.Setenv("TESSDATA_PREFIX", "")
+	requested := serveTestLanguages(t, "deu")
+	dir := t.TempDir()
+
+	config := NewExtractionConfig(WithOCR(
+		WithTesseract(WithTesseractLanguage("xyz")),
+		WithOCRAutoDownloadLanguages(true),
+		WithOCRLanguageDataDir(dir),
+		WithOCRLanguageChecksums(testLanguageChecksums("xyz")),
+	))
+	_, _, err := ensureOCRLanguages(context.Background(), config)
+	var missing *MissingDependencyError
+	if !errors.As(err, &missing) {
+		t.Fatalf("expected MissingDependencyError, got %v", err)
+	}
+
+	// A download that does not match its checksum is discarded.
+	config.OCR.Tesseract.Language = "deu"
+	config.OCR.LanguageChecksums = testLanguageChecksums("fra")
+	config.OCR.LanguageChecksums["deu"] = config.OCR.LanguageChecksums["fra"]
+	if _, _, err := ensureOCRLanguages(context.Background(), config); !errors.As(err, &missing) || !strings.Contains(err.Error(), "checksum") {
+		t.Fatalf("expected a checksum mismatch, got %v", err)
+	}
+
+	// Languages without a checksum are not fetched at all.
+	*requested = nil
+	config.OCR.LanguageChecksums = nil
+	if _, _, err := ensureOCRLanguages(context.Background(), config); !errors.As(err, &missing) || len(*requested) != 0 {
+		t.Fatalf("expected no request without a checksum, got %v after requesting %v", err, *requested)
+	}
+	if entries, _ := os.ReadDir(dir); len(entries) != 0 {
+		t.Fatalf("failed download left files behind: %v", entries)
+	}
+
+	config.OCR.Tesseract.Language = "../eng"
+	if _, _, err := ensureOCRLanguages(context.Background(), config); err == nil {
+		t.Fatal("expected an invalid language code to be rejected")
+	}
+
+	for _, disabled := range []*ExtractionConfig{
+		NewExtractionConfig(WithOCR(WithOCRLanguage("xyz"))),
+		NewExtractionConfig(WithOCR(WithOCRLanguage("xyz"), WithOCRAutoDownloadLanguages(true), WithOCRBackend("paddleocr"))),
+	} {
+		if resolved, downloaded, err := ensureOCRLanguages(context.Background(), disabled); err != nil || downloaded != nil || resolved != disabled {
+			t.Fatalf("expected no download, got %v, %v", downloaded, err)
+		}
+	}
+}
+
+func TestTessdataDirSkipsReadOnlyDirectories(t *testing.T) {
+	if os.Geteuid() == 0 {
+		t.Skip("root can write to read-only directories")
+	}
+	t.Setenv("TESSDATA_PREFIX", "")
+	t.Setenv("XDG_CACHE_HOME", t.TempDir())
+	readOnly := t.TempDir()
+	if err := os.Chmod(readOnly, 0o555); err != nil {
+		t.Fatal(err)
+	}
+	t.Cleanup(func() { _ = os.Chmod(readOnly, 0o755) })
+	previous := tessdataSearchPaths
+	tessdataSearchPaths = []string{readOnly}
+	t.Cleanup(func() { tessdataSearchPaths = previous })
+
+	dir, err := tessdataDir(&OCRConfig{})
+	if err != nil {
+		t.Fatalf("tessdataDir: %v", err)
+	}
+	if dir == readOnly || filepath.Base(dir) != "tessdata" {
+		t.Fatalf("tessdataDir = %q, want the user cache directory", dir)
+	}
+}
@@ -0,0 +1,88 @@
+package kreuzberg
+
+import (
+	"context"
+	"errors"
+	"sync"
+	"time"
+)
+
+// errOCRPageTimeout reports that OCR of a single page exceeded its time budget.
+var errOCRPageTimeout = errors.New("ocr page timeout")
+
+// ocrPageTimeout returns the per-page OCR budget configured on config, or 0 for none.
+func ocrPageTimeout(config *ExtractionConfig) time.Duration {
+	if config == nil || config.OCR == nil || config.OCR.PageTimeoutMillis == nil || *config.OCR.PageTimeoutMillis <= 0 {
+		return 0
+	}
+	return time.Duration(*config.OCR.PageTimeoutMillis) * time.Millisecond
+}
+
+// nativeStartKey is the context key of the function extractBytesNative calls once it holds
+// ffiMutex.
+type nativeStartKey struct{}
+
+// withNativeStart returns a context that makes extractBytesNative call started once the
+// native library takes up the call.
+func withNativeStart(ctx context.Context, started func()) context.Context {
+	return context.WithValue(ctx, nativeStartKey{}, started)
+}
+
+// notifyNativeStart calls the function withNativeStart put into ctx, if any.
+func notifyNativeStart(ctx context.Context) {
+	if started, ok := ctx.Value(nativeStartKey{}).(func()); ok {
+		started()
+	}
+}
+
+// extractPageWithTimeout runs a single-page native extraction and drops its result after
+// timeout, returning errOCRPageTimeout.
+func extractPageWithTimeout(ctx context.Context, data []byte, mimeType string, config *ExtractionConfig, timeout time.Duration) (*ExtractionResult, error) {
+	return extractWithTimeout(ctx, data, mimeType, config, timeout, extractBytesNative)
+}
+
+// extractWithTimeout is extractPageWithTimeout with extract doing the extraction. The budget
+// starts once the native library takes up the page, so time spent queued behind other calls
+// does not count. A native call cannot be interrupted: like runWithContext, this returns as
+// soon as the budget is spent and abandons the call, which holds ffiMutex until it finishes,
+// so later native calls, including the next page, wait for it. The timeout therefore loses
+// the slow page's text without shortening the extraction. A page still queued when it is
+// abandoned is dropped without running.
+func extractWithTimeout(ctx context.Context, data []byte, mimeType string, config *ExtractionConfig, timeout time.Duration,
+	extract func(context.Context, []byte, string, *ExtractionConfig) (*ExtractionResult, error)) (*ExtractionResult, error) {
+	if timeout <= 0 {
+		return extract(ctx, data, mimeType, config)
+	}
+
+	type outcome struct {
+		result *ExtractionResult
+		err    error
+	}
+	pageCtx, cancel := context.WithCancel(ctx)
+	defer cancel()
+	started := make(chan struct{})
+	done := make(chan outcome, 1)
+	go func() {
+		result, err := extract(withNativeStart(pageCtx, sync.OnceFunc(func() { close(started) })), data, mimeType, config)
+		done <- outcome{result: result, err: err}
+	}()
+
+	select {
+	case out := <-done:
+		return out.result, out.err
+	case <-ctx.Done():
+		return nil, ctx.Err()
+	case <-started:
+	}
+
+	timer := time.NewTimer(timeout)
+	defer timer.Stop()
+	select {
+	case out := <-done:
+		return out.result, out.err
+	case <-ctx.Done():
+		return nil, ctx.Err()
+	case <-timer.C:
+		return nil, errOCRPageTimeout
+	}
+}
@@ -0,0 +1,149 @@
+package kreuzberg
+
+import (
+	"context"
+	"errors"
+	"math/rand"
+	"slices"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestWithOCRPageTimeout(t *testing.T) {
+	config := NewExtractionConfig(WithOCR(WithOCRPageTimeout(1500 * time.Millisecond)))
+	if config.OCR.PageTimeoutMillis == nil || *config.OCR.PageTimeoutMillis != 1500 {
+		t.Fatalf("expected 1500ms page timeout, got %v", config.OCR.PageTimeoutMillis)
+	}
+	if got := ocrPageTimeout(config); got != 1500*time.Millisecond {
+		t.Errorf("ocrPageTimeout = %s", got)
+	}
+	if got := ocrPageTimeout(NewExtractionConfig()); got != 0 {
+		t.Errorf("expected no timeout by default, got %s", got)
+	}
+}
+
+// TestOCRPageTimeoutSkipsHugePage OCRs a TIFF whose first frame is a huge noise image and
+// verifies that only that page is skipped.
+func TestOCRPageTimeoutSkipsHugePage(t *testing.T) {
+	const size = 6000
+	rng := rand.New(rand.NewSource(1))
+	noise := make([]byte, size*size)
+	rng.Read(noise)
+	data := encodeTIFF(tiffTestFrame{width: size, height: size, pixels: noise}, renderWord("BRAVO"))
+
+	config := NewExtractionConfig(
+		WithOCR(WithOCRBackend("tesseract"), WithOCRPageTimeout(2*time.Second)),
+		WithPages(WithExtractPages(true)),
+	)
+	result, err := ExtractBytesSync(data, "image/tiff", config)
+	if err != nil {
+		t.Skipf("TIFF OCR unavailable: %v", err)
+	}
+	if len(result.Warnings) == 0 {
+		t.Skip("huge page was OCR'd within the timeout on this machine")
+	}
+
+	warning := result.Warnings[0]
+	if warning.Code != WarningCodeOCRPageTimeout || warning.PageNumber != 1 {
+		t.Errorf("unexpected warning: %+v", warning)
+	}
+	if len(result.Warnings) != 1 {
+		t.Errorf("only the huge page should time out, got %+v", result.Warnings)
+	}
+	if !strings.Contains(strings.ToUpper(result.Content), "BRAVO") {
+		t.Errorf("expected remaining page to be extracted, got %q", result.Content)
+	}
+}
+
+func TestExtractWithTimeoutAbandonsPage(t *testing.T) {
+	release := make(chan struct{})
+	defer close(release)
+	blocked := func(ctx context.Context, _ []byte, _ string, _ *ExtractionConfig) (*ExtractionResult, error) {
+		notifyNativeStart(ctx)
+		<-release
+		return &ExtractionResult{}, nil
+	}
+	start := time.Now()
+	if _, err := extractWithTimeout(context.Background(), nil, "image/png", NewExtractionConfig(), 20*time.Millisecond, blocked); !errors.Is(err, errOCRPageTimeout) {
+		t.Fatalf("expected a page timeout, got %v", err)
+	}
+	if elapsed := time.Since(start); elapsed > time.Second {
+		t.Fatalf("timeout returned after %s instead of abandoning the call", elapsed)
+	}
+
+	// Time spent waiting for the native library does not count against the page.
+	queued := func(ctx context.Context, _ []byte, _ string, _ *ExtractionConfig) (*ExtractionResult, error) {
+		time.Sleep(100 * time.Millisecond)
+		notifyNativeStart(ctx)
+		return &ExtractionResult{Content: "done"}, nil
+	}
+	result, err := extractWithTimeout(context.Background(), nil, "image/png", NewExtractionConfig(), 20*time.Millisecond, queued)
+	if err != nil || result.Content != "done" {
+		t.Fatalf("queued page = %+v, %v", result, err)
+	}
+}
+
+func TestOCRPDFPagesTimeout(t *testing.T) {
+	release := make(chan struct{})
+	defer close(release)
+	extract := func(ctx context.Context, data []byte, mimeType string, config *ExtractionConfig) (*ExtractionResult, error) {
+		if config.OCR == nil {
+			// The scan has no text layer.
+			return &ExtractionResult{MimeType: mimeType, Success: true}, nil
+		}
+		texts, _ := mustParsePDF(t, data).rawText()
+		notifyNativeStart(ctx)
+		if len(texts) == 1 && texts[0] == "Page 2" {
+			<-release
+		}
+		return &ExtractionResult{Content: strings.Join(texts, "") + " OCR"}, nil
+	}
+	config := NewExtractionConfig(
+		WithOCR(WithOCRPageTimeout(50*time.Millisecond)),
+		WithPages(WithInsertPageMarkers(true), WithExtractPages(true)),
+	)
+	result, err := ocrPDFPages(context.Background(), testSamplePDF(3), config, extract, nil)
+	if err != nil {
+		t.Fatalf("ocrPDFPages failed: %v", err)
+	}
+	want := "\n\n<!-- PAGE 1 -->\n\nPage 1 OCR\n\n<!-- PAGE 2 -->\n\n\n\n<!-- PAGE 3 -->\n\nPage 3 OCR"
+	if result.Content != want {
+		t.Fatalf("content = %q, want %q", result.Content, want)
+	}
+	if len(result.Warnings) != 1 || result.Warnings[0].Code != WarningCodeOCRPageTimeout || result.Warnings[0].PageNumber != 2 {
+		t.Fatalf("unexpected warnings %+v", result.Warnings)
+	}
+	if len(result.Pages) != 3 || result.Pages[2].Content != "Page 3 OCR" || result.Metadata.PageStructure.TotalCount != 3 {
+		t.Fatalf("unexpected pages %+v", result.Pages)
+	}
+	applyPipeline(result, config)
+	if !result.OCRUsed || !slices.Equal(result.OCRPages, []int{1, 3}) {
+		t.Fatalf("OCRUsed = %v, OCRPages = %v; want the pages that were OCR'd", result.OCRUsed, result.OCRPages)
+	}
+
+	// A document with a usable text layer is not OCR'd.
+	text := func(_ context.Context, _ []byte, mimeType string, config *ExtractionConfig) (*ExtractionResult, error) {
+		if config.OCR != nil {
+			t.Fatal("document with a text layer was OCR'd")
+		}
+		return &ExtractionResult{MimeType: mimeType, Content: strings.Repeat("readable words on the page ", 5)}, nil
+	}
+	if _, err := ocrPDFPages(context.Background(), testSamplePDF(3), config, text, nil); err != nil {
+		t.Fatalf("ocrPDFPages failed: %v", err)
+	}
+}
+
+func TestEncodeConfigOmitsPageTimeout(t *testing.T) {
+	config := NewExtractionConfig(WithOCR(WithOCRPageTimeout(time.Second)))
+	data, err := encodeConfig(config)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if strings.Contains(string(data), "page_timeout_millis") {
+		t.Fatalf("page timeout sent to the native library: %s", data)
+	}
+	if config.OCR.PageTimeoutMillis == nil {
+		t.Fatal("encodeConfig modified the config")
+	}
+}
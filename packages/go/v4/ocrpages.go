@@ -0,0 +1,292 @@
+package kreuzberg
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"fmt"
+	"slices"
+	"strconv"
+	"strings"
+	"time"
+	"unicode"
+)
+
+// Thresholds of the native PDF extractor's decision to OCR a document instead of using its
+// text layer. The native library does not report that decision, so they are copies of the
+// constants of the same names in crates/kreuzberg/src/extractors/pdf.rs, used by
+// evaluate_native_text_for_ocr; change them together.
+const (
+	pdfOCRMinTotalNonWhitespace   = 64  // MIN_TOTAL_NON_WHITESPACE
+	pdfOCRMinNonWhitespacePerPage = 32  // MIN_NON_WHITESPACE_PER_PAGE
+	pdfOCRMinMeaningfulWordLen    = 4   // MIN_MEANINGFUL_WORD_LEN
+	pdfOCRMinMeaningfulWords      = 3   // MIN_MEANINGFUL_WORDS
+	pdfOCRMinAlnumRatio           = 0.3 // MIN_ALNUM_RATIO
+)
+
+// pdfTextNeedsOCR reports whether the text layer of a PDF is too thin to use, by the rules
+// of the native evaluate_native_text_for_ocr, which the native PDF extractor applies before
+// it OCRs every page. The native extractor takes the whole text as one page, so its
+// per-page averages are the totals here.
+func pdfTextNeedsOCR(text string) bool {
+	text = strings.TrimSpace(text)
+	nonWhitespace, alnum := 0, 0
+	for _, r := range text {
+		if !unicode.IsSpace(r) {
+			nonWhitespace++
+			if unicode.IsLetter(r) || unicode.IsNumber(r) {
+				alnum++
+			}
+		}
+	}
+	if nonWhitespace == 0 || alnum == 0 {
+		return true
+	}
+	meaningful := 0
+	for _, word := range strings.Fields(text) {
+		letters := 0
+		for _, r := range word {
+			if unicode.IsLetter(r) || unicode.IsNumber(r) {
+				letters++
+			}
+		}
+		if letters >= pdfOCRMinMeaningfulWordLen {
+			meaningful++
+		}
+	}
+	ratio := float64(alnum) / float64(nonWhitespace)
+	switch {
+	case nonWhitespace >= pdfOCRMinTotalNonWhitespace && meaningful >= pdfOCRMinMeaningfulWords:
+		return false
+	case ratio < pdfOCRMinAlnumRatio && alnum < pdfOCRMinNonWhitespacePerPage:
+		return true
+	case nonWhitespace < pdfOCRMinTotalNonWhitespace && nonWhitespace < pdfOCRMinNonWhitespacePerPage:
+		return true
+	default:
+		return meaningful == 0 && nonWhitespace < pdfOCRMinNonWhitespacePerPage
+	}
+}
+
+// ocrsPDFPages reports whether a PDF is OCR'd one page at a time by the Go binding, which is
+// how a page timeout and WithParallelPages apply to its pages.
+func ocrsPDFPages(data []byte, mimeType string, config *ExtractionConfig) bool {
+	return splitsOCRPages(config) && isPDFMimeType(normalizeMimeType(mimeType)) && bytes.HasPrefix(data, []byte("%PDF-"))
+}
+
+// splitsOCRPages reports whether config OCRs documents one page at a time.
+func splitsOCRPages(config *ExtractionConfig) bool {
+	return config != nil && config.OCR != nil && (ocrPageTimeout(config) > 0 || parallelPages(config) > 1)
+}
+
+// readOCRPagesPDFFile returns the contents of path when it is a PDF that is OCR'd one page
+// at a time.
+func readOCRPagesPDFFile(path string, config *ExtractionConfig) ([]byte, bool) {
+	if !splitsOCRPages(config) {
+		return nil, false
+	}
+	return readPDFFile(path)
+}
+
+// extractPDFPages extracts a PDF whose pages are OCR'd one at a time. The document is first
+// extracted without OCR; when the native library would OCR it, because OCR is forced or its
+// text layer is too thin, every page is then OCR'd on its own as a single-page PDF and the
+// page texts replace the content. Chunks and detected languages are computed again from the
+// OCR'd content. Encrypted PDFs, and PDFs whose pages cannot be separated, are extracted by
+// the native library as a whole.
+func extractPDFPages(ctx context.Context, data []byte, config *ExtractionConfig) (*ExtractionResult, error) {
+	return ocrPDFPages(ctx, data, config, extractBytesNative, batchExtractBytesNative)
+}
+
+// ocrPDFPages is extractPDFPages extracting documents and pages with extract, and runs of
+// pages with extractBatch under WithParallelPages.
+func ocrPDFPages(ctx context.Context, data []byte, config *ExtractionConfig,
+	extract func(context.Context, []byte, string, *ExtractionConfig) (*ExtractionResult, error),
+	extractBatch func(context.Context, []BytesWithMime, *ExtractionConfig) ([]*ExtractionResult, error)) (*ExtractionResult, error) {
+	doc, err := parsePDFDocument(data)
+	if err != nil {
+		return extract(ctx, data, "application/pdf", config)
+	}
+	pages := doc.pages()
+	if doc.encrypted || len(pages) == 0 || slices.ContainsFunc(pages, func(p pdfPage) bool { return p.objectNumber == 0 }) {
+		return extract(ctx, data, "application/pdf", config)
+	}
+
+	textConfig := cloneConfig(config)
+	textConfig.OCR = nil
+	textConfig.ForceOCR = nil
+	result, err := extract(ctx, data, "application/pdf", textConfig)
+	if err != nil {
+		return nil, err
+	}
+	if (config.ForceOCR == nil || !*config.ForceOCR) && !pdfTextNeedsOCR(result.Content) {
+		return result, nil
+	}
+
+	pageConfig := &ExtractionConfig{UseCache: config.UseCache, OCR: config.OCR, ForceOCR: BoolPtr(true)}
+	timeout := ocrPageTimeout(config)
+	single := func(ctx context.Context, pdf []byte) (*ExtractionResult, error) {
+		return extractWithTimeout(ctx, pdf, "application/pdf", pageConfig, timeout, extract)
+	}
+	var batch pageBatchExtractor
+	// A batch cannot give up on one of its pages, so pages with a time budget are OCR'd one
+	// at a time.
+	if n := parallelPages(config); n > 1 && timeout <= 0 {
+		batchConfig := *pageConfig
+		batchConfig.MaxConcurrentExtractions = &n
+		batch = func(ctx context.Context, pdfs [][]byte) ([]*ExtractionResult, error) {
+			items := make([]BytesWithMime, len(pdfs))
+			for i, pdf := range pdfs {
+				items[i] = BytesWithMime{Data: pdf, MimeType: "application/pdf"}
+			}
+			return extractBatch(ctx, items, &batchConfig)
+		}
+	}
+	page := func(i int) ([]byte, error) {
+		pdf, _ := pdfPageDocument(data, doc, pages[i])
+		return pdf, nil
+	}
+
+	joiner := newPageTextJoiner(config)
+	var ocrPages []int
+	hocr := make([]string, len(pages))
+	err = extractPagesInOrder(ctx, len(pages), parallelPages(config), page, single, batch, func(i int, pageResult *ExtractionResult, err error) error {
+		text, err := pageOCRText(ctx, result, config, i+1, timeout, pageResult, err)
+		if err != nil {
+			return err
+		}
+		if pageResult != nil {
+			ocrPages = append(ocrPages, i+1)
+			if page := ocrPageHOCR(pageResult.Metadata); len(page) > 0 {
+				hocr[i] = page[0]
+			}
+			if degrees, ok := ocrRotations(pageResult.Metadata)[1]; ok {
+				addPageRotation(result, i+1, degrees)
+			}
+		}
+		joiner.add(i+1, text)
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+	joiner.apply(result, len(pages))
+	setOCRPages(result, ocrPages)
+	setPageHOCR(result, hocr)
+	if err := applyContentFeatures(ctx, result, config, extract); err != nil {
+		return nil, err
+	}
+	return result, nil
+}
+
+// applyContentFeatures computes the chunks and detected languages of result again from its
+// content, for content the Go binding put together after the native library was done.
+func applyContentFeatures(ctx context.Context, result *ExtractionResult, config *ExtractionConfig,
+	extract func(context.Context, []byte, string, *ExtractionConfig) (*ExtractionResult, error)) error {
+	if config.Chunking == nil && config.LanguageDetection == nil {
+		return nil
+	}
+	textConfig := &ExtractionConfig{UseCache: config.UseCache, Chunking: config.Chunking, LanguageDetection: config.LanguageDetection}
+	text, err := extract(ctx, []byte(result.Content), "text/plain", textConfig)
+	if err != nil {
+		return err
+	}
+	if config.Chunking != nil {
+		result.Chunks = text.Chunks
+		if structure := result.Metadata.PageStructure; structure != nil {
+			assignChunkPages(result.Chunks, structure.Boundaries)
+		}
+	}
+	if config.LanguageDetection != nil {
+		result.DetectedLanguages = text.DetectedLanguages
+	}
+	return nil
+}
+
+// pageOCRText returns the text of a page OCR'd on its own, given the result or error of
+// extracting it. A page that timed out, or failed while partial results are allowed, gets
+// a warning on result and no text; other errors are returned.
+func pageOCRText(ctx context.Context, result *ExtractionResult, config *ExtractionConfig, pageNumber int, timeout time.Duration, pageResult *ExtractionResult, err error) (string, error) {
+	switch {
+	case errors.Is(err, errOCRPageTimeout):
+		result.addWarning(WarningCodeOCRPageTimeout, pageNumber, fmt.Sprintf("OCR of page %d exceeded %s and was skipped", pageNumber, timeout))
+		return "", nil
+	case err != nil && (!allowPartialResults(config) || ctx.Err() != nil):
+		return "", err
+	case err != nil:
+		result.Success = false
+		result.addWarning(WarningCodePageFailed, pageNumber, fmt.Sprintf("page %d could not be extracted and was skipped: %v", pageNumber, err))
+		return "", nil
+	default:
+		return strings.TrimSpace(pageResult.Content), nil
+	}
+}
+
+// pageTextJoiner joins the texts of pages OCR'd one at a time the same way the native
+// library joins PDF pages: with the configured page marker before every page, or a blank
+// line between pages when markers are disabled.
+type pageTextJoiner struct {
+	content       strings.Builder
+	boundaries    []PageBoundary
+	pages         []PageContent
+	insertMarkers bool
+	extractPages  bool
+	markerFormat  string
+}
+
+func newPageTextJoiner(config *ExtractionConfig) *pageTextJoiner {
+	j := &pageTextJoiner{markerFormat: defaultPageMarkerFormat}
+	if pages := config.Pages; pages != nil {
+		j.insertMarkers = pages.InsertPageMarkers != nil && *pages.InsertPageMarkers
+		j.extractPages = pages.ExtractPages != nil && *pages.ExtractPages
+		if pages.MarkerFormat != nil {
+			j.markerFormat = *pages.MarkerFormat
+		}
+	}
+	return j
+}
+
+// add appends the text of the page numbered pageNumber.
+func (j *pageTextJoiner) add(pageNumber int, text string) {
+	if j.insertMarkers {
+		j.content.WriteString(strings.ReplaceAll(j.markerFormat, "{page_num}", strconv.Itoa(pageNumber)))
+	} else if len(j.boundaries) > 0 {
+		j.content.WriteString("\n\n")
+	}
+	start := j.content.Len()
+	j.content.WriteString(text)
+	j.boundaries = append(j.boundaries, PageBoundary{
+		ByteStart:  uint64(start),
+		ByteEnd:    uint64(j.content.Len()),
+		PageNumber: uint64(pageNumber),
+	})
+	if j.extractPages {
+		j.pages = append(j.pages, PageContent{PageNumber: uint64(pageNumber), Content: text})
+	}
+}
+
+// apply replaces the content of result, the texts of its pages and its page boundaries with
+// the joined pages; count is the number of pages of the document. Tables and images the
+// result already has for a page are kept.
+func (j *pageTextJoiner) apply(result *ExtractionResult, count int) {
+	result.Content = j.content.String()
+	if j.extractPages {
+		for _, page := range j.pages {
+			index := slices.IndexFunc(result.Pages, func(p PageContent) bool { return p.PageNumber == page.PageNumber })
+			if index < 0 {
+				result.Pages = append(result.Pages, page)
+				continue
+			}
+			result.Pages[index].Content = page.Content
+		}
+	} else {
+		result.Pages = nil
+	}
+	structure := result.Metadata.PageStructure
+	if structure == nil {
+		structure = &PageStructure{}
+		result.Metadata.PageStructure = structure
+	}
+	structure.TotalCount = uint64(count)
+	structure.UnitType = PageUnitTypePage
+	structure.Boundaries = j.boundaries
+}
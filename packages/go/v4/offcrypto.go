@@ -0,0 +1,413 @@
+package kreuzberg
+
+import (
+	"bytes"
+	"crypto/aes"
+	"crypto/cipher"
+	"crypto/sha1" // #nosec G505 -- mandated by the Office encryption formats
+	"crypto/sha256"
+	"crypto/sha512"
+	"encoding/base64"
+	"encoding/binary"
+	"encoding/xml"
+	"errors"
+	"hash"
+	"strings"
+	"unicode/utf16"
+)
+
+// Encrypted OOXML packages (MS-OFFCRYPTO) are stored as a CFB container holding the
+// encryption parameters and the encrypted ZIP package.
+const (
+	officeEncryptionInfoStream    = "EncryptionInfo"
+	officeEncryptedPackageStream  = "EncryptedPackage"
+	officeAgileSegmentSize        = 4096
+	officeStandardSpinCount       = 50000
+	officeMaxSpinCount            = 10_000_000
+	officeStandardFlagAES         = 0x20
+	officeAgilePasswordKeyEncrypt = "http://schemas.microsoft.com/office/2006/keyEncryptor/password"
+)
+
+// Block keys from MS-OFFCRYPTO 2.3.4.11 and 2.3.4.13.
+var (
+	officeBlockKeyVerifierInput = []byte{0xfe, 0xa7, 0xd2, 0x76, 0x3b, 0x4b, 0x9e, 0x79}
+	officeBlockKeyVerifierHash  = []byte{0xd7, 0xaa, 0x0f, 0x6d, 0x30, 0x61, 0x34, 0x4e}
+	officeBlockKeyEncryptedKey  = []byte{0x14, 0x6e, 0x0b, 0xe7, 0xab, 0xac, 0xd0, 0xd6}
+)
+
+var (
+	errOfficePasswordRejected = errors.New("none of the supplied passwords is correct")
+	errOfficeUnsupported      = errors.New("unsupported Office encryption")
+	errOfficeCorrupt          = errors.New("corrupt Office encryption data")
+)
+
+// isEncryptedOffice reports whether container is an encrypted OOXML package.
+func isEncryptedOffice(container *cfbFile) bool {
+	return container.hasStream(officeEncryptionInfoStream) && container.hasStream(officeEncryptedPackageStream)
+}
+
+// decryptOfficePackage tries each password against an encrypted OOXML container and
+// returns the decrypted ZIP package. It returns errOfficePasswordRejected when no password
+// opens the document, including when passwords is empty.
+func decryptOfficePackage(container *cfbFile, passwords []string) ([]byte, error) {
+	info, err := container.stream(officeEncryptionInfoStream)
+	if err != nil {
+		return nil, errOfficeCorrupt
+	}
+	pkg, err := container.stream(officeEncryptedPackageStream)
+	if err != nil || len(pkg) < 8 {
+		return nil, errOfficeCorrupt
+	}
+	if len(info) < 8 {
+		return nil, errOfficeCorrupt
+	}
+
+	major := binary.LittleEndian.Uint16(info[0:])
+	minor := binary.LittleEndian.Uint16(info[2:])
+	var key func(password string) ([]byte, error)
+	var decrypt func(key, pkg []byte) ([]byte, error)
+	switch {
+	case major == 4 && minor == 4:
+		params, err := parseAgileEncryptionInfo(info[8:])
+		if err != nil {
+			return nil, err
+		}
+		key, decrypt = params.key, params.decrypt
+	case (major == 2 || major == 3 || major == 4) && minor == 2:
+		params, err := parseStandardEncryptionInfo(info[8:])
+		if err != nil {
+			return nil, err
+		}
+		key, decrypt = params.key, params.decrypt
+	default:
+		return nil, errOfficeUnsupported
+	}
+
+	for _, password := range passwords {
+		secret, err := key(password)
+		if errors.Is(err, errOfficePasswordRejected) {
+			continue
+		}
+		if err != nil {
+			return nil, err
+		}
+		return decrypt(secret, pkg)
+	}
+	return nil, errOfficePasswordRejected
+}
+
+// agileEncryptionInfo is the XML descriptor of Agile encryption (MS-OFFCRYPTO 2.3.4.10).
+type agileEncryptionInfo struct {
+	KeyData       agileKeyParams `xml:"keyData"`
+	KeyEncryptors struct {
+		KeyEncryptor []struct {
+			URI          string         `xml:"uri,attr"`
+			EncryptedKey agileKeyParams `xml:"encryptedKey"`
+		} `xml:"keyEncryptor"`
+	} `xml:"keyEncryptors"`
+
+	password agileKeyParams
+}
+
+type agileKeyParams struct {
+	SpinCount                  int    `xml:"spinCount,attr"`
+	SaltSize                   int    `xml:"saltSize,attr"`
+	BlockSize                  int    `xml:"blockSize,attr"`
+	KeyBits                    int    `xml:"keyBits,attr"`
+	HashSize                   int    `xml:"hashSize,attr"`
+	CipherAlgorithm            string `xml:"cipherAlgorithm,attr"`
+	CipherChaining             string `xml:"cipherChaining,attr"`
+	HashAlgorithm              string `xml:"hashAlgorithm,attr"`
+	SaltValue                  string `xml:"saltValue,attr"`
+	EncryptedVerifierHashInput string `xml:"encryptedVerifierHashInput,attr"`
+	EncryptedVerifierHashValue string `xml:"encryptedVerifierHashValue,attr"`
+	EncryptedKeyValue          string `xml:"encryptedKeyValue,attr"`
+}
+
+func parseAgileEncryptionInfo(data []byte) (*agileEncryptionInfo, error) {
+	var info agileEncryptionInfo
+	if err := xml.Unmarshal(data, &info); err != nil {
+		return nil, errOfficeCorrupt
+	}
+	found := false
+	for _, encryptor := range info.KeyEncryptors.KeyEncryptor {
+		if encryptor.URI == officeAgilePasswordKeyEncrypt || encryptor.EncryptedKey.SpinCount > 0 {
+			info.password = encryptor.EncryptedKey
+			found = true
+			break
+		}
+	}
+	if !found {
+		return nil, errOfficeUnsupported
+	}
+	for _, params := range []agileKeyParams{info.KeyData, info.password} {
+		if params.CipherAlgorithm != "AES" || params.CipherChaining != "ChainingModeCBC" || officeHash(params.HashAlgorithm) == nil {
+			return nil, errOfficeUnsupported
+		}
+		if params.KeyBits <= 0 || params.KeyBits%8 != 0 || params.BlockSize != aes.BlockSize {
+			return nil, errOfficeCorrupt
+		}
+	}
+	return &info, nil
+}
+
+// key verifies password and returns the intermediate key that encrypts the package.
+func (info *agileEncryptionInfo) key(password string) ([]byte, error) {
+	p := info.password
+	salt, err1 := base64.StdEncoding.DecodeString(p.SaltValue)
+	verifierInput, err2 := base64.StdEncoding.DecodeString(p.EncryptedVerifierHashInput)
+	verifierHash, err3 := base64.StdEncoding.DecodeString(p.EncryptedVerifierHashValue)
+	encryptedKey, err4 := base64.StdEncoding.DecodeString(p.EncryptedKeyValue)
+	if err := errors.Join(err1, err2, err3, err4); err != nil {
+		return nil, errOfficeCorrupt
+	}
+
+	// The sizes and the spin count come from the file: sizes beyond the salt or the digest
+	// would slice out of range, and a huge spin count would hash for minutes.
+	newHash := officeHash(p.HashAlgorithm)
+	if p.SaltSize <= 0 || p.SaltSize > len(salt) || p.HashSize <= 0 || p.HashSize > newHash().Size() ||
+		p.SpinCount < 0 || p.SpinCount > officeMaxSpinCount {
+		return nil, errOfficeCorrupt
+	}
+	h := officePasswordHash(newHash, salt, password, p.SpinCount)
+	derive := func(blockKey []byte) []byte {
+		return officeFitKey(officeDigest(newHash, h, blockKey), p.KeyBits/8)
+	}
+
+	input, err := aesCBCDecrypt(derive(officeBlockKeyVerifierInput), salt, verifierInput)
+	if err != nil || len(input) < p.SaltSize {
+		return nil, errOfficeCorrupt
+	}
+	expected, err := aesCBCDecrypt(derive(officeBlockKeyVerifierHash), salt, verifierHash)
+	if err != nil || len(expected) < p.HashSize {
+		return nil, errOfficeCorrupt
+	}
+	if !bytes.Equal(officeDigest(newHash, input[:p.SaltSize])[:p.HashSize], expected[:p.HashSize]) {
+		return nil, errOfficePasswordRejected
+	}
+
+	secret, err := aesCBCDecrypt(derive(officeBlockKeyEncryptedKey), salt, encryptedKey)
+	if err != nil || len(secret) < p.KeyBits/8 {
+		return nil, errOfficeCorrupt
+	}
+	return secret[:p.KeyBits/8], nil
+}
+
+// decrypt decrypts an EncryptedPackage stream segment by segment.
+func (info *agileEncryptionInfo) decrypt(key, pkg []byte) ([]byte, error) {
+	salt, err := base64.StdEncoding.DecodeString(info.KeyData.SaltValue)
+	if err != nil || len(key) != info.KeyData.KeyBits/8 {
+		return nil, errOfficeCorrupt
+	}
+	size := binary.LittleEndian.Uint64(pkg)
+	body := pkg[8:]
+	newHash := officeHash(info.KeyData.HashAlgorithm)
+
+	out := make([]byte, 0, len(body))
+	var index [4]byte
+	for segment := 0; len(body) > 0; segment++ {
+		n := min(officeAgileSegmentSize, len(body))
+		binary.LittleEndian.PutUint32(index[:], uint32(segment)) // #nosec G115 -- bounded by the package size
+		iv := officeFitKey(officeDigest(newHash, salt, index[:]), info.KeyData.BlockSize)
+		plain, err := aesCBCDecrypt(key, iv, body[:n])
+		if err != nil {
+			return nil, errOfficeCorrupt
+		}
+		out = append(out, plain...)
+		body = body[n:]
+	}
+	if size > uint64(len(out)) {
+		return nil, errOfficeCorrupt
+	}
+	return out[:size], nil
+}
+
+// standardEncryptionInfo holds the parameters of Standard encryption (MS-OFFCRYPTO 2.3.4.5).
+type standardEncryptionInfo struct {
+	keyBytes              int
+	salt                  []byte
+	encryptedVerifier     []byte
+	verifierHashSize      int
+	encryptedVerifierHash []byte
+}
+
+func parseStandardEncryptionInfo(data []byte) (*standardEncryptionInfo, error) {
+	le := binary.LittleEndian
+	if len(data) < 4 {
+		return nil, errOfficeCorrupt
+	}
+	headerSize := int(le.Uint32(data))
+	data = data[4:]
+	if headerSize < 32 || len(data) < headerSize {
+		return nil, errOfficeCorrupt
+	}
+	header, verifier := data[:headerSize], data[headerSize:]
+	if le.Uint32(header)&officeStandardFlagAES == 0 {
+		return nil, errOfficeUnsupported
+	}
+	keyBits := int(le.Uint32(header[16:]))
+	if keyBits != 128 && keyBits != 192 && keyBits != 256 {
+		return nil, errOfficeUnsupported
+	}
+
+	if len(verifier) < 4 {
+		return nil, errOfficeCorrupt
+	}
+	saltSize := int(le.Uint32(verifier))
+	if saltSize != 16 || len(verifier) < 4+saltSize+16+4+32 {
+		return nil, errOfficeCorrupt
+	}
+	pos := 4
+	info := &standardEncryptionInfo{keyBytes: keyBits / 8}
+	info.salt = verifier[pos : pos+saltSize]
+	pos += saltSize
+	info.encryptedVerifier = verifier[pos : pos+16]
+	pos += 16
+	info.verifierHashSize = int(le.Uint32(verifier[pos:]))
+	pos += 4
+	info.encryptedVerifierHash = verifier[pos : pos+32]
+	if info.verifierHashSize != sha1.Size {
+		return nil, errOfficeCorrupt
+	}
+	return info, nil
+}
+
+// key derives the AES key for password (MS-OFFCRYPTO 2.3.4.7) and checks it against the
+// stored verifier (2.3.4.9).
+func (info *standardEncryptionInfo) key(password string) ([]byte, error) {
+	h := officePasswordHash(sha1.New, info.salt, password, officeStandardSpinCount)
+	final := officeDigest(sha1.New, h, []byte{0, 0, 0, 0})
+
+	var x1, x2 [64]byte
+	for i := range x1 {
+		x1[i], x2[i] = 0x36, 0x5c
+	}
+	for i, b := range final {
+		x1[i] ^= b
+		x2[i] ^= b
+	}
+	key := append(officeDigest(sha1.New, x1[:]), officeDigest(sha1.New, x2[:])...)[:info.keyBytes]
+
+	verifier, err := aesECBDecrypt(key, info.encryptedVerifier)
+	if err != nil {
+		return nil, errOfficeCorrupt
+	}
+	expected, err := aesECBDecrypt(key, info.encryptedVerifierHash)
+	if err != nil {
+		return nil, errOfficeCorrupt
+	}
+	if !bytes.Equal(officeDigest(sha1.New, verifier), expected[:info.verifierHashSize]) {
+		return nil, errOfficePasswordRejected
+	}
+	return key, nil
+}
+
+func (info *standardEncryptionInfo) decrypt(key, pkg []byte) ([]byte, error) {
+	size := binary.LittleEndian.Uint64(pkg)
+	body := pkg[8:]
+	body = body[:len(body)-len(body)%aes.BlockSize]
+	out, err := aesECBDecrypt(key, body)
+	if err != nil || size > uint64(len(out)) {
+		return nil, errOfficeCorrupt
+	}
+	return out[:size], nil
+}
+
+func officeHash(name string) func() hash.Hash {
+	switch name {
+	case "SHA1", "SHA-1":
+		return sha1.New
+	case "SHA256":
+		return sha256.New
+	case "SHA384":
+		return sha512.New384
+	case "SHA512":
+		return sha512.New
+	default:
+		return nil
+	}
+}
+
+func officeDigest(newHash func() hash.Hash, parts ...[]byte) []byte {
+	h := newHash()
+	for _, part := range parts {
+		h.Write(part)
+	}
+	return h.Sum(nil)
+}
+
+// officePasswordHash iterates the salted password hash spinCount times.
+func officePasswordHash(newHash func() hash.Hash, salt []byte, password string, spinCount int) []byte {
+	units := utf16.Encode([]rune(password))
+	encoded := make([]byte, 2*len(units))
+	for i, u := range units {
+		binary.LittleEndian.PutUint16(encoded[2*i:], u)
+	}
+
+	h := newHash()
+	digest := officeDigest(newHash, salt, encoded)
+	var iterator [4]byte
+	for i := range spinCount {
+		binary.LittleEndian.PutUint32(iterator[:], uint32(i)) // #nosec G115 -- spinCount is an int32 in the format
+		h.Reset()
+		h.Write(iterator[:])
+		h.Write(digest)
+		digest = h.Sum(digest[:0])
+	}
+	return digest
+}
+
+// officeFitKey truncates b to n bytes or pads it with 0x36 bytes.
+func officeFitKey(b []byte, n int) []byte {
+	if len(b) >= n {
+		return b[:n]
+	}
+	return append(b, bytes.Repeat([]byte{0x36}, n-len(b))...)
+}
+
+func aesCBCDecrypt(key, iv, data []byte) ([]byte, error) {
+	block, err := aes.NewCipher(key)
+	if err != nil {
+		return nil, err
+	}
+	if len(data)%aes.BlockSize != 0 || len(iv) < aes.BlockSize {
+		return nil, errOfficeCorrupt
+	}
+	out := make([]byte, len(data))
+	cipher.NewCBCDecrypter(block, iv[:aes.BlockSize]).CryptBlocks(out, data)
+	return out, nil
+}
+
+func aesECBDecrypt(key, data []byte) ([]byte, error) {
+	block, err := aes.NewCipher(key)
+	if err != nil {
+		return nil, err
+	}
+	if len(data)%aes.BlockSize != 0 {
+		return nil, errOfficeCorrupt
+	}
+	out := make([]byte, len(data))
+	for i := 0; i < len(data); i += aes.BlockSize {
+		block.Decrypt(out[i:i+aes.BlockSize], data[i:i+aes.BlockSize])
+	}
+	return out, nil
+}
+
+// officePackageMimeType identifies a decrypted OOXML package from its content types.
+func officePackageMimeType(pkg []byte) (string, bool) {
+	types, ok, err := readZipEntry(pkg, "[Content_Types].xml")
+	if err != nil || !ok {
+		return "", false
+	}
+	content := string(types)
+	switch {
+	case strings.Contains(content, "wordprocessingml"):
+		return mimeTypeDOCX, true
+	case strings.Contains(content, "spreadsheetml"):
+		return mimeTypeXLSX, true
+	case strings.Contains(content, "presentationml"):
+		return mimeTypePPTX, true
+	default:
+		return "", false
+	}
+}
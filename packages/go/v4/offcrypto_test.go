@@ -0,0 +1,369 @@
+package kreuzberg
+
+import (
+	"archive/zip"
+	"bytes"
+	"crypto/aes"
+	"crypto/cipher"
+	"crypto/sha512"
+	"encoding/base64"
+	"encoding/binary"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"unicode/utf16"
+)
+
+// testDocxPackage returns a minimal DOCX package large enough that its encrypted form is
+// stored in regular sectors rather than the mini stream.
+func testDocxPackage(t *testing.T) []byte {
+	t.Helper()
+	var buf bytes.Buffer
+	w := zip.NewWriter(&buf)
+	files := []struct{ name, body string }{
+		{"[Content_Types].xml", `<Types xmlns="http://schemas.openxmlformats.org/package/2006/content-types"><Override PartName="/word/document.xml" ContentType="application/vnd.openxmlformats-officedocument.wordprocessingml.document.main+xml"/></Types>`},
+		{"word/document.xml", `<w:document><w:body><w:p><w:r><w:t>` + strings.Repeat("secret ", 1000) + `</w:t></w:r></w:p></w:body></w:document>`},
+	}
+	for _, f := range files {
+		fw, err := w.CreateHeader(&zip.FileHeader{Name: f.name, Method: zip.Store})
+		if err != nil {
+			t.Fatal(err)
+		}
+		if _, err := fw.Write([]byte(f.body)); err != nil {
+			t.Fatal(err)
+		}
+	}
+	if err := w.Close(); err != nil {
+		t.Fatal(err)
+	}
+	return buf.Bytes()
+}
+
+// encryptTestOffice encrypts pkg with Agile encryption (AES-256, SHA-512) and wraps it in
+// a compound file, mirroring what Office writes for a password-protected document.
+func encryptTestOffice(t *testing.T, pkg []byte, password string) []byte {
+	t.Helper()
+	keySalt := bytes.Repeat([]byte{0x11}, 16)
+	passwordSalt := bytes.Repeat([]byte{0x22}, 16)
+	secret := bytes.Repeat([]byte{0x33}, 32)
+	verifierInput := bytes.Repeat([]byte{0x44}, 16)
+	const spinCount = 1000
+
+	h := officePasswordHash(sha512.New, passwordSalt, password, spinCount)
+	derive := func(blockKey []byte) []byte {
+		return officeFitKey(officeDigest(sha512.New, h, blockKey), 32)
+	}
+	encrypt := func(key, iv, data []byte) []byte {
+		block, err := aes.NewCipher(key)
+		if err != nil {
+			t.Fatal(err)
+		}
+		padded := append([]byte(nil), data...)
+		if rem := len(padded) % aes.BlockSize; rem != 0 {
+			padded = append(padded, make([]byte, aes.BlockSize-rem)...)
+		}
+		out := make([]byte, len(padded))
+		cipher.NewCBCEncrypter(block, iv[:aes.BlockSize]).CryptBlocks(out, padded)
+		return out
+	}
+	b64 := base64.StdEncoding.EncodeToString
+
+	info := fmt.Sprintf(`<?xml version="1.0" encoding="UTF-8" standalone="yes"?>`+
+		`<encryption xmlns="http://schemas.microsoft.com/office/2006/encryption" xmlns:p="http://schemas.microsoft.com/office/2006/keyEncryptor/password">`+
+		`<keyData saltSize="16" blockSize="16" keyBits="256" hashSize="64" cipherAlgorithm="AES" cipherChaining="ChainingModeCBC" hashAlgorithm="SHA512" saltValue="%s"/>`+
+		`<keyEncryptors><keyEncryptor uri="http://schemas.microsoft.com/office/2006/keyEncryptor/password">`+
+		`<p:encryptedKey spinCount="%d" saltSize="16" blockSize="16" keyBits="256" hashSize="64" cipherAlgorithm="AES" cipherChaining="ChainingModeCBC" hashAlgorithm="SHA512" saltValue="%s" encryptedVerifierHashInput="%s" encryptedVerifierHashValue="%s" encryptedKeyValue="%s"/>`+
+		`</keyEncryptor></keyEncryptors></encryption>`,
+		b64(keySalt), spinCount, b64(passwordSalt),
+		b64(encrypt(derive(officeBlockKeyVerifierInput), passwordSalt, verifierInput)),
+		b64(encrypt(derive(officeBlockKeyVerifierHash), passwordSalt, officeDigest(sha512.New, verifierInput))),
+		b64(encrypt(derive(officeBlockKeyEncryptedKey), passwordSalt, secret)),
+	)
+	encryptionInfo := append([]byte{4, 0, 4, 0, 0x40, 0, 0, 0}, info...)
+
+	encryptedPackage := binary.LittleEndian.AppendUint64(nil, uint64(len(pkg)))
+	var index [4]byte
+	for segment, rest := 0, pkg; len(rest) > 0; segment++ {
+		n := min(officeAgileSegmentSize, len(rest))
+		binary.LittleEndian.PutUint32(index[:], uint32(segment))
+		iv := officeFitKey(officeDigest(sha512.New, keySalt, index[:]), 16)
+		encryptedPackage = append(encryptedPackage, encrypt(secret, iv, rest[:n])...)
+		rest = rest[n:]
+	}
+
+	return buildTestCFB(t, []testCFBStream{
+		{officeEncryptionInfoStream, encryptionInfo},
+		{officeEncryptedPackageStream, encryptedPackage},
+	})
+}
+
+type testCFBStream struct {
+	name string
+	data []byte
+}
+
+// buildTestCFB writes a version 3 compound file with a single FAT sector. Streams below
+// the 4096-byte cutoff are placed in the mini stream.
+func buildTestCFB(t *testing.T, streams []testCFBStream) []byte {
+	t.Helper()
+	const sectorSize = 512
+	le := binary.LittleEndian
+	fat := []uint32{0xFFFFFFFD}
+	sectors := [][]byte{nil}
+	allocate := func(data []byte) uint32 {
+		if len(data) == 0 {
+			return cfbEndOfChain
+		}
+		start := uint32(len(sectors))
+		for off := 0; off < len(data); off += sectorSize {
+			sector := make([]byte, sectorSize)
+			copy(sector, data[off:])
+			sectors = append(sectors, sector)
+			fat = append(fat, uint32(len(sectors)))
+		}
+		fat[len(fat)-1] = cfbEndOfChain
+		return start
+	}
+
+	var miniStream []byte
+	var miniFAT []uint32
+	starts := make([]uint32, len(streams))
+	for i, s := range streams {
+		if len(s.data) >= 4096 {
+			continue
+		}
+		starts[i] = uint32(len(miniStream) / cfbMiniSectorSize)
+		for off := 0; off < len(s.data); off += cfbMiniSectorSize {
+			chunk := make([]byte, cfbMiniSectorSize)
+			copy(chunk, s.data[off:])
+			miniStream = append(miniStream, chunk...)
+			miniFAT = append(miniFAT, uint32(len(miniStream)/cfbMiniSectorSize))
+		}
+		miniFAT[len(miniFAT)-1] = cfbEndOfChain
+	}
+	for i, s := range streams {
+		if len(s.data) >= 4096 {
+			starts[i] = allocate(s.data)
+		}
+	}
+	rootStart := allocate(miniStream)
+	var miniFATBytes []byte
+	for _, entry := range miniFAT {
+		miniFATBytes = le.AppendUint32(miniFATBytes, entry)
+	}
+	miniFATStart := allocate(miniFATBytes)
+
+	entry := func(name string, kind byte, start uint32, size int, child, right uint32) []byte {
+		raw := make([]byte, cfbDirEntrySize)
+		units := utf16.Encode([]rune(name))
+		for i, u := range units {
+			le.PutUint16(raw[2*i:], u)
+		}
+		le.PutUint16(raw[64:], uint16(2*len(units)+2))
+		raw[66], raw[67] = kind, 1
+		le.PutUint32(raw[68:], cfbFreeSector)
+		le.PutUint32(raw[72:], right)
+		le.PutUint32(raw[76:], child)
+		le.PutUint32(raw[116:], start)
+		le.PutUint32(raw[120:], uint32(size))
+		return raw
+	}
+	dir := entry("Root Entry", 5, rootStart, len(miniStream), 1, cfbFreeSector)
+	for i, s := range streams {
+		right := uint32(cfbFreeSector)
+		if i+1 < len(streams) {
+			right = uint32(i + 2)
+		}
+		dir = append(dir, entry(s.name, 2, starts[i], len(s.data), cfbFreeSector, right)...)
+	}
+	dirStart := allocate(dir)
+	if len(fat) > sectorSize/4 {
+		t.Fatalf("test compound file needs %d FAT entries", len(fat))
+	}
+
+	sectors[0] = make([]byte, sectorSize)
+	for i := range sectorSize / 4 {
+		value := uint32(cfbFreeSector)
+		if i < len(fat) {
+			value = fat[i]
+		}
+		le.PutUint32(sectors[0][4*i:], value)
+	}
+
+	header := make([]byte, cfbHeaderSize)
+	copy(header, cfbSignature)
+	le.PutUint16(header[0x18:], 0x3E)
+	le.PutUint16(header[0x1A:], 3)
+	le.PutUint16(header[0x1C:], 0xFFFE)
+	le.PutUint16(header[0x1E:], 9)
+	le.PutUint16(header[0x20:], 6)
+	le.PutUint32(header[0x2C:], 1)
+	le.PutUint32(header[0x30:], dirStart)
+	le.PutUint32(header[0x38:], 4096)
+	le.PutUint32(header[0x3C:], miniFATStart)
+	le.PutUint32(header[0x40:], uint32((len(miniFATBytes)+sectorSize-1)/sectorSize))
+	le.PutUint32(header[0x44:], cfbEndOfChain)
+	for i := range 109 {
+		le.PutUint32(header[0x4C+4*i:], cfbFreeSector)
+	}
+	le.PutUint32(header[0x4C:], 0)
+
+	out := header
+	for _, sector := range sectors {
+		out = append(out, sector...)
+	}
+	return out
+}
+
+func TestDecryptAgileOfficePackage(t *testing.T) {
+	pkg := testDocxPackage(t)
+	data := encryptTestOffice(t, pkg, "hunter2")
+
+	plain, mimeType, ok, err := openEncryptedOffice(data, "docx", NewExtractionConfig(WithDocumentPasswords("wrong", "hunter2")))
+	if err != nil || !ok {
+		t.Fatalf("openEncryptedOffice = %v, %v", ok, err)
+	}
+	if !bytes.Equal(plain, pkg) {
+		t.Fatal("decrypted package differs from the original")
+	}
+	if mimeType != mimeTypeDOCX {
+		t.Fatalf("mime type = %q, want %q", mimeType, mimeTypeDOCX)
+	}
+}
+
+func TestEncryptedOfficeErrors(t *testing.T) {
+	data := encryptTestOffice(t, testDocxPackage(t), "hunter2")
+
+	for _, tc := range []struct {
+		name     string
+		config   *ExtractionConfig
+		rejected bool
+	}{
+		{"no password", NewExtractionConfig(), false},
+		{"wrong password", NewExtractionConfig(WithDocumentPasswords("letmein")), true},
+	} {
+		t.Run(tc.name, func(t *testing.T) {
+			_, _, ok, err := openEncryptedOffice(data, "docx", tc.config)
+			var encErr *EncryptedDocumentError
+			if !ok || !errors.As(err, &encErr) {
+				t.Fatalf("expected EncryptedDocumentError, got %v (ok=%v)", err, ok)
+			}
+			if encErr.Format() != "docx" || encErr.PasswordRejected() != tc.rejected || encErr.Kind() != ErrorKindEncryptedDocument {
+				t.Fatalf("unexpected error details: format=%q rejected=%v kind=%q", encErr.Format(), encErr.PasswordRejected(), encErr.Kind())
+			}
+		})
+	}
+
+	// A damaged container is corruption, not encryption.
+	damaged := append([]byte(nil), data...)
+	for i := 600; i < len(damaged); i++ {
+		damaged[i] ^= 0xFF
+	}
+	_, _, ok, err := openEncryptedOffice(damaged, "docx", NewExtractionConfig(WithDocumentPasswords("hunter2")))
+	var encErr *EncryptedDocumentError
+	if ok && errors.As(err, &encErr) {
+		t.Fatalf("damaged container reported as encrypted: %v", err)
+	}
+}
+
+// TestAgileKeyParamsValidated verifies that out-of-range sizes and spin counts read from
+// the encryption info are rejected rather than sliced with or hashed for minutes.
+func TestAgileKeyParamsValidated(t *testing.T) {
+	block := base64.StdEncoding.EncodeToString(make([]byte, 16))
+	valid := agileKeyParams{
+		SpinCount: 1, SaltSize: 16, BlockSize: 16, KeyBits: 256, HashSize: 64, HashAlgorithm: "SHA512",
+		SaltValue: block, EncryptedVerifierHashInput: block, EncryptedVerifierHashValue: block, EncryptedKeyValue: block,
+	}
+	for _, tc := range []struct {
+		name   string
+		modify func(*agileKeyParams)
+	}{
+		{"negative salt size", func(p *agileKeyParams) { p.SaltSize = -1 }},
+		{"salt size beyond the salt", func(p *agileKeyParams) { p.SaltSize = 17 }},
+		{"hash size beyond the digest", func(p *agileKeyParams) { p.HashSize = 65 }},
+		{"zero hash size", func(p *agileKeyParams) { p.HashSize = 0 }},
+		{"huge spin count", func(p *agileKeyParams) { p.SpinCount = 1 << 30 }},
+	} {
+		t.Run(tc.name, func(t *testing.T) {
+			params := valid
+			tc.modify(&params)
+			info := &agileEncryptionInfo{password: params}
+			if _, err := info.key("pw"); !errors.Is(err, errOfficeCorrupt) {
+				t.Fatalf("expected errOfficeCorrupt, got %v", err)
+			}
+		})
+	}
+}
+
+// TestCFBSelfLinkedDIFAT verifies that a DIFAT sector linking to itself is rejected
+// instead of adding FAT sectors until memory runs out.
+func TestCFBSelfLinkedDIFAT(t *testing.T) {
+	data := buildTestCFB(t, []testCFBStream{{name: "WordDocument", data: []byte("text")}})
+	le := binary.LittleEndian
+	difat := uint32(len(data)/512 - 1)
+	sector := make([]byte, 512)
+	le.PutUint32(sector[508:], difat)
+	data = append(data, sector...)
+	le.PutUint32(data[0x44:], difat)
+	le.PutUint32(data[0x48:], 1)
+
+	if _, err := parseCFB(data); !errors.Is(err, errInvalidCFB) {
+		t.Fatalf("expected errInvalidCFB, got %v", err)
+	}
+}
+
+func TestEncryptedOfficeFileDetection(t *testing.T) {
+	pkg := testDocxPackage(t)
+	path := filepath.Join(t.TempDir(), "protected.docx")
+	if err := os.WriteFile(path, encryptTestOffice(t, pkg, "pw"), 0o600); err != nil {
+		t.Fatal(err)
+	}
+
+	_, _, ok, err := openEncryptedOfficeFile(path, nil)
+	var encErr *EncryptedDocumentError
+	if !ok || !errors.As(err, &encErr) || encErr.Format() != "docx" {
+		t.Fatalf("expected docx EncryptedDocumentError, got %v", err)
+	}
+
+	plain, _, ok, err := openEncryptedOfficeFile(path, NewExtractionConfig(WithDocumentPasswords("pw")))
+	if err != nil || !ok || !bytes.Equal(plain, pkg) {
+		t.Fatalf("openEncryptedOfficeFile failed: ok=%v err=%v", ok, err)
+	}
+
+	legacy := getTestFilePath("legacy_office/unit_test_lists.doc")
+	if _, _, ok, err := openEncryptedOfficeFile(legacy, nil); ok || err != nil {
+		t.Fatalf("legacy document treated as encrypted: ok=%v err=%v", ok, err)
+	}
+}
+
+func TestClassifyNativePasswordErrors(t *testing.T) {
+	err := classifyNativeError("Parsing error: PDF is password-protected", ErrorCodeParsing, nil)
+	var encErr *EncryptedDocumentError
+	if !errors.As(err, &encErr) || encErr.Format() != "pdf" || encErr.PasswordRejected() {
+		t.Fatalf("unexpected classification: %#v", err)
+	}
+	err = classifyNativeError("Parsing error: Invalid password provided", ErrorCodeParsing, nil)
+	if !errors.As(err, &encErr) || !encErr.PasswordRejected() {
+		t.Fatalf("unexpected classification: %#v", err)
+	}
+}
+
+func TestDocumentPasswordsForwardedToPDF(t *testing.T) {
+	config := NewExtractionConfig(
+		WithPdfOptions(WithPdfPasswords([]string{"first"})),
+		WithDocumentPasswords("second"),
+	)
+	data, err := encodeConfig(config)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !strings.Contains(string(data), `"passwords":["first","second"]`) {
+		t.Fatalf("passwords not forwarded: %s", data)
+	}
+	if len(config.PdfOptions.Passwords) != 1 {
+		t.Fatal("encodeConfig must not modify the config")
+	}
+}
@@ -0,0 +1,41 @@
+package kreuzberg
+
+// OffsetEntry traces Content[Start:End] to its origin in the source document. BBox is
+// [left, top, right, bottom] in points from the top-left corner of the unrotated page, or
+// nil when the position on the page is unknown.
+type OffsetEntry struct {
+	Start int         `json:"start"`
+	End   int         `json:"end"`
+	Page  int         `json:"page"`
+	BBox  *[4]float64 `json:"bbox,omitempty"`
+}
+
+// applyOffsetMap fills result.OffsetMap when ExtractionConfig.OffsetMap is enabled. Entries
+// are per text span with a BBox when positions are known, as for LocateText, and otherwise
+// per page from the page boundaries.
+func applyOffsetMap(result *ExtractionResult, config *ExtractionConfig) {
+	if config.OffsetMap == nil || !*config.OffsetMap {
+		return
+	}
+	if len(result.textPositions) > 0 && result.textPositionsContent == result.Content {
+		entries := make([]OffsetEntry, len(result.textPositions))
+		for i, pos := range result.textPositions {
+			box := pos.box
+			entries[i] = OffsetEntry{Start: pos.start, End: pos.end, Page: pos.page, BBox: &box}
+		}
+		result.OffsetMap = entries
+		return
+	}
+	if result.Metadata.PageStructure == nil {
+		return
+	}
+	var entries []OffsetEntry
+	for _, boundary := range result.Metadata.PageStructure.Boundaries {
+		start := int(min(boundary.ByteStart, uint64(len(result.Content))))
+		end := int(min(boundary.ByteEnd, uint64(len(result.Content))))
+		if start < end {
+			entries = append(entries, OffsetEntry{Start: start, End: end, Page: int(boundary.PageNumber)})
+		}
+	}
+	result.OffsetMap = entries
+}
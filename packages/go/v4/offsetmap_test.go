@@ -0,0 +1,56 @@
+package kreuzberg
+
+import (
+	"strings"
+	"testing"
+)
+
+func TestApplyOffsetMapFromPositions(t *testing.T) {
+	result := &ExtractionResult{Content: "Hello\n\nWorld"}
+	result.textPositions = []textPosition{
+		{start: 0, end: 5, page: 1, box: [4]float64{10, 20, 40, 30}},
+		{start: 7, end: 12, page: 2, box: [4]float64{10, 20, 45, 30}},
+	}
+	result.textPositionsContent = result.Content
+
+	applyOffsetMap(result, &ExtractionConfig{})
+	if result.OffsetMap != nil {
+		t.Fatalf("expected no offset map by default, got %+v", result.OffsetMap)
+	}
+
+	applyOffsetMap(result, NewExtractionConfig(WithOffsetMap(true)))
+	if len(result.OffsetMap) != 2 {
+		t.Fatalf("expected two entries, got %+v", result.OffsetMap)
+	}
+	entry := result.OffsetMap[1]
+	if entry.Start != 7 || entry.End != 12 || entry.Page != 2 || entry.BBox == nil || entry.BBox[2] != 45 {
+		t.Fatalf("unexpected entry %+v", entry)
+	}
+	if got := result.Content[entry.Start:entry.End]; got != "World" {
+		t.Fatalf("entry covers %q, want World", got)
+	}
+}
+
+func TestApplyOffsetMapFromPages(t *testing.T) {
+	result := &ExtractionResult{
+		Content: "first\n\nsecond",
+		Metadata: Metadata{PageStructure: &PageStructure{Boundaries: []PageBoundary{
+			{ByteStart: 0, ByteEnd: 5, PageNumber: 1},
+			{ByteStart: 7, ByteEnd: 13, PageNumber: 2},
+		}}},
+		SampledPages: []int{4, 9},
+	}
+	config := NewExtractionConfig(WithOffsetMap(true))
+	applyOffsetMap(result, config)
+	applySampledPageNumbers(result)
+	if len(result.OffsetMap) != 2 || result.OffsetMap[1].Page != 9 || result.OffsetMap[1].BBox != nil {
+		t.Fatalf("unexpected offset map %+v", result.OffsetMap)
+	}
+
+	config.ContentFilter = strings.ToUpper
+	applyOffsetMap(result, config)
+	applyFilters(result, config)
+	if result.OffsetMap != nil {
+		t.Fatalf("expected the offset map to be dropped after the content changed, got %+v", result.OffsetMap)
+	}
+}
@@ -0,0 +1,50 @@
+package kreuzberg
+
+import (
+	"archive/zip"
+	"bytes"
+	"encoding/xml"
+	"io"
+)
+
+// MIME types of the Office Open XML formats inspected by the Go-side stages.
+const (
+	mimeTypeDOCX = "application/vnd.openxmlformats-officedocument.wordprocessingml.document"
+	mimeTypeXLSX = "application/vnd.openxmlformats-officedocument.spreadsheetml.sheet"
+	mimeTypePPTX = "application/vnd.openxmlformats-officedocument.presentationml.presentation"
+)
+
+// readZipEntry returns the contents of the named entry of an OOXML package.
+// The boolean is false when the package has no such entry.
+func readZipEntry(data []byte, name string) ([]byte, bool, error) {
+	reader, err := zip.NewReader(bytes.NewReader(data), int64(len(data)))
+	if err != nil {
+		return nil, false, err
+	}
+	for _, file := range reader.File {
+		if file.Name != name {
+			continue
+		}
+		rc, err := file.Open()
+		if err != nil {
+			return nil, false, err
+		}
+		defer rc.Close()
+		content, err := io.ReadAll(rc)
+		if err != nil {
+			return nil, false, err
+		}
+		return content, true, nil
+	}
+	return nil, false, nil
+}
+
+// xmlAttr returns the value of the attribute with the given local name, ignoring namespaces.
+func xmlAttr(el xml.StartElement, local string) string {
+	for _, attr := range el.Attr {
+		if attr.Name.Local == local {
+			return attr.Value
+		}
+	}
+	return ""
+}
@@ -0,0 +1,215 @@
+package kreuzberg
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"io"
+	"os"
+)
+
+// PageResult is a page of a document, passed to the callback of ExtractFilePaged.
+type PageResult struct {
+	// PageNumber is the 1-based number of the page in the document.
+	PageNumber int `json:"page_number"`
+	// PageCount is the number of pages the callback is called for, which is less than the
+	// pages of the document when PageConfig.SamplePages applies.
+	PageCount int              `json:"page_count"`
+	Content   string           `json:"content"`
+	Tables    []Table          `json:"tables,omitempty"`
+	Images    []ExtractedImage `json:"images,omitempty"`
+	// Warnings are the warnings about the page; for documents extracted as a whole they are
+	// only set on the first page.
+	Warnings []ExtractionWarning `json:"warnings,omitempty"`
+}
+
+// ExtractFilePaged extracts the file at path and calls fn for each page, in order, as soon
+// as that page is done, so that a viewer can show the first pages of a large document
+// before the rest is extracted. An error returned by fn stops the extraction and is
+// returned as is.
+//
+// PDFs and multi-page TIFFs are extracted one page at a time, each page getting the full
+// post-processing of config; results that span pages, such as chunks and the document
+// metadata, are not available. PageConfig.SamplePages and MaxPages apply as in
+// ExtractFileSync. Other formats, and encrypted PDFs, are extracted as a whole and their
+// pages passed to fn afterwards; a document without pages is passed as a single page.
+func ExtractFilePaged(path string, config *ExtractionConfig, fn func(page PageResult) error) error {
+	return extractFilePaged(context.Background(), path, config, fn)
+}
+
+func extractFilePaged(ctx context.Context, path string, config *ExtractionConfig, fn func(page PageResult) error) error {
+	config = configOrDefault(config)
+	if fn == nil {
+		return newValidationErrorWithContext("page callback is required", nil, ErrorCodeValidation, nil)
+	}
+	if err := validateConfig(config); err != nil {
+		return err
+	}
+	if err := checkInputSizeFile(path, config); err != nil {
+		return err
+	}
+	data, mimeType, ok := readPagedDocument(path)
+	if !ok {
+		return extractFileWholePages(ctx, path, config, fn)
+	}
+
+	var count int
+	var split func(page int) ([]byte, bool)
+	if mimeType == "application/pdf" {
+		doc, err := parsePDFDocument(data)
+		if err != nil {
+			return extractFileWholePages(ctx, path, config, fn)
+		}
+		pages := doc.pages()
+		if doc.encrypted || len(pages) == 0 {
+			return extractFileWholePages(ctx, path, config, fn)
+		}
+		for _, page := range pages {
+			if page.objectNumber == 0 {
+				return extractFileWholePages(ctx, path, config, fn)
+			}
+		}
+		count = len(pages)
+		split = func(page int) ([]byte, bool) { return pdfPageDocument(data, doc, pages[page-1]) }
+	} else {
+		order, offsets := tiffIFDOffsets(data)
+		if len(offsets) <= 1 {
+			return extractFileWholePages(ctx, path, config, fn)
+		}
+		count = len(offsets)
+		split = func(page int) ([]byte, bool) { return tiffFrame(data, order, offsets[page-1]) }
+	}
+
+	numbers := samplePageNumbers(count, count, SampleFirst, nil)
+	if n, strategy, ok := samplePages(config); ok && count > n {
+		numbers = samplePageNumbers(count, n, strategy, config.Pages.SampleSeed)
+	} else if err := checkMaxPages(data, config); err != nil {
+		return err
+	}
+
+	pageConfig := cloneConfig(config)
+	pageConfig.ParallelPages = nil
+	if pageConfig.Pages != nil {
+		pageConfig.Pages.SamplePages = nil
+		pageConfig.Pages.MaxPages = nil
+	}
+	page := func(i int) ([]byte, error) {
+		data, ok := split(numbers[i])
+		if !ok {
+			return nil, errPageNotSplit
+		}
+		return data, nil
+	}
+	single := func(ctx context.Context, page []byte) (*ExtractionResult, error) {
+		return extractBytes(ctx, page, mimeType, pageConfig)
+	}
+	var batch pageBatchExtractor
+	// A batch cannot give up on one of its pages, so pages with a time budget are extracted
+	// one at a time.
+	if n := parallelPages(config); n > 1 && ocrPageTimeout(config) <= 0 {
+		batchConfig := cloneConfig(pageConfig)
+		batchConfig.MaxConcurrentExtractions = &n
+		batch = func(ctx context.Context, pages [][]byte) ([]*ExtractionResult, error) {
+			items := make([]BytesWithMime, len(pages))
+			for i, page := range pages {
+				items[i] = BytesWithMime{Data: page, MimeType: mimeType}
+			}
+			return batchExtractBytes(ctx, items, batchConfig)
+		}
+	}
+	err := extractPagesInOrder(ctx, len(numbers), parallelPages(config), page, single, batch, func(i int, result *ExtractionResult, err error) error {
+		if err != nil {
+			return err
+		}
+		number := numbers[i]
+		result.SampledPages = []int{number}
+		applySampledPageNumbers(result)
+		return fn(PageResult{
+			PageNumber: number,
+			PageCount:  len(numbers),
+			Content:    result.Content,
+			Tables:     result.Tables,
+			Images:     result.Images,
+			Warnings:   result.Warnings,
+		})
+	})
+	if errors.Is(err, errPageNotSplit) {
+		// Only a PDF whose page tree cannot be rewritten at all fails here, which shows on
+		// its first page since every page was checked to be an object of its own.
+		return extractFileWholePages(ctx, path, config, fn)
+	}
+	return err
+}
+
+// errPageNotSplit reports a page extractFilePaged cannot cut out of its document.
+var errPageNotSplit = errors.New("page cannot be split from document")
+
+// extractFileWholePages extracts the file at path as a whole and calls fn for each of its
+// pages.
+func extractFileWholePages(ctx context.Context, path string, config *ExtractionConfig, fn func(page PageResult) error) error {
+	pagesConfig := cloneConfig(config)
+	if pagesConfig.Pages == nil {
+		pagesConfig.Pages = &PageConfig{}
+	}
+	pagesConfig.Pages.ExtractPages = BoolPtr(true)
+	result, err := extractFile(ctx, path, pagesConfig)
+	if err != nil {
+		return err
+	}
+
+	if len(result.Pages) == 0 {
+		return fn(PageResult{
+			PageNumber: 1,
+			PageCount:  1,
+			Content:    result.Content,
+			Tables:     result.Tables,
+			Images:     result.Images,
+			Warnings:   result.Warnings,
+		})
+	}
+	for i, page := range result.Pages {
+		if err := ctx.Err(); err != nil {
+			return err
+		}
+		var warnings []ExtractionWarning
+		if i == 0 {
+			warnings = result.Warnings
+		}
+		if err := fn(PageResult{
+			PageNumber: int(page.PageNumber),
+			PageCount:  len(result.Pages),
+			Content:    page.Content,
+			Tables:     page.Tables,
+			Images:     page.Images,
+			Warnings:   warnings,
+		}); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// readPagedDocument reads the file at path when it is a PDF or a TIFF, returning its MIME
+// type.
+func readPagedDocument(path string) ([]byte, string, bool) {
+	file, err := os.Open(path)
+	if err != nil {
+		return nil, "", false
+	}
+	defer file.Close()
+	header := make([]byte, 8)
+	if n, _ := io.ReadFull(file, header); !isPagedDocumentHeader(header[:n]) {
+		return nil, "", false
+	}
+	if _, err := file.Seek(0, io.SeekStart); err != nil {
+		return nil, "", false
+	}
+	data, err := io.ReadAll(file)
+	if err != nil {
+		return nil, "", false
+	}
+	if bytes.HasPrefix(data, []byte("%PDF-")) {
+		return data, "application/pdf", true
+	}
+	return data, "image/tiff", true
+}
@@ -0,0 +1,75 @@
+package kreuzberg
+
+import (
+	"errors"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+func TestExtractFilePaged(t *testing.T) {
+	if _, err := ExtractBytesSync([]byte("probe"), "text/plain", nil); err != nil {
+		t.Skipf("native library unavailable: %v", err)
+	}
+	path := filepath.Join(t.TempDir(), "pages.pdf")
+	if err := os.WriteFile(path, testSamplePDF(4), 0o600); err != nil {
+		t.Fatal(err)
+	}
+
+	var pages []PageResult
+	err := ExtractFilePaged(path, NewExtractionConfig(WithRawText(true)), func(page PageResult) error {
+		pages = append(pages, page)
+		return nil
+	})
+	if err != nil {
+		t.Fatalf("ExtractFilePaged failed: %v", err)
+	}
+	if len(pages) != 4 {
+		t.Fatalf("got %d pages, want 4", len(pages))
+	}
+	for i, page := range pages {
+		if page.PageNumber != i+1 || page.PageCount != 4 || strings.TrimSpace(page.Content) != "Page "+string(rune('1'+i)) {
+			t.Fatalf("unexpected page %d: %+v", i, page)
+		}
+	}
+
+	sampled := NewExtractionConfig(WithRawText(true), WithPages(WithSamplePages(2, SampleUniform)))
+	pages = nil
+	if err := ExtractFilePaged(path, sampled, func(page PageResult) error {
+		pages = append(pages, page)
+		return nil
+	}); err != nil {
+		t.Fatalf("sampled ExtractFilePaged failed: %v", err)
+	}
+	if len(pages) != 2 || pages[0].PageNumber != 1 || pages[1].PageNumber != 4 || strings.TrimSpace(pages[1].Content) != "Page 4" {
+		t.Fatalf("unexpected sampled pages %+v", pages)
+	}
+}
+
+func TestExtractFilePagedStopsEarly(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "pages.pdf")
+	if err := os.WriteFile(path, testSamplePDF(5), 0o600); err != nil {
+		t.Fatal(err)
+	}
+	var valErr *ValidationError
+	if err := ExtractFilePaged(path, nil, nil); !errors.As(err, &valErr) {
+		t.Fatalf("expected ValidationError for a nil callback, got %v", err)
+	}
+	if _, err := ExtractBytesSync([]byte("probe"), "text/plain", nil); err != nil {
+		t.Skipf("native library unavailable: %v", err)
+	}
+
+	stop := errors.New("stop")
+	calls := 0
+	err := ExtractFilePaged(path, NewExtractionConfig(WithRawText(true)), func(page PageResult) error {
+		calls++
+		if page.PageNumber == 2 {
+			return stop
+		}
+		return nil
+	})
+	if !errors.Is(err, stop) || calls != 2 {
+		t.Fatalf("expected the callback error after 2 pages, got %v after %d", err, calls)
+	}
+}
@@ -0,0 +1,76 @@
+package kreuzberg
+
+import (
+	"image"
+	"math"
+	"math/bits"
+	"slices"
+)
+
+// pageHashSize is the side of the grayscale image a perceptual hash is computed from, and
+// pageHashBits the side of the block of its lowest frequencies that make up the hash.
+const (
+	pageHashSize = 32
+	pageHashBits = 8
+)
+
+// PageHash is the perceptual hash of a page image produced by WithPageThumbprints.
+type PageHash struct {
+	PageNumber int `json:"page_number"`
+	// PHash is a 64-bit DCT perceptual hash: each bit tells whether one of the lowest
+	// spatial frequencies of the page is above their median.
+	PHash uint64 `json:"phash"`
+}
+
+// Distance returns the number of bits in which the hashes of h and other differ, from 0
+// for pages that look the same to 64. Scans of the same page usually differ by less than
+// 10 bits.
+func (h PageHash) Distance(other PageHash) int {
+	return bits.OnesCount64(h.PHash ^ other.PHash)
+}
+
+// perceptualHash returns the DCT perceptual hash of img turned clockwise by rotate degrees.
+// The image is reduced to 32x32 gray pixels and transformed with a two-dimensional DCT;
+// the hash has one bit per coefficient of the lowest 8x8 frequencies, set when the
+// coefficient exceeds their median, the constant term excluded from the median.
+func perceptualHash(img image.Image, rotate int) uint64 {
+	small := scaleImage(img, pageHashSize, pageHashSize)
+	if rotate != 0 {
+		small = rotateImage(small, rotate)
+	}
+	var gray [pageHashSize][pageHashSize]float64
+	for y := range pageHashSize {
+		for x := range pageHashSize {
+			c := small.RGBAAt(x, y)
+			gray[y][x] = 0.299*float64(c.R) + 0.587*float64(c.G) + 0.114*float64(c.B)
+		}
+	}
+
+	var coefficients [pageHashBits * pageHashBits]float64
+	for v := range pageHashBits {
+		for u := range pageHashBits {
+			sum := 0.0
+			for y := range pageHashSize {
+				cy := math.Cos(float64(2*y+1) * float64(v) * math.Pi / (2 * pageHashSize))
+				for x := range pageHashSize {
+					sum += gray[y][x] * cy * math.Cos(float64(2*x+1)*float64(u)*math.Pi/(2*pageHashSize))
+				}
+			}
+			coefficients[v*pageHashBits+u] = sum
+		}
+	}
+	sorted := slices.Clone(coefficients[1:])
+	slices.Sort(sorted)
+	median := (sorted[len(sorted)/2-1] + sorted[len(sorted)/2]) / 2
+	if len(sorted)%2 == 1 {
+		median = sorted[len(sorted)/2]
+	}
+
+	var hash uint64
+	for i, c := range coefficients {
+		if c > median {
+			hash |= 1 << (63 - i)
+		}
+	}
+	return hash
+}
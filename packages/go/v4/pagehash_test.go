@@ -0,0 +1,76 @@
+package kreuzberg
+
+import (
+	"bytes"
+	"context"
+	"image"
+	"image/color"
+	"image/png"
+	"testing"
+)
+
+// testPageImage returns a width by height page with a few dark text-like bars, shifted by
+// noise gray levels on alternate pixels.
+func testPageImage(width, height int, noise uint8) *image.Gray {
+	img := image.NewGray(image.Rect(0, 0, width, height))
+	for y := range height {
+		for x := range width {
+			level := uint8(0xf0)
+			if row := y * 10 / height; row%2 == 1 && x > width/10 && x < width*(5+row)/10 {
+				level = 0x20
+			}
+			if (x+y)%2 == 0 {
+				level -= noise
+			}
+			img.SetGray(x, y, color.Gray{Y: level})
+		}
+	}
+	return img
+}
+
+func TestPerceptualHash(t *testing.T) {
+	page := PageHash{PHash: perceptualHash(testPageImage(200, 280, 0), 0)}
+	noisy := PageHash{PHash: perceptualHash(testPageImage(200, 280, 12), 0)}
+	rescaled := PageHash{PHash: perceptualHash(testPageImage(150, 210, 0), 0)}
+	other := PageHash{PHash: perceptualHash(testRenderImage(200, 280), 0)}
+
+	if d := page.Distance(noisy); d > 6 {
+		t.Fatalf("expected a noisy copy to be close, distance %d", d)
+	}
+	if d := page.Distance(rescaled); d > 6 {
+		t.Fatalf("expected a rescaled copy to be close, distance %d", d)
+	}
+	if d := page.Distance(other); d < 16 {
+		t.Fatalf("expected a different page to be far, distance %d", d)
+	}
+	if d := page.Distance(page); d != 0 {
+		t.Fatalf("expected distance 0 to itself, got %d", d)
+	}
+}
+
+func TestApplyPageThumbprints(t *testing.T) {
+	var data bytes.Buffer
+	if err := png.Encode(&data, testPageImage(100, 140, 0)); err != nil {
+		t.Fatal(err)
+	}
+	src := &documentSource{data: data.Bytes()}
+
+	result := &ExtractionResult{MimeType: "image/png"}
+	if err := applyRenderPages(context.Background(), result, NewExtractionConfig(), src); err != nil {
+		t.Fatalf("applyRenderPages failed: %v", err)
+	}
+	if len(result.PageHashes) != 0 {
+		t.Fatalf("expected no page hashes when disabled, got %+v", result.PageHashes)
+	}
+
+	result = &ExtractionResult{MimeType: "image/png"}
+	if err := applyRenderPages(context.Background(), result, NewExtractionConfig(WithPageThumbprints(true)), src); err != nil {
+		t.Fatalf("applyRenderPages failed: %v", err)
+	}
+	if len(result.PageHashes) != 1 || result.PageHashes[0].PageNumber != 1 || result.PageHashes[0].PHash == 0 {
+		t.Fatalf("unexpected page hashes: %+v", result.PageHashes)
+	}
+	if len(result.PageImages) != 0 {
+		t.Fatalf("expected no page images without WithRenderPages, got %d", len(result.PageImages))
+	}
+}
@@ -0,0 +1,155 @@
+package kreuzberg
+
+import (
+	"bytes"
+	"fmt"
+	"io"
+	"os"
+)
+
+// PageLimitError is the cause of the ValidationError returned for a document with more
+// pages than PageConfig.MaxPages allows.
+type PageLimitError struct {
+	Pages    int
+	MaxPages int
+}
+
+func (e *PageLimitError) Error() string {
+	return fmt.Sprintf("document has %d pages, more than the allowed %d", e.Pages, e.MaxPages)
+}
+
+func maxPages(config *ExtractionConfig) (int, bool) {
+	if config == nil || config.Pages == nil || config.Pages.MaxPages == nil {
+		return 0, false
+	}
+	return *config.Pages.MaxPages, true
+}
+
+// checkMaxPages rejects data whose page count exceeds PageConfig.MaxPages. Page counts are
+// read from PDF page trees and TIFF frames; other documents pass unchecked.
+func checkMaxPages(data []byte, config *ExtractionConfig) error {
+	limit, ok := maxPages(config)
+	if !ok || limit < 1 {
+		// validateConfig reports limits below one.
+		return nil
+	}
+	pages, ok := documentPageCount(data)
+	if !ok || pages <= limit {
+		return nil
+	}
+	cause := &PageLimitError{Pages: pages, MaxPages: limit}
+	return newValidationErrorWithContext(cause.Error(), cause, ErrorCodeValidation, nil)
+}
+
+// checkMaxPagesFile applies checkMaxPages to the file at path, reading it only when it is a
+// PDF or TIFF no larger than ExtractionConfig.MaxInputBytes.
+func checkMaxPagesFile(path string, config *ExtractionConfig) error {
+	if _, ok := maxPages(config); !ok || path == "" {
+		return nil
+	}
+	// #nosec G304 -- path is the document the caller asked us to extract
+	file, err := os.Open(path)
+	if err != nil {
+		// Leave reporting unreadable files to the extraction itself.
+		return nil
+	}
+	defer file.Close()
+	if info, err := file.Stat(); err == nil {
+		if err := checkInputSizeOf(info.Size(), config); err != nil {
+			return err
+		}
+	}
+	header := make([]byte, 8)
+	if n, _ := io.ReadFull(file, header); !isPagedDocumentHeader(header[:n]) {
+		return nil
+	}
+	if _, err := file.Seek(0, io.SeekStart); err != nil {
+		return nil
+	}
+	data, err := io.ReadAll(file)
+	if err != nil {
+		return nil
+	}
+	return checkMaxPages(data, config)
+}
+
+func isPagedDocumentHeader(header []byte) bool {
+	return bytes.HasPrefix(header, []byte("%PDF-")) ||
+		bytes.HasPrefix(header, []byte("II*\x00")) || bytes.HasPrefix(header, []byte("MM\x00*"))
+}
+
+// documentPageCount returns the number of pages of a PDF or TIFF. For PDFs this is the
+// count declared by the page tree, which is what a renderer would allocate for, or the
+// number of pages found when the tree declares none. PDFs the bounded reader in
+// pdfobjects.go refuses are left for the native library to reject.
+func documentPageCount(data []byte) (int, bool) {
+	switch {
+	case bytes.HasPrefix(data, []byte("%PDF-")):
+		doc, err := parsePDFDocument(data)
+		if err != nil {
+			return 0, false
+		}
+		return pdfPageCount(doc)
+	case isPagedDocumentHeader(data):
+		if _, offsets := tiffIFDOffsets(data); len(offsets) > 0 {
+			return len(offsets), true
+		}
+	}
+	return 0, false
+}
+
+// pdfPageCount is documentPageCount for a parsed PDF.
+func pdfPageCount(doc *pdfDocument) (int, bool) {
+	catalog := doc.catalog()
+	if catalog == nil {
+		return 0, false
+	}
+	if count, ok := doc.resolve(doc.dict(catalog["Pages"])["Count"]).(float64); ok && count > 0 {
+		return int(count), true
+	}
+	if pages := doc.pages(); len(pages) > 0 {
+		return len(pages), true
+	}
+	return 0, false
+}
+
+// InputSizeError is the cause of the ValidationError returned for bytes larger than
+// ExtractionConfig.MaxInputBytes allows.
+type InputSizeError struct {
+	Size          int
+	MaxInputBytes int
+}
+
+func (e *InputSizeError) Error() string {
+	return fmt.Sprintf("input is %d bytes, more than the allowed %d", e.Size, e.MaxInputBytes)
+}
+
+// checkInputSize rejects data larger than ExtractionConfig.MaxInputBytes.
+func checkInputSize(data []byte, config *ExtractionConfig) error {
+	return checkInputSizeOf(int64(len(data)), config)
+}
+
+// checkInputSizeFile rejects a file larger than ExtractionConfig.MaxInputBytes before it is
+// read. Files that cannot be stat'ed are left for the extraction to report.
+func checkInputSizeFile(path string, config *ExtractionConfig) error {
+	if config == nil || config.MaxInputBytes == nil || path == "" {
+		return nil
+	}
+	info, err := os.Stat(path)
+	if err != nil {
+		return nil
+	}
+	return checkInputSizeOf(info.Size(), config)
+}
+
+func checkInputSizeOf(size int64, config *ExtractionConfig) error {
+	if config == nil || config.MaxInputBytes == nil || *config.MaxInputBytes < 1 {
+		// validateConfig reports limits below one.
+		return nil
+	}
+	if size <= int64(*config.MaxInputBytes) {
+		return nil
+	}
+	cause := &InputSizeError{Size: int(size), MaxInputBytes: *config.MaxInputBytes}
+	return newValidationErrorWithContext(cause.Error(), cause, ErrorCodeValidation, nil)
+}
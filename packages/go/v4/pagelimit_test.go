@@ -0,0 +1,113 @@
+package kreuzberg
+
+import (
+	"errors"
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+// testClaimedPagesPDF is a PDF whose page tree declares a billion pages but holds one.
+var testClaimedPagesPDF = buildTestPDF(
+	`<< /Type /Catalog /Pages 2 0 R >>`,
+	`<< /Type /Pages /Kids [3 0 R] /Count 1000000000 >>`,
+	`<< /Type /Page /Parent 2 0 R /MediaBox [0 0 612 792] >>`,
+)
+
+func TestMaxPages(t *testing.T) {
+	config := NewExtractionConfig(WithPages(WithMaxPages(10)))
+
+	_, err := ExtractBytesSync(testClaimedPagesPDF, "application/pdf", config)
+	var validation *ValidationError
+	var limit *PageLimitError
+	if !errors.As(err, &validation) || !errors.As(err, &limit) {
+		t.Fatalf("expected a ValidationError caused by PageLimitError, got %v", err)
+	}
+	if limit.Pages != 1000000000 || limit.MaxPages != 10 {
+		t.Fatalf("unexpected limit error %+v", limit)
+	}
+
+	path := filepath.Join(t.TempDir(), "claimed.pdf")
+	if err := os.WriteFile(path, testClaimedPagesPDF, 0o600); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := ExtractFileSync(path, config); !errors.As(err, &limit) {
+		t.Fatalf("expected ExtractFileSync to enforce the limit, got %v", err)
+	}
+	if _, err := BatchExtractFilesSync([]string{path}, config); !errors.As(err, &limit) {
+		t.Fatalf("expected BatchExtractFilesSync to enforce the limit, got %v", err)
+	}
+	if _, err := BatchExtractBytesSync([]BytesWithMime{{Data: testClaimedPagesPDF, MimeType: "application/pdf"}}, config); !errors.As(err, &limit) {
+		t.Fatalf("expected BatchExtractBytesSync to enforce the limit, got %v", err)
+	}
+
+	tiff := encodeTIFF(renderWord("A"), renderWord("B"), renderWord("H"))
+	if err := checkMaxPages(tiff, NewExtractionConfig(WithPages(WithMaxPages(2)))); !errors.As(err, &limit) || limit.Pages != 3 {
+		t.Fatalf("expected the TIFF frame count to be checked, got %v", err)
+	}
+	for _, config := range []*ExtractionConfig{
+		NewExtractionConfig(WithPages(WithMaxPages(3))),
+		NewExtractionConfig(WithPages(WithExtractPages(true))),
+		nil,
+	} {
+		if err := checkMaxPages(tiff, config); err != nil {
+			t.Fatalf("unexpected error within the limit: %v", err)
+		}
+	}
+	if err := checkMaxPages([]byte("plain text"), config); err != nil {
+		t.Fatalf("documents without pages should pass, got %v", err)
+	}
+
+	if err := validateConfig(NewExtractionConfig(WithPages(WithMaxPages(0)))); err == nil {
+		t.Fatal("expected a max pages below one to be rejected")
+	}
+}
+
+func TestMaxInputBytes(t *testing.T) {
+	config := NewExtractionConfig(WithMaxInputBytes(64))
+	data := make([]byte, 100)
+
+	_, err := ExtractBytesSync(data, "text/plain", config)
+	var validation *ValidationError
+	var limit *InputSizeError
+	if !errors.As(err, &validation) || !errors.As(err, &limit) {
+		t.Fatalf("expected a ValidationError caused by InputSizeError, got %v", err)
+	}
+	if limit.Size != 100 || limit.MaxInputBytes != 64 {
+		t.Fatalf("unexpected limit error %+v", limit)
+	}
+	items := []BytesWithMime{{Data: data[:10], MimeType: "text/plain"}, {Data: data, MimeType: "text/plain"}}
+	if _, err := BatchExtractBytesSync(items, config); !errors.As(err, &limit) {
+		t.Fatalf("expected BatchExtractBytesSync to enforce the limit, got %v", err)
+	}
+
+	path := filepath.Join(t.TempDir(), "large.txt")
+	if err := os.WriteFile(path, data, 0o600); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := ExtractFileSync(path, config); !errors.As(err, &limit) || limit.Size != 100 {
+		t.Fatalf("expected ExtractFileSync to enforce the limit, got %v", err)
+	}
+	if _, err := BatchExtractFilesSync([]string{path}, config); !errors.As(err, &limit) {
+		t.Fatalf("expected BatchExtractFilesSync to enforce the limit, got %v", err)
+	}
+	// The size is checked before the page count, so an oversized PDF is never parsed.
+	pdfPath := filepath.Join(t.TempDir(), "claimed.pdf")
+	if err := os.WriteFile(pdfPath, testClaimedPagesPDF, 0o600); err != nil {
+		t.Fatal(err)
+	}
+	pdfConfig := NewExtractionConfig(WithMaxInputBytes(64), WithPages(WithMaxPages(10)))
+	if err := checkMaxPagesFile(pdfPath, pdfConfig); !errors.As(err, &limit) {
+		t.Fatalf("expected checkMaxPagesFile to check the size first, got %v", err)
+	}
+
+	if err := checkInputSize(data[:64], config); err != nil {
+		t.Fatalf("unexpected error within the limit: %v", err)
+	}
+	if err := checkInputSize(data, nil); err != nil {
+		t.Fatalf("unexpected error without a limit: %v", err)
+	}
+	if err := validateConfig(NewExtractionConfig(WithMaxInputBytes(0))); err == nil {
+		t.Fatal("expected a max input bytes below one to be rejected")
+	}
+}
@@ -0,0 +1,64 @@
+package kreuzberg
+
+func pageGeometryEnabled(config *ExtractionConfig) bool {
+	return config != nil && config.Pages != nil && config.Pages.PageGeometry != nil && *config.Pages.PageGeometry
+}
+
+// applyPageSizes records the size of every page of a PDF in
+// Metadata.PageStructure.PageSizes. By default these are the dimensions reported by the
+// native library. With WithPageGeometry they are read from the page tree instead, along
+// with each page's rotation: the visible area is the CropBox when present, otherwise the
+// MediaBox. Other formats are left untouched.
+func applyPageSizes(result *ExtractionResult, config *ExtractionConfig, src *documentSource) error {
+	if !isPDFMimeType(result.MimeType) {
+		return nil
+	}
+
+	var sizes []PageSize
+	if pageGeometryEnabled(config) {
+		doc, err := src.pdfDocument()
+		if err != nil {
+			return err
+		}
+		for i, page := range doc.pages() {
+			box := page.mediaBox
+			if page.cropBox != nil {
+				box = *page.cropBox
+			}
+			sizes = append(sizes, PageSize{
+				PageNumber: i + 1,
+				Width:      box[2] - box[0],
+				Height:     box[3] - box[1],
+				Rotation:   page.rotate,
+			})
+		}
+	}
+
+	structure := result.Metadata.PageStructure
+	if len(sizes) == 0 {
+		if structure == nil {
+			return nil
+		}
+		for _, info := range structure.Pages {
+			if info.Dimensions != nil {
+				sizes = append(sizes, PageSize{
+					PageNumber: int(info.Number),
+					Width:      info.Dimensions[0],
+					Height:     info.Dimensions[1],
+				})
+			}
+		}
+		structure.PageSizes = sizes
+		return nil
+	}
+
+	if structure == nil {
+		structure = &PageStructure{
+			TotalCount: uint64(len(sizes)),
+			UnitType:   PageUnitTypePage,
+		}
+		result.Metadata.PageStructure = structure
+	}
+	structure.PageSizes = sizes
+	return nil
+}
@@ -0,0 +1,107 @@
+package kreuzberg
+
+import (
+	"path/filepath"
+	"reflect"
+	"testing"
+)
+
+func TestPageSizesFromPageTree(t *testing.T) {
+	data := buildTestPDF(
+		`<< /Type /Catalog /Pages 2 0 R >>`,
+		`<< /Type /Pages /Kids [3 0 R 4 0 R 5 0 R] /Count 3 /MediaBox [0 0 612 792] /Rotate 90 >>`,
+		`<< /Type /Page /Parent 2 0 R >>`,
+		`<< /Type /Page /Parent 2 0 R /MediaBox [0 0 842 595] /Rotate -360 >>`,
+		`<< /Type /Page /Parent 2 0 R /CropBox [36 36 576 756] /Rotate 270 >>`,
+	)
+	result := &ExtractionResult{MimeType: "application/pdf"}
+	config := &ExtractionConfig{Pages: NewPageConfig(WithPageGeometry(true))}
+	if err := applyPageSizes(result, config, &documentSource{data: data}); err != nil {
+		t.Fatalf("applyPageSizes failed: %v", err)
+	}
+
+	structure := result.Metadata.PageStructure
+	if structure == nil || structure.TotalCount != 3 || structure.UnitType != PageUnitTypePage {
+		t.Fatalf("unexpected page structure: %+v", structure)
+	}
+	want := []PageSize{
+		{PageNumber: 1, Width: 612, Height: 792, Rotation: 90},
+		{PageNumber: 2, Width: 842, Height: 595, Rotation: 0},
+		{PageNumber: 3, Width: 540, Height: 720, Rotation: 270},
+	}
+	if !reflect.DeepEqual(structure.PageSizes, want) {
+		t.Fatalf("PageSizes = %+v, want %+v", structure.PageSizes, want)
+	}
+	for i, landscape := range []bool{true, true, true} {
+		if got := structure.PageSizes[i].Landscape(); got != landscape {
+			t.Errorf("page %d Landscape() = %v, want %v", i+1, got, landscape)
+		}
+	}
+}
+
+func TestPageSizesFallBackToNativeDimensions(t *testing.T) {
+	result := &ExtractionResult{MimeType: "application/pdf"}
+	result.Metadata.PageStructure = &PageStructure{
+		TotalCount: 1,
+		UnitType:   PageUnitTypePage,
+		Pages:      []PageInfo{{Number: 1, Dimensions: &[2]float64{595, 842}}},
+	}
+	config := &ExtractionConfig{Pages: NewPageConfig(WithPageGeometry(true))}
+	if err := applyPageSizes(result, config, &documentSource{data: []byte("not a pdf")}); err != nil {
+		t.Fatalf("applyPageSizes failed: %v", err)
+	}
+	want := []PageSize{{PageNumber: 1, Width: 595, Height: 842}}
+	if !reflect.DeepEqual(result.Metadata.PageStructure.PageSizes, want) {
+		t.Fatalf("PageSizes = %+v, want %+v", result.Metadata.PageStructure.PageSizes, want)
+	}
+}
+
+// TestPageSizesDefaultToNativeDimensions verifies that without WithPageGeometry the source
+// document is never read.
+func TestPageSizesDefaultToNativeDimensions(t *testing.T) {
+	result := &ExtractionResult{MimeType: "application/pdf"}
+	result.Metadata.PageStructure = &PageStructure{
+		TotalCount: 2,
+		UnitType:   PageUnitTypePage,
+		Pages: []PageInfo{
+			{Number: 1, Dimensions: &[2]float64{612, 792}},
+			{Number: 2, Dimensions: &[2]float64{842, 595}},
+		},
+	}
+	src := &documentSource{path: filepath.Join(t.TempDir(), "missing.pdf")}
+	if err := applyPageSizes(result, &ExtractionConfig{}, src); err != nil {
+		t.Fatalf("applyPageSizes failed: %v", err)
+	}
+	want := []PageSize{{PageNumber: 1, Width: 612, Height: 792}, {PageNumber: 2, Width: 842, Height: 595}}
+	if !reflect.DeepEqual(result.Metadata.PageStructure.PageSizes, want) {
+		t.Fatalf("PageSizes = %+v, want %+v", result.Metadata.PageStructure.PageSizes, want)
+	}
+}
+
+func TestPageSizesIgnoresOtherFormats(t *testing.T) {
+	result := &ExtractionResult{MimeType: "text/plain"}
+	if err := applyPageSizes(result, nil, &documentSource{data: []byte("hello")}); err != nil {
+		t.Fatalf("applyPageSizes failed: %v", err)
+	}
+	if result.Metadata.PageStructure != nil {
+		t.Fatalf("expected no page structure, got %+v", result.Metadata.PageStructure)
+	}
+}
+
+func TestPageSizesFromFixture(t *testing.T) {
+	path := getTestFilePath("pdf/with_images.pdf")
+	result := &ExtractionResult{MimeType: "application/pdf"}
+	config := &ExtractionConfig{Pages: NewPageConfig(WithPageGeometry(true))}
+	if err := applyPageSizes(result, config, &documentSource{path: path}); err != nil {
+		t.Skipf("fixture unavailable: %v", err)
+	}
+	structure := result.Metadata.PageStructure
+	if structure == nil || len(structure.PageSizes) == 0 {
+		t.Fatal("expected page sizes for fixture PDF")
+	}
+	for _, size := range structure.PageSizes {
+		if size.Width <= 0 || size.Height <= 0 {
+			t.Errorf("page %d has invalid size %vx%v", size.PageNumber, size.Width, size.Height)
+		}
+	}
+}
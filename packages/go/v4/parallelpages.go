@@ -0,0 +1,68 @@
+package kreuzberg
+
+import (
+	"context"
+)
+
+// pageExtractor extracts one page of a document split into pages.
+type pageExtractor func(ctx context.Context, page []byte) (*ExtractionResult, error)
+
+// pageBatchExtractor extracts several pages of a document split into pages concurrently,
+// returning their results in the order of pages.
+type pageBatchExtractor func(ctx context.Context, pages [][]byte) ([]*ExtractionResult, error)
+
+// parallelPages returns the number of pages WithParallelPages lets be OCR'd at once, 1 when
+// it is not set.
+func parallelPages(config *ExtractionConfig) int {
+	if config == nil || config.ParallelPages == nil || *config.ParallelPages < 1 {
+		return 1
+	}
+	return *config.ParallelPages
+}
+
+// extractPagesInOrder extracts count pages and passes each result, or the error extracting
+// it, to emit in page order; an error returned by emit stops the extraction. page returns
+// the document of page i and is called for at most n pages at a time, so no more than n
+// pages are held at once.
+//
+// With n above 1, each run of n pages is extracted with batch, which the native library
+// works through concurrently. A page the batch returns no usable result for, because the batch
+// failed or reported an error for the page, is extracted again with single, so it fails
+// exactly as it does when pages are extracted one at a time with single.
+func extractPagesInOrder(ctx context.Context, count, n int, page func(i int) ([]byte, error), single pageExtractor, batch pageBatchExtractor, emit func(i int, result *ExtractionResult, err error) error) error {
+	if batch == nil {
+		n = 1
+	}
+	n = max(n, 1)
+	for start := 0; start < count; start += n {
+		if err := ctx.Err(); err != nil {
+			return err
+		}
+		pages := make([][]byte, 0, min(n, count-start))
+		for i := start; i < start+cap(pages); i++ {
+			data, err := page(i)
+			if err != nil {
+				return err
+			}
+			pages = append(pages, data)
+		}
+		var results []*ExtractionResult
+		if len(pages) > 1 {
+			// A failed batch falls back to extracting its pages one at a time below.
+			results, _ = batch(ctx, pages)
+		}
+		for j, data := range pages {
+			var result *ExtractionResult
+			var err error
+			if j < len(results) && results[j] != nil && results[j].Metadata.Error == nil {
+				result = results[j]
+			} else {
+				result, err = single(ctx, data)
+			}
+			if err := emit(start+j, result, err); err != nil {
+				return err
+			}
+		}
+	}
+	return nil
+}
@@ -0,0 +1,332 @@
+package kreuzberg
+
+import (
+	"context"
+	"errors"
+	"os"
+	"path/filepath"
+	"reflect"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+)
+
+// fakeFrameOCR OCRs TIFF frames by looking up the word a test frame was rendered from,
+// recording how many frames are in flight at once.
+type fakeFrameOCR struct {
+	frames map[string]int
+	words  []string
+	fail   map[int]bool
+
+	mu       sync.Mutex
+	inFlight int
+	peak     int
+	batches  []int
+	ocrd     map[int]int
+}
+
+func newFakeFrameOCR(data []byte, words ...string) *fakeFrameOCR {
+	order, offsets := tiffIFDOffsets(data)
+	f := &fakeFrameOCR{frames: make(map[string]int), words: words, fail: make(map[int]bool), ocrd: make(map[int]int)}
+	for i, offset := range offsets {
+		frame, _ := tiffFrame(data, order, offset)
+		f.frames[string(frame)] = i
+	}
+	return f
+}
+
+func (f *fakeFrameOCR) enter(n int) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	f.inFlight += n
+	f.peak = max(f.peak, f.inFlight)
+}
+
+func (f *fakeFrameOCR) leave(n int) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	f.inFlight -= n
+}
+
+func (f *fakeFrameOCR) ocr(frame []byte) (*ExtractionResult, error) {
+	i, ok := f.frames[string(frame)]
+	f.mu.Lock()
+	f.ocrd[i]++
+	f.mu.Unlock()
+	if !ok || f.fail[i] {
+		return nil, newOCRErrorWithContext("frame unreadable", nil, ErrorCodeOcr, nil)
+	}
+	return &ExtractionResult{Content: " " + f.words[i] + "\n", Success: true}, nil
+}
+
+func (f *fakeFrameOCR) extract(_ context.Context, data []byte, mimeType string, _ *ExtractionConfig) (*ExtractionResult, error) {
+	if mimeType == "text/plain" {
+		// The joined text, chunked and language-detected again.
+		return &ExtractionResult{
+			Content:           string(data),
+			Chunks:            []Chunk{{Content: string(data), Metadata: ChunkMetadata{ByteStart: 0, ByteEnd: uint64(len(data))}}},
+			DetectedLanguages: []string{"eng"},
+		}, nil
+	}
+	f.enter(1)
+	defer f.leave(1)
+	return f.ocr(data)
+}
+
+func (f *fakeFrameOCR) extractBatch(_ context.Context, items []BytesWithMime, config *ExtractionConfig) ([]*ExtractionResult, error) {
+	f.mu.Lock()
+	f.batches = append(f.batches, len(items))
+	f.mu.Unlock()
+	if config.MaxConcurrentExtractions == nil || *config.MaxConcurrentExtractions < len(items) {
+		return nil, errors.New("batch larger than its concurrency")
+	}
+	f.enter(len(items))
+	defer f.leave(len(items))
+	results := make([]*ExtractionResult, len(items))
+	for i, item := range items {
+		result, err := f.ocr(item.Data)
+		if err != nil {
+			msg := err.Error()
+			result = &ExtractionResult{Metadata: Metadata{Error: &ErrorMetadata{Message: msg}}}
+		}
+		results[i] = result
+	}
+	return results, nil
+}
+
+func ocrFakeTIFF(t *testing.T, data []byte, ocr *fakeFrameOCR, opts ...ExtractionOption) (*ExtractionResult, error) {
+	t.Helper()
+	opts = append([]ExtractionOption{WithOCR(WithOCRBackend("tesseract")), WithPages(WithExtractPages(true), WithInsertPageMarkers(true))}, opts...)
+	// The native library OCRs the first frame of the file.
+	result := &ExtractionResult{Content: ocr.words[0], MimeType: "image/tiff", Success: true}
+	err := ocrTIFFPages(context.Background(), result, NewExtractionConfig(opts...), &documentSource{data: data}, ocr.extract, ocr.extractBatch)
+	return result, err
+}
+
+// TestParallelPagesTIFF verifies that OCRing TIFF frames concurrently gives exactly the
+// serial result, with no more than n frames in flight.
+func TestParallelPagesTIFF(t *testing.T) {
+	words := []string{"ALPHA", "BRAVO", "CHARLIE", "DELTA", "ECHO", "FOXTROT", "GOLF"}
+	frames := make([]tiffTestFrame, len(words))
+	for i, word := range words {
+		frames[i] = renderWord(word)
+	}
+	data := encodeTIFF(frames...)
+
+	serialOCR := newFakeFrameOCR(data, words...)
+	serial, err := ocrFakeTIFF(t, data, serialOCR, WithAllowPartialResults(true))
+	if err != nil {
+		t.Fatalf("serial OCR failed: %v", err)
+	}
+	if len(serial.Pages) != len(words) || serialOCR.peak != 1 || len(serialOCR.batches) != 0 {
+		t.Fatalf("unexpected serial OCR: %d pages, peak %d, batches %v", len(serial.Pages), serialOCR.peak, serialOCR.batches)
+	}
+	if serial.Pages[0].Content != words[0] || serialOCR.ocrd[0] != 0 || serialOCR.ocrd[1] != 1 {
+		t.Fatalf("expected the native result to stand for the first frame, OCR'd %v", serialOCR.ocrd)
+	}
+
+	for _, n := range []int{2, 3, 16} {
+		ocr := newFakeFrameOCR(data, words...)
+		parallel, err := ocrFakeTIFF(t, data, ocr, WithAllowPartialResults(true), WithParallelPages(n))
+		if err != nil {
+			t.Fatalf("n=%d: OCR failed: %v", n, err)
+		}
+		if !reflect.DeepEqual(parallel, serial) {
+			t.Fatalf("n=%d: result differs from the serial one:\n%+v\n%+v", n, parallel, serial)
+		}
+		if ocr.peak > n || ocr.peak < 2 {
+			t.Errorf("n=%d: %d frames in flight at once", n, ocr.peak)
+		}
+		for _, size := range ocr.batches {
+			if size > n {
+				t.Errorf("n=%d: batch of %d frames", n, size)
+			}
+		}
+	}
+
+	// A frame the batch fails is OCR'd again on its own and fails the same way.
+	serialOCR = newFakeFrameOCR(data, words...)
+	serialOCR.fail[3] = true
+	serial, err = ocrFakeTIFF(t, data, serialOCR, WithAllowPartialResults(true))
+	if err != nil {
+		t.Fatalf("serial OCR failed: %v", err)
+	}
+	ocr := newFakeFrameOCR(data, words...)
+	ocr.fail[3] = true
+	parallel, err := ocrFakeTIFF(t, data, ocr, WithAllowPartialResults(true), WithParallelPages(3))
+	if err != nil {
+		t.Fatalf("OCR failed: %v", err)
+	}
+	if !reflect.DeepEqual(parallel, serial) || parallel.Success || len(parallel.Warnings) != 1 {
+		t.Fatalf("failed frame handled differently:\n%+v\n%+v", parallel, serial)
+	}
+	if _, err := ocrFakeTIFF(t, data, ocr, WithParallelPages(3)); err == nil {
+		t.Fatal("expected the failed frame to fail the extraction without partial results")
+	}
+
+	// Frames with a page timeout are OCR'd one at a time.
+	ocr = newFakeFrameOCR(data, words...)
+	if _, err := ocrFakeTIFF(t, data, ocr, WithParallelPages(3), WithOCR(WithOCRBackend("tesseract"), WithOCRPageTimeout(time.Minute))); err != nil {
+		t.Fatalf("OCR with a page timeout failed: %v", err)
+	}
+	if len(ocr.batches) != 0 {
+		t.Errorf("expected no batches with a page timeout, got %v", ocr.batches)
+	}
+}
+
+// TestTIFFPagesContentFeatures verifies that the chunks and languages of a multi-page TIFF
+// are computed from all of its frames, and that a sampled TIFF reports its full page count.
+func TestTIFFPagesContentFeatures(t *testing.T) {
+	words := []string{"ALPHA", "BRAVO", "CHARLIE"}
+	frames := make([]tiffTestFrame, len(words))
+	for i, word := range words {
+		frames[i] = renderWord(word)
+	}
+	data := encodeTIFF(frames...)
+
+	ocr := newFakeFrameOCR(data, words...)
+	result, err := ocrFakeTIFF(t, data, ocr, WithChunking(WithMaxChars(1000)), WithLanguageDetection())
+	if err != nil {
+		t.Fatalf("OCR failed: %v", err)
+	}
+	if len(result.Chunks) != 1 || result.Chunks[0].Content != result.Content || !strings.Contains(result.Content, "CHARLIE") {
+		t.Fatalf("expected the chunks to cover every frame, got %+v for %q", result.Chunks, result.Content)
+	}
+	if len(result.DetectedLanguages) != 1 {
+		t.Fatalf("expected languages detected from the joined text, got %v", result.DetectedLanguages)
+	}
+
+	sampled := &ExtractionResult{Content: words[0], MimeType: "image/tiff", Success: true, SampledPages: []int{1, 3}}
+	config := NewExtractionConfig(WithOCR(WithOCRBackend("tesseract")))
+	if err := ocrTIFFPages(context.Background(), sampled, config, &documentSource{data: data}, ocr.extract, ocr.extractBatch); err != nil {
+		t.Fatalf("OCR of the sampled TIFF failed: %v", err)
+	}
+	if structure := sampled.Metadata.PageStructure; structure == nil || structure.TotalCount != 3 || len(structure.Boundaries) != 2 {
+		t.Fatalf("expected 2 of 3 pages, got %+v", structure)
+	}
+}
+
+// TestExtractPagesInOrder verifies the windows pages are held in and that cancellation
+// stops before the next window.
+func TestExtractPagesInOrder(t *testing.T) {
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	var requested, emitted []int
+	page := func(i int) ([]byte, error) {
+		requested = append(requested, i)
+		return []byte{byte(i)}, nil
+	}
+	single := func(_ context.Context, page []byte) (*ExtractionResult, error) {
+		return &ExtractionResult{Content: string(rune('a' + page[0]))}, nil
+	}
+	batch := func(ctx context.Context, pages [][]byte) ([]*ExtractionResult, error) {
+		results := make([]*ExtractionResult, len(pages))
+		for i, page := range pages {
+			results[i], _ = single(ctx, page)
+		}
+		return results, nil
+	}
+	err := extractPagesInOrder(ctx, 10, 4, page, single, batch, func(i int, result *ExtractionResult, err error) error {
+		if err != nil || result.Content != string(rune('a'+i)) {
+			t.Fatalf("page %d: got %+v, %v", i, result, err)
+		}
+		if len(requested) > i+4 {
+			t.Fatalf("page %d: %d pages requested before it was emitted", i, len(requested))
+		}
+		emitted = append(emitted, i)
+		if i == 5 {
+			cancel()
+		}
+		return nil
+	})
+	if !errors.Is(err, context.Canceled) {
+		t.Fatalf("expected cancellation, got %v", err)
+	}
+	if !reflect.DeepEqual(emitted, []int{0, 1, 2, 3, 4, 5, 6, 7}) || len(requested) != 8 {
+		t.Fatalf("expected the window in flight to finish, emitted %v of %v", emitted, requested)
+	}
+}
+
+// TestExtractFilePagedParallelPages verifies that ExtractFilePaged gives the same pages in
+// the same order with WithParallelPages.
+func TestExtractFilePagedParallelPages(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "pages.pdf")
+	if err := os.WriteFile(path, testSamplePDF(7), 0o600); err != nil {
+		t.Fatal(err)
+	}
+	var valErr *ValidationError
+	if err := ExtractFilePaged(path, NewExtractionConfig(WithParallelPages(0)), func(PageResult) error { return nil }); !errors.As(err, &valErr) {
+		t.Fatalf("expected ValidationError for 0 parallel pages, got %v", err)
+	}
+
+	collect := func(opts ...ExtractionOption) []PageResult {
+		var pages []PageResult
+		if err := ExtractFilePaged(path, NewExtractionConfig(append([]ExtractionOption{WithRawText(true)}, opts...)...), func(page PageResult) error {
+			pages = append(pages, page)
+			return nil
+		}); err != nil {
+			t.Skipf("raw text extraction unavailable: %v", err)
+		}
+		return pages
+	}
+	serial := collect()
+	if len(serial) != 7 {
+		t.Fatalf("got %d pages, want 7", len(serial))
+	}
+	if parallel := collect(WithParallelPages(3)); !reflect.DeepEqual(parallel, serial) {
+		t.Fatalf("parallel pages differ:\n%+v\n%+v", parallel, serial)
+	}
+}
+
+// TestParallelPagesPDF verifies that OCRing the pages of a scanned PDF concurrently gives
+// exactly the serial result, with each run of n pages in one batch.
+func TestParallelPagesPDF(t *testing.T) {
+	data := testSamplePDF(7)
+	ocrPage := func(pdf []byte) *ExtractionResult {
+		texts, _ := mustParsePDF(t, pdf).rawText()
+		return &ExtractionResult{Content: strings.Join(texts, "") + " OCR", Success: true}
+	}
+	extract := func(_ context.Context, pdf []byte, mimeType string, config *ExtractionConfig) (*ExtractionResult, error) {
+		if config.OCR == nil {
+			// The scan has no text layer.
+			return &ExtractionResult{MimeType: mimeType, Success: true}, nil
+		}
+		return ocrPage(pdf), nil
+	}
+	var batches []int
+	extractBatch := func(_ context.Context, items []BytesWithMime, config *ExtractionConfig) ([]*ExtractionResult, error) {
+		if config.MaxConcurrentExtractions == nil || *config.MaxConcurrentExtractions < len(items) {
+			return nil, errors.New("batch larger than its concurrency")
+		}
+		batches = append(batches, len(items))
+		results := make([]*ExtractionResult, len(items))
+		for i, item := range items {
+			results[i] = ocrPage(item.Data)
+		}
+		return results, nil
+	}
+	ocr := func(opts ...ExtractionOption) *ExtractionResult {
+		opts = append([]ExtractionOption{WithOCR(WithOCRBackend("tesseract")), WithPages(WithExtractPages(true), WithInsertPageMarkers(true))}, opts...)
+		result, err := ocrPDFPages(context.Background(), data, NewExtractionConfig(opts...), extract, extractBatch)
+		if err != nil {
+			t.Fatalf("ocrPDFPages failed: %v", err)
+		}
+		return result
+	}
+
+	serial := ocr()
+	if len(serial.Pages) != 7 || serial.Pages[6].Content != "Page 7 OCR" || len(batches) != 0 {
+		t.Fatalf("unexpected serial OCR: %+v, batches %v", serial.Pages, batches)
+	}
+	if !ocrsPDFPages(data, "application/pdf", NewExtractionConfig(WithOCR(), WithParallelPages(3))) {
+		t.Fatal("expected scanned PDFs to be split into pages with WithParallelPages")
+	}
+	parallel := ocr(WithParallelPages(3))
+	if !reflect.DeepEqual(parallel, serial) {
+		t.Fatalf("parallel result differs from the serial one:\n%+v\n%+v", parallel, serial)
+	}
+	if !reflect.DeepEqual(batches, []int{3, 3}) {
+		t.Fatalf("expected two batches of 3 and a single page, got %v", batches)
+	}
+}
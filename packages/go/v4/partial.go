@@ -0,0 +1,64 @@
+package kreuzberg
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"fmt"
+)
+
+func allowPartialResults(config *ExtractionConfig) bool {
+	return config != nil && config.AllowPartialResults != nil && *config.AllowPartialResults
+}
+
+// extractPartialResult salvages what it can of a document whose extraction failed with err
+// when partial results are allowed. PDFs are read page by page from their content streams,
+// skipping pages that cannot be read. The result has Success set to false and warnings
+// describing the failure. It returns false when nothing could be recovered.
+func extractPartialResult(ctx context.Context, src *documentSource, config *ExtractionConfig, err error) (*ExtractionResult, bool) {
+	if !allowPartialResults(config) || ctx.Err() != nil {
+		return nil, false
+	}
+	var validation *ValidationError
+	if errors.As(err, &validation) {
+		// Invalid input or configuration is not a failure of part of the document.
+		return nil, false
+	}
+	data, readErr := src.bytes()
+	if readErr != nil || !bytes.HasPrefix(data, []byte("%PDF-")) {
+		return nil, false
+	}
+
+	doc, err := parsePDFDocument(data)
+	if err != nil || doc.encrypted {
+		return nil, false
+	}
+	pages := doc.pages()
+	texts := make([]pdfPageText, len(pages))
+	fonts := make(map[any]*pdfFontDecoder)
+	var failed []int
+	hasText := false
+	for i, page := range pages {
+		text, ok := doc.pageText(page, fonts)
+		if !ok {
+			failed = append(failed, i+1)
+			continue
+		}
+		texts[i] = text
+		hasText = hasText || text.text != ""
+	}
+	if !hasText {
+		return nil, false
+	}
+
+	result, buildErr := rawTextResult(ctx, doc, data, texts, config)
+	if buildErr != nil {
+		return nil, false
+	}
+	result.Success = false
+	result.addWarning(WarningCodePartialResult, 0, fmt.Sprintf("extraction failed (%v); content was recovered from the PDF text layer", err))
+	for _, page := range failed {
+		result.addWarning(WarningCodePageFailed, page, fmt.Sprintf("page %d could not be read and was skipped", page))
+	}
+	return result, true
+}
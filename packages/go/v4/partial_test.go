@@ -0,0 +1,78 @@
+package kreuzberg
+
+import (
+	"context"
+	"testing"
+)
+
+// testPartialPDF has a readable first page and a second page whose content stream is
+// corrupt.
+var testPartialPDF = buildTestPDF(
+	`<< /Type /Catalog /Pages 2 0 R >>`,
+	`<< /Type /Pages /Kids [3 0 R 4 0 R] /Count 2 /Resources << /Font << /F1 5 0 R >> >> >>`,
+	`<< /Type /Page /Parent 2 0 R /Contents 6 0 R >>`,
+	`<< /Type /Page /Parent 2 0 R /Contents 7 0 R >>`,
+	`<< /Type /Font /Subtype /Type1 /BaseFont /Helvetica >>`,
+	testPDFStream("BT /F1 12 Tf 72 700 Td (Surviving page) Tj ET", true),
+	"<< /Length 9 /Filter /FlateDecode >>\nstream\nnot zlib!\nendstream",
+)
+
+func TestPartialResultsNotApplicable(t *testing.T) {
+	failure := newParsingErrorWithContext("page 2 is corrupt", nil, ErrorCodeParsing, nil)
+	src := &documentSource{data: testPartialPDF}
+	ctx := context.Background()
+
+	doc := mustParsePDF(t, testPartialPDF)
+	pages := doc.pages()
+	fonts := make(map[any]*pdfFontDecoder)
+	if text, ok := doc.pageText(pages[0], fonts); !ok || text.text != "Surviving page" {
+		t.Fatalf("first page = %q, %v", text.text, ok)
+	}
+	if _, ok := doc.pageText(pages[1], fonts); ok {
+		t.Fatal("the corrupt page should not be readable")
+	}
+
+	if _, ok := extractPartialResult(ctx, src, NewExtractionConfig(), failure); ok {
+		t.Fatal("partial results must be opt-in")
+	}
+	partial := NewExtractionConfig(WithAllowPartialResults(true))
+	validation := newValidationErrorWithContext("bad config", nil, ErrorCodeValidation, nil)
+	if _, ok := extractPartialResult(ctx, src, partial, validation); ok {
+		t.Fatal("validation errors must not produce partial results")
+	}
+	if _, ok := extractPartialResult(ctx, &documentSource{data: []byte("plain text")}, partial, failure); ok {
+		t.Fatal("only PDFs can be recovered")
+	}
+	cancelled, cancel := context.WithCancel(ctx)
+	cancel()
+	if _, ok := extractPartialResult(cancelled, src, partial, failure); ok {
+		t.Fatal("cancelled extractions must not produce partial results")
+	}
+}
+
+func TestPartialResults(t *testing.T) {
+	if _, err := ExtractBytesSync([]byte("probe"), "text/plain", nil); err != nil {
+		t.Skipf("native library unavailable: %v", err)
+	}
+	failure := newParsingErrorWithContext("page 2 is corrupt", nil, ErrorCodeParsing, nil)
+	config := NewExtractionConfig(WithAllowPartialResults(true), WithUseCache(false))
+	result, ok := extractPartialResult(context.Background(), &documentSource{data: testPartialPDF}, config, failure)
+	if !ok {
+		t.Fatal("expected a partial result")
+	}
+	if result.Success || result.Content != "Surviving page" {
+		t.Fatalf("unexpected partial result: success=%v content=%q", result.Success, result.Content)
+	}
+	var pageFailed *ExtractionWarning
+	for i, warning := range result.Warnings {
+		if warning.Code == WarningCodePageFailed {
+			pageFailed = &result.Warnings[i]
+		}
+	}
+	if pageFailed == nil || pageFailed.PageNumber != 2 {
+		t.Fatalf("expected a page_failed warning for page 2, got %+v", result.Warnings)
+	}
+	if len(result.Warnings) != 2 {
+		t.Fatalf("expected the failure and the skipped page to be reported, got %+v", result.Warnings)
+	}
+}
@@ -0,0 +1,113 @@
+package kreuzberg
+
+import (
+	"sort"
+	"strings"
+)
+
+// DefaultPartSeparator joins the parts of a multi-part document in Content when no
+// PartSeparator is configured. It matches the blank line the native library places between
+// archive members.
+const DefaultPartSeparator = "\n\n"
+
+// archiveContentsMarker introduces the member contents in native archive results.
+const archiveContentsMarker = "\n\nText File Contents:\n\n"
+
+// applyPartSeparator rebuilds the content of archive results from their parts: the archive
+// summary followed by one "=== path ===" section per text member, in archive order, joined
+// by the configured separator. The byte offset at which each part starts is recorded in
+// result.PartBoundaries.
+func applyPartSeparator(result *ExtractionResult, config *ExtractionConfig) {
+	archive, ok := result.Metadata.ArchiveMetadata()
+	if !ok {
+		return
+	}
+	separator := DefaultPartSeparator
+	if config.PartSeparator != nil {
+		separator = *config.PartSeparator
+	}
+
+	summary, members := result.Content, ""
+	if idx := strings.Index(result.Content, archiveContentsMarker); idx >= 0 {
+		summary, members = result.Content[:idx], result.Content[idx+len(archiveContentsMarker):]
+	}
+	parts := append([]string{strings.TrimRight(summary, "\n")}, splitArchiveMembers(members, archive.FileList)...)
+
+	var content strings.Builder
+	boundaries := make([]int, 0, len(parts))
+	for i, part := range parts {
+		if i > 0 {
+			content.WriteString(separator)
+		}
+		boundaries = append(boundaries, content.Len())
+		content.WriteString(part)
+	}
+	result.Content = content.String()
+	result.PartBoundaries = boundaries
+}
+
+// splitArchiveMembers splits the native "=== path ===\n{content}\n\n" member sections and
+// returns them ordered as the members appear in fileList. Only headers naming a listed
+// file start a new section, so member text that merely resembles a header stays intact.
+func splitArchiveMembers(members string, fileList []string) []string {
+	if members == "" {
+		return nil
+	}
+	order := make(map[string]int, len(fileList))
+	for i, name := range fileList {
+		if _, seen := order[name]; !seen {
+			order[name] = i
+		}
+	}
+
+	type section struct {
+		start, index int
+		text         string
+	}
+	var sections []section
+	used := make(map[string]bool)
+	for pos := 0; pos < len(members); {
+		idx := strings.Index(members[pos:], "=== ")
+		if idx < 0 {
+			break
+		}
+		start := pos + idx
+		pos = start + len("=== ")
+		if start != 0 && !strings.HasSuffix(members[:start], "\n\n") {
+			continue
+		}
+		lineEnd := strings.IndexByte(members[start:], '\n')
+		if lineEnd < 0 {
+			break
+		}
+		line := members[start : start+lineEnd]
+		if !strings.HasSuffix(line, " ===") || len(line) < len("===  ===") {
+			continue
+		}
+		name := line[len("=== ") : len(line)-len(" ===")]
+		index, listed := order[name]
+		if !listed || used[name] {
+			continue
+		}
+		used[name] = true
+		sections = append(sections, section{start: start, index: index})
+	}
+	if len(sections) == 0 {
+		return []string{strings.TrimSuffix(members, "\n\n")}
+	}
+
+	for i := range sections {
+		end := len(members)
+		if i+1 < len(sections) {
+			end = sections[i+1].start
+		}
+		sections[i].text = strings.TrimSuffix(members[sections[i].start:end], "\n\n")
+	}
+	sort.SliceStable(sections, func(i, j int) bool { return sections[i].index < sections[j].index })
+
+	parts := make([]string, len(sections))
+	for i, s := range sections {
+		parts[i] = s.text
+	}
+	return parts
+}
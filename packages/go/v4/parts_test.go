@@ -0,0 +1,79 @@
+package kreuzberg
+
+import (
+	"reflect"
+	"strings"
+	"testing"
+)
+
+const testArchiveContent = "ZIP Archive (3 files, 30 bytes)\n\nFiles:\n- a.txt (10 bytes)\n- b.txt (10 bytes)\n- img.png (10 bytes)\n" +
+	"\n\nText File Contents:\n\n" +
+	"=== b.txt ===\nsecond\n\n=== x ===\nstill b\n\n" +
+	"=== a.txt ===\nfirst\n\n"
+
+func testArchiveResult() *ExtractionResult {
+	result := &ExtractionResult{Content: testArchiveContent, MimeType: "application/zip"}
+	result.Metadata.Format = FormatMetadata{
+		Type:    FormatArchive,
+		Archive: &ArchiveMetadata{Format: "ZIP", FileCount: 3, FileList: []string{"a.txt", "b.txt", "img.png"}},
+	}
+	return result
+}
+
+func TestPartSeparatorDefault(t *testing.T) {
+	result := testArchiveResult()
+	applyPartSeparator(result, NewExtractionConfig())
+
+	want := []string{
+		"ZIP Archive (3 files, 30 bytes)\n\nFiles:\n- a.txt (10 bytes)\n- b.txt (10 bytes)\n- img.png (10 bytes)",
+		"=== a.txt ===\nfirst",
+		"=== b.txt ===\nsecond\n\n=== x ===\nstill b",
+	}
+	if result.Content != strings.Join(want, DefaultPartSeparator) {
+		t.Fatalf("unexpected content:\n%q", result.Content)
+	}
+	if got := splitAtBoundaries(result.Content, result.PartBoundaries, DefaultPartSeparator); !reflect.DeepEqual(got, want) {
+		t.Fatalf("parts = %q, want %q", got, want)
+	}
+}
+
+func TestPartSeparatorCustom(t *testing.T) {
+	result := testArchiveResult()
+	applyPartSeparator(result, NewExtractionConfig(WithPartSeparator("\f")))
+
+	if strings.Count(result.Content, "\f") != 2 {
+		t.Fatalf("expected two form feeds, got %q", result.Content)
+	}
+	if !reflect.DeepEqual(result.PartBoundaries, []int{0, strings.Index(result.Content, "=== a.txt"), strings.Index(result.Content, "=== b.txt")}) {
+		t.Fatalf("unexpected boundaries %v for %q", result.PartBoundaries, result.Content)
+	}
+}
+
+func TestPartSeparatorIgnoresOtherFormats(t *testing.T) {
+	result := &ExtractionResult{Content: "a\n\nb", MimeType: "text/plain"}
+	applyPartSeparator(result, NewExtractionConfig(WithPartSeparator("\f")))
+	if result.Content != "a\n\nb" || result.PartBoundaries != nil {
+		t.Fatalf("unexpected result: %q %v", result.Content, result.PartBoundaries)
+	}
+}
+
+func TestContentFilterDropsPartBoundaries(t *testing.T) {
+	result := testArchiveResult()
+	applyPartSeparator(result, NewExtractionConfig())
+	applyFilters(result, NewExtractionConfig(WithContentFilter(strings.ToUpper)))
+	if result.PartBoundaries != nil {
+		t.Fatalf("expected boundaries to be dropped, got %v", result.PartBoundaries)
+	}
+}
+
+func splitAtBoundaries(content string, boundaries []int, separator string) []string {
+	parts := make([]string, len(boundaries))
+	for i, start := range boundaries {
+		end := len(content)
+		if i+1 < len(boundaries) {
+			end = boundaries[i+1] - len(separator)
+		}
+		parts[i] = content[start:end]
+	}
+	return parts
+}
@@ -0,0 +1,338 @@
+package kreuzberg
+
+import (
+	"bytes"
+	"strconv"
+	"strings"
+	"unicode/utf16"
+)
+
+// pdfFontDecoder maps the character codes of a font to Unicode text and glyph widths.
+type pdfFontDecoder struct {
+	// codespace holds the byte ranges of valid codes, from the ToUnicode CMap.
+	codespace [][2][]byte
+	toUnicode map[string]string
+	// simple maps single-byte codes of simple fonts, used when ToUnicode has no entry.
+	simple *[256]rune
+
+	// Glyph widths in thousandths of text space units, keyed by code (simple fonts) or CID
+	// (composite fonts, whose codes are read as CIDs).
+	widths       map[uint32]float64
+	defaultWidth float64
+}
+
+// newPDFFontDecoder returns nil for composite fonts without a ToUnicode CMap, whose codes
+// cannot be mapped to text without the embedded font program.
+func newPDFFontDecoder(doc *pdfDocument, font pdfDict) *pdfFontDecoder {
+	decoder := &pdfFontDecoder{widths: make(map[uint32]float64)}
+	composite := font.name("Subtype") == "Type0"
+	if stream, ok := doc.stream(font["ToUnicode"]); ok {
+		if data, ok := stream.decode(); ok {
+			decoder.parseCMap(data)
+		}
+	}
+	if composite {
+		if decoder.toUnicode == nil {
+			return nil
+		}
+		decoder.loadCIDWidths(doc, font)
+	} else {
+		decoder.simple = simpleFontEncoding(doc, font)
+		decoder.loadSimpleWidths(doc, font)
+	}
+	if len(decoder.codespace) == 0 {
+		width := 1
+		if composite {
+			width = 2
+		}
+		decoder.codespace = [][2][]byte{{bytes.Repeat([]byte{0}, width), bytes.Repeat([]byte{0xff}, width)}}
+	}
+	return decoder
+}
+
+// loadSimpleWidths reads /Widths, scaled by /FontMatrix for Type3 fonts. Standard fonts
+// without widths get an average width.
+func (f *pdfFontDecoder) loadSimpleWidths(doc *pdfDocument, font pdfDict) {
+	scale := 1.0
+	if matrix := doc.array(font["FontMatrix"]); len(matrix) == 6 {
+		if a, ok := doc.resolve(matrix[0]).(float64); ok && a != 0 {
+			scale = a * 1000
+		}
+	}
+	f.defaultWidth = 500
+	if descriptor := doc.dict(font["FontDescriptor"]); descriptor != nil {
+		if missing, ok := doc.resolve(descriptor["MissingWidth"]).(float64); ok && missing > 0 {
+			f.defaultWidth = missing * scale
+		}
+	}
+	first, _ := doc.resolve(font["FirstChar"]).(float64)
+	for i, value := range doc.array(font["Widths"]) {
+		if width, ok := doc.resolve(value).(float64); ok {
+			f.widths[uint32(int(first)+i)] = width * scale
+		}
+	}
+}
+
+// loadCIDWidths reads /DW and /W from the descendant CIDFont.
+func (f *pdfFontDecoder) loadCIDWidths(doc *pdfDocument, font pdfDict) {
+	f.defaultWidth = 1000
+	descendants := doc.array(font["DescendantFonts"])
+	if len(descendants) == 0 {
+		return
+	}
+	cidFont := doc.dict(descendants[0])
+	if dw, ok := doc.resolve(cidFont["DW"]).(float64); ok {
+		f.defaultWidth = dw
+	}
+	entries := doc.array(cidFont["W"])
+	for i := 0; i+1 < len(entries); {
+		first, ok := doc.resolve(entries[i]).(float64)
+		if !ok {
+			return
+		}
+		if list := doc.array(entries[i+1]); list != nil {
+			// c [w1 w2 ...]
+			for j, value := range list {
+				if width, ok := doc.resolve(value).(float64); ok {
+					f.widths[uint32(int(first)+j)] = width
+				}
+			}
+			i += 2
+			continue
+		}
+		// cFirst cLast w
+		if i+2 >= len(entries) {
+			return
+		}
+		last, ok1 := doc.resolve(entries[i+1]).(float64)
+		width, ok2 := doc.resolve(entries[i+2]).(float64)
+		if !ok1 || !ok2 || last < first || last-first >= pdfMaxBFRange {
+			return
+		}
+		for cid := uint32(first); cid <= uint32(last); cid++ {
+			f.widths[cid] = width
+		}
+		i += 3
+	}
+}
+
+// decode returns the text of raw, the sum of its glyph widths in thousandths of text space
+// units, the number of codes and the number of single-byte space codes, which are affected
+// by word spacing.
+func (f *pdfFontDecoder) decode(raw []byte) (string, float64, int, int) {
+	var out strings.Builder
+	width := 0.0
+	codes, spaces := 0, 0
+	for i := 0; i < len(raw); {
+		n := f.codeLength(raw[i:])
+		code := raw[i : i+n]
+		i += n
+		codes++
+		if n == 1 && code[0] == ' ' {
+			spaces++
+		}
+
+		value := bytesToUint(code)
+		if w, ok := f.widths[value]; ok {
+			width += w
+		} else {
+			width += f.defaultWidth
+		}
+		if text, ok := f.toUnicode[string(code)]; ok {
+			out.WriteString(text)
+		} else if f.simple != nil && n == 1 {
+			if r := f.simple[code[0]]; r != 0 {
+				out.WriteRune(r)
+			}
+		}
+	}
+	return out.String(), width, codes, spaces
+}
+
+// codeLength returns the byte length of the code at the start of raw.
+func (f *pdfFontDecoder) codeLength(raw []byte) int {
+	for _, r := range f.codespace {
+		n := len(r[0])
+		if n == 0 || n > len(raw) {
+			continue
+		}
+		inRange := true
+		for i := range n {
+			if raw[i] < r[0][i] || raw[i] > r[1][i] {
+				inRange = false
+				break
+			}
+		}
+		if inRange {
+			return n
+		}
+	}
+	return 1
+}
+
+// parseCMap reads the codespace ranges and bfchar/bfrange mappings of a ToUnicode CMap.
+func (f *pdfFontDecoder) parseCMap(data []byte) {
+	f.toUnicode = make(map[string]string)
+	lexer := &pdfLexer{data: data}
+	var operands []any
+	for {
+		token, ok := lexer.contentToken()
+		if !ok {
+			return
+		}
+		op, isOp := token.(pdfOperator)
+		if !isOp {
+			operands = append(operands, token)
+			continue
+		}
+		switch op {
+		case "endcodespacerange":
+			for i := 0; i+1 < len(operands); i += 2 {
+				lo, _ := operands[i].(pdfBytes)
+				hi, _ := operands[i+1].(pdfBytes)
+				if len(lo) > 0 && len(lo) == len(hi) {
+					f.codespace = append(f.codespace, [2][]byte{lo, hi})
+				}
+			}
+		case "endbfchar":
+			for i := 0; i+1 < len(operands); i += 2 {
+				src, _ := operands[i].(pdfBytes)
+				if dst, ok := operands[i+1].(pdfBytes); ok {
+					f.toUnicode[string(src)] = decodeUTF16BE(dst)
+				}
+			}
+		case "endbfrange":
+			for i := 0; i+2 < len(operands); i += 3 {
+				f.addBFRange(operands[i], operands[i+1], operands[i+2])
+			}
+		}
+		operands = operands[:0]
+	}
+}
+
+// pdfMaxBFRange bounds the number of codes a single bfrange entry may define.
+const pdfMaxBFRange = 1 << 16
+
+func (f *pdfFontDecoder) addBFRange(loValue, hiValue, dst any) {
+	lo, _ := loValue.(pdfBytes)
+	hi, _ := hiValue.(pdfBytes)
+	if len(lo) == 0 || len(lo) != len(hi) || len(lo) > 4 {
+		return
+	}
+	start, end := bytesToUint(lo), bytesToUint(hi)
+	if end < start || end-start >= pdfMaxBFRange {
+		return
+	}
+	code := make([]byte, len(lo))
+	for n := start; n <= end; n++ {
+		for i := range code {
+			code[len(code)-1-i] = byte(n >> (8 * i))
+		}
+		offset := n - start
+		switch dst := dst.(type) {
+		case pdfBytes:
+			if len(dst) == 0 {
+				continue
+			}
+			units := make([]uint16, 0, len(dst)/2)
+			for i := 0; i+1 < len(dst); i += 2 {
+				units = append(units, uint16(dst[i])<<8|uint16(dst[i+1]))
+			}
+			if len(units) == 0 {
+				continue
+			}
+			units[len(units)-1] += uint16(offset)
+			f.toUnicode[string(code)] = string(utf16.Decode(units))
+		case pdfArray:
+			if int(offset) < len(dst) {
+				if value, ok := dst[offset].(pdfBytes); ok {
+					f.toUnicode[string(code)] = decodeUTF16BE(value)
+				}
+			}
+		}
+	}
+}
+
+func bytesToUint(b []byte) uint32 {
+	var n uint32
+	for _, c := range b {
+		n = n<<8 | uint32(c)
+	}
+	return n
+}
+
+func decodeUTF16BE(b []byte) string {
+	units := make([]uint16, 0, len(b)/2)
+	for i := 0; i+1 < len(b); i += 2 {
+		units = append(units, uint16(b[i])<<8|uint16(b[i+1]))
+	}
+	return string(utf16.Decode(units))
+}
+
+// winAnsiHigh maps the WinAnsiEncoding codes 0x80-0x9F, which differ from Latin-1.
+var winAnsiHigh = [32]rune{
+	'€', 0, '‚', 'ƒ', '„', '…', '†', '‡', 'ˆ', '‰', 'Š', '‹', 'Œ', 0, 'Ž', 0,
+	0, '‘', '’', '“', '”', '•', '–', '—', '˜', '™', 'š', '›', 'œ', 0, 'ž', 'Ÿ',
+}
+
+// pdfGlyphNames maps common glyph names used in /Differences arrays that are not single
+// characters or uniXXXX names.
+var pdfGlyphNames = map[string]rune{
+	"space": ' ', "exclam": '!', "quotedbl": '"', "numbersign": '#', "dollar": '$',
+	"percent": '%', "ampersand": '&', "quotesingle": '\'', "parenleft": '(', "parenright": ')',
+	"asterisk": '*', "plus": '+', "comma": ',', "hyphen": '-', "period": '.', "slash": '/',
+	"zero": '0', "one": '1', "two": '2', "three": '3', "four": '4', "five": '5', "six": '6',
+	"seven": '7', "eight": '8', "nine": '9', "colon": ':', "semicolon": ';', "less": '<',
+	"equal": '=', "greater": '>', "question": '?', "at": '@', "bracketleft": '[',
+	"backslash": '\\', "bracketright": ']', "underscore": '_', "braceleft": '{', "bar": '|',
+	"braceright": '}', "quoteleft": '‘', "quoteright": '’', "quotedblleft": '“',
+	"quotedblright": '”', "endash": '–', "emdash": '—', "bullet": '•', "ellipsis": '…',
+	"fi": 'ﬁ', "fl": 'ﬂ', "minus": '−', "degree": '°', "copyright": '©', "registered": '®',
+	"trademark": '™', "section": '§', "paragraph": '¶', "dagger": '†', "daggerdbl": '‡',
+}
+
+// simpleFontEncoding returns the code-to-rune table of a simple font: WinAnsiEncoding (a
+// superset of the standard Latin text encodings for printable ASCII) adjusted by the
+// font's /Differences array.
+func simpleFontEncoding(doc *pdfDocument, font pdfDict) *[256]rune {
+	var table [256]rune
+	for i := 0x20; i < 0x100; i++ {
+		table[i] = rune(i)
+	}
+	for i, r := range winAnsiHigh {
+		table[0x80+i] = r
+	}
+	table['\t'], table['\n'], table['\r'] = ' ', ' ', ' '
+
+	encoding := doc.dict(font["Encoding"])
+	code := 0
+	for _, item := range doc.array(encoding["Differences"]) {
+		switch value := doc.resolve(item).(type) {
+		case float64:
+			code = int(value)
+		case pdfName:
+			if code >= 0 && code < len(table) {
+				if r, ok := glyphNameRune(string(value)); ok {
+					table[code] = r
+				}
+			}
+			code++
+		}
+	}
+	return &table
+}
+
+func glyphNameRune(name string) (rune, bool) {
+	if len(name) == 1 {
+		return rune(name[0]), true
+	}
+	if r, ok := pdfGlyphNames[name]; ok {
+		return r, true
+	}
+	if strings.HasPrefix(name, "uni") && len(name) == 7 {
+		if value, err := strconv.ParseUint(name[3:], 16, 32); err == nil {
+			return rune(value), true
+		}
+	}
+	return 0, false
+}
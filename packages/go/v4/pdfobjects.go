@@ -0,0 +1,887 @@
+package kreuzberg
+
+import (
+	"bytes"
+	"compress/zlib"
+	"errors"
+	"io"
+	"math"
+	"strconv"
+	"unicode/utf16"
+)
+
+// This file implements a small, read-only reader for PDF object structure. It is used by
+// Go-side stages that need document structure the native library does not report (form
+// fields, signatures, layers) and by the raw text reader in pdftext.go. It does not render or decrypt anything: encrypted documents
+// are detected and left alone, and only FlateDecode streams are decoded.
+//
+// Documents are untrusted input, so the reader bounds the work it does: arrays and
+// dictionaries may nest at most maxPDFNestingDepth levels, a stream decodes to at most
+// maxPDFStreamBytes, and object streams are only decoded once an object they hold is
+// looked up.
+
+const (
+	// maxPDFNestingDepth is the deepest nesting of arrays and dictionaries the reader
+	// accepts. Real documents stay in the single digits.
+	maxPDFNestingDepth = 256
+	// maxPDFStreamBytes is the most a single stream may decode to.
+	maxPDFStreamBytes = 64 << 20
+)
+
+var (
+	errPDFNestingTooDeep = errors.New("pdf objects nest too deeply")
+	errPDFStreamTooLarge = errors.New("pdf stream decodes to more than the allowed size")
+)
+
+type (
+	pdfName  string
+	pdfDict  map[string]any
+	pdfArray []any
+	pdfRef   struct{ num, gen int }
+)
+
+type pdfStream struct {
+	dict pdfDict
+	raw  []byte
+}
+
+type pdfDocument struct {
+	objects map[int]any
+	// spans are the byte ranges of the bodies of objects that are not in object streams.
+	spans     map[int][2]int
+	trailers  []pdfDict
+	encrypted bool
+	// layers, when set, restricts the text read from the document to the selected layers.
+	layers *pdfLayerFilter
+
+	// objectStreams are the object streams of the document in file order, by object
+	// number. They are decoded on demand by object.
+	objectStreams []int
+	loadedStreams map[int]bool
+	// compressed maps the numbers of objects stored in object streams to the number of the
+	// stream holding their current version, as declared by the cross-reference streams.
+	// It is built on the first lookup that misses.
+	compressed map[int]int
+	xrefs      []*pdfStream
+}
+
+func isPDFMimeType(mimeType string) bool {
+	return mimeType == "application/pdf"
+}
+
+// parsePDFDocument scans data for indirect objects. Objects appearing later in the file
+// override earlier ones, which matches how incremental updates are applied. Objects packed
+// into object streams are read when first looked up. It fails when the objects nest more
+// deeply than maxPDFNestingDepth.
+func parsePDFDocument(data []byte) (*pdfDocument, error) {
+	doc := &pdfDocument{objects: make(map[int]any), spans: make(map[int][2]int), loadedStreams: make(map[int]bool)}
+
+	for pos := 0; ; {
+		num, bodyStart, ok := nextPDFObjectHeader(data, pos)
+		if !ok {
+			break
+		}
+		pos = bodyStart
+		lexer := &pdfLexer{data: data, pos: bodyStart}
+		value, ok := lexer.parseValue()
+		if lexer.err != nil {
+			return nil, lexer.err
+		}
+		if !ok {
+			continue
+		}
+		pos = lexer.pos
+		if dict, isDict := value.(pdfDict); isDict {
+			if stream, end := lexer.readStream(dict); stream != nil {
+				value = stream
+				pos = end
+				if dict.name("Type") == "ObjStm" {
+					doc.objectStreams = append(doc.objectStreams, num)
+				}
+				if dict.name("Type") == "XRef" {
+					doc.trailers = append(doc.trailers, dict)
+					doc.xrefs = append(doc.xrefs, stream)
+				}
+			}
+		}
+		doc.objects[num] = value
+		doc.spans[num] = [2]int{bodyStart, pos}
+	}
+
+	for pos := 0; ; {
+		index := bytes.Index(data[pos:], []byte("trailer"))
+		if index < 0 {
+			break
+		}
+		lexer := &pdfLexer{data: data, pos: pos + index + len("trailer")}
+		value, ok := lexer.parseValue()
+		if lexer.err != nil {
+			return nil, lexer.err
+		}
+		if ok {
+			if dict, isDict := value.(pdfDict); isDict {
+				doc.trailers = append(doc.trailers, dict)
+			}
+		}
+		pos += index + len("trailer")
+	}
+	for _, trailer := range doc.trailers {
+		if _, ok := trailer["Encrypt"]; ok {
+			doc.encrypted = true
+		}
+	}
+	return doc, nil
+}
+
+// nextPDFObjectHeader finds the next "<num> <gen> obj" header at or after from and returns
+// the object number and the offset of the object body.
+func nextPDFObjectHeader(data []byte, from int) (int, int, bool) {
+	for from < len(data) {
+		index := bytes.Index(data[from:], []byte("obj"))
+		if index < 0 {
+			return 0, 0, false
+		}
+		keyword := from + index
+		from = keyword + len("obj")
+		if from < len(data) && !isPDFDelimiter(data[from]) {
+			continue
+		}
+		genEnd := keyword
+		for genEnd > 0 && isPDFWhitespace(data[genEnd-1]) {
+			genEnd--
+		}
+		genStart := genEnd
+		for genStart > 0 && data[genStart-1] >= '0' && data[genStart-1] <= '9' {
+			genStart--
+		}
+		numEnd := genStart
+		for numEnd > 0 && isPDFWhitespace(data[numEnd-1]) {
+			numEnd--
+		}
+		numStart := numEnd
+		for numStart > 0 && data[numStart-1] >= '0' && data[numStart-1] <= '9' {
+			numStart--
+		}
+		if genStart == genEnd || genEnd == keyword || numStart == numEnd || numEnd == genStart {
+			continue
+		}
+		num, err := strconv.Atoi(string(data[numStart:numEnd]))
+		if err != nil {
+			continue
+		}
+		return num, from, true
+	}
+	return 0, 0, false
+}
+
+// object returns object num, decoding the object stream holding it when it is not a
+// direct object.
+func (d *pdfDocument) object(num int) any {
+	if value, ok := d.objects[num]; ok {
+		return value
+	}
+	if len(d.loadedStreams) == len(d.objectStreams) {
+		return nil
+	}
+	if d.compressed == nil {
+		d.indexCompressedObjects()
+	}
+	if stream, ok := d.compressed[num]; ok {
+		d.loadObjectStream(stream)
+		return d.objects[num]
+	}
+	// Without a cross-reference entry the object may be in any stream. Later object
+	// streams belong to later revisions, so they are searched first.
+	for i := len(d.objectStreams) - 1; i >= 0; i-- {
+		d.loadObjectStream(d.objectStreams[i])
+		if value, ok := d.objects[num]; ok {
+			return value
+		}
+	}
+	return nil
+}
+
+// loadAllObjects decodes every object stream, for callers that walk all objects.
+func (d *pdfDocument) loadAllObjects() {
+	for i := len(d.objectStreams) - 1; i >= 0; i-- {
+		d.loadObjectStream(d.objectStreams[i])
+	}
+}
+
+// indexCompressedObjects reads the type 2 entries of the cross-reference streams into
+// d.compressed. Later streams belong to later revisions and override earlier entries.
+func (d *pdfDocument) indexCompressedObjects() {
+	d.compressed = make(map[int]int)
+	for _, xref := range d.xrefs {
+		data, ok := xref.decode()
+		if !ok {
+			continue
+		}
+		widths, _ := xref.dict["W"].(pdfArray)
+		if len(widths) != 3 {
+			continue
+		}
+		var w [3]int
+		for i, width := range widths {
+			n, _ := width.(float64)
+			if n < 0 || n > 8 {
+				w[0] = -1
+				break
+			}
+			w[i] = int(n)
+		}
+		rowSize := w[0] + w[1] + w[2]
+		if w[0] < 0 || rowSize == 0 {
+			continue
+		}
+		if params, ok := xref.dict["DecodeParms"].(pdfDict); ok {
+			if data, ok = pdfUnpredict(data, params, rowSize); !ok {
+				continue
+			}
+		}
+		index, _ := xref.dict["Index"].(pdfArray)
+		if index == nil {
+			size, _ := xref.dict["Size"].(float64)
+			index = pdfArray{0.0, size}
+		}
+		row := 0
+		for i := 0; i+1 < len(index); i += 2 {
+			start, _ := index[i].(float64)
+			count, _ := index[i+1].(float64)
+			if start < 0 || count < 0 || start+count > math.MaxInt32 {
+				continue
+			}
+			for j := 0; j < int(count) && (row+1)*rowSize <= len(data); j++ {
+				fields := data[row*rowSize : (row+1)*rowSize]
+				row++
+				kind := 1
+				if w[0] > 0 {
+					kind = int(pdfBigEndian(fields[:w[0]]))
+				}
+				if kind == 2 {
+					d.compressed[int(start)+j] = int(pdfBigEndian(fields[w[0] : w[0]+w[1]]))
+				}
+			}
+		}
+	}
+}
+
+func pdfBigEndian(b []byte) uint64 {
+	var n uint64
+	for _, c := range b {
+		n = n<<8 | uint64(c)
+	}
+	return n
+}
+
+// pdfUnpredict reverses the PNG predictors cross-reference streams are compressed with.
+func pdfUnpredict(data []byte, params pdfDict, columns int) ([]byte, bool) {
+	predictor, _ := params["Predictor"].(float64)
+	if predictor < 10 {
+		return data, predictor <= 1
+	}
+	if c, ok := params["Columns"].(float64); ok {
+		// Checked before converting: a row cannot be wider than the data, and converting a
+		// float beyond the int range is implementation-dependent.
+		if c < 1 || c > float64(len(data)) {
+			return nil, false
+		}
+		columns = int(c)
+	}
+	if columns <= 0 || columns > len(data) {
+		return nil, false
+	}
+	stride := columns + 1
+	out := make([]byte, 0, len(data)/stride*columns)
+	prev := make([]byte, columns)
+	for row := 0; (row+1)*stride <= len(data); row++ {
+		line := data[row*stride : (row+1)*stride]
+		cur := make([]byte, columns)
+		for i := range columns {
+			var left, upLeft byte
+			if i > 0 {
+				left, upLeft = cur[i-1], prev[i-1]
+			}
+			up := prev[i]
+			switch line[0] {
+			case 0:
+				cur[i] = line[i+1]
+			case 1:
+				cur[i] = line[i+1] + left
+			case 2:
+				cur[i] = line[i+1] + up
+			case 3:
+				cur[i] = line[i+1] + byte((int(left)+int(up))/2)
+			case 4:
+				cur[i] = line[i+1] + pdfPaeth(left, up, upLeft)
+			default:
+				return nil, false
+			}
+		}
+		out = append(out, cur...)
+		prev = cur
+	}
+	return out, true
+}
+
+func pdfPaeth(a, b, c byte) byte {
+	p := int(a) + int(b) - int(c)
+	distance := func(n int) int { return max(n-p, p-n) }
+	pa, pb, pc := distance(int(a)), distance(int(b)), distance(int(c))
+	switch {
+	case pa <= pb && pa <= pc:
+		return a
+	case pb <= pc:
+		return b
+	default:
+		return c
+	}
+}
+
+// loadObjectStream decodes the object stream num and adds the objects it holds that are
+// neither direct objects nor declared by the cross-reference streams to live elsewhere.
+func (d *pdfDocument) loadObjectStream(num int) {
+	stream, ok := d.objects[num].(*pdfStream)
+	if !ok || stream.dict.name("Type") != "ObjStm" || d.loadedStreams[num] {
+		return
+	}
+	d.loadedStreams[num] = true
+	data, ok := stream.decode()
+	if !ok {
+		return
+	}
+	count, _ := stream.dict["N"].(float64)
+	first, _ := stream.dict["First"].(float64)
+	if count <= 0 || first < 0 || int(first) > len(data) {
+		return
+	}
+	header := &pdfLexer{data: data[:int(first)]}
+	for i := 0; i < int(count); i++ {
+		object, ok1 := header.parseValue()
+		offset, ok2 := header.parseValue()
+		n, isNum := object.(float64)
+		o, isOffset := offset.(float64)
+		if !ok1 || !ok2 || !isNum || !isOffset {
+			return
+		}
+		start := int(first) + int(o)
+		if start < 0 || start >= len(data) {
+			continue
+		}
+		if _, exists := d.objects[int(n)]; exists {
+			continue
+		}
+		if holder, ok := d.compressed[int(n)]; ok && holder != num {
+			continue
+		}
+		lexer := &pdfLexer{data: data, pos: start}
+		if value, ok := lexer.parseValue(); ok {
+			d.objects[int(n)] = value
+		}
+	}
+}
+
+// resolve follows indirect references.
+func (d *pdfDocument) resolve(value any) any {
+	for range 32 {
+		ref, ok := value.(pdfRef)
+		if !ok {
+			break
+		}
+		value = d.object(ref.num)
+	}
+	if stream, ok := value.(*pdfStream); ok {
+		return stream.dict
+	}
+	return value
+}
+
+// stream follows indirect references to a stream object.
+func (d *pdfDocument) stream(value any) (*pdfStream, bool) {
+	for range 32 {
+		ref, ok := value.(pdfRef)
+		if !ok {
+			break
+		}
+		value = d.object(ref.num)
+	}
+	stream, ok := value.(*pdfStream)
+	return stream, ok
+}
+
+func (d *pdfDocument) dict(value any) pdfDict {
+	dict, _ := d.resolve(value).(pdfDict)
+	return dict
+}
+
+func (d *pdfDocument) array(value any) pdfArray {
+	array, _ := d.resolve(value).(pdfArray)
+	return array
+}
+
+// catalog returns the document catalog, falling back to a scan when no trailer names it.
+func (d *pdfDocument) catalog() pdfDict {
+	for i := len(d.trailers) - 1; i >= 0; i-- {
+		if root := d.dict(d.trailers[i]["Root"]); root != nil {
+			return root
+		}
+	}
+	d.loadAllObjects()
+	for _, object := range d.objects {
+		if dict, ok := object.(pdfDict); ok && dict.name("Type") == "Catalog" {
+			return dict
+		}
+	}
+	return nil
+}
+
+// pdfPage is a leaf of the page tree with its inheritable attributes resolved.
+type pdfPage struct {
+	objectNumber int
+	dict         pdfDict
+	mediaBox     [4]float64
+	cropBox      *[4]float64
+	rotate       int
+	resources    pdfDict
+}
+
+// pages returns the document's pages in page tree order.
+func (d *pdfDocument) pages() []pdfPage {
+	catalog := d.catalog()
+	if catalog == nil {
+		return nil
+	}
+	var pages []pdfPage
+	visited := make(map[int]bool)
+	var walk func(node any, inherited pdfDict)
+	walk = func(node any, inherited pdfDict) {
+		ref, isRef := node.(pdfRef)
+		if isRef {
+			if visited[ref.num] {
+				return
+			}
+			visited[ref.num] = true
+		}
+		dict := d.dict(node)
+		if dict == nil {
+			return
+		}
+		attrs := pdfDict{}
+		for _, key := range []string{"MediaBox", "CropBox", "Rotate", "Resources"} {
+			if value, ok := dict[key]; ok {
+				attrs[key] = value
+			} else if value, ok := inherited[key]; ok {
+				attrs[key] = value
+			}
+		}
+		if kids := d.array(dict["Kids"]); kids != nil && dict.name("Type") != "Page" {
+			for _, kid := range kids {
+				walk(kid, attrs)
+			}
+			return
+		}
+
+		page := pdfPage{dict: dict}
+		if isRef {
+			page.objectNumber = ref.num
+		}
+		if box, ok := d.rect(attrs["MediaBox"]); ok {
+			page.mediaBox = box
+		} else {
+			// US Letter is the default when a page omits its MediaBox.
+			page.mediaBox = [4]float64{0, 0, 612, 792}
+		}
+		if box, ok := d.rect(attrs["CropBox"]); ok {
+			page.cropBox = &box
+		}
+		if rotate, ok := d.resolve(attrs["Rotate"]).(float64); ok {
+			page.rotate = ((int(rotate) % 360) + 360) % 360
+		}
+		page.resources = d.dict(attrs["Resources"])
+		pages = append(pages, page)
+	}
+	walk(catalog["Pages"], nil)
+	return pages
+}
+
+// pageNumbers maps page object numbers to 1-based page numbers in page tree order.
+func (d *pdfDocument) pageNumbers() map[int]int {
+	numbers := make(map[int]int)
+	for i, page := range d.pages() {
+		if page.objectNumber != 0 {
+			numbers[page.objectNumber] = i + 1
+		}
+	}
+	return numbers
+}
+
+// rect resolves a rectangle array into normalized [llx lly urx ury] coordinates.
+func (d *pdfDocument) rect(value any) ([4]float64, bool) {
+	array := d.array(value)
+	if len(array) != 4 {
+		return [4]float64{}, false
+	}
+	var rect [4]float64
+	for i, item := range array {
+		number, ok := d.resolve(item).(float64)
+		if !ok {
+			return [4]float64{}, false
+		}
+		rect[i] = number
+	}
+	rect[0], rect[2] = min(rect[0], rect[2]), max(rect[0], rect[2])
+	rect[1], rect[3] = min(rect[1], rect[3]), max(rect[1], rect[3])
+	return rect, true
+}
+
+func (d pdfDict) name(key string) pdfName {
+	name, _ := d[key].(pdfName)
+	return name
+}
+
+// readStream reads the raw stream following dict and returns it with the offset just past
+// "endstream". Decoding is deferred to pdfStream.decode, since most streams are page
+// content the reader never needs.
+func (l *pdfLexer) readStream(dict pdfDict) (*pdfStream, int) {
+	l.skipSpace()
+	if !bytes.HasPrefix(l.data[l.pos:], []byte("stream")) {
+		return nil, 0
+	}
+	start := l.pos + len("stream")
+	if start < len(l.data) && l.data[start] == '\r' {
+		start++
+	}
+	if start < len(l.data) && l.data[start] == '\n' {
+		start++
+	}
+	end := bytes.Index(l.data[start:], []byte("endstream"))
+	if end < 0 {
+		return nil, 0
+	}
+	raw := bytes.TrimRight(l.data[start:start+end], "\r\n")
+	return &pdfStream{dict: dict, raw: raw}, start + end + len("endstream")
+}
+
+// decode returns the stream data with FlateDecode applied. Streams using other filters,
+// and streams that would decode to more than their limit, are reported as undecodable.
+func (s *pdfStream) decode() ([]byte, bool) {
+	filter := s.dict["Filter"]
+	if array, ok := filter.(pdfArray); ok && len(array) == 1 {
+		filter = array[0]
+	}
+	switch filter {
+	case nil:
+		return s.raw, true
+	case pdfName("FlateDecode"):
+		decoded, err := inflatePDFStream(s.raw, maxPDFStreamBytes)
+		if errors.Is(err, errPDFStreamTooLarge) || (err != nil && len(decoded) == 0) {
+			return nil, false
+		}
+		return decoded, true
+	default:
+		return nil, false
+	}
+}
+
+// inflatePDFStream inflates zlib data, failing with errPDFStreamTooLarge once the output
+// exceeds limit bytes. Other errors are returned with the data inflated so far, since
+// truncated streams are common and their prefix is still usable.
+func inflatePDFStream(raw []byte, limit int64) ([]byte, error) {
+	reader, err := zlib.NewReader(bytes.NewReader(raw))
+	if err != nil {
+		return nil, err
+	}
+	decoded, err := io.ReadAll(io.LimitReader(reader, limit+1))
+	if int64(len(decoded)) > limit {
+		return nil, errPDFStreamTooLarge
+	}
+	return decoded, err
+}
+
+// pdfLexer parses PDF object syntax into pdfDict, pdfArray, pdfName, pdfRef, string,
+// float64, bool and nil values.
+type pdfLexer struct {
+	data []byte
+	pos  int
+	// depth is the number of arrays and dictionaries being parsed.
+	depth int
+	// err is set, and parsing stops, when the nesting exceeds maxPDFNestingDepth.
+	err error
+}
+
+// enter records the start of an array or dictionary, refusing to go deeper than
+// maxPDFNestingDepth so hostile input cannot exhaust the stack.
+func (l *pdfLexer) enter() bool {
+	if l.depth >= maxPDFNestingDepth {
+		l.err = errPDFNestingTooDeep
+		return false
+	}
+	l.depth++
+	return true
+}
+
+func (l *pdfLexer) leave() {
+	l.depth--
+}
+
+func isPDFWhitespace(c byte) bool {
+	return c == ' ' || c == '\n' || c == '\r' || c == '\t' || c == '\f' || c == 0
+}
+
+func isPDFDelimiter(c byte) bool {
+	return isPDFWhitespace(c) || bytes.IndexByte([]byte("()<>[]{}/%"), c) >= 0
+}
+
+func (l *pdfLexer) skipSpace() {
+	for l.pos < len(l.data) {
+		c := l.data[l.pos]
+		if isPDFWhitespace(c) {
+			l.pos++
+			continue
+		}
+		if c == '%' {
+			for l.pos < len(l.data) && l.data[l.pos] != '\n' && l.data[l.pos] != '\r' {
+				l.pos++
+			}
+			continue
+		}
+		return
+	}
+}
+
+func (l *pdfLexer) token() string {
+	start := l.pos
+	for l.pos < len(l.data) && !isPDFDelimiter(l.data[l.pos]) {
+		l.pos++
+	}
+	return string(l.data[start:l.pos])
+}
+
+func (l *pdfLexer) parseValue() (any, bool) {
+	l.skipSpace()
+	if l.pos >= len(l.data) {
+		return nil, false
+	}
+	switch c := l.data[l.pos]; {
+	case c == '/':
+		l.pos++
+		return pdfName(decodePDFName(l.token())), true
+	case c == '(':
+		return l.parseLiteralString(), true
+	case c == '<' && l.pos+1 < len(l.data) && l.data[l.pos+1] == '<':
+		return l.parseDict()
+	case c == '<':
+		return l.parseHexString(), true
+	case c == '[':
+		return l.parseArray()
+	case c == '+' || c == '-' || c == '.' || (c >= '0' && c <= '9'):
+		return l.parseNumberOrRef()
+	default:
+		switch word := l.token(); word {
+		case "true":
+			return true, true
+		case "false":
+			return false, true
+		case "null":
+			return nil, true
+		default:
+			return nil, false
+		}
+	}
+}
+
+func (l *pdfLexer) parseDict() (any, bool) {
+	if !l.enter() {
+		return nil, false
+	}
+	defer l.leave()
+	l.pos += 2
+	dict := pdfDict{}
+	for {
+		l.skipSpace()
+		if l.pos+1 < len(l.data) && l.data[l.pos] == '>' && l.data[l.pos+1] == '>' {
+			l.pos += 2
+			return dict, true
+		}
+		key, ok := l.parseValue()
+		if !ok {
+			return nil, false
+		}
+		name, isName := key.(pdfName)
+		if !isName {
+			return nil, false
+		}
+		value, ok := l.parseValue()
+		if !ok {
+			return nil, false
+		}
+		dict[string(name)] = value
+	}
+}
+
+func (l *pdfLexer) parseArray() (any, bool) {
+	if !l.enter() {
+		return nil, false
+	}
+	defer l.leave()
+	l.pos++
+	array := pdfArray{}
+	for {
+		l.skipSpace()
+		if l.pos < len(l.data) && l.data[l.pos] == ']' {
+			l.pos++
+			return array, true
+		}
+		value, ok := l.parseValue()
+		if !ok {
+			return nil, false
+		}
+		array = append(array, value)
+	}
+}
+
+func (l *pdfLexer) parseNumberOrRef() (any, bool) {
+	number, err := strconv.ParseFloat(l.token(), 64)
+	if err != nil {
+		return nil, false
+	}
+	// An integer followed by another integer and "R" is an indirect reference.
+	save := l.pos
+	l.skipSpace()
+	if gen, err := strconv.Atoi(l.token()); err == nil && number == float64(int(number)) {
+		l.skipSpace()
+		if l.pos < len(l.data) && l.data[l.pos] == 'R' && (l.pos+1 == len(l.data) || isPDFDelimiter(l.data[l.pos+1])) {
+			l.pos++
+			return pdfRef{num: int(number), gen: gen}, true
+		}
+	}
+	l.pos = save
+	return number, true
+}
+
+func (l *pdfLexer) parseLiteralString() string {
+	return decodePDFText(l.literalStringBytes())
+}
+
+// literalStringBytes reads a "(...)" string and returns its bytes with escapes resolved.
+func (l *pdfLexer) literalStringBytes() []byte {
+	l.pos++
+	var out []byte
+	depth := 1
+	for l.pos < len(l.data) {
+		c := l.data[l.pos]
+		l.pos++
+		switch c {
+		case '(':
+			depth++
+		case ')':
+			depth--
+			if depth == 0 {
+				return out
+			}
+		case '\\':
+			if l.pos >= len(l.data) {
+				continue
+			}
+			esc := l.data[l.pos]
+			l.pos++
+			switch esc {
+			case 'n':
+				c = '\n'
+			case 'r':
+				c = '\r'
+			case 't':
+				c = '\t'
+			case 'b':
+				c = '\b'
+			case 'f':
+				c = '\f'
+			case '\r', '\n':
+				if esc == '\r' && l.pos < len(l.data) && l.data[l.pos] == '\n' {
+					l.pos++
+				}
+				continue
+			default:
+				if esc >= '0' && esc <= '7' {
+					value := int(esc - '0')
+					for i := 0; i < 2 && l.pos < len(l.data) && l.data[l.pos] >= '0' && l.data[l.pos] <= '7'; i++ {
+						value = value*8 + int(l.data[l.pos]-'0')
+						l.pos++
+					}
+					c = byte(value)
+				} else {
+					c = esc
+				}
+			}
+		}
+		out = append(out, c)
+	}
+	return out
+}
+
+func (l *pdfLexer) parseHexString() string {
+	return decodePDFText(l.hexStringBytes())
+}
+
+// hexStringBytes reads a "<...>" string and returns the bytes it encodes.
+func (l *pdfLexer) hexStringBytes() []byte {
+	l.pos++
+	var digits []byte
+	for l.pos < len(l.data) && l.data[l.pos] != '>' {
+		if c := l.data[l.pos]; !isPDFWhitespace(c) {
+			digits = append(digits, c)
+		}
+		l.pos++
+	}
+	l.pos++
+	if len(digits)%2 == 1 {
+		digits = append(digits, '0')
+	}
+	out := make([]byte, 0, len(digits)/2)
+	for i := 0; i < len(digits); i += 2 {
+		value, err := strconv.ParseUint(string(digits[i:i+2]), 16, 8)
+		if err != nil {
+			break
+		}
+		out = append(out, byte(value))
+	}
+	return out
+}
+
+func decodePDFName(raw string) string {
+	if !bytes.ContainsRune([]byte(raw), '#') {
+		return raw
+	}
+	var out []byte
+	for i := 0; i < len(raw); i++ {
+		if raw[i] == '#' && i+2 < len(raw) {
+			if value, err := strconv.ParseUint(raw[i+1:i+3], 16, 8); err == nil {
+				out = append(out, byte(value))
+				i += 2
+				continue
+			}
+		}
+		out = append(out, raw[i])
+	}
+	return string(out)
+}
+
+// decodePDFText decodes a PDF text string: UTF-16BE when it carries a byte order mark,
+// UTF-8 with a BOM, and PDFDocEncoding (treated as Latin-1) otherwise.
+func decodePDFText(raw []byte) string {
+	if len(raw) >= 2 && raw[0] == 0xfe && raw[1] == 0xff {
+		units := make([]uint16, 0, len(raw)/2)
+		for i := 2; i+1 < len(raw); i += 2 {
+			units = append(units, uint16(raw[i])<<8|uint16(raw[i+1]))
+		}
+		return string(utf16.Decode(units))
+	}
+	if len(raw) >= 3 && raw[0] == 0xef && raw[1] == 0xbb && raw[2] == 0xbf {
+		return string(raw[3:])
+	}
+	runes := make([]rune, len(raw))
+	for i, b := range raw {
+		runes[i] = rune(b)
+	}
+	return string(runes)
+}
@@ -0,0 +1,108 @@
+package kreuzberg
+
+import (
+	"bytes"
+	"compress/zlib"
+	"errors"
+	"fmt"
+	"strings"
+	"testing"
+)
+
+func mustParsePDF(t testing.TB, data []byte) *pdfDocument {
+	t.Helper()
+	doc, err := parsePDFDocument(data)
+	if err != nil {
+		t.Fatalf("parsePDFDocument: %v", err)
+	}
+	return doc
+}
+
+// testObjectStream returns an object stream holding body as object num.
+func testObjectStream(num int, body string) string {
+	header := fmt.Sprintf("%d 0 ", num)
+	content := header + body
+	return fmt.Sprintf("<< /Type /ObjStm /N 1 /First %d /Length %d >>\nstream\n%s\nendstream", len(header), len(content), content)
+}
+
+func TestPDFNestingLimit(t *testing.T) {
+	deep := buildTestPDF(strings.Repeat("[", 1<<20))
+	if _, err := parsePDFDocument(deep); !errors.Is(err, errPDFNestingTooDeep) {
+		t.Fatalf("expected errPDFNestingTooDeep, got %v", err)
+	}
+	deepDict := buildTestPDF(strings.Repeat("<< /A ", 1<<16))
+	if _, err := parsePDFDocument(deepDict); !errors.Is(err, errPDFNestingTooDeep) {
+		t.Fatalf("expected errPDFNestingTooDeep for dictionaries, got %v", err)
+	}
+
+	nested := strings.Repeat("[", maxPDFNestingDepth) + strings.Repeat("]", maxPDFNestingDepth)
+	doc := mustParsePDF(t, buildTestPDF(nested))
+	if _, ok := doc.objects[1].(pdfArray); !ok {
+		t.Fatalf("expected an array at the nesting limit, got %T", doc.objects[1])
+	}
+}
+
+func TestPDFStreamDecodeLimit(t *testing.T) {
+	var packed bytes.Buffer
+	zw := zlib.NewWriter(&packed)
+	zeros := make([]byte, 1<<20)
+	for written := 0; written <= maxPDFStreamBytes; written += len(zeros) {
+		_, _ = zw.Write(zeros)
+	}
+	_ = zw.Close()
+	stream := &pdfStream{dict: pdfDict{"Filter": pdfName("FlateDecode")}, raw: packed.Bytes()}
+	if _, ok := stream.decode(); ok {
+		t.Fatal("expected a stream decoding past maxPDFStreamBytes to be refused")
+	}
+}
+
+func TestPDFObjectStreamsDecodedOnDemand(t *testing.T) {
+	entry := func(kind, field2, field3 int) string {
+		return string([]byte{byte(kind), byte(field2 >> 8), byte(field2), byte(field3)})
+	}
+	xref := entry(0, 0, 255) + entry(1, 0, 0) + entry(1, 0, 0) + entry(2, 5, 0) + entry(2, 6, 0) +
+		entry(1, 0, 0) + entry(1, 0, 0) + entry(1, 0, 0)
+	data := buildTestPDF(
+		`<< /Type /Catalog /Pages 2 0 R >>`,
+		`<< /Type /Pages /Kids [3 0 R] /Count 1 >>`,
+		`<< /Type /Page /Parent 2 0 R /MediaBox [0 0 100 100] >>`,
+		`<< /Unused true >>`,
+		testObjectStream(3, `<< /Type /Page /Parent 2 0 R /MediaBox [0 0 300 400] >>`),
+		testObjectStream(4, `<< /Unused false >>`),
+		fmt.Sprintf("<< /Type /XRef /W [1 2 1] /Size 8 /Root 1 0 R /Length %d >>\nstream\n%s\nendstream", len(xref), xref),
+	)
+	// Objects 3 and 4 appear both directly and in object streams; drop the direct copies
+	// so the document only has the compressed versions.
+	data = bytes.Replace(data, []byte("3 0 obj"), []byte("0 0 xxx"), 1)
+	data = bytes.Replace(data, []byte("4 0 obj"), []byte("0 0 xxx"), 1)
+
+	doc := mustParsePDF(t, data)
+	if len(doc.loadedStreams) != 0 {
+		t.Fatalf("expected no object stream decoded while parsing, got %v", doc.loadedStreams)
+	}
+	pages := doc.pages()
+	if len(pages) != 1 || pages[0].mediaBox != [4]float64{0, 0, 300, 400} {
+		t.Fatalf("unexpected pages %+v", pages)
+	}
+	if !doc.loadedStreams[5] || doc.loadedStreams[6] {
+		t.Fatalf("expected only the stream holding the page to be decoded, got %v", doc.loadedStreams)
+	}
+}
+
+func TestPDFUnpredict(t *testing.T) {
+	// Two rows of two columns with the Up predictor: the second row adds to the first.
+	data := []byte{2, 1, 2, 2, 1, 1}
+	out, ok := pdfUnpredict(data, pdfDict{"Predictor": 12.0, "Columns": 2.0}, 0)
+	if !ok || !bytes.Equal(out, []byte{1, 2, 2, 3}) {
+		t.Fatalf("unexpected unpredicted rows %v (%v)", out, ok)
+	}
+
+	for _, columns := range []float64{1e15, 7, 0, -3} {
+		if _, ok := pdfUnpredict(data, pdfDict{"Predictor": 12.0, "Columns": columns}, 0); ok {
+			t.Fatalf("expected /Columns %v to be rejected for %d bytes", columns, len(data))
+		}
+	}
+	huge := buildTestPDF(`<< /Type /Catalog >>`,
+		"<< /Type /XRef /W [1 2 1] /Size 2 /Root 1 0 R /DecodeParms << /Predictor 12 /Columns 1000000000000000 >> /Length 4 >>\nstream\n\x00\x00\x00\x00\nendstream")
+	mustParsePDF(t, huge)
+}
@@ -0,0 +1,769 @@
+package kreuzberg
+
+import (
+	"bytes"
+	"context"
+	"io"
+	"math"
+	"os"
+	"strconv"
+	"strings"
+)
+
+// This file implements the raw text fast path enabled by ExtractionConfig.RawText: text is
+// read from the PDF content streams in the order it is drawn, without the native layout
+// analysis. Documents the reader cannot decode (encrypted files, fonts without a Unicode
+// mapping, unsupported stream filters, pages without text) fall back to native extraction.
+
+// rawTextPageSeparator joins the text of consecutive pages in raw text results.
+const rawTextPageSeparator = "\n\n"
+
+// pdfMaxFormDepth bounds the nesting of form XObjects followed by the raw text reader.
+const pdfMaxFormDepth = 8
+
+func rawTextEnabled(config *ExtractionConfig) bool {
+	return config != nil && config.RawText != nil && *config.RawText
+}
+
+// isRawTextPDF reports whether data should take the raw text fast path.
+func isRawTextPDF(data []byte, mimeType string, config *ExtractionConfig) bool {
+	if !rawTextEnabled(config) && !layerSelectionEnabled(config) {
+		return false
+	}
+	return isPDFMimeType(normalizeMimeType(mimeType)) || bytes.HasPrefix(data, []byte("%PDF-"))
+}
+
+// readRawTextPDFFile returns the contents of path when it is a PDF that should take the raw
+// text fast path.
+func readRawTextPDFFile(path string, config *ExtractionConfig) ([]byte, bool) {
+	if !rawTextEnabled(config) && !layerSelectionEnabled(config) {
+		return nil, false
+	}
+	return readPDFFile(path)
+}
+
+// readPDFFile returns the contents of path when it is a PDF.
+func readPDFFile(path string) ([]byte, bool) {
+	if path == "" {
+		return nil, false
+	}
+	// #nosec G304 -- path is the document the caller asked us to extract
+	file, err := os.Open(path)
+	if err != nil {
+		return nil, false
+	}
+	defer file.Close()
+	header := make([]byte, 5)
+	if _, err := io.ReadFull(file, header); err != nil || string(header) != "%PDF-" {
+		return nil, false
+	}
+	if _, err := file.Seek(0, io.SeekStart); err != nil {
+		return nil, false
+	}
+	data, err := io.ReadAll(file)
+	if err != nil {
+		return nil, false
+	}
+	return data, true
+}
+
+// extractRawTextPDF builds a result from the text stored in the content streams of a PDF.
+// The text is run through the native plain-text pipeline so chunking, language detection and
+// keyword extraction still apply. It returns false when the document cannot be read this way.
+// With a layer selection only the selected layers are read, and documents without layers
+// take the raw text path only when RawText is enabled.
+func extractRawTextPDF(ctx context.Context, data []byte, config *ExtractionConfig) (*ExtractionResult, bool, error) {
+	doc, err := parsePDFDocument(data)
+	if err != nil {
+		// The native library reads documents the Go reader refuses.
+		return nil, false, nil
+	}
+	if layerSelectionEnabled(config) {
+		doc.layers = doc.layerFilter(config.LayerSelection)
+		if doc.layers == nil && !rawTextEnabled(config) {
+			return nil, false, nil
+		}
+	}
+	pageTexts, ok := doc.rawTextPages()
+	if !ok {
+		return nil, false, nil
+	}
+	result, err := rawTextResult(ctx, doc, data, pageTexts, config)
+	return result, true, err
+}
+
+// rawTextResult builds the result for the page texts read from doc.
+func rawTextResult(ctx context.Context, doc *pdfDocument, data []byte, pageTexts []pdfPageText, config *ExtractionConfig) (*ExtractionResult, error) {
+	if readingOrderColumns(config) {
+		for i := range pageTexts {
+			pageTexts[i] = pageTexts[i].columnOrder()
+		}
+	}
+	pages := make([]string, len(pageTexts))
+	for i, page := range pageTexts {
+		pages[i] = page.text
+	}
+
+	content := strings.Join(pages, rawTextPageSeparator)
+	result, err := extractBytesNative(ctx, []byte(content), "text/plain", config)
+	if err != nil {
+		return nil, err
+	}
+	result.Content = content
+	result.MimeType = "application/pdf"
+	result.Pipeline = &PipelineInfo{Extractor: ExtractorRawText}
+
+	boundaries := make([]PageBoundary, len(pages))
+	offset := 0
+	for i, text := range pages {
+		boundaries[i] = PageBoundary{ByteStart: uint64(offset), ByteEnd: uint64(offset + len(text)), PageNumber: uint64(i + 1)}
+		offset += len(text) + len(rawTextPageSeparator)
+	}
+	result.setRawTextPositions(pageTexts)
+	result.Metadata.PageStructure = &PageStructure{TotalCount: uint64(len(pages)), UnitType: PageUnitTypePage, Boundaries: boundaries}
+	result.Metadata.Format = FormatMetadata{Type: FormatPDF, Pdf: doc.rawTextMetadata(data, len(pages))}
+
+	assignChunkPages(result.Chunks, boundaries)
+
+	if config != nil && config.Pages != nil && config.Pages.ExtractPages != nil && *config.Pages.ExtractPages {
+		result.Pages = make([]PageContent, len(pages))
+		for i, text := range pages {
+			result.Pages[i] = PageContent{PageNumber: uint64(i + 1), Content: text}
+		}
+	}
+	return result, nil
+}
+
+// assignChunkPages sets the first and last page of chunks from the page boundaries of the
+// content they were cut from.
+func assignChunkPages(chunks []Chunk, boundaries []PageBoundary) {
+	for i := range chunks {
+		meta := &chunks[i].Metadata
+		for _, boundary := range boundaries {
+			if boundary.ByteEnd >= meta.ByteStart && boundary.ByteStart <= meta.ByteEnd {
+				page := boundary.PageNumber
+				if meta.FirstPage == nil {
+					meta.FirstPage = &page
+				}
+				meta.LastPage = &page
+			}
+		}
+	}
+}
+
+// setRawTextPositions records the span positions of pages, whose texts joined by
+// rawTextPageSeparator are r.Content, for LocateText.
+func (r *ExtractionResult) setRawTextPositions(pages []pdfPageText) {
+	r.textPositions = r.textPositions[:0]
+	offset := 0
+	for i, page := range pages {
+		for _, span := range page.spans {
+			r.textPositions = append(r.textPositions, textPosition{
+				start: offset + span.start, end: offset + span.end, page: i + 1, box: span.box,
+			})
+		}
+		offset += len(page.text) + len(rawTextPageSeparator)
+	}
+	r.textPositionsContent = r.Content
+}
+
+// rawTextMetadata reads the document information dictionary.
+func (d *pdfDocument) rawTextMetadata(data []byte, pageCount int) *PdfMetadata {
+	meta := &PdfMetadata{PageCount: &pageCount}
+	if end := bytes.IndexAny(data, "\r\n"); bytes.HasPrefix(data, []byte("%PDF-")) && end > 5 {
+		version := string(data[5:end])
+		meta.PDFVersion = &version
+	}
+	var info pdfDict
+	for i := len(d.trailers) - 1; i >= 0 && info == nil; i-- {
+		info = d.dict(d.trailers[i]["Info"])
+	}
+	text := func(key string) *string {
+		if value, ok := d.resolve(info[key]).(string); ok && value != "" {
+			return &value
+		}
+		return nil
+	}
+	meta.Title = text("Title")
+	meta.Subject = text("Subject")
+	meta.CreatedBy = text("Creator")
+	meta.Producer = text("Producer")
+	if author := text("Author"); author != nil {
+		meta.Authors = []string{*author}
+	}
+	return meta
+}
+
+// pdfPageText is the raw text of a page with the position of each shown string.
+type pdfPageText struct {
+	text  string
+	spans []pdfTextSpan
+}
+
+// pdfTextSpan locates text[start:end] on the page. box is [left, top, right, bottom] in
+// points from the top-left corner of the MediaBox.
+// color is the fill color of the text, or the color of the highlight behind it when
+// highlight is set; both are only read by coloredPageText.
+type pdfTextSpan struct {
+	start, end int
+	box        [4]float64
+	color      [3]uint8
+	highlight  bool
+}
+
+// rawText returns the text of each page in content stream order.
+func (d *pdfDocument) rawText() ([]string, bool) {
+	pages, ok := d.rawTextPages()
+	if !ok {
+		return nil, false
+	}
+	texts := make([]string, len(pages))
+	for i, page := range pages {
+		texts[i] = page.text
+	}
+	return texts, true
+}
+
+// rawTextPages returns the text and text positions of each page in content stream order.
+func (d *pdfDocument) rawTextPages() ([]pdfPageText, bool) {
+	if d.encrypted {
+		return nil, false
+	}
+	pages := d.pages()
+	if len(pages) == 0 {
+		return nil, false
+	}
+	texts := make([]pdfPageText, len(pages))
+	fonts := make(map[any]*pdfFontDecoder)
+	hasText := false
+	for i, page := range pages {
+		text, ok := d.pageText(page, fonts)
+		if !ok {
+			return nil, false
+		}
+		texts[i] = text
+		hasText = hasText || text.text != ""
+	}
+	if !hasText && d.layers == nil {
+		// Scanned documents need the native OCR pipeline; with layers selected the
+		// selection may just hold no text.
+		return nil, false
+	}
+	return texts, true
+}
+
+// pageText reads the text of one page, sharing font decoders through fonts. It returns false
+// when a content stream cannot be decoded or uses a font without a Unicode mapping.
+func (d *pdfDocument) pageText(page pdfPage, fonts map[any]*pdfFontDecoder) (pdfPageText, bool) {
+	return d.readPageText(page, &pdfTextWriter{doc: d, fonts: fonts})
+}
+
+// readPageText reads the text of one page with w.
+func (d *pdfDocument) readPageText(page pdfPage, w *pdfTextWriter) (pdfPageText, bool) {
+	var content []byte
+	contents := page.dict["Contents"]
+	streams := d.array(contents)
+	if streams == nil && contents != nil {
+		streams = pdfArray{contents}
+	}
+	for _, value := range streams {
+		stream, ok := d.stream(value)
+		if !ok {
+			continue
+		}
+		decoded, ok := stream.decode()
+		if !ok {
+			return pdfPageText{}, false
+		}
+		content = append(content, decoded...)
+		content = append(content, '\n')
+	}
+
+	if !w.run(content, page.resources, 0) {
+		return pdfPageText{}, false
+	}
+	if w.colors {
+		w.markHighlights()
+	}
+	text := w.text()
+	for j := range text.spans {
+		box := &text.spans[j].box
+		box[0], box[2] = box[0]-page.mediaBox[0], box[2]-page.mediaBox[0]
+		box[1], box[3] = page.mediaBox[3]-box[1], page.mediaBox[3]-box[3]
+	}
+	return text, true
+}
+
+// pdfOperator is a content stream operator; pdfBytes is an undecoded string operand.
+type (
+	pdfOperator string
+	pdfBytes    []byte
+)
+
+// contentToken reads the next operand or operator from a content stream or CMap. Unlike
+// parseValue it keeps strings as raw bytes and does not resolve indirect references.
+func (l *pdfLexer) contentToken() (any, bool) {
+	l.skipSpace()
+	if l.pos >= len(l.data) {
+		return nil, false
+	}
+	switch c := l.data[l.pos]; {
+	case c == '(':
+		return pdfBytes(l.literalStringBytes()), true
+	case c == '<' && l.pos+1 < len(l.data) && l.data[l.pos+1] == '<':
+		start := l.pos
+		if dict, ok := l.parseDict(); ok {
+			return dict, true
+		}
+		if l.err != nil {
+			return nil, false
+		}
+		l.pos = start + 2
+		return nil, true
+	case c == '<':
+		return pdfBytes(l.hexStringBytes()), true
+	case c == '[':
+		if !l.enter() {
+			return nil, false
+		}
+		defer l.leave()
+		l.pos++
+		var array pdfArray
+		for {
+			l.skipSpace()
+			if l.pos >= len(l.data) {
+				return array, true
+			}
+			if l.data[l.pos] == ']' {
+				l.pos++
+				return array, true
+			}
+			value, ok := l.contentToken()
+			if !ok {
+				return array, true
+			}
+			array = append(array, value)
+		}
+	case c == '/':
+		l.pos++
+		return pdfName(decodePDFName(l.token())), true
+	case c == '+' || c == '-' || c == '.' || (c >= '0' && c <= '9'):
+		number, _ := strconv.ParseFloat(l.token(), 64)
+		return number, true
+	default:
+		word := l.token()
+		if word == "" {
+			// Stray delimiter.
+			l.pos++
+			return nil, true
+		}
+		return pdfOperator(word), true
+	}
+}
+
+// skipInlineImage moves past the data of an inline image, which follows the "ID" operator
+// and ends at an "EI" operator.
+func (l *pdfLexer) skipInlineImage() {
+	for i := l.pos + 1; i+1 < len(l.data); i++ {
+		if l.data[i] == 'E' && l.data[i+1] == 'I' && isPDFWhitespace(l.data[i-1]) &&
+			(i+2 == len(l.data) || isPDFWhitespace(l.data[i+2])) {
+			l.pos = i + 2
+			return
+		}
+	}
+	l.pos = len(l.data)
+}
+
+// pdfMatrix is an affine transformation [a b c d e f].
+type pdfMatrix [6]float64
+
+var pdfIdentity = pdfMatrix{1, 0, 0, 1, 0, 0}
+
+// multiply returns m × n, the transformation applying m and then n.
+func (m pdfMatrix) multiply(n pdfMatrix) pdfMatrix {
+	return pdfMatrix{
+		m[0]*n[0] + m[1]*n[2],
+		m[0]*n[1] + m[1]*n[3],
+		m[2]*n[0] + m[3]*n[2],
+		m[2]*n[1] + m[3]*n[3],
+		m[4]*n[0] + m[5]*n[2] + n[4],
+		m[4]*n[1] + m[5]*n[3] + n[5],
+	}
+}
+
+func pdfTranslation(tx, ty float64) pdfMatrix {
+	return pdfMatrix{1, 0, 0, 1, tx, ty}
+}
+
+// pdfTextState is the part of the graphics state that affects text placement.
+type pdfTextState struct {
+	ctm         pdfMatrix
+	font        *pdfFontDecoder
+	fontSize    float64
+	charSpacing float64
+	wordSpacing float64
+	scale       float64
+	leading     float64
+	fill        [3]uint8
+}
+
+// pdfTextWriter collects the text shown by a content stream. Word and line breaks are
+// inferred from the distance between the end of one string and the start of the next.
+type pdfTextWriter struct {
+	doc   *pdfDocument
+	fonts map[any]*pdfFontDecoder
+	out   strings.Builder
+
+	state      pdfTextState
+	stack      []pdfTextState
+	textMatrix pdfMatrix
+	lineMatrix pdfMatrix
+
+	// end is the device position after the last shown string and endSize the font size
+	// there, in device units.
+	end     [2]float64
+	endSize float64
+	shown   bool
+
+	// spans locate the strings written to out, with boxes in device space.
+	spans []pdfTextSpan
+
+	// colors enables collecting the rectangles filled in a color behind text into
+	// highlights, with path holding the rectangles of the path being built.
+	colors     bool
+	path       [][4]float64
+	highlights []pdfHighlight
+
+	// marked records for each open marked-content sequence whether it hides its content
+	// because it belongs to a layer outside doc.layers.
+	marked []bool
+}
+
+// run interprets content with the given resources. It returns false when the text uses a
+// font without a Unicode mapping.
+func (w *pdfTextWriter) run(content []byte, resources pdfDict, depth int) bool {
+	if depth == 0 {
+		w.state = pdfTextState{ctm: pdfIdentity, scale: 1}
+	}
+	lexer := &pdfLexer{data: content}
+	var operands []any
+	for {
+		token, ok := lexer.contentToken()
+		if !ok {
+			return true
+		}
+		op, isOp := token.(pdfOperator)
+		if !isOp {
+			operands = append(operands, token)
+			continue
+		}
+		number := func(i int) float64 {
+			if i < len(operands) {
+				value, _ := operands[i].(float64)
+				return value
+			}
+			return 0
+		}
+		matrix := func() pdfMatrix {
+			var m pdfMatrix
+			for i := range m {
+				m[i] = number(i)
+			}
+			return m
+		}
+
+		switch op {
+		case "q":
+			w.stack = append(w.stack, w.state)
+		case "Q":
+			if len(w.stack) > 0 {
+				w.state = w.stack[len(w.stack)-1]
+				w.stack = w.stack[:len(w.stack)-1]
+			}
+		case "cm":
+			if len(operands) == 6 {
+				w.state.ctm = matrix().multiply(w.state.ctm)
+			}
+		case "BT":
+			w.textMatrix, w.lineMatrix = pdfIdentity, pdfIdentity
+		case "Tf":
+			if len(operands) >= 2 {
+				name, _ := operands[0].(pdfName)
+				font, ok := w.fontDecoder(resources, name)
+				if !ok {
+					return false
+				}
+				w.state.font, w.state.fontSize = font, number(1)
+			}
+		case "Tc":
+			w.state.charSpacing = number(0)
+		case "Tw":
+			w.state.wordSpacing = number(0)
+		case "Tz":
+			w.state.scale = number(0) / 100
+		case "TL":
+			w.state.leading = number(0)
+		case "Td":
+			w.moveLine(number(0), number(1))
+		case "TD":
+			w.state.leading = -number(1)
+			w.moveLine(number(0), number(1))
+		case "Tm":
+			if len(operands) == 6 {
+				w.textMatrix, w.lineMatrix = matrix(), matrix()
+			}
+		case "T*":
+			w.moveLine(0, -w.state.leading)
+		case "Tj":
+			if len(operands) >= 1 {
+				w.show(operands[0])
+			}
+		case "'":
+			w.moveLine(0, -w.state.leading)
+			if len(operands) >= 1 {
+				w.show(operands[0])
+			}
+		case "\"":
+			if len(operands) >= 3 {
+				w.state.wordSpacing, w.state.charSpacing = number(0), number(1)
+				w.moveLine(0, -w.state.leading)
+				w.show(operands[2])
+			}
+		case "TJ":
+			if len(operands) >= 1 {
+				array, _ := operands[0].(pdfArray)
+				for _, item := range array {
+					if adjust, ok := item.(float64); ok {
+						// Adjustments are in thousandths of text space units.
+						w.advance(-adjust / 1000 * w.state.fontSize * w.state.scale)
+						continue
+					}
+					w.show(item)
+				}
+			}
+		case "Do":
+			if len(operands) >= 1 && depth < pdfMaxFormDepth {
+				name, _ := operands[0].(pdfName)
+				if !w.runForm(resources, name, depth) {
+					return false
+				}
+			}
+		case "g", "rg", "k", "sc", "scn":
+			if color, ok := pdfDeviceColor(operands); ok {
+				w.state.fill = color
+			}
+		case "cs":
+			// Selecting a color space resets the color to its initial value, black for the
+			// device color spaces.
+			w.state.fill = [3]uint8{}
+		case "re":
+			if w.colors && len(operands) == 4 {
+				w.path = append(w.path, w.deviceRect(number(0), number(1), number(2), number(3)))
+			}
+		case "f", "F", "f*", "B", "B*", "b", "b*":
+			if w.colors && isHighlightColor(w.state.fill) {
+				for _, rect := range w.path {
+					w.highlights = append(w.highlights, pdfHighlight{box: rect, color: w.state.fill})
+				}
+			}
+			w.path = w.path[:0]
+		case "n", "S", "s":
+			w.path = w.path[:0]
+		case "BDC":
+			hidden := false
+			if len(operands) >= 2 && operands[0] == pdfName("OC") {
+				group := operands[1]
+				if name, ok := group.(pdfName); ok {
+					group = w.doc.dict(resources["Properties"])[string(name)]
+				}
+				hidden = !w.doc.layers.visible(w.doc, group)
+			}
+			w.marked = append(w.marked, hidden)
+		case "BMC":
+			w.marked = append(w.marked, false)
+		case "EMC":
+			if len(w.marked) > 0 {
+				w.marked = w.marked[:len(w.marked)-1]
+			}
+		case "ID":
+			lexer.skipInlineImage()
+		}
+		operands = operands[:0]
+	}
+}
+
+// runForm interprets a form XObject drawn with the "Do" operator.
+func (w *pdfTextWriter) runForm(resources pdfDict, name pdfName, depth int) bool {
+	xobjects := w.doc.dict(resources["XObject"])
+	stream, ok := w.doc.stream(xobjects[string(name)])
+	if !ok || stream.dict.name("Subtype") != "Form" || !w.doc.layers.visible(w.doc, stream.dict["OC"]) {
+		return true
+	}
+	content, ok := stream.decode()
+	if !ok {
+		return true
+	}
+	formResources := w.doc.dict(stream.dict["Resources"])
+	if formResources == nil {
+		formResources = resources
+	}
+
+	saved, textMatrix, lineMatrix := w.state, w.textMatrix, w.lineMatrix
+	if values := w.doc.array(stream.dict["Matrix"]); len(values) == 6 {
+		var m pdfMatrix
+		for i, value := range values {
+			m[i], _ = w.doc.resolve(value).(float64)
+		}
+		w.state.ctm = m.multiply(w.state.ctm)
+	}
+	ok = w.run(content, formResources, depth+1)
+	w.state, w.textMatrix, w.lineMatrix = saved, textMatrix, lineMatrix
+	return ok
+}
+
+func (w *pdfTextWriter) moveLine(tx, ty float64) {
+	w.lineMatrix = pdfTranslation(tx, ty).multiply(w.lineMatrix)
+	w.textMatrix = w.lineMatrix
+}
+
+func (w *pdfTextWriter) advance(tx float64) {
+	w.textMatrix = pdfTranslation(tx, 0).multiply(w.textMatrix)
+}
+
+// device returns the device position of the text origin and the font size in device units.
+func (w *pdfTextWriter) device() ([2]float64, float64) {
+	m := w.textMatrix.multiply(w.state.ctm)
+	size := w.state.fontSize * math.Hypot(m[2], m[3])
+	return [2]float64{m[4], m[5]}, size
+}
+
+func (w *pdfTextWriter) show(value any) {
+	raw, ok := value.(pdfBytes)
+	font := w.state.font
+	if !ok || font == nil {
+		return
+	}
+	text, width, codes, spaces := font.decode(raw)
+	state := w.state
+	shift := (width/1000*state.fontSize + float64(codes)*state.charSpacing + float64(spaces)*state.wordSpacing) * state.scale
+	if w.hidden() {
+		w.advance(shift)
+		return
+	}
+
+	start, size := w.device()
+	if w.shown && text != "" {
+		w.separate(start, size)
+	}
+	w.advance(shift)
+	if text == "" {
+		return
+	}
+	offset := w.out.Len()
+	w.out.WriteString(text)
+	w.end, w.endSize = w.device()
+	w.endSize = max(w.endSize, size)
+	w.shown = true
+
+	// The box spans the baseline from start to end, extended by a typical ascent and
+	// descent of the font size.
+	w.spans = append(w.spans, pdfTextSpan{start: offset, end: w.out.Len(), color: state.fill, box: [4]float64{
+		min(start[0], w.end[0]), max(start[1], w.end[1]) + 0.8*size,
+		max(start[0], w.end[0]), min(start[1], w.end[1]) - 0.2*size,
+	}})
+}
+
+// separate writes a line break when the next string starts on another line and a space
+// when it starts a word gap away from the previous one. Small vertical shifts such as
+// subscripts and kerning gaps add nothing.
+func (w *pdfTextWriter) separate(start [2]float64, size float64) {
+	size = max(size, w.endSize)
+	if size <= 0 {
+		size = 1
+	}
+	dx, dy := start[0]-w.end[0], start[1]-w.end[1]
+	var sep byte
+	switch {
+	case math.Abs(dy) > size/2:
+		sep = '\n'
+	case dx > size*0.15 || dx < -size:
+		sep = ' '
+	default:
+		return
+	}
+
+	text := w.out.String()
+	switch last := text[len(text)-1]; {
+	case last == '\n':
+	case last == ' ' && sep == '\n':
+		w.out.Reset()
+		w.out.WriteString(strings.TrimRight(text, " "))
+		w.out.WriteByte('\n')
+	case last == ' ':
+	default:
+		w.out.WriteByte(sep)
+	}
+}
+
+// text returns the collected text with blank lines and surrounding spaces removed, and the
+// spans moved to match.
+func (w *pdfTextWriter) text() pdfPageText {
+	out := w.out.String()
+	var b strings.Builder
+	var spans []pdfTextSpan
+	next := 0
+	for lineStart := 0; lineStart <= len(out); {
+		lineEnd := strings.IndexByte(out[lineStart:], '\n')
+		if lineEnd < 0 {
+			lineEnd = len(out)
+		} else {
+			lineEnd += lineStart
+		}
+		line := out[lineStart:lineEnd]
+		trimmed := strings.TrimSpace(line)
+		from, shift := lineStart+strings.Index(line, trimmed), 0
+		if trimmed != "" {
+			if b.Len() > 0 {
+				b.WriteByte('\n')
+			}
+			shift = b.Len() - from
+			b.WriteString(trimmed)
+		}
+		for ; next < len(w.spans) && w.spans[next].start < lineEnd; next++ {
+			span := w.spans[next]
+			span.start = max(span.start, from) + shift
+			span.end = min(span.end, from+len(trimmed)) + shift
+			if trimmed != "" && span.start < span.end {
+				spans = append(spans, span)
+			}
+		}
+		lineStart = lineEnd + 1
+	}
+	return pdfPageText{text: b.String(), spans: spans}
+}
+
+// fontDecoder returns the decoder for the font resource name, caching decoders by font object.
+func (w *pdfTextWriter) fontDecoder(resources pdfDict, name pdfName) (*pdfFontDecoder, bool) {
+	ref := w.doc.dict(resources["Font"])[string(name)]
+	key := any(ref)
+	if _, isRef := ref.(pdfRef); !isRef {
+		key = string(name)
+	}
+	if font, ok := w.fonts[key]; ok {
+		return font, font != nil
+	}
+	font := newPDFFontDecoder(w.doc, w.doc.dict(ref))
+	w.fonts[key] = font
+	return font, font != nil
+}
+
+// hidden reports whether the current marked content belongs to a layer that is not
+// selected.
+func (w *pdfTextWriter) hidden() bool {
+	for _, hidden := range w.marked {
+		if hidden {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,142 @@
+package kreuzberg
+
+import (
+	"bytes"
+	"compress/zlib"
+	"fmt"
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+func testPDFStream(content string, compress bool) string {
+	if !compress {
+		return fmt.Sprintf("<< /Length %d >>\nstream\n%s\nendstream", len(content), content)
+	}
+	var packed bytes.Buffer
+	zw := zlib.NewWriter(&packed)
+	_, _ = zw.Write([]byte(content))
+	_ = zw.Close()
+	return fmt.Sprintf("<< /Length %d /Filter /FlateDecode >>\nstream\n%s\nendstream", packed.Len(), packed.String())
+}
+
+const testToUnicodeCMap = `/CIDInit /ProcSet findresource begin
+12 dict begin
+begincmap
+1 begincodespacerange
+<0000> <FFFF>
+endcodespacerange
+1 beginbfrange
+<0001> <0002> <0041>
+endbfrange
+1 beginbfchar
+<0003> <03A9>
+endbfchar
+endcmap`
+
+func testRawTextPDF(type0 string) []byte {
+	return buildTestPDF(
+		`<< /Type /Catalog /Pages 2 0 R >>`,
+		`<< /Type /Pages /Kids [3 0 R 4 0 R] /Count 2 /Resources << /Font << /F1 5 0 R /F2 6 0 R >> /XObject << /X1 9 0 R >> >> >>`,
+		`<< /Type /Page /Parent 2 0 R /Contents 7 0 R >>`,
+		`<< /Type /Page /Parent 2 0 R /Contents [8 0 R] >>`,
+		`<< /Type /Font /Subtype /Type1 /BaseFont /Helvetica /Encoding << /Differences [39 /quoteright] >> >>`,
+		type0,
+		testPDFStream("BT /F1 12 Tf 72 700 Td (Hello) Tj ( world) Tj 0 -14 Td [(Raw)-300(text)] TJ ET\n"+
+			"q 1 0 0 1 0 -100 cm BT /F2 12 Tf 72 700 Td <000100020003> Tj ET Q\n"+
+			"BI /W 1 /H 1 /BPC 8 /CS /G ID \x00Tj EI\n/X1 Do", true),
+		testPDFStream("BT /F1 10 Tf 1 0 0 1 72 700 Tm (It) Tj (') Tj (s) Tj 25 0 Td (a) Tj 5 0 Td (b) Tj ET", false),
+		`<< /Type /XObject /Subtype /Form /BBox [0 0 612 792] /Length 37 >>
+stream
+BT /F1 12 Tf 72 100 Td (Footer) Tj ET
+endstream`,
+		testPDFStream(testToUnicodeCMap, true),
+		`<< /Type /Font /Subtype /CIDFontType2 /DW 600 >>`,
+	)
+}
+
+const testType0Font = `<< /Type /Font /Subtype /Type0 /Encoding /Identity-H /DescendantFonts [11 0 R] /ToUnicode 10 0 R >>`
+
+func TestPDFRawText(t *testing.T) {
+	pages, ok := mustParsePDF(t, testRawTextPDF(testType0Font)).rawText()
+	if !ok {
+		t.Fatal("expected the raw text reader to handle the document")
+	}
+	want := []string{"Hello world\nRaw text\nABΩ\nFooter", "It’s ab"}
+	if len(pages) != len(want) {
+		t.Fatalf("got %d pages, want %d: %q", len(pages), len(want), pages)
+	}
+	for i := range want {
+		if pages[i] != want[i] {
+			t.Errorf("page %d = %q, want %q", i+1, pages[i], want[i])
+		}
+	}
+}
+
+func TestPDFRawTextFallback(t *testing.T) {
+	cases := map[string][]byte{
+		"composite font without ToUnicode": testRawTextPDF(`<< /Type /Font /Subtype /Type0 /Encoding /Identity-H /DescendantFonts [11 0 R] >>`),
+		"no text": buildTestPDF(
+			`<< /Type /Catalog /Pages 2 0 R >>`,
+			`<< /Type /Pages /Kids [3 0 R] /Count 1 >>`,
+			`<< /Type /Page /Parent 2 0 R /Contents 4 0 R >>`,
+			testPDFStream("q 100 0 0 100 0 0 cm /Im1 Do Q", false),
+		),
+		"encrypted": append(testRawTextPDF(testType0Font), []byte("trailer\n<< /Root 1 0 R /Encrypt << /Filter /Standard >> >>\n")...),
+	}
+	for name, data := range cases {
+		t.Run(name, func(t *testing.T) {
+			if _, ok := mustParsePDF(t, data).rawText(); ok {
+				t.Fatal("expected fallback to native extraction")
+			}
+		})
+	}
+}
+
+func TestRawTextExtraction(t *testing.T) {
+	if _, err := ExtractBytesSync([]byte("probe"), "text/plain", nil); err != nil {
+		t.Skipf("native extraction unavailable: %v", err)
+	}
+	config := NewExtractionConfig(WithRawText(true), WithPages(WithExtractPages(true)))
+	result, err := ExtractBytesSync(testRawTextPDF(testType0Font), "application/pdf", config)
+	if err != nil {
+		t.Fatalf("ExtractBytesSync failed: %v", err)
+	}
+	if result.Content != "Hello world\nRaw text\nABΩ\nFooter\n\nIt’s ab" {
+		t.Errorf("unexpected content %q", result.Content)
+	}
+	if result.MimeType != "application/pdf" || result.Metadata.Format.Type != FormatPDF {
+		t.Errorf("unexpected mime type %q or format %q", result.MimeType, result.Metadata.Format.Type)
+	}
+	if count, _ := result.GetPageCount(); count != 2 || len(result.Pages) != 2 || result.Pages[1].Content != "It’s ab" {
+		t.Errorf("unexpected pages: count %d, %+v", count, result.Pages)
+	}
+}
+
+func benchmarkPDFExtraction(b *testing.B, opts ...ExtractionOption) {
+	path := filepath.Join("..", "..", "..", "test_documents", "pdfs", "a_course_in_machine_learning_ciml_v0_9_all.pdf")
+	data, err := os.ReadFile(path)
+	if err != nil {
+		b.Skipf("benchmark fixture not available: %v", err)
+	}
+	config := NewExtractionConfig(append([]ExtractionOption{WithUseCache(false)}, opts...)...)
+	if _, err := ExtractBytesSync(data, "application/pdf", config); err != nil {
+		b.Skipf("native extraction unavailable: %v", err)
+	}
+	b.SetBytes(int64(len(data)))
+	for b.Loop() {
+		if _, err := ExtractBytesSync(data, "application/pdf", config); err != nil {
+			b.Fatal(err)
+		}
+	}
+}
+
+// BenchmarkExtractPDFLayout and BenchmarkExtractPDFRawText compare the native layout
+// extraction with the raw text reader on a large born-digital PDF.
+func BenchmarkExtractPDFLayout(b *testing.B) {
+	benchmarkPDFExtraction(b)
+}
+
+func BenchmarkExtractPDFRawText(b *testing.B) {
+	benchmarkPDFExtraction(b, WithRawText(true))
+}
@@ -0,0 +1,206 @@
+package kreuzberg
+
+import (
+	"bytes"
+	"fmt"
+	"slices"
+	"strconv"
+)
+
+// pdfObject is an indirect object written by pdfWriter: a value produced by
+// pdfLexer.parseValue, a *pdfStream, or raw object syntax given as []byte.
+type pdfObject struct {
+	ref   pdfRef
+	value any
+}
+
+// pdfWriter writes indirect objects and records their offsets for the cross-reference
+// table.
+type pdfWriter struct {
+	buf     bytes.Buffer
+	offsets map[pdfRef]int
+}
+
+func (w *pdfWriter) object(obj pdfObject) {
+	if w.offsets == nil {
+		w.offsets = make(map[pdfRef]int)
+	}
+	w.offsets[obj.ref] = w.buf.Len()
+	fmt.Fprintf(&w.buf, "%d %d obj\n", obj.ref.num, obj.ref.gen)
+	switch v := obj.value.(type) {
+	case []byte:
+		w.buf.Write(v)
+	case *pdfStream:
+		dict := make(pdfDict, len(v.dict)+1)
+		for key, value := range v.dict {
+			dict[key] = value
+		}
+		dict["Length"] = float64(len(v.raw))
+		writePDFValue(&w.buf, dict)
+		w.buf.WriteString("\nstream\n")
+		w.buf.Write(v.raw)
+		w.buf.WriteString("\nendstream")
+	default:
+		writePDFValue(&w.buf, v)
+	}
+	w.buf.WriteString("\nendobj\n")
+}
+
+// xref writes a cross-reference section for the objects written so far, the trailer and
+// the startxref pointer. When full is set the section starts with the free entry of object
+// 0, as the only section of a file must; either way it has one subsection per run of
+// consecutive object numbers.
+func (w *pdfWriter) xref(trailer pdfDict, full bool) {
+	refs := make([]pdfRef, 0, len(w.offsets)+1)
+	if full {
+		refs = append(refs, pdfRef{num: 0, gen: 65535})
+	}
+	for ref := range w.offsets {
+		refs = append(refs, ref)
+	}
+	slices.SortFunc(refs, func(a, b pdfRef) int { return a.num - b.num })
+
+	start := w.buf.Len()
+	w.buf.WriteString("xref\n")
+	for i := 0; i < len(refs); {
+		j := i + 1
+		for j < len(refs) && refs[j].num == refs[j-1].num+1 {
+			j++
+		}
+		fmt.Fprintf(&w.buf, "%d %d\n", refs[i].num, j-i)
+		for _, ref := range refs[i:j] {
+			if ref.num == 0 {
+				w.buf.WriteString("0000000000 65535 f\r\n")
+				continue
+			}
+			fmt.Fprintf(&w.buf, "%010d %05d n\r\n", w.offsets[ref], ref.gen)
+		}
+		i = j
+	}
+	w.buf.WriteString("trailer\n")
+	writePDFValue(&w.buf, trailer)
+	fmt.Fprintf(&w.buf, "\nstartxref\n%d\n%%%%EOF\n", start)
+}
+
+// nextObjectNumber returns the lowest object number not used by doc.
+func (d *pdfDocument) nextObjectNumber() int {
+	size := 1
+	for _, t := range d.trailers {
+		if s, ok := t["Size"].(float64); ok {
+			size = max(size, int(s))
+		}
+	}
+	if size == 1 {
+		// Without a declared size the objects in object streams must be counted too.
+		d.loadAllObjects()
+	}
+	for num := range d.objects {
+		size = max(size, num+1)
+	}
+	return size
+}
+
+// appendPDFUpdate appends an incremental update to data that adds or replaces objects. doc
+// is data parsed, and must have a trailer naming its /Root.
+func appendPDFUpdate(data []byte, doc *pdfDocument, objects []pdfObject) ([]byte, bool) {
+	if len(doc.trailers) == 0 {
+		return nil, false
+	}
+	trailer := doc.trailers[len(doc.trailers)-1]
+	rootRef, ok := trailer["Root"].(pdfRef)
+	if !ok {
+		return nil, false
+	}
+	size := doc.nextObjectNumber()
+	for _, obj := range objects {
+		size = max(size, obj.ref.num+1)
+	}
+
+	w := &pdfWriter{}
+	w.buf.Grow(len(data) + 1024)
+	w.buf.Write(data)
+	if !bytes.HasSuffix(data, []byte("\n")) {
+		w.buf.WriteByte('\n')
+	}
+	for _, obj := range objects {
+		w.object(obj)
+	}
+	update := pdfDict{"Size": float64(size), "Root": rootRef}
+	if info, ok := trailer["Info"].(pdfRef); ok {
+		update["Info"] = info
+	}
+	if prev, ok := lastStartXref(data); ok {
+		update["Prev"] = float64(prev)
+	}
+	w.xref(update, false)
+	return w.buf.Bytes(), true
+}
+
+// lastStartXref returns the cross-reference offset named by the last startxref of data.
+func lastStartXref(data []byte) (int, bool) {
+	index := bytes.LastIndex(data, []byte("startxref"))
+	if index < 0 {
+		return 0, false
+	}
+	lexer := &pdfLexer{data: data, pos: index + len("startxref")}
+	lexer.skipSpace()
+	offset, err := strconv.Atoi(lexer.token())
+	return offset, err == nil && offset >= 0
+}
+
+// writePDFValue serializes a value produced by pdfLexer.parseValue.
+func writePDFValue(buf *bytes.Buffer, value any) {
+	switch v := value.(type) {
+	case pdfName:
+		buf.WriteByte('/')
+		for _, c := range []byte(v) {
+			if c <= ' ' || c >= 0x7f || c == '#' || isPDFDelimiter(c) {
+				fmt.Fprintf(buf, "#%02X", c)
+			} else {
+				buf.WriteByte(c)
+			}
+		}
+	case pdfRef:
+		fmt.Fprintf(buf, "%d %d R", v.num, v.gen)
+	case float64:
+		buf.WriteString(strconv.FormatFloat(v, 'f', -1, 64))
+	case bool:
+		buf.WriteString(strconv.FormatBool(v))
+	case string:
+		// Strings were decoded to text when parsed; write them back as UTF-16BE.
+		buf.WriteString("<FEFF")
+		for _, r := range v {
+			if r > 0xFFFF {
+				r -= 0x10000
+				fmt.Fprintf(buf, "%04X%04X", 0xD800+(r>>10), 0xDC00+(r&0x3FF))
+				continue
+			}
+			fmt.Fprintf(buf, "%04X", r)
+		}
+		buf.WriteByte('>')
+	case pdfArray:
+		buf.WriteByte('[')
+		for i, item := range v {
+			if i > 0 {
+				buf.WriteByte(' ')
+			}
+			writePDFValue(buf, item)
+		}
+		buf.WriteByte(']')
+	case pdfDict:
+		keys := make([]string, 0, len(v))
+		for key := range v {
+			keys = append(keys, key)
+		}
+		slices.Sort(keys)
+		buf.WriteString("<<")
+		for _, key := range keys {
+			writePDFValue(buf, pdfName(key))
+			buf.WriteByte(' ')
+			writePDFValue(buf, v[key])
+		}
+		buf.WriteString(">>")
+	default:
+		buf.WriteString("null")
+	}
+}
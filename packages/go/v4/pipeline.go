@@ -0,0 +1,143 @@
+package kreuzberg
+
+import (
+	"encoding/json"
+	"strings"
+)
+
+// Extractors reported in PipelineInfo.Extractor, besides the OCR backend names used for
+// images.
+const (
+	ExtractorPDFium      = "pdfium"
+	ExtractorRawText     = "raw_text"
+	ExtractorGo          = "go"
+	ExtractorOffice      = "office"
+	ExtractorSpreadsheet = "spreadsheet"
+	ExtractorHTML        = "html"
+	ExtractorEmail       = "email"
+	ExtractorArchive     = "archive"
+	ExtractorXML         = "xml"
+	ExtractorText        = "text"
+	ExtractorImage       = "image"
+)
+
+// Steps reported in PipelineInfo.Steps, in the order they run.
+const (
+	PipelineStepParse       = "parse"
+	PipelineStepOCR         = "ocr"
+	PipelineStepChunk       = "chunk"
+	PipelineStepPostprocess = "postprocess"
+)
+
+// PipelineInfo describes how a result was produced, for telling why two similar documents
+// extracted differently.
+type PipelineInfo struct {
+	// Extractor is the component that read the document, such as ExtractorPDFium, or the OCR
+	// backend (for example "tesseract") for images that were OCR'd.
+	Extractor string `json:"extractor"`
+	// Steps lists the stages that ran, from PipelineStepParse to PipelineStepPostprocess.
+	Steps []string `json:"steps"`
+	// OCRBackend is the OCR backend that ran, or "" when the document was not OCR'd.
+	OCRBackend string `json:"ocr_backend,omitempty"`
+}
+
+// applyPipeline fills result.Pipeline, result.OCRUsed and result.OCRPages. Results from the
+// raw PDF text reader and registered Go extractors arrive with the extractor set; for the
+// native library it is told by the MIME type. OCR counts as run when the result says so,
+// whatever config asked for, and OCRPages lists the pages the OCR read.
+func applyPipeline(result *ExtractionResult, config *ExtractionConfig) {
+	pipeline := result.Pipeline
+	if pipeline == nil {
+		pipeline = &PipelineInfo{}
+		result.Pipeline = pipeline
+	}
+	mimeType := normalizeMimeType(result.MimeType)
+	ocr := ocrRan(result)
+	if pipeline.Extractor == "" {
+		pipeline.Extractor = nativeExtractor(mimeType)
+		if ocr && strings.HasPrefix(mimeType, "image/") {
+			pipeline.Extractor = ocrBackendName(config.OCR)
+		}
+	}
+
+	pipeline.Steps = append(pipeline.Steps[:0], PipelineStepParse)
+	result.OCRPages = result.OCRPages[:0]
+	if ocr {
+		pipeline.OCRBackend = ocrBackendName(config.OCR)
+		pipeline.Steps = append(pipeline.Steps, PipelineStepOCR)
+		result.OCRUsed = true
+		result.OCRPages = appendOCRPages(result.OCRPages, result)
+	}
+	delete(result.Metadata.Additional, "ocr_pages")
+	if len(result.OCRPages) == 0 {
+		result.OCRPages = nil
+	}
+	if len(result.Chunks) > 0 || (config.Chunking != nil && (config.Chunking.Enabled == nil || *config.Chunking.Enabled)) {
+		pipeline.Steps = append(pipeline.Steps, PipelineStepChunk)
+	}
+	pipeline.Steps = append(pipeline.Steps, PipelineStepPostprocess)
+}
+
+// ocrRan reports whether the document of result went through OCR: the native library, and
+// the Go binding when it OCRs pages one at a time, list the pages they OCR'd under
+// "ocr_pages" in the metadata, and native OCR results also carry OCR or image
+// preprocessing metadata.
+func ocrRan(result *ExtractionResult) bool {
+	_, ok := result.Metadata.Additional["ocr_pages"]
+	return ok || result.Metadata.Format.Type == FormatOCR || result.Metadata.ImagePreprocessing != nil
+}
+
+// setOCRPages lists pages under "ocr_pages" in the metadata of result, as the native library
+// does for the pages it OCRs.
+func setOCRPages(result *ExtractionResult, pages []int) {
+	if pages == nil {
+		pages = []int{}
+	}
+	data, _ := json.Marshal(pages)
+	if result.Metadata.Additional == nil {
+		result.Metadata.Additional = make(map[string]json.RawMessage)
+	}
+	result.Metadata.Additional["ocr_pages"] = data
+}
+
+// appendOCRPages appends to pages the numbers of the pages listed under "ocr_pages" in the
+// metadata of result, numbered as in the original document when pages were sampled.
+func appendOCRPages(pages []int, result *ExtractionResult) []int {
+	var listed []int
+	if raw, ok := result.Metadata.Additional["ocr_pages"]; !ok || json.Unmarshal(raw, &listed) != nil {
+		return pages
+	}
+	for _, n := range listed {
+		if n >= 1 && n <= len(result.SampledPages) {
+			n = result.SampledPages[n-1]
+		}
+		pages = append(pages, n)
+	}
+	return pages
+}
+
+// nativeExtractor returns the native extractor that handles mimeType.
+func nativeExtractor(mimeType string) string {
+	switch {
+	case isPDFMimeType(mimeType):
+		return ExtractorPDFium
+	case strings.HasPrefix(mimeType, "image/"):
+		return ExtractorImage
+	case isDelimitedMimeType(mimeType), strings.Contains(mimeType, "spreadsheet"), strings.Contains(mimeType, "excel"):
+		return ExtractorSpreadsheet
+	case strings.Contains(mimeType, "html"):
+		return ExtractorHTML
+	case mimeType == "message/rfc822", strings.Contains(mimeType, "outlook"):
+		return ExtractorEmail
+	case strings.Contains(mimeType, "epub"):
+		return ExtractorOffice
+	case strings.Contains(mimeType, "zip"), strings.Contains(mimeType, "tar"), strings.Contains(mimeType, "7z"), strings.Contains(mimeType, "gzip"):
+		return ExtractorArchive
+	case strings.HasSuffix(mimeType, "xml") && !strings.Contains(mimeType, "officedocument"):
+		return ExtractorXML
+	case strings.HasPrefix(mimeType, "application/vnd."), strings.Contains(mimeType, "msword"), mimeType == "application/rtf":
+		return ExtractorOffice
+	default:
+		return ExtractorText
+	}
+}
@@ -0,0 +1,154 @@
+package kreuzberg
+
+import (
+	"slices"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestApplyPipeline(t *testing.T) {
+	tests := []struct {
+		name      string
+		result    *ExtractionResult
+		config    *ExtractionConfig
+		extractor string
+		steps     []string
+		backend   string
+	}{
+		{
+			name:      "pdf",
+			result:    &ExtractionResult{MimeType: "application/pdf"},
+			config:    NewExtractionConfig(),
+			extractor: ExtractorPDFium,
+			steps:     []string{PipelineStepParse, PipelineStepPostprocess},
+		},
+		{
+			name:      "ocr image",
+			result:    &ExtractionResult{MimeType: "image/png", Metadata: ocrPagesMetadata(1)},
+			config:    NewExtractionConfig(WithOCR(WithOCRBackend("tesseract"))),
+			extractor: "tesseract",
+			steps:     []string{PipelineStepParse, PipelineStepOCR, PipelineStepPostprocess},
+			backend:   "tesseract",
+		},
+		{
+			name:      "chunked docx",
+			result:    &ExtractionResult{MimeType: "application/vnd.openxmlformats-officedocument.wordprocessingml.document", Chunks: []Chunk{{Content: "a"}}},
+			config:    NewExtractionConfig(),
+			extractor: ExtractorOffice,
+			steps:     []string{PipelineStepParse, PipelineStepChunk, PipelineStepPostprocess},
+		},
+		{
+			name:      "raw text pdf with OCR config",
+			result:    &ExtractionResult{MimeType: "application/pdf", Pipeline: &PipelineInfo{Extractor: ExtractorRawText}},
+			config:    NewExtractionConfig(WithOCR(), WithForceOCR(true)),
+			extractor: ExtractorRawText,
+			steps:     []string{PipelineStepParse, PipelineStepPostprocess},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			applyPipeline(tt.result, tt.config)
+			p := tt.result.Pipeline
+			if p == nil || p.Extractor != tt.extractor || !slices.Equal(p.Steps, tt.steps) || p.OCRBackend != tt.backend {
+				t.Fatalf("unexpected pipeline %+v", p)
+			}
+		})
+	}
+}
+
+func TestOCRUsage(t *testing.T) {
+	tests := []struct {
+		name   string
+		result *ExtractionResult
+		config *ExtractionConfig
+		used   bool
+		pages  []int
+	}{
+		{
+			name:   "pdf with text layer",
+			result: &ExtractionResult{MimeType: "application/pdf", Metadata: Metadata{PageStructure: &PageStructure{TotalCount: 3}}},
+			config: NewExtractionConfig(WithOCR()),
+		},
+		{
+			name:   "forced pdf",
+			result: &ExtractionResult{MimeType: "application/pdf", Metadata: Metadata{PageStructure: &PageStructure{TotalCount: 3}, Additional: ocrPagesMetadata(1, 2, 3).Additional}},
+			config: NewExtractionConfig(WithOCR(), WithForceOCR(true)),
+			used:   true,
+			pages:  []int{1, 2, 3},
+		},
+		{
+			name:   "sampled scan",
+			result: &ExtractionResult{MimeType: "application/pdf", SampledPages: []int{2, 5}, Metadata: ocrPagesMetadata(1, 2)},
+			config: NewExtractionConfig(WithOCR()),
+			used:   true,
+			pages:  []int{2, 5},
+		},
+		{
+			name:   "pages OCR'd one at a time",
+			result: &ExtractionResult{MimeType: "application/pdf", Metadata: Metadata{PageStructure: &PageStructure{TotalCount: 3}, Additional: ocrPagesMetadata(1, 3).Additional}},
+			config: NewExtractionConfig(WithOCR(WithOCRPageTimeout(time.Second))),
+			used:   true,
+			pages:  []int{1, 3},
+		},
+		{
+			name:   "OCR metadata without pages",
+			result: &ExtractionResult{MimeType: "application/pdf", Metadata: Metadata{Format: FormatMetadata{Type: FormatOCR}}},
+			config: NewExtractionConfig(WithOCR()),
+			used:   true,
+		},
+		{
+			name:   "image",
+			result: &ExtractionResult{MimeType: "image/png", Metadata: ocrPagesMetadata(1)},
+			config: NewExtractionConfig(WithOCR()),
+			used:   true,
+			pages:  []int{1},
+		},
+		{
+			name:   "image not OCR'd",
+			result: &ExtractionResult{MimeType: "image/png"},
+			config: NewExtractionConfig(WithOCR()),
+		},
+		{
+			name:   "OCR without OCR config",
+			result: &ExtractionResult{MimeType: "image/png", Metadata: ocrPagesMetadata(1)},
+			config: NewExtractionConfig(),
+			used:   true,
+			pages:  []int{1},
+		},
+		{
+			name:   "embedded images only",
+			result: &ExtractionResult{MimeType: "application/pdf", OCRUsed: true},
+			config: NewExtractionConfig(),
+			used:   true,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			applyPipeline(tt.result, tt.config)
+			if tt.result.OCRUsed != tt.used || !slices.Equal(tt.result.OCRPages, tt.pages) {
+				t.Fatalf("OCRUsed = %v, OCRPages = %v; want %v, %v", tt.result.OCRUsed, tt.result.OCRPages, tt.used, tt.pages)
+			}
+		})
+	}
+}
+
+// ocrPagesMetadata returns metadata listing pages under "ocr_pages", as the native library
+// reports the pages it OCR'd.
+func ocrPagesMetadata(pages ...int) Metadata {
+	var result ExtractionResult
+	setOCRPages(&result, pages)
+	return result.Metadata
+}
+
+func TestPipelineInResultJSON(t *testing.T) {
+	result := &ExtractionResult{MimeType: "text/plain", Success: true}
+	applyPipeline(result, NewExtractionConfig())
+	data, err := ResultToJSON(result)
+	if err != nil {
+		t.Fatalf("ResultToJSON failed: %v", err)
+	}
+	if !strings.Contains(data, `"pipeline":{"extractor":"text","steps":["parse","postprocess"]}`) {
+		t.Fatalf("pipeline missing from %s", data)
+	}
+}
@@ -0,0 +1,95 @@
+package kreuzberg
+
+import "sync"
+
+// Reset clears every field of r so that it can be reused, for example through a
+// ResultPool. Slices are truncated to zero length and maps are emptied, keeping their
+// backing storage for the next use; the elements of every slice are zeroed first so that
+// nothing of the previous document stays reachable through it and its data can be garbage
+// collected. Other slices, such as SourceBytes, are dropped.
+//
+// Reset invalidates anything obtained from r before the call: slices such as Tables,
+// Images, Chunks and Pages (and the structs inside them) will be overwritten when r is
+// reused. Copy out what you need, including strings taken from those elements, before
+// calling Reset.
+func (r *ExtractionResult) Reset() {
+	if r == nil {
+		return
+	}
+	clear(r.Tables)
+	clear(r.DetectedLanguages)
+	clear(r.Chunks)
+	clear(r.Images)
+	clear(r.PageImages)
+	clear(r.Pages)
+	clear(r.Annotations)
+	clear(r.Revisions)
+	clear(r.Signatures)
+	clear(r.ColoredSpans)
+	clear(r.Slides)
+	clear(r.Layers)
+	clear(r.PageHashes)
+	clear(r.Headers)
+	clear(r.Footers)
+	clear(r.Warnings)
+	clear(r.PartBoundaries)
+	clear(r.Barcodes)
+	clear(r.Blocks)
+	clear(r.SampledPages)
+	clear(r.OCRPages)
+	clear(r.OffsetMap)
+	clear(r.PageRotations)
+	clear(r.UserData)
+	clear(r.Metadata.Additional)
+	*r = ExtractionResult{
+		Metadata:          Metadata{Additional: r.Metadata.Additional},
+		Tables:            r.Tables[:0],
+		DetectedLanguages: r.DetectedLanguages[:0],
+		Chunks:            r.Chunks[:0],
+		Images:            r.Images[:0],
+		PageImages:        r.PageImages[:0],
+		Pages:             r.Pages[:0],
+		Annotations:       r.Annotations[:0],
+		Revisions:         r.Revisions[:0],
+		Signatures:        r.Signatures[:0],
+		ColoredSpans:      r.ColoredSpans[:0],
+		Slides:            r.Slides[:0],
+		Layers:            r.Layers[:0],
+		PageHashes:        r.PageHashes[:0],
+		Headers:           r.Headers[:0],
+		Footers:           r.Footers[:0],
+		Warnings:          r.Warnings[:0],
+		PartBoundaries:    r.PartBoundaries[:0],
+		Barcodes:          r.Barcodes[:0],
+		Blocks:            r.Blocks[:0],
+		SampledPages:      r.SampledPages[:0],
+		PageRotations:     r.PageRotations,
+		OCRPages:          r.OCRPages[:0],
+		OffsetMap:         r.OffsetMap[:0],
+		UserData:          r.UserData,
+	}
+}
+
+// ResultPool is a sync.Pool of *ExtractionResult values for services that build or decode
+// many results and want to reduce allocations. The zero value is ready to use.
+type ResultPool struct {
+	pool sync.Pool
+}
+
+// Get returns a reset result from the pool, or a new one when the pool is empty.
+func (p *ResultPool) Get() *ExtractionResult {
+	if r, ok := p.pool.Get().(*ExtractionResult); ok {
+		return r
+	}
+	return &ExtractionResult{}
+}
+
+// Put resets r and returns it to the pool. r must not be used after Put; see Reset for the
+// references that become invalid.
+func (p *ResultPool) Put(r *ExtractionResult) {
+	if r == nil {
+		return
+	}
+	r.Reset()
+	p.pool.Put(r)
+}
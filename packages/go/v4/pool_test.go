@@ -0,0 +1,177 @@
+package kreuzberg
+
+import (
+	"encoding/json"
+	"reflect"
+	"testing"
+)
+
+func testPoolResult() *ExtractionResult {
+	lang := "en"
+	return &ExtractionResult{
+		Content:           "content",
+		MimeType:          "text/plain",
+		Metadata:          Metadata{Language: &lang, Additional: map[string]json.RawMessage{"k": json.RawMessage(`1`)}},
+		Tables:            []Table{{Markdown: "| a |"}},
+		DetectedLanguages: []string{"en"},
+		Chunks:            []Chunk{{Content: "chunk"}},
+		Images:            []ExtractedImage{{Data: []byte{1, 2, 3}}},
+		Pages:             []PageContent{{PageNumber: 1}},
+		Warnings:          []ExtractionWarning{{Code: "w"}},
+		PartBoundaries:    []int{0, 10},
+		Success:           true,
+	}
+}
+
+func TestResultReset(t *testing.T) {
+	result := testPoolResult()
+	tables := result.Tables
+	result.Reset()
+
+	empty := ExtractionResult{
+		Metadata:          Metadata{Additional: map[string]json.RawMessage{}},
+		Tables:            []Table{},
+		DetectedLanguages: []string{},
+		Chunks:            []Chunk{},
+		Images:            []ExtractedImage{},
+		Pages:             []PageContent{},
+		Warnings:          []ExtractionWarning{},
+		PartBoundaries:    []int{},
+	}
+	if !reflect.DeepEqual(*result, empty) {
+		t.Fatalf("result not reset: %+v", *result)
+	}
+	if cap(result.Tables) != 1 || tables[0].Markdown != "" {
+		t.Fatal("expected table storage to be cleared and kept for reuse")
+	}
+
+	var nilResult *ExtractionResult
+	nilResult.Reset()
+}
+
+// nonZeroValue returns a value of typ that is not its zero value.
+func nonZeroValue(typ reflect.Type) reflect.Value {
+	v := reflect.New(typ).Elem()
+	switch typ.Kind() {
+	case reflect.String:
+		v.SetString("x")
+	case reflect.Bool:
+		v.SetBool(true)
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64:
+		v.SetInt(1)
+	case reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64:
+		v.SetUint(1)
+	case reflect.Float32, reflect.Float64:
+		v.SetFloat(1)
+	case reflect.Slice:
+		v.Set(reflect.MakeSlice(typ, 1, 1))
+	case reflect.Map:
+		v.Set(reflect.MakeMap(typ))
+	case reflect.Pointer:
+		v.Set(reflect.New(typ.Elem()))
+	case reflect.Interface:
+		v.Set(reflect.ValueOf("x"))
+	case reflect.Array:
+		v.Index(0).Set(nonZeroValue(typ.Elem()))
+	case reflect.Struct:
+		for i := 0; i < v.NumField(); i++ {
+			if v.Field(i).CanSet() {
+				v.Field(i).Set(nonZeroValue(typ.Field(i).Type))
+				break
+			}
+		}
+	}
+	return v
+}
+
+func TestResultResetDropsStaleElements(t *testing.T) {
+	var result ExtractionResult
+	fields := reflect.ValueOf(&result).Elem()
+	for i := 0; i < fields.NumField(); i++ {
+		field := fields.Field(i)
+		switch {
+		case !field.CanSet():
+		case field.Kind() == reflect.Slice:
+			slice := reflect.MakeSlice(field.Type(), 2, 2)
+			slice.Index(0).Set(nonZeroValue(field.Type().Elem()))
+			slice.Index(1).Set(nonZeroValue(field.Type().Elem()))
+			field.Set(slice)
+		case field.Kind() == reflect.Map:
+			m := reflect.MakeMap(field.Type())
+			m.SetMapIndex(nonZeroValue(field.Type().Key()), nonZeroValue(field.Type().Elem()))
+			field.Set(m)
+		}
+	}
+	result.Metadata.Additional = map[string]json.RawMessage{"k": json.RawMessage(`1`)}
+	result.textPositions = []textPosition{{}}
+	result.Reset()
+
+	for i := 0; i < fields.NumField(); i++ {
+		field, name := fields.Field(i), fields.Type().Field(i).Name
+		switch field.Kind() {
+		case reflect.Slice:
+			if field.Len() != 0 {
+				t.Errorf("%s has %d elements after Reset", name, field.Len())
+			}
+			// Elements past the length stay reachable by reslicing up to the capacity.
+			for j := 0; j < field.Cap(); j++ {
+				if !field.Slice(0, field.Cap()).Index(j).IsZero() {
+					t.Errorf("%s keeps element %d in its backing storage", name, j)
+				}
+			}
+		case reflect.Map:
+			if field.Len() != 0 {
+				t.Errorf("%s has %d entries after Reset", name, field.Len())
+			}
+		}
+	}
+	if len(result.Metadata.Additional) != 0 {
+		t.Errorf("Metadata.Additional has %d entries after Reset", len(result.Metadata.Additional))
+	}
+}
+
+func TestResultPool(t *testing.T) {
+	var pool ResultPool
+	result := pool.Get()
+	if result == nil {
+		t.Fatal("Get returned nil")
+	}
+	*result = *testPoolResult()
+	pool.Put(result)
+	pool.Put(nil)
+
+	reused := pool.Get()
+	if reused.Content != "" || len(reused.Tables) != 0 || reused.Success {
+		t.Fatalf("pooled result was not reset: %+v", reused)
+	}
+}
+
+func BenchmarkResultPoolDecode(b *testing.B) {
+	data, err := json.Marshal(testPoolResult())
+	if err != nil {
+		b.Fatal(err)
+	}
+	var pool ResultPool
+	b.ReportAllocs()
+	for b.Loop() {
+		result := pool.Get()
+		if err := json.Unmarshal(data, result); err != nil {
+			b.Fatal(err)
+		}
+		pool.Put(result)
+	}
+}
+
+func BenchmarkResultDecode(b *testing.B) {
+	data, err := json.Marshal(testPoolResult())
+	if err != nil {
+		b.Fatal(err)
+	}
+	b.ReportAllocs()
+	for b.Loop() {
+		var result ExtractionResult
+		if err := json.Unmarshal(data, &result); err != nil {
+			b.Fatal(err)
+		}
+	}
+}
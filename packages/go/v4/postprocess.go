@@ -0,0 +1,148 @@
+package kreuzberg
+
+import (
+	"context"
+	"os"
+)
+
+// documentSource identifies the input of an extraction so Go-side stages can inspect the
+// original document. Files are only read when a stage asks for their bytes.
+type documentSource struct {
+	path string
+	data []byte
+	pdf  *pdfDocument
+}
+
+func (s *documentSource) bytes() ([]byte, error) {
+	if s == nil {
+		return nil, nil
+	}
+	if s.data == nil && s.path != "" {
+		// #nosec G304 -- path is the document the caller asked us to extract
+		data, err := os.ReadFile(s.path)
+		if err != nil {
+			return nil, newIOErrorWithContext("failed to read source document", err, ErrorCodeIo, nil)
+		}
+		s.data = data
+	}
+	return s.data, nil
+}
+
+// pdfDocument parses the source as a PDF once and shares the parsed structure between
+// stages.
+func (s *documentSource) pdfDocument() (*pdfDocument, error) {
+	if s == nil {
+		return parsePDFDocument(nil)
+	}
+	if s.pdf == nil {
+		data, err := s.bytes()
+		if err != nil {
+			return nil, err
+		}
+		doc, err := parsePDFDocument(data)
+		if err != nil {
+			return nil, newParsingErrorWithContext("failed to read PDF structure", err, ErrorCodeParsing, nil)
+		}
+		s.pdf = doc
+	}
+	return s.pdf, nil
+}
+
+// postProcessResult applies the Go-side stages configured on config to a result returned by
+// the native library. It runs after the FFI mutex has been released, so stages are free to
+// call back into the native library.
+func postProcessResult(ctx context.Context, result *ExtractionResult, config *ExtractionConfig, src *documentSource) error {
+	if result == nil {
+		return nil
+	}
+	if config == nil {
+		config = &ExtractionConfig{}
+	}
+
+	if err := applyTIFFPages(ctx, result, config, src); err != nil {
+		return err
+	}
+	if err := applyReadingOrder(ctx, result, config, src); err != nil {
+		return err
+	}
+	if err := applyPageSizes(result, config, src); err != nil {
+		return err
+	}
+	applyOCRTextPositions(result, config)
+	applyPageRotations(result)
+	if err := applySheetSelection(result, config); err != nil {
+		return err
+	}
+	if err := applyCSVTable(result, config, src); err != nil {
+		return err
+	}
+	applyBidi(result, config)
+	applyCellWhitespace(result, config)
+	if err := applyTableMerges(result, config, src); err != nil {
+		return err
+	}
+	applyTableLimits(result, config)
+	applyTableNumbers(result, config)
+	applyHeadersFooters(result, config)
+	applyChunkOverlapStrategy(result, config)
+	applyChunkSectionTitles(result, config)
+	if err := applyNestedArchives(ctx, result, config, src); err != nil {
+		return err
+	}
+	applyPartSeparator(result, config)
+	if err := applyImageOCR(ctx, result, config); err != nil {
+		return err
+	}
+	applyImageColors(result, config)
+	applyColorProfiles(result, config)
+	if err := applyImageCaptions(ctx, result, config); err != nil {
+		return err
+	}
+	if err := applyRenderPages(ctx, result, config, src); err != nil {
+		return err
+	}
+	if err := applyHOCR(ctx, result, config, src); err != nil {
+		return err
+	}
+	if err := applyBarcodes(result, config, src); err != nil {
+		return err
+	}
+	if err := applyAnnotations(result, config, src); err != nil {
+		return err
+	}
+	if err := applyFormFieldsInContent(ctx, result, config, src); err != nil {
+		return err
+	}
+	if err := applyRevisions(result, config, src); err != nil {
+		return err
+	}
+	if err := applySignatures(result, config, src); err != nil {
+		return err
+	}
+	if err := applySlides(result, config, src); err != nil {
+		return err
+	}
+	if err := applyLayers(result, config, src); err != nil {
+		return err
+	}
+	if err := applyRetainSource(result, config, src); err != nil {
+		return err
+	}
+	applyWhitespaceNormalization(result, config)
+	applyOffsetMap(result, config)
+	applyFilters(result, config)
+	// Blocks and colored spans describe the final content, so they are built after the
+	// filters.
+	applyBlocks(result, config)
+	if err := applyTextColors(result, config, src); err != nil {
+		return err
+	}
+	applyConfidence(result)
+	applySampledPageNumbers(result)
+	applyStableChunkIDs(result, config)
+	applyContextFields(result, config)
+	applyPipeline(result, config)
+	logResult(ctx, result, config)
+
+	return checkEmptyContent(result, config)
+}
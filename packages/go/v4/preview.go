@@ -0,0 +1,94 @@
+package kreuzberg
+
+import (
+	"strings"
+	"unicode"
+)
+
+// ellipsis marks text removed by Preview and Snippet.
+const ellipsis = "…"
+
+// Preview returns at most maxRunes runes from the start of Content without splitting a
+// multi-byte character. When the content is longer it is cut back to the last word
+// boundary and an ellipsis (not counted in maxRunes) is appended.
+func (r *ExtractionResult) Preview(maxRunes int) string {
+	if r == nil || maxRunes <= 0 {
+		return ""
+	}
+	content := strings.TrimSpace(r.Content)
+	runes := []rune(content)
+	if len(runes) <= maxRunes {
+		return content
+	}
+
+	cut := maxRunes
+	if !unicode.IsSpace(runes[cut]) {
+		for i := cut - 1; i > 0; i-- {
+			if unicode.IsSpace(runes[i]) {
+				cut = i
+				break
+			}
+		}
+	}
+	return strings.TrimRightFunc(string(runes[:cut]), unicode.IsSpace) + ellipsis
+}
+
+// Snippet returns the text surrounding the first case-insensitive occurrence of around in
+// Content, including up to radius runes on either side, widened or narrowed so that it
+// does not start or end mid-word. An ellipsis marks each truncated end. The boolean is
+// false when around does not occur in Content.
+func (r *ExtractionResult) Snippet(around string, radius int) (string, bool) {
+	if r == nil || around == "" {
+		return "", false
+	}
+	if radius < 0 {
+		radius = 0
+	}
+
+	runes := []rune(r.Content)
+	match := indexFoldRunes(runes, []rune(around))
+	if match < 0 {
+		return "", false
+	}
+	matchEnd := match + len([]rune(around))
+
+	start := max(match-radius, 0)
+	if start > 0 && !unicode.IsSpace(runes[start-1]) {
+		for start < match && !unicode.IsSpace(runes[start-1]) {
+			start++
+		}
+	}
+	end := min(matchEnd+radius, len(runes))
+	if end < len(runes) && !unicode.IsSpace(runes[end]) {
+		for end > matchEnd && !unicode.IsSpace(runes[end]) {
+			end--
+		}
+	}
+
+	snippet := strings.TrimSpace(string(runes[start:end]))
+	if start > 0 {
+		snippet = ellipsis + snippet
+	}
+	if end < len(runes) {
+		snippet += ellipsis
+	}
+	return snippet, true
+}
+
+// indexFoldRunes returns the rune index of the first case-insensitive occurrence of needle
+// in haystack, or -1.
+func indexFoldRunes(haystack, needle []rune) int {
+	for i := 0; i+len(needle) <= len(haystack); i++ {
+		matched := true
+		for j, r := range needle {
+			if unicode.ToLower(haystack[i+j]) != unicode.ToLower(r) {
+				matched = false
+				break
+			}
+		}
+		if matched {
+			return i
+		}
+	}
+	return -1
+}
@@ -0,0 +1,45 @@
+package kreuzberg
+
+import (
+	"strings"
+	"testing"
+	"unicode/utf8"
+)
+
+func TestPreview(t *testing.T) {
+	result := &ExtractionResult{Content: "Grüße aus München, schöne Stadt"}
+
+	if got := result.Preview(100); got != result.Content {
+		t.Errorf("short content should be returned unchanged, got %q", got)
+	}
+	if got := result.Preview(12); got != "Grüße aus…" {
+		t.Errorf("expected preview cut at word boundary, got %q", got)
+	}
+	for n := 1; n < utf8.RuneCountInString(result.Content); n++ {
+		if got := result.Preview(n); !utf8.ValidString(got) {
+			t.Fatalf("Preview(%d) produced invalid UTF-8: %q", n, got)
+		}
+	}
+	if got := result.Preview(0); got != "" {
+		t.Errorf("expected empty preview, got %q", got)
+	}
+}
+
+func TestSnippet(t *testing.T) {
+	result := &ExtractionResult{Content: "The quarterly report shows that Revenue grew by twelve percent in Q3."}
+
+	snippet, ok := result.Snippet("revenue", 12)
+	if !ok {
+		t.Fatal("expected to find query term")
+	}
+	if snippet != "…shows that Revenue grew by…" {
+		t.Errorf("unexpected snippet %q", snippet)
+	}
+
+	if snippet, ok := result.Snippet("The", 3); !ok || !strings.HasPrefix(snippet, "The") {
+		t.Errorf("snippet at start should not have a leading ellipsis, got %q", snippet)
+	}
+	if _, ok := result.Snippet("missing", 10); ok {
+		t.Error("expected no match")
+	}
+}
@@ -0,0 +1,188 @@
+package kreuzberg
+
+import (
+	"cmp"
+	"context"
+	"slices"
+	"strings"
+)
+
+const (
+	// pdfColumnSplitGap is the horizontal gap, in line heights, that separates text on one
+	// line into pieces in different columns.
+	pdfColumnSplitGap = 1.5
+	// pdfColumnMinGutter is the narrowest gap between columns, in line heights.
+	pdfColumnMinGutter = 1.0
+	// pdfColumnMaxWidth is the widest a line may be, as a share of the text width, to count
+	// towards finding columns; wider lines span several columns.
+	pdfColumnMaxWidth = 0.6
+)
+
+// readingOrderColumns reports whether config asks for multi-column text to be read one
+// column at a time.
+func readingOrderColumns(config *ExtractionConfig) bool {
+	return config != nil && config.ReadingOrder != nil && *config.ReadingOrder == ReadingOrderColumns
+}
+
+// applyReadingOrder replaces the content of a PDF result read by the native library with
+// the text layer of each page in column order. Chunks and detected languages are computed
+// again from the new content. Documents whose text layer the Go reader cannot decode, or
+// that were OCR'd because their text layer is too thin, keep the native order; results of
+// the raw text reader are already in column order.
+func applyReadingOrder(ctx context.Context, result *ExtractionResult, config *ExtractionConfig, src *documentSource) error {
+	if !readingOrderColumns(config) || !isPDFMimeType(normalizeMimeType(result.MimeType)) ||
+		(result.Pipeline != nil && result.Pipeline.Extractor == ExtractorRawText) {
+		return nil
+	}
+	doc, err := src.pdfDocument()
+	if err != nil {
+		return err
+	}
+	pages, ok := doc.rawTextPages()
+	if !ok {
+		return nil
+	}
+	texts := make([]string, len(pages))
+	for i, page := range pages {
+		texts[i] = page.text
+	}
+	if pdfTextNeedsOCR(strings.Join(texts, "\n")) {
+		return nil
+	}
+
+	joiner := newPageTextJoiner(config)
+	for i, page := range pages {
+		joiner.add(i+1, page.columnOrder().text)
+	}
+	joiner.apply(result, len(pages))
+	return applyContentFeatures(ctx, result, config, extractBytesNative)
+}
+
+// pdfTextFragment is a run of spans on one line of a page and within one column.
+type pdfTextFragment struct {
+	spans []pdfTextSpan
+	box   [4]float64
+}
+
+// columnOrder returns the text of the page with its columns read one after another, left to
+// right, and the spans moved to match. Lines spanning several columns, such as titles, end
+// the columns above them. A page without columns is returned as is.
+func (p pdfPageText) columnOrder() pdfPageText {
+	fragments := p.fragments()
+	gutters := columnGutters(fragments)
+	if len(gutters) == 0 {
+		return p
+	}
+
+	slices.SortStableFunc(fragments, func(a, b pdfTextFragment) int {
+		return cmp.Or(cmp.Compare(a.box[1], b.box[1]), cmp.Compare(a.box[0], b.box[0]))
+	})
+	ordered := make([]pdfTextFragment, 0, len(fragments))
+	columns := make([][]pdfTextFragment, len(gutters)+1)
+	flush := func() {
+		for i, column := range columns {
+			ordered = append(ordered, column...)
+			columns[i] = column[:0]
+		}
+	}
+	for _, f := range fragments {
+		column := 0
+		for _, gutter := range gutters {
+			if f.box[0] < gutter && f.box[2] > gutter {
+				column = -1
+				break
+			}
+			if (f.box[0]+f.box[2])/2 > gutter {
+				column++
+			}
+		}
+		if column < 0 {
+			flush()
+			ordered = append(ordered, f)
+			continue
+		}
+		columns[column] = append(columns[column], f)
+	}
+	flush()
+
+	var b strings.Builder
+	spans := make([]pdfTextSpan, 0, len(p.spans))
+	for i, f := range ordered {
+		if i > 0 {
+			b.WriteByte('\n')
+		}
+		start, end := f.spans[0].start, f.spans[len(f.spans)-1].end
+		shift := b.Len() - start
+		b.WriteString(p.text[start:end])
+		for _, span := range f.spans {
+			span.start += shift
+			span.end += shift
+			spans = append(spans, span)
+		}
+	}
+	return pdfPageText{text: b.String(), spans: spans}
+}
+
+// fragments splits the spans of the page into lines, and lines at gaps too wide to be
+// between words.
+func (p pdfPageText) fragments() []pdfTextFragment {
+	var fragments []pdfTextFragment
+	for i, span := range p.spans {
+		if i > 0 {
+			prev := p.spans[i-1]
+			height := max(prev.box[3]-prev.box[1], span.box[3]-span.box[1])
+			gap := span.box[0] - prev.box[2]
+			if !strings.Contains(p.text[prev.end:span.start], "\n") && span.box[0] >= prev.box[0] && gap <= pdfColumnSplitGap*height {
+				f := &fragments[len(fragments)-1]
+				f.spans = append(f.spans, span)
+				f.box = [4]float64{min(f.box[0], span.box[0]), min(f.box[1], span.box[1]), max(f.box[2], span.box[2]), max(f.box[3], span.box[3])}
+				continue
+			}
+		}
+		fragments = append(fragments, pdfTextFragment{spans: []pdfTextSpan{span}, box: span.box})
+	}
+	return fragments
+}
+
+// columnGutters returns the horizontal positions of the gaps between the columns of
+// fragments, or nil for text in a single column. Gaps are found between the lines narrow
+// enough to sit in one column, and every column must hold at least two of them.
+func columnGutters(fragments []pdfTextFragment) []float64 {
+	if len(fragments) == 0 {
+		return nil
+	}
+	left, right := fragments[0].box[0], fragments[0].box[2]
+	for _, f := range fragments {
+		left, right = min(left, f.box[0]), max(right, f.box[2])
+	}
+	var narrow [][2]float64
+	heights := make([]float64, 0, len(fragments))
+	for _, f := range fragments {
+		heights = append(heights, f.box[3]-f.box[1])
+		if f.box[2]-f.box[0] <= pdfColumnMaxWidth*(right-left) {
+			narrow = append(narrow, [2]float64{f.box[0], f.box[2]})
+		}
+	}
+	if len(narrow) < 4 || 2*len(narrow) < len(fragments) {
+		return nil
+	}
+	slices.Sort(heights)
+	height := heights[len(heights)/2]
+	slices.SortFunc(narrow, func(a, b [2]float64) int { return cmp.Compare(a[0], b[0]) })
+
+	var gutters []float64
+	counts := []int{0}
+	end := narrow[0][1]
+	for _, interval := range narrow {
+		if interval[0]-end >= pdfColumnMinGutter*height {
+			gutters = append(gutters, (end+interval[0])/2)
+			counts = append(counts, 0)
+		}
+		end = max(end, interval[1])
+		counts[len(counts)-1]++
+	}
+	if slices.Min(counts) < 2 {
+		return nil
+	}
+	return gutters
+}
@@ -0,0 +1,88 @@
+package kreuzberg
+
+import (
+	"context"
+	"fmt"
+	"strings"
+	"testing"
+)
+
+// testColumnsPDF returns a one-page PDF with a title above two columns whose lines are drawn
+// across the page, left line then right line.
+func testColumnsPDF() []byte {
+	var content strings.Builder
+	content.WriteString("BT /F1 12 Tf 72 740 Td (Findings On Reading Order Across Both Columns Of This Page) Tj ET\n")
+	for i := 1; i <= 4; i++ {
+		y := 700 - 14*i
+		fmt.Fprintf(&content, "BT /F1 10 Tf 72 %d Td (left column line %d) Tj ET\n", y, i)
+		fmt.Fprintf(&content, "BT /F1 10 Tf 320 %d Td (right column line %d) Tj ET\n", y, i)
+	}
+	return buildTestPDF(
+		`<< /Type /Catalog /Pages 2 0 R >>`,
+		`<< /Type /Pages /Kids [3 0 R] /Count 1 /MediaBox [0 0 612 792] >>`,
+		`<< /Type /Page /Parent 2 0 R /Contents 4 0 R /Resources << /Font << /F1 5 0 R >> >> >>`,
+		testPDFStream(content.String(), false),
+		`<< /Type /Font /Subtype /Type1 /BaseFont /Helvetica >>`,
+	)
+}
+
+func TestColumnOrder(t *testing.T) {
+	pages, ok := mustParsePDF(t, testColumnsPDF()).rawTextPages()
+	if !ok {
+		t.Fatal("expected the test PDF to be readable")
+	}
+	raw := pages[0]
+	if !strings.Contains(raw.text, "left column line 1 right column line 1") {
+		t.Fatalf("expected the raw order to read across the columns, got %q", raw.text)
+	}
+
+	ordered := raw.columnOrder()
+	want := strings.Join([]string{
+		"Findings On Reading Order Across Both Columns Of This Page",
+		"left column line 1", "left column line 2", "left column line 3", "left column line 4",
+		"right column line 1", "right column line 2", "right column line 3", "right column line 4",
+	}, "\n")
+	if ordered.text != want {
+		t.Fatalf("column order = %q, want %q", ordered.text, want)
+	}
+	for _, span := range ordered.spans {
+		if strings.TrimSpace(ordered.text[span.start:span.end]) == "" {
+			t.Fatalf("span %+v does not cover text", span)
+		}
+	}
+	if i := strings.Index(ordered.text, "right column line 1"); ordered.spans[5].start != i || ordered.spans[5].box[0] < 240 {
+		t.Fatalf("spans not moved with their text: %+v", ordered.spans[5])
+	}
+
+	single := pdfPageText{text: "one line\nanother line"}
+	if got := single.columnOrder(); got.text != single.text {
+		t.Fatalf("single column changed: %q", got.text)
+	}
+}
+
+func TestApplyReadingOrder(t *testing.T) {
+	data := testColumnsPDF()
+	native := func() *ExtractionResult {
+		return &ExtractionResult{MimeType: "application/pdf", Content: "across the columns"}
+	}
+
+	result := native()
+	if err := applyReadingOrder(context.Background(), result, NewExtractionConfig(), &documentSource{data: data}); err != nil || result.Content != "across the columns" {
+		t.Fatalf("raw order changed the content: %q, %v", result.Content, err)
+	}
+
+	config := NewExtractionConfig(WithReadingOrder(ReadingOrderColumns), WithPages(WithExtractPages(true)))
+	if err := applyReadingOrder(context.Background(), result, config, &documentSource{data: data}); err != nil {
+		t.Fatalf("applyReadingOrder failed: %v", err)
+	}
+	if !strings.Contains(result.Content, "line 3\nleft column line 4\nright column line 1") {
+		t.Fatalf("expected the columns one after another, got %q", result.Content)
+	}
+	if len(result.Pages) != 1 || result.Pages[0].Content != result.Content || result.Metadata.PageStructure.TotalCount != 1 {
+		t.Fatalf("unexpected pages %+v", result.Pages)
+	}
+
+	if data, err := encodeConfig(config); err != nil || strings.Contains(string(data), "reading_order") {
+		t.Fatalf("reading order sent to the native library: %s, %v", data, err)
+	}
+}
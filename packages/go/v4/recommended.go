@@ -0,0 +1,29 @@
+package kreuzberg
+
+import "strings"
+
+// RecommendedConfig returns a starting config for documents of mimeType, to be adjusted by
+// the caller. Parameters such as "; charset=utf-8" are ignored. The recommendations are:
+//
+//   - image/*: OCR with Tesseract, since images have no text layer to read.
+//   - application/pdf: PDF metadata extraction. Text PDFs need no OCR, and scanned PDFs
+//     are OCR'd by the native library when a page has no text even without an OCR config.
+//   - text/csv and text/tab-separated-values: structured output, so the table parsed from
+//     the file is also returned as a block. Tables is filled for delimited text either way.
+//
+// Other MIME types get an empty config, which selects the library defaults. Each call
+// returns a new config.
+func RecommendedConfig(mimeType string) *ExtractionConfig {
+	mimeType, _, _ = strings.Cut(mimeType, ";")
+	mimeType = normalizeMimeType(mimeType)
+	switch {
+	case strings.HasPrefix(mimeType, "image/"):
+		return NewExtractionConfig(WithOCR(WithOCRBackend("tesseract")))
+	case mimeType == "application/pdf":
+		return NewExtractionConfig(WithPdfOptions(WithPdfExtractMetadata(true)))
+	case isDelimitedMimeType(mimeType):
+		return NewExtractionConfig(WithStructuredOutput(true))
+	default:
+		return NewExtractionConfig()
+	}
+}
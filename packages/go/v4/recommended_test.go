@@ -0,0 +1,28 @@
+package kreuzberg
+
+import "testing"
+
+func TestRecommendedConfig(t *testing.T) {
+	image := RecommendedConfig("image/png")
+	if image.OCR == nil || image.OCR.Backend != "tesseract" {
+		t.Fatalf("expected tesseract OCR for images, got %+v", image.OCR)
+	}
+
+	pdf := RecommendedConfig("application/pdf")
+	if pdf.PdfOptions == nil || pdf.PdfOptions.ExtractMetadata == nil || !*pdf.PdfOptions.ExtractMetadata {
+		t.Fatalf("expected PDF metadata extraction, got %+v", pdf.PdfOptions)
+	}
+
+	csv := RecommendedConfig("text/csv; charset=utf-8")
+	if csv.StructuredOutput == nil || !*csv.StructuredOutput {
+		t.Fatalf("expected structured output for CSV, got %+v", csv.StructuredOutput)
+	}
+
+	other := RecommendedConfig("text/plain")
+	if other == nil || other.OCR != nil || other.PdfOptions != nil {
+		t.Fatalf("expected an empty config for plain text, got %+v", other)
+	}
+	if RecommendedConfig("image/png") == image {
+		t.Fatal("expected a new config per call")
+	}
+}
@@ -0,0 +1,69 @@
+package kreuzberg
+
+import (
+	"crypto/sha1" // #nosec G505 -- UUIDv5 is defined in terms of SHA-1; not used for security
+	"fmt"
+	"strconv"
+)
+
+// VectorRecord is a chunk in the {id, text, embedding, metadata} shape accepted by vector
+// databases such as Pinecone, Qdrant and pgvector.
+type VectorRecord struct {
+	ID        string         `json:"id"`
+	Text      string         `json:"text"`
+	Embedding []float32      `json:"embedding,omitempty"`
+	Metadata  map[string]any `json:"metadata"`
+}
+
+// vectorRecordNamespace is the RFC 4122 URL namespace used to derive record IDs.
+var vectorRecordNamespace = [16]byte{
+	0x6b, 0xa7, 0xb8, 0x11, 0x9d, 0xad, 0x11, 0xd1,
+	0x80, 0xb4, 0x00, 0xc0, 0x4f, 0xd4, 0x30, 0xc8,
+}
+
+// VectorRecords converts the result's chunks into vector database records. Each ID is a
+// version 5 UUID derived from source and the chunk index, so re-ingesting the same
+// document overwrites its previous records instead of duplicating them. Metadata holds
+// "source", "chunk_index", "total_chunks", "mime_type" and, when known, "first_page",
+// "last_page" and the primary detected "language". Returns nil when the result has no
+// chunks; enable chunking to produce them.
+func (r *ExtractionResult) VectorRecords(source string) []VectorRecord {
+	if r == nil || len(r.Chunks) == 0 {
+		return nil
+	}
+	records := make([]VectorRecord, len(r.Chunks))
+	for i, chunk := range r.Chunks {
+		metadata := map[string]any{
+			"source":       source,
+			"chunk_index":  chunk.Metadata.ChunkIndex,
+			"total_chunks": chunk.Metadata.TotalChunks,
+			"mime_type":    r.MimeType,
+		}
+		if chunk.Metadata.FirstPage != nil {
+			metadata["first_page"] = int(*chunk.Metadata.FirstPage)
+		}
+		if chunk.Metadata.LastPage != nil {
+			metadata["last_page"] = int(*chunk.Metadata.LastPage)
+		}
+		if len(r.DetectedLanguages) > 0 {
+			metadata["language"] = r.DetectedLanguages[0]
+		}
+		records[i] = VectorRecord{
+			ID:        vectorRecordID(source, chunk.Metadata.ChunkIndex),
+			Text:      chunk.Content,
+			Embedding: chunk.Embedding,
+			Metadata:  metadata,
+		}
+	}
+	return records
+}
+
+func vectorRecordID(source string, chunkIndex int) string {
+	h := sha1.New() // #nosec G401 -- see import
+	h.Write(vectorRecordNamespace[:])
+	h.Write([]byte(source + "#" + strconv.Itoa(chunkIndex)))
+	sum := h.Sum(nil)
+	sum[6] = (sum[6] & 0x0f) | 0x50
+	sum[8] = (sum[8] & 0x3f) | 0x80
+	return fmt.Sprintf("%x-%x-%x-%x-%x", sum[0:4], sum[4:6], sum[6:8], sum[8:10], sum[10:16])
+}
@@ -0,0 +1,57 @@
+package kreuzberg
+
+import (
+	"testing"
+)
+
+func TestVectorRecords(t *testing.T) {
+	page := uint64(2)
+	result := &ExtractionResult{
+		MimeType:          "application/pdf",
+		DetectedLanguages: []string{"de", "en"},
+		Chunks: []Chunk{
+			{Content: "first", Embedding: []float32{0.1, 0.2}, Metadata: ChunkMetadata{ChunkIndex: 0, TotalChunks: 2}},
+			{Content: "second", Metadata: ChunkMetadata{ChunkIndex: 1, TotalChunks: 2, FirstPage: &page, LastPage: &page}},
+		},
+	}
+
+	records := result.VectorRecords("docs/report.pdf")
+	if len(records) != 2 {
+		t.Fatalf("expected 2 records, got %d", len(records))
+	}
+	if records[0].Text != "first" || len(records[0].Embedding) != 2 {
+		t.Fatalf("unexpected first record: %+v", records[0])
+	}
+	if _, ok := records[0].Metadata["first_page"]; ok {
+		t.Fatal("first record should not carry page numbers")
+	}
+	meta := records[1].Metadata
+	if meta["source"] != "docs/report.pdf" || meta["chunk_index"] != 1 || meta["total_chunks"] != 2 ||
+		meta["first_page"] != 2 || meta["last_page"] != 2 || meta["language"] != "de" || meta["mime_type"] != "application/pdf" {
+		t.Fatalf("unexpected metadata: %v", meta)
+	}
+
+	again := result.VectorRecords("docs/report.pdf")
+	if records[0].ID != again[0].ID || records[0].ID == records[1].ID {
+		t.Fatalf("IDs must be deterministic and unique: %q %q %q", records[0].ID, again[0].ID, records[1].ID)
+	}
+	if other := result.VectorRecords("docs/other.pdf"); other[0].ID == records[0].ID {
+		t.Fatal("IDs must depend on the source")
+	}
+	if id := records[0].ID; len(id) != 36 || id[14] != '5' {
+		t.Fatalf("expected a version 5 UUID, got %q", id)
+	}
+}
+
+func TestVectorRecordIDMatchesUUIDv5(t *testing.T) {
+	// Python: uuid.uuid5(uuid.NAMESPACE_URL, "a#0")
+	if got, want := vectorRecordID("a", 0), "57b7c2f4-eb2f-522b-8be9-17370260c923"; got != want {
+		t.Fatalf("vectorRecordID = %q, want %q", got, want)
+	}
+}
+
+func TestVectorRecordsWithoutChunks(t *testing.T) {
+	if records := (&ExtractionResult{Content: "text"}).VectorRecords("x"); records != nil {
+		t.Fatalf("expected nil, got %v", records)
+	}
+}
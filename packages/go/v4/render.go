@@ -0,0 +1,96 @@
+package kreuzberg
+
+import (
+	"encoding/csv"
+	"strings"
+	"text/template"
+)
+
+// RenderData is the data Render executes templates with.
+type RenderData struct {
+	// Title and Author come from the format metadata, as in IndexDocument.
+	Title  string
+	Author string
+	// Language is the primary language, as GetDetectedLanguage returns it.
+	Language string
+	// PageCount is the number of pages, slides or sheets, or the page count of the PDF
+	// metadata.
+	PageCount int
+	MimeType  string
+	Content   string
+	Tables    []Table
+	Keywords  []string
+	Metadata  Metadata
+	// Result is the rendered result, for the fields not copied above.
+	Result *ExtractionResult
+}
+
+// renderFuncs are the table helpers available to Render templates.
+var renderFuncs = template.FuncMap{
+	"markdown": func(t Table) string {
+		if t.Markdown != "" {
+			return t.Markdown
+		}
+		return tableMarkdown(t.Cells)
+	},
+	"csv": func(t Table) (string, error) { return tableDelimited(t, ',') },
+	"tsv": func(t Table) (string, error) { return tableDelimited(t, '\t') },
+}
+
+// Render executes the text/template tmpl with the result's RenderData and returns the
+// output, for summaries in a fixed layout such as
+//
+//	Title: {{.Title}}
+//	Pages: {{.PageCount}}
+//
+//	{{.Content}}
+//
+// Besides the built-in functions, templates can format tables with markdown, which returns
+// a table's Markdown, and csv and tsv, which return its cells as comma- and tab-separated
+// values quoted as encoding/csv does:
+//
+//	{{range .Tables}}{{csv .}}{{end}}
+//
+// A template that does not parse returns a ValidationError and one that fails to execute,
+// for example by naming a field RenderData does not have, a RuntimeError; both wrap the
+// text/template error, which gives the position in tmpl. A nil result renders as an empty
+// one.
+func (r *ExtractionResult) Render(tmpl string) (string, error) {
+	if r == nil {
+		r = &ExtractionResult{}
+	}
+	t, err := template.New("result").Funcs(renderFuncs).Parse(tmpl)
+	if err != nil {
+		return "", newValidationErrorWithContext("invalid template", err, ErrorCodeValidation, nil)
+	}
+	title, author := r.indexTitleAuthor()
+	language, _ := r.GetDetectedLanguage()
+	data := RenderData{
+		Title:     title,
+		Author:    author,
+		Language:  language,
+		PageCount: r.indexPageCount(),
+		MimeType:  r.MimeType,
+		Content:   r.Content,
+		Tables:    r.Tables,
+		Keywords:  r.indexKeywords(),
+		Metadata:  r.Metadata,
+		Result:    r,
+	}
+	var b strings.Builder
+	if err := t.Execute(&b, data); err != nil {
+		return "", newRuntimeErrorWithContext("failed to render template", err, ErrorCodeInternal, nil)
+	}
+	return b.String(), nil
+}
+
+// tableDelimited returns the cells of t as delimiter-separated values.
+func tableDelimited(t Table, delimiter rune) (string, error) {
+	var b strings.Builder
+	w := csv.NewWriter(&b)
+	w.Comma = delimiter
+	if err := w.WriteAll(t.Cells); err != nil {
+		return "", err
+	}
+	return b.String(), nil
+}
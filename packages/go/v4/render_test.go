@@ -0,0 +1,52 @@
+package kreuzberg
+
+import (
+	"errors"
+	"strings"
+	"testing"
+)
+
+func TestRender(t *testing.T) {
+	title, pages := "Annual Report", 12
+	result := &ExtractionResult{
+		Content:  "Revenue grew.",
+		MimeType: "application/pdf",
+		Tables:   []Table{{Cells: [][]string{{"Year", "Revenue"}, {"2024", "1,200"}}}},
+		Metadata: Metadata{
+			Format: FormatMetadata{Type: FormatPDF, Pdf: &PdfMetadata{Title: &title, Authors: []string{"Ada"}, PageCount: &pages}},
+		},
+	}
+
+	out, err := result.Render("Title: {{.Title}}\nPages: {{.PageCount}}\n\n{{.Content}}")
+	if err != nil {
+		t.Fatalf("Render failed: %v", err)
+	}
+	if out != "Title: Annual Report\nPages: 12\n\nRevenue grew." {
+		t.Fatalf("unexpected output %q", out)
+	}
+
+	out, err = result.Render("{{range .Tables}}{{markdown .}}\n{{csv .}}{{tsv .}}{{end}}{{.Result.MimeType}}")
+	if err != nil {
+		t.Fatalf("Render failed: %v", err)
+	}
+	want := "| Year | Revenue |\n| --- | --- |\n| 2024 | 1,200 |\n" +
+		"Year,Revenue\n2024,\"1,200\"\n" +
+		"Year\tRevenue\n2024\t1,200\n" +
+		"application/pdf"
+	if out != want {
+		t.Fatalf("unexpected table output %q, want %q", out, want)
+	}
+}
+
+func TestRenderErrors(t *testing.T) {
+	result := &ExtractionResult{Content: "text"}
+	var valErr *ValidationError
+	if _, err := result.Render("{{.Content"); !errors.As(err, &valErr) {
+		t.Fatalf("expected a ValidationError for a malformed template, got %v", err)
+	}
+	var runErr *RuntimeError
+	_, err := result.Render("{{.Missing}}")
+	if !errors.As(err, &runErr) || !strings.Contains(err.Error(), "Missing") {
+		t.Fatalf("expected a RuntimeError naming the field, got %v", err)
+	}
+}
@@ -0,0 +1,213 @@
+package kreuzberg
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+	"image"
+	"image/color"
+	"image/jpeg"
+	"image/png"
+	"math"
+	"strings"
+)
+
+// Page image formats accepted by WithRenderPages.
+const (
+	RenderFormatPNG  = "png"
+	RenderFormatJPEG = "jpeg"
+)
+
+const (
+	// renderJPEGQuality is the quality of JPEG page images.
+	renderJPEGQuality = 90
+	// renderAspectTolerance is how far the aspect ratio of a PDF page's largest image may
+	// differ from the page's for the image to be taken as a scan of the whole page.
+	renderAspectTolerance = 0.05
+)
+
+// RenderPagesConfig selects the resolution and file format of the page images produced by
+// WithRenderPages.
+type RenderPagesConfig struct {
+	DPI    int    `json:"dpi"`
+	Format string `json:"format"`
+}
+
+// applyRenderPages fills result.PageImages when page rendering is configured, and
+// result.PageHashes from the same page images when page thumbprints are enabled. Image
+// documents are one page. A PDF page is rendered from its largest image when that image has
+// the page's aspect ratio, as on scanned pages; the page box gives the rendered size and
+// /Rotate its orientation. Pages that cannot be rendered this way get a
+// WarningCodePageNotRendered warning.
+func applyRenderPages(ctx context.Context, result *ExtractionResult, config *ExtractionConfig, src *documentSource) error {
+	render := config.RenderPages
+	thumbprints := config.PageThumbprints != nil && *config.PageThumbprints
+	if render == nil && !thumbprints {
+		return nil
+	}
+	mimeType := normalizeMimeType(result.MimeType)
+	if !isPDFMimeType(mimeType) && !strings.HasPrefix(mimeType, "image/") {
+		return nil
+	}
+	data, err := src.bytes()
+	if err != nil {
+		return err
+	}
+	notRendered := func(pageNumber int, reason string) {
+		result.addWarning(WarningCodePageNotRendered, pageNumber, fmt.Sprintf("page %d was not rendered: %s", pageNumber, reason))
+	}
+
+	if !isPDFMimeType(mimeType) {
+		img, format, err := image.Decode(bytes.NewReader(data))
+		if err != nil {
+			notRendered(1, "only PNG, JPEG and GIF images can be rendered")
+			return nil
+		}
+		if thumbprints {
+			result.PageHashes = append(result.PageHashes, PageHash{PageNumber: 1, PHash: perceptualHash(img, 0)})
+		}
+		if render == nil {
+			return nil
+		}
+		dpiX, dpiY := imageResolution(data, format)
+		bounds := img.Bounds()
+		width := int(math.Round(float64(bounds.Dx()) * float64(render.DPI) / dpiX))
+		height := int(math.Round(float64(bounds.Dy()) * float64(render.DPI) / dpiY))
+		page, err := renderedPage(img, width, height, 0, 1, render.Format)
+		if err != nil {
+			return err
+		}
+		result.PageImages = append(result.PageImages, page)
+		return nil
+	}
+
+	doc, err := src.pdfDocument()
+	if err != nil {
+		return err
+	}
+	for i, pdfPage := range doc.pages() {
+		if err := ctx.Err(); err != nil {
+			return err
+		}
+		pageNumber := i + 1
+		box := pdfPage.mediaBox
+		if pdfPage.cropBox != nil {
+			box = *pdfPage.cropBox
+		}
+		pageWidth, pageHeight := box[2]-box[0], box[3]-box[1]
+		file, _, ok := pdfPageImage(doc, pdfPage)
+		if !ok || pageWidth <= 0 || pageHeight <= 0 {
+			notRendered(pageNumber, "the page has no scanned image and PDF pages are rendered only from their scans")
+			continue
+		}
+		img, _, err := image.Decode(bytes.NewReader(file))
+		if err != nil {
+			notRendered(pageNumber, "the page image could not be decoded")
+			continue
+		}
+		bounds := img.Bounds()
+		imageAspect := float64(bounds.Dx()) / float64(bounds.Dy())
+		if math.Abs(imageAspect/(pageWidth/pageHeight)-1) > renderAspectTolerance {
+			notRendered(pageNumber, "the page's largest image does not cover the page and PDF pages are rendered only from their scans")
+			continue
+		}
+		if thumbprints {
+			result.PageHashes = append(result.PageHashes, PageHash{PageNumber: pageNumber, PHash: perceptualHash(img, pdfPage.rotate)})
+		}
+		if render == nil {
+			continue
+		}
+		width := int(math.Round(pageWidth * float64(render.DPI) / 72))
+		height := int(math.Round(pageHeight * float64(render.DPI) / 72))
+		page, err := renderedPage(img, width, height, pdfPage.rotate, pageNumber, render.Format)
+		if err != nil {
+			return err
+		}
+		result.PageImages = append(result.PageImages, page)
+	}
+	return nil
+}
+
+// renderedPage scales img to width by height pixels, turns it clockwise by rotate degrees
+// and encodes it as an ExtractedImage of page pageNumber.
+func renderedPage(img image.Image, width, height, rotate, pageNumber int, format string) (ExtractedImage, error) {
+	scaled := scaleImage(img, max(width, 1), max(height, 1))
+	if rotate != 0 {
+		scaled = rotateImage(scaled, rotate)
+	}
+	var buf bytes.Buffer
+	var err error
+	if format == RenderFormatJPEG {
+		err = jpeg.Encode(&buf, scaled, &jpeg.Options{Quality: renderJPEGQuality})
+	} else {
+		err = png.Encode(&buf, scaled)
+	}
+	if err != nil {
+		return ExtractedImage{}, newRuntimeErrorWithContext(fmt.Sprintf("failed to encode page %d image", pageNumber), err, ErrorCodeInternal, nil)
+	}
+	bounds := scaled.Bounds()
+	page := pageNumber
+	w, h := uint32(bounds.Dx()), uint32(bounds.Dy())
+	return ExtractedImage{
+		Data:       buf.Bytes(),
+		Format:     format,
+		ImageIndex: pageNumber - 1,
+		PageNumber: &page,
+		Width:      &w,
+		Height:     &h,
+	}, nil
+}
+
+// scaleImage resamples img to width by height pixels, averaging the source pixels each
+// target pixel covers.
+func scaleImage(img image.Image, width, height int) *image.RGBA {
+	bounds := img.Bounds()
+	out := image.NewRGBA(image.Rect(0, 0, width, height))
+	scaleX := float64(bounds.Dx()) / float64(width)
+	scaleY := float64(bounds.Dy()) / float64(height)
+	for y := range height {
+		y0 := bounds.Min.Y + int(float64(y)*scaleY)
+		y1 := max(bounds.Min.Y+int(float64(y+1)*scaleY), y0+1)
+		for x := range width {
+			x0 := bounds.Min.X + int(float64(x)*scaleX)
+			x1 := max(bounds.Min.X+int(float64(x+1)*scaleX), x0+1)
+			var r, g, b, a, n uint64
+			for sy := y0; sy < y1; sy++ {
+				for sx := x0; sx < x1; sx++ {
+					cr, cg, cb, ca := img.At(sx, sy).RGBA()
+					r, g, b, a, n = r+uint64(cr), g+uint64(cg), b+uint64(cb), a+uint64(ca), n+1
+				}
+			}
+			out.SetRGBA(x, y, color.RGBA{uint8(r / n >> 8), uint8(g / n >> 8), uint8(b / n >> 8), uint8(a / n >> 8)})
+		}
+	}
+	return out
+}
+
+// rotateImage turns img clockwise by degrees, a multiple of 90.
+func rotateImage(img *image.RGBA, degrees int) *image.RGBA {
+	bounds := img.Bounds()
+	w, h := bounds.Dx(), bounds.Dy()
+	var out *image.RGBA
+	if degrees%180 == 0 {
+		out = image.NewRGBA(image.Rect(0, 0, w, h))
+	} else {
+		out = image.NewRGBA(image.Rect(0, 0, h, w))
+	}
+	for y := range h {
+		for x := range w {
+			c := img.RGBAAt(x, y)
+			switch degrees {
+			case 90:
+				out.SetRGBA(h-1-y, x, c)
+			case 180:
+				out.SetRGBA(w-1-x, h-1-y, c)
+			case 270:
+				out.SetRGBA(y, w-1-x, c)
+			default:
+				out.SetRGBA(x, y, c)
+			}
+		}
+	}
+	return out
+}
@@ -0,0 +1,113 @@
+package kreuzberg
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"image"
+	"image/color"
+	"image/jpeg"
+	"image/png"
+	"testing"
+)
+
+// testRenderImage returns a width by height image, black in the left half and white in the
+// right half.
+func testRenderImage(width, height int) *image.Gray {
+	img := image.NewGray(image.Rect(0, 0, width, height))
+	for y := range height {
+		for x := range width {
+			if x >= width/2 {
+				img.SetGray(x, y, color.Gray{Y: 0xff})
+			}
+		}
+	}
+	return img
+}
+
+func TestApplyRenderPagesPDF(t *testing.T) {
+	var scan bytes.Buffer
+	if err := jpeg.Encode(&scan, testRenderImage(80, 40), nil); err != nil {
+		t.Fatal(err)
+	}
+	page := func(num int, extra pdfDict) pdfObject {
+		dict := pdfDict{"Type": pdfName("Page"), "Parent": pdfRef{num: 2}, "Resources": pdfDict{"XObject": pdfDict{"Im1": pdfRef{num: 6}}}}
+		for key, value := range extra {
+			dict[key] = value
+		}
+		return pdfObject{ref: pdfRef{num: num}, value: dict}
+	}
+	w := &pdfWriter{}
+	w.buf.WriteString("%PDF-1.4\n")
+	for _, obj := range []pdfObject{
+		{ref: pdfRef{num: 1}, value: pdfDict{"Type": pdfName("Catalog"), "Pages": pdfRef{num: 2}}},
+		{ref: pdfRef{num: 2}, value: pdfDict{"Type": pdfName("Pages"), "Kids": pdfArray{pdfRef{num: 3}, pdfRef{num: 4}, pdfRef{num: 5}}, "Count": 3.0,
+			"MediaBox": pdfArray{0.0, 0.0, 20.0, 10.0}}},
+		page(3, nil),
+		page(4, pdfDict{"Rotate": 90.0}),
+		page(5, pdfDict{"Resources": pdfDict{}}),
+		{ref: pdfRef{num: 6}, value: &pdfStream{dict: pdfDict{"Type": pdfName("XObject"), "Subtype": pdfName("Image"),
+			"Width": 80.0, "Height": 40.0, "BitsPerComponent": 8.0, "ColorSpace": pdfName("DeviceGray"), "Filter": pdfName("DCTDecode")}, raw: scan.Bytes()}},
+	} {
+		w.object(obj)
+	}
+	w.xref(pdfDict{"Size": 7.0, "Root": pdfRef{num: 1}}, true)
+
+	result := &ExtractionResult{MimeType: "application/pdf"}
+	config := NewExtractionConfig(WithRenderPages(144, RenderFormatPNG))
+	if err := applyRenderPages(context.Background(), result, config, &documentSource{data: w.buf.Bytes()}); err != nil {
+		t.Fatalf("applyRenderPages failed: %v", err)
+	}
+	if len(result.PageImages) != 2 {
+		t.Fatalf("expected two rendered pages, got %d", len(result.PageImages))
+	}
+	for i, want := range []image.Point{{40, 20}, {20, 40}} {
+		pageImage := result.PageImages[i]
+		img, err := png.Decode(bytes.NewReader(pageImage.Data))
+		if err != nil {
+			t.Fatalf("page %d is not a PNG: %v", i+1, err)
+		}
+		if got := img.Bounds().Size(); got != want || *pageImage.Width != uint32(want.X) || *pageImage.PageNumber != i+1 {
+			t.Fatalf("page %d rendered as %v (%+v), want %v", i+1, got, pageImage, want)
+		}
+	}
+	// The rotated page shows the dark left half of the scan at the top.
+	rotated, _ := png.Decode(bytes.NewReader(result.PageImages[1].Data))
+	if top, bottom := color.GrayModel.Convert(rotated.At(10, 5)).(color.Gray), color.GrayModel.Convert(rotated.At(10, 35)).(color.Gray); top.Y > 64 || bottom.Y < 192 {
+		t.Fatalf("unexpected rotation: top %d, bottom %d", top.Y, bottom.Y)
+	}
+	if len(result.Warnings) != 1 || result.Warnings[0].Code != WarningCodePageNotRendered || result.Warnings[0].PageNumber != 3 {
+		t.Fatalf("unexpected warnings %+v", result.Warnings)
+	}
+}
+
+func TestApplyRenderPagesImage(t *testing.T) {
+	var data bytes.Buffer
+	if err := png.Encode(&data, testRenderImage(30, 10)); err != nil {
+		t.Fatal(err)
+	}
+	result := &ExtractionResult{MimeType: "image/png"}
+	config := NewExtractionConfig(WithRenderPages(36, RenderFormatJPEG))
+	if err := applyRenderPages(context.Background(), result, config, &documentSource{data: data.Bytes()}); err != nil {
+		t.Fatalf("applyRenderPages failed: %v", err)
+	}
+	if len(result.PageImages) != 1 {
+		t.Fatalf("expected one page image, got %d", len(result.PageImages))
+	}
+	img, err := jpeg.Decode(bytes.NewReader(result.PageImages[0].Data))
+	if err != nil || img.Bounds().Dx() != 15 || img.Bounds().Dy() != 5 {
+		t.Fatalf("unexpected page image: %v, %v", img.Bounds(), err)
+	}
+}
+
+func TestRenderPagesValidation(t *testing.T) {
+	var valErr *ValidationError
+	for _, config := range []*ExtractionConfig{
+		NewExtractionConfig(WithRenderPages(0, RenderFormatPNG)),
+		NewExtractionConfig(WithRenderPages(72, "bmp")),
+	} {
+		if err := validateConfig(config); !errors.As(err, &valErr) {
+			t.Errorf("expected ValidationError for %+v, got %v", config.RenderPages, err)
+		}
+	}
+}
@@ -0,0 +1,36 @@
+package kreuzberg
+
+import "time"
+
+// ResourceUsage is the memory and CPU time used by the native extraction of a document; see
+// WithResourceTracking.
+type ResourceUsage struct {
+	// PeakRSSBytes is the highest resident set size of the process during the extraction.
+	PeakRSSBytes uint64 `json:"peak_rss_bytes"`
+	// CPUTime is the user and system CPU time the process used during the extraction.
+	CPUTime time.Duration `json:"cpu_time"`
+}
+
+// resourceTracker measures the resources used between its start and finish.
+type resourceTracker struct {
+	cpu time.Duration
+}
+
+// startResourceTracking starts measuring when resource tracking is enabled on config and
+// supported on this platform, and returns nil otherwise. Native calls are serialized, so
+// the measurement is only shared with Go code running concurrently.
+func startResourceTracking(config *ExtractionConfig) *resourceTracker {
+	if !resourceTrackingSupported || config == nil || config.ResourceTracking == nil || !*config.ResourceTracking {
+		return nil
+	}
+	resetPeakRSS()
+	return &resourceTracker{cpu: processCPUTime()}
+}
+
+// finish returns the resources used since the tracker started, or nil for a nil tracker.
+func (t *resourceTracker) finish() *ResourceUsage {
+	if t == nil {
+		return nil
+	}
+	return &ResourceUsage{PeakRSSBytes: peakRSS(), CPUTime: processCPUTime() - t.cpu}
+}
@@ -0,0 +1,54 @@
+package kreuzberg
+
+import (
+	"bufio"
+	"os"
+	"strconv"
+	"strings"
+	"syscall"
+	"time"
+)
+
+const resourceTrackingSupported = true
+
+// resetPeakRSS resets the peak resident set size reported as VmHWM. Kernels before 4.0 and
+// sandboxes that deny the write keep the peak of the whole process lifetime.
+func resetPeakRSS() {
+	file, err := os.OpenFile("/proc/self/clear_refs", os.O_WRONLY, 0)
+	if err != nil {
+		return
+	}
+	_, _ = file.WriteString("5")
+	_ = file.Close()
+}
+
+// peakRSS returns the VmHWM of /proc/self/status in bytes.
+func peakRSS() uint64 {
+	file, err := os.Open("/proc/self/status")
+	if err != nil {
+		return 0
+	}
+	defer file.Close()
+	scanner := bufio.NewScanner(file)
+	for scanner.Scan() {
+		value, ok := strings.CutPrefix(scanner.Text(), "VmHWM:")
+		if !ok {
+			continue
+		}
+		kib, err := strconv.ParseUint(strings.TrimSuffix(strings.TrimSpace(value), " kB"), 10, 64)
+		if err != nil {
+			return 0
+		}
+		return kib * 1024
+	}
+	return 0
+}
+
+// processCPUTime returns the user and system CPU time used by the process so far.
+func processCPUTime() time.Duration {
+	var usage syscall.Rusage
+	if err := syscall.Getrusage(syscall.RUSAGE_SELF, &usage); err != nil {
+		return 0
+	}
+	return time.Duration(usage.Utime.Nano() + usage.Stime.Nano())
+}
@@ -0,0 +1,13 @@
+//go:build !linux
+
+package kreuzberg
+
+import "time"
+
+const resourceTrackingSupported = false
+
+func resetPeakRSS() {}
+
+func peakRSS() uint64 { return 0 }
+
+func processCPUTime() time.Duration { return 0 }
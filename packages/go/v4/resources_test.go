@@ -0,0 +1,36 @@
+package kreuzberg
+
+import (
+	"runtime"
+	"testing"
+	"time"
+)
+
+func TestResourceTracking(t *testing.T) {
+	if startResourceTracking(nil) != nil || startResourceTracking(NewExtractionConfig(WithResourceTracking(false))) != nil {
+		t.Fatal("expected no tracker when resource tracking is disabled")
+	}
+	var tracker *resourceTracker
+	if tracker.finish() != nil {
+		t.Fatal("expected a nil tracker to report nothing")
+	}
+	if runtime.GOOS != "linux" {
+		t.Skip("resource tracking is only supported on Linux")
+	}
+
+	tracker = startResourceTracking(NewExtractionConfig(WithResourceTracking(true)))
+	if tracker == nil {
+		t.Fatal("expected a tracker when resource tracking is enabled")
+	}
+	buf := make([]byte, 32<<20)
+	for i := range buf {
+		buf[i] = byte(i)
+	}
+	for deadline := time.Now().Add(20 * time.Millisecond); time.Now().Before(deadline); {
+	}
+	usage := tracker.finish()
+	runtime.KeepAlive(buf)
+	if usage.CPUTime <= 0 || usage.PeakRSSBytes < uint64(len(buf)) {
+		t.Fatalf("unexpected usage %+v", usage)
+	}
+}
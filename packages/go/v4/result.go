@@ -2,7 +2,10 @@ package kreuzberg
 
 import (
 	"encoding/json"
+	"errors"
 	"fmt"
+	"io"
+	"strings"
 )
 
 /*
@@ -127,6 +130,37 @@ func ResultFromJSON(jsonStr string) (*ExtractionResult, error) {
 	return &result, nil
 }
 
+// ResultFromJSONStrict is ResultFromJSON, but fails on fields ExtractionResult does not
+// have, naming the first one, to detect results from a library version with fields this
+// binding would silently drop. Metadata is the exception: keys it does not know are kept
+// in Metadata.Additional, as with ResultFromJSON.
+func ResultFromJSONStrict(jsonStr string) (*ExtractionResult, error) {
+	if jsonStr == "" {
+		return nil, newValidationErrorWithContext("JSON string cannot be empty", nil, ErrorCodeValidation, nil)
+	}
+
+	var result ExtractionResult
+	if err := decodeJSONStrict(jsonStr, &result); err != nil {
+		return nil, newSerializationErrorWithContext(fmt.Sprintf("failed to decode result JSON: %v", err), err, ErrorCodeValidation, nil)
+	}
+
+	return &result, nil
+}
+
+// decodeJSONStrict decodes the single JSON value in data into v, failing on unknown fields
+// and on trailing data.
+func decodeJSONStrict(data string, v any) error {
+	decoder := json.NewDecoder(strings.NewReader(data))
+	decoder.DisallowUnknownFields()
+	if err := decoder.Decode(v); err != nil {
+		return err
+	}
+	if _, err := decoder.Token(); !errors.Is(err, io.EOF) {
+		return errors.New("unexpected data after the JSON value")
+	}
+	return nil
+}
+
 // String implements fmt.Stringer for ExtractionResult, showing a summary.
 func (r *ExtractionResult) String() string {
 	if r == nil {
@@ -0,0 +1,206 @@
+package kreuzberg
+
+import (
+	"bytes"
+	"encoding/xml"
+	"errors"
+	"io"
+	"strings"
+	"time"
+)
+
+// Revision types reported in Revision.Type.
+const (
+	RevisionInsertion = "insertion"
+	RevisionDeletion  = "deletion"
+	RevisionFormat    = "format"
+)
+
+// Revision is a tracked change of a DOCX document: an insertion (w:ins), a deletion (w:del)
+// or a formatting change (w:rPrChange, w:pPrChange). Text is the inserted or deleted text,
+// or for a formatting change the text whose formatting changed. Date is zero when Word did
+// not record one.
+type Revision struct {
+	Type   string    `json:"type"`
+	Author string    `json:"author,omitempty"`
+	Date   time.Time `json:"date"`
+	Text   string    `json:"text"`
+}
+
+// docxParagraphText is the text of a paragraph with tracked changes in each
+// DocxOptions.TrackedChangesMode.
+type docxParagraphText struct {
+	accepted, rejected, original string
+}
+
+// applyRevisions fills result.Revisions when revision extraction is enabled and rewrites
+// the content of DOCX documents for the configured DocxOptions.TrackedChangesMode.
+func applyRevisions(result *ExtractionResult, config *ExtractionConfig, src *documentSource) error {
+	extract := config.ExtractRevisions != nil && *config.ExtractRevisions
+	mode := TrackedChangesAccepted
+	if config.Docx != nil && config.Docx.TrackedChangesMode != "" {
+		mode = config.Docx.TrackedChangesMode
+	}
+	if result.MimeType != mimeTypeDOCX || (!extract && mode == TrackedChangesAccepted) {
+		return nil
+	}
+	data, err := src.bytes()
+	if err != nil {
+		return err
+	}
+	revisions, paragraphs, err := parseDocxRevisions(data)
+	if err != nil {
+		return newParsingErrorWithContext("failed to read DOCX revisions", err, ErrorCodeParsing, nil)
+	}
+	if extract {
+		result.Revisions = revisions
+	}
+	if mode != TrackedChangesAccepted {
+		result.Content = replaceRevisedParagraphs(result.Content, paragraphs, mode)
+	}
+	return nil
+}
+
+// replaceRevisedParagraphs replaces the accepted text of each changed paragraph in content,
+// searching forward from the previous replacement, with its text in mode. Paragraphs whose
+// accepted text is empty or cannot be found, for example because of Markdown formatting,
+// are left as they are.
+func replaceRevisedParagraphs(content string, paragraphs []docxParagraphText, mode string) string {
+	var out strings.Builder
+	rest := content
+	for _, p := range paragraphs {
+		accepted := strings.TrimSpace(p.accepted)
+		target := strings.TrimSpace(p.rejected)
+		if mode == TrackedChangesOriginal {
+			target = strings.TrimSpace(p.original)
+		}
+		if accepted == "" || accepted == target {
+			continue
+		}
+		index := strings.Index(rest, accepted)
+		if index < 0 {
+			continue
+		}
+		out.WriteString(rest[:index])
+		out.WriteString(target)
+		rest = rest[index+len(accepted):]
+	}
+	out.WriteString(rest)
+	return out.String()
+}
+
+// parseDocxRevisions reads the tracked changes of word/document.xml in document order,
+// together with the text of every paragraph that contains an insertion or deletion.
+func parseDocxRevisions(data []byte) ([]Revision, []docxParagraphText, error) {
+	raw, ok, err := readZipEntry(data, "word/document.xml")
+	if err != nil || !ok {
+		return nil, nil, err
+	}
+
+	var revisions []Revision
+	var paragraphs []docxParagraphText
+	var accepted, rejected, original strings.Builder
+	changed := false
+	// insertion and deletion index the open w:ins and w:del in revisions, or are -1.
+	insertion, deletion := -1, -1
+	// runFormat and paragraphFormat index the formatting changes collecting the text of the
+	// current run and paragraph, or are -1.
+	runFormat, paragraphFormat := -1, -1
+	inText, inRun := false, false
+
+	write := func(text string) {
+		original.WriteString(text)
+		if deletion < 0 {
+			accepted.WriteString(text)
+		}
+		if insertion < 0 {
+			rejected.WriteString(text)
+		}
+		for _, i := range []int{insertion, deletion, runFormat, paragraphFormat} {
+			if i >= 0 {
+				revisions[i].Text += text
+			}
+		}
+	}
+	open := func(kind string, el xml.StartElement) int {
+		revision := Revision{Type: kind, Author: xmlAttr(el, "author")}
+		if date, err := time.Parse(time.RFC3339, xmlAttr(el, "date")); err == nil {
+			revision.Date = date
+		}
+		revisions = append(revisions, revision)
+		return len(revisions) - 1
+	}
+
+	decoder := xml.NewDecoder(bytes.NewReader(raw))
+	for {
+		token, err := decoder.Token()
+		if errors.Is(err, io.EOF) {
+			break
+		}
+		if err != nil {
+			return nil, nil, err
+		}
+
+		switch el := token.(type) {
+		case xml.StartElement:
+			switch el.Name.Local {
+			case "p":
+				accepted.Reset()
+				rejected.Reset()
+				original.Reset()
+				changed = false
+			case "ins":
+				insertion, changed = open(RevisionInsertion, el), true
+			case "del":
+				deletion, changed = open(RevisionDeletion, el), true
+			case "r":
+				inRun = true
+			case "rPrChange":
+				// Formatting changes of paragraph marks have no text and are skipped.
+				if inRun {
+					runFormat = open(RevisionFormat, el)
+				}
+			case "pPrChange":
+				paragraphFormat = open(RevisionFormat, el)
+			case "t", "delText":
+				inText = true
+			case "tab":
+				// w:tab also defines tab stops in paragraph properties.
+				if inRun {
+					write("\t")
+				}
+			case "br", "cr":
+				write("\n")
+			}
+		case xml.EndElement:
+			switch el.Name.Local {
+			case "p":
+				if changed {
+					paragraphs = append(paragraphs, docxParagraphText{accepted.String(), rejected.String(), original.String()})
+				}
+				paragraphFormat = -1
+			case "ins":
+				insertion = -1
+			case "del":
+				deletion = -1
+			case "r":
+				inRun, runFormat = false, -1
+			case "t", "delText":
+				inText = false
+			}
+		case xml.CharData:
+			if inText {
+				write(string(el))
+			}
+		}
+	}
+
+	// Changes without text, such as inserted paragraph marks, are not reported.
+	kept := revisions[:0]
+	for _, revision := range revisions {
+		if revision.Text != "" {
+			kept = append(kept, revision)
+		}
+	}
+	return kept, paragraphs, nil
+}
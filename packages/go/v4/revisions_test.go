@@ -0,0 +1,79 @@
+package kreuzberg
+
+import (
+	"testing"
+	"time"
+)
+
+const testDocxRevisions = `<?xml version="1.0" encoding="UTF-8"?>
+<w:document xmlns:w="http://schemas.openxmlformats.org/wordprocessingml/2006/main"><w:body>
+  <w:p><w:r><w:t>Title</w:t></w:r></w:p>
+  <w:p>
+    <w:r><w:t xml:space="preserve">The quick </w:t></w:r>
+    <w:ins w:id="1" w:author="Alice" w:date="2024-03-01T09:30:00Z"><w:r><w:t xml:space="preserve">brown </w:t></w:r></w:ins>
+    <w:r><w:t>fox</w:t></w:r>
+    <w:del w:id="2" w:author="Bob" w:date="2024-03-02T10:00:00Z"><w:r><w:delText xml:space="preserve"> jumps</w:delText></w:r></w:del>
+    <w:r><w:t>.</w:t></w:r>
+  </w:p>
+  <w:p>
+    <w:pPr><w:rPr><w:ins w:id="3" w:author="Alice"/></w:rPr></w:pPr>
+    <w:r><w:rPr><w:b/><w:rPrChange w:id="4" w:author="Carol"><w:rPr/></w:rPrChange></w:rPr><w:t>Bold</w:t></w:r>
+    <w:r><w:t xml:space="preserve"> text</w:t></w:r>
+  </w:p>
+</w:body></w:document>`
+
+func TestParseDocxRevisions(t *testing.T) {
+	revisions, paragraphs, err := parseDocxRevisions(buildDocxWithDocument(t, testDocxRevisions))
+	if err != nil {
+		t.Fatalf("parse: %v", err)
+	}
+	want := []Revision{
+		{Type: RevisionInsertion, Author: "Alice", Date: time.Date(2024, 3, 1, 9, 30, 0, 0, time.UTC), Text: "brown "},
+		{Type: RevisionDeletion, Author: "Bob", Date: time.Date(2024, 3, 2, 10, 0, 0, 0, time.UTC), Text: " jumps"},
+		{Type: RevisionFormat, Author: "Carol", Text: "Bold"},
+	}
+	if len(revisions) != len(want) {
+		t.Fatalf("revisions = %+v", revisions)
+	}
+	for i := range want {
+		if revisions[i] != want[i] {
+			t.Errorf("revision %d = %+v, want %+v", i, revisions[i], want[i])
+		}
+	}
+	if len(paragraphs) != 2 || paragraphs[0] != (docxParagraphText{"The quick brown fox.", "The quick fox jumps.", "The quick brown fox jumps."}) {
+		t.Fatalf("paragraphs = %+v", paragraphs)
+	}
+}
+
+func TestApplyRevisions(t *testing.T) {
+	src := &documentSource{data: buildDocxWithDocument(t, testDocxRevisions)}
+	extracted := "Title\n\nThe quick brown fox.\n\nBold text\n"
+	for _, tc := range []struct {
+		mode, want string
+	}{
+		{TrackedChangesAccepted, extracted},
+		{TrackedChangesRejected, "Title\n\nThe quick fox jumps.\n\nBold text\n"},
+		{TrackedChangesOriginal, "Title\n\nThe quick brown fox jumps.\n\nBold text\n"},
+	} {
+		result := &ExtractionResult{MimeType: mimeTypeDOCX, Content: extracted}
+		config := NewExtractionConfig(WithTrackedChangesMode(tc.mode))
+		if err := applyRevisions(result, config, src); err != nil {
+			t.Fatalf("%s: %v", tc.mode, err)
+		}
+		if result.Content != tc.want || result.Revisions != nil {
+			t.Errorf("%s: content %q, revisions %v", tc.mode, result.Content, result.Revisions)
+		}
+	}
+
+	result := &ExtractionResult{MimeType: mimeTypeDOCX, Content: extracted}
+	if err := applyRevisions(result, NewExtractionConfig(WithExtractRevisions(true)), src); err != nil {
+		t.Fatal(err)
+	}
+	if len(result.Revisions) != 3 || result.Content != extracted {
+		t.Fatalf("unexpected result %+v", result)
+	}
+
+	if err := validateConfig(NewExtractionConfig(WithTrackedChangesMode("final"))); err == nil {
+		t.Fatal("expected an unknown tracked changes mode to fail validation")
+	}
+}
@@ -0,0 +1,53 @@
+package kreuzberg
+
+import (
+	"encoding/json"
+	"strconv"
+)
+
+// applyPageRotations fills result.PageRotations with the clockwise rotation, in degrees,
+// that auto-rotate applied to each page or image to OCR it upright. The native OCR finds
+// the rotation from the orientation of the text, so pages scanned sideways or upside down
+// are reported whatever the /Rotate entry of a PDF page says. Pages that needed no
+// rotation, and pages that were not OCR'd, are omitted.
+func applyPageRotations(result *ExtractionResult) {
+	for page, degrees := range ocrRotations(result.Metadata) {
+		addPageRotation(result, page, degrees)
+	}
+	delete(result.Metadata.Additional, "page_rotations")
+	delete(result.Metadata.Additional, "auto_rotation")
+}
+
+// ocrRotations returns the rotations the native OCR reports in metadata, keyed by page
+// number: "page_rotations" for the pages of a PDF, and "auto_rotation" for an image, which
+// is page 1.
+func ocrRotations(metadata Metadata) map[int]int {
+	rotations := make(map[int]int)
+	if raw, ok := metadata.Additional["page_rotations"]; ok {
+		var pages map[string]int
+		if json.Unmarshal(raw, &pages) == nil {
+			for page, degrees := range pages {
+				if n, err := strconv.Atoi(page); err == nil && degrees != 0 {
+					rotations[n] = degrees
+				}
+			}
+		}
+	}
+	if raw, ok := metadata.Additional["auto_rotation"]; ok {
+		var degrees string
+		if json.Unmarshal(raw, &degrees) == nil {
+			if n, err := strconv.Atoi(degrees); err == nil && n != 0 {
+				rotations[1] = n
+			}
+		}
+	}
+	return rotations
+}
+
+// addPageRotation records on result that page was rotated by degrees to be OCR'd.
+func addPageRotation(result *ExtractionResult, page, degrees int) {
+	if result.PageRotations == nil {
+		result.PageRotations = make(map[int]int)
+	}
+	result.PageRotations[page] = degrees
+}
@@ -0,0 +1,89 @@
+package kreuzberg
+
+import (
+	"bytes"
+	"encoding/json"
+	"reflect"
+	"testing"
+)
+
+func TestPageRotations(t *testing.T) {
+	result := &ExtractionResult{MimeType: "application/pdf", Metadata: Metadata{Additional: map[string]json.RawMessage{
+		"page_rotations": json.RawMessage(`{"2":90,"5":270}`),
+	}}}
+	applyPageRotations(result)
+	if want := map[int]int{2: 90, 5: 270}; !reflect.DeepEqual(result.PageRotations, want) {
+		t.Fatalf("PageRotations = %v, want %v", result.PageRotations, want)
+	}
+	if _, ok := result.Metadata.Additional["page_rotations"]; ok {
+		t.Fatal("expected page_rotations to be removed from the metadata")
+	}
+
+	result = &ExtractionResult{MimeType: "image/png", Metadata: Metadata{Additional: map[string]json.RawMessage{
+		"auto_rotation": json.RawMessage(`"180"`),
+	}}}
+	applyPageRotations(result)
+	if want := map[int]int{1: 180}; !reflect.DeepEqual(result.PageRotations, want) {
+		t.Fatalf("image PageRotations = %v, want %v", result.PageRotations, want)
+	}
+
+	result = &ExtractionResult{MimeType: "application/pdf"}
+	applyPageRotations(result)
+	if result.PageRotations != nil {
+		t.Fatalf("expected no rotations without OCR, got %v", result.PageRotations)
+	}
+
+	result = &ExtractionResult{PageRotations: map[int]int{2: 90}, SampledPages: []int{3, 7}}
+	applySampledPageNumbers(result)
+	if want := map[int]int{7: 90}; !reflect.DeepEqual(result.PageRotations, want) {
+		t.Fatalf("sampled PageRotations = %v, want %v", result.PageRotations, want)
+	}
+}
+
+// rotateCounterClockwise turns frame a quarter turn counter-clockwise.
+func rotateCounterClockwise(frame tiffTestFrame) tiffTestFrame {
+	rotated := tiffTestFrame{width: frame.height, height: frame.width, pixels: make([]byte, len(frame.pixels))}
+	for y := 0; y < frame.height; y++ {
+		for x := 0; x < frame.width; x++ {
+			rotated.pixels[(frame.width-1-x)*rotated.width+y] = frame.pixels[y*frame.width+x]
+		}
+	}
+	return rotated
+}
+
+// stackFrames draws frames one below the other on a white canvas as wide as the widest.
+func stackFrames(frames ...tiffTestFrame) tiffTestFrame {
+	var stacked tiffTestFrame
+	for _, frame := range frames {
+		stacked.width = max(stacked.width, frame.width)
+		stacked.height += frame.height
+	}
+	stacked.pixels = bytes.Repeat([]byte{0xff}, stacked.width*stacked.height)
+	top := 0
+	for _, frame := range frames {
+		for y := 0; y < frame.height; y++ {
+			copy(stacked.pixels[(top+y)*stacked.width:], frame.pixels[y*frame.width:(y+1)*frame.width])
+		}
+		top += frame.height
+	}
+	return stacked
+}
+
+func TestAutoRotateSidewaysScan(t *testing.T) {
+	page := stackFrames(renderWord("PARABOLA HALL"), renderWord("LABOR PAROL"), renderWord("HOLLA BAR"),
+		renderWord("OVAL HARBOR"), renderWord("PAROLA BALL"))
+	data := encodeTIFF(rotateCounterClockwise(page))
+	config := NewExtractionConfig(WithOCR(WithOCRBackend("tesseract"), WithTesseract(
+		WithTesseractPreprocessing(WithAutoRotate(true)),
+	)))
+	if !tessdataInstalled(config.OCR, "osd") {
+		t.Skip("osd.traineddata is not installed")
+	}
+	result, err := ExtractBytesSync(data, "image/tiff", config)
+	if err != nil {
+		t.Skipf("TIFF OCR unavailable: %v", err)
+	}
+	if want := map[int]int{1: 90}; !reflect.DeepEqual(result.PageRotations, want) {
+		t.Fatalf("PageRotations = %v, want %v", result.PageRotations, want)
+	}
+}
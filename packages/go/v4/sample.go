@@ -0,0 +1,338 @@
+package kreuzberg
+
+import (
+	"bytes"
+	"math/rand/v2"
+	"slices"
+)
+
+// samplePages returns the page count and strategy of PageConfig.SamplePages, or false when
+// sampling is not configured.
+func samplePages(config *ExtractionConfig) (int, string, bool) {
+	if config == nil || config.Pages == nil || config.Pages.SamplePages == nil || *config.Pages.SamplePages < 1 {
+		return 0, "", false
+	}
+	strategy := SampleFirst
+	if config.Pages.SampleStrategy != nil && *config.Pages.SampleStrategy != "" {
+		strategy = *config.Pages.SampleStrategy
+	}
+	return *config.Pages.SamplePages, strategy, true
+}
+
+// sampleDocument reduces a PDF or TIFF to the pages selected by PageConfig.SamplePages. It
+// returns the document to extract and the 1-based numbers of the sampled pages in the
+// original document, or data unchanged and nil when the document is not sampled: sampling
+// is off, the document has no more pages than the sample size, or its pages cannot be
+// selected (encrypted PDFs and other formats).
+//
+// PDFs are rewritten with an incremental update that replaces the page tree with one
+// holding only the sampled pages, so the native library never parses the others. A PDF
+// whose structure or page tree cannot be read, or which cannot be rewritten, fails with a
+// ParsingError rather than being extracted in full. TIFF frames are OCR'd separately anyway, so only the
+// sampled ones are (see applyTIFFPages).
+func sampleDocument(data []byte, config *ExtractionConfig) ([]byte, []int, error) {
+	n, strategy, ok := samplePages(config)
+	if !ok {
+		return data, nil, nil
+	}
+	if !bytes.HasPrefix(data, []byte("%PDF-")) {
+		count, ok := documentPageCount(data)
+		if !ok || count <= n {
+			return data, nil, nil
+		}
+		return data, samplePageNumbers(count, n, strategy, config.Pages.SampleSeed), nil
+	}
+
+	doc, err := parsePDFDocument(data)
+	if err != nil {
+		return nil, nil, newParsingErrorWithContext("failed to read PDF structure for page sampling", err, ErrorCodeParsing, nil)
+	}
+	if doc.encrypted {
+		return data, nil, nil
+	}
+	count, ok := pdfPageCount(doc)
+	if !ok {
+		return nil, nil, newParsingErrorWithContext("failed to read the PDF page tree for page sampling", nil, ErrorCodeParsing, nil)
+	}
+	if count <= n {
+		return data, nil, nil
+	}
+	pages := samplePageNumbers(count, n, strategy, config.Pages.SampleSeed)
+	sampled, ok := pdfWithPages(data, doc, pages)
+	if !ok {
+		return nil, nil, newParsingErrorWithContext("failed to select the sampled pages of the PDF", nil, ErrorCodeParsing, nil)
+	}
+	return sampled, pages, nil
+}
+
+// sampleBatchItems applies sampleDocument to each item, returning the items to extract and
+// the sampled page numbers of each, nil for items that are not sampled.
+func sampleBatchItems(items []BytesWithMime, config *ExtractionConfig) ([]BytesWithMime, [][]int, error) {
+	if _, _, ok := samplePages(config); !ok {
+		return items, nil, nil
+	}
+	out := make([]BytesWithMime, len(items))
+	pages := make([][]int, len(items))
+	for i, item := range items {
+		data, sampled, err := sampleDocument(item.Data, config)
+		if err != nil {
+			return nil, nil, err
+		}
+		out[i] = BytesWithMime{Data: data, MimeType: item.MimeType}
+		pages[i] = sampled
+	}
+	return out, pages, nil
+}
+
+// readSampledFile reads the file at path when page sampling applies to it, returning the
+// sampled document and its MIME type.
+func readSampledFile(path string, config *ExtractionConfig) ([]byte, string, bool) {
+	if _, _, ok := samplePages(config); !ok {
+		return nil, "", false
+	}
+	return readPagedDocument(path)
+}
+
+// samplePageNumbers picks n of count pages, in ascending order. "first" takes pages 1 to n;
+// "uniform" spreads them evenly from the first page to the last; "random" draws them with
+// a generator seeded by seed, or by a random seed when seed is nil.
+func samplePageNumbers(count, n int, strategy string, seed *int64) []int {
+	n = min(n, count)
+	pages := make([]int, 0, n)
+	switch strategy {
+	case SampleUniform:
+		for i := range n {
+			page := 1
+			if n > 1 {
+				page = 1 + i*(count-1)/(n-1)
+			}
+			pages = append(pages, page)
+		}
+	case SampleRandom:
+		var rng *rand.Rand
+		if seed != nil {
+			rng = rand.New(rand.NewPCG(uint64(*seed), 0))
+		} else {
+			rng = rand.New(rand.NewPCG(rand.Uint64(), rand.Uint64()))
+		}
+		for _, index := range rng.Perm(count)[:n] {
+			pages = append(pages, index+1)
+		}
+		slices.Sort(pages)
+	default:
+		for page := 1; page <= n; page++ {
+			pages = append(pages, page)
+		}
+	}
+	return pages
+}
+
+// pdfWithPages appends an incremental update to data that redefines the root of the page
+// tree to hold only pages, given as 1-based numbers. Attributes the pages inherited from the
+// old root are copied to the new one; attributes inherited from intermediate nodes are still
+// found through the pages' /Parent entries. doc is data parsed.
+func pdfWithPages(data []byte, doc *pdfDocument, pages []int) ([]byte, bool) {
+	if doc.encrypted || len(doc.trailers) == 0 {
+		return nil, false
+	}
+	trailer := doc.trailers[len(doc.trailers)-1]
+	rootRef, ok := trailer["Root"].(pdfRef)
+	if !ok {
+		return nil, false
+	}
+	treeRef, ok := doc.dict(rootRef)["Pages"].(pdfRef)
+	if !ok {
+		return nil, false
+	}
+	tree := doc.dict(treeRef)
+	all := doc.pages()
+	var kids pdfArray
+	for _, page := range pages {
+		if page > len(all) || all[page-1].objectNumber == 0 {
+			return nil, false
+		}
+		kids = append(kids, pdfRef{num: all[page-1].objectNumber})
+	}
+
+	root := pdfDict{"Type": pdfName("Pages"), "Kids": kids, "Count": float64(len(kids))}
+	for _, key := range []string{"MediaBox", "CropBox", "Rotate", "Resources"} {
+		if value, ok := tree[key]; ok {
+			root[key] = value
+		}
+	}
+	return appendPDFUpdate(data, doc, []pdfObject{{ref: treeRef, value: root}})
+}
+
+// pdfPageDocument returns a PDF holding only page of doc, which is data parsed, with just
+// the objects the page draws on, so a page of a large scan is extracted without copying the
+// rest of the file. Objects keep their numbers and, outside object streams, their bytes.
+// References to other pages, such as link destinations, are left dangling.
+func pdfPageDocument(data []byte, doc *pdfDocument, page pdfPage) ([]byte, bool) {
+	if doc.encrypted || page.objectNumber == 0 {
+		return nil, false
+	}
+	dict := pdfDict{}
+	for key, value := range page.dict {
+		if key != "Parent" {
+			dict[key] = value
+		}
+	}
+	// Attributes inherited from the page tree move onto the page.
+	if _, ok := dict["Resources"]; !ok && page.resources != nil {
+		dict["Resources"] = page.resources
+	}
+	if _, ok := dict["MediaBox"]; !ok {
+		dict["MediaBox"] = pdfArray{page.mediaBox[0], page.mediaBox[1], page.mediaBox[2], page.mediaBox[3]}
+	}
+	if _, ok := dict["CropBox"]; !ok && page.cropBox != nil {
+		dict["CropBox"] = pdfArray{page.cropBox[0], page.cropBox[1], page.cropBox[2], page.cropBox[3]}
+	}
+	if _, ok := dict["Rotate"]; !ok && page.rotate != 0 {
+		dict["Rotate"] = float64(page.rotate)
+	}
+
+	gens := map[int]int{page.objectNumber: 0}
+	var walk func(value any)
+	walk = func(value any) {
+		switch v := value.(type) {
+		case pdfRef:
+			object := doc.object(v.num)
+			if _, seen := gens[v.num]; seen || object == nil {
+				return
+			}
+			if object, ok := object.(pdfDict); ok && (object.name("Type") == "Page" || object.name("Type") == "Pages") {
+				return
+			}
+			gens[v.num] = v.gen
+			walk(object)
+		case pdfDict:
+			for _, item := range v {
+				walk(item)
+			}
+		case pdfArray:
+			for _, item := range v {
+				walk(item)
+			}
+		case *pdfStream:
+			walk(v.dict)
+		}
+	}
+	walk(dict)
+
+	next := doc.nextObjectNumber()
+	catalog, tree := pdfRef{num: next}, pdfRef{num: next + 1}
+	dict["Parent"] = tree
+	w := &pdfWriter{}
+	w.buf.WriteString("%PDF-1.7\n%\xe2\xe3\xcf\xd3\n")
+	w.object(pdfObject{ref: catalog, value: pdfDict{"Type": pdfName("Catalog"), "Pages": tree}})
+	w.object(pdfObject{ref: tree, value: pdfDict{"Type": pdfName("Pages"), "Kids": pdfArray{pdfRef{num: page.objectNumber}}, "Count": 1.0}})
+	w.object(pdfObject{ref: pdfRef{num: page.objectNumber}, value: dict})
+	nums := make([]int, 0, len(gens))
+	for num := range gens {
+		if num != page.objectNumber {
+			nums = append(nums, num)
+		}
+	}
+	slices.Sort(nums)
+	for _, num := range nums {
+		ref := pdfRef{num: num, gen: gens[num]}
+		if span, ok := doc.spans[num]; ok {
+			w.object(pdfObject{ref: ref, value: data[span[0]:span[1]]})
+		} else {
+			w.object(pdfObject{ref: ref, value: doc.object(num)})
+		}
+	}
+	w.xref(pdfDict{"Size": float64(next + 2), "Root": catalog}, true)
+	return w.buf.Bytes(), true
+}
+
+// applySampledPageNumbers renumbers the pages of a sampled extraction, which are numbered
+// 1 to n, with their numbers in the original document.
+func applySampledPageNumbers(result *ExtractionResult) {
+	sampled := result.SampledPages
+	if len(sampled) == 0 {
+		return
+	}
+	page := func(n int) int {
+		if n < 1 || n > len(sampled) {
+			return n
+		}
+		return sampled[n-1]
+	}
+	page64 := func(n uint64) uint64 { return uint64(page(int(n))) }
+	tables := func(tables []Table) {
+		for i := range tables {
+			tables[i].PageNumber = page(tables[i].PageNumber)
+		}
+	}
+	images := func(images []ExtractedImage) {
+		for i := range images {
+			if images[i].PageNumber != nil {
+				n := page(*images[i].PageNumber)
+				images[i].PageNumber = &n
+			}
+		}
+	}
+
+	if ps := result.Metadata.PageStructure; ps != nil {
+		for i := range ps.Boundaries {
+			ps.Boundaries[i].PageNumber = page64(ps.Boundaries[i].PageNumber)
+		}
+		for i := range ps.Pages {
+			ps.Pages[i].Number = page64(ps.Pages[i].Number)
+		}
+		for i := range ps.PageSizes {
+			ps.PageSizes[i].PageNumber = page(ps.PageSizes[i].PageNumber)
+		}
+	}
+	for i := range result.Pages {
+		result.Pages[i].PageNumber = page64(result.Pages[i].PageNumber)
+		tables(result.Pages[i].Tables)
+		images(result.Pages[i].Images)
+	}
+	tables(result.Tables)
+	images(result.Images)
+	images(result.PageImages)
+	for i := range result.PageHashes {
+		result.PageHashes[i].PageNumber = page(result.PageHashes[i].PageNumber)
+	}
+	for i := range result.Chunks {
+		meta := &result.Chunks[i].Metadata
+		if meta.FirstPage != nil {
+			first := page64(*meta.FirstPage)
+			meta.FirstPage = &first
+		}
+		if meta.LastPage != nil {
+			last := page64(*meta.LastPage)
+			meta.LastPage = &last
+		}
+	}
+	for i := range result.Annotations {
+		result.Annotations[i].PageNumber = page(result.Annotations[i].PageNumber)
+	}
+	for i := range result.ColoredSpans {
+		result.ColoredSpans[i].PageNumber = page(result.ColoredSpans[i].PageNumber)
+	}
+	for i := range result.Barcodes {
+		result.Barcodes[i].PageNumber = page(result.Barcodes[i].PageNumber)
+	}
+	for i := range result.OffsetMap {
+		result.OffsetMap[i].Page = page(result.OffsetMap[i].Page)
+	}
+	if len(result.PageRotations) > 0 {
+		rotations := make(map[int]int, len(result.PageRotations))
+		for n, degrees := range result.PageRotations {
+			rotations[page(n)] = degrees
+		}
+		result.PageRotations = rotations
+	}
+	for i := range result.Blocks {
+		result.Blocks[i].PageNumber = page(result.Blocks[i].PageNumber)
+	}
+	for i := range result.Warnings {
+		result.Warnings[i].PageNumber = page(result.Warnings[i].PageNumber)
+	}
+	for i := range result.textPositions {
+		result.textPositions[i].page = page(result.textPositions[i].page)
+	}
+}
@@ -0,0 +1,264 @@
+package kreuzberg
+
+import (
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"slices"
+	"strings"
+	"testing"
+)
+
+// testSamplePDF returns a PDF of pages pages whose page tree has an intermediate node and
+// whose only text on page n is "Page n".
+func testSamplePDF(pages int) []byte {
+	objects := []string{
+		`<< /Type /Catalog /Pages 2 0 R >>`,
+		fmt.Sprintf(`<< /Type /Pages /Kids [3 0 R] /Count %d /MediaBox [0 0 300 400] /Resources << /Font << /F1 4 0 R >> >> >>`, pages),
+		"",
+		`<< /Type /Font /Subtype /Type1 /BaseFont /Helvetica >>`,
+	}
+	var kids []string
+	for page := 1; page <= pages; page++ {
+		kids = append(kids, fmt.Sprintf("%d 0 R", len(objects)+1))
+		objects = append(objects,
+			fmt.Sprintf(`<< /Type /Page /Parent 3 0 R /Contents %d 0 R >>`, len(objects)+2),
+			testPDFStream(fmt.Sprintf("BT /F1 12 Tf 72 700 Td (Page %d) Tj ET", page), false))
+	}
+	objects[2] = fmt.Sprintf(`<< /Type /Pages /Parent 2 0 R /Kids [%s] /Count %d >>`, strings.Join(kids, " "), pages)
+	data := buildTestPDF(objects...)
+	return append(data[:len(data)-len("%%EOF\n")], "startxref\n0\n%%EOF\n"...)
+}
+
+func TestSamplePageNumbers(t *testing.T) {
+	seed := int64(42)
+	cases := []struct {
+		count, n int
+		strategy string
+		want     []int
+	}{
+		{10, 3, SampleFirst, []int{1, 2, 3}},
+		{10, 4, SampleUniform, []int{1, 4, 7, 10}},
+		{1000, 1, SampleUniform, []int{1}},
+		{3, 5, SampleUniform, []int{1, 2, 3}},
+	}
+	for _, tc := range cases {
+		if got := samplePageNumbers(tc.count, tc.n, tc.strategy, &seed); !slices.Equal(got, tc.want) {
+			t.Errorf("samplePageNumbers(%d, %d, %q) = %v, want %v", tc.count, tc.n, tc.strategy, got, tc.want)
+		}
+	}
+
+	random := samplePageNumbers(1000, 8, SampleRandom, &seed)
+	if !slices.Equal(random, samplePageNumbers(1000, 8, SampleRandom, &seed)) {
+		t.Fatal("seeded random samples must be reproducible")
+	}
+	if len(random) != 8 || !slices.IsSorted(random) || len(slices.Compact(slices.Clone(random))) != 8 || random[0] < 1 || random[7] > 1000 {
+		t.Fatalf("random sample %v is not 8 distinct sorted pages of 1000", random)
+	}
+}
+
+func TestSampleDocumentPDF(t *testing.T) {
+	data := testSamplePDF(6)
+	config := NewExtractionConfig(WithPages(WithSamplePages(3, SampleUniform)))
+
+	sampled, pages, err := sampleDocument(data, config)
+	if err != nil {
+		t.Fatalf("sampleDocument failed: %v", err)
+	}
+	if !slices.Equal(pages, []int{1, 3, 6}) {
+		t.Fatalf("sampled pages %v, want [1 3 6]", pages)
+	}
+	if count, ok := documentPageCount(sampled); !ok || count != 3 {
+		t.Fatalf("sampled document has %d pages (%v), want 3", count, ok)
+	}
+	texts, ok := mustParsePDF(t, sampled).rawText()
+	if !ok || !slices.Equal(texts, []string{"Page 1", "Page 3", "Page 6"}) {
+		t.Fatalf("sampled page texts = %q, %v", texts, ok)
+	}
+	doc := mustParsePDF(t, sampled)
+	if size := doc.pages()[1].mediaBox; size != [4]float64{0, 0, 300, 400} {
+		t.Fatalf("inherited MediaBox lost: %v", size)
+	}
+	if !strings.HasSuffix(string(sampled), "%%EOF\n") || !strings.Contains(string(sampled[len(data):]), "/Prev 0/Root 1 0 R/Size 17") {
+		t.Fatalf("expected an incremental update chained to the previous xref:\n%s", sampled[len(data):])
+	}
+
+	for _, unsampled := range []*ExtractionConfig{
+		NewExtractionConfig(),
+		NewExtractionConfig(WithPages(WithSamplePages(6, SampleFirst))),
+		NewExtractionConfig(WithPages(WithSamplePages(10, SampleRandom))),
+	} {
+		if out, pages, err := sampleDocument(data, unsampled); err != nil || pages != nil || len(out) != len(data) {
+			t.Fatalf("expected the document to be extracted in full, got pages %v (%v)", pages, err)
+		}
+	}
+}
+
+// TestSampleDocumentUnreadablePDF verifies that a PDF the Go reader refuses fails instead
+// of being extracted without sampling.
+func TestSampleDocumentUnreadablePDF(t *testing.T) {
+	data := buildTestPDF(strings.Repeat("[", 1<<16))
+	config := NewExtractionConfig(WithPages(WithSamplePages(1, SampleFirst)))
+	out, pages, err := sampleDocument(data, config)
+	var parsing *ParsingError
+	if !errors.As(err, &parsing) || out != nil || pages != nil {
+		t.Fatalf("expected a ParsingError, got %v (pages %v)", err, pages)
+	}
+	if _, err := ExtractBytesSync(data, "application/pdf", config); !errors.As(err, &parsing) {
+		t.Fatalf("expected ExtractBytesSync to fail with a ParsingError, got %v", err)
+	}
+
+	// A readable PDF without a page tree cannot be sampled either.
+	noPages := buildTestPDF(`<< /Type /Catalog >>`)
+	if _, _, err := sampleDocument(noPages, config); !errors.As(err, &parsing) {
+		t.Fatalf("expected a ParsingError for a PDF without pages, got %v", err)
+	}
+}
+
+func TestSampledPageNumbers(t *testing.T) {
+	one, two := uint64(1), uint64(2)
+	imagePage := 2
+	result := &ExtractionResult{
+		SampledPages: []int{4, 9},
+		Metadata: Metadata{PageStructure: &PageStructure{
+			Boundaries: []PageBoundary{{PageNumber: 1}, {PageNumber: 2}},
+			PageSizes:  []PageSize{{PageNumber: 1}, {PageNumber: 2}},
+		}},
+		Pages:    []PageContent{{PageNumber: 1}, {PageNumber: 2, Tables: []Table{{PageNumber: 2}}}},
+		Tables:   []Table{{PageNumber: 2}},
+		Images:   []ExtractedImage{{PageNumber: &imagePage}},
+		Chunks:   []Chunk{{Metadata: ChunkMetadata{FirstPage: &one, LastPage: &two}}},
+		Warnings: []ExtractionWarning{{PageNumber: 1}, {}},
+	}
+	applySampledPageNumbers(result)
+
+	ps := result.Metadata.PageStructure
+	if ps.Boundaries[1].PageNumber != 9 || ps.PageSizes[0].PageNumber != 4 || result.Pages[1].PageNumber != 9 ||
+		result.Pages[1].Tables[0].PageNumber != 9 || result.Tables[0].PageNumber != 9 || *result.Images[0].PageNumber != 9 {
+		t.Fatalf("pages not renumbered: %+v", result)
+	}
+	if imagePage != 2 {
+		t.Fatal("image page pointers must not be modified in place")
+	}
+	if meta := result.Chunks[0].Metadata; *meta.FirstPage != 4 || *meta.LastPage != 9 {
+		t.Fatalf("chunk pages = %d-%d, want 4-9", *meta.FirstPage, *meta.LastPage)
+	}
+	if result.Warnings[0].PageNumber != 4 || result.Warnings[1].PageNumber != 0 {
+		t.Fatalf("warning pages = %+v", result.Warnings)
+	}
+}
+
+func TestSamplePagesValidation(t *testing.T) {
+	for _, config := range []*ExtractionConfig{
+		NewExtractionConfig(WithPages(WithSamplePages(0, SampleFirst))),
+		NewExtractionConfig(WithPages(WithSamplePages(2, "middle"))),
+	} {
+		var validation *ValidationError
+		if err := validateConfig(config); !errors.As(err, &validation) {
+			t.Fatalf("expected ValidationError, got %v", err)
+		}
+	}
+	if err := validateConfig(NewExtractionConfig(WithPages(WithSamplePages(2, SampleRandom), WithSampleSeed(7)))); err != nil {
+		t.Fatalf("valid sampling rejected: %v", err)
+	}
+}
+
+func TestSamplePagesExtraction(t *testing.T) {
+	if _, err := ExtractBytesSync([]byte("probe"), "text/plain", nil); err != nil {
+		t.Skipf("native library unavailable: %v", err)
+	}
+	config := NewExtractionConfig(WithRawText(true), WithUseCache(false),
+		WithPages(WithSamplePages(2, SampleUniform), WithMaxPages(2)))
+	result, err := ExtractBytesSync(testSamplePDF(5), "application/pdf", config)
+	if err != nil {
+		t.Fatalf("ExtractBytesSync: %v", err)
+	}
+	if !slices.Equal(result.SampledPages, []int{1, 5}) || !strings.Contains(result.Content, "Page 5") || strings.Contains(result.Content, "Page 3") {
+		t.Fatalf("sampled %v with content %q", result.SampledPages, result.Content)
+	}
+	if boundaries := result.Metadata.PageStructure.Boundaries; boundaries[1].PageNumber != 5 {
+		t.Fatalf("boundaries not numbered by original page: %+v", boundaries)
+	}
+}
+
+func TestSampleBatchItems(t *testing.T) {
+	items := []BytesWithMime{
+		{Data: testSamplePDF(6), MimeType: "application/pdf"},
+		{Data: testSamplePDF(2), MimeType: "application/pdf"},
+		{Data: []byte("text"), MimeType: "text/plain"},
+	}
+	out, sampled, err := sampleBatchItems(items, NewExtractionConfig(WithPages(WithSamplePages(3, SampleUniform))))
+	if err != nil {
+		t.Fatalf("sampleBatchItems failed: %v", err)
+	}
+	if !slices.Equal(sampled[0], []int{1, 3, 6}) || sampled[1] != nil || sampled[2] != nil {
+		t.Fatalf("sampled pages %v", sampled)
+	}
+	if count, _ := documentPageCount(out[0].Data); count != 3 || len(out[1].Data) != len(items[1].Data) {
+		t.Fatalf("expected only the first document to be sampled, got %d pages", count)
+	}
+	if out, sampled, _ := sampleBatchItems(items, NewExtractionConfig()); sampled != nil || &out[0] != &items[0] {
+		t.Fatal("expected items to be returned as is without sampling")
+	}
+}
+
+func TestSamplePagesBatchExtraction(t *testing.T) {
+	if _, err := ExtractBytesSync([]byte("probe"), "text/plain", nil); err != nil {
+		t.Skipf("native library unavailable: %v", err)
+	}
+	config := NewExtractionConfig(WithRawText(true), WithUseCache(false),
+		WithPages(WithSamplePages(2, SampleUniform), WithMaxPages(2)))
+	check := func(name string, results []*ExtractionResult, err error) {
+		t.Helper()
+		if err != nil {
+			t.Fatalf("%s: %v", name, err)
+		}
+		if len(results) != 2 || !slices.Equal(results[0].SampledPages, []int{1, 5}) || strings.Contains(results[0].Content, "Page 3") ||
+			results[1].SampledPages != nil || !strings.Contains(results[1].Content, "Page 2") {
+			t.Fatalf("%s: unexpected results %+v", name, results)
+		}
+	}
+
+	results, err := BatchExtractBytesSync([]BytesWithMime{
+		{Data: testSamplePDF(5), MimeType: "application/pdf"},
+		{Data: testSamplePDF(2), MimeType: "application/pdf"},
+	}, config)
+	check("BatchExtractBytesSync", results, err)
+
+	dir := t.TempDir()
+	paths := []string{filepath.Join(dir, "five.pdf"), filepath.Join(dir, "two.pdf")}
+	for i, pages := range []int{5, 2} {
+		if err := os.WriteFile(paths[i], testSamplePDF(pages), 0o600); err != nil {
+			t.Fatal(err)
+		}
+	}
+	results, err = BatchExtractFilesSync(paths, config)
+	check("BatchExtractFilesSync", results, err)
+}
+
+func TestPDFPageDocument(t *testing.T) {
+	data := testSamplePDF(4)
+	doc := mustParsePDF(t, data)
+	pages := doc.pages()
+
+	single, ok := pdfPageDocument(data, doc, pages[2])
+	if !ok {
+		t.Fatal("expected a single-page document")
+	}
+	page := mustParsePDF(t, single)
+	if got := page.pages(); len(got) != 1 || got[0].mediaBox != [4]float64{0, 0, 300, 400} || got[0].resources == nil {
+		t.Fatalf("single page lost its inherited attributes: %+v", got)
+	}
+	texts, ok := page.rawText()
+	if !ok || !slices.Equal(texts, []string{"Page 3"}) {
+		t.Fatalf("single page texts = %q, %v", texts, ok)
+	}
+	// Only the page, its content stream and font, and the new catalog and page tree are kept.
+	if len(page.objects) != 5 || len(single) >= len(data) {
+		t.Fatalf("single page has %d objects and %d bytes, document %d bytes", len(page.objects), len(single), len(data))
+	}
+	if _, ok := pdfPageDocument(data, &pdfDocument{encrypted: true}, pages[0]); ok {
+		t.Fatal("expected encrypted documents not to be split")
+	}
+}
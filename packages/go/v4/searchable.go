@@ -0,0 +1,778 @@
+package kreuzberg
+
+import (
+	"bytes"
+	"compress/zlib"
+	"context"
+	"encoding/binary"
+	"errors"
+	"fmt"
+	"hash/crc32"
+	"html"
+	"image"
+	"image/color"
+	"io"
+	"os"
+	"regexp"
+	"slices"
+	"strconv"
+	"strings"
+	"unicode/utf16"
+)
+
+// searchableFontName is the resource name of the text layer font added to pages.
+const searchableFontName = "KzOCR"
+
+var (
+	hocrPageBBox = regexp.MustCompile(`class=['"]ocr_page['"][^>]*?bbox (\d+) (\d+) (\d+) (\d+)`)
+	hocrWord     = regexp.MustCompile(`(?s)<span class=['"]ocrx_word['"][^>]*title=['"]bbox (\d+) (\d+) (\d+) (\d+)[^>]*>(.*?)</span>`)
+	htmlTag      = regexp.MustCompile(`<[^>]*>`)
+)
+
+// pageRecognizer OCRs a page image, returning its words and the size of the image in the
+// coordinates of the word boxes.
+type pageRecognizer func(ctx context.Context, img []byte, mimeType string) ([]ocrWord, float64, float64, error)
+
+// ocrWord is a word recognized on a page image, with its box in pixels of the image as
+// [left, top, right, bottom].
+type ocrWord struct {
+	text string
+	box  [4]float64
+}
+
+// ExtractFileToSearchablePDF OCRs the scanned document at srcPath and writes it to dstPath
+// as a PDF with an invisible text layer over the page images, so the text can be searched,
+// selected and copied in PDF viewers.
+//
+// PDF input is kept as it is and the text layer is appended as an incremental update, so
+// page sizes, images and any existing text are preserved. Every page whose content draws an
+// image XObject is OCR'd; the largest image is taken to cover the page. Pages without a
+// decodable image (JPEG, or Flate-compressed gray or RGB) are left unchanged. PNG, JPEG,
+// GIF and TIFF input becomes a PDF with a page per image, or per frame of a multi-page TIFF,
+// sized by the image's resolution, or 72 dpi when the file does not record one. TIFF frames
+// must be stored in strips: uncompressed, Deflate and PackBits frames are supported, and
+// CCITT Group 3 and 4, LZW and JPEG frames when they are a single strip. Other images
+// return an UnsupportedFormatError.
+//
+// OCR uses the Tesseract backend and the OCR settings of config, with English when config
+// has none. A page whose OCR exceeds OCRConfig.PageTimeoutMillis gets no text layer. Text
+// outside the Basic Multilingual Plane is written as U+FFFD.
+func ExtractFileToSearchablePDF(srcPath, dstPath string, config *ExtractionConfig) error {
+	return ExtractFileToSearchablePDFWithContext(context.Background(), srcPath, dstPath, config)
+}
+
+// ExtractFileToSearchablePDFWithContext is ExtractFileToSearchablePDF, returning ctx.Err()
+// once ctx is done. Native OCR cannot be interrupted, so ctx is checked before each page is
+// OCR'd and nothing is written to dstPath when it ends.
+func ExtractFileToSearchablePDFWithContext(ctx context.Context, srcPath, dstPath string, config *ExtractionConfig) error {
+	if srcPath == "" || dstPath == "" {
+		return newValidationErrorWithContext("source and destination paths are required", nil, ErrorCodeValidation, nil)
+	}
+	if err := validateConfig(config); err != nil {
+		return err
+	}
+	ocrConfig := &ExtractionConfig{OCR: &OCRConfig{Backend: "tesseract"}}
+	if config != nil {
+		ocrConfig.UseCache = config.UseCache
+		if config.OCR != nil {
+			if config.OCR.Backend != "" && !strings.EqualFold(config.OCR.Backend, "tesseract") {
+				return newValidationErrorWithContext("searchable PDF output requires the tesseract OCR backend", nil, ErrorCodeValidation, nil)
+			}
+			ocrConfig.OCR = config.OCR
+		}
+	}
+
+	// #nosec G304 -- srcPath is the document the caller asked us to convert
+	data, err := os.ReadFile(srcPath)
+	if err != nil {
+		return newIOErrorWithContext("failed to read source document", err, ErrorCodeIo, nil)
+	}
+	recognize := func(ctx context.Context, img []byte, mimeType string) ([]ocrWord, float64, float64, error) {
+		if err := ctx.Err(); err != nil {
+			return nil, 0, 0, err
+		}
+		return ocrPageWords(ctx, img, mimeType, ocrConfig)
+	}
+	var out []byte
+	if bytes.HasPrefix(data, []byte("%PDF-")) {
+		out, err = searchablePDFFromPDF(ctx, data, recognize)
+	} else {
+		out, err = searchablePDFFromImage(ctx, data, recognize)
+	}
+	if err != nil {
+		return err
+	}
+	// #nosec G306 -- the output is a document for the caller, not a secret
+	if err := os.WriteFile(dstPath, out, 0o644); err != nil {
+		return newIOErrorWithContext("failed to write searchable PDF", err, ErrorCodeIo, nil)
+	}
+	return nil
+}
+
+// searchablePDFFromPDF appends a text layer to every scanned page of a PDF.
+func searchablePDFFromPDF(ctx context.Context, data []byte, recognize pageRecognizer) ([]byte, error) {
+	doc, err := parsePDFDocument(data)
+	if err != nil {
+		return nil, newParsingErrorWithContext("failed to read PDF structure", err, ErrorCodeParsing, nil)
+	}
+	if doc.encrypted {
+		return nil, newEncryptedDocumentErrorWithContext("pdf", false, "pdf document is password-protected", nil, ErrorCodeParsing, nil)
+	}
+	pages := doc.pages()
+	if len(pages) == 0 {
+		return nil, newParsingErrorWithContext("PDF has no readable page tree", nil, ErrorCodeParsing, nil)
+	}
+
+	next := doc.nextObjectNumber()
+	fontRef, objects := searchableFontObjects(next)
+	next += len(objects)
+	fontObjects := len(objects)
+	for _, page := range pages {
+		if page.objectNumber == 0 {
+			continue
+		}
+		img, mimeType, ok := pdfPageImage(doc, page)
+		if !ok {
+			continue
+		}
+		words, width, height, err := recognize(ctx, img, mimeType)
+		if err != nil {
+			return nil, err
+		}
+		if len(words) == 0 {
+			continue
+		}
+		box := page.mediaBox
+		if page.cropBox != nil {
+			box = *page.cropBox
+		}
+
+		saveRef, layerRef := pdfRef{num: next}, pdfRef{num: next + 1}
+		next += 2
+		contents := pdfArray{saveRef}
+		switch existing := doc.resolve(page.dict["Contents"]).(type) {
+		case nil:
+		case pdfArray:
+			contents = append(contents, existing...)
+		default:
+			contents = append(contents, page.dict["Contents"])
+		}
+		contents = append(contents, layerRef)
+
+		resources := pdfDict{}
+		for key, value := range page.resources {
+			resources[key] = value
+		}
+		fonts := pdfDict{}
+		for key, value := range doc.dict(resources["Font"]) {
+			fonts[key] = value
+		}
+		fonts[searchableFontName] = fontRef
+		resources["Font"] = fonts
+
+		dict := pdfDict{}
+		for key, value := range page.dict {
+			dict[key] = value
+		}
+		dict["Contents"] = contents
+		dict["Resources"] = resources
+
+		layer := append([]byte("Q\n"), ocrTextLayer(words, width, height, box)...)
+		objects = append(objects,
+			pdfObject{ref: saveRef, value: &pdfStream{dict: pdfDict{}, raw: []byte("q")}},
+			pdfObject{ref: layerRef, value: &pdfStream{dict: pdfDict{}, raw: layer}},
+			pdfObject{ref: pdfRef{num: page.objectNumber}, value: dict},
+		)
+	}
+	if len(objects) == fontObjects {
+		return data, nil
+	}
+	out, ok := appendPDFUpdate(data, doc, objects)
+	if !ok {
+		return nil, newParsingErrorWithContext("PDF has no readable trailer", nil, ErrorCodeParsing, nil)
+	}
+	return out, nil
+}
+
+// searchableImage is a page image of a searchable PDF made from image input.
+type searchableImage struct {
+	// xobject draws the image on the page.
+	xobject *pdfStream
+	// width and height are the size of the image in pixels, and dpiX and dpiY its resolution.
+	width, height int
+	dpiX, dpiY    float64
+	// data is the image file passed to OCR.
+	data     []byte
+	mimeType string
+}
+
+// searchablePDFFromImage builds a PDF showing a PNG, JPEG or GIF image, or every frame of a
+// TIFF, each on a page of its own under its text layer.
+func searchablePDFFromImage(ctx context.Context, data []byte, recognize pageRecognizer) ([]byte, error) {
+	var images []searchableImage
+	if order, offsets := tiffIFDOffsets(data); len(offsets) > 0 {
+		for i, offset := range offsets {
+			img, ok := tiffImage(data, order, offset)
+			if !ok {
+				return nil, newUnsupportedFormatErrorWithContext("image/tiff",
+					fmt.Sprintf("searchable PDF output does not support the compression or layout of TIFF frame %d", i+1), nil, ErrorCodeUnsupportedFormat, nil)
+			}
+			images = append(images, img)
+		}
+	} else {
+		img, err := decodedImage(data)
+		if err != nil {
+			return nil, err
+		}
+		images = append(images, img)
+	}
+
+	fontRef, objects := searchableFontObjects(3)
+	first := 3 + len(objects)
+	kids := make(pdfArray, 0, len(images))
+	for i, img := range images {
+		words, width, height, err := recognize(ctx, img.data, img.mimeType)
+		if err != nil {
+			return nil, err
+		}
+		box := [4]float64{0, 0, float64(img.width) * 72 / img.dpiX, float64(img.height) * 72 / img.dpiY}
+
+		var content bytes.Buffer
+		fmt.Fprintf(&content, "q %s 0 0 %s 0 0 cm /Im0 Do Q\n", pdfNumber(box[2]), pdfNumber(box[3]))
+		content.Write(ocrTextLayer(words, width, height, box))
+
+		imageRef, contentRef, pageRef := pdfRef{num: first + 3*i}, pdfRef{num: first + 3*i + 1}, pdfRef{num: first + 3*i + 2}
+		kids = append(kids, pageRef)
+		objects = append(objects,
+			pdfObject{ref: imageRef, value: img.xobject},
+			pdfObject{ref: contentRef, value: &pdfStream{dict: pdfDict{}, raw: content.Bytes()}},
+			pdfObject{ref: pageRef, value: pdfDict{
+				"Type":     pdfName("Page"),
+				"Parent":   pdfRef{num: 2},
+				"MediaBox": pdfArray{0.0, 0.0, box[2], box[3]},
+				"Contents": contentRef,
+				"Resources": pdfDict{
+					"XObject": pdfDict{"Im0": imageRef},
+					"Font":    pdfDict{searchableFontName: fontRef},
+				},
+			}},
+		)
+	}
+	objects = append(objects,
+		pdfObject{ref: pdfRef{num: 1}, value: pdfDict{"Type": pdfName("Catalog"), "Pages": pdfRef{num: 2}}},
+		pdfObject{ref: pdfRef{num: 2}, value: pdfDict{"Type": pdfName("Pages"), "Kids": kids, "Count": float64(len(kids))}},
+	)
+
+	w := &pdfWriter{}
+	w.buf.WriteString("%PDF-1.7\n%\xe2\xe3\xcf\xd3\n")
+	for _, obj := range objects {
+		w.object(obj)
+	}
+	w.xref(pdfDict{"Size": float64(len(objects) + 1), "Root": pdfRef{num: 1}}, true)
+	return w.buf.Bytes(), nil
+}
+
+// decodedImage returns a PNG, JPEG or GIF image as a page image. JPEG data is embedded as it
+// is; other images are decoded and compressed again with Flate.
+func decodedImage(data []byte) (searchableImage, error) {
+	cfg, format, err := image.DecodeConfig(bytes.NewReader(data))
+	if err != nil {
+		return searchableImage{}, newUnsupportedFormatErrorWithContext("", "searchable PDF output supports PDF, PNG, JPEG, GIF and TIFF input", err, ErrorCodeUnsupportedFormat, nil)
+	}
+	xobject := &pdfStream{dict: pdfDict{
+		"Type": pdfName("XObject"), "Subtype": pdfName("Image"),
+		"Width": float64(cfg.Width), "Height": float64(cfg.Height), "BitsPerComponent": float64(8),
+	}}
+	switch format {
+	case "jpeg":
+		colorSpace := "DeviceRGB"
+		switch cfg.ColorModel {
+		case color.GrayModel:
+			colorSpace = "DeviceGray"
+		case color.CMYKModel:
+			colorSpace = "DeviceCMYK"
+		}
+		xobject.dict["ColorSpace"] = pdfName(colorSpace)
+		xobject.dict["Filter"] = pdfName("DCTDecode")
+		xobject.raw = data
+	default:
+		img, _, err := image.Decode(bytes.NewReader(data))
+		if err != nil {
+			return searchableImage{}, newParsingErrorWithContext("failed to decode image", err, ErrorCodeParsing, nil)
+		}
+		bounds := img.Bounds()
+		pixels := make([]byte, 0, 3*bounds.Dx()*bounds.Dy())
+		for y := bounds.Min.Y; y < bounds.Max.Y; y++ {
+			for x := bounds.Min.X; x < bounds.Max.X; x++ {
+				c := color.RGBAModel.Convert(img.At(x, y)).(color.RGBA)
+				pixels = append(pixels, c.R, c.G, c.B)
+			}
+		}
+		xobject.dict["ColorSpace"] = pdfName("DeviceRGB")
+		xobject.dict["Filter"] = pdfName("FlateDecode")
+		xobject.raw = zlibCompress(pixels)
+	}
+	dpiX, dpiY := imageResolution(data, format)
+	return searchableImage{xobject: xobject, width: cfg.Width, height: cfg.Height, dpiX: dpiX, dpiY: dpiY,
+		data: data, mimeType: "image/" + format}, nil
+}
+
+// tiffImage returns the frame of a TIFF whose IFD starts at offset as a page image, OCR'd
+// as a single-frame TIFF. CCITT Group 3 and 4, LZW and JPEG frames of a single strip are
+// embedded as they are, with the matching PDF filter; uncompressed, Deflate and PackBits
+// frames are decoded, palette colors looked up, and compressed again with Flate. ok is
+// false for tiled frames, frames with separate color planes, extra samples or more than 8
+// bits per sample, and other compressions.
+func tiffImage(data []byte, order binary.ByteOrder, offset uint32) (img searchableImage, ok bool) {
+	tags := tiffTags(data, order, offset)
+	value := func(tag uint16, fallback uint64) uint64 {
+		if values := tags[tag]; len(values) > 0 {
+			return values[0]
+		}
+		return fallback
+	}
+	width, height := value(256, 0), value(257, 0)
+	bits, samples, compression := value(258, 1), value(277, 1), value(259, 1)
+	photometric := value(262, 1)
+	if compression == 3 || compression == 4 {
+		photometric = value(262, 0)
+	}
+	starts, lengths := tags[273], tags[279]
+	if width == 0 || height == 0 || width > 1<<16 || height > 1<<16 || len(starts) == 0 || len(starts) != len(lengths) ||
+		(bits != 1 && bits != 2 && bits != 4 && bits != 8) || value(284, 1) != 1 || len(tags[338]) > 0 || value(266, 1) != 1 {
+		return img, false
+	}
+	strips := make([][]byte, len(starts))
+	for i := range starts {
+		if starts[i]+lengths[i] > uint64(len(data)) {
+			return img, false
+		}
+		strips[i] = data[starts[i] : starts[i]+lengths[i]]
+	}
+	colorSpace := pdfName("DeviceGray")
+	switch {
+	case (photometric == 0 || photometric == 1) && samples == 1:
+	case photometric == 2 && samples == 3 && bits == 8:
+		colorSpace = pdfName("DeviceRGB")
+	case photometric == 3 && samples == 1 && len(tags[320]) == 3<<bits:
+	case photometric == 6 && samples == 3 && compression == 7:
+		colorSpace = pdfName("DeviceRGB")
+	default:
+		return img, false
+	}
+
+	xobject := &pdfStream{dict: pdfDict{
+		"Type": pdfName("XObject"), "Subtype": pdfName("Image"), "Width": float64(width), "Height": float64(height),
+		"BitsPerComponent": float64(bits), "ColorSpace": colorSpace,
+	}}
+	// Samples are decoded here unless the strip can be embedded as it is.
+	passThrough := len(strips) == 1 && photometric != 3
+	switch {
+	case (compression == 3 || compression == 4) && passThrough && bits == 1:
+		options := value(292, 0)
+		k := -1.0
+		if compression == 3 {
+			k = float64(options & 1)
+		}
+		xobject.dict["Filter"] = pdfName("CCITTFaxDecode")
+		xobject.dict["DecodeParms"] = pdfDict{"K": k, "Columns": float64(width), "Rows": float64(height),
+			"BlackIs1": photometric == 1, "EncodedByteAlign": compression == 3 && options&4 != 0}
+		xobject.raw = strips[0]
+	case compression == 5 && passThrough:
+		xobject.dict["Filter"] = pdfName("LZWDecode")
+		if value(317, 1) == 2 {
+			xobject.dict["DecodeParms"] = pdfDict{"Predictor": 2.0, "Colors": float64(samples), "BitsPerComponent": float64(bits), "Columns": float64(width)}
+		}
+		xobject.raw = strips[0]
+	case compression == 7 && passThrough && bits == 8 && len(tags[347]) == 0:
+		xobject.dict["Filter"] = pdfName("DCTDecode")
+		xobject.raw = strips[0]
+	case compression == 1 || compression == 8 || compression == 32946 || compression == 32773:
+		rowBytes := (width*samples*bits + 7) / 8
+		var pixels []byte
+		for _, strip := range strips {
+			switch compression {
+			case 8, 32946:
+				r, err := zlib.NewReader(bytes.NewReader(strip))
+				if err != nil {
+					return img, false
+				}
+				strip, err = io.ReadAll(io.LimitReader(r, int64(rowBytes*height)))
+				if err != nil {
+					return img, false
+				}
+			case 32773:
+				strip = unpackBits(strip)
+			}
+			pixels = append(pixels, strip...)
+		}
+		if uint64(len(pixels)) < rowBytes*height {
+			return img, false
+		}
+		pixels = pixels[:rowBytes*height]
+		if predictor := value(317, 1); predictor == 2 && compression != 1 {
+			xobject.dict["DecodeParms"] = pdfDict{"Predictor": 2.0, "Colors": float64(samples), "BitsPerComponent": float64(bits), "Columns": float64(width)}
+		} else if predictor != 1 && compression != 1 {
+			return img, false
+		}
+		if photometric == 3 {
+			if _, ok := xobject.dict["DecodeParms"]; ok {
+				return img, false
+			}
+			pixels = tiffPaletteRGB(pixels, tags[320], int(width), int(height), int(bits))
+			xobject.dict["ColorSpace"] = pdfName("DeviceRGB")
+			xobject.dict["BitsPerComponent"] = 8.0
+		}
+		xobject.dict["Filter"] = pdfName("FlateDecode")
+		xobject.raw = zlibCompress(pixels)
+	default:
+		return img, false
+	}
+	if photometric == 0 && compression != 3 && compression != 4 {
+		xobject.dict["Decode"] = pdfArray{1.0, 0.0}
+	}
+
+	frame, ok := tiffFrame(data, order, offset)
+	if !ok {
+		return img, false
+	}
+	dpiX, dpiY := tiffResolution(tags)
+	return searchableImage{xobject: xobject, width: int(width), height: int(height), dpiX: dpiX, dpiY: dpiY,
+		data: frame, mimeType: "image/tiff"}, true
+}
+
+// tiffPaletteRGB looks up the palette indexes of a frame of width by height pixels, packed
+// bits to a sample, in colorMap, the TIFF ColorMap of 16-bit red, green and blue tables.
+func tiffPaletteRGB(pixels []byte, colorMap []uint64, width, height, bits int) []byte {
+	colors := len(colorMap) / 3
+	rowBytes := (width*bits + 7) / 8
+	rgb := make([]byte, 0, 3*width*height)
+	for y := range height {
+		row := pixels[y*rowBytes : (y+1)*rowBytes]
+		for x := range width {
+			bit := x * bits
+			index := int(row[bit/8]>>(8-bits-bit%8)) & (1<<bits - 1)
+			rgb = append(rgb, byte(colorMap[index]>>8), byte(colorMap[colors+index]>>8), byte(colorMap[2*colors+index]>>8))
+		}
+	}
+	return rgb
+}
+
+// unpackBits decodes PackBits run-length encoded data.
+func unpackBits(data []byte) []byte {
+	var out []byte
+	for i := 0; i < len(data); {
+		n := int(int8(data[i]))
+		i++
+		switch {
+		case n >= 0:
+			end := min(len(data), i+n+1)
+			out = append(out, data[i:end]...)
+			i = end
+		case n != -128 && i < len(data):
+			out = append(out, bytes.Repeat(data[i:i+1], 1-n)...)
+			i++
+		}
+	}
+	return out
+}
+
+// tiffResolution returns the resolution of a TIFF frame in dots per inch from its tags, or
+// 72 dpi when it records none.
+func tiffResolution(tags map[uint16][]uint64) (float64, float64) {
+	unit := uint64(2)
+	if values := tags[296]; len(values) > 0 {
+		unit = values[0]
+	}
+	resolution := func(tag uint16) float64 {
+		values := tags[tag]
+		if len(values) < 2 || values[0] == 0 || values[1] == 0 {
+			return 0
+		}
+		return float64(values[0]) / float64(values[1])
+	}
+	x, y := resolution(282), resolution(283)
+	switch {
+	case x == 0 || y == 0:
+	case unit == 2:
+		return x, y
+	case unit == 3:
+		return x * 2.54, y * 2.54
+	}
+	return 72, 72
+}
+
+// ocrPageWords OCRs a page image and returns its words together with the size of the
+// image in the coordinates of the word boxes. A page whose OCR times out has no words.
+func ocrPageWords(ctx context.Context, img []byte, mimeType string, config *ExtractionConfig) ([]ocrWord, float64, float64, error) {
+	hocrConfig := hocrExtractionConfig(config)
+	page, err := extractPageWithTimeout(ctx, img, mimeType, hocrConfig, ocrPageTimeout(hocrConfig))
+	if errors.Is(err, errOCRPageTimeout) {
+		return nil, 0, 0, nil
+	}
+	if err != nil {
+		return nil, 0, 0, err
+	}
+	words, width, height := parseHOCRWords(page.Content)
+	return words, width, height, nil
+}
+
+// parseHOCRWords returns the ocrx_word elements of one hOCR page and the page size.
+func parseHOCRWords(hocr string) ([]ocrWord, float64, float64) {
+	match := hocrPageBBox.FindStringSubmatch(hocr)
+	if match == nil {
+		return nil, 0, 0
+	}
+	width, _ := strconv.ParseFloat(match[3], 64)
+	height, _ := strconv.ParseFloat(match[4], 64)
+	if width <= 0 || height <= 0 {
+		return nil, 0, 0
+	}
+	var words []ocrWord
+	for _, m := range hocrWord.FindAllStringSubmatch(hocr, -1) {
+		text := strings.TrimSpace(html.UnescapeString(htmlTag.ReplaceAllString(m[5], "")))
+		if text == "" {
+			continue
+		}
+		var word ocrWord
+		word.text = text
+		for i := range word.box {
+			word.box[i], _ = strconv.ParseFloat(m[i+1], 64)
+		}
+		words = append(words, word)
+	}
+	return words, width, height
+}
+
+// ocrTextLayer returns content stream operators drawing words invisibly (text rendering
+// mode 3) over the page box, where the page image of width by height pixels is shown.
+// Each word is scaled horizontally to the width of its box so selections match the image.
+func ocrTextLayer(words []ocrWord, width, height float64, box [4]float64) []byte {
+	scaleX, scaleY := (box[2]-box[0])/width, (box[3]-box[1])/height
+	var out bytes.Buffer
+	out.WriteString("BT\n3 Tr\n")
+	for _, word := range words {
+		units := utf16.Encode([]rune(word.text))
+		size := (word.box[3] - word.box[1]) * scaleY
+		wordWidth := (word.box[2] - word.box[0]) * scaleX
+		if size <= 0 || wordWidth <= 0 {
+			continue
+		}
+		// Every glyph of the text layer font is half an em wide.
+		stretch := 100 * wordWidth / (float64(len(units)) * size / 2)
+		fmt.Fprintf(&out, "/%s %s Tf %s Tz 1 0 0 1 %s %s Tm <", searchableFontName, pdfNumber(size), pdfNumber(stretch),
+			pdfNumber(box[0]+word.box[0]*scaleX), pdfNumber(box[3]-word.box[3]*scaleY))
+		for _, unit := range units {
+			if utf16.IsSurrogate(rune(unit)) {
+				unit = 0xFFFD
+			}
+			fmt.Fprintf(&out, "%04X", unit)
+		}
+		out.WriteString("> Tj\n")
+	}
+	out.WriteString("ET\n")
+	return out.Bytes()
+}
+
+// searchableFontObjects returns the text layer font, numbered from first: a composite font
+// whose character codes are the UTF-16 code units of the text. It has no glyphs, which
+// invisible text does not need, and maps every code back to Unicode for text extraction.
+func searchableFontObjects(first int) (pdfRef, []pdfObject) {
+	fontRef := pdfRef{num: first}
+	cidFontRef, descriptorRef, cmapRef := pdfRef{num: first + 1}, pdfRef{num: first + 2}, pdfRef{num: first + 3}
+
+	var cmap bytes.Buffer
+	cmap.WriteString("/CIDInit /ProcSet findresource begin\n12 dict begin\nbegincmap\n" +
+		"/CIDSystemInfo << /Registry (Adobe) /Ordering (UCS) /Supplement 0 >> def\n" +
+		"/CMapName /Adobe-Identity-UCS def\n/CMapType 2 def\n" +
+		"1 begincodespacerange\n<0000> <FFFF>\nendcodespacerange\n")
+	var ranges []int
+	for high := range 0x100 {
+		if high < 0xD8 || high > 0xDF {
+			ranges = append(ranges, high)
+		}
+	}
+	for chunk := range slices.Chunk(ranges, 100) {
+		fmt.Fprintf(&cmap, "%d beginbfrange\n", len(chunk))
+		for _, high := range chunk {
+			fmt.Fprintf(&cmap, "<%02X00> <%02XFF> <%02X00>\n", high, high, high)
+		}
+		cmap.WriteString("endbfrange\n")
+	}
+	cmap.WriteString("endcmap\nCMapName currentdict /CMap defineresource pop\nend\nend\n")
+
+	return fontRef, []pdfObject{
+		{ref: fontRef, value: []byte(fmt.Sprintf("<</Type /Font /Subtype /Type0 /BaseFont /GlyphLessFont /Encoding /Identity-H /DescendantFonts [%d 0 R] /ToUnicode %d 0 R>>", cidFontRef.num, cmapRef.num))},
+		{ref: cidFontRef, value: []byte(fmt.Sprintf("<</Type /Font /Subtype /CIDFontType2 /BaseFont /GlyphLessFont /CIDSystemInfo <</Registry (Adobe) /Ordering (Identity) /Supplement 0>> /FontDescriptor %d 0 R /DW 500 /CIDToGIDMap /Identity>>", descriptorRef.num))},
+		{ref: descriptorRef, value: []byte("<</Type /FontDescriptor /FontName /GlyphLessFont /Flags 5 /FontBBox [0 0 500 1000] /ItalicAngle 0 /Ascent 1000 /Descent 0 /CapHeight 1000 /StemV 80>>")},
+		{ref: cmapRef, value: &pdfStream{dict: pdfDict{}, raw: cmap.Bytes()}},
+	}
+}
+
+// pdfPageImage returns the largest image XObject of a page as an image file the OCR
+// backend can read. JPEG images are returned as they are; Flate-compressed and
+// uncompressed gray and RGB images are converted to PNG.
+func pdfPageImage(doc *pdfDocument, page pdfPage) ([]byte, string, bool) {
+	xobjects := doc.dict(page.resources["XObject"])
+	names := make([]string, 0, len(xobjects))
+	for name := range xobjects {
+		names = append(names, name)
+	}
+	slices.Sort(names)
+	var best *pdfStream
+	bestArea := 0.0
+	for _, name := range names {
+		stream, ok := doc.stream(xobjects[name])
+		if !ok || stream.dict.name("Subtype") != "Image" {
+			continue
+		}
+		width, _ := doc.resolve(stream.dict["Width"]).(float64)
+		height, _ := doc.resolve(stream.dict["Height"]).(float64)
+		if area := width * height; area > bestArea {
+			best, bestArea = stream, area
+		}
+	}
+	if best == nil {
+		return nil, "", false
+	}
+	return pdfImageFile(doc, best)
+}
+
+// pdfImageFile converts an image XObject to a JPEG or PNG file.
+func pdfImageFile(doc *pdfDocument, stream *pdfStream) ([]byte, string, bool) {
+	filter := doc.resolve(stream.dict["Filter"])
+	if array, ok := filter.(pdfArray); ok && len(array) == 1 {
+		filter = doc.resolve(array[0])
+	}
+	if filter == pdfName("DCTDecode") {
+		return stream.raw, "image/jpeg", true
+	}
+	if filter != nil && filter != pdfName("FlateDecode") {
+		return nil, "", false
+	}
+	if mask, _ := doc.resolve(stream.dict["ImageMask"]).(bool); mask {
+		return nil, "", false
+	}
+
+	width, _ := doc.resolve(stream.dict["Width"]).(float64)
+	height, _ := doc.resolve(stream.dict["Height"]).(float64)
+	bits, _ := doc.resolve(stream.dict["BitsPerComponent"]).(float64)
+	components := 0
+	switch cs := doc.resolve(stream.dict["ColorSpace"]).(type) {
+	case pdfName:
+		switch cs {
+		case "DeviceGray", "CalGray":
+			components = 1
+		case "DeviceRGB", "CalRGB":
+			components = 3
+		}
+	case pdfArray:
+		if len(cs) == 2 && doc.resolve(cs[0]) == pdfName("ICCBased") {
+			if profile, ok := doc.stream(cs[1]); ok {
+				n, _ := doc.resolve(profile.dict["N"]).(float64)
+				if n == 1 || n == 3 {
+					components = int(n)
+				}
+			}
+		}
+	}
+	depth := int(bits)
+	validDepth := depth == 8 || depth == 16 || (components == 1 && (depth == 1 || depth == 2 || depth == 4))
+	if width < 1 || height < 1 || components == 0 || !validDepth {
+		return nil, "", false
+	}
+	colorType := byte(0)
+	if components == 3 {
+		colorType = 2
+	}
+
+	// Flate data with a PNG predictor is already a PNG image data stream.
+	predictor, _ := doc.resolve(doc.dict(stream.dict["DecodeParms"])["Predictor"]).(float64)
+	if filter == pdfName("FlateDecode") && predictor >= 10 {
+		return pngFile(int(width), int(height), depth, colorType, stream.raw), "image/png", true
+	}
+	if predictor > 1 {
+		return nil, "", false
+	}
+	pixels, ok := stream.decode()
+	rowBytes := (int(width)*components*depth + 7) / 8
+	if !ok || len(pixels) < rowBytes*int(height) {
+		return nil, "", false
+	}
+	rows := make([]byte, 0, (rowBytes+1)*int(height))
+	for y := range int(height) {
+		rows = append(rows, 0)
+		rows = append(rows, pixels[y*rowBytes:(y+1)*rowBytes]...)
+	}
+	return pngFile(int(width), int(height), depth, colorType, zlibCompress(rows)), "image/png", true
+}
+
+// pngFile assembles a PNG file from its header fields and zlib-compressed image data.
+func pngFile(width, height, depth int, colorType byte, idat []byte) []byte {
+	var out bytes.Buffer
+	out.WriteString("\x89PNG\r\n\x1a\n")
+	chunk := func(kind string, data []byte) {
+		_ = binary.Write(&out, binary.BigEndian, uint32(len(data)))
+		out.WriteString(kind)
+		out.Write(data)
+		crc := crc32.NewIEEE()
+		crc.Write([]byte(kind))
+		crc.Write(data)
+		_ = binary.Write(&out, binary.BigEndian, crc.Sum32())
+	}
+	header := binary.BigEndian.AppendUint32(nil, uint32(width))
+	header = binary.BigEndian.AppendUint32(header, uint32(height))
+	header = append(header, byte(depth), colorType, 0, 0, 0)
+	chunk("IHDR", header)
+	chunk("IDAT", idat)
+	chunk("IEND", nil)
+	return out.Bytes()
+}
+
+func zlibCompress(data []byte) []byte {
+	var buf bytes.Buffer
+	w := zlib.NewWriter(&buf)
+	_, _ = w.Write(data)
+	_ = w.Close()
+	return buf.Bytes()
+}
+
+// imageResolution returns the horizontal and vertical resolution in dots per inch recorded
+// in a PNG pHYs chunk or a JPEG JFIF header, or 72 dpi when there is none.
+func imageResolution(data []byte, format string) (float64, float64) {
+	switch format {
+	case "png":
+		for pos := 8; pos+12 <= len(data); {
+			length := int(binary.BigEndian.Uint32(data[pos:]))
+			kind := string(data[pos+4 : pos+8])
+			if kind == "pHYs" && length >= 9 && pos+8+9 <= len(data) {
+				body := data[pos+8:]
+				x, y := binary.BigEndian.Uint32(body), binary.BigEndian.Uint32(body[4:])
+				if body[8] == 1 && x > 0 && y > 0 {
+					return float64(x) * 0.0254, float64(y) * 0.0254
+				}
+			}
+			if kind == "IDAT" {
+				break
+			}
+			pos += 12 + length
+		}
+	case "jpeg":
+		if len(data) >= 18 && data[2] == 0xFF && data[3] == 0xE0 && string(data[6:11]) == "JFIF\x00" {
+			x, y := float64(binary.BigEndian.Uint16(data[14:])), float64(binary.BigEndian.Uint16(data[16:]))
+			switch {
+			case x == 0 || y == 0:
+			case data[13] == 1:
+				return x, y
+			case data[13] == 2:
+				return x * 2.54, y * 2.54
+			}
+		}
+	}
+	return 72, 72
+}
+
+// pdfNumber formats a coordinate for a content stream.
+func pdfNumber(v float64) string {
+	return strconv.FormatFloat(v, 'f', 2, 64)
+}
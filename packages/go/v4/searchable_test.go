@@ -0,0 +1,262 @@
+package kreuzberg
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"fmt"
+	"image"
+	"image/color"
+	"image/jpeg"
+	"image/png"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+// testScannedPDF wraps a JPEG in a one-page PDF without text, like a scanner writes.
+func testScannedPDF(t *testing.T, jpeg []byte) []byte {
+	t.Helper()
+	cfg, _, err := image.DecodeConfig(bytes.NewReader(jpeg))
+	if err != nil {
+		t.Fatal(err)
+	}
+	w := &pdfWriter{}
+	w.buf.WriteString("%PDF-1.4\n")
+	for _, obj := range []pdfObject{
+		{ref: pdfRef{num: 1}, value: pdfDict{"Type": pdfName("Catalog"), "Pages": pdfRef{num: 2}}},
+		{ref: pdfRef{num: 2}, value: pdfDict{"Type": pdfName("Pages"), "Kids": pdfArray{pdfRef{num: 3}}, "Count": 1.0,
+			"MediaBox": pdfArray{0.0, 0.0, float64(cfg.Width) / 2, float64(cfg.Height) / 2}}},
+		{ref: pdfRef{num: 3}, value: pdfDict{"Type": pdfName("Page"), "Parent": pdfRef{num: 2}, "Contents": pdfRef{num: 4},
+			"Resources": pdfDict{"XObject": pdfDict{"Im1": pdfRef{num: 5}}}}},
+		{ref: pdfRef{num: 4}, value: &pdfStream{dict: pdfDict{}, raw: []byte(
+			"q " + pdfNumber(float64(cfg.Width)/2) + " 0 0 " + pdfNumber(float64(cfg.Height)/2) + " 0 0 cm /Im1 Do Q")}},
+		{ref: pdfRef{num: 5}, value: &pdfStream{dict: pdfDict{"Type": pdfName("XObject"), "Subtype": pdfName("Image"),
+			"Width": float64(cfg.Width), "Height": float64(cfg.Height), "BitsPerComponent": 8.0,
+			"ColorSpace": pdfName("DeviceRGB"), "Filter": pdfName("DCTDecode")}, raw: jpeg}},
+	} {
+		w.object(obj)
+	}
+	w.xref(pdfDict{"Size": 6.0, "Root": pdfRef{num: 1}}, true)
+	return w.buf.Bytes()
+}
+
+func TestParseHOCRWords(t *testing.T) {
+	hocr := `<div class='ocr_page' id='page_1' title='image "x.png"; bbox 0 0 200 100; ppageno 0'>
+   <span class='ocrx_word' id='word_1_1' title='bbox 10 20 60 40; x_wconf 96'>Fish</span>
+   <span class='ocrx_word' id='word_1_2' title='bbox 70 20 120 40; x_wconf 91'><strong>&amp;</strong></span>
+   <span class='ocrx_word' id='word_1_3' title='bbox 130 20 150 40; x_wconf 10'> </span>
+  </div>`
+	words, width, height := parseHOCRWords(hocr)
+	if width != 200 || height != 100 || len(words) != 2 {
+		t.Fatalf("parseHOCRWords = %+v, %v, %v", words, width, height)
+	}
+	if words[0].text != "Fish" || words[0].box != [4]float64{10, 20, 60, 40} || words[1].text != "&" {
+		t.Fatalf("unexpected words %+v", words)
+	}
+
+	layer := string(ocrTextLayer(words, width, height, [4]float64{0, 0, 400, 200}))
+	// Fish is 100pt wide and 40pt high, drawn from (20, 120): 4 glyphs of 20pt at 40pt/2 each.
+	if !strings.Contains(layer, "3 Tr") || !strings.Contains(layer, "/KzOCR 40.00 Tf 125.00 Tz 1 0 0 1 20.00 120.00 Tm <0046006900730068> Tj") {
+		t.Fatalf("unexpected text layer:\n%s", layer)
+	}
+}
+
+func TestSearchablePDFTextLayer(t *testing.T) {
+	img := image.NewGray(image.Rect(0, 0, 200, 100))
+	for i := range img.Pix {
+		img.Pix[i] = 0xff
+	}
+	var jpg, pngData bytes.Buffer
+	if err := jpeg.Encode(&jpg, img, nil); err != nil {
+		t.Fatal(err)
+	}
+	if err := png.Encode(&pngData, img); err != nil {
+		t.Fatal(err)
+	}
+	var recognized []string
+	recognize := func(_ context.Context, data []byte, mimeType string) ([]ocrWord, float64, float64, error) {
+		recognized = append(recognized, mimeType)
+		return []ocrWord{{text: "Hello", box: [4]float64{10, 20, 60, 40}}, {text: "wörld", box: [4]float64{70, 20, 120, 40}}}, 200, 100, nil
+	}
+
+	out, err := searchablePDFFromPDF(context.Background(), testScannedPDF(t, jpg.Bytes()), recognize)
+	if err != nil {
+		t.Fatalf("searchablePDFFromPDF: %v", err)
+	}
+	doc := mustParsePDF(t, out)
+	pages, ok := doc.rawTextPages()
+	if !ok || len(pages) != 1 || !strings.Contains(pages[0].text, "Hello") || !strings.Contains(pages[0].text, "wörld") {
+		t.Fatalf("expected the text layer to be extractable, got %+v (%v)", pages, ok)
+	}
+	// The page is 100 by 50 points, half the image size, so Hello spans x 5 to 30.
+	if box := pages[0].spans[0].box; box[0] != 5 || box[2] != 30 {
+		t.Fatalf("unexpected position of the first word %v", box)
+	}
+	if len(recognized) != 1 || recognized[0] != "image/jpeg" {
+		t.Fatalf("expected the page JPEG to be recognized, got %v", recognized)
+	}
+
+	out, err = searchablePDFFromImage(context.Background(), pngData.Bytes(), recognize)
+	if err != nil {
+		t.Fatalf("searchablePDFFromImage: %v", err)
+	}
+	doc = mustParsePDF(t, out)
+	if got := doc.pages(); len(got) != 1 || got[0].mediaBox != [4]float64{0, 0, 200, 100} {
+		t.Fatalf("expected one 200x100pt page, got %+v", got)
+	}
+	if pages, ok := doc.rawTextPages(); !ok || !strings.Contains(pages[0].text, "Hello") {
+		t.Fatalf("expected the text layer to be extractable, got %+v (%v)", pages, ok)
+	}
+	if _, mimeType, ok := pdfPageImage(doc, doc.pages()[0]); !ok || mimeType != "image/png" {
+		t.Fatalf("expected the page image of the generated PDF to be readable, got %q", mimeType)
+	}
+}
+
+func TestSearchablePDFFromTIFF(t *testing.T) {
+	data := encodeTIFF(renderWord("AB"), renderWord("LLL"))
+	var recognized []string
+	recognize := func(_ context.Context, frame []byte, mimeType string) ([]ocrWord, float64, float64, error) {
+		if _, offsets := tiffIFDOffsets(frame); len(offsets) != 1 {
+			t.Fatalf("expected a single-frame TIFF to OCR, got %d frames", len(offsets))
+		}
+		recognized = append(recognized, mimeType)
+		return []ocrWord{{text: fmt.Sprintf("page%d", len(recognized)), box: [4]float64{40, 40, 100, 96}}}, 100, 100, nil
+	}
+	out, err := searchablePDFFromImage(context.Background(), data, recognize)
+	if err != nil {
+		t.Fatalf("searchablePDFFromImage: %v", err)
+	}
+	doc := mustParsePDF(t, out)
+	pages := doc.pages()
+	if len(pages) != 2 || pages[0].mediaBox != [4]float64{0, 0, 176, 136} || pages[1].mediaBox != [4]float64{0, 0, 224, 136} {
+		t.Fatalf("expected a page per frame at 72 dpi, got %+v", pages)
+	}
+	texts, ok := doc.rawTextPages()
+	if !ok || len(texts) != 2 || !strings.Contains(texts[0].text, "page1") || !strings.Contains(texts[1].text, "page2") {
+		t.Fatalf("expected a text layer on each page, got %+v (%v)", texts, ok)
+	}
+	if len(recognized) != 2 || recognized[0] != "image/tiff" {
+		t.Fatalf("expected both frames to be recognized, got %v", recognized)
+	}
+	img, mimeType, ok := pdfPageImage(doc, pages[1])
+	if !ok || mimeType != "image/png" {
+		t.Fatalf("expected the frame to be embedded as a readable image, got %q", mimeType)
+	}
+	decoded, err := png.Decode(bytes.NewReader(img))
+	if err != nil || decoded.Bounds().Dx() != 224 {
+		t.Fatalf("decode embedded frame: %v", err)
+	}
+
+	// A single LZW strip is embedded as it is; a frame without strips is not supported.
+	order, offsets := tiffIFDOffsets(data)
+	lzw := bytes.Clone(data)
+	for i := range int(order.Uint16(lzw[offsets[0]:])) {
+		entry := lzw[int(offsets[0])+2+i*12:]
+		if order.Uint16(entry) == 259 {
+			order.PutUint16(entry[8:], 5)
+		}
+	}
+	if _, ok := tiffImage(lzw, order, offsets[0]); !ok {
+		t.Fatal("expected a single LZW strip to be embedded")
+	}
+	order.PutUint16(lzw[offsets[0]+2+12*5:], 0xffff) // an unknown tag stands in for the strip offsets
+	_, err = searchablePDFFromImage(context.Background(), lzw, recognize)
+	var unsupported *UnsupportedFormatError
+	if !errors.As(err, &unsupported) {
+		t.Fatalf("expected an UnsupportedFormatError for a frame without strips, got %v", err)
+	}
+
+	if got := unpackBits([]byte{0xfe, 'a', 0x01, 'b', 'c', 0x80}); string(got) != "aaabc" {
+		t.Fatalf("unpackBits = %q", got)
+	}
+	if x, y := tiffResolution(map[uint16][]uint64{282: {600, 2}, 283: {300, 1}, 296: {2}}); x != 300 || y != 300 {
+		t.Fatalf("tiffResolution = %v, %v", x, y)
+	}
+}
+
+func TestExtractFileToSearchablePDFCanceled(t *testing.T) {
+	dir := t.TempDir()
+	src, dst := filepath.Join(dir, "scan.tiff"), filepath.Join(dir, "scan.pdf")
+	if err := os.WriteFile(src, encodeTIFF(renderWord("AB")), 0o600); err != nil {
+		t.Fatal(err)
+	}
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+	if err := ExtractFileToSearchablePDFWithContext(ctx, src, dst, nil); !errors.Is(err, context.Canceled) {
+		t.Fatalf("expected context.Canceled, got %v", err)
+	}
+	if _, err := os.Stat(dst); !os.IsNotExist(err) {
+		t.Fatalf("expected nothing to be written, got %v", err)
+	}
+}
+
+func TestPDFImageFileFlate(t *testing.T) {
+	doc := mustParsePDF(t, nil)
+	pixels := []byte{0, 128, 255, 255, 128, 0}
+	stream := &pdfStream{
+		dict: pdfDict{"Subtype": pdfName("Image"), "Width": 3.0, "Height": 2.0, "BitsPerComponent": 8.0,
+			"ColorSpace": pdfName("DeviceGray"), "Filter": pdfName("FlateDecode")},
+		raw: zlibCompress(pixels),
+	}
+	data, mimeType, ok := pdfImageFile(doc, stream)
+	if !ok || mimeType != "image/png" {
+		t.Fatalf("pdfImageFile = %v, %v", mimeType, ok)
+	}
+	img, err := png.Decode(bytes.NewReader(data))
+	if err != nil {
+		t.Fatalf("decode converted image: %v", err)
+	}
+	if got := color.GrayModel.Convert(img.At(1, 1)).(color.Gray).Y; got != 128 {
+		t.Fatalf("pixel (1, 1) = %d, want 128", got)
+	}
+}
+
+func TestExtractFileToSearchablePDF(t *testing.T) {
+	if _, err := ExtractBytesSync([]byte("probe"), "text/plain", nil); err != nil {
+		t.Skipf("native library unavailable: %v", err)
+	}
+	jpeg, err := os.ReadFile(getTestFilePath("images/ocr_image.jpg"))
+	if err != nil {
+		t.Skipf("test image unavailable: %v", err)
+	}
+	dir := t.TempDir()
+
+	scanned := filepath.Join(dir, "scanned.pdf")
+	if err := os.WriteFile(scanned, testScannedPDF(t, jpeg), 0o600); err != nil {
+		t.Fatal(err)
+	}
+	before, err := ExtractFileSync(scanned, nil)
+	if err != nil {
+		t.Fatalf("extract scanned PDF: %v", err)
+	}
+
+	searchable := filepath.Join(dir, "searchable.pdf")
+	if err := ExtractFileToSearchablePDF(scanned, searchable, nil); err != nil {
+		t.Fatalf("ExtractFileToSearchablePDF: %v", err)
+	}
+	after, err := ExtractFileSync(searchable, nil)
+	if err != nil {
+		t.Fatalf("extract searchable PDF: %v", err)
+	}
+	if len(strings.Fields(after.Content)) <= len(strings.Fields(before.Content)) {
+		t.Fatalf("expected the text layer to add words, before %q, after %q", before.Content, after.Content)
+	}
+
+	fromImage := filepath.Join(dir, "image.pdf")
+	if err := ExtractFileToSearchablePDF(getTestFilePath("images/test_hello_world.png"), fromImage, nil); err != nil {
+		t.Fatalf("ExtractFileToSearchablePDF from PNG: %v", err)
+	}
+	result, err := ExtractFileSync(fromImage, nil)
+	if err != nil {
+		t.Fatalf("extract PDF made from PNG: %v", err)
+	}
+	if !strings.Contains(strings.ToLower(result.Content), "hello") {
+		t.Fatalf("expected the text layer of the PNG to be extractable, got %q", result.Content)
+	}
+
+	if err := ExtractFileToSearchablePDF(scanned, searchable, NewExtractionConfig(WithOCR(WithOCRBackend("easyocr")))); err == nil {
+		t.Fatal("expected an error for a backend without hOCR")
+	}
+}
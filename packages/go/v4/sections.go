@@ -0,0 +1,149 @@
+package kreuzberg
+
+import (
+	"strings"
+)
+
+// Section is a heading found in the extracted content.
+type Section struct {
+	Title string
+	// Level is the heading depth, 1 for top-level headings.
+	Level int
+	// Offset is the byte offset of the heading line in Content.
+	Offset int
+}
+
+// Sections returns the headings of the document in content order. Headings are read from
+// Markdown or Djot content (ATX "#" headings and, for Markdown, underlined setext headings);
+// plain-text content has none. Lines inside fenced code blocks are ignored.
+func (r *ExtractionResult) Sections() []Section {
+	if r == nil {
+		return nil
+	}
+	var sections []Section
+	var fence string
+	prevStart, prevLine := -1, ""
+	for offset := 0; offset < len(r.Content); {
+		end := strings.IndexByte(r.Content[offset:], '\n')
+		if end < 0 {
+			end = len(r.Content)
+		} else {
+			end += offset
+		}
+		line := strings.TrimRight(r.Content[offset:end], "\r")
+		trimmed := strings.TrimSpace(line)
+		start := offset
+		offset = end + 1
+
+		if fence != "" {
+			if strings.HasPrefix(trimmed, fence) {
+				fence = ""
+			}
+			prevStart = -1
+			continue
+		}
+		if strings.HasPrefix(trimmed, "```") || strings.HasPrefix(trimmed, "~~~") {
+			fence = trimmed[:3]
+			prevStart = -1
+			continue
+		}
+
+		if title, level, ok := atxHeading(line); ok {
+			sections = append(sections, Section{Title: title, Level: level, Offset: start})
+			prevStart = -1
+			continue
+		}
+		if level := setextLevel(trimmed); level > 0 && prevStart >= 0 && strings.TrimSpace(prevLine) != "" {
+			sections = append(sections, Section{Title: strings.TrimSpace(prevLine), Level: level, Offset: prevStart})
+			prevStart = -1
+			continue
+		}
+		prevStart, prevLine = start, line
+	}
+	return sections
+}
+
+// atxHeading parses a "# Title" line with up to three spaces of indentation.
+func atxHeading(line string) (string, int, bool) {
+	indent := len(line) - len(strings.TrimLeft(line, " "))
+	if indent > 3 {
+		return "", 0, false
+	}
+	line = line[indent:]
+	level := 0
+	for level < len(line) && line[level] == '#' {
+		level++
+	}
+	if level == 0 || level > 6 {
+		return "", 0, false
+	}
+	rest := line[level:]
+	if rest != "" && rest[0] != ' ' && rest[0] != '\t' {
+		return "", 0, false
+	}
+	title := strings.TrimSpace(rest)
+	// Drop an optional closing sequence of '#'.
+	if trimmed := strings.TrimRight(title, "#"); trimmed == "" || strings.HasSuffix(trimmed, " ") {
+		title = strings.TrimSpace(trimmed)
+	}
+	if title == "" {
+		return "", 0, false
+	}
+	return title, level, true
+}
+
+// setextLevel reports the heading level of a setext underline ("===" or "---"), or 0.
+func setextLevel(trimmed string) int {
+	switch {
+	case trimmed == "":
+		return 0
+	case strings.Trim(trimmed, "=") == "":
+		return 1
+	case strings.Trim(trimmed, "-") == "" && len(trimmed) >= 2:
+		return 2
+	}
+	return 0
+}
+
+// applyChunkSectionTitles sets ChunkMetadata.SectionPath on every chunk to the headings
+// enclosing the chunk's start offset.
+func applyChunkSectionTitles(result *ExtractionResult, config *ExtractionConfig) {
+	if config.Chunking == nil || config.Chunking.SectionTitles == nil || !*config.Chunking.SectionTitles {
+		return
+	}
+	if len(result.Chunks) == 0 {
+		return
+	}
+	sections := result.Sections()
+	if len(sections) == 0 {
+		return
+	}
+
+	var path []Section
+	next, prevStart := 0, 0
+	for i := range result.Chunks {
+		start := int(result.Chunks[i].Metadata.ByteStart)
+		if start < prevStart {
+			// Chunks are normally in content order; rebuild the path if one is not.
+			path, next = nil, 0
+		}
+		prevStart = start
+		for next < len(sections) && sections[next].Offset <= start {
+			section := sections[next]
+			for len(path) > 0 && path[len(path)-1].Level >= section.Level {
+				path = path[:len(path)-1]
+			}
+			path = append(path, section)
+			next++
+		}
+		if len(path) == 0 {
+			result.Chunks[i].Metadata.SectionPath = nil
+			continue
+		}
+		titles := make([]string, len(path))
+		for j, section := range path {
+			titles[j] = section.Title
+		}
+		result.Chunks[i].Metadata.SectionPath = titles
+	}
+}
@@ -0,0 +1,67 @@
+package kreuzberg
+
+import (
+	"reflect"
+	"strings"
+	"testing"
+)
+
+const testSectionsContent = "# Chapter 1\n\nIntro text.\n\n" +
+	"# Chapter 2\n\n## 2.1 Methods\n\nWe measured.\n\n```\n# not a heading\n```\n\n" +
+	"Results\n-------\n\nNumbers.\n"
+
+func TestSections(t *testing.T) {
+	result := &ExtractionResult{Content: testSectionsContent}
+	got := result.Sections()
+	want := []Section{
+		{Title: "Chapter 1", Level: 1, Offset: 0},
+		{Title: "Chapter 2", Level: 1, Offset: strings.Index(testSectionsContent, "# Chapter 2")},
+		{Title: "2.1 Methods", Level: 2, Offset: strings.Index(testSectionsContent, "## 2.1")},
+		{Title: "Results", Level: 2, Offset: strings.Index(testSectionsContent, "Results")},
+	}
+	if !reflect.DeepEqual(got, want) {
+		t.Fatalf("Sections() = %+v, want %+v", got, want)
+	}
+
+	plain := &ExtractionResult{Content: "Just text.\nNo headings here."}
+	if sections := plain.Sections(); len(sections) != 0 {
+		t.Fatalf("expected no sections, got %+v", sections)
+	}
+}
+
+func TestChunkSectionTitles(t *testing.T) {
+	content := testSectionsContent
+	chunkAt := func(marker string) Chunk {
+		start := strings.Index(content, marker)
+		return Chunk{Content: marker, Metadata: ChunkMetadata{ByteStart: uint64(start), ByteEnd: uint64(start + len(marker))}}
+	}
+	result := &ExtractionResult{
+		Content: content,
+		Chunks:  []Chunk{chunkAt("# Chapter 1"), chunkAt("We measured."), chunkAt("Numbers."), chunkAt("Intro text.")},
+	}
+
+	applyChunkSectionTitles(result, NewExtractionConfig(WithChunking(WithChunkSectionTitles(true))))
+
+	want := [][]string{
+		{"Chapter 1"},
+		{"Chapter 2", "2.1 Methods"},
+		{"Chapter 2", "Results"},
+		{"Chapter 1"},
+	}
+	for i, chunk := range result.Chunks {
+		if !reflect.DeepEqual(chunk.Metadata.SectionPath, want[i]) {
+			t.Errorf("chunk %d SectionPath = %q, want %q", i, chunk.Metadata.SectionPath, want[i])
+		}
+	}
+}
+
+func TestChunkSectionTitlesDisabled(t *testing.T) {
+	result := &ExtractionResult{
+		Content: testSectionsContent,
+		Chunks:  []Chunk{{Content: "# Chapter 1"}},
+	}
+	applyChunkSectionTitles(result, NewExtractionConfig(WithChunking()))
+	if result.Chunks[0].Metadata.SectionPath != nil {
+		t.Fatalf("expected no section path, got %q", result.Chunks[0].Metadata.SectionPath)
+	}
+}
@@ -0,0 +1,40 @@
+package kreuzberg
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+	"testing"
+)
+
+func TestSelfTestPDFIsWellFormed(t *testing.T) {
+	doc := mustParsePDF(t, selfTestPDF)
+	if pages := doc.pages(); len(pages) != 1 {
+		t.Fatalf("expected 1 page, got %d", len(pages))
+	}
+	if !strings.Contains(string(selfTestPDF), "("+selfTestPhrase+")") {
+		t.Fatal("self-test PDF does not show the expected phrase")
+	}
+
+	// Every cross-reference entry must point at the start of its object.
+	data := string(selfTestPDF)
+	xref := data[strings.Index(data, "\nxref\n")+1:]
+	for i, line := range strings.Split(xref, "\n")[3:8] {
+		offset, err := strconv.Atoi(line[:10])
+		if err != nil {
+			t.Fatalf("xref entry %d: %v", i+1, err)
+		}
+		if want := fmt.Sprintf("%d 0 obj", i+1); !strings.HasPrefix(data[offset:], want) {
+			t.Errorf("xref entry %d points at %q, want %q", i+1, data[offset:offset+len(want)], want)
+		}
+	}
+}
+
+func TestSelfTest(t *testing.T) {
+	if _, err := ExtractBytesSync([]byte("probe"), "text/plain", nil); err != nil {
+		t.Skipf("native extraction unavailable: %v", err)
+	}
+	if err := SelfTest(); err != nil {
+		t.Fatalf("SelfTest failed: %v", err)
+	}
+}
@@ -0,0 +1,190 @@
+package kreuzberg
+
+import (
+	"strings"
+	"unicode"
+	"unicode/utf8"
+)
+
+// sentenceLanguages maps ISO 639-3 and 639-2/B codes to the ISO 639-1 codes that key
+// sentenceAbbreviations.
+var sentenceLanguages = map[string]string{
+	"eng": "en",
+	"deu": "de", "ger": "de",
+	"fra": "fr", "fre": "fr",
+	"spa": "es",
+	"ita": "it",
+	"por": "pt",
+	"nld": "nl", "dut": "nl",
+}
+
+// sentenceAbbreviations lists, per language, the lower-case abbreviations that are written
+// with a final period and are usually followed by more of the same sentence, such as
+// titles before names. Abbreviations of single letters and abbreviations with inner
+// periods, such as "J." and "e.g.", are recognized without being listed.
+var sentenceAbbreviations = map[string]map[string]bool{
+	"en": wordSet("mr", "mrs", "ms", "dr", "prof", "sr", "jr", "st", "vs", "approx", "fig", "figs", "vol", "vols",
+		"ed", "eds", "pp", "ch", "sec", "dept", "mt", "gen", "gov", "sen", "rep", "capt", "lt", "col", "sgt", "cf", "al",
+		"ca", "jan", "feb", "mar", "apr", "jun", "jul", "aug", "sep", "sept", "oct", "nov", "dec"),
+	"de": wordSet("dr", "prof", "hr", "fr", "str", "nr", "bzw", "ca", "vgl", "evtl", "ggf", "inkl", "zzgl", "abs",
+		"bd", "jh", "mio", "mrd", "st", "s", "abb", "tel"),
+	"fr": wordSet("m", "mme", "mlle", "mm", "dr", "pr", "st", "ste", "av", "bd", "env", "cf", "ex", "vol", "chap", "tél"),
+	"es": wordSet("sr", "sra", "srta", "dr", "dra", "ud", "uds", "pág", "págs", "av", "avda", "núm", "aprox", "cap"),
+	"it": wordSet("sig", "sigg", "dott", "prof", "ing", "avv", "pag", "pagg", "cap", "tel"),
+	"pt": wordSet("sr", "sra", "dr", "dra", "prof", "av", "pág", "págs", "núm", "cap"),
+	"nl": wordSet("dhr", "mevr", "dr", "prof", "bijv", "ca", "blz", "nr", "mr", "ir", "ing"),
+}
+
+func wordSet(words ...string) map[string]bool {
+	set := make(map[string]bool, len(words))
+	for _, word := range words {
+		set[word] = true
+	}
+	return set
+}
+
+// sentenceLanguage returns the key of sentenceAbbreviations for lang, an ISO 639 code with
+// an optional region such as "en-GB". Unknown and empty languages use the English rules.
+func sentenceLanguage(lang string) string {
+	lang = strings.ToLower(strings.TrimSpace(lang))
+	if i := strings.IndexAny(lang, "-_"); i >= 0 {
+		lang = lang[:i]
+	}
+	if code, ok := sentenceLanguages[lang]; ok {
+		lang = code
+	}
+	if _, ok := sentenceAbbreviations[lang]; !ok {
+		return "en"
+	}
+	return lang
+}
+
+// SplitSentences splits text into sentences with the segmenter the chunker uses for
+// OverlapStrategySentences, so both agree on where sentences start. lang is the ISO 639
+// code of the text's language, such as "en", "de" or "deu", and selects the abbreviations
+// that do not end a sentence; ExtractionResult.GetDetectedLanguage gives the detected
+// language of a document. Unknown and empty languages use the English rules.
+//
+// A sentence ends at a period, exclamation mark, question mark or ellipsis followed by
+// whitespace, optionally after closing quotes or brackets, at a blank line, and after the
+// full-width marks 。！？ even without whitespace. No mark ends a sentence before text that
+// goes on in lower case, and a period does not end one after a listed abbreviation such as
+// "Dr.", an initial such as "J.", an abbreviation with inner periods such as "e.g." or, in
+// German, an ordinal number such as "3.". Periods inside numbers such as 3.14 are never
+// sentence ends. Sentences are returned without surrounding whitespace, in order; text
+// without any yields nil.
+func SplitSentences(text string, lang string) []string {
+	lang = sentenceLanguage(lang)
+	var sentences []string
+	add := func(sentence string) {
+		if sentence = strings.TrimSpace(sentence); sentence != "" {
+			sentences = append(sentences, sentence)
+		}
+	}
+	start := 0
+	for i := nextSentenceStart(text, 1, len(text), lang); i >= 0; i = nextSentenceStart(text, i+1, len(text), lang) {
+		add(text[start:i])
+		start = i
+	}
+	add(text[start:])
+	return sentences
+}
+
+// nextSentenceStart returns the first offset in [from, limit] of text where a sentence
+// starts for the rules of lang, or -1.
+func nextSentenceStart(text string, from, limit int, lang string) int {
+	lang = sentenceLanguage(lang)
+	for i := from; i <= limit && i < len(text); {
+		if !utf8.RuneStart(text[i]) {
+			i++
+			continue
+		}
+		if isSentenceStart(text, i, lang) {
+			return i
+		}
+		_, size := utf8.DecodeRuneInString(text[i:])
+		i += size
+	}
+	return -1
+}
+
+// isSentenceStart reports whether a sentence starts at offset i of text: at its first
+// character that is not whitespace, after a blank line or after the end of a sentence. lang
+// must be a key of sentenceAbbreviations.
+func isSentenceStart(text string, i int, lang string) bool {
+	if i >= len(text) {
+		return false
+	}
+	if r, _ := utf8.DecodeRuneInString(text[i:]); unicode.IsSpace(r) {
+		return false
+	}
+	before := text[:i]
+	trimmed := strings.TrimRightFunc(before, unicode.IsSpace)
+	if trimmed == "" {
+		return true
+	}
+	last, _ := utf8.DecodeLastRuneInString(trimmed)
+	if last == '。' || last == '！' || last == '？' {
+		return true
+	}
+	if len(trimmed) == len(before) {
+		return false
+	}
+	if strings.Contains(before[len(trimmed):], "\n\n") {
+		return true
+	}
+	trimmed = strings.TrimRight(trimmed, "\"')]»”’")
+	last, _ = utf8.DecodeLastRuneInString(trimmed)
+	if last != '.' && last != '!' && last != '?' && last != '…' {
+		return false
+	}
+	// Text that goes on in lower case, as in `"Why?" she asked`, continues the sentence.
+	if r, _ := utf8.DecodeRuneInString(strings.TrimLeft(text[i:], "\"'([«“‘")); unicode.IsLower(r) {
+		return false
+	}
+	return last != '.' || !isAbbreviationEnd(trimmed, lang)
+}
+
+// isAbbreviationEnd reports whether the period ending before ends an abbreviation rather
+// than a sentence.
+func isAbbreviationEnd(before string, lang string) bool {
+	word := before[strings.LastIndexFunc(before, unicode.IsSpace)+1:]
+	word = strings.TrimLeft(word, "\"'([«“‘")
+	word = strings.TrimSuffix(word, ".")
+	if word == "" {
+		return false
+	}
+	if sentenceAbbreviations[lang][strings.ToLower(word)] {
+		return true
+	}
+	if r, size := utf8.DecodeRuneInString(word); size == len(word) && unicode.IsUpper(r) {
+		return true
+	}
+	if strings.Contains(word, ".") {
+		for _, part := range strings.Split(word, ".") {
+			if n := utf8.RuneCountInString(part); n == 0 || n > 2 || !isLetters(part) {
+				return false
+			}
+		}
+		return true
+	}
+	return lang == "de" && isDigits(word)
+}
+
+func isLetters(s string) bool {
+	for _, r := range s {
+		if !unicode.IsLetter(r) {
+			return false
+		}
+	}
+	return true
+}
+
+func isDigits(s string) bool {
+	for _, r := range s {
+		if r < '0' || r > '9' {
+			return false
+		}
+	}
+	return s != ""
+}
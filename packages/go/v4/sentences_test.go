@@ -0,0 +1,69 @@
+package kreuzberg
+
+import (
+	"slices"
+	"testing"
+)
+
+func TestSplitSentences(t *testing.T) {
+	tests := []struct {
+		name string
+		text string
+		lang string
+		want []string
+	}{
+		{
+			name: "abbreviations and decimals",
+			text: "Dr. Smith paid $3.50 for it, i.e. almost nothing. J. R. R. Tolkien wrote (see Fig. 2) a lot!  Really?\n\nYes.",
+			lang: "en",
+			want: []string{"Dr. Smith paid $3.50 for it, i.e. almost nothing.", "J. R. R. Tolkien wrote (see Fig. 2) a lot!", "Really?", "Yes."},
+		},
+		{
+			name: "closing quotes",
+			text: `He said "Stop." Then he left. "Why?" she asked.`,
+			lang: "",
+			want: []string{`He said "Stop."`, "Then he left.", `"Why?" she asked.`},
+		},
+		{
+			name: "german ordinals",
+			text: "Am 3. Mai kam Hr. Weber. Er blieb bis z.B. Juni.",
+			lang: "deu",
+			want: []string{"Am 3. Mai kam Hr. Weber.", "Er blieb bis z.B. Juni."},
+		},
+		{
+			name: "ordinals end english sentences",
+			text: "We counted to 3. Mai was there.",
+			lang: "en-US",
+			want: []string{"We counted to 3.", "Mai was there."},
+		},
+		{
+			name: "full-width marks",
+			text: "今日は晴れです。明日は雨？はい！",
+			lang: "ja",
+			want: []string{"今日は晴れです。", "明日は雨？", "はい！"},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := SplitSentences(tt.text, tt.lang); !slices.Equal(got, tt.want) {
+				t.Fatalf("SplitSentences = %q, want %q", got, tt.want)
+			}
+		})
+	}
+	if got := SplitSentences(" \n ", "en"); got != nil {
+		t.Fatalf("blank text gave %q", got)
+	}
+}
+
+func TestSplitSentencesMatchesChunkOverlap(t *testing.T) {
+	content := "Prof. Adams arrived. Mrs. Lee followed him."
+	chunks := []Chunk{
+		{Content: content[:24], Metadata: ChunkMetadata{ByteStart: 0, ByteEnd: 24}},
+		{Content: content[2:], Metadata: ChunkMetadata{ByteStart: 2, ByteEnd: uint64(len(content))}},
+	}
+	result := &ExtractionResult{Content: content, Chunks: chunks, DetectedLanguages: []string{"en"}}
+	applyChunkOverlapStrategy(result, NewExtractionConfig(WithChunking(WithChunkOverlapStrategy(OverlapStrategySentences))))
+	if want := SplitSentences(content, "en")[1]; result.Chunks[1].Content != want {
+		t.Fatalf("chunk starts at %q, want the second sentence %q", result.Chunks[1].Content, want)
+	}
+}
@@ -0,0 +1,499 @@
+package kreuzberg
+
+import (
+	"bytes"
+	"crypto"
+	"crypto/x509"
+	"crypto/x509/pkix"
+	"encoding/asn1"
+	"errors"
+	"fmt"
+	"math/big"
+	"strconv"
+	"time"
+
+	// Hash functions used by signatures must be linked in for crypto.Hash.New.
+	_ "crypto/sha1"
+	_ "crypto/sha256"
+	_ "crypto/sha512"
+)
+
+// Signature is a digital signature of a PDF.
+type Signature struct {
+	// SignerName is the /Name of the signature, or the common name of the signing
+	// certificate when the signature does not name its signer.
+	SignerName string `json:"signer_name,omitempty"`
+	// Valid reports whether the signed bytes are unchanged and the signing certificate
+	// chains to a trusted root now; see WithExtractSignatures.
+	Valid bool `json:"valid"`
+	// RevocationChecked reports whether the revocation status of the signing certificate
+	// was checked. It is always false: neither CRLs nor OCSP are consulted, so a Valid
+	// signature may have been made with a revoked certificate.
+	RevocationChecked bool `json:"revocation_checked"`
+	// SignedAt is the signing time of the signature, or its /M date when it has none; zero
+	// when neither is recorded.
+	SignedAt time.Time `json:"signed_at"`
+	Reason   string    `json:"reason,omitempty"`
+	// CoversWholeDocument reports whether the signed byte range spans the whole file, so
+	// that nothing was appended to the document after it was signed.
+	CoversWholeDocument bool `json:"covers_whole_document"`
+}
+
+// Object identifiers of the CMS structures and attributes read from signatures.
+var (
+	oidSignedData       = asn1.ObjectIdentifier{1, 2, 840, 113549, 1, 7, 2}
+	oidMessageDigest    = asn1.ObjectIdentifier{1, 2, 840, 113549, 1, 9, 4}
+	oidSigningTime      = asn1.ObjectIdentifier{1, 2, 840, 113549, 1, 9, 5}
+	oidSignatureRSAPSS  = asn1.ObjectIdentifier{1, 2, 840, 113549, 1, 1, 10}
+	oidSignatureEd25519 = asn1.ObjectIdentifier{1, 3, 101, 112}
+)
+
+// signatureDigests maps the digest algorithm identifiers of CMS signer infos to hashes.
+var signatureDigests = map[string]crypto.Hash{
+	"1.3.14.3.2.26":          crypto.SHA1,
+	"2.16.840.1.101.3.4.2.1": crypto.SHA256,
+	"2.16.840.1.101.3.4.2.2": crypto.SHA384,
+	"2.16.840.1.101.3.4.2.3": crypto.SHA512,
+}
+
+// applySignatures fills result.Signatures with the signature fields of a PDF when signature
+// extraction is enabled. Each signature whose check fails is also reported as a
+// WarningCodeSignatureInvalid warning giving the reason. Encrypted documents are skipped.
+func applySignatures(result *ExtractionResult, config *ExtractionConfig, src *documentSource) error {
+	if config.ExtractSignatures == nil || !*config.ExtractSignatures || !isPDFMimeType(result.MimeType) {
+		return nil
+	}
+	doc, err := src.pdfDocument()
+	if err != nil {
+		return err
+	}
+	if doc.encrypted {
+		return nil
+	}
+	data, err := src.bytes()
+	if err != nil {
+		return err
+	}
+	for _, dict := range pdfSignatureDicts(doc) {
+		signature, err := verifyPDFSignature(doc, data, dict, config.SignatureRoots)
+		if err != nil {
+			name := signature.SignerName
+			if name == "" {
+				name = "unnamed signer"
+			}
+			result.addWarning(WarningCodeSignatureInvalid, 0, fmt.Sprintf("signature of %s is not valid: %v", name, err))
+		}
+		result.Signatures = append(result.Signatures, signature)
+	}
+	return nil
+}
+
+// pdfSignatureDicts returns the signature dictionaries (the /V values) of the signed
+// signature fields of the document's AcroForm, in field order.
+func pdfSignatureDicts(doc *pdfDocument) []pdfDict {
+	catalog := doc.catalog()
+	if catalog == nil {
+		return nil
+	}
+	acroForm := doc.dict(catalog["AcroForm"])
+	if acroForm == nil {
+		return nil
+	}
+	var dicts []pdfDict
+	visited := make(map[int]bool)
+	var walk func(node any, fieldType pdfName)
+	walk = func(node any, fieldType pdfName) {
+		if ref, ok := node.(pdfRef); ok {
+			if visited[ref.num] {
+				return
+			}
+			visited[ref.num] = true
+		}
+		dict := doc.dict(node)
+		if dict == nil {
+			return
+		}
+		if name := dict.name("FT"); name != "" {
+			fieldType = name
+		}
+		if fieldType == "Sig" {
+			if value := doc.dict(dict["V"]); value != nil {
+				dicts = append(dicts, value)
+				return
+			}
+		}
+		for _, kid := range doc.array(dict["Kids"]) {
+			walk(kid, fieldType)
+		}
+	}
+	for _, field := range doc.array(acroForm["Fields"]) {
+		walk(field, "")
+	}
+	return dicts
+}
+
+// verifyPDFSignature reads the signature dictionary dict of the PDF data and checks it. The
+// returned Signature is filled as far as the dictionary could be read; the error tells why
+// the signature is not valid.
+func verifyPDFSignature(doc *pdfDocument, data []byte, dict pdfDict, roots *x509.CertPool) (Signature, error) {
+	var signature Signature
+	signature.SignerName, _ = doc.resolve(dict["Name"]).(string)
+	signature.Reason, _ = doc.resolve(dict["Reason"]).(string)
+	if date, ok := doc.resolve(dict["M"]).(string); ok {
+		signature.SignedAt, _ = parsePDFDate(date)
+	}
+
+	byteRange := doc.array(dict["ByteRange"])
+	if len(byteRange) != 4 {
+		return signature, errors.New("missing byte range")
+	}
+	var offsets [4]int
+	for i, value := range byteRange {
+		n, ok := doc.resolve(value).(float64)
+		if !ok || n < 0 || n > float64(len(data)) {
+			return signature, errors.New("invalid byte range")
+		}
+		offsets[i] = int(n)
+	}
+	if offsets[0]+offsets[1] > offsets[2] || offsets[2]+offsets[3] > len(data) {
+		return signature, errors.New("invalid byte range")
+	}
+	signed := append(append([]byte(nil), data[offsets[0]:offsets[0]+offsets[1]]...), data[offsets[2]:offsets[2]+offsets[3]]...)
+	signature.CoversWholeDocument = offsets[0] == 0 && offsets[2]+offsets[3] >= len(bytes.TrimRight(data, "\r\n\x00 "))
+
+	contents, ok := doc.resolve(dict["Contents"]).(string)
+	if !ok {
+		return signature, errors.New("missing signature contents")
+	}
+	switch subFilter := dict.name("SubFilter"); subFilter {
+	case "adbe.pkcs7.detached", "ETSI.CAdES.detached", "adbe.pkcs7.sha1":
+		signer, signingTime, err := verifyCMSSignature(pdfStringBytes(contents), signed, subFilter == "adbe.pkcs7.sha1", roots)
+		if signer != nil && signature.SignerName == "" {
+			signature.SignerName = signer.Subject.CommonName
+		}
+		if !signingTime.IsZero() {
+			signature.SignedAt = signingTime
+		}
+		if err != nil {
+			return signature, err
+		}
+	default:
+		return signature, fmt.Errorf("unsupported signature format %q", subFilter)
+	}
+	signature.Valid = true
+	return signature, nil
+}
+
+// verifyCMSSignature checks a CMS SignedData signature of content and returns the
+// certificate and signing time attribute of its first signer. With sha1Content the signed
+// data encapsulates the SHA-1 digest of content instead of signing it directly
+// (adbe.pkcs7.sha1). Every signer info must verify, and each signer's certificate chain is
+// verified at the current time against roots, or the system roots when roots is nil: the
+// signing time attribute and the /M date are claims of the signer and are not trusted for
+// certificate validity, and timestamp tokens are not read.
+func verifyCMSSignature(der, content []byte, sha1Content bool, roots *x509.CertPool) (*x509.Certificate, time.Time, error) {
+	// Signature contents are padded with zero bytes after the DER value.
+	var top asn1.RawValue
+	if _, err := asn1.Unmarshal(der, &top); err != nil {
+		return nil, time.Time{}, errors.New("malformed signature")
+	}
+	contentInfo, ok := derChildren(top.Bytes)
+	if !ok || len(contentInfo) < 2 || contentInfo[1].Class != asn1.ClassContextSpecific {
+		return nil, time.Time{}, errors.New("malformed signature")
+	}
+	var contentType asn1.ObjectIdentifier
+	if _, err := asn1.Unmarshal(contentInfo[0].FullBytes, &contentType); err != nil || !contentType.Equal(oidSignedData) {
+		return nil, time.Time{}, errors.New("signature is not CMS signed data")
+	}
+	signedData, ok := derChildren(contentInfo[1].Bytes)
+	if ok && len(signedData) == 1 {
+		signedData, ok = derChildren(signedData[0].Bytes)
+	}
+	if !ok || len(signedData) < 4 {
+		return nil, time.Time{}, errors.New("malformed signed data")
+	}
+
+	var certs []*x509.Certificate
+	var signerInfos []asn1.RawValue
+	for _, field := range signedData[3:] {
+		switch {
+		case field.Class == asn1.ClassContextSpecific && field.Tag == 0:
+			parsed, err := x509.ParseCertificates(field.Bytes)
+			if err != nil {
+				return nil, time.Time{}, fmt.Errorf("malformed certificates: %w", err)
+			}
+			certs = parsed
+		case field.Class == asn1.ClassUniversal && field.Tag == asn1.TagSet:
+			signerInfos, ok = derChildren(field.Bytes)
+		}
+	}
+	if !ok || len(signerInfos) == 0 {
+		return nil, time.Time{}, errors.New("signature has no signer")
+	}
+
+	if sha1Content {
+		digest := crypto.SHA1.New()
+		digest.Write(content)
+		encapsulated, ok := cmsEncapsulatedContent(signedData[2])
+		if !ok || !bytes.Equal(encapsulated, digest.Sum(nil)) {
+			return nil, time.Time{}, errors.New("document was modified after signing")
+		}
+		content = encapsulated
+	}
+
+	var firstSigner *x509.Certificate
+	var firstSigningTime time.Time
+	for i, signerInfo := range signerInfos {
+		signer, signingTime, err := verifyCMSSignerInfo(signerInfo, content, certs, roots)
+		if i == 0 {
+			firstSigner, firstSigningTime = signer, signingTime
+		}
+		if err != nil {
+			if len(signerInfos) > 1 {
+				err = fmt.Errorf("signer %d: %w", i+1, err)
+			}
+			return firstSigner, firstSigningTime, err
+		}
+	}
+	return firstSigner, firstSigningTime, nil
+}
+
+// verifyCMSSignerInfo checks one signer info of a CMS SignedData signature of content, whose
+// embedded certificates are certs, and returns the signer's certificate and signing time
+// attribute.
+func verifyCMSSignerInfo(signerInfo asn1.RawValue, content []byte, certs []*x509.Certificate, roots *x509.CertPool) (*x509.Certificate, time.Time, error) {
+	var signingTime time.Time
+	info, ok := derChildren(signerInfo.Bytes)
+	if !ok || len(info) < 5 {
+		return nil, signingTime, errors.New("malformed signer info")
+	}
+	signer := cmsSignerCertificate(info[1], certs)
+	if signer == nil {
+		return nil, signingTime, errors.New("signing certificate not included")
+	}
+
+	var digestAlgorithm, signatureAlgorithm pkix.AlgorithmIdentifier
+	if _, err := asn1.Unmarshal(info[2].FullBytes, &digestAlgorithm); err != nil {
+		return signer, signingTime, errors.New("malformed digest algorithm")
+	}
+	hash, ok := signatureDigests[digestAlgorithm.Algorithm.String()]
+	if !ok {
+		return signer, signingTime, fmt.Errorf("unsupported digest algorithm %s", digestAlgorithm.Algorithm)
+	}
+	rest := info[3:]
+	var signedAttrs, attrs []byte
+	if rest[0].Class == asn1.ClassContextSpecific && rest[0].Tag == 0 {
+		attrs = rest[0].Bytes
+		// The signature covers the attributes encoded as a SET rather than with their
+		// implicit [0] tag.
+		signedAttrs = append([]byte{0x31}, rest[0].FullBytes[1:]...)
+		rest = rest[1:]
+	}
+	if len(rest) < 2 {
+		return signer, signingTime, errors.New("malformed signer info")
+	}
+	if _, err := asn1.Unmarshal(rest[0].FullBytes, &signatureAlgorithm); err != nil {
+		return signer, signingTime, errors.New("malformed signature algorithm")
+	}
+	var sig []byte
+	if _, err := asn1.Unmarshal(rest[1].FullBytes, &sig); err != nil {
+		return signer, signingTime, errors.New("malformed signature value")
+	}
+
+	digest := hash.New()
+	digest.Write(content)
+	signedBytes := content
+	if signedAttrs != nil {
+		messageDigest, when, ok := cmsSignedAttributes(attrs)
+		signingTime = when
+		if !ok || !bytes.Equal(messageDigest, digest.Sum(nil)) {
+			return signer, signingTime, errors.New("document was modified after signing")
+		}
+		signedBytes = signedAttrs
+	}
+
+	algorithm := cmsSignatureAlgorithm(hash, signatureAlgorithm.Algorithm, signer.PublicKeyAlgorithm)
+	if algorithm == x509.UnknownSignatureAlgorithm {
+		return signer, signingTime, fmt.Errorf("unsupported signature algorithm %s", signatureAlgorithm.Algorithm)
+	}
+	if err := signer.CheckSignature(algorithm, signedBytes, sig); err != nil {
+		return signer, signingTime, fmt.Errorf("signature does not match the document: %w", err)
+	}
+
+	intermediates := x509.NewCertPool()
+	for _, cert := range certs {
+		if cert != signer {
+			intermediates.AddCert(cert)
+		}
+	}
+	options := x509.VerifyOptions{
+		Roots:         roots,
+		Intermediates: intermediates,
+		CurrentTime:   time.Now(),
+		KeyUsages:     []x509.ExtKeyUsage{x509.ExtKeyUsageAny},
+	}
+	if _, err := signer.Verify(options); err != nil {
+		return signer, signingTime, fmt.Errorf("certificate is not trusted: %w", err)
+	}
+	return signer, signingTime, nil
+}
+
+// cmsSignedAttributes returns the message digest and signing time attributes of the signed
+// attributes of a signer info; attrs is the content of the attribute set.
+func cmsSignedAttributes(attrs []byte) ([]byte, time.Time, bool) {
+	var messageDigest []byte
+	var signingTime time.Time
+	attributes, ok := derChildren(attrs)
+	if !ok {
+		return nil, signingTime, false
+	}
+	for _, attribute := range attributes {
+		parts, ok := derChildren(attribute.Bytes)
+		if !ok || len(parts) != 2 {
+			continue
+		}
+		var oid asn1.ObjectIdentifier
+		if _, err := asn1.Unmarshal(parts[0].FullBytes, &oid); err != nil {
+			continue
+		}
+		values, ok := derChildren(parts[1].Bytes)
+		if !ok || len(values) == 0 {
+			continue
+		}
+		switch {
+		case oid.Equal(oidMessageDigest):
+			_, _ = asn1.Unmarshal(values[0].FullBytes, &messageDigest)
+		case oid.Equal(oidSigningTime):
+			_, _ = asn1.Unmarshal(values[0].FullBytes, &signingTime)
+		}
+	}
+	return messageDigest, signingTime, messageDigest != nil
+}
+
+// cmsEncapsulatedContent returns the eContent octets of a CMS EncapsulatedContentInfo.
+func cmsEncapsulatedContent(encap asn1.RawValue) ([]byte, bool) {
+	parts, ok := derChildren(encap.Bytes)
+	if !ok || len(parts) < 2 {
+		return nil, false
+	}
+	var octets []byte
+	if _, err := asn1.Unmarshal(parts[1].Bytes, &octets); err != nil {
+		return nil, false
+	}
+	return octets, true
+}
+
+// cmsSignerCertificate returns the certificate of certs that sid, a signer identifier by
+// issuer and serial number or by subject key identifier, names.
+func cmsSignerCertificate(sid asn1.RawValue, certs []*x509.Certificate) *x509.Certificate {
+	if sid.Class == asn1.ClassContextSpecific {
+		for _, cert := range certs {
+			if bytes.Equal(cert.SubjectKeyId, sid.Bytes) {
+				return cert
+			}
+		}
+		return nil
+	}
+	parts, ok := derChildren(sid.Bytes)
+	if !ok || len(parts) != 2 {
+		return nil
+	}
+	serial := new(big.Int)
+	if _, err := asn1.Unmarshal(parts[1].FullBytes, &serial); err != nil {
+		return nil
+	}
+	for _, cert := range certs {
+		if bytes.Equal(cert.RawIssuer, parts[0].FullBytes) && cert.SerialNumber.Cmp(serial) == 0 {
+			return cert
+		}
+	}
+	return nil
+}
+
+// cmsSignatureAlgorithm returns the x509 signature algorithm for a CMS signature made with
+// hash and a key of type keyType; algorithm is the signature algorithm of the signer info,
+// which for most signatures only names the key type.
+func cmsSignatureAlgorithm(hash crypto.Hash, algorithm asn1.ObjectIdentifier, keyType x509.PublicKeyAlgorithm) x509.SignatureAlgorithm {
+	switch {
+	case algorithm.Equal(oidSignatureEd25519) || keyType == x509.Ed25519:
+		return x509.PureEd25519
+	case algorithm.Equal(oidSignatureRSAPSS):
+		return map[crypto.Hash]x509.SignatureAlgorithm{
+			crypto.SHA256: x509.SHA256WithRSAPSS, crypto.SHA384: x509.SHA384WithRSAPSS, crypto.SHA512: x509.SHA512WithRSAPSS,
+		}[hash]
+	case keyType == x509.RSA:
+		return map[crypto.Hash]x509.SignatureAlgorithm{
+			crypto.SHA1: x509.SHA1WithRSA, crypto.SHA256: x509.SHA256WithRSA, crypto.SHA384: x509.SHA384WithRSA, crypto.SHA512: x509.SHA512WithRSA,
+		}[hash]
+	case keyType == x509.ECDSA:
+		return map[crypto.Hash]x509.SignatureAlgorithm{
+			crypto.SHA1: x509.ECDSAWithSHA1, crypto.SHA256: x509.ECDSAWithSHA256, crypto.SHA384: x509.ECDSAWithSHA384, crypto.SHA512: x509.ECDSAWithSHA512,
+		}[hash]
+	}
+	return x509.UnknownSignatureAlgorithm
+}
+
+// derChildren splits the content of a DER constructed value into its elements.
+func derChildren(data []byte) ([]asn1.RawValue, bool) {
+	var children []asn1.RawValue
+	for len(data) > 0 {
+		var child asn1.RawValue
+		rest, err := asn1.Unmarshal(data, &child)
+		if err != nil {
+			return nil, false
+		}
+		children = append(children, child)
+		data = rest
+	}
+	return children, true
+}
+
+// pdfStringBytes returns the bytes of a PDF string that the lexer decoded as
+// PDFDocEncoding, for binary strings such as signature contents.
+func pdfStringBytes(s string) []byte {
+	out := make([]byte, 0, len(s))
+	for _, r := range s {
+		out = append(out, byte(r))
+	}
+	return out
+}
+
+// parsePDFDate parses a PDF date string, "D:YYYYMMDDHHmmSSOHH'mm'", in which every part
+// after the year is optional. Dates without a time zone are taken as UTC.
+func parsePDFDate(s string) (time.Time, bool) {
+	if len(s) >= 2 && s[:2] == "D:" {
+		s = s[2:]
+	}
+	fields := []int{0, 1, 1, 0, 0, 0}
+	widths := []int{4, 2, 2, 2, 2, 2}
+	for i, width := range widths {
+		if len(s) < width || s[0] < '0' || s[0] > '9' {
+			if i == 0 {
+				return time.Time{}, false
+			}
+			break
+		}
+		n, err := strconv.Atoi(s[:width])
+		if err != nil {
+			return time.Time{}, false
+		}
+		fields[i] = n
+		s = s[width:]
+	}
+	location := time.UTC
+	if len(s) >= 3 && (s[0] == '+' || s[0] == '-') {
+		hours, errHours := strconv.Atoi(s[1:3])
+		minutes := 0
+		if len(s) >= 6 && s[3] == '\'' {
+			minutes, _ = strconv.Atoi(s[4:6])
+		}
+		if errHours == nil {
+			offset := hours*3600 + minutes*60
+			if s[0] == '-' {
+				offset = -offset
+			}
+			location = time.FixedZone("", offset)
+		}
+	}
+	return time.Date(fields[0], time.Month(fields[1]), fields[2], fields[3], fields[4], fields[5], 0, location), true
+}
@@ -0,0 +1,264 @@
+package kreuzberg
+
+import (
+	"bytes"
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"crypto/rand"
+	"crypto/sha256"
+	"crypto/x509"
+	"crypto/x509/pkix"
+	"encoding/asn1"
+	"encoding/hex"
+	"fmt"
+	"math/big"
+	"strings"
+	"testing"
+	"time"
+)
+
+// derValue encodes a DER value with the identifier octet tag and the given content.
+func derValue(tag byte, content ...[]byte) []byte {
+	body := bytes.Join(content, nil)
+	out := []byte{tag}
+	switch n := len(body); {
+	case n < 0x80:
+		out = append(out, byte(n))
+	case n < 0x100:
+		out = append(out, 0x81, byte(n))
+	default:
+		out = append(out, 0x82, byte(n>>8), byte(n))
+	}
+	return append(out, body...)
+}
+
+func mustDER(t *testing.T, value any) []byte {
+	t.Helper()
+	der, err := asn1.Marshal(value)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return der
+}
+
+// testSigner is a signing certificate issued by its own test root.
+type testSigner struct {
+	key   *ecdsa.PrivateKey
+	cert  *x509.Certificate
+	roots *x509.CertPool
+}
+
+func newTestSigner(t *testing.T) testSigner {
+	t.Helper()
+	return newTestSignerValidUntil(t, time.Date(2030, 1, 1, 0, 0, 0, 0, time.UTC))
+}
+
+// newTestSignerValidUntil returns a test signer whose certificate expires at notAfter.
+func newTestSignerValidUntil(t *testing.T, notAfter time.Time) testSigner {
+	t.Helper()
+	rootKey, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	if err != nil {
+		t.Fatal(err)
+	}
+	notBefore := time.Date(2020, 1, 1, 0, 0, 0, 0, time.UTC)
+	rootTemplate := &x509.Certificate{
+		SerialNumber:          big.NewInt(1),
+		Subject:               pkix.Name{CommonName: "Test Root CA"},
+		NotBefore:             notBefore,
+		NotAfter:              notBefore.AddDate(20, 0, 0),
+		IsCA:                  true,
+		BasicConstraintsValid: true,
+		KeyUsage:              x509.KeyUsageCertSign,
+	}
+	rootDER, err := x509.CreateCertificate(rand.Reader, rootTemplate, rootTemplate, &rootKey.PublicKey, rootKey)
+	if err != nil {
+		t.Fatal(err)
+	}
+	root, _ := x509.ParseCertificate(rootDER)
+
+	key, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	if err != nil {
+		t.Fatal(err)
+	}
+	template := &x509.Certificate{
+		SerialNumber: big.NewInt(42),
+		Subject:      pkix.Name{CommonName: "Jane Signer"},
+		NotBefore:    notBefore,
+		NotAfter:     notAfter,
+		KeyUsage:     x509.KeyUsageDigitalSignature,
+	}
+	der, err := x509.CreateCertificate(rand.Reader, template, root, &key.PublicKey, rootKey)
+	if err != nil {
+		t.Fatal(err)
+	}
+	cert, _ := x509.ParseCertificate(der)
+	roots := x509.NewCertPool()
+	roots.AddCert(root)
+	return testSigner{key: key, cert: cert, roots: roots}
+}
+
+// sign returns a detached CMS SignedData signature of content with signed attributes.
+func (s testSigner) sign(t *testing.T, content []byte, signingTime time.Time) []byte {
+	t.Helper()
+	digest := sha256.Sum256(content)
+	oidSHA256 := mustDER(t, pkix.AlgorithmIdentifier{Algorithm: asn1.ObjectIdentifier{2, 16, 840, 1, 101, 3, 4, 2, 1}})
+	oidData := mustDER(t, asn1.ObjectIdentifier{1, 2, 840, 113549, 1, 7, 1})
+	attrs := [][]byte{
+		derValue(0x30, mustDER(t, asn1.ObjectIdentifier{1, 2, 840, 113549, 1, 9, 3}), derValue(0x31, oidData)),
+		derValue(0x30, mustDER(t, oidSigningTime), derValue(0x31, mustDER(t, signingTime))),
+		derValue(0x30, mustDER(t, oidMessageDigest), derValue(0x31, mustDER(t, digest[:]))),
+	}
+	attrDigest := sha256.Sum256(derValue(0x31, attrs...))
+	sig, err := ecdsa.SignASN1(rand.Reader, s.key, attrDigest[:])
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	var serial asn1.RawValue
+	_, _ = asn1.Unmarshal(mustDER(t, s.cert.SerialNumber), &serial)
+	signerInfo := derValue(0x30,
+		mustDER(t, 1),
+		derValue(0x30, s.cert.RawIssuer, serial.FullBytes),
+		oidSHA256,
+		derValue(0xa0, attrs...),
+		mustDER(t, pkix.AlgorithmIdentifier{Algorithm: asn1.ObjectIdentifier{1, 2, 840, 10045, 4, 3, 2}}),
+		mustDER(t, sig),
+	)
+	signedData := derValue(0x30,
+		mustDER(t, 1),
+		derValue(0x31, oidSHA256),
+		derValue(0x30, oidData),
+		derValue(0xa0, s.cert.Raw),
+		derValue(0x31, signerInfo),
+	)
+	return derValue(0x30, mustDER(t, oidSignedData), derValue(0xa0, signedData))
+}
+
+// testSignedPDF returns a PDF with one signature field signed by signer over the whole file.
+func testSignedPDF(t *testing.T, signer testSigner, signingTime time.Time) []byte {
+	t.Helper()
+	const placeholder = "[0000000000 0000000000 0000000000 0000000000]"
+	data := buildTestPDF(
+		`<< /Type /Catalog /Pages 2 0 R /AcroForm << /Fields [4 0 R] /SigFlags 3 >> >>`,
+		`<< /Type /Pages /Kids [3 0 R] /Count 1 >>`,
+		`<< /Type /Page /Parent 2 0 R >>`,
+		`<< /FT /Sig /T (Signature1) /V 5 0 R /P 3 0 R /Rect [0 0 0 0] >>`,
+		`<< /Type /Sig /Filter /Adobe.PPKLite /SubFilter /adbe.pkcs7.detached /ByteRange `+placeholder+
+			` /Contents <`+strings.Repeat("0", 4096)+`> /M (D:20240315103000+01'00') /Reason (Contract approval) >>`,
+	)
+	start := bytes.Index(data, []byte("/Contents <")) + len("/Contents ")
+	end := bytes.IndexByte(data[start:], '>') + start + 1
+	byteRange := fmt.Sprintf("[%010d %010d %010d %010d]", 0, start, end, len(data)-end)
+	copy(data[bytes.Index(data, []byte(placeholder)):], byteRange)
+
+	signed := append(append([]byte(nil), data[:start]...), data[end:]...)
+	contents := hex.EncodeToString(signer.sign(t, signed, signingTime))
+	copy(data[start+1:], contents)
+	return data
+}
+
+func TestApplySignatures(t *testing.T) {
+	signer := newTestSigner(t)
+	signingTime := time.Date(2024, 3, 15, 9, 30, 0, 0, time.UTC)
+	data := testSignedPDF(t, signer, signingTime)
+	config := NewExtractionConfig(WithExtractSignatures(true), WithSignatureRoots(signer.roots))
+
+	result := &ExtractionResult{MimeType: "application/pdf"}
+	if err := applySignatures(result, config, &documentSource{data: data}); err != nil {
+		t.Fatalf("applySignatures failed: %v", err)
+	}
+	want := Signature{
+		SignerName:          "Jane Signer",
+		Valid:               true,
+		SignedAt:            signingTime,
+		Reason:              "Contract approval",
+		CoversWholeDocument: true,
+	}
+	if len(result.Signatures) != 1 || !result.Signatures[0].SignedAt.Equal(signingTime) {
+		t.Fatalf("unexpected signatures %+v (warnings %+v)", result.Signatures, result.Warnings)
+	}
+	got := result.Signatures[0]
+	got.SignedAt = signingTime
+	if got != want || len(result.Warnings) != 0 {
+		t.Fatalf("signature = %+v, want %+v (warnings %+v)", got, want, result.Warnings)
+	}
+
+	// A byte changed inside the signed range breaks the signature.
+	tampered := bytes.Replace(data, []byte("/Count 1"), []byte("/Count 2"), 1)
+	result = &ExtractionResult{MimeType: "application/pdf"}
+	if err := applySignatures(result, config, &documentSource{data: tampered}); err != nil {
+		t.Fatal(err)
+	}
+	if len(result.Signatures) != 1 || result.Signatures[0].Valid || len(result.Warnings) != 1 ||
+		result.Warnings[0].Code != WarningCodeSignatureInvalid || !strings.Contains(result.Warnings[0].Message, "modified") {
+		t.Fatalf("tampered document not detected: %+v %+v", result.Signatures, result.Warnings)
+	}
+
+	// Content appended after signing leaves the signature intact but not covering the file.
+	appended := append(append([]byte(nil), data...), "6 0 obj\n<< /Producer (Editor) >>\nendobj\n%%EOF\n"...)
+	result = &ExtractionResult{MimeType: "application/pdf"}
+	if err := applySignatures(result, config, &documentSource{data: appended}); err != nil {
+		t.Fatal(err)
+	}
+	if len(result.Signatures) != 1 || !result.Signatures[0].Valid || result.Signatures[0].CoversWholeDocument {
+		t.Fatalf("unexpected signature of an updated document: %+v", result.Signatures)
+	}
+}
+
+func TestApplySignaturesUntrusted(t *testing.T) {
+	signer := newTestSigner(t)
+	data := testSignedPDF(t, signer, time.Date(2024, 3, 15, 9, 30, 0, 0, time.UTC))
+	result := &ExtractionResult{MimeType: "application/pdf"}
+	if err := applySignatures(result, NewExtractionConfig(WithExtractSignatures(true), WithSignatureRoots(x509.NewCertPool())), &documentSource{data: data}); err != nil {
+		t.Fatal(err)
+	}
+	if len(result.Signatures) != 1 || result.Signatures[0].Valid || result.Signatures[0].SignerName != "Jane Signer" ||
+		len(result.Warnings) != 1 || !strings.Contains(result.Warnings[0].Message, "not trusted") {
+		t.Fatalf("expected an untrusted signature: %+v %+v", result.Signatures, result.Warnings)
+	}
+
+	unsigned := &ExtractionResult{MimeType: "application/pdf"}
+	if err := applySignatures(unsigned, NewExtractionConfig(WithExtractSignatures(true)), &documentSource{data: testFormPDF()}); err != nil {
+		t.Fatal(err)
+	}
+	if unsigned.Signatures != nil {
+		t.Fatalf("unsigned document reported signatures: %+v", unsigned.Signatures)
+	}
+	disabled := &ExtractionResult{MimeType: "application/pdf"}
+	if err := applySignatures(disabled, NewExtractionConfig(), &documentSource{data: data}); err != nil || disabled.Signatures != nil {
+		t.Fatalf("signatures extracted without WithExtractSignatures: %+v, %v", disabled.Signatures, err)
+	}
+}
+
+func TestApplySignaturesExpiredCertificate(t *testing.T) {
+	// The certificate was valid at the claimed signing time but has expired since; the
+	// unauthenticated claim must not make it valid.
+	signer := newTestSignerValidUntil(t, time.Date(2022, 1, 1, 0, 0, 0, 0, time.UTC))
+	data := testSignedPDF(t, signer, time.Date(2021, 3, 15, 9, 30, 0, 0, time.UTC))
+	result := &ExtractionResult{MimeType: "application/pdf"}
+	config := NewExtractionConfig(WithExtractSignatures(true), WithSignatureRoots(signer.roots))
+	if err := applySignatures(result, config, &documentSource{data: data}); err != nil {
+		t.Fatal(err)
+	}
+	if len(result.Signatures) != 1 || result.Signatures[0].Valid || result.Signatures[0].RevocationChecked ||
+		len(result.Warnings) != 1 || !strings.Contains(result.Warnings[0].Message, "not trusted") {
+		t.Fatalf("expected an expired certificate to be untrusted: %+v %+v", result.Signatures, result.Warnings)
+	}
+}
+
+func TestParsePDFDate(t *testing.T) {
+	cases := map[string]time.Time{
+		"D:20240315103000+01'00'": time.Date(2024, 3, 15, 9, 30, 0, 0, time.UTC),
+		"D:20240315103000Z":       time.Date(2024, 3, 15, 10, 30, 0, 0, time.UTC),
+		"D:2024":                  time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC),
+		"20240315":                time.Date(2024, 3, 15, 0, 0, 0, 0, time.UTC),
+	}
+	for input, want := range cases {
+		if got, ok := parsePDFDate(input); !ok || !got.Equal(want) {
+			t.Errorf("parsePDFDate(%q) = %v, %v, want %v", input, got, ok, want)
+		}
+	}
+	if _, ok := parsePDFDate("D:soon"); ok {
+		t.Error("expected an invalid date to fail")
+	}
+}
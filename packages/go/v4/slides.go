@@ -0,0 +1,259 @@
+package kreuzberg
+
+import (
+	"archive/zip"
+	"bytes"
+	"encoding/xml"
+	"errors"
+	"io"
+	"path"
+	"strings"
+)
+
+// Slide is a slide of a presentation, filled by structured output for PPTX documents. The
+// images of a slide are the entries of ExtractionResult.Images whose PageNumber is Number.
+type Slide struct {
+	// Number is the 1-based position of the slide in the presentation.
+	Number int    `json:"number"`
+	Title  string `json:"title,omitempty"`
+	// Layout is the name of the slide layout the slide is based on, such as "Title and
+	// Content".
+	Layout string `json:"layout,omitempty"`
+	// Content is the text of the slide other than its title, one line per paragraph.
+	Content string `json:"content"`
+}
+
+// applySlides fills result.Slides for PPTX documents when structured output is enabled.
+// Slides are read from the source package in presentation order.
+func applySlides(result *ExtractionResult, config *ExtractionConfig, src *documentSource) error {
+	if config.StructuredOutput == nil || !*config.StructuredOutput || result.MimeType != mimeTypePPTX {
+		return nil
+	}
+	data, err := src.bytes()
+	if err != nil {
+		return err
+	}
+	slides, err := parsePptxSlides(data)
+	if err != nil {
+		return newParsingErrorWithContext("failed to read PPTX slides", err, ErrorCodeParsing, nil)
+	}
+	result.Slides = append(result.Slides, slides...)
+	return nil
+}
+
+// parsePptxSlides reads the slides of a PPTX package in the order of the presentation's
+// slide list.
+func parsePptxSlides(data []byte) ([]Slide, error) {
+	reader, err := zip.NewReader(bytes.NewReader(data), int64(len(data)))
+	if err != nil {
+		return nil, err
+	}
+	files := make(map[string]*zip.File, len(reader.File))
+	for _, file := range reader.File {
+		files[file.Name] = file
+	}
+	read := func(name string) ([]byte, bool, error) {
+		file, ok := files[name]
+		if !ok {
+			return nil, false, nil
+		}
+		rc, err := file.Open()
+		if err != nil {
+			return nil, false, err
+		}
+		defer rc.Close()
+		content, err := io.ReadAll(rc)
+		return content, err == nil, err
+	}
+
+	const presentation = "ppt/presentation.xml"
+	raw, ok, err := read(presentation)
+	if err != nil || !ok {
+		return nil, err
+	}
+	ids, err := pptxSlideIDs(raw)
+	if err != nil {
+		return nil, err
+	}
+	rels, err := pptxRelationships(read, presentation)
+	if err != nil {
+		return nil, err
+	}
+
+	slides := make([]Slide, 0, len(ids))
+	for _, id := range ids {
+		rel, ok := rels[id]
+		if !ok {
+			continue
+		}
+		name := rel.target
+		raw, ok, err := read(name)
+		if err != nil {
+			return nil, err
+		}
+		if !ok {
+			continue
+		}
+		slide, err := parsePptxSlide(raw)
+		if err != nil {
+			return nil, err
+		}
+		slide.Number = len(slides) + 1
+
+		slideRels, err := pptxRelationships(read, name)
+		if err != nil {
+			return nil, err
+		}
+		for _, rel := range slideRels {
+			if !strings.HasSuffix(rel.kind, "/slideLayout") {
+				continue
+			}
+			if raw, ok, err := read(rel.target); err != nil {
+				return nil, err
+			} else if ok {
+				slide.Layout, err = pptxLayoutName(raw)
+				if err != nil {
+					return nil, err
+				}
+			}
+		}
+		slides = append(slides, slide)
+	}
+	return slides, nil
+}
+
+// pptxRelationship is a relationship of a package part: its type URI and the package path
+// of its target.
+type pptxRelationship struct {
+	kind   string
+	target string
+}
+
+// pptxSlideIDs returns the relationship IDs of the slide list of presentation.xml.
+func pptxSlideIDs(raw []byte) ([]string, error) {
+	decoder := xml.NewDecoder(bytes.NewReader(raw))
+	var ids []string
+	for {
+		token, err := decoder.Token()
+		if errors.Is(err, io.EOF) {
+			return ids, nil
+		}
+		if err != nil {
+			return nil, err
+		}
+		if el, ok := token.(xml.StartElement); ok && el.Name.Local == "sldId" {
+			for _, attr := range el.Attr {
+				// The relationship ID is r:id; the unprefixed id is the slide's number.
+				if attr.Name.Local == "id" && attr.Name.Space != "" {
+					ids = append(ids, attr.Value)
+				}
+			}
+		}
+	}
+}
+
+// pptxRelationships reads the internal relationships of the package part name by
+// relationship ID.
+func pptxRelationships(read func(name string) ([]byte, bool, error), name string) (map[string]pptxRelationship, error) {
+	dir := path.Dir(name)
+	raw, ok, err := read(path.Join(dir, "_rels", path.Base(name)+".rels"))
+	if err != nil || !ok {
+		return nil, err
+	}
+	decoder := xml.NewDecoder(bytes.NewReader(raw))
+	rels := make(map[string]pptxRelationship)
+	for {
+		token, err := decoder.Token()
+		if errors.Is(err, io.EOF) {
+			return rels, nil
+		}
+		if err != nil {
+			return nil, err
+		}
+		el, ok := token.(xml.StartElement)
+		if !ok || el.Name.Local != "Relationship" || xmlAttr(el, "TargetMode") == "External" {
+			continue
+		}
+		target := xmlAttr(el, "Target")
+		if strings.HasPrefix(target, "/") {
+			target = strings.TrimPrefix(target, "/")
+		} else {
+			target = path.Join(dir, target)
+		}
+		rels[xmlAttr(el, "Id")] = pptxRelationship{kind: xmlAttr(el, "Type"), target: target}
+	}
+}
+
+// parsePptxSlide reads the title and text of a slide. The title is the text of the title
+// placeholder; every other paragraph with text goes to Content.
+func parsePptxSlide(raw []byte) (Slide, error) {
+	decoder := xml.NewDecoder(bytes.NewReader(raw))
+	var slide Slide
+	var title, body []string
+	var paragraph strings.Builder
+	isTitle, inText := false, false
+	for {
+		token, err := decoder.Token()
+		if errors.Is(err, io.EOF) {
+			break
+		}
+		if err != nil {
+			return slide, err
+		}
+		switch el := token.(type) {
+		case xml.StartElement:
+			switch el.Name.Local {
+			case "sp":
+				isTitle = false
+			case "ph":
+				kind := xmlAttr(el, "type")
+				isTitle = kind == "title" || kind == "ctrTitle"
+			case "t":
+				inText = true
+			case "br":
+				paragraph.WriteByte('\n')
+			case "p":
+				paragraph.Reset()
+			}
+		case xml.EndElement:
+			switch el.Name.Local {
+			case "t":
+				inText = false
+			case "p":
+				if text := strings.TrimSpace(paragraph.String()); text != "" {
+					if isTitle {
+						title = append(title, text)
+					} else {
+						body = append(body, text)
+					}
+				}
+			case "sp":
+				isTitle = false
+			}
+		case xml.CharData:
+			if inText {
+				paragraph.Write(el)
+			}
+		}
+	}
+	slide.Title = strings.Join(title, " ")
+	slide.Content = strings.Join(body, "\n")
+	return slide, nil
+}
+
+// pptxLayoutName returns the name of a slide layout, the name attribute of its p:cSld.
+func pptxLayoutName(raw []byte) (string, error) {
+	decoder := xml.NewDecoder(bytes.NewReader(raw))
+	for {
+		token, err := decoder.Token()
+		if errors.Is(err, io.EOF) {
+			return "", nil
+		}
+		if err != nil {
+			return "", err
+		}
+		if el, ok := token.(xml.StartElement); ok && el.Name.Local == "cSld" {
+			return xmlAttr(el, "name"), nil
+		}
+	}
+}
@@ -0,0 +1,91 @@
+package kreuzberg
+
+import (
+	"archive/zip"
+	"bytes"
+	"reflect"
+	"testing"
+)
+
+const (
+	testPptxRelNS   = `xmlns:r="http://schemas.openxmlformats.org/officeDocument/2006/relationships"`
+	testPptxSlideNS = `xmlns:p="http://schemas.openxmlformats.org/presentationml/2006/main" xmlns:a="http://schemas.openxmlformats.org/drawingml/2006/main"`
+	testPptxLayout  = "http://schemas.openxmlformats.org/officeDocument/2006/relationships/slideLayout"
+	testPptxSlide   = "http://schemas.openxmlformats.org/officeDocument/2006/relationships/slide"
+)
+
+// buildTestPptx returns a PPTX package with the given entries.
+func buildTestPptx(t *testing.T, entries map[string]string) []byte {
+	t.Helper()
+	var buf bytes.Buffer
+	zw := zip.NewWriter(&buf)
+	for name, content := range entries {
+		w, err := zw.Create(name)
+		if err != nil {
+			t.Fatalf("create %s: %v", name, err)
+		}
+		if _, err := w.Write([]byte(content)); err != nil {
+			t.Fatalf("write %s: %v", name, err)
+		}
+	}
+	if err := zw.Close(); err != nil {
+		t.Fatalf("close zip: %v", err)
+	}
+	return buf.Bytes()
+}
+
+func testPptxShape(placeholder string, paragraphs ...string) string {
+	shape := `<p:sp><p:nvSpPr><p:nvPr>`
+	if placeholder != "" {
+		shape += `<p:ph type="` + placeholder + `"/>`
+	}
+	shape += `</p:nvPr></p:nvSpPr><p:txBody>`
+	for _, text := range paragraphs {
+		shape += `<a:p><a:r><a:t>` + text + `</a:t></a:r></a:p>`
+	}
+	return shape + `</p:txBody></p:sp>`
+}
+
+func TestApplySlides(t *testing.T) {
+	slideRels := func(layout string) string {
+		return `<Relationships><Relationship Id="rId1" Type="` + testPptxLayout + `" Target="../slideLayouts/` + layout + `"/></Relationships>`
+	}
+	data := buildTestPptx(t, map[string]string{
+		"ppt/presentation.xml": `<p:presentation ` + testPptxSlideNS + ` ` + testPptxRelNS + `><p:sldIdLst>` +
+			`<p:sldId id="256" r:id="rId3"/><p:sldId id="257" r:id="rId2"/></p:sldIdLst></p:presentation>`,
+		"ppt/_rels/presentation.xml.rels": `<Relationships>` +
+			`<Relationship Id="rId2" Type="` + testPptxSlide + `" Target="slides/slide1.xml"/>` +
+			`<Relationship Id="rId3" Type="` + testPptxSlide + `" Target="/ppt/slides/slide2.xml"/></Relationships>`,
+		"ppt/slides/slide1.xml": `<p:sld ` + testPptxSlideNS + `><p:cSld><p:spTree>` +
+			testPptxShape("title", "Quarterly results") + testPptxShape("body", "Revenue up 12%", "Costs flat") +
+			`</p:spTree></p:cSld></p:sld>`,
+		"ppt/slides/_rels/slide1.xml.rels": slideRels("slideLayout2.xml"),
+		"ppt/slides/slide2.xml": `<p:sld ` + testPptxSlideNS + `><p:cSld><p:spTree>` +
+			testPptxShape("ctrTitle", "Welcome") + testPptxShape("", "Presented by the team") +
+			`</p:spTree></p:cSld></p:sld>`,
+		"ppt/slides/_rels/slide2.xml.rels":  slideRels("slideLayout1.xml"),
+		"ppt/slideLayouts/slideLayout1.xml": `<p:sldLayout ` + testPptxSlideNS + `><p:cSld name="Title Slide"/></p:sldLayout>`,
+		"ppt/slideLayouts/slideLayout2.xml": `<p:sldLayout ` + testPptxSlideNS + `><p:cSld name="Title and Content"/></p:sldLayout>`,
+	})
+
+	result := &ExtractionResult{MimeType: mimeTypePPTX}
+	if err := applySlides(result, NewExtractionConfig(WithStructuredOutput(true)), &documentSource{data: data}); err != nil {
+		t.Fatalf("applySlides failed: %v", err)
+	}
+	want := []Slide{
+		{Number: 1, Title: "Welcome", Layout: "Title Slide", Content: "Presented by the team"},
+		{Number: 2, Title: "Quarterly results", Layout: "Title and Content", Content: "Revenue up 12%\nCosts flat"},
+	}
+	if !reflect.DeepEqual(result.Slides, want) {
+		t.Fatalf("slides = %+v, want %+v", result.Slides, want)
+	}
+
+	disabled := &ExtractionResult{MimeType: mimeTypePPTX}
+	if err := applySlides(disabled, NewExtractionConfig(), &documentSource{data: data}); err != nil || disabled.Slides != nil {
+		t.Fatalf("slides read without structured output: %+v, %v", disabled.Slides, err)
+	}
+	other := &ExtractionResult{MimeType: mimeTypeDOCX}
+	if err := applySlides(other, NewExtractionConfig(WithStructuredOutput(true)), &documentSource{data: data}); err != nil || other.Slides != nil {
+		t.Fatalf("slides read for a non-presentation: %+v, %v", other.Slides, err)
+	}
+}
@@ -0,0 +1,33 @@
+package kreuzberg
+
+import "bytes"
+
+// applyRetainSource stores the source document on the result when source retention is
+// enabled, so the result can be extracted again with ReExtract.
+func applyRetainSource(result *ExtractionResult, config *ExtractionConfig, src *documentSource) error {
+	if config.RetainSource == nil || !*config.RetainSource || src == nil {
+		return nil
+	}
+	fromFile := src.data == nil && src.path != ""
+	data, err := src.bytes()
+	if err != nil {
+		return err
+	}
+	if !fromFile {
+		// Do not alias a buffer the caller may reuse.
+		data = bytes.Clone(data)
+	}
+	result.SourceBytes = data
+	result.SourceMime = result.MimeType
+	return nil
+}
+
+// ReExtract extracts the retained source document again with config, for comparing
+// extraction settings over stored results without the original files. The result must have
+// been produced with WithRetainSource(true); otherwise a ValidationError is returned.
+func (r *ExtractionResult) ReExtract(config *ExtractionConfig) (*ExtractionResult, error) {
+	if r == nil || len(r.SourceBytes) == 0 {
+		return nil, newValidationErrorWithContext("result has no retained source document; extract it with WithRetainSource(true)", nil, ErrorCodeValidation, nil)
+	}
+	return ExtractBytesSync(r.SourceBytes, r.SourceMime, config)
+}
@@ -0,0 +1,45 @@
+package kreuzberg
+
+import (
+	"bytes"
+	"errors"
+	"strings"
+	"testing"
+)
+
+func TestRetainSource(t *testing.T) {
+	registerTestExtractor(t)
+
+	data := []byte("original text")
+	result, err := ExtractBytesSync(data, testExtractorMime, NewExtractionConfig(WithRetainSource(true)))
+	if err != nil {
+		t.Fatalf("ExtractBytesSync: %v", err)
+	}
+	if !bytes.Equal(result.SourceBytes, data) || result.SourceMime != testExtractorMime {
+		t.Fatalf("source = %q (%s), want the input document", result.SourceBytes, result.SourceMime)
+	}
+	data[0] = 'X'
+	if result.SourceBytes[0] != 'o' {
+		t.Fatal("retained source must not alias the caller's buffer")
+	}
+
+	again, err := result.ReExtract(NewExtractionConfig(WithContentFilter(strings.ToLower)))
+	if err != nil {
+		t.Fatalf("ReExtract: %v", err)
+	}
+	if again.Content != "original text" || again.SourceBytes != nil {
+		t.Fatalf("unexpected re-extraction %q (source retained: %v)", again.Content, again.SourceBytes != nil)
+	}
+
+	plain, err := ExtractBytesSync([]byte("text"), testExtractorMime, nil)
+	if err != nil {
+		t.Fatalf("ExtractBytesSync: %v", err)
+	}
+	if plain.SourceBytes != nil {
+		t.Fatal("source must only be retained when enabled")
+	}
+	var validation *ValidationError
+	if _, err := plain.ReExtract(nil); !errors.As(err, &validation) {
+		t.Fatalf("expected ValidationError without a retained source, got %v", err)
+	}
+}
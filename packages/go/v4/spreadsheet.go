@@ -0,0 +1,103 @@
+package kreuzberg
+
+import (
+	"fmt"
+	"slices"
+	"strconv"
+	"strings"
+)
+
+// applySheetSelection drops the sheets not selected by config.Spreadsheet from a
+// spreadsheet result. The native extractor renders each sheet as a "## <name>" section
+// joined by blank lines and numbers sheet tables by their 1-based position, so both can be
+// filtered without re-reading the workbook. Metadata keeps describing the whole workbook.
+func applySheetSelection(result *ExtractionResult, config *ExtractionConfig) error {
+	selection := config.Spreadsheet
+	if selection == nil || (len(selection.SheetNames) == 0 && len(selection.SheetIndices) == 0) {
+		return nil
+	}
+	excel, ok := result.Metadata.ExcelMetadata()
+	if !ok {
+		return nil
+	}
+
+	selected := make(map[int]bool)
+	var unknown []string
+	for _, name := range selection.SheetNames {
+		index := slices.Index(excel.SheetNames, name)
+		if index < 0 {
+			unknown = append(unknown, strconv.Quote(name))
+			continue
+		}
+		selected[index] = true
+	}
+	for _, index := range selection.SheetIndices {
+		if index < 0 || index >= len(excel.SheetNames) {
+			unknown = append(unknown, strconv.Itoa(index))
+			continue
+		}
+		selected[index] = true
+	}
+	if len(unknown) > 0 {
+		available := make([]string, len(excel.SheetNames))
+		for i, name := range excel.SheetNames {
+			available[i] = strconv.Quote(name)
+		}
+		return newValidationErrorWithContext(
+			fmt.Sprintf("unknown sheets %s; available sheets: %s", strings.Join(unknown, ", "), strings.Join(available, ", ")),
+			nil, ErrorCodeValidation, nil,
+		)
+	}
+
+	if sections := splitSheetSections(result.Content, excel.SheetNames); sections != nil {
+		kept := make([]string, 0, len(selected))
+		for i, section := range sections {
+			if selected[i] {
+				kept = append(kept, section)
+			}
+		}
+		result.Content = strings.Join(kept, "\n\n")
+	}
+
+	tables := result.Tables[:0]
+	for _, table := range result.Tables {
+		if selected[table.PageNumber-1] {
+			tables = append(tables, table)
+		}
+	}
+	result.Tables = tables
+	return nil
+}
+
+// splitSheetSections splits spreadsheet content into one section per sheet. It returns nil
+// when the content does not have the expected "## <name>" layout.
+func splitSheetSections(content string, names []string) []string {
+	starts := make([]int, len(names))
+	pos := 0
+	for i, name := range names {
+		heading := "## " + name
+		if i == 0 {
+			if !strings.HasPrefix(content, heading) {
+				return nil
+			}
+			starts[i] = 0
+			continue
+		}
+		offset := strings.Index(content[pos:], "\n\n"+heading)
+		if offset < 0 {
+			return nil
+		}
+		starts[i] = pos + offset + 2
+		pos = starts[i]
+	}
+
+	sections := make([]string, len(names))
+	for i, start := range starts {
+		end := len(content)
+		if i+1 < len(starts) {
+			end = starts[i+1] - 2
+		}
+		sections[i] = content[start:end]
+	}
+	return sections
+}
@@ -0,0 +1,81 @@
+package kreuzberg
+
+import (
+	"errors"
+	"strings"
+	"testing"
+)
+
+func newWorkbookResult() *ExtractionResult {
+	return &ExtractionResult{
+		Content: "## Revenue\n\n| Q1 | Q2 |\n| --- | --- |\n| 1 | 2 |\n\n## Costs\n\n*Empty sheet*\n\n## Forecast\n\n| Q3 |\n| --- |\n| 3 |",
+		Tables: []Table{
+			{Cells: [][]string{{"Q1", "Q2"}, {"1", "2"}}, PageNumber: 1},
+			{Cells: [][]string{{"Q3"}, {"3"}}, PageNumber: 3},
+		},
+		Metadata: Metadata{Format: FormatMetadata{
+			Type:  FormatExcel,
+			Excel: &ExcelMetadata{SheetCount: 3, SheetNames: []string{"Revenue", "Costs", "Forecast"}},
+		}},
+	}
+}
+
+// TestSheetSelectionFiltersContentAndTables verifies that only selected sheets remain.
+func TestSheetSelectionFiltersContentAndTables(t *testing.T) {
+	result := newWorkbookResult()
+	config := NewExtractionConfig(WithSheets("Forecast"), WithSheetIndices(1))
+
+	if err := applySheetSelection(result, config); err != nil {
+		t.Fatalf("applySheetSelection failed: %v", err)
+	}
+
+	if want := "## Costs\n\n*Empty sheet*\n\n## Forecast\n\n| Q3 |\n| --- |\n| 3 |"; result.Content != want {
+		t.Errorf("unexpected content:\n%s", result.Content)
+	}
+	if len(result.Tables) != 1 || result.Tables[0].PageNumber != 3 {
+		t.Errorf("expected only the Forecast table, got %+v", result.Tables)
+	}
+}
+
+// TestSheetSelectionUnknownSheet verifies that unknown sheets produce a ValidationError
+// listing the available sheets.
+func TestSheetSelectionUnknownSheet(t *testing.T) {
+	result := newWorkbookResult()
+	err := applySheetSelection(result, NewExtractionConfig(WithSheets("Revenue", "Payroll")))
+
+	var validationErr *ValidationError
+	if !errors.As(err, &validationErr) {
+		t.Fatalf("expected ValidationError, got %v", err)
+	}
+	for _, name := range []string{"Payroll", "Revenue", "Costs", "Forecast"} {
+		if !strings.Contains(err.Error(), name) {
+			t.Errorf("error %q should mention %q", err.Error(), name)
+		}
+	}
+}
+
+// TestSheetSelectionMultiSheetWorkbook extracts a real workbook restricted to its first sheet.
+func TestSheetSelectionMultiSheetWorkbook(t *testing.T) {
+	path := getTestFilePath("spreadsheets/excel_multi_sheet.xlsx")
+	full, err := ExtractFileSync(path, nil)
+	if err != nil {
+		t.Skipf("spreadsheet extraction unavailable: %v", err)
+	}
+	excel, ok := full.Metadata.ExcelMetadata()
+	if !ok || len(excel.SheetNames) < 2 {
+		t.Skip("workbook does not expose multiple sheets")
+	}
+
+	result, err := ExtractFileSync(path, NewExtractionConfig(WithSheets(excel.SheetNames[0])))
+	if err != nil {
+		t.Fatalf("extraction with sheet selection failed: %v", err)
+	}
+	if strings.Contains(result.Content, "## "+excel.SheetNames[1]) {
+		t.Errorf("content should not include unselected sheet %q", excel.SheetNames[1])
+	}
+	for _, table := range result.Tables {
+		if table.PageNumber != 1 {
+			t.Errorf("unexpected table from sheet %d", table.PageNumber)
+		}
+	}
+}
@@ -0,0 +1,351 @@
+package kreuzberg
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"regexp"
+	"strings"
+)
+
+// StageFunc is a post-extraction stage of a Pipeline. It changes result in place and
+// returns an error to stop the pipeline.
+type StageFunc func(result *ExtractionResult) error
+
+// Pipeline is a sequence of stages run on an extraction result, for enrichment that should
+// be declared once and applied the same way to every document, such as redacting and then
+// normalizing whitespace. A Pipeline is safe for concurrent use once built.
+type Pipeline struct {
+	stages []StageFunc
+}
+
+// NewPipeline returns a Pipeline running stages in order. Nil stages are skipped.
+func NewPipeline(stages ...StageFunc) *Pipeline {
+	p := &Pipeline{}
+	return p.Then(stages...)
+}
+
+// Then appends stages to p and returns p. Nil stages are skipped.
+func (p *Pipeline) Then(stages ...StageFunc) *Pipeline {
+	for _, stage := range stages {
+		if stage != nil {
+			p.stages = append(p.stages, stage)
+		}
+	}
+	return p
+}
+
+// PipelineStageError identifies the stage of a Pipeline that failed.
+type PipelineStageError struct {
+	// Stage is the zero-based position of the stage in the pipeline.
+	Stage int
+	Err   error
+}
+
+func (e *PipelineStageError) Error() string {
+	return fmt.Sprintf("pipeline stage %d: %v", e.Stage, e.Err)
+}
+
+func (e *PipelineStageError) Unwrap() error {
+	return e.Err
+}
+
+// Run runs the stages of p on result in order, stopping at the first stage that fails and
+// returning its error as a *PipelineStageError. A nil Pipeline runs no stages.
+func (p *Pipeline) Run(result *ExtractionResult) error {
+	if p == nil {
+		return nil
+	}
+	for i, stage := range p.stages {
+		if err := stage(result); err != nil {
+			return &PipelineStageError{Stage: i, Err: err}
+		}
+	}
+	return nil
+}
+
+// ExtractFilePipeline extracts the file at path with config like ExtractFileSync and runs
+// p on the result. It returns the error of the extraction or of the first failing stage.
+func ExtractFilePipeline(path string, config *ExtractionConfig, p *Pipeline) (*ExtractionResult, error) {
+	result, err := ExtractFileSync(path, config)
+	if err != nil {
+		return nil, err
+	}
+	if err := p.Run(result); err != nil {
+		return nil, err
+	}
+	return result, nil
+}
+
+// RedactStage returns a stage replacing every match of patterns with replacement in every
+// text of the result: Content, chunks, pages, tables, blocks, slides, headers and footers,
+// annotations, revisions, colored spans, barcodes, warnings, the OCR text and captions of
+// images, metadata and UserData. Offsets into Content held by the result, such as page
+// boundaries, chunk byte ranges and the offset map, are moved to match. Fields that show the
+// original text but cannot be rewritten are dropped once anything was redacted:
+// SourceBytes, PageImages and HOCR, and the data of images whose OCR text matched.
+func RedactStage(replacement string, patterns ...*regexp.Regexp) StageFunc {
+	return func(result *ExtractionResult) error {
+		for _, pattern := range patterns {
+			if pattern != nil {
+				redactContent(result, pattern, replacement)
+			}
+		}
+		return nil
+	}
+}
+
+// redactContent replaces the non-empty matches of pattern in the text of result and
+// reports whether anything was replaced.
+func redactContent(result *ExtractionResult, pattern *regexp.Regexp, replacement string) bool {
+	redacted := false
+	replace := func(s string) string {
+		out := redactString(s, pattern, replacement)
+		if out != s {
+			redacted = true
+		}
+		return out
+	}
+
+	content := result.Content
+	matches := pattern.FindAllStringIndex(content, -1)
+	inContent := make([]bool, len(result.Chunks))
+	for i, chunk := range result.Chunks {
+		inContent[i] = result.chunkInContent(chunk)
+	}
+	if len(matches) > 0 {
+		var b strings.Builder
+		offsets := make([]int, len(content)+1)
+		last := 0
+		for _, match := range matches {
+			if match[0] == match[1] {
+				continue
+			}
+			for i := last; i < match[0]; i++ {
+				offsets[i] = b.Len() + i - last
+			}
+			b.WriteString(content[last:match[0]])
+			for i := match[0]; i < match[1]; i++ {
+				offsets[i] = b.Len()
+			}
+			b.WriteString(replacement)
+			last = match[1]
+		}
+		for i := last; i <= len(content); i++ {
+			offsets[i] = b.Len() + i - last
+		}
+		b.WriteString(content[last:])
+		if content := b.String(); content != result.Content {
+			remapContent(result, content, offsets)
+			redacted = true
+		}
+	}
+
+	for i := range result.Chunks {
+		chunk := &result.Chunks[i]
+		if start, end := chunk.Metadata.ByteStart, chunk.Metadata.ByteEnd; inContent[i] && start <= end && end <= uint64(len(result.Content)) {
+			chunk.Content = result.Content[start:end]
+		} else {
+			chunk.Content = replace(chunk.Content)
+		}
+	}
+	redactTables(result.Tables, replace)
+	for i := range result.Pages {
+		page := &result.Pages[i]
+		page.Content = replace(page.Content)
+		redactTables(page.Tables, replace)
+		if redactImages(page.Images, pattern, replacement) {
+			redacted = true
+		}
+	}
+	if redactImages(result.Images, pattern, replacement) {
+		redacted = true
+	}
+	for i := range result.Blocks {
+		result.Blocks[i].Content = replace(result.Blocks[i].Content)
+	}
+	for i := range result.Slides {
+		slide := &result.Slides[i]
+		slide.Title, slide.Content = replace(slide.Title), replace(slide.Content)
+	}
+	for i := range result.Headers {
+		result.Headers[i] = replace(result.Headers[i])
+	}
+	for i := range result.Footers {
+		result.Footers[i] = replace(result.Footers[i])
+	}
+	for i := range result.Annotations {
+		annotation := &result.Annotations[i]
+		annotation.Text, annotation.Author = replace(annotation.Text), replace(annotation.Author)
+	}
+	for i := range result.Revisions {
+		revision := &result.Revisions[i]
+		revision.Text, revision.Author = replace(revision.Text), replace(revision.Author)
+	}
+	for i := range result.Signatures {
+		signature := &result.Signatures[i]
+		signature.SignerName, signature.Reason = replace(signature.SignerName), replace(signature.Reason)
+	}
+	for i := range result.ColoredSpans {
+		result.ColoredSpans[i].Text = replace(result.ColoredSpans[i].Text)
+	}
+	for i := range result.Barcodes {
+		result.Barcodes[i].Value = replace(result.Barcodes[i].Value)
+	}
+	for i := range result.Warnings {
+		result.Warnings[i].Message = replace(result.Warnings[i].Message)
+	}
+	for key, value := range result.UserData {
+		result.UserData[key] = redactValue(value, replace)
+	}
+	redactMetadata(&result.Metadata, replace)
+
+	if result.textPositionsContent != result.Content {
+		result.textPositions, result.textPositionsContent = nil, ""
+	}
+	result.contentLower, result.contentLowerOf = "", ""
+	if redacted || pattern.MatchString(result.HOCR) {
+		result.SourceBytes = nil
+		result.PageImages = nil
+		result.HOCR = ""
+		redacted = true
+	}
+	return redacted
+}
+
+// redactTables applies replace to the cells, original cell text and Markdown of tables.
+func redactTables(tables []Table, replace func(string) string) {
+	for t := range tables {
+		table := &tables[t]
+		for _, row := range table.Cells {
+			for col, cell := range row {
+				row[col] = replace(cell)
+			}
+		}
+		for key, cell := range table.rawCells {
+			table.rawCells[key] = replace(cell)
+		}
+		table.Markdown = replace(table.Markdown)
+	}
+}
+
+// redactImages replaces the matches of pattern in the OCR text, OCR result, description and
+// caption of images, and drops the data of the images whose text matched, since the image
+// still shows it. It reports whether anything was replaced.
+func redactImages(images []ExtractedImage, pattern *regexp.Regexp, replacement string) bool {
+	redacted := false
+	for i := range images {
+		img := &images[i]
+		matched := false
+		if img.OCRResult != nil && redactContent(img.OCRResult, pattern, replacement) {
+			matched = true
+		}
+		for _, text := range []*string{&img.OCRText, &img.Caption, img.Description} {
+			if text == nil {
+				continue
+			}
+			if out := redactString(*text, pattern, replacement); out != *text {
+				*text = out
+				matched = true
+			}
+		}
+		if matched {
+			img.Data = nil
+			redacted = true
+		}
+	}
+	return redacted
+}
+
+// redactString replaces the non-empty matches of pattern in s with replacement.
+func redactString(s string, pattern *regexp.Regexp, replacement string) string {
+	return pattern.ReplaceAllStringFunc(s, func(match string) string {
+		if match == "" {
+			return match
+		}
+		return replacement
+	})
+}
+
+// redactValue applies replace to the strings of a JSON-like value: strings, slices and maps
+// of them, nested to any depth. Other values are returned unchanged.
+func redactValue(value any, replace func(string) string) any {
+	switch v := value.(type) {
+	case string:
+		return replace(v)
+	case []string:
+		for i := range v {
+			v[i] = replace(v[i])
+		}
+	case []any:
+		for i := range v {
+			v[i] = redactValue(v[i], replace)
+		}
+	case map[string]string:
+		for key, item := range v {
+			v[key] = replace(item)
+		}
+	case map[string]any:
+		for key, item := range v {
+			v[key] = redactValue(item, replace)
+		}
+	}
+	return value
+}
+
+// redactMetadata applies replace to every string of m, including the format metadata and
+// the additional fields, by rewriting the strings of its JSON form.
+func redactMetadata(m *Metadata, replace func(string) string) {
+	data, err := json.Marshal(m)
+	if err != nil {
+		return
+	}
+	decoder := json.NewDecoder(bytes.NewReader(data))
+	decoder.UseNumber()
+	var decoded any
+	if err := decoder.Decode(&decoded); err != nil {
+		return
+	}
+	changed := false
+	redactValue(decoded, func(s string) string {
+		out := replace(s)
+		if out != s {
+			changed = true
+		}
+		return out
+	})
+	if !changed {
+		return
+	}
+	data, err = json.Marshal(decoded)
+	if err != nil {
+		return
+	}
+	var redacted Metadata
+	if err := json.Unmarshal(data, &redacted); err != nil {
+		return
+	}
+	// The page structure holds no text and is not part of the JSON form.
+	redacted.PageStructure = m.PageStructure
+	*m = redacted
+}
+
+// NormalizeStage returns a stage normalizing the whitespace of Content like
+// WithWhitespaceNormalization with mode WhitespaceTrim or WhitespaceAggressive. Any other
+// mode fails the stage with a ValidationError, except WhitespaceNone, which leaves Content
+// unchanged.
+func NormalizeStage(mode string) StageFunc {
+	return func(result *ExtractionResult) error {
+		switch mode {
+		case WhitespaceNone:
+			return nil
+		case WhitespaceTrim, WhitespaceAggressive:
+		default:
+			return newValidationErrorWithContext(
+				fmt.Sprintf("invalid whitespace normalization: %q (must be %q, %q or %q)", mode, WhitespaceNone, WhitespaceTrim, WhitespaceAggressive),
+				nil, ErrorCodeValidation, nil)
+		}
+		applyWhitespaceNormalization(result, &ExtractionConfig{WhitespaceNormalization: &mode})
+		return nil
+	}
+}
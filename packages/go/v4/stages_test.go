@@ -0,0 +1,163 @@
+package kreuzberg
+
+import (
+	"encoding/json"
+	"errors"
+	"os"
+	"path/filepath"
+	"regexp"
+	"strings"
+	"testing"
+)
+
+var testEmailPattern = regexp.MustCompile(`[a-z]+@example\.com`)
+
+func TestPipelineStages(t *testing.T) {
+	content := "Mail bob@example.com   now.\n\n\n\nPage two: alice@example.com"
+	result := &ExtractionResult{
+		Content: content,
+		Chunks: []Chunk{
+			{Content: content[:27], Metadata: ChunkMetadata{ByteStart: 0, ByteEnd: 27}},
+			{Content: content[31:], Metadata: ChunkMetadata{ByteStart: 31, ByteEnd: uint64(len(content))}},
+		},
+		Tables: []Table{{Cells: [][]string{{"contact"}, {"bob@example.com"}}, Markdown: "| contact |\n| --- |\n| bob@example.com |"}},
+	}
+
+	var seen string
+	p := NewPipeline(RedactStage("[email]", testEmailPattern), NormalizeStage(WhitespaceAggressive)).
+		Then(func(r *ExtractionResult) error {
+			seen = r.Content
+			return nil
+		})
+	if err := p.Run(result); err != nil {
+		t.Fatalf("Run failed: %v", err)
+	}
+
+	want := "Mail [email] now.\n\nPage two: [email]"
+	if result.Content != want || seen != want {
+		t.Fatalf("content = %q, custom stage saw %q, want %q", result.Content, seen, want)
+	}
+	for i, chunk := range result.Chunks {
+		start, end := chunk.Metadata.ByteStart, chunk.Metadata.ByteEnd
+		if got := result.Content[start:end]; got != []string{"Mail [email] now.", "Page two: [email]"}[i] {
+			t.Errorf("chunk %d offsets locate %q", i, got)
+		}
+	}
+	if result.Chunks[1].Content != "Page two: [email]" {
+		t.Errorf("chunk content not redacted: %q", result.Chunks[1].Content)
+	}
+	if table := result.Tables[0]; table.Cells[1][0] != "[email]" || table.Markdown != "| contact |\n| --- |\n| [email] |" {
+		t.Errorf("table not redacted: %+v", table)
+	}
+}
+
+func TestRedactStageCoversEveryField(t *testing.T) {
+	const email = "bob@example.com"
+	content := "Contact " + email + " today"
+	title := "Notes for " + email
+	description := "scan of " + email
+	result := &ExtractionResult{
+		Content: content,
+		Metadata: Metadata{
+			Subject:       &title,
+			Format:        FormatMetadata{Type: FormatPDF, Pdf: &PdfMetadata{Title: &title, Authors: []string{email}}},
+			Additional:    map[string]json.RawMessage{"owner": json.RawMessage(`"` + email + `"`)},
+			PageStructure: &PageStructure{Boundaries: []PageBoundary{{ByteStart: 0, ByteEnd: uint64(len(content)), PageNumber: 1}}},
+		},
+		Tables:       []Table{{Cells: [][]string{{email}}, Markdown: "| " + email + " |", rawCells: map[[2]int]string{{0, 0}: email}}},
+		Chunks:       []Chunk{{Content: "note: " + email}},
+		Images:       []ExtractedImage{{Data: []byte{1}, OCRText: email, Description: &description, OCRResult: &ExtractionResult{Content: email}}},
+		PageImages:   []ExtractedImage{{Data: []byte{2}}},
+		Pages:        []PageContent{{PageNumber: 1, Content: content, Tables: []Table{{Cells: [][]string{{email}}}}}},
+		Annotations:  []Annotation{{Text: "ask " + email, Author: email}},
+		Revisions:    []Revision{{Text: email}},
+		ColoredSpans: []ColoredSpan{{Text: email, ByteStart: 8, ByteEnd: 8 + len(email)}},
+		Slides:       []Slide{{Title: email, Content: email}},
+		Headers:      []string{email},
+		Footers:      []string{email},
+		Warnings:     []ExtractionWarning{{Message: "cell " + email + " not parsed"}},
+		Barcodes:     []Barcode{{Value: "mailto:" + email}},
+		Blocks:       []Block{{Content: content}},
+		HOCR:         "<span class='ocrx_word'>bob@example.com</span>",
+		OffsetMap:    []OffsetEntry{{Start: 0, End: len(content), Page: 1}},
+		SourceBytes:  []byte(content),
+		UserData:     map[string]any{"requester": email, "cc": []any{email}},
+
+		textPositions:        []textPosition{{start: 0, end: len(content), page: 1}},
+		textPositionsContent: content,
+	}
+	if err := RedactStage("[email]", testEmailPattern)(result); err != nil {
+		t.Fatal(err)
+	}
+
+	data, err := json.Marshal(result)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if strings.Contains(string(data), email) {
+		t.Fatalf("redacted result still contains the match: %s", data)
+	}
+	if result.SourceBytes != nil || result.PageImages != nil || result.HOCR != "" || result.Images[0].Data != nil {
+		t.Errorf("fields that cannot be rewritten were kept: %+v", result)
+	}
+	if cell := result.Tables[0].rawCells[[2]int{0, 0}]; cell != "[email]" || strings.Contains(result.textPositionsContent, email) {
+		t.Errorf("unexported text not redacted: raw cell %q, positions of %q", cell, result.textPositionsContent)
+	}
+	if span := result.ColoredSpans[0]; result.Content[span.ByteStart:span.ByteEnd] != "[email]" {
+		t.Errorf("colored span locates %q", result.Content[span.ByteStart:span.ByteEnd])
+	}
+	if entry := result.OffsetMap[0]; entry.End != len(result.Content) {
+		t.Errorf("offset map entry %+v not moved to the redacted content %q", entry, result.Content)
+	}
+	if title, ok := result.Metadata.PdfMetadata(); !ok || *title.Title != "Notes for [email]" || result.Metadata.PageStructure == nil {
+		t.Errorf("metadata not redacted: %+v", result.Metadata)
+	}
+}
+
+func TestPipelineShortCircuits(t *testing.T) {
+	failure := errors.New("enrichment unavailable")
+	ran := false
+	p := NewPipeline(
+		func(*ExtractionResult) error { return nil },
+		func(*ExtractionResult) error { return failure },
+		func(*ExtractionResult) error { ran = true; return nil },
+	)
+	err := p.Run(&ExtractionResult{})
+	var stageErr *PipelineStageError
+	if !errors.As(err, &stageErr) || stageErr.Stage != 1 || !errors.Is(err, failure) {
+		t.Fatalf("expected stage 1 to fail with the stage error, got %v", err)
+	}
+	if ran {
+		t.Fatal("stage after the failing one ran")
+	}
+
+	var valErr *ValidationError
+	if err := NewPipeline(NormalizeStage("squash")).Run(&ExtractionResult{}); !errors.As(err, &valErr) {
+		t.Fatalf("expected ValidationError for an unknown mode, got %v", err)
+	}
+	var nilPipeline *Pipeline
+	if err := nilPipeline.Run(&ExtractionResult{}); err != nil {
+		t.Fatalf("nil pipeline failed: %v", err)
+	}
+}
+
+func TestExtractFilePipeline(t *testing.T) {
+	config := registerChanTestExtractor(t)
+	path := filepath.Join(t.TempDir(), "notes.txt")
+	if err := os.WriteFile(path, []byte("Reach carol@example.com  "), 0o600); err != nil {
+		t.Fatal(err)
+	}
+	p := NewPipeline(RedactStage("***", testEmailPattern), NormalizeStage(WhitespaceTrim))
+	result, err := ExtractFilePipeline(path, config, p)
+	if err != nil {
+		t.Fatalf("ExtractFilePipeline failed: %v", err)
+	}
+	if result.Content != "Reach ***" {
+		t.Fatalf("content = %q", result.Content)
+	}
+
+	failing := NewPipeline(func(*ExtractionResult) error { return errors.New("boom") })
+	if result, err := ExtractFilePipeline(path, config, failing); err == nil || result != nil {
+		t.Fatalf("expected the stage error and no result, got %v, %v", result, err)
+	}
+}
@@ -0,0 +1,44 @@
+package kreuzberg
+
+// ResultStats summarizes the size of an extraction result. ContentLength is in bytes.
+type ResultStats struct {
+	ContentLength    int    `json:"content_length"`
+	TableCount       int    `json:"table_count"`
+	ImageCount       int    `json:"image_count"`
+	TotalImageBytes  int    `json:"total_image_bytes"`
+	ChunkCount       int    `json:"chunk_count"`
+	PageCount        int    `json:"page_count"`
+	DetectedLanguage string `json:"detected_language,omitempty"`
+}
+
+// TotalImageBytes returns the combined size of the data of the extracted images. It returns 0
+// for a nil result.
+func (r *ExtractionResult) TotalImageBytes() int {
+	if r == nil {
+		return 0
+	}
+	total := 0
+	for _, img := range r.Images {
+		total += len(img.Data)
+	}
+	return total
+}
+
+// Stats returns the sizes and counts of the result in one value, with the page count and
+// detected language reported as by GetPageCount and GetDetectedLanguage. It returns the zero
+// ResultStats for a nil result.
+func (r *ExtractionResult) Stats() ResultStats {
+	if r == nil {
+		return ResultStats{}
+	}
+	stats := ResultStats{
+		ContentLength:   len(r.Content),
+		TableCount:      len(r.Tables),
+		ImageCount:      len(r.Images),
+		TotalImageBytes: r.TotalImageBytes(),
+		ChunkCount:      len(r.Chunks),
+	}
+	stats.PageCount, _ = r.GetPageCount()
+	stats.DetectedLanguage, _ = r.GetDetectedLanguage()
+	return stats
+}
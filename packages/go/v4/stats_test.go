@@ -0,0 +1,40 @@
+package kreuzberg
+
+import "testing"
+
+func TestResultStats(t *testing.T) {
+	var nilResult *ExtractionResult
+	if nilResult.TotalImageBytes() != 0 || nilResult.Stats() != (ResultStats{}) {
+		t.Fatal("nil results must report zero stats")
+	}
+	if (&ExtractionResult{}).Stats() != (ResultStats{}) {
+		t.Fatal("empty results must report zero stats")
+	}
+
+	language := "de"
+	result := &ExtractionResult{
+		Content: "Grüße",
+		Tables:  []Table{{}, {}},
+		Images:  []ExtractedImage{{Data: make([]byte, 100)}, {Data: make([]byte, 28)}, {}},
+		Chunks:  []Chunk{{}},
+		Metadata: Metadata{
+			Language:      &language,
+			PageStructure: &PageStructure{TotalCount: 4},
+		},
+	}
+	want := ResultStats{
+		ContentLength:    len("Grüße"),
+		TableCount:       2,
+		ImageCount:       3,
+		TotalImageBytes:  128,
+		ChunkCount:       1,
+		PageCount:        4,
+		DetectedLanguage: "de",
+	}
+	if got := result.Stats(); got != want {
+		t.Fatalf("Stats() = %+v, want %+v", got, want)
+	}
+	if result.TotalImageBytes() != 128 {
+		t.Fatalf("TotalImageBytes() = %d, want 128", result.TotalImageBytes())
+	}
+}
@@ -0,0 +1,253 @@
+package kreuzberg
+
+import (
+	"bufio"
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"strconv"
+	"strings"
+	"unicode/utf8"
+)
+
+// csvStreamSample is how much of a delimited stream is inspected to detect its dialect.
+const csvStreamSample = 64 << 10
+
+func isNDJSONMimeType(mimeType string) bool {
+	switch normalizeMimeType(mimeType) {
+	case "application/x-ndjson", "application/ndjson", "application/jsonl", "application/x-jsonlines", "application/jsonlines":
+		return true
+	default:
+		return false
+	}
+}
+
+// ExtractRecordsStream reads the CSV, TSV or NDJSON document from r and calls fn with each
+// record in order, for data files too large to hold in an ExtractionResult. Only the
+// current record is kept in memory, so files of any size are read with bounded memory.
+//
+// For CSV and TSV each record is a row, header rows included, split as applyCSVTable
+// splits them: the dialect is detected from the start of the stream unless
+// SpreadsheetOptions.CSVDelimiter is set, and blank lines are skipped. For NDJSON
+// (application/x-ndjson and the other JSON Lines types) each line holds a JSON object
+// whose fields are flattened into path and value pairs, in the order they appear: nested
+// object fields are named by dot-joined keys, array elements by their index, and null
+// becomes "". {"id": 1, "tags": ["a"]} is the record ["id", "1", "tags.0", "a"]. Blank
+// lines are skipped, and a line that is not a JSON object fails with a ParsingError.
+//
+// The input must be UTF-8; a leading byte order mark is dropped. The document is not
+// sent to the native library, and the other settings of config do not apply. When fn
+// returns an error, reading stops and that error is returned.
+func ExtractRecordsStream(r io.Reader, mimeType string, config *ExtractionConfig, fn func(record []string) error) error {
+	return ExtractRecordsStreamWithContext(context.Background(), r, mimeType, config, fn)
+}
+
+// ExtractRecordsStreamWithContext is ExtractRecordsStream stopping with ctx.Err() when ctx
+// is done. ctx is checked before each record, so a read from r that blocks is not
+// interrupted.
+func ExtractRecordsStreamWithContext(ctx context.Context, r io.Reader, mimeType string, config *ExtractionConfig, fn func(record []string) error) error {
+	config = configOrDefault(config)
+	if err := validateConfig(config); err != nil {
+		return err
+	}
+	if r == nil || fn == nil {
+		return newValidationErrorWithContext("reader and record callback are required", nil, ErrorCodeValidation, nil)
+	}
+	emit := func(record []string) error {
+		if err := ctx.Err(); err != nil {
+			return err
+		}
+		return fn(record)
+	}
+
+	reader := bufio.NewReaderSize(r, csvStreamSample)
+	if bom, _ := reader.Peek(3); bytes.Equal(bom, []byte{0xEF, 0xBB, 0xBF}) {
+		_, _ = reader.Discard(3)
+	}
+	var err error
+	switch {
+	case isDelimitedMimeType(mimeType):
+		err = streamDelimited(reader, csvStreamDialect(reader, mimeType, config), emit)
+	case isNDJSONMimeType(mimeType):
+		err = streamNDJSON(reader, emit)
+	default:
+		return newUnsupportedFormatErrorWithContext(mimeType,
+			fmt.Sprintf("records cannot be streamed from %q documents; only CSV, TSV and NDJSON are supported", mimeType),
+			nil, ErrorCodeUnsupportedFormat, nil)
+	}
+	var readErr *recordReadError
+	if errors.As(err, &readErr) {
+		return newIOErrorWithContext("failed to read records", readErr.err, ErrorCodeIo, nil)
+	}
+	return err
+}
+
+// recordReadError wraps an error returned by the reader of a record stream, to tell it apart
+// from errors returned by the callback.
+type recordReadError struct {
+	err error
+}
+
+func (e *recordReadError) Error() string { return e.err.Error() }
+
+// csvStreamDialect detects the dialect of the delimited stream from its buffered start.
+func csvStreamDialect(reader *bufio.Reader, mimeType string, config *ExtractionConfig) csvDialect {
+	sample, _ := reader.Peek(csvStreamSample)
+	text := string(sample)
+	if len(sample) == csvStreamSample {
+		if cut := strings.LastIndexByte(text, '\n'); cut > 0 {
+			text = text[:cut]
+		}
+	}
+	if config != nil && config.Spreadsheet != nil && config.Spreadsheet.CSVDelimiter != "" {
+		delimiter, _ := utf8.DecodeRuneInString(config.Spreadsheet.CSVDelimiter)
+		return csvDialect{delimiter: delimiter, quote: detectCSVQuote(text, delimiter)}
+	}
+	return detectCSVDialect(text, normalizeMimeType(mimeType) == "text/tab-separated-values")
+}
+
+// streamDelimited splits the stream into rows like parseDelimited and passes each to emit.
+func streamDelimited(reader *bufio.Reader, dialect csvDialect, emit func([]string) error) error {
+	var row []string
+	var field strings.Builder
+	inQuotes, quoted := false, false
+	endRow := func() error {
+		row = append(row, field.String())
+		field.Reset()
+		quoted = false
+		record := row
+		row = nil
+		if len(record) > 1 || record[0] != "" {
+			return emit(record)
+		}
+		return nil
+	}
+
+	for {
+		r, _, err := reader.ReadRune()
+		if err == io.EOF {
+			break
+		}
+		if err != nil {
+			return &recordReadError{err: err}
+		}
+		switch {
+		case inQuotes:
+			if r != dialect.quote {
+				field.WriteRune(r)
+			} else if next, _, err := reader.ReadRune(); err == nil && next == dialect.quote {
+				field.WriteRune(r)
+			} else {
+				if err == nil {
+					_ = reader.UnreadRune()
+				}
+				inQuotes = false
+			}
+		case r == dialect.quote && field.Len() == 0 && !quoted:
+			inQuotes, quoted = true, true
+		case r == dialect.delimiter:
+			row = append(row, field.String())
+			field.Reset()
+			quoted = false
+		case r == '\r':
+			if next, _ := reader.Peek(1); len(next) == 0 || next[0] != '\n' {
+				field.WriteRune(r)
+			}
+		case r == '\n':
+			if err := endRow(); err != nil {
+				return err
+			}
+		default:
+			field.WriteRune(r)
+		}
+	}
+	if field.Len() > 0 || len(row) > 0 || quoted {
+		return endRow()
+	}
+	return nil
+}
+
+// streamNDJSON passes the flattened object on each non-blank line of the stream to emit.
+func streamNDJSON(reader *bufio.Reader, emit func([]string) error) error {
+	for lineNumber := 1; ; lineNumber++ {
+		line, err := reader.ReadBytes('\n')
+		if err != nil && err != io.EOF {
+			return &recordReadError{err: err}
+		}
+		if trimmed := bytes.TrimSpace(line); len(trimmed) > 0 {
+			record, parseErr := flattenJSONObject(trimmed)
+			if parseErr != nil {
+				return newParsingErrorWithContext(fmt.Sprintf("invalid NDJSON record on line %d", lineNumber), parseErr, ErrorCodeParsing, nil)
+			}
+			if emitErr := emit(record); emitErr != nil {
+				return emitErr
+			}
+		}
+		if err == io.EOF {
+			return nil
+		}
+	}
+}
+
+// flattenJSONObject returns the path and value pairs of the JSON object in data.
+func flattenJSONObject(data []byte) ([]string, error) {
+	if data[0] != '{' {
+		return nil, errors.New("record is not a JSON object")
+	}
+	dec := json.NewDecoder(bytes.NewReader(data))
+	dec.UseNumber()
+	record, err := flattenJSONValue(dec, "", []string{})
+	if err != nil {
+		return nil, err
+	}
+	if _, err := dec.Token(); err != io.EOF {
+		return nil, errors.New("unexpected data after the JSON object")
+	}
+	return record, nil
+}
+
+// flattenJSONValue appends the pairs of the next JSON value of dec, found at path, to
+// record.
+func flattenJSONValue(dec *json.Decoder, path string, record []string) ([]string, error) {
+	tok, err := dec.Token()
+	if err != nil {
+		return nil, err
+	}
+	join := func(key string) string {
+		if path == "" {
+			return key
+		}
+		return path + "." + key
+	}
+	switch v := tok.(type) {
+	case json.Delim:
+		for i := 0; dec.More(); i++ {
+			key := strconv.Itoa(i)
+			if v == '{' {
+				keyTok, err := dec.Token()
+				if err != nil {
+					return nil, err
+				}
+				key, _ = keyTok.(string)
+			}
+			if record, err = flattenJSONValue(dec, join(key), record); err != nil {
+				return nil, err
+			}
+		}
+		if _, err := dec.Token(); err != nil {
+			return nil, err
+		}
+		return record, nil
+	case string:
+		return append(record, path, v), nil
+	case json.Number:
+		return append(record, path, v.String()), nil
+	case bool:
+		return append(record, path, strconv.FormatBool(v)), nil
+	default:
+		return append(record, path, ""), nil
+	}
+}
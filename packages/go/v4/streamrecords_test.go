@@ -0,0 +1,109 @@
+package kreuzberg
+
+import (
+	"context"
+	"errors"
+	"io"
+	"reflect"
+	"strings"
+	"testing"
+)
+
+func collectRecords(t *testing.T, input, mimeType string, config *ExtractionConfig) [][]string {
+	t.Helper()
+	var records [][]string
+	err := ExtractRecordsStream(strings.NewReader(input), mimeType, config, func(record []string) error {
+		records = append(records, record)
+		return nil
+	})
+	if err != nil {
+		t.Fatalf("ExtractRecordsStream failed: %v", err)
+	}
+	return records
+}
+
+func TestExtractRecordsStreamCSV(t *testing.T) {
+	input := "\ufeffname;note\r\nAda;\"semi; \"\"quoted\"\"\nline\"\n\nBob;plain"
+	want := [][]string{{"name", "note"}, {"Ada", "semi; \"quoted\"\nline"}, {"Bob", "plain"}}
+	if got := collectRecords(t, input, "text/csv", nil); !reflect.DeepEqual(got, want) {
+		t.Fatalf("records = %q, want %q", got, want)
+	}
+	if got := parseDelimited(strings.TrimPrefix(input, "\ufeff"), csvDialect{delimiter: ';', quote: '"'}, -1); !reflect.DeepEqual(got, want) {
+		t.Fatalf("streamed records differ from parseDelimited: %q", got)
+	}
+
+	config := NewExtractionConfig(WithCSVDelimiter('|'))
+	if got := collectRecords(t, "a|b,c\n", "text/csv", config); !reflect.DeepEqual(got, [][]string{{"a", "b,c"}}) {
+		t.Fatalf("configured delimiter not used: %q", got)
+	}
+}
+
+func TestExtractRecordsStreamNDJSON(t *testing.T) {
+	input := `{"id": 1, "user": {"name": "Ada", "admin": true}, "tags": ["x", "y"], "note": null}` + "\n\n" + `{"id": 2}`
+	want := [][]string{
+		{"id", "1", "user.name", "Ada", "user.admin", "true", "tags.0", "x", "tags.1", "y", "note", ""},
+		{"id", "2"},
+	}
+	if got := collectRecords(t, input, "application/x-ndjson", nil); !reflect.DeepEqual(got, want) {
+		t.Fatalf("records = %q, want %q", got, want)
+	}
+
+	err := ExtractRecordsStream(strings.NewReader("{\"ok\": 1}\n[1, 2]\n"), "application/x-ndjson", nil, func([]string) error { return nil })
+	var parseErr *ParsingError
+	if !errors.As(err, &parseErr) || !strings.Contains(err.Error(), "line 2") {
+		t.Fatalf("expected a ParsingError for line 2, got %v", err)
+	}
+}
+
+func TestExtractRecordsStreamStops(t *testing.T) {
+	// An endless stream only terminates if records are delivered as they are read.
+	endless := io.MultiReader(strings.NewReader("a,b\n"), repeatReader("1,2\n"))
+	stop := errors.New("enough")
+	count := 0
+	err := ExtractRecordsStream(endless, "text/csv", nil, func([]string) error {
+		if count++; count == 1000 {
+			return stop
+		}
+		return nil
+	})
+	if !errors.Is(err, stop) {
+		t.Fatalf("expected the callback error, got %v", err)
+	}
+
+	ctx, cancel := context.WithCancel(context.Background())
+	count = 0
+	err = ExtractRecordsStreamWithContext(ctx, repeatReader("{\"n\": 1}\n"), "application/x-ndjson", nil, func([]string) error {
+		if count++; count == 10 {
+			cancel()
+		}
+		return nil
+	})
+	if !errors.Is(err, context.Canceled) || count != 10 {
+		t.Fatalf("expected cancellation after 10 records, got %v after %d", err, count)
+	}
+
+	var unsupported *UnsupportedFormatError
+	if err := ExtractRecordsStream(strings.NewReader("x"), "application/pdf", nil, func([]string) error { return nil }); !errors.As(err, &unsupported) {
+		t.Fatalf("expected UnsupportedFormatError, got %v", err)
+	}
+}
+
+// repeatReader returns an endless reader repeating s.
+func repeatReader(s string) io.Reader {
+	return &repeatingReader{s: s}
+}
+
+type repeatingReader struct {
+	s   string
+	pos int
+}
+
+func (r *repeatingReader) Read(p []byte) (int, error) {
+	n := 0
+	for n < len(p) {
+		copied := copy(p[n:], r.s[r.pos:])
+		n += copied
+		r.pos = (r.pos + copied) % len(r.s)
+	}
+	return n, nil
+}
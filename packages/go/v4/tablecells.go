@@ -0,0 +1,212 @@
+package kreuzberg
+
+import (
+	"bytes"
+	"encoding/xml"
+	"errors"
+	"io"
+	"strconv"
+)
+
+// CellRange is a merged cell of a Table: the cell at Row and Col, both zero-based indices
+// into Table.Cells, spans RowSpan rows and ColSpan columns.
+type CellRange struct {
+	Row     int `json:"row"`
+	Col     int `json:"col"`
+	RowSpan int `json:"row_span"`
+	ColSpan int `json:"col_span"`
+}
+
+// TableCell is a cell of Table.CellGrid. A merged cell is reported at its top-left
+// position with its spans; the other positions it covers are continuation cells.
+type TableCell struct {
+	Text           string
+	RowSpan        int
+	ColSpan        int
+	IsContinuation bool
+	// Nested is the table held in the cell, parsed from the Markdown pipe table in Text,
+	// or nil; see Table.IsNested.
+	Nested *Table
+}
+
+// CellGrid returns the cells of the table with their merge geometry, in the shape of
+// Cells. Ordinary cells span one row and one column. A merged cell has its full spans at
+// its top-left position, and every other position it covers is marked IsContinuation,
+// keeps the text of Cells (normally empty) and spans one row and one column. A cell holding
+// a nested table has it parsed into Nested.
+//
+// Merges are known for DOCX tables extracted with WithTableMerges, whose w:gridSpan and
+// w:vMerge cell properties are read from the source document; see Table.MergedCells. Other
+// tables, which the native extractor reports without span information, have no merged
+// cells.
+func (t *Table) CellGrid() [][]TableCell {
+	if t == nil {
+		return nil
+	}
+	grid := make([][]TableCell, len(t.Cells))
+	for r, row := range t.Cells {
+		grid[r] = make([]TableCell, len(row))
+		for c, text := range row {
+			grid[r][c] = TableCell{Text: text, RowSpan: 1, ColSpan: 1}
+			if nested, ok := parseNestedTable(text); ok {
+				grid[r][c].Nested = nested
+			}
+		}
+	}
+	for _, merge := range t.MergedCells {
+		if merge.Row < 0 || merge.Col < 0 || merge.Row >= len(grid) || merge.Col >= len(grid[merge.Row]) {
+			continue
+		}
+		grid[merge.Row][merge.Col].RowSpan = merge.RowSpan
+		grid[merge.Row][merge.Col].ColSpan = merge.ColSpan
+		for r := merge.Row; r < merge.Row+merge.RowSpan && r < len(grid); r++ {
+			for c := merge.Col; c < merge.Col+merge.ColSpan && c < len(grid[r]); c++ {
+				if r != merge.Row || c != merge.Col {
+					grid[r][c].IsContinuation = true
+				}
+			}
+		}
+	}
+	return grid
+}
+
+// applyTableMerges fills Table.MergedCells for the tables of DOCX documents when
+// WithTableMerges is set. The tables of
+// word/document.xml are matched to result.Tables in document order; a table is only given
+// merges when its number of rows and columns agree with the native one, so a document the
+// native extractor laid out differently is left without span information.
+func applyTableMerges(result *ExtractionResult, config *ExtractionConfig, src *documentSource) error {
+	if config.TableMerges == nil || !*config.TableMerges || result.MimeType != mimeTypeDOCX || len(result.Tables) == 0 {
+		return nil
+	}
+	data, err := src.bytes()
+	if err != nil {
+		return err
+	}
+	// Span information is an extra; a package the native extractor could read but this
+	// parser cannot leaves the tables without it rather than failing the extraction.
+	tables, err := parseDocxTableMerges(data)
+	if err != nil || len(tables) != len(result.Tables) {
+		return nil
+	}
+	for i, layout := range tables {
+		table := &result.Tables[i]
+		if len(layout.merges) == 0 || len(table.Cells) != layout.rows {
+			continue
+		}
+		matches := true
+		for _, row := range table.Cells {
+			matches = matches && len(row) == layout.cols
+		}
+		if matches {
+			table.MergedCells = layout.merges
+		}
+	}
+	return nil
+}
+
+// docxTableLayout is the grid size and the merged cells of a w:tbl.
+type docxTableLayout struct {
+	rows, cols int
+	merges     []CellRange
+}
+
+// parseDocxTableMerges reads the grid layout of every table of word/document.xml, in order
+// of the tables' start tags, so a nested table follows the table containing it.
+func parseDocxTableMerges(data []byte) ([]docxTableLayout, error) {
+	raw, ok, err := readZipEntry(data, "word/document.xml")
+	if err != nil || !ok {
+		return nil, err
+	}
+
+	type tableState struct {
+		index int
+		col   int
+		// open maps a grid column to the index in merges of the cell that a w:vMerge
+		// continuation in that column extends.
+		open map[int]int
+		// span and vMerge hold the properties of the current w:tc.
+		span         int
+		vMerge       string
+		hasVMerge    bool
+		cellRow      int
+		cellStartCol int
+	}
+	var layouts []docxTableLayout
+	var stack []*tableState
+
+	decoder := xml.NewDecoder(bytes.NewReader(raw))
+	for {
+		token, err := decoder.Token()
+		if errors.Is(err, io.EOF) {
+			break
+		}
+		if err != nil {
+			return nil, err
+		}
+		var current *tableState
+		if len(stack) > 0 {
+			current = stack[len(stack)-1]
+		}
+
+		switch el := token.(type) {
+		case xml.StartElement:
+			switch el.Name.Local {
+			case "tbl":
+				stack = append(stack, &tableState{index: len(layouts), open: make(map[int]int)})
+				layouts = append(layouts, docxTableLayout{})
+			case "tr":
+				if current != nil {
+					current.col = 0
+				}
+			case "tc":
+				if current != nil {
+					current.span, current.vMerge, current.hasVMerge = 1, "", false
+					current.cellRow, current.cellStartCol = layouts[current.index].rows, current.col
+				}
+			case "gridSpan":
+				if current != nil {
+					if n, err := strconv.Atoi(xmlAttr(el, "val")); err == nil && n > 1 {
+						current.span = n
+					}
+				}
+			case "vMerge":
+				if current != nil {
+					current.hasVMerge, current.vMerge = true, xmlAttr(el, "val")
+				}
+			}
+		case xml.EndElement:
+			if current == nil {
+				continue
+			}
+			layout := &layouts[current.index]
+			switch el.Name.Local {
+			case "tbl":
+				stack = stack[:len(stack)-1]
+				var merges []CellRange
+				for _, merge := range layout.merges {
+					if merge.RowSpan > 1 || merge.ColSpan > 1 {
+						merges = append(merges, merge)
+					}
+				}
+				layout.merges = merges
+			case "tc":
+				col := current.cellStartCol
+				if current.hasVMerge && current.vMerge != "restart" {
+					if anchor, ok := current.open[col]; ok && layout.merges[anchor].Row+layout.merges[anchor].RowSpan == current.cellRow {
+						layout.merges[anchor].RowSpan++
+						current.col += current.span
+						continue
+					}
+				}
+				layout.merges = append(layout.merges, CellRange{Row: current.cellRow, Col: col, RowSpan: 1, ColSpan: current.span})
+				current.open[col] = len(layout.merges) - 1
+				current.col += current.span
+			case "tr":
+				layout.rows++
+				layout.cols = max(layout.cols, current.col)
+			}
+		}
+	}
+	return layouts, nil
+}
@@ -0,0 +1,103 @@
+package kreuzberg
+
+import (
+	"archive/zip"
+	"bytes"
+	"slices"
+	"testing"
+)
+
+const testDocxTables = `<?xml version="1.0" encoding="UTF-8"?>
+<w:document xmlns:w="http://schemas.openxmlformats.org/wordprocessingml/2006/main"><w:body><w:tbl>
+  <w:tr>
+    <w:tc><w:tcPr><w:gridSpan w:val="2"/></w:tcPr><w:p><w:r><w:t>A</w:t></w:r></w:p></w:tc>
+    <w:tc><w:tcPr><w:vMerge w:val="restart"/></w:tcPr><w:p><w:r><w:t>B</w:t></w:r></w:p></w:tc>
+  </w:tr>
+  <w:tr>
+    <w:tc><w:p><w:r><w:t>C</w:t></w:r></w:p></w:tc>
+    <w:tc><w:p><w:r><w:t>D</w:t></w:r></w:p></w:tc>
+    <w:tc><w:tcPr><w:vMerge/></w:tcPr><w:p/></w:tc>
+  </w:tr>
+  <w:tr>
+    <w:tc><w:tbl><w:tr><w:tc><w:tcPr><w:gridSpan w:val="3"/></w:tcPr><w:p><w:r><w:t>N</w:t></w:r></w:p></w:tc></w:tr></w:tbl><w:p/></w:tc>
+    <w:tc><w:p><w:r><w:t>F</w:t></w:r></w:p></w:tc>
+    <w:tc><w:p><w:r><w:t>G</w:t></w:r></w:p></w:tc>
+  </w:tr>
+</w:tbl></w:body></w:document>`
+
+func buildDocxWithDocument(t *testing.T, document string) []byte {
+	t.Helper()
+	var buf bytes.Buffer
+	zw := zip.NewWriter(&buf)
+	w, err := zw.Create("word/document.xml")
+	if err != nil {
+		t.Fatalf("create document entry: %v", err)
+	}
+	if _, err := w.Write([]byte(document)); err != nil {
+		t.Fatalf("write document entry: %v", err)
+	}
+	if err := zw.Close(); err != nil {
+		t.Fatalf("close zip: %v", err)
+	}
+	return buf.Bytes()
+}
+
+func TestParseDocxTableMerges(t *testing.T) {
+	layouts, err := parseDocxTableMerges(buildDocxWithDocument(t, testDocxTables))
+	if err != nil {
+		t.Fatalf("parse: %v", err)
+	}
+	if len(layouts) != 2 {
+		t.Fatalf("expected the outer and the nested table, got %+v", layouts)
+	}
+	outer, nested := layouts[0], layouts[1]
+	want := []CellRange{{Row: 0, Col: 0, RowSpan: 1, ColSpan: 2}, {Row: 0, Col: 2, RowSpan: 2, ColSpan: 1}}
+	if outer.rows != 3 || outer.cols != 3 || !slices.Equal(outer.merges, want) {
+		t.Fatalf("outer table = %+v, want 3x3 with %v", outer, want)
+	}
+	if nested.rows != 1 || nested.cols != 3 || !slices.Equal(nested.merges, []CellRange{{Row: 0, Col: 0, RowSpan: 1, ColSpan: 3}}) {
+		t.Fatalf("nested table = %+v", nested)
+	}
+}
+
+func TestTableCellGrid(t *testing.T) {
+	result := &ExtractionResult{MimeType: mimeTypeDOCX, Tables: []Table{
+		{Cells: [][]string{{"A", "", "B"}, {"C", "D", ""}, {"N", "F", "G"}}},
+		{Cells: [][]string{{"N"}}},
+	}}
+	src := &documentSource{data: buildDocxWithDocument(t, testDocxTables)}
+	if err := applyTableMerges(result, NewExtractionConfig(), src); err != nil {
+		t.Fatalf("applyTableMerges: %v", err)
+	}
+	if result.Tables[0].MergedCells != nil {
+		t.Fatalf("expected no merges without WithTableMerges, got %v", result.Tables[0].MergedCells)
+	}
+	if err := applyTableMerges(result, NewExtractionConfig(WithTableMerges(true)), src); err != nil {
+		t.Fatalf("applyTableMerges: %v", err)
+	}
+	if result.Tables[1].MergedCells != nil {
+		t.Fatalf("expected no merges for a table whose shape differs, got %v", result.Tables[1].MergedCells)
+	}
+
+	grid := result.Tables[0].CellGrid()
+	if got := grid[0][0]; got != (TableCell{Text: "A", RowSpan: 1, ColSpan: 2}) {
+		t.Fatalf("grid[0][0] = %+v", got)
+	}
+	if got := grid[0][1]; !got.IsContinuation || got.RowSpan != 1 || got.ColSpan != 1 {
+		t.Fatalf("grid[0][1] = %+v", got)
+	}
+	if got := grid[0][2]; got != (TableCell{Text: "B", RowSpan: 2, ColSpan: 1}) {
+		t.Fatalf("grid[0][2] = %+v", got)
+	}
+	if got := grid[1][2]; !got.IsContinuation {
+		t.Fatalf("grid[1][2] = %+v", got)
+	}
+	if got := grid[2][1]; got != (TableCell{Text: "F", RowSpan: 1, ColSpan: 1}) {
+		t.Fatalf("grid[2][1] = %+v", got)
+	}
+
+	plain := Table{Cells: [][]string{{"x", ""}}}
+	if grid := plain.CellGrid(); grid[0][1].IsContinuation || grid[0][1].ColSpan != 1 {
+		t.Fatalf("expected a table without merges to have plain cells, got %+v", grid)
+	}
+}
@@ -0,0 +1,69 @@
+package kreuzberg
+
+// applyTableLimits drops tables with fewer cells than ExtractionConfig.TableMinArea or fewer
+// rows or columns than ExtractionConfig.MinTableRows and MinTableCols, and then keeps the
+// first ExtractionConfig.MaxTables of the remaining ones, in document order. The
+// tables of PageContent entries are trimmed to the ones kept. Content is left as extracted.
+func applyTableLimits(result *ExtractionResult, config *ExtractionConfig) {
+	minArea := 0
+	if config.TableMinArea != nil {
+		minArea = *config.TableMinArea
+	}
+	minRows, minCols := 0, 0
+	if config.MinTableRows != nil {
+		minRows = *config.MinTableRows
+	}
+	if config.MinTableCols != nil {
+		minCols = *config.MinTableCols
+	}
+	if (minArea <= 0 && minRows <= 0 && minCols <= 0 && config.MaxTables == nil) || len(result.Tables) == 0 {
+		return
+	}
+
+	type tableKey struct {
+		page     int
+		markdown string
+	}
+	kept := result.Tables[:0]
+	keptKeys := make(map[tableKey]int)
+	for _, table := range result.Tables {
+		rows, cols := table.size()
+		if rows*cols < minArea || rows < minRows || cols < minCols || (config.MaxTables != nil && len(kept) >= *config.MaxTables) {
+			continue
+		}
+		kept = append(kept, table)
+		keptKeys[tableKey{table.PageNumber, table.Markdown}]++
+	}
+	clear(result.Tables[len(kept):])
+	result.Tables = kept
+
+	for i := range result.Pages {
+		tables := result.Pages[i].Tables[:0]
+		for _, table := range result.Pages[i].Tables {
+			key := tableKey{table.PageNumber, table.Markdown}
+			if keptKeys[key] > 0 {
+				keptKeys[key]--
+				tables = append(tables, table)
+			}
+		}
+		clear(result.Pages[i].Tables[len(tables):])
+		result.Pages[i].Tables = tables
+	}
+}
+
+// size returns the number of rows of t and the length of its longest row.
+func (t *Table) size() (rows, cols int) {
+	for _, row := range t.Cells {
+		cols = max(cols, len(row))
+	}
+	return len(t.Cells), cols
+}
+
+// FirstTable returns the first table of the document, after any MaxTables, TableMinArea and
+// MinTableRows and MinTableCols limits, and false when there is none.
+func (r *ExtractionResult) FirstTable() (*Table, bool) {
+	if r == nil || len(r.Tables) == 0 {
+		return nil, false
+	}
+	return &r.Tables[0], true
+}
@@ -0,0 +1,73 @@
+package kreuzberg
+
+import (
+	"errors"
+	"testing"
+)
+
+func TestApplyTableLimits(t *testing.T) {
+	layout := Table{Cells: [][]string{{"logo"}}, Markdown: "| logo |", PageNumber: 1}
+	items := Table{Cells: [][]string{{"item", "qty"}, {"pen", "2"}}, Markdown: "| item | qty |", PageNumber: 1}
+	totals := Table{Cells: [][]string{{"net", "vat"}, {"10", "2"}}, Markdown: "| net | vat |", PageNumber: 2}
+	result := &ExtractionResult{
+		Tables: []Table{layout, items, totals},
+		Pages: []PageContent{
+			{PageNumber: 1, Tables: []Table{layout, items}},
+			{PageNumber: 2, Tables: []Table{totals}},
+		},
+	}
+	applyTableLimits(result, NewExtractionConfig(WithMaxTables(1), WithTableMinArea(2)))
+
+	first, ok := result.FirstTable()
+	if !ok || len(result.Tables) != 1 || first.Markdown != items.Markdown {
+		t.Fatalf("expected only the items table, got %+v", result.Tables)
+	}
+	if len(result.Pages[0].Tables) != 1 || result.Pages[0].Tables[0].Markdown != items.Markdown || len(result.Pages[1].Tables) != 0 {
+		t.Fatalf("unexpected page tables %+v", result.Pages)
+	}
+
+	if _, ok := (&ExtractionResult{}).FirstTable(); ok {
+		t.Fatal("expected no first table for an empty result")
+	}
+}
+
+func TestApplyMinTableSize(t *testing.T) {
+	columns := Table{Cells: [][]string{{"left column text", "right column text"}}, Markdown: "| left | right |", PageNumber: 1}
+	narrow := Table{Cells: [][]string{{"a"}, {"b"}, {"c"}}, Markdown: "| a |", PageNumber: 1}
+	data := Table{Cells: [][]string{{"item", "qty"}, {"pen", "2"}}, Markdown: "| item | qty |", PageNumber: 2}
+	result := &ExtractionResult{
+		Content: "left column text right column text",
+		Tables:  []Table{columns, narrow, data},
+		Pages: []PageContent{
+			{PageNumber: 1, Tables: []Table{columns, narrow}},
+			{PageNumber: 2, Tables: []Table{data}},
+		},
+	}
+	applyTableLimits(result, NewExtractionConfig(WithMinTableSize(2, 2), WithMaxTables(1)))
+
+	if len(result.Tables) != 1 || result.Tables[0].Markdown != data.Markdown {
+		t.Fatalf("expected only the data table, got %+v", result.Tables)
+	}
+	if len(result.Pages[0].Tables) != 0 || len(result.Pages[1].Tables) != 1 {
+		t.Fatalf("unexpected page tables %+v", result.Pages)
+	}
+	if result.Content != "left column text right column text" {
+		t.Fatalf("content changed: %q", result.Content)
+	}
+}
+
+func TestTableLimitsValidation(t *testing.T) {
+	var valErr *ValidationError
+	if err := validateConfig(NewExtractionConfig(WithMaxTables(0))); !errors.As(err, &valErr) {
+		t.Fatalf("expected ValidationError for max tables 0, got %v", err)
+	}
+	if err := validateConfig(NewExtractionConfig(WithTableMinArea(-1))); !errors.As(err, &valErr) {
+		t.Fatalf("expected ValidationError for a negative min area, got %v", err)
+	}
+	if err := validateConfig(NewExtractionConfig(WithMinTableSize(2, -1))); !errors.As(err, &valErr) {
+		t.Fatalf("expected ValidationError for negative min columns, got %v", err)
+	}
+	if err := validateConfig(NewExtractionConfig(WithMaxTables(1), WithTableMinArea(0), WithMinTableSize(0, 2))); err != nil {
+		t.Fatalf("valid limits rejected: %v", err)
+	}
+}
@@ -0,0 +1,173 @@
+package kreuzberg
+
+import "strings"
+
+// IsNested reports whether a cell of the table holds a nested table. The native extractor
+// reports a table inside a cell as a Markdown pipe table in the text of that cell: a header
+// row, a delimiter row such as "| --- | --- |" and any body rows.
+func (t *Table) IsNested() bool {
+	if t == nil {
+		return false
+	}
+	for _, row := range t.Cells {
+		for _, cell := range row {
+			if _, ok := parseNestedTable(cell); ok {
+				return true
+			}
+		}
+	}
+	return false
+}
+
+// Flatten returns a copy of the table with its nested tables merged into one grid, for
+// consumers that cannot handle nesting. The cells of a nested table become additional rows
+// and columns: each row of the table is expanded to as many rows as the tallest nested table
+// in it, and each column to as many columns as the widest nested table in it. A nested table
+// keeps its header row as its first row, and text of its cell outside it is put before the
+// text of its first cell. An ordinary cell in an expanded row or column covers the whole
+// expansion and is reported in MergedCells, as are the table's own merged cells, so CellGrid
+// of the flat table shows which cells belong together. ColumnTypes are dropped when nesting
+// adds columns. A table without nested tables is returned as a copy; the nested structure
+// remains available from CellGrid of the original table, as TableCell.Nested.
+func (t *Table) Flatten() *Table {
+	if t == nil {
+		return nil
+	}
+	grid := t.CellGrid()
+	rows, cols := t.size()
+
+	// heights[r] and widths[c] are the number of flat rows and columns that row r and column
+	// c of the table expand to.
+	heights := make([]int, rows)
+	widths := make([]int, cols)
+	for r := range heights {
+		heights[r] = 1
+	}
+	for c := range widths {
+		widths[c] = 1
+	}
+	for r, row := range grid {
+		for c, cell := range row {
+			if cell.Nested != nil && !cell.IsContinuation {
+				nestedRows, nestedCols := cell.Nested.size()
+				heights[r] = max(heights[r], nestedRows)
+				widths[c] = max(widths[c], nestedCols)
+			}
+		}
+	}
+	rowStart := make([]int, rows+1)
+	for r, height := range heights {
+		rowStart[r+1] = rowStart[r] + height
+	}
+	colStart := make([]int, cols+1)
+	for c, width := range widths {
+		colStart[c+1] = colStart[c] + width
+	}
+
+	cells := make([][]string, rowStart[rows])
+	for r := range cells {
+		cells[r] = make([]string, colStart[cols])
+	}
+	var merges []CellRange
+	for r, row := range grid {
+		for c, cell := range row {
+			if cell.IsContinuation {
+				continue
+			}
+			top, left := rowStart[r], colStart[c]
+			if cell.Nested != nil {
+				for nr, nestedRow := range cell.Nested.Cells {
+					copy(cells[top+nr][left:], nestedRow)
+				}
+				continue
+			}
+			cells[top][left] = cell.Text
+			bottom := rowStart[min(r+max(cell.RowSpan, 1), rows)]
+			right := colStart[min(c+max(cell.ColSpan, 1), cols)]
+			if bottom-top > 1 || right-left > 1 {
+				merges = append(merges, CellRange{Row: top, Col: left, RowSpan: bottom - top, ColSpan: right - left})
+			}
+		}
+	}
+
+	flat := &Table{
+		Cells:       cells,
+		Markdown:    tableMarkdown(cells),
+		PageNumber:  t.PageNumber,
+		HasHeader:   t.HasHeader,
+		MergedCells: merges,
+	}
+	if colStart[cols] == cols {
+		flat.ColumnTypes = append([]ColumnType(nil), t.ColumnTypes...)
+	}
+	return flat
+}
+
+// parseNestedTable returns the Markdown pipe table held in the text of a cell. Text of the
+// cell around the table is joined by spaces and put before the text of its first cell.
+func parseNestedTable(text string) (*Table, bool) {
+	if !strings.Contains(text, "\n") {
+		return nil, false
+	}
+	lines := strings.Split(text, "\n")
+	for start := 0; start+1 < len(lines); start++ {
+		if !isPipeRow(lines[start]) || !isPipeDelimiterRow(lines[start+1]) {
+			continue
+		}
+		end := start + 2
+		for end < len(lines) && isPipeRow(lines[end]) {
+			end++
+		}
+		cells := [][]string{splitPipeRow(lines[start])}
+		for _, line := range lines[start+2 : end] {
+			cells = append(cells, splitPipeRow(line))
+		}
+		outside := strings.Join(strings.Fields(strings.Join(append(append([]string(nil), lines[:start]...), lines[end:]...), " ")), " ")
+		if outside != "" {
+			cells[0][0] = strings.TrimSpace(outside + " " + cells[0][0])
+		}
+		return &Table{Cells: cells, Markdown: tableMarkdown(cells)}, true
+	}
+	return nil, false
+}
+
+// isPipeRow reports whether line is a row of a Markdown pipe table.
+func isPipeRow(line string) bool {
+	line = strings.TrimSpace(line)
+	return len(line) >= 2 && strings.HasPrefix(line, "|") && strings.HasSuffix(line, "|")
+}
+
+// isPipeDelimiterRow reports whether line is the delimiter row of a Markdown pipe table.
+func isPipeDelimiterRow(line string) bool {
+	if !isPipeRow(line) {
+		return false
+	}
+	for _, cell := range splitPipeRow(line) {
+		cell = strings.Trim(cell, ":")
+		if cell == "" || strings.Trim(cell, "-") != "" {
+			return false
+		}
+	}
+	return true
+}
+
+// splitPipeRow returns the cells of a Markdown pipe table row, unescaping "\|".
+func splitPipeRow(line string) []string {
+	line = strings.TrimSpace(line)
+	line = strings.TrimSuffix(strings.TrimPrefix(line, "|"), "|")
+	var cells []string
+	var cell strings.Builder
+	for i := 0; i < len(line); i++ {
+		switch {
+		case line[i] == '\\' && i+1 < len(line) && line[i+1] == '|':
+			cell.WriteByte('|')
+			i++
+		case line[i] == '|':
+			cells = append(cells, strings.TrimSpace(cell.String()))
+			cell.Reset()
+		default:
+			cell.WriteByte(line[i])
+		}
+	}
+	return append(cells, strings.TrimSpace(cell.String()))
+}
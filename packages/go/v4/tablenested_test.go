@@ -0,0 +1,72 @@
+package kreuzberg
+
+import (
+	"reflect"
+	"testing"
+)
+
+func TestTableFlatten(t *testing.T) {
+	table := &Table{
+		Cells: [][]string{
+			{"Name", "Details"},
+			{"Widget", "Specs:\n| Size | Weight |\n| --- | ---: |\n| 10cm | 2\\|3kg |"},
+			{"Gadget", "none"},
+		},
+		PageNumber:  2,
+		HasHeader:   true,
+		ColumnTypes: []ColumnType{ColumnTypeString, ColumnTypeString},
+	}
+	if !table.IsNested() {
+		t.Fatal("expected nested table")
+	}
+
+	grid := table.CellGrid()
+	nested := grid[1][1].Nested
+	if nested == nil || !reflect.DeepEqual(nested.Cells, [][]string{{"Specs: Size", "Weight"}, {"10cm", "2|3kg"}}) {
+		t.Fatalf("unexpected nested table %+v", nested)
+	}
+
+	flat := table.Flatten()
+	want := [][]string{
+		{"Name", "Details", ""},
+		{"Widget", "Specs: Size", "Weight"},
+		{"", "10cm", "2|3kg"},
+		{"Gadget", "none", ""},
+	}
+	if !reflect.DeepEqual(flat.Cells, want) {
+		t.Fatalf("flat cells = %q, want %q", flat.Cells, want)
+	}
+	wantMerges := []CellRange{
+		{Row: 0, Col: 1, RowSpan: 1, ColSpan: 2},
+		{Row: 1, Col: 0, RowSpan: 2, ColSpan: 1},
+		{Row: 3, Col: 1, RowSpan: 1, ColSpan: 2},
+	}
+	if !reflect.DeepEqual(flat.MergedCells, wantMerges) {
+		t.Fatalf("merged cells = %+v, want %+v", flat.MergedCells, wantMerges)
+	}
+	if flat.IsNested() || flat.PageNumber != 2 || !flat.HasHeader || flat.ColumnTypes != nil || flat.Markdown == "" {
+		t.Fatalf("unexpected flat table %+v", flat)
+	}
+	if len(table.Cells) != 3 || table.CellGrid()[1][1].Nested == nil {
+		t.Fatal("original table was modified")
+	}
+}
+
+func TestTableFlattenWithoutNesting(t *testing.T) {
+	table := &Table{
+		Cells:       [][]string{{"A", ""}, {"| not a table |", "B"}},
+		MergedCells: []CellRange{{Row: 0, Col: 0, RowSpan: 1, ColSpan: 2}},
+		ColumnTypes: []ColumnType{ColumnTypeString, ColumnTypeString},
+	}
+	if table.IsNested() {
+		t.Fatal("unexpected nested table")
+	}
+	flat := table.Flatten()
+	if !reflect.DeepEqual(flat.Cells, table.Cells) || !reflect.DeepEqual(flat.MergedCells, table.MergedCells) ||
+		!reflect.DeepEqual(flat.ColumnTypes, table.ColumnTypes) {
+		t.Fatalf("flattening a flat table changed it: %+v", flat)
+	}
+	if (*Table)(nil).Flatten() != nil || (*Table)(nil).IsNested() {
+		t.Fatal("nil table not handled")
+	}
+}
@@ -0,0 +1,272 @@
+package kreuzberg
+
+import (
+	"fmt"
+	"strings"
+	"unicode"
+	"unicode/utf8"
+)
+
+// numberFormat gives the separators numbers are written with in a locale.
+type numberFormat struct {
+	decimal rune
+	// groups lists the separators accepted between digit groups.
+	groups string
+	// indian selects the Indian grouping of 12,34,567, with groups of two digits before the
+	// last group of three.
+	indian bool
+}
+
+const numberSpaces = " \u00a0\u202f"
+
+var (
+	decimalPoint     = numberFormat{decimal: '.', groups: ","}
+	decimalComma     = numberFormat{decimal: ',', groups: "." + numberSpaces}
+	decimalCommaThin = numberFormat{decimal: ',', groups: numberSpaces}
+)
+
+// numberFormats maps ISO 639-1 language codes to the number format of the language.
+var numberFormats = map[string]numberFormat{
+	"en": decimalPoint, "ja": decimalPoint, "zh": decimalPoint, "ko": decimalPoint, "he": decimalPoint,
+	"th": decimalPoint, "ms": decimalPoint, "ga": decimalPoint,
+	"hi": {decimal: '.', groups: ",", indian: true},
+	"de": decimalComma, "es": decimalComma, "it": decimalComma, "pt": decimalComma, "nl": decimalComma,
+	"da": decimalComma, "tr": decimalComma, "id": decimalComma, "el": decimalComma, "ro": decimalComma,
+	"fr": decimalCommaThin, "ru": decimalCommaThin, "pl": decimalCommaThin, "cs": decimalCommaThin,
+	"sk": decimalCommaThin, "sv": decimalCommaThin, "nb": decimalCommaThin, "no": decimalCommaThin,
+	"fi": decimalCommaThin, "uk": decimalCommaThin, "hu": decimalCommaThin, "bg": decimalCommaThin,
+}
+
+// numberRegionFormats maps language and region pairs whose number format differs from that
+// of their language.
+var numberRegionFormats = map[string]numberFormat{
+	"de-ch": {decimal: '.', groups: "'’"},
+	"de-li": {decimal: '.', groups: "'’"},
+	"it-ch": {decimal: '.', groups: "'’"},
+	"en-in": {decimal: '.', groups: ",", indian: true},
+	"en-za": decimalCommaThin,
+	"es-mx": decimalPoint,
+	"es-us": decimalPoint,
+}
+
+// currencyCodes are the currency abbreviations stripped from numbers along with currency
+// symbols.
+var currencyCodes = []string{"USD", "EUR", "GBP", "JPY", "CHF", "CAD", "AUD", "NZD", "CNY", "INR", "SEK", "NOK",
+	"DKK", "PLN", "CZK", "HUF", "BRL", "MXN", "ZAR", "RUB", "TRY", "R$", "kr", "zł", "Kč", "Fr."}
+
+// numberFormatFor returns the number format of locale, a language code with an optional
+// region such as "en-US" or "de_CH".
+func numberFormatFor(locale string) (numberFormat, bool) {
+	locale = strings.ReplaceAll(strings.ToLower(strings.TrimSpace(locale)), "_", "-")
+	if format, ok := numberRegionFormats[locale]; ok {
+		return format, true
+	}
+	lang, _, _ := strings.Cut(locale, "-")
+	format, ok := numberFormats[lang]
+	return format, ok
+}
+
+// RawCell returns the text of the cell at row and col as extracted, before
+// WithNormalizeTableNumbers rewrote it, or the cell as it is when it was not rewritten. It
+// returns "" outside the table.
+func (t *Table) RawCell(row, col int) string {
+	if t == nil || row < 0 || row >= len(t.Cells) || col < 0 || col >= len(t.Cells[row]) {
+		return ""
+	}
+	if raw, ok := t.rawCells[[2]int{row, col}]; ok {
+		return raw
+	}
+	return t.Cells[row][col]
+}
+
+// applyTableNumbers rewrites the numeric cells of tables in the canonical form of
+// WithNormalizeTableNumbers and reports the numeric-looking cells it cannot read.
+func applyTableNumbers(result *ExtractionResult, config *ExtractionConfig) {
+	if config.NormalizeTableNumbers == nil {
+		return
+	}
+	locale := *config.NormalizeTableNumbers
+	format, ok := numberFormatFor(locale)
+	if !ok {
+		// validateConfig reports unknown locales.
+		return
+	}
+	preserve := config.PreserveCellWhitespace != nil && *config.PreserveCellWhitespace
+	for i := range result.Tables {
+		table := &result.Tables[i]
+		for _, cell := range normalizeTableNumbers(table, format, preserve) {
+			result.addWarning(WarningCodeNumberNotNormalized, table.PageNumber, fmt.Sprintf(
+				"table %d, row %d, column %d: %q is not a number in locale %s", i+1, cell[0]+1, cell[1]+1, table.Cells[cell[0]][cell[1]], locale))
+		}
+	}
+	for i := range result.Pages {
+		for j := range result.Pages[i].Tables {
+			normalizeTableNumbers(&result.Pages[i].Tables[j], format, preserve)
+		}
+	}
+}
+
+// normalizeTableNumbers rewrites the numeric cells of table, keeping their text for RawCell,
+// and renders its Markdown and infers its ColumnTypes again when a cell changed. It returns
+// the positions of the cells that look numeric but cannot be read in format.
+func normalizeTableNumbers(table *Table, format numberFormat, preserveWhitespace bool) [][2]int {
+	var failed [][2]int
+	changed := false
+	for r, row := range table.Cells {
+		for c, cell := range row {
+			canonical, numeric, ok := canonicalNumber(cell, format)
+			if !numeric {
+				continue
+			}
+			if !ok {
+				failed = append(failed, [2]int{r, c})
+				continue
+			}
+			if canonical == cell {
+				continue
+			}
+			if table.rawCells == nil {
+				table.rawCells = make(map[[2]int]string)
+			}
+			table.rawCells[[2]int{r, c}] = cell
+			row[c] = canonical
+			changed = true
+		}
+	}
+	if changed {
+		table.Markdown = renderTableMarkdown(table.Cells, preserveWhitespace)
+		if table.ColumnTypes != nil {
+			dataRows := table.Cells
+			if table.HasHeader && len(dataRows) > 0 {
+				dataRows = dataRows[1:]
+			}
+			table.ColumnTypes = inferColumnTypes(dataRows)
+		}
+	}
+	return failed
+}
+
+// canonicalNumber rewrites cell as an optional minus sign, digits and an optional fraction
+// after a period, followed by a percent sign when cell has one. Currency symbols and codes
+// are dropped, and parentheses around a number make it negative. numeric reports whether
+// cell looks like a number: digits with number punctuation, signs, currency and percent
+// signs around them; ok reports whether it is a valid number in format.
+func canonicalNumber(cell string, format numberFormat) (canonical string, numeric, ok bool) {
+	s := strings.TrimSpace(cell)
+	negative, signed, parenthesized, percent := false, false, false, false
+	for changed := true; changed; {
+		changed = false
+		s = strings.TrimSpace(s)
+		switch {
+		case !parenthesized && !signed && len(s) >= 2 && s[0] == '(' && s[len(s)-1] == ')':
+			s, negative, parenthesized, changed = s[1:len(s)-1], true, true, true
+		case !signed && !parenthesized && (strings.HasPrefix(s, "-") || strings.HasPrefix(s, "−") || strings.HasPrefix(s, "+")):
+			negative = s[0] != '+'
+			_, size := utf8.DecodeRuneInString(s)
+			s, signed, changed = s[size:], true, true
+		case !percent && strings.HasSuffix(s, "%"):
+			s, percent, changed = strings.TrimSuffix(s, "%"), true, true
+		default:
+			s, changed = trimCurrency(s)
+		}
+	}
+
+	digits := false
+	for _, r := range s {
+		switch {
+		case r >= '0' && r <= '9':
+			digits = true
+		case r == '.' || r == ',' || r == format.decimal || strings.ContainsRune(format.groups, r):
+		default:
+			return "", false, false
+		}
+	}
+	if !digits {
+		return "", false, false
+	}
+
+	integer, fraction, hasFraction := strings.Cut(s, string(format.decimal))
+	if hasFraction && (fraction == "" || !isDigits(fraction)) {
+		return "", true, false
+	}
+	integer, ok = ungroupDigits(integer, format)
+	if !ok || (integer == "" && !hasFraction) {
+		return "", true, false
+	}
+	if integer == "" {
+		integer = "0"
+	}
+	canonical = integer
+	if hasFraction {
+		canonical += "." + fraction
+	}
+	if negative {
+		canonical = "-" + canonical
+	}
+	if percent {
+		canonical += "%"
+	}
+	return canonical, true, true
+}
+
+// ungroupDigits removes the group separators of format from the integer part of a number,
+// checking that they separate groups of three digits, or the groups of Indian grouping in
+// locales that use it.
+func ungroupDigits(integer string, format numberFormat) (string, bool) {
+	if integer == "" || isDigits(integer) {
+		return integer, true
+	}
+	sep, _ := utf8.DecodeRuneInString(strings.TrimLeft(integer, "0123456789"))
+	if !strings.ContainsRune(format.groups, sep) {
+		return "", false
+	}
+	groups := strings.Split(integer, string(sep))
+	for _, group := range groups {
+		if !isDigits(group) {
+			return "", false
+		}
+	}
+	if !digitGroupsValid(groups, 3, 3) && !(format.indian && digitGroupsValid(groups, 2, 2)) {
+		return "", false
+	}
+	return strings.Join(groups, ""), true
+}
+
+// digitGroupsValid reports whether groups, split at group separators, has a first group of
+// at most first digits, a last group of three digits and middle groups of middle digits.
+func digitGroupsValid(groups []string, first, middle int) bool {
+	for i, group := range groups {
+		switch {
+		case i == 0:
+			if len(group) > first {
+				return false
+			}
+		case i == len(groups)-1:
+			if len(group) != 3 {
+				return false
+			}
+		case len(group) != middle:
+			return false
+		}
+	}
+	return true
+}
+
+// trimCurrency removes a currency symbol or code from the start or end of s.
+func trimCurrency(s string) (string, bool) {
+	if r, size := utf8.DecodeRuneInString(s); size > 0 && unicode.Is(unicode.Sc, r) {
+		return s[size:], true
+	}
+	if r, size := utf8.DecodeLastRuneInString(s); size > 0 && unicode.Is(unicode.Sc, r) {
+		return s[:len(s)-size], true
+	}
+	for _, code := range currencyCodes {
+		if strings.HasPrefix(s, code) {
+			return s[len(code):], true
+		}
+		if strings.HasSuffix(s, code) {
+			return s[:len(s)-len(code)], true
+		}
+	}
+	return s, false
+}
@@ -0,0 +1,92 @@
+package kreuzberg
+
+import (
+	"errors"
+	"slices"
+	"strconv"
+	"strings"
+	"testing"
+)
+
+func TestCanonicalNumber(t *testing.T) {
+	cases := []struct {
+		locale, cell, want string
+		numeric, ok        bool
+	}{
+		{"en-US", "$1,234.56", "1234.56", true, true},
+		{"en-US", "(500)", "-500", true, true},
+		{"en-US", "($1,200)", "-1200", true, true},
+		{"en-US", "-$3.50", "-3.50", true, true},
+		{"en-US", "USD 99", "99", true, true},
+		{"en-US", "12.5%", "12.5%", true, true},
+		{"en-US", ".5", "0.5", true, true},
+		{"en-US", "007", "007", true, true},
+		{"en-US", "1.234,56", "", true, false},
+		{"en-US", "1.2.3", "", true, false},
+		{"en-US", "12,34", "", true, false},
+		{"en-US", "Total", "", false, false},
+		{"en-US", "2024-01-05", "", false, false},
+		{"en-US", "-", "", false, false},
+		{"de", "1.234,56 €", "1234.56", true, true},
+		{"de-DE", "−0,5", "-0.5", true, true},
+		{"fr", "1 234 567,8", "1234567.8", true, true},
+		{"fr", "1.234", "", true, false},
+		{"de-CH", "CHF 1'234.50", "1234.50", true, true},
+		{"en-IN", "₹12,34,567", "1234567", true, true},
+		{"en-IN", "1,234,567", "1234567", true, true},
+	}
+	for _, tc := range cases {
+		format, ok := numberFormatFor(tc.locale)
+		if !ok {
+			t.Fatalf("locale %s not supported", tc.locale)
+		}
+		got, numeric, ok := canonicalNumber(tc.cell, format)
+		if got != tc.want || numeric != tc.numeric || ok != tc.ok {
+			t.Errorf("canonicalNumber(%q, %s) = %q, %v, %v; want %q, %v, %v", tc.cell, tc.locale, got, numeric, ok, tc.want, tc.numeric, tc.ok)
+		}
+		if ok {
+			if _, err := strconv.ParseFloat(strings.TrimSuffix(got, "%"), 64); err != nil {
+				t.Errorf("canonical form %q does not parse: %v", got, err)
+			}
+		}
+	}
+}
+
+func TestApplyTableNumbers(t *testing.T) {
+	result := &ExtractionResult{Tables: []Table{{
+		Cells:       [][]string{{"Item", "Amount"}, {"Rent", "$1,200.00"}, {"Refund", "(500)"}, {"Fee", "1.234,5"}},
+		PageNumber:  2,
+		HasHeader:   true,
+		ColumnTypes: []ColumnType{ColumnTypeString, ColumnTypeString},
+	}}}
+	applyTableNumbers(result, NewExtractionConfig(WithNormalizeTableNumbers("en-US")))
+
+	table := &result.Tables[0]
+	var amounts []string
+	for _, row := range table.Cells[1:] {
+		amounts = append(amounts, row[1])
+	}
+	if !slices.Equal(amounts, []string{"1200.00", "-500", "1.234,5"}) {
+		t.Fatalf("unexpected cells %q", amounts)
+	}
+	if table.RawCell(1, 1) != "$1,200.00" || table.RawCell(2, 1) != "(500)" || table.RawCell(1, 0) != "Rent" || table.RawCell(9, 0) != "" {
+		t.Fatalf("unexpected raw cells %q %q %q", table.RawCell(1, 1), table.RawCell(2, 1), table.RawCell(1, 0))
+	}
+	if !strings.Contains(table.Markdown, "| Refund | -500 |") {
+		t.Fatalf("expected Markdown to be rendered again, got %q", table.Markdown)
+	}
+	if len(result.Warnings) != 1 || result.Warnings[0].Code != WarningCodeNumberNotNormalized || result.Warnings[0].PageNumber != 2 ||
+		!strings.Contains(result.Warnings[0].Message, "row 4, column 2") {
+		t.Fatalf("unexpected warnings %+v", result.Warnings)
+	}
+}
+
+func TestNormalizeTableNumbersValidation(t *testing.T) {
+	var valErr *ValidationError
+	if err := validateConfig(NewExtractionConfig(WithNormalizeTableNumbers("xx"))); !errors.As(err, &valErr) {
+		t.Fatalf("expected a ValidationError for an unknown locale, got %v", err)
+	}
+	if err := validateConfig(NewExtractionConfig(WithNormalizeTableNumbers("pt_BR"))); err != nil {
+		t.Fatalf("expected pt_BR to be accepted, got %v", err)
+	}
+}
@@ -0,0 +1,206 @@
+package kreuzberg
+
+import "strings"
+
+// ColoredSpan is a run of PDF text drawn in a color other than black or highlighted, found
+// by WithExtractTextColors.
+type ColoredSpan struct {
+	Text string `json:"text"`
+	// Color is the color of the text, or of the highlight behind it when IsHighlight is set.
+	Color       [3]uint8 `json:"color"`
+	IsHighlight bool     `json:"is_highlight"`
+	PageNumber  int      `json:"page_number"`
+	// ByteStart and ByteEnd locate Text in Content. Both are -1 when the text could not be
+	// found there, for example because the layout analysis joined it differently.
+	ByteStart int `json:"byte_start"`
+	ByteEnd   int `json:"byte_end"`
+}
+
+// pdfHighlight is a highlighted area of a page: a Highlight annotation or a rectangle filled
+// in a color. box is [left, top, right, bottom] in device space.
+type pdfHighlight struct {
+	box   [4]float64
+	color [3]uint8
+}
+
+// defaultHighlightColor is the color of Highlight annotations that do not set one.
+var defaultHighlightColor = [3]uint8{255, 255, 0}
+
+// applyTextColors fills result.ColoredSpans with the colored and highlighted text of a PDF
+// when text color extraction is enabled. The text is read from the content streams like the
+// raw text reader does; pages it cannot decode are skipped. Encrypted documents are skipped.
+func applyTextColors(result *ExtractionResult, config *ExtractionConfig, src *documentSource) error {
+	if config.ExtractTextColors == nil || !*config.ExtractTextColors || !isPDFMimeType(result.MimeType) {
+		return nil
+	}
+	doc, err := src.pdfDocument()
+	if err != nil {
+		return err
+	}
+	if doc.encrypted {
+		return nil
+	}
+
+	pageStarts := make(map[int]int)
+	if structure := result.Metadata.PageStructure; structure != nil {
+		for _, boundary := range structure.Boundaries {
+			pageStarts[int(boundary.PageNumber)] = int(boundary.ByteStart)
+		}
+	}
+	fonts := make(map[any]*pdfFontDecoder)
+	cursor := 0
+	for i, page := range doc.pages() {
+		text, ok := doc.coloredPageText(page, fonts)
+		if !ok {
+			continue
+		}
+		for _, span := range coloredSpans(text) {
+			colored := ColoredSpan{
+				Text:        text.text[span.start:span.end],
+				Color:       span.color,
+				IsHighlight: span.highlight,
+				PageNumber:  i + 1,
+				ByteStart:   -1,
+				ByteEnd:     -1,
+			}
+			from := max(cursor, pageStarts[i+1])
+			if from <= len(result.Content) {
+				if at := strings.Index(result.Content[from:], colored.Text); at >= 0 {
+					colored.ByteStart = from + at
+					colored.ByteEnd = colored.ByteStart + len(colored.Text)
+					cursor = colored.ByteEnd
+				}
+			}
+			result.ColoredSpans = append(result.ColoredSpans, colored)
+		}
+	}
+	return nil
+}
+
+// coloredPageText reads the text of a page like pageText, recording the color of each span
+// and whether it lies on a highlight.
+func (d *pdfDocument) coloredPageText(page pdfPage, fonts map[any]*pdfFontDecoder) (pdfPageText, bool) {
+	w := &pdfTextWriter{doc: d, fonts: fonts, colors: true, highlights: d.highlightAnnotations(page)}
+	return d.readPageText(page, w)
+}
+
+// highlightAnnotations returns the areas of the Highlight annotations of page, one for each
+// quadrilateral of the annotation or its rectangle when it has none.
+func (d *pdfDocument) highlightAnnotations(page pdfPage) []pdfHighlight {
+	var highlights []pdfHighlight
+	for _, value := range d.array(page.dict["Annots"]) {
+		annot := d.dict(value)
+		if annot == nil || annot.name("Subtype") != "Highlight" {
+			continue
+		}
+		color := defaultHighlightColor
+		var components pdfArray
+		for _, item := range d.array(annot["C"]) {
+			components = append(components, d.resolve(item))
+		}
+		if c, ok := pdfDeviceColor(components); ok {
+			color = c
+		}
+		points := d.array(annot["QuadPoints"])
+		for i := 0; i+8 <= len(points); i += 8 {
+			box := [4]float64{}
+			for j := range 4 {
+				x, _ := d.resolve(points[i+2*j]).(float64)
+				y, _ := d.resolve(points[i+2*j+1]).(float64)
+				if j == 0 {
+					box = [4]float64{x, y, x, y}
+				}
+				box = [4]float64{min(box[0], x), max(box[1], y), max(box[2], x), min(box[3], y)}
+			}
+			highlights = append(highlights, pdfHighlight{box: box, color: color})
+		}
+		if len(points) < 8 {
+			if rect, ok := d.rect(annot["Rect"]); ok {
+				highlights = append(highlights, pdfHighlight{box: [4]float64{rect[0], rect[3], rect[2], rect[1]}, color: color})
+			}
+		}
+	}
+	return highlights
+}
+
+// markHighlights marks the spans whose center lies on a highlight, giving them the color of
+// the topmost one.
+func (w *pdfTextWriter) markHighlights() {
+	for i := range w.spans {
+		span := &w.spans[i]
+		x, y := (span.box[0]+span.box[2])/2, (span.box[1]+span.box[3])/2
+		for _, h := range w.highlights {
+			if x >= h.box[0] && x <= h.box[2] && y <= h.box[1] && y >= h.box[3] {
+				span.highlight, span.color = true, h.color
+			}
+		}
+	}
+}
+
+// deviceRect returns the device space box [left, top, right, bottom] of the rectangle at
+// x, y of width and height in user space.
+func (w *pdfTextWriter) deviceRect(x, y, width, height float64) [4]float64 {
+	m := w.state.ctm
+	var box [4]float64
+	for i, corner := range [4][2]float64{{x, y}, {x + width, y}, {x, y + height}, {x + width, y + height}} {
+		dx := m[0]*corner[0] + m[2]*corner[1] + m[4]
+		dy := m[1]*corner[0] + m[3]*corner[1] + m[5]
+		if i == 0 {
+			box = [4]float64{dx, dy, dx, dy}
+		}
+		box = [4]float64{min(box[0], dx), max(box[1], dy), max(box[2], dx), min(box[3], dy)}
+	}
+	return box
+}
+
+// coloredSpans returns the spans of text that are highlighted or not black, with adjacent
+// spans of the same color on a line merged.
+func coloredSpans(text pdfPageText) []pdfTextSpan {
+	var spans []pdfTextSpan
+	for _, span := range text.spans {
+		if !span.highlight && span.color == ([3]uint8{}) {
+			continue
+		}
+		if n := len(spans); n > 0 {
+			last := &spans[n-1]
+			if last.color == span.color && last.highlight == span.highlight && last.end <= span.start &&
+				strings.Trim(text.text[last.end:span.start], " ") == "" {
+				last.end = span.end
+				continue
+			}
+		}
+		spans = append(spans, span)
+	}
+	return spans
+}
+
+// pdfDeviceColor converts the numeric operands of a color operator to RGB, reading one
+// component as gray, three as RGB and four as CMYK. Operands of other color spaces, such as
+// pattern names, are not converted.
+func pdfDeviceColor(operands []any) ([3]uint8, bool) {
+	var components []float64
+	for _, operand := range operands {
+		value, ok := operand.(float64)
+		if !ok {
+			return [3]uint8{}, false
+		}
+		components = append(components, min(max(value, 0), 1))
+	}
+	channel := func(v float64) uint8 { return uint8(v*255 + 0.5) }
+	switch c := components; len(c) {
+	case 1:
+		return [3]uint8{channel(c[0]), channel(c[0]), channel(c[0])}, true
+	case 3:
+		return [3]uint8{channel(c[0]), channel(c[1]), channel(c[2])}, true
+	case 4:
+		k := 1 - c[3]
+		return [3]uint8{channel((1 - c[0]) * k), channel((1 - c[1]) * k), channel((1 - c[2]) * k)}, true
+	}
+	return [3]uint8{}, false
+}
+
+// isHighlightColor reports whether a filled rectangle of color marks the text over it.
+// Gray fills, used for table shading and page backgrounds, do not.
+func isHighlightColor(color [3]uint8) bool {
+	return color[0] != color[1] || color[1] != color[2]
+}
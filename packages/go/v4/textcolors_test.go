@@ -0,0 +1,65 @@
+package kreuzberg
+
+import "testing"
+
+func testColoredTextPDF() []byte {
+	return buildTestPDF(
+		`<< /Type /Catalog /Pages 2 0 R >>`,
+		`<< /Type /Pages /Kids [3 0 R] /Count 1 /MediaBox [0 0 612 792] /Resources << /Font << /F1 4 0 R >> >> >>`,
+		`<< /Type /Page /Parent 2 0 R /Contents 5 0 R /Annots [6 0 R] >>`,
+		`<< /Type /Font /Subtype /Type1 /BaseFont /Helvetica >>`,
+		testPDFStream("BT /F1 12 Tf 72 700 Td (Plain text) Tj ET\n"+
+			"q 1 0 0 rg BT /F1 12 Tf 72 680 Td (Urgent) Tj ( notice) Tj ET Q\n"+
+			"1 1 0 rg 70 655 100 16 re f 0 g BT /F1 12 Tf 72 660 Td (Marked) Tj ET\n"+
+			"BT /F1 12 Tf 72 640 Td (Annotated) Tj ET\n"+
+			"0.9 g 0 0 300 50 re f 0 g BT /F1 12 Tf 72 20 Td (Shaded) Tj ET", false),
+		`<< /Type /Annot /Subtype /Highlight /Rect [70 636 170 652] /QuadPoints [70 652 170 652 70 636 170 636] /C [0 1 0] >>`,
+	)
+}
+
+func TestApplyTextColors(t *testing.T) {
+	content := "Plain text\n\nUrgent notice\n\nMarked\n\nAnnotated\n\nShaded"
+	result := &ExtractionResult{Content: content, MimeType: "application/pdf"}
+	if err := applyTextColors(result, NewExtractionConfig(WithExtractTextColors(true)), &documentSource{data: testColoredTextPDF()}); err != nil {
+		t.Fatalf("applyTextColors failed: %v", err)
+	}
+	want := []ColoredSpan{
+		{Text: "Urgent notice", Color: [3]uint8{255, 0, 0}, PageNumber: 1, ByteStart: 12, ByteEnd: 25},
+		{Text: "Marked", Color: [3]uint8{255, 255, 0}, IsHighlight: true, PageNumber: 1, ByteStart: 27, ByteEnd: 33},
+		{Text: "Annotated", Color: [3]uint8{0, 255, 0}, IsHighlight: true, PageNumber: 1, ByteStart: 35, ByteEnd: 44},
+	}
+	if len(result.ColoredSpans) != len(want) {
+		t.Fatalf("got spans %+v, want %+v", result.ColoredSpans, want)
+	}
+	for i, span := range result.ColoredSpans {
+		if span != want[i] {
+			t.Errorf("span %d = %+v, want %+v", i, span, want[i])
+		}
+		if content[span.ByteStart:span.ByteEnd] != span.Text {
+			t.Errorf("span %d offsets do not locate %q", i, span.Text)
+		}
+	}
+
+	disabled := &ExtractionResult{Content: content, MimeType: "application/pdf"}
+	if err := applyTextColors(disabled, NewExtractionConfig(), &documentSource{data: testColoredTextPDF()}); err != nil || disabled.ColoredSpans != nil {
+		t.Fatalf("colored spans extracted without WithExtractTextColors: %+v, %v", disabled.ColoredSpans, err)
+	}
+}
+
+func TestPDFDeviceColor(t *testing.T) {
+	cases := []struct {
+		operands []any
+		want     [3]uint8
+		ok       bool
+	}{
+		{[]any{0.5}, [3]uint8{128, 128, 128}, true},
+		{[]any{0.0, 0.0, 1.0}, [3]uint8{0, 0, 255}, true},
+		{[]any{0.0, 1.0, 1.0, 0.0}, [3]uint8{255, 0, 0}, true},
+		{[]any{pdfName("P1")}, [3]uint8{}, false},
+	}
+	for _, c := range cases {
+		if got, ok := pdfDeviceColor(c.operands); got != c.want || ok != c.ok {
+			t.Errorf("pdfDeviceColor(%v) = %v, %v, want %v, %v", c.operands, got, ok, c.want, c.ok)
+		}
+	}
+}
@@ -0,0 +1,361 @@
+package kreuzberg
+
+import (
+	"context"
+	"encoding/binary"
+	"errors"
+	"maps"
+	"strings"
+)
+
+// defaultPageMarkerFormat matches the native PageConfig default.
+const defaultPageMarkerFormat = "\n\n<!-- PAGE {page_num} -->\n\n"
+
+// errTIFFFrameUnreadable reports a frame whose image data tiffFrame cannot find.
+var errTIFFFrameUnreadable = errors.New("TIFF frame has no image data that can be read")
+
+// maxTIFFFrames bounds the IFD walk so a malformed or cyclic chain cannot loop forever.
+const maxTIFFFrames = 10000
+
+func isTIFFMimeType(mimeType string) bool {
+	return strings.Contains(strings.ToLower(mimeType), "tiff")
+}
+
+// tiffIFDOffsets returns the offsets of the image file directories of a classic TIFF file,
+// one per frame. It returns nil for data that is not a TIFF it can walk (including BigTIFF).
+func tiffIFDOffsets(data []byte) (binary.ByteOrder, []uint32) {
+	if len(data) < 8 {
+		return nil, nil
+	}
+	var order binary.ByteOrder
+	switch string(data[:2]) {
+	case "II":
+		order = binary.LittleEndian
+	case "MM":
+		order = binary.BigEndian
+	default:
+		return nil, nil
+	}
+	if order.Uint16(data[2:4]) != 42 {
+		return nil, nil
+	}
+
+	var offsets []uint32
+	seen := make(map[uint32]bool)
+	offset := order.Uint32(data[4:8])
+	for offset != 0 && len(offsets) < maxTIFFFrames {
+		if seen[offset] || uint64(offset)+2 > uint64(len(data)) {
+			break
+		}
+		seen[offset] = true
+		entries := uint64(order.Uint16(data[offset : offset+2]))
+		next := uint64(offset) + 2 + entries*12
+		if next+4 > uint64(len(data)) {
+			break
+		}
+		offsets = append(offsets, offset)
+		offset = order.Uint32(data[next : next+4])
+	}
+	return order, offsets
+}
+
+// tiffTags returns the BYTE, SHORT, LONG and RATIONAL fields of the IFD at offset, keyed by
+// tag. A RATIONAL is given as its numerator and denominator. Fields of other types, and
+// fields whose values lie past the end of data, are left out.
+func tiffTags(data []byte, order binary.ByteOrder, offset uint32) map[uint16][]uint64 {
+	tags := make(map[uint16][]uint64)
+	count := int(order.Uint16(data[offset : offset+2]))
+	for i := 0; i < count; i++ {
+		entry := data[int(offset)+2+i*12 : int(offset)+14+i*12]
+		tag, typ, n := order.Uint16(entry[0:2]), order.Uint16(entry[2:4]), uint64(order.Uint32(entry[4:8]))
+		var size uint64
+		switch typ {
+		case 1:
+			size = 1
+		case 3:
+			size = 2
+		case 4:
+			size = 4
+		case 5:
+			size, n = 4, 2*n
+		default:
+			continue
+		}
+		value := entry[8:12]
+		if n*size > 4 {
+			start := uint64(order.Uint32(entry[8:12]))
+			if start+n*size > uint64(len(data)) {
+				continue
+			}
+			value = data[start : start+n*size]
+		}
+		values := make([]uint64, n)
+		for j := range values {
+			switch size {
+			case 1:
+				values[j] = uint64(value[j])
+			case 2:
+				values[j] = uint64(order.Uint16(value[2*j:]))
+			default:
+				values[j] = uint64(order.Uint32(value[4*j:]))
+			}
+		}
+		tags[tag] = values
+	}
+	return tags
+}
+
+// tiffFrame returns a single-frame TIFF holding only the frame whose IFD starts at offset:
+// its directory, the values stored outside it, and its strips or tiles. Tags pointing at
+// other directories are dropped, as are fields whose type is unknown or whose values lie
+// past the end of data. It fails for frames whose image data cannot be found.
+func tiffFrame(data []byte, order binary.ByteOrder, offset uint32) ([]byte, bool) {
+	type field struct {
+		tag, typ uint16
+		count    uint32
+		value    []byte
+	}
+	count := int(order.Uint16(data[offset : offset+2]))
+	fields := make([]field, 0, count)
+	for i := 0; i < count; i++ {
+		entry := data[int(offset)+2+i*12 : int(offset)+14+i*12]
+		f := field{tag: order.Uint16(entry[0:2]), typ: order.Uint16(entry[2:4]), count: order.Uint32(entry[4:8])}
+		if tiffDroppedTags[f.tag] || int(f.typ) >= len(tiffTypeSizes) || f.typ == 0 {
+			continue
+		}
+		size := uint64(f.count) * tiffTypeSizes[f.typ]
+		if size <= 4 {
+			f.value = entry[8 : 8+size]
+		} else {
+			start := uint64(order.Uint32(entry[8:12]))
+			if start+size > uint64(len(data)) {
+				continue
+			}
+			f.value = data[start : start+size]
+		}
+		fields = append(fields, f)
+	}
+
+	// The image data is a list of strips or of tiles, or for old-style JPEG a single
+	// stream, each given by an offset and a size.
+	values := func(f field) []uint64 {
+		out := make([]uint64, f.count)
+		for i := range out {
+			if f.typ == 3 {
+				out[i] = uint64(order.Uint16(f.value[i*2:]))
+			} else {
+				out[i] = uint64(order.Uint32(f.value[i*4:]))
+			}
+		}
+		return out
+	}
+	type segmentList struct {
+		field           int
+		starts, lengths []uint64
+		at              int
+	}
+	var lists []*segmentList
+	for _, pair := range tiffSegmentTags {
+		offsets, sizes := -1, -1
+		for i, f := range fields {
+			switch {
+			case f.tag == pair[0] && (f.typ == 3 || f.typ == 4):
+				offsets = i
+			case f.tag == pair[1] && (f.typ == 3 || f.typ == 4):
+				sizes = i
+			}
+		}
+		if offsets < 0 || sizes < 0 || fields[offsets].count != fields[sizes].count {
+			continue
+		}
+		// Each segment is copied, so segments that overlap or repeat could make the frame
+		// many times larger than the file. The segments of one list cannot together be
+		// larger than the file, which keeps a frame within a few times its size; old-style
+		// JPEG files may point their JPEG stream at the same bytes as their strips.
+		list := &segmentList{field: offsets, starts: values(fields[offsets]), lengths: values(fields[sizes])}
+		var total uint64
+		for i := range list.starts {
+			total += list.lengths[i]
+			if list.starts[i]+list.lengths[i] > uint64(len(data)) || total > uint64(len(data)) {
+				return nil, false
+			}
+		}
+		// The new offsets are always LONGs.
+		fields[offsets].typ = 4
+		fields[offsets].value = make([]byte, 4*len(list.starts))
+		lists = append(lists, list)
+	}
+	if len(lists) == 0 {
+		return nil, false
+	}
+
+	out := make([]byte, 8, 64)
+	copy(out, data[:4])
+	order.PutUint32(out[4:8], 8)
+	out = append(out, make([]byte, 2+12*len(fields)+4)...)
+	order.PutUint16(out[8:10], uint16(len(fields)))
+	align := func() {
+		if len(out)%2 == 1 {
+			out = append(out, 0)
+		}
+	}
+	// Values that do not fit their entry follow the directory; the segment offsets are
+	// placed now and filled in once the segments are written.
+	for i, f := range fields {
+		entry := out[10+i*12 : 22+i*12]
+		order.PutUint16(entry[0:2], f.tag)
+		order.PutUint16(entry[2:4], f.typ)
+		order.PutUint32(entry[4:8], f.count)
+		at := 10 + i*12 + 8
+		if len(f.value) > 4 {
+			align()
+			at = len(out)
+			order.PutUint32(out[10+i*12+8:], uint32(at))
+			out = append(out, f.value...)
+		} else {
+			copy(out[at:], f.value)
+		}
+		for _, list := range lists {
+			if list.field == i {
+				list.at = at
+			}
+		}
+	}
+	for _, list := range lists {
+		for i := range list.starts {
+			align()
+			order.PutUint32(out[list.at+i*4:], uint32(len(out)))
+			out = append(out, data[list.starts[i]:list.starts[i]+list.lengths[i]]...)
+		}
+	}
+	return out, true
+}
+
+// tiffTypeSizes are the sizes in bytes of the TIFF field types, indexed by type.
+var tiffTypeSizes = [...]uint64{0, 1, 1, 2, 4, 8, 1, 1, 2, 4, 8, 4, 8, 4}
+
+// tiffDroppedTags point at data outside the frame's image: sub-images, EXIF, GPS and
+// interoperability directories, and free space.
+var tiffDroppedTags = map[uint16]bool{288: true, 289: true, 330: true, 34665: true, 34853: true, 40965: true}
+
+// tiffSegmentTags pair the tags giving the offsets of a frame's image data with the tags
+// giving their sizes: strips, tiles, and the stream of an old-style JPEG frame.
+var tiffSegmentTags = [][2]uint16{{273, 279}, {324, 325}, {513, 514}}
+
+// applyTIFFPages OCRs each frame of a multi-page TIFF separately. The native image extractor
+// OCRs only the first frame, so without this the remaining pages of faxes and scanned
+// batches are lost. Pages are joined the same way as PDF pages (see pageTextJoiner).
+// When the document was sampled, only the sampled frames are OCR'd. With WithParallelPages
+// up to that many frames are OCR'd at once. The first frame is not OCR'd again: the native
+// result already holds it.
+func applyTIFFPages(ctx context.Context, result *ExtractionResult, config *ExtractionConfig, src *documentSource) error {
+	return ocrTIFFPages(ctx, result, config, src, extractBytesNative, batchExtractBytesNative)
+}
+
+// ocrTIFFPages is applyTIFFPages OCRing single frames with extract and several frames at
+// once with extractBatch.
+func ocrTIFFPages(ctx context.Context, result *ExtractionResult, config *ExtractionConfig, src *documentSource,
+	extract func(context.Context, []byte, string, *ExtractionConfig) (*ExtractionResult, error),
+	extractBatch func(context.Context, []BytesWithMime, *ExtractionConfig) ([]*ExtractionResult, error)) error {
+	if config.OCR == nil || !isTIFFMimeType(result.MimeType) {
+		return nil
+	}
+	data, err := src.bytes()
+	if err != nil {
+		return err
+	}
+	order, offsets := tiffIFDOffsets(data)
+	if len(offsets) <= 1 {
+		return nil
+	}
+	firstFrame, frameCount := offsets[0], len(offsets)
+	if len(result.SampledPages) > 0 {
+		frames := make([]uint32, 0, len(result.SampledPages))
+		for _, page := range result.SampledPages {
+			if page >= 1 && page <= len(offsets) {
+				frames = append(frames, offsets[page-1])
+			}
+		}
+		offsets = frames
+	}
+
+	frameConfig := &ExtractionConfig{UseCache: config.UseCache, OCR: config.OCR}
+	timeout := ocrPageTimeout(config)
+
+	single := func(ctx context.Context, frame []byte) (*ExtractionResult, error) {
+		if frame == nil {
+			return nil, newParsingErrorWithContext("failed to split TIFF frame", errTIFFFrameUnreadable, ErrorCodeParsing, nil)
+		}
+		return extractWithTimeout(ctx, frame, result.MimeType, frameConfig, timeout, extract)
+	}
+	var batch pageBatchExtractor
+	// A batch cannot give up on one of its frames, so frames with a time budget are OCR'd
+	// one at a time.
+	if n := parallelPages(config); n > 1 && timeout <= 0 {
+		batchConfig := *frameConfig
+		batchConfig.MaxConcurrentExtractions = &n
+		batch = func(ctx context.Context, frames [][]byte) ([]*ExtractionResult, error) {
+			items := make([]BytesWithMime, len(frames))
+			for i, frame := range frames {
+				if frame == nil {
+					return nil, errTIFFFrameUnreadable
+				}
+				items[i] = BytesWithMime{Data: frame, MimeType: result.MimeType}
+			}
+			return extractBatch(ctx, items, &batchConfig)
+		}
+	}
+
+	// The native library OCR'd only the first frame, with the whole file; when that frame
+	// is wanted its result stands for the frame's own.
+	var native *ExtractionResult
+	if len(offsets) > 0 && offsets[0] == firstFrame && result.Metadata.Error == nil {
+		native = &ExtractionResult{Content: result.Content, Metadata: result.Metadata, Success: true}
+		native.Metadata.Additional = maps.Clone(result.Metadata.Additional)
+	}
+	// The rotations of the frames replace the one the native library found.
+	delete(result.Metadata.Additional, "auto_rotation")
+	joiner := newPageTextJoiner(config)
+	var ocrPages []int
+	hocr := make([]string, len(offsets))
+	emit := func(i int, frameResult *ExtractionResult, err error) error {
+		text, err := pageOCRText(ctx, result, config, i+1, timeout, frameResult, err)
+		if err != nil {
+			return err
+		}
+		if frameResult != nil {
+			ocrPages = append(ocrPages, i+1)
+			if page := ocrPageHOCR(frameResult.Metadata); len(page) > 0 {
+				hocr[i] = page[0]
+			}
+			if degrees, ok := ocrRotations(frameResult.Metadata)[1]; ok {
+				addPageRotation(result, i+1, degrees)
+			}
+		}
+		joiner.add(i+1, text)
+		return nil
+	}
+
+	first := 0
+	if native != nil {
+		if err := emit(0, native, nil); err != nil {
+			return err
+		}
+		first = 1
+	}
+	frame := func(i int) ([]byte, error) {
+		frame, _ := tiffFrame(data, order, offsets[first+i])
+		return frame, nil
+	}
+	err = extractPagesInOrder(ctx, len(offsets)-first, parallelPages(config), frame, single, batch, func(i int, frameResult *ExtractionResult, err error) error {
+		return emit(first+i, frameResult, err)
+	})
+	if err != nil {
+		return err
+	}
+	joiner.apply(result, frameCount)
+	setOCRPages(result, ocrPages)
+	setPageHOCR(result, hocr)
+	// The chunks and languages the native library found describe the first frame only.
+	return applyContentFeatures(ctx, result, config, extract)
+}
@@ -0,0 +1,228 @@
+package kreuzberg
+
+import (
+	"bytes"
+	"encoding/binary"
+	"strings"
+	"testing"
+)
+
+// glyphs is a 5x7 bitmap font covering the letters used by the multi-page TIFF test.
+var glyphs = map[rune][7]string{
+	'A': {".###.", "#...#", "#...#", "#####", "#...#", "#...#", "#...#"},
+	'B': {"####.", "#...#", "#...#", "####.", "#...#", "#...#", "####."},
+	'H': {"#...#", "#...#", "#...#", "#####", "#...#", "#...#", "#...#"},
+	'L': {"#....", "#....", "#....", "#....", "#....", "#....", "#####"},
+	'O': {".###.", "#...#", "#...#", "#...#", "#...#", "#...#", ".###."},
+	'P': {"####.", "#...#", "#...#", "####.", "#....", "#....", "#...."},
+	'R': {"####.", "#...#", "#...#", "####.", "#.#..", "#..#.", "#...#"},
+	'V': {"#...#", "#...#", "#...#", "#...#", "#...#", ".#.#.", "..#.."},
+}
+
+type tiffTestFrame struct {
+	width, height int
+	pixels        []byte
+}
+
+// renderWord draws word in black on a white 8-bit grayscale canvas.
+func renderWord(word string) tiffTestFrame {
+	const scale, margin = 8, 40
+	width := margin*2 + len(word)*6*scale
+	height := margin*2 + 7*scale
+	pixels := bytes.Repeat([]byte{0xff}, width*height)
+	for i, r := range word {
+		glyph := glyphs[r]
+		for row, line := range glyph {
+			for col, bit := range line {
+				if bit != '#' {
+					continue
+				}
+				for dy := 0; dy < scale; dy++ {
+					for dx := 0; dx < scale; dx++ {
+						x := margin + (i*6+col)*scale + dx
+						y := margin + row*scale + dy
+						pixels[y*width+x] = 0
+					}
+				}
+			}
+		}
+	}
+	return tiffTestFrame{width: width, height: height, pixels: pixels}
+}
+
+// encodeTIFF writes an uncompressed little-endian grayscale TIFF with one IFD per frame.
+func encodeTIFF(frames ...tiffTestFrame) []byte {
+	var buf bytes.Buffer
+	le := binary.LittleEndian
+	buf.WriteString("II")
+	_ = binary.Write(&buf, le, uint16(42))
+	_ = binary.Write(&buf, le, uint32(0))
+
+	prevNext := 4
+	for _, frame := range frames {
+		stripOffset := buf.Len()
+		buf.Write(frame.pixels)
+		if buf.Len()%2 == 1 {
+			buf.WriteByte(0)
+		}
+		ifdOffset := buf.Len()
+		le.PutUint32(buf.Bytes()[prevNext:], uint32(ifdOffset))
+
+		entries := []struct {
+			tag, typ uint16
+			value    uint32
+		}{
+			{256, 4, uint32(frame.width)},
+			{257, 4, uint32(frame.height)},
+			{258, 3, 8},
+			{259, 3, 1},
+			{262, 3, 1},
+			{273, 4, uint32(stripOffset)},
+			{277, 3, 1},
+			{278, 4, uint32(frame.height)},
+			{279, 4, uint32(len(frame.pixels))},
+		}
+		_ = binary.Write(&buf, le, uint16(len(entries)))
+		for _, e := range entries {
+			_ = binary.Write(&buf, le, e.tag)
+			_ = binary.Write(&buf, le, e.typ)
+			_ = binary.Write(&buf, le, uint32(1))
+			if e.typ == 3 {
+				_ = binary.Write(&buf, le, uint16(e.value))
+				_ = binary.Write(&buf, le, uint16(0))
+			} else {
+				_ = binary.Write(&buf, le, e.value)
+			}
+		}
+		prevNext = buf.Len()
+		_ = binary.Write(&buf, le, uint32(0))
+	}
+	return buf.Bytes()
+}
+
+// TestTIFFFrameSplitting verifies that every IFD of a multi-frame TIFF becomes a standalone
+// single-frame TIFF holding only that frame.
+func TestTIFFFrameSplitting(t *testing.T) {
+	frames := []tiffTestFrame{renderWord("A"), renderWord("BB"), renderWord("LLL")}
+	data := encodeTIFF(frames...)
+
+	order, offsets := tiffIFDOffsets(data)
+	if len(offsets) != 3 {
+		t.Fatalf("expected 3 frames, got %d", len(offsets))
+	}
+
+	for i, offset := range offsets {
+		frame, ok := tiffFrame(data, order, offset)
+		if !ok {
+			t.Fatalf("frame %d: not split", i)
+		}
+		_, frameOffsets := tiffIFDOffsets(frame)
+		if len(frameOffsets) != 1 {
+			t.Fatalf("frame %d: expected a single IFD, got %v", i, frameOffsets)
+		}
+		want := frames[i]
+		ifd := frameOffsets[0]
+		if width := order.Uint32(frame[ifd+2+8:]); width != uint32(want.width) {
+			t.Errorf("frame %d: width %d, want %d", i, width, want.width)
+		}
+		strip := order.Uint32(frame[ifd+2+5*12+8:])
+		if !bytes.Equal(frame[strip:strip+uint32(len(want.pixels))], want.pixels) {
+			t.Errorf("frame %d: strip does not hold the frame's pixels", i)
+		}
+		if len(frame) > 8+2+9*12+4+len(want.pixels)+1 {
+			t.Errorf("frame %d: %d bytes copied for a %d byte image", i, len(frame), len(want.pixels))
+		}
+	}
+	// A frame without image data cannot be split on its own.
+	stripless := bytes.Clone(data)
+	order.PutUint16(stripless[offsets[0]+2+5*12:], 1)
+	if _, ok := tiffFrame(stripless, order, offsets[0]); ok {
+		t.Error("expected a frame without strips not to be split")
+	}
+	// Strips that all cover the whole file would copy it once per strip.
+	le := binary.LittleEndian
+	repeated := bytes.Clone(data)
+	const strips = 64
+	offsetsAt := len(repeated)
+	repeated = append(repeated, make([]byte, 4*strips)...)
+	sizesAt := len(repeated)
+	for range strips {
+		repeated = le.AppendUint32(repeated, uint32(len(data)))
+	}
+	ifd := len(repeated)
+	repeated = le.AppendUint16(repeated, 2)
+	for _, e := range [][2]uint32{{273, uint32(offsetsAt)}, {279, uint32(sizesAt)}} {
+		repeated = le.AppendUint16(repeated, uint16(e[0]))
+		repeated = le.AppendUint16(repeated, 4)
+		repeated = le.AppendUint32(repeated, strips)
+		repeated = le.AppendUint32(repeated, e[1])
+	}
+	repeated = le.AppendUint32(repeated, 0)
+	if _, ok := tiffFrame(repeated, le, uint32(ifd)); ok {
+		t.Error("expected strips larger than the file together not to be split")
+	}
+	if _, offsets := tiffIFDOffsets(data[:0]); offsets != nil {
+		t.Error("empty input should not be treated as a TIFF")
+	}
+}
+
+// TestMultiPageTIFFOCR verifies that each frame of a multi-page TIFF is OCR'd and reported
+// as its own page.
+func TestMultiPageTIFFOCR(t *testing.T) {
+	data := encodeTIFF(renderWord("ALPHA"), renderWord("BRAVO"))
+	config := NewExtractionConfig(
+		WithOCR(WithOCRBackend("tesseract")),
+		WithPages(WithExtractPages(true), WithInsertPageMarkers(true)),
+	)
+
+	result, err := ExtractBytesSync(data, "image/tiff", config)
+	if err != nil {
+		t.Skipf("TIFF OCR unavailable: %v", err)
+	}
+
+	count, err := result.GetPageCount()
+	if err != nil {
+		t.Fatalf("GetPageCount failed: %v", err)
+	}
+	if count != 2 {
+		t.Fatalf("expected 2 pages, got %d", count)
+	}
+	if len(result.Pages) != 2 {
+		t.Fatalf("expected 2 page contents, got %d", len(result.Pages))
+	}
+
+	content := strings.ToUpper(result.Content)
+	for _, word := range []string{"ALPHA", "BRAVO"} {
+		if !strings.Contains(content, word) {
+			t.Errorf("expected content from every frame, %q missing in %q", word, result.Content)
+		}
+	}
+	if !strings.Contains(result.Content, "<!-- PAGE 2 -->") {
+		t.Errorf("expected page marker for page 2 in %q", result.Content)
+	}
+}
+
+// TestOCRPreserveSpacing OCRs a line of a monospaced form and verifies that the blank cells
+// between its fields are kept as spaces.
+func TestOCRPreserveSpacing(t *testing.T) {
+	data := encodeTIFF(renderWord("LABOR      HALL"))
+	ocr := func(preserve bool) string {
+		config := NewExtractionConfig(WithOCR(WithOCRBackend("tesseract"), WithTesseract(
+			WithTesseractPSM(7),
+			WithTesseractTextordSpaceSizeIsVariable(false),
+			WithTesseractPreserveInterwordSpacing(preserve),
+		)))
+		result, err := ExtractBytesSync(data, "image/tiff", config)
+		if err != nil {
+			t.Skipf("TIFF OCR unavailable: %v", err)
+		}
+		return strings.ToUpper(strings.TrimSpace(result.Content))
+	}
+
+	if collapsed := ocr(false); !strings.Contains(collapsed, "LABOR HALL") {
+		t.Fatalf("expected a single space between the fields, got %q", collapsed)
+	}
+	if preserved := ocr(true); !strings.Contains(preserved, "LABOR   ") || !strings.HasSuffix(preserved, "HALL") {
+		t.Fatalf("expected the blank cells between the fields to be kept, got %q", preserved)
+	}
+}
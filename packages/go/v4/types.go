@@ -1,25 +1,76 @@
 package kreuzberg
 
-import "encoding/json"
+import (
+	"encoding/json"
+	"time"
+)
 
 // ExtractionResult mirrors the Rust ExtractionResult struct returned by the core API.
 type ExtractionResult struct {
-	Content           string           `json:"content"`
-	MimeType          string           `json:"mime_type"`
-	Metadata          Metadata         `json:"metadata"`
-	Tables            []Table          `json:"tables"`
-	DetectedLanguages []string         `json:"detected_languages,omitempty"`
-	Chunks            []Chunk          `json:"chunks,omitempty"`
-	Images            []ExtractedImage `json:"images,omitempty"`
-	Pages             []PageContent    `json:"pages,omitempty"`
-	Success           bool             `json:"success"`
+	Content           string              `json:"content"`
+	MimeType          string              `json:"mime_type"`
+	Metadata          Metadata            `json:"metadata"`
+	Tables            []Table             `json:"tables"`
+	DetectedLanguages []string            `json:"detected_languages,omitempty"`
+	Chunks            []Chunk             `json:"chunks,omitempty"`
+	Images            []ExtractedImage    `json:"images,omitempty"`
+	PageImages        []ExtractedImage    `json:"page_images,omitempty"`
+	Pages             []PageContent       `json:"pages,omitempty"`
+	Annotations       []Annotation        `json:"annotations,omitempty"`
+	Revisions         []Revision          `json:"revisions,omitempty"`
+	Signatures        []Signature         `json:"signatures,omitempty"`
+	ColoredSpans      []ColoredSpan       `json:"colored_spans,omitempty"`
+	Slides            []Slide             `json:"slides,omitempty"`
+	Layers            []PDFLayer          `json:"layers,omitempty"`
+	PageHashes        []PageHash          `json:"page_hashes,omitempty"`
+	Headers           []string            `json:"headers,omitempty"`
+	Footers           []string            `json:"footers,omitempty"`
+	Warnings          []ExtractionWarning `json:"warnings,omitempty"`
+	PartBoundaries    []int               `json:"part_boundaries,omitempty"`
+	Barcodes          []Barcode           `json:"barcodes,omitempty"`
+	Blocks            []Block             `json:"blocks,omitempty"`
+	HOCR              string              `json:"hocr,omitempty"`
+	SampledPages      []int               `json:"sampled_pages,omitempty"`
+	PageRotations     map[int]int         `json:"page_rotations,omitempty"`
+	OffsetMap         []OffsetEntry       `json:"offset_map,omitempty"`
+	Resources         *ResourceUsage      `json:"resources,omitempty"`
+	Confidence        *ConfidenceReport   `json:"confidence,omitempty"`
+	Pipeline          *PipelineInfo       `json:"pipeline,omitempty"`
+	OCRUsed           bool                `json:"ocr_used"`
+	OCRPages          []int               `json:"ocr_pages,omitempty"`
+	SourceBytes       []byte              `json:"source_bytes,omitempty"`
+	SourceMime        string              `json:"source_mime,omitempty"`
+	UserData          map[string]any      `json:"user_data,omitempty"`
+	Success           bool                `json:"success"`
+
+	// textPositions locate ranges of textPositionsContent on the page for LocateText.
+	textPositions        []textPosition
+	textPositionsContent string
+	// contentLower caches ContentLower for contentLowerOf.
+	contentLower   string
+	contentLowerOf string
 }
 
 // Table represents a detected table in the source document.
 type Table struct {
-	Cells      [][]string `json:"cells"`
-	Markdown   string     `json:"markdown"`
-	PageNumber int        `json:"page_number"`
+	Cells       [][]string   `json:"cells"`
+	Markdown    string       `json:"markdown"`
+	PageNumber  int          `json:"page_number"`
+	HasHeader   bool         `json:"has_header,omitempty"`
+	ColumnTypes []ColumnType `json:"column_types,omitempty"`
+	MergedCells []CellRange  `json:"merged_cells,omitempty"`
+
+	// rawCells holds the text of the cells WithNormalizeTableNumbers rewrote, for RawCell.
+	rawCells map[[2]int]string
+}
+
+// Barcode is a barcode or QR code found in the document. BBox is [left, top, right, bottom]
+// in pixels of the page image, and nil when the position on the page is unknown.
+type Barcode struct {
+	Type       string      `json:"type"`
+	Value      string      `json:"value"`
+	PageNumber int         `json:"page_number"`
+	BBox       *[4]float64 `json:"bbox,omitempty"`
 }
 
 // Chunk contains chunked content plus optional embeddings and metadata.
@@ -38,6 +89,12 @@ type ChunkMetadata struct {
 	TotalChunks int     `json:"total_chunks"`
 	FirstPage   *uint64 `json:"first_page,omitempty"`
 	LastPage    *uint64 `json:"last_page,omitempty"`
+	// SectionPath lists the headings enclosing the chunk, outermost first, when
+	// ChunkingConfig.SectionTitles is enabled and the content has headings.
+	SectionPath []string `json:"section_path,omitempty"`
+	// ID identifies the chunk by its document and content when stable chunk IDs are
+	// enabled; see WithStableChunkIDs.
+	ID string `json:"id,omitempty"`
 }
 
 // ExtractedImage represents an extracted image, optionally with nested OCR results.
@@ -53,6 +110,17 @@ type ExtractedImage struct {
 	IsMask           bool              `json:"is_mask"`
 	Description      *string           `json:"description,omitempty"`
 	OCRResult        *ExtractionResult `json:"ocr_result,omitempty"`
+	OCRText          string            `json:"ocr_text,omitempty"`
+	// IsGrayscale and DominantColors are set by image color analysis; see
+	// WithImageColorAnalysis. DominantColors holds RGB colors, most common first.
+	IsGrayscale    *bool      `json:"is_grayscale,omitempty"`
+	DominantColors [][3]uint8 `json:"dominant_colors,omitempty"`
+	// Caption is set by ImageExtractionConfig.Captioner; see WithImageCaptioner.
+	Caption string `json:"caption,omitempty"`
+	// ICCProfile and ColorProfileName are set for images with an embedded ICC profile; see
+	// WithExtractColorProfiles.
+	ICCProfile       []byte `json:"icc_profile,omitempty"`
+	ColorProfileName string `json:"color_profile_name,omitempty"`
 }
 
 // Metadata aggregates document metadata and format-specific payloads.
@@ -65,7 +133,23 @@ type Metadata struct {
 	JSONSchema         json.RawMessage             `json:"json_schema,omitempty"`
 	Error              *ErrorMetadata              `json:"error,omitempty"`
 	PageStructure      *PageStructure              `json:"page_structure,omitempty"`
-	Additional         map[string]json.RawMessage  `json:"-"`
+	// DominantTextDirection is TextDirectionLTR or TextDirectionRTL, whichever direction
+	// most letters of Content are written in; nil when Content has no letters.
+	DominantTextDirection *string `json:"dominant_text_direction,omitempty"`
+	// SourceEncoding is the character encoding plain text or CSV was converted to UTF-8
+	// from, set when WithDetectEncoding or WithSourceEncoding applies.
+	SourceEncoding *string `json:"source_encoding,omitempty"`
+	// CreatedAt and ModifiedAt are the creation and last modification times of the
+	// document, parsed from the dates the extractor reported in whichever format the
+	// document stores them, such as PDF dates ("D:20230101120000+05'30'"), ISO 8601 or
+	// RFC 5322. CreatedAt falls back to Date for formats that report a single date. Both are
+	// nil when the document has no such date or it could not be parsed; CreatedAtRaw and
+	// ModifiedAtRaw keep the dates as reported either way.
+	CreatedAt     *time.Time                 `json:"-"`
+	ModifiedAt    *time.Time                 `json:"-"`
+	CreatedAtRaw  *string                    `json:"-"`
+	ModifiedAtRaw *string                    `json:"-"`
+	Additional    map[string]json.RawMessage `json:"-"`
 }
 
 // FormatMetadata represents the discriminated union of metadata formats.
@@ -351,6 +435,26 @@ type PageStructure struct {
 	UnitType   PageUnitType   `json:"unit_type"`
 	Boundaries []PageBoundary `json:"boundaries,omitempty"`
 	Pages      []PageInfo     `json:"pages,omitempty"`
+	PageSizes  []PageSize     `json:"page_sizes,omitempty"`
+}
+
+// PageSize describes the geometry of a single PDF page in points (1/72 inch).
+// Width and Height are those of the unrotated page box; Rotation is the clockwise
+// display rotation in degrees (0, 90, 180 or 270), read from the page tree with
+// WithPageGeometry.
+type PageSize struct {
+	PageNumber int     `json:"page_number"`
+	Width      float64 `json:"width"`
+	Height     float64 `json:"height"`
+	Rotation   int     `json:"rotation"`
+}
+
+// Landscape reports whether the page is wider than it is tall once its rotation is applied.
+func (p PageSize) Landscape() bool {
+	if p.Rotation == 90 || p.Rotation == 270 {
+		return p.Height > p.Width
+	}
+	return p.Width > p.Height
 }
 
 // PageContent represents extracted content for a single page.
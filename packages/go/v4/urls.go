@@ -0,0 +1,126 @@
+package kreuzberg
+
+import (
+	"net/url"
+	"regexp"
+	"strings"
+)
+
+var (
+	// contentURL matches bare URLs: an http, https or ftp URL, or a host starting with
+	// "www.". Brackets end a URL so that the parts of a Markdown link are matched apart.
+	contentURL = regexp.MustCompile("(?i)\\b(?:(?:https?|ftp)://|www\\.)[^\\s<>\"'`\\[\\]{}|\\\\^]+")
+	// contentEmail matches email addresses with a dotted domain.
+	contentEmail = regexp.MustCompile(`[A-Za-z0-9._%+\-]+@[A-Za-z0-9](?:[A-Za-z0-9\-]*[A-Za-z0-9])?(?:\.[A-Za-z0-9](?:[A-Za-z0-9\-]*[A-Za-z0-9])?)*\.[A-Za-z]{2,}\b`)
+)
+
+// URLs returns the URLs written in Content, including bare URLs typed into the text and the
+// targets of Markdown links, in order of first appearance and without duplicates. Trailing
+// punctuation that ends the surrounding sentence, and closing brackets without a matching
+// opening one in the URL, are not part of it. URLs are normalized: the scheme and host are
+// lowercased, "http://" is added to hosts written without a scheme such as
+// "www.example.com", and a lone "/" path is removed.
+func (r *ExtractionResult) URLs() []string {
+	if r == nil {
+		return nil
+	}
+	var urls []string
+	seen := make(map[string]struct{})
+	for _, span := range contentURLSpans(r.Content) {
+		normalized, ok := normalizeContentURL(r.Content[span[0]:span[1]])
+		if !ok {
+			continue
+		}
+		if _, dup := seen[normalized]; !dup {
+			seen[normalized] = struct{}{}
+			urls = append(urls, normalized)
+		}
+	}
+	return urls
+}
+
+// Emails returns the email addresses written in Content, in order of first appearance and
+// without duplicates, with their domain lowercased. Addresses in mailto: links are included;
+// user information inside URLs, as in "ftp://user@example.com", is not.
+func (r *ExtractionResult) Emails() []string {
+	if r == nil {
+		return nil
+	}
+	content := r.Content
+	urlSpans := contentURLSpans(content)
+	var emails []string
+	seen := make(map[string]struct{})
+	for _, span := range contentEmail.FindAllStringIndex(content, -1) {
+		inURL := false
+		for _, u := range urlSpans {
+			if span[0] < u[1] && u[0] < span[1] {
+				inURL = true
+				break
+			}
+		}
+		if inURL {
+			continue
+		}
+		local, domain, _ := strings.Cut(content[span[0]:span[1]], "@")
+		local = strings.Trim(local, ".")
+		if local == "" {
+			continue
+		}
+		email := local + "@" + strings.ToLower(domain)
+		if _, dup := seen[email]; !dup {
+			seen[email] = struct{}{}
+			emails = append(emails, email)
+		}
+	}
+	return emails
+}
+
+// contentURLSpans returns the byte ranges of the URLs in content, with trailing punctuation
+// trimmed.
+func contentURLSpans(content string) [][2]int {
+	var spans [][2]int
+	for _, match := range contentURL.FindAllStringIndex(content, -1) {
+		end := match[0] + len(trimURLPunctuation(content[match[0]:match[1]]))
+		spans = append(spans, [2]int{match[0], end})
+	}
+	return spans
+}
+
+// trimURLPunctuation removes the characters at the end of a matched URL that belong to the
+// surrounding text: sentence punctuation, Markdown emphasis and closing brackets the URL
+// does not open, as in "(see https://example.com/a)".
+func trimURLPunctuation(raw string) string {
+	for raw != "" {
+		last := raw[len(raw)-1]
+		switch last {
+		case '.', ',', ';', ':', '!', '?', '*', '~':
+			raw = raw[:len(raw)-1]
+			continue
+		case ')':
+			if strings.Count(raw, "(") < strings.Count(raw, ")") {
+				raw = raw[:len(raw)-1]
+				continue
+			}
+		}
+		break
+	}
+	return raw
+}
+
+// normalizeContentURL returns the normalized form of a matched URL, or false when it has no
+// host.
+func normalizeContentURL(raw string) (string, bool) {
+	if strings.HasPrefix(strings.ToLower(raw), "www.") {
+		raw = "http://" + raw
+	}
+	u, err := url.Parse(raw)
+	if err != nil || u.Host == "" || strings.HasPrefix(u.Host, ".") {
+		return "", false
+	}
+	u.Scheme = strings.ToLower(u.Scheme)
+	u.Host = strings.ToLower(u.Host)
+	if u.Path == "/" && u.RawQuery == "" && u.Fragment == "" {
+		u.Path = ""
+	}
+	return u.String(), true
+}
@@ -0,0 +1,38 @@
+package kreuzberg
+
+import (
+	"reflect"
+	"testing"
+)
+
+func TestResultURLs(t *testing.T) {
+	result := &ExtractionResult{Content: "See https://Example.COM/docs/page. Also (www.example.org/a_(b)), " +
+		"[the guide](https://example.com/guide?x=1#top) and **http://example.net/**!\n" +
+		"Repeated: HTTPS://example.com/docs/page, ftp://files.example.com/pub/x.tar.gz; " +
+		"not a URL: http:// nor example.com."}
+	want := []string{
+		"https://example.com/docs/page",
+		"http://www.example.org/a_(b)",
+		"https://example.com/guide?x=1#top",
+		"http://example.net",
+		"ftp://files.example.com/pub/x.tar.gz",
+	}
+	if got := result.URLs(); !reflect.DeepEqual(got, want) {
+		t.Fatalf("URLs() = %q, want %q", got, want)
+	}
+
+	var nilResult *ExtractionResult
+	if got := nilResult.URLs(); got != nil {
+		t.Fatalf("nil result returned %q", got)
+	}
+}
+
+func TestResultEmails(t *testing.T) {
+	result := &ExtractionResult{Content: "Write to Jane.Doe@Example.COM, or <support+go@example.co.uk>. " +
+		"[Mail us](mailto:info@example.org); again jane.doe@example.com. " +
+		"Not these: ftp://user@files.example.com/x, @handle, name@localhost."}
+	want := []string{"Jane.Doe@example.com", "support+go@example.co.uk", "info@example.org", "jane.doe@example.com"}
+	if got := result.Emails(); !reflect.DeepEqual(got, want) {
+		t.Fatalf("Emails() = %q, want %q", got, want)
+	}
+}
@@ -0,0 +1,39 @@
+package kreuzberg
+
+import (
+	"context"
+	"maps"
+)
+
+// ExtractFileWithData extracts the file at path like ExtractFileSync and stores data in
+// the result's UserData, for tagging each result with caller context such as a tenant or
+// job ID. data is added to the fields of WithContextFields, replacing those with the same
+// keys; the result gets its own copy of the map, not of the values.
+//
+// UserData is serialized by ResultToJSON under "user_data" and restored by ResultFromJSON,
+// where values come back as encoding/json decodes them into an any: numbers as float64,
+// objects as map[string]any and arrays as []any.
+func ExtractFileWithData(path string, config *ExtractionConfig, data map[string]any) (*ExtractionResult, error) {
+	var seeded ExtractionConfig
+	if base := configOrDefault(config); base != nil {
+		seeded = *base
+	}
+	fields := maps.Clone(seeded.ContextFields)
+	if fields == nil {
+		fields = make(map[string]any, len(data))
+	}
+	maps.Copy(fields, data)
+	seeded.ContextFields = fields
+	return extractFile(context.Background(), path, &seeded)
+}
+
+// applyContextFields copies the fields of WithContextFields into result.UserData.
+func applyContextFields(result *ExtractionResult, config *ExtractionConfig) {
+	if len(config.ContextFields) == 0 {
+		return
+	}
+	if result.UserData == nil {
+		result.UserData = make(map[string]any, len(config.ContextFields))
+	}
+	maps.Copy(result.UserData, config.ContextFields)
+}
@@ -0,0 +1,65 @@
+package kreuzberg
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+func TestExtractFileWithData(t *testing.T) {
+	if err := RegisterExtractor(testExtractorMime, func(data []byte, _ *ExtractionConfig) (*ExtractionResult, error) {
+		return &ExtractionResult{Content: string(data), Success: true}, nil
+	}); err != nil {
+		t.Fatalf("RegisterExtractor failed: %v", err)
+	}
+	t.Cleanup(func() { _ = UnregisterExtractor(testExtractorMime) })
+
+	path := filepath.Join(t.TempDir(), "notes.txt")
+	if err := os.WriteFile(path, []byte("hello"), 0o600); err != nil {
+		t.Fatal(err)
+	}
+	fields := map[string]any{"tenant": "acme", "region": "eu"}
+	// The MIME override routes .txt files to the registered extractor.
+	overrides := map[string]string{".txt": testExtractorMime}
+	config := NewExtractionConfig(WithContextFields(fields), WithMimeOverrides(overrides))
+	result, err := ExtractFileWithData(path, config, map[string]any{"job": 42, "region": "us"})
+	if err != nil {
+		t.Fatalf("ExtractFileWithData failed: %v", err)
+	}
+	if result.UserData["tenant"] != "acme" || result.UserData["job"] != 42 || result.UserData["region"] != "us" {
+		t.Fatalf("unexpected user data %v", result.UserData)
+	}
+	if fields["region"] != "eu" || len(fields) != 2 || config.ContextFields["job"] != nil {
+		t.Fatalf("expected the caller's fields to be left alone, got %v and %v", fields, config.ContextFields)
+	}
+
+	encoded, err := ResultToJSON(result)
+	if err != nil {
+		t.Fatalf("ResultToJSON failed: %v", err)
+	}
+	decoded, err := ResultFromJSON(encoded)
+	if err != nil {
+		t.Fatalf("ResultFromJSON failed: %v", err)
+	}
+	if decoded.UserData["tenant"] != "acme" || decoded.UserData["job"] != float64(42) {
+		t.Fatalf("unexpected decoded user data %v", decoded.UserData)
+	}
+
+	results, err := BatchExtractBytesSync([]BytesWithMime{{Data: []byte("a"), MimeType: testExtractorMime}, {Data: []byte("b"), MimeType: testExtractorMime}}, config)
+	if err != nil {
+		t.Fatalf("BatchExtractBytesSync failed: %v", err)
+	}
+	results[0].UserData["tenant"] = "changed"
+	if results[1].UserData["tenant"] != "acme" || fields["tenant"] != "acme" {
+		t.Fatalf("expected every result to get its own map, got %v", results[1].UserData)
+	}
+
+	if cloneConfig(config).ContextFields["tenant"] != "acme" {
+		t.Fatal("expected cloned configs to keep their context fields")
+	}
+
+	plain, err := ExtractFileSync(path, NewExtractionConfig(WithMimeOverrides(overrides)))
+	if err != nil || plain.UserData != nil {
+		t.Fatalf("expected no user data without fields, got %v, %v", plain, err)
+	}
+}
@@ -189,6 +189,13 @@ func GetValidLanguageCodes() ([]string, error) {
 	return codes, nil
 }
 
+// AvailableTableBackends returns the table detection backends ExtractionConfig.TableBackend
+// accepts, the default first. The native library currently provides only
+// TableBackendHeuristic.
+func AvailableTableBackends() []string {
+	return []string{TableBackendHeuristic}
+}
+
 // GetValidOCRBackends returns a list of all valid OCR backends.
 func GetValidOCRBackends() ([]string, error) {
 	ptr := C.kreuzberg_get_valid_ocr_backends()
@@ -220,3 +227,12 @@ func GetValidTokenReductionLevels() ([]string, error) {
 	}
 	return levels, nil
 }
+
+// ValidateConfig checks config the way extraction does, returning a *ValidationError for the
+// first problem found without reading any document. The extraction functions run the same
+// check before they open files, so a bad config is rejected without the cost of opening a
+// large or remote file; ValidateConfig lets callers reject it even earlier, for example when
+// loading their settings. A nil config is valid.
+func ValidateConfig(config *ExtractionConfig) error {
+	return validateConfig(config)
+}
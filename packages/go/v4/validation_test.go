@@ -1,6 +1,8 @@
 package kreuzberg
 
 import (
+	"errors"
+	"path/filepath"
 	"testing"
 )
 
@@ -268,3 +270,28 @@ func TestGetValidTokenReductionLevels(t *testing.T) {
 		t.Fatalf("expected non-empty level name in list")
 	}
 }
+
+func TestValidateConfigBeforeIO(t *testing.T) {
+	config := NewExtractionConfig(
+		WithChunking(WithChunkSize(-1)),
+		// The override would make extraction read the file before reaching native code.
+		WithMimeOverrides(map[string]string{".failfast": "text/plain"}),
+	)
+	if err := ValidateConfig(config); err == nil {
+		t.Fatal("expected ValidateConfig to reject a negative chunk size")
+	}
+
+	missing := filepath.Join(t.TempDir(), "missing.failfast")
+	_, err := ExtractFileSync(missing, config)
+	var valErr *ValidationError
+	if !errors.As(err, &valErr) {
+		t.Fatalf("expected ValidationError for a file that does not exist, got %T: %v", err, err)
+	}
+	if _, err := BatchExtractFilesSync([]string{missing}, config); !errors.As(err, &valErr) {
+		t.Fatalf("expected ValidationError from the batch API, got %T: %v", err, err)
+	}
+
+	if err := ValidateConfig(nil); err != nil {
+		t.Fatalf("nil config rejected: %v", err)
+	}
+}
@@ -0,0 +1,58 @@
+package kreuzberg
+
+// Warning codes reported in ExtractionWarning.Code.
+const (
+	// WarningCodeOCRPageTimeout marks a page whose OCR exceeded OCRConfig.PageTimeoutMillis
+	// and was skipped.
+	WarningCodeOCRPageTimeout = "ocr_page_timeout"
+	// WarningCodeOCRLanguageDownloaded reports OCR language data downloaded because
+	// OCRConfig.AutoDownloadLanguages is enabled.
+	WarningCodeOCRLanguageDownloaded = "ocr_language_downloaded"
+	// WarningCodePageFailed marks a page left out of a partial result because it could not
+	// be extracted.
+	WarningCodePageFailed = "page_failed"
+	// WarningCodePartialResult reports the error that made a result partial.
+	WarningCodePartialResult = "partial_result"
+	// WarningCodePageNotRendered marks a page WithRenderPages or WithPageThumbprints could
+	// not render.
+	WarningCodePageNotRendered = "page_not_rendered"
+	// WarningCodeImageDownsampled reports an image shrunk to fit
+	// ImagePreprocessingConfig.MaxPixels before OCR, with the scale applied.
+	WarningCodeImageDownsampled = "image_downsampled"
+	// WarningCodeImageCaptionFailed reports an error returned by
+	// ImageExtractionConfig.Captioner; the image is left without a caption.
+	WarningCodeImageCaptionFailed = "image_caption_failed"
+	// WarningCodeSignatureInvalid reports why a signature found by WithExtractSignatures is
+	// not valid.
+	WarningCodeSignatureInvalid = "signature_invalid"
+	// WarningCodeLayerNotFound names a layer selected with WithLayerSelection that the
+	// document does not have.
+	WarningCodeLayerNotFound = "layer_not_found"
+	// WarningCodeLayerSelectionIgnored reports a document extracted with all its layers
+	// because its text could not be read layer by layer.
+	WarningCodeLayerSelectionIgnored = "layer_selection_ignored"
+	// WarningCodeRecursionLimit names a nested archive that was not opened because it lies
+	// deeper than WithMaxRecursionDepth allows.
+	WarningCodeRecursionLimit = "recursion_limit"
+	// WarningCodeExtractedBytesLimit names a nested archive that was not opened because the
+	// total set with WithMaxExtractedBytes was reached.
+	WarningCodeExtractedBytesLimit = "extracted_bytes_limit"
+	// WarningCodeNestedArchiveFailed names a nested archive that could not be read or
+	// extracted.
+	WarningCodeNestedArchiveFailed = "nested_archive_failed"
+	// WarningCodeNumberNotNormalized names a table cell that looks like a number but could
+	// not be read in the locale of WithNormalizeTableNumbers; the cell is left as it is.
+	WarningCodeNumberNotNormalized = "number_not_normalized"
+)
+
+// ExtractionWarning describes a non-fatal problem encountered while extracting a document.
+// The result is still usable, but may be incomplete where the warning says so.
+type ExtractionWarning struct {
+	Code       string `json:"code"`
+	Message    string `json:"message"`
+	PageNumber int    `json:"page_number,omitempty"`
+}
+
+func (r *ExtractionResult) addWarning(code string, pageNumber int, message string) {
+	r.Warnings = append(r.Warnings, ExtractionWarning{Code: code, Message: message, PageNumber: pageNumber})
+}
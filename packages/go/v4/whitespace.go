@@ -0,0 +1,180 @@
+package kreuzberg
+
+import "strings"
+
+// applyWhitespaceNormalization rewrites result.Content according to
+// ExtractionConfig.WhitespaceNormalization. Offsets into Content held by the result (page
+// and part boundaries, chunk byte ranges and text positions) are moved to match. The text
+// of chunks taken verbatim from Content is cut from the new Content; the text of other
+// chunks and of pages is normalized on its own.
+func applyWhitespaceNormalization(result *ExtractionResult, config *ExtractionConfig) {
+	if config.WhitespaceNormalization == nil {
+		return
+	}
+	mode := *config.WhitespaceNormalization
+	if mode != WhitespaceTrim && mode != WhitespaceAggressive {
+		return
+	}
+	aggressive := mode == WhitespaceAggressive
+	normalize := func(s string) string {
+		normalized, _ := normalizeWhitespace(s, aggressive)
+		return normalized
+	}
+	inContent := make([]bool, len(result.Chunks))
+	for i, chunk := range result.Chunks {
+		inContent[i] = result.chunkInContent(chunk)
+	}
+
+	normalized, offsets := normalizeWhitespace(result.Content, aggressive)
+	if normalized != result.Content {
+		remapContent(result, normalized, offsets)
+	}
+	for i := range result.Chunks {
+		chunk := &result.Chunks[i]
+		if start, end := chunk.Metadata.ByteStart, chunk.Metadata.ByteEnd; inContent[i] && start <= end && end <= uint64(len(result.Content)) {
+			chunk.Content = result.Content[start:end]
+		} else {
+			chunk.Content = normalize(chunk.Content)
+		}
+	}
+	for i := range result.Pages {
+		result.Pages[i].Content = normalize(result.Pages[i].Content)
+	}
+}
+
+// remapContent replaces result.Content with content, moving the offsets into Content held
+// by the result (page and part boundaries, chunk byte ranges, the offset map, colored spans
+// and text positions) to match.
+// offsets[i] is the position in content of byte i of the old Content, or of the next kept
+// byte when byte i was removed; offsets has len(result.Content)+1 entries.
+func remapContent(result *ExtractionResult, content string, offsets []int) {
+	remap := func(offset uint64) uint64 {
+		return uint64(offsets[min(offset, uint64(len(offsets)-1))])
+	}
+
+	if ps := result.Metadata.PageStructure; ps != nil {
+		for i := range ps.Boundaries {
+			ps.Boundaries[i].ByteStart = remap(ps.Boundaries[i].ByteStart)
+			ps.Boundaries[i].ByteEnd = remap(ps.Boundaries[i].ByteEnd)
+		}
+	}
+	for i, boundary := range result.PartBoundaries {
+		result.PartBoundaries[i] = int(remap(uint64(max(boundary, 0))))
+	}
+	for i := range result.Chunks {
+		meta := &result.Chunks[i].Metadata
+		meta.ByteStart, meta.ByteEnd = remap(meta.ByteStart), remap(meta.ByteEnd)
+	}
+	for i := range result.OffsetMap {
+		entry := &result.OffsetMap[i]
+		entry.Start, entry.End = int(remap(uint64(max(entry.Start, 0)))), int(remap(uint64(max(entry.End, 0))))
+	}
+	for i := range result.ColoredSpans {
+		if span := &result.ColoredSpans[i]; span.ByteStart >= 0 && span.ByteEnd >= 0 {
+			span.ByteStart, span.ByteEnd = int(remap(uint64(span.ByteStart))), int(remap(uint64(span.ByteEnd)))
+		}
+	}
+	if result.textPositionsContent == result.Content {
+		positions := result.textPositions[:0]
+		for _, pos := range result.textPositions {
+			pos.start, pos.end = int(remap(uint64(pos.start))), int(remap(uint64(pos.end)))
+			if pos.start < pos.end {
+				positions = append(positions, pos)
+			}
+		}
+		result.textPositions = positions
+		result.textPositionsContent = content
+	}
+	result.Content = content
+}
+
+// normalizeWhitespace converts line endings to "\n", drops trailing spaces and tabs, and
+// keeps at most two consecutive blank lines. In aggressive mode it also collapses runs of
+// spaces and tabs into one space, drops leading indentation, keeps at most one blank line
+// in a row and removes leading and trailing blank lines.
+//
+// offsets[i] is the position in the result of byte i of s, or of the next kept byte when
+// byte i was removed; offsets has len(s)+1 entries.
+func normalizeWhitespace(s string, aggressive bool) (string, []int) {
+	maxBlank := 2
+	if aggressive {
+		maxBlank = 1
+	}
+	var out strings.Builder
+	out.Grow(len(s))
+	offsets := make([]int, len(s)+1)
+	skip := func(from, to int) {
+		for i := from; i < to; i++ {
+			offsets[i] = out.Len()
+		}
+	}
+
+	blank := 0
+	seenText := false
+	for start := 0; start < len(s); {
+		end := strings.IndexAny(s[start:], "\r\n")
+		next := len(s)
+		if end < 0 {
+			end = len(s)
+		} else {
+			end += start
+			next = end + 1
+			if s[end] == '\r' && next < len(s) && s[next] == '\n' {
+				next++
+			}
+		}
+
+		line := strings.TrimRight(s[start:end], " \t")
+		lead := 0
+		if aggressive {
+			lead = len(line) - len(strings.TrimLeft(line, " \t"))
+		}
+		if len(line) == lead {
+			blank++
+			if blank > maxBlank || (aggressive && !seenText) {
+				skip(start, next)
+				start = next
+				continue
+			}
+		} else {
+			blank = 0
+			seenText = true
+		}
+
+		skip(start, start+lead)
+		inSpace := false
+		for i := start + lead; i < start+len(line); i++ {
+			c := s[i]
+			if aggressive && (c == ' ' || c == '\t') {
+				if inSpace {
+					offsets[i] = out.Len()
+					continue
+				}
+				inSpace = true
+				offsets[i] = out.Len()
+				out.WriteByte(' ')
+				continue
+			}
+			inSpace = false
+			offsets[i] = out.Len()
+			out.WriteByte(c)
+		}
+		skip(start+len(line), end)
+		if end < len(s) {
+			offsets[end] = out.Len()
+			out.WriteByte('\n')
+			skip(end+1, next)
+		}
+		start = next
+	}
+	offsets[len(s)] = out.Len()
+
+	normalized := out.String()
+	if aggressive {
+		normalized = strings.TrimRight(normalized, "\n")
+		for i, offset := range offsets {
+			offsets[i] = min(offset, len(normalized))
+		}
+	}
+	return normalized, offsets
+}
@@ -0,0 +1,74 @@
+package kreuzberg
+
+import "testing"
+
+func TestNormalizeWhitespace(t *testing.T) {
+	input := "Title  \r\n\r\n\r\n\r\n  Indented\t\ttext   here\r\nnext\rline\n\n\n"
+	cases := []struct {
+		mode, want string
+	}{
+		{WhitespaceNone, input},
+		{WhitespaceTrim, "Title\n\n\n  Indented\t\ttext   here\nnext\nline\n\n\n"},
+		{WhitespaceAggressive, "Title\n\nIndented text here\nnext\nline"},
+	}
+	for _, tc := range cases {
+		t.Run(tc.mode, func(t *testing.T) {
+			result := &ExtractionResult{Content: input}
+			applyWhitespaceNormalization(result, NewExtractionConfig(WithWhitespaceNormalization(tc.mode)))
+			if result.Content != tc.want {
+				t.Fatalf("Content = %q, want %q", result.Content, tc.want)
+			}
+		})
+	}
+
+	if got, _ := normalizeWhitespace("\n\n  lead\n", true); got != "lead" {
+		t.Fatalf("aggressive mode kept leading blank lines: %q", got)
+	}
+}
+
+func TestWhitespaceNormalizationOffsets(t *testing.T) {
+	content := "Page one  \r\n\r\n\r\n\r\nmore\r\nPage two\r\n"
+	second := uint64(len("Page one  \r\n\r\n\r\n\r\nmore\r\n"))
+	result := &ExtractionResult{
+		Content: content,
+		Metadata: Metadata{PageStructure: &PageStructure{Boundaries: []PageBoundary{
+			{ByteStart: 0, ByteEnd: second, PageNumber: 1},
+			{ByteStart: second, ByteEnd: uint64(len(content)), PageNumber: 2},
+		}}},
+		PartBoundaries: []int{0, int(second)},
+		Chunks: []Chunk{
+			{Content: "more", Metadata: ChunkMetadata{ByteStart: second - 6, ByteEnd: second - 2}},
+			{Content: content[:second-2], Metadata: ChunkMetadata{ByteStart: 0, ByteEnd: second - 2}},
+		},
+		Pages: []PageContent{{PageNumber: 1, Content: "Page one  \r\n\r\n\r\n\r\nmore"}},
+	}
+	applyWhitespaceNormalization(result, NewExtractionConfig(WithWhitespaceNormalization(WhitespaceTrim)))
+
+	want := "Page one\n\n\nmore\nPage two\n"
+	if result.Content != want {
+		t.Fatalf("Content = %q, want %q", result.Content, want)
+	}
+	boundaries := result.Metadata.PageStructure.Boundaries
+	if got := want[boundaries[1].ByteStart:boundaries[1].ByteEnd]; got != "Page two\n" {
+		t.Fatalf("second page = %q", got)
+	}
+	if got := want[result.PartBoundaries[1]:]; got != "Page two\n" {
+		t.Fatalf("second part = %q", got)
+	}
+	meta := result.Chunks[0].Metadata
+	if got := want[meta.ByteStart:meta.ByteEnd]; got != "more" {
+		t.Fatalf("chunk range = %q", got)
+	}
+	for _, chunk := range result.Chunks {
+		if got := want[chunk.Metadata.ByteStart:chunk.Metadata.ByteEnd]; got != chunk.Content {
+			t.Fatalf("chunk text %q differs from its range %q", chunk.Content, got)
+		}
+	}
+	if got := result.Pages[0].Content; got != "Page one\n\n\nmore" {
+		t.Fatalf("page text = %q", got)
+	}
+
+	if err := validateConfig(NewExtractionConfig(WithWhitespaceNormalization("tidy"))); err == nil {
+		t.Fatal("expected an unknown mode to fail validation")
+	}
+}